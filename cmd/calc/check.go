@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/andrewneudegg/calc/pkg/analysis"
+	"github.com/andrewneudegg/calc/pkg/config"
+	"github.com/andrewneudegg/calc/pkg/evaluator"
+)
+
+// checkScript checks the script at path, or on stdin for "-", without
+// running it, printing each problem as file:line:column to stderr. It exits
+// 1 when the script has errors or cannot be read, and 0 when it has at most
+// warnings.
+func checkScript(path string, stdin io.Reader, stderr io.Writer) int {
+	// Lines are read with the settings and custom units a run would use
+	env := evaluator.NewEnvironment()
+	if unitsPath, err := config.Path(config.CustomUnits); err == nil {
+		for _, w := range env.Units().LoadCustomUnits(unitsPath) {
+			fmt.Fprintf(stderr, "warning: %s\n", w)
+		}
+	}
+	checker := analysis.New(loadSettings(stderr), env)
+
+	src, name := stdin, "stdin"
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		src, name = f, path
+	}
+	script := newScriptReader(src)
+	for {
+		ln, more := script.next()
+		if !more {
+			break
+		}
+		checker.Line(script.line, ln)
+	}
+	if err := script.err(); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	problems := checker.Problems()
+	for _, p := range problems {
+		fmt.Fprintf(stderr, "%s:%s\n", name, p)
+	}
+	if analysis.HasErrors(problems) {
+		return 1
+	}
+	return 0
+}
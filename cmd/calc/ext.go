@@ -0,0 +1,8 @@
+//go:build ext
+
+package main
+
+// Building with -tags ext links in the example extension, which registers
+// its functions from init. Extensions of your own can be added the same way
+// with a file like this one under a build tag of their choosing.
+import _ "github.com/andrewneudegg/calc/examples/ext"
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/andrewneudegg/calc/pkg/evaluator"
+)
+
+// listFunctions prints each function calc can call with its summary,
+// marking those added by extensions built into this binary.
+func listFunctions(w io.Writer) {
+	for _, f := range evaluator.Functions() {
+		desc := f.Description
+		if !f.Builtin() {
+			desc += " (extension)"
+		}
+		fmt.Fprintf(w, "%-16s %s\n", f.Usage(), desc)
+	}
+}
@@ -2,20 +2,33 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/andrewneudegg/calc/pkg/config"
 	"github.com/andrewneudegg/calc/pkg/display"
 	"github.com/andrewneudegg/calc/pkg/evaluator"
 	"github.com/andrewneudegg/calc/pkg/formatter"
 	"github.com/andrewneudegg/calc/pkg/lexer"
 	"github.com/andrewneudegg/calc/pkg/parser"
+	"github.com/andrewneudegg/calc/pkg/rounding"
 	"github.com/andrewneudegg/calc/pkg/settings"
+	"github.com/andrewneudegg/calc/pkg/units"
 )
 
+// timingsReportLines is the number of slowest lines listed by --timings.
+const timingsReportLines = 10
+
+// defaultTimingThreshold is how long a line must take for --timings to list
+// it, unless --timing-threshold says otherwise.
+const defaultTimingThreshold = 10 * time.Millisecond
+
 // argsMap is a custom flag type for repeated --arg flags
 type argsMap map[string]string
 
@@ -41,12 +54,28 @@ USAGE:
 	calc                Start interactive REPL mode
 	calc -c "expr"      Execute a single calculation and exit
 	calc -f file.calc    Execute all lines from a file and print results
+	calc serve          Serve calculations over HTTP on 127.0.0.1:7436 (calc serve -h for options)
 
 OPTIONS:
 	-c string           Execute calculation and exit
 	-f string           Execute a .calc file and print results
 	-a, --arg name=value  Pass argument to script (can be repeated)
 	--arg-file path     Read arguments from a file (key=value format)
+	--timings           With -f, report the slowest lines, per-stage totals and tokens/s to stderr (also --timing)
+	--timing-threshold duration  With --timings, also list every line taking at least this long (default 10ms)
+	--echo              With -f, print each input line beside its result (also :set echo on)
+	--also              With -c or -f, show unit results in companion units on an "also:" line
+	--show-steps        With -c or -f, show the steps of a conversion chain on a "steps:" line
+	--fail-fast         With -f, stop at the first line that fails
+	--quiet-errors      With -f, skip the failure summary and exit 0 even if lines failed
+	--timeout duration  With -c or -f, stop evaluating after this long, e.g. 5s, and exit 1
+	--now time          Fix the current time for now, today and weekdays (RFC 3339, e.g. 2024-06-01T00:00:00Z)
+	-q, --quiet         Print results only, not assignments or tips (--output quiet)
+	-v, --verbose       Also print exchange rates, companion units and timings (--output verbose)
+	--output level      Set how much is printed: silent, quiet, normal or verbose (also :set output)
+	--parse-only        With -c, print the tokens and syntax tree as JSON instead of evaluating
+	--check             With -f, report syntax errors, undefined variables and unused :arg directives without running the script
+	--list-functions    List the functions calc can call, including any added by extensions
 	-h, --help          Show this help message
 
 EXAMPLES:
@@ -58,6 +87,13 @@ EXAMPLES:
 	calc -f examples/k8s-cluster.calc
 	calc -f script.calc --arg count=5 --arg rate=10
 	calc -f script.calc --arg-file args.env
+	calc -f script.calc --echo
+	calc -f script.calc --fail-fast
+	calc -c "today + 3 weeks" --now 2024-06-01T00:00:00Z
+	calc -f untrusted.calc --timeout 5s
+	calc -c "£100 in USD" -v
+	calc --parse-only -c "5 km in miles"
+	calc -f budget.calc --check
 
 FEATURES:
   • Arithmetic with operator precedence and parentheses
@@ -75,7 +111,7 @@ REPL COMMANDS:
   :help              Show available commands
   :set precision N   Set decimal precision
   :set currency C    Set default currency (GBP, USD, EUR, JPY)
-	:quiet [on|off]    Toggle or set quiet mode (suppress assignment output)
+	:quiet [on|off]    Toggle quiet output for this session (:set output quiet to keep it)
   :save file.txt     Save workspace to file
   :open file.txt     Load workspace from file
   :quit              Exit calculator
@@ -84,37 +120,108 @@ For more information, visit: https://github.com/AndrewNeudegg/calc
 `
 
 func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run parses the command line, runs calc and returns the process exit code.
+// It only touches the streams it is given, apart from the interactive REPL,
+// so tests can drive the whole program without building a binary.
+func run(argv []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(argv) > 0 && argv[0] == "serve" {
+		return runServe(argv[1:], stdout, stderr)
+	}
+
+	fs := flag.NewFlagSet("calc", flag.ContinueOnError)
+	fs.SetOutput(stderr)
 	// Custom usage function
-	flag.Usage = func() {
-		fmt.Fprint(os.Stderr, helpText)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, helpText)
 	}
 
 	// Define flags
-	calcExpr := flag.String("c", "", "Execute a single calculation and exit")
-	filePath := flag.String("f", "", "Execute a .calc file and print results")
-	argFile := flag.String("arg-file", "", "Read arguments from a file")
-	showHelp := flag.Bool("help", false, "Show help message")
-	flag.BoolVar(showHelp, "h", false, "Show help message")
-	
+	calcExpr := fs.String("c", "", "Execute a single calculation and exit")
+	filePath := fs.String("f", "", "Execute a .calc file and print results")
+	argFile := fs.String("arg-file", "", "Read arguments from a file")
+	showTimings := fs.Bool("timings", false, "Report per-line timings after running a file")
+	fs.BoolVar(showTimings, "timing", false, "Report per-line timings after running a file")
+	timingThreshold := fs.Duration("timing-threshold", defaultTimingThreshold, "With --timings, also list every line that took at least this long")
+	echo := fs.Bool("echo", false, "Print each input line beside its result when running a file")
+	also := fs.Bool("also", false, "Show unit results in companion units")
+	showSteps := fs.Bool("show-steps", false, "Show the steps of conversion chains")
+	failFast := fs.Bool("fail-fast", false, "Stop a file at the first line that fails")
+	quietErrors := fs.Bool("quiet-errors", false, "Exit 0 without a summary when file lines fail")
+	nowFlag := fs.String("now", "", "Fix the current time (RFC 3339)")
+	timeout := fs.Duration("timeout", 0, "Stop evaluating after this long")
+	quiet := fs.Bool("quiet", false, "Print results only")
+	fs.BoolVar(quiet, "q", false, "Print results only")
+	verbose := fs.Bool("verbose", false, "Also print rates, companion units and timings")
+	fs.BoolVar(verbose, "v", false, "Also print rates, companion units and timings")
+	outputFlag := fs.String("output", "", "How much to print: silent, quiet, normal or verbose")
+	parseOnlyFlag := fs.Bool("parse-only", false, "With -c, print the tokens and AST as JSON without evaluating")
+	checkFlag := fs.Bool("check", false, "With -f, check the script for errors without running it")
+	listFunctionsFlag := fs.Bool("list-functions", false, "List the functions calc can call")
+	showHelp := fs.Bool("help", false, "Show help message")
+	fs.BoolVar(showHelp, "h", false, "Show help message")
+
 	// Custom argsMap for repeated --arg flags
 	args := make(argsMap)
-	flag.Var(&args, "arg", "Pass argument to script (name=value)")
-	flag.Var(&args, "a", "Pass argument to script (name=value)")
-	
-	flag.Parse()
+	fs.Var(&args, "arg", "Pass argument to script (name=value)")
+	fs.Var(&args, "a", "Pass argument to script (name=value)")
+
+	if err := fs.Parse(argv); err != nil {
+		return 2
+	}
 
 	// Show help if requested
 	if *showHelp {
-		fmt.Print(helpText)
-		os.Exit(0)
+		fmt.Fprint(stdout, helpText)
+		return 0
+	}
+
+	if *listFunctionsFlag {
+		listFunctions(stdout)
+		return 0
+	}
+
+	if *parseOnlyFlag {
+		if *calcExpr == "" || *filePath != "" {
+			fmt.Fprintln(stderr, "Error: --parse-only needs an expression given with -c")
+			return 2
+		}
+		return parseOnly(*calcExpr, stdout, stderr)
+	}
+
+	if *checkFlag {
+		if *filePath == "" || *calcExpr != "" {
+			fmt.Fprintln(stderr, "Error: --check needs a script given with -f")
+			return 2
+		}
+		return checkScript(*filePath, stdin, stderr)
+	}
+
+	output, err := outputLevel(*outputFlag, *quiet, *verbose)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	// A fixed clock makes date output reproducible
+	var clock evaluator.Clock
+	if *nowFlag != "" {
+		now, err := parseNow(*nowFlag)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		clock = evaluator.FixedClock(now)
 	}
 
 	// Load arguments from file if specified
 	if *argFile != "" {
 		fileArgs, err := loadArgsFromFile(*argFile)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error loading arg file: %v\n", err)
-			os.Exit(1)
+			fmt.Fprintf(stderr, "Error loading arg file: %v\n", err)
+			return 1
 		}
 		// Merge file args with CLI args (CLI args override file args)
 		for k, v := range fileArgs {
@@ -126,22 +233,88 @@ func main() {
 
 	// If -f flag is provided, execute file and exit
 	if *filePath != "" {
-		if err := executeFile(*filePath, args); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+		opts := fileOptions{
+			args:        args,
+			showTimings: *showTimings,
+			threshold:   *timingThreshold,
+			echo:        *echo,
+			also:        *also,
+			showSteps:   *showSteps,
+			failFast:    *failFast,
+			timeout:     *timeout,
+			clock:       clock,
+			output:      output,
 		}
-		return
+		report, err := executeFile(*filePath, opts, stdin, stdout, stderr)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		if *quietErrors {
+			return 0
+		}
+		if len(report.warnings) > 0 && report.output > settings.OutputSilent {
+			fmt.Fprintln(stderr, report.warningSummary())
+		}
+		if len(report.failed) == 0 {
+			return 0
+		}
+		fmt.Fprintln(stderr, report.summary())
+		return 1
 	}
 
 	// If -c flag is provided, execute and exit
 	if *calcExpr != "" {
-		executeAndExit(*calcExpr)
-		return
+		return executeExpr(*calcExpr, clock, *also, *showSteps, *timeout, output, stdout, stderr)
 	}
 
 	// Otherwise, start the REPL
 	repl := display.NewREPL()
+	if clock != nil {
+		repl.SetClock(clock)
+	}
+	repl.SetOutputLevel(output)
 	repl.Run()
+	return 0
+}
+
+// outputLevel reads the -q, -v and --output flags as an output level, or
+// the unset level when none is given so the output setting applies.
+func outputLevel(name string, quiet, verbose bool) (settings.OutputLevel, error) {
+	var level settings.OutputLevel
+	given := 0
+	if name != "" {
+		l, err := settings.ParseOutputLevel(name)
+		if err != nil {
+			return 0, err
+		}
+		level = l
+		given++
+	}
+	if quiet {
+		level = settings.OutputQuiet
+		given++
+	}
+	if verbose {
+		level = settings.OutputVerbose
+		given++
+	}
+	if given > 1 {
+		return 0, fmt.Errorf("use only one of -q, -v and --output")
+	}
+	return level, nil
+}
+
+// parseNow reads the --now flag as an RFC 3339 timestamp, or a bare date
+// meaning midnight UTC.
+func parseNow(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --now %q: expected an RFC 3339 time such as 2024-06-01T09:30:00Z", value)
 }
 
 // loadArgsFromFile reads arguments from a .env-style file
@@ -176,7 +349,10 @@ func loadArgsFromFile(path string) (map[string]string, error) {
 // If env is nil, constants will not be recognized and will be treated as regular identifiers.
 func parseLineToExpr(input string, env *evaluator.Environment) (parser.Expr, error) {
 	lex := lexer.New(input)
-	// Hook up constants checker if environment is available
+	// Hook up unit and constants checkers if environment is available
+	if env != nil {
+		lex = lexer.NewWithUnits(input, env.IsUnit)
+	}
 	if env != nil && env.Constants() != nil {
 		lex.SetConstantChecker(env.Constants().IsConstant)
 	}
@@ -192,109 +368,270 @@ func parseLineToExpr(input string, env *evaluator.Environment) (parser.Expr, err
 	return p.Parse()
 }
 
-// executeFile runs a .calc script file line-by-line, printing results to stdout.
-// Commands (lines starting with :) are executed and their messages printed; comment-only lines are ignored.
-func executeFile(path string, providedArgs map[string]string) error {
-	var b []byte
-	var err error
+// fileOptions controls how executeFile runs a script.
+type fileOptions struct {
+	args        map[string]string    // Values for :arg directives
+	showTimings bool                 // Report the slowest lines to stderr after the run
+	threshold   time.Duration        // With showTimings, also report every line that took this long
+	echo        bool                 // Print each input line beside its result
+	also        bool                 // Follow unit results with an "also:" line of companion units
+	showSteps   bool                 // Follow converted results with a "steps:" line of the chain
+	failFast    bool                 // Stop at the first line that fails
+	timeout     time.Duration        // Stop the run after this long when non-zero
+	clock       evaluator.Clock      // Fixes the time used for relative dates when non-nil
+	output      settings.OutputLevel // Output level for the run; unset follows the output setting
+}
+
+// fileReport records which lines of a script failed.
+type fileReport struct {
+	run     int   // Lines evaluated, not counting blanks, comments and :arg directives
+	failed  []int // Line numbers that produced an error
+	stopped bool  // The run ended early at the first failure
+	// warnings are the run's warnings by line, such as undefined variables
+	// read as 0 when strict mode is off
+	warnings []string
+	output   settings.OutputLevel // Output level the run ended at, which decides whether warnings are listed
+}
 
-	if path == "-" {
-		// Read from stdin
-		b, err = io.ReadAll(os.Stdin)
-	} else {
-		b, err = os.ReadFile(path)
+// summary describes the failed lines, e.g. "3 of 42 lines failed: lines 7, 19, 30".
+func (r fileReport) summary() string {
+	nums := make([]string, len(r.failed))
+	for i, n := range r.failed {
+		nums[i] = strconv.Itoa(n)
 	}
-	if err != nil {
-		return err
+	noun := "lines"
+	if len(r.failed) == 1 {
+		noun = "line"
 	}
+	s := fmt.Sprintf("%d of %d lines failed: %s %s", len(r.failed), r.run, noun, strings.Join(nums, ", "))
+	if r.stopped {
+		s += " (stopped at the first failure)"
+	}
+	return s
+}
+
+// warningSummary lists the run's warnings, e.g.
+// "1 warning:\n  line 4: undefined variable rent treated as 0".
+func (r fileReport) warningSummary() string {
+	noun := "warnings"
+	if len(r.warnings) == 1 {
+		noun = "warning"
+	}
+	return fmt.Sprintf("%d %s:\n  %s", len(r.warnings), noun, strings.Join(r.warnings, "\n  "))
+}
+
+// executeFile runs a .calc script file line-by-line, printing results to stdout.
+// Commands (lines starting with :) are executed and their messages printed; comment-only lines are ignored.
+// Errors are printed to stderr and the run carries on unless opts.failFast is set;
+// the returned report lists the lines that failed. An error is returned only when
+// the script cannot be run at all, such as a missing file or an invalid argument.
+//
+// The script is streamed, each result printed as its line is read, so a
+// script of any length runs in the memory of its longest line. A file is
+// first scanned for its :arg directives; a script on stdin can only be read
+// once, so its directives are resolved as they are reached, without
+// prompting, and echoed inputs are padded to the widest allowed.
+func executeFile(path string, opts fileOptions, stdin io.Reader, stdout, stderr io.Writer) (fileReport, error) {
+	var report fileReport
 
 	repl := display.NewREPL()
 	repl.SetSilent(true)
+	repl.SetOutputLevel(opts.output)
+	if opts.clock != nil {
+		repl.SetClock(opts.clock)
+	}
 
-	// First pass: collect all :arg directives
-	requiredArgs := make(map[string]string) // name -> prompt
-	lines := strings.Split(string(b), "\n")
-	
-	for _, ln := range lines {
-		input := strings.TrimSpace(ln)
-		if input == "" || strings.HasPrefix(input, "#") {
-			continue
+	src := stdin
+	width := maxEchoWidth
+	if path != "-" {
+		directives, w, err := scanScript(path, repl)
+		if err != nil {
+			return report, err
 		}
-		
-		// Parse to check if it's an :arg directive
-		expr, parseErr := parseLineToExpr(input, repl.Env())
-		if parseErr != nil || expr == nil {
-			continue
+		width = w
+
+		// Process arguments: use provided args, fall back to defaults when not
+		// interactive, or prompt for missing ones
+		interactive := false
+		if f, ok := stdin.(*os.File); ok {
+			interactive = display.IsTerminal(f)
 		}
-		
-		if argDir, ok := expr.(*parser.ArgDirectiveExpr); ok {
-			requiredArgs[argDir.Name] = argDir.Prompt
+		reader := bufio.NewReader(stdin)
+		for _, dir := range directives {
+			if err := resolveArg(repl, dir, opts.args, interactive, reader, stdout); err != nil {
+				return report, err
+			}
 		}
-	}
 
-	// Process arguments: use provided args or prompt for missing ones
-	for name, prompt := range requiredArgs {
-		if val, exists := providedArgs[name]; exists {
-			// Parse the provided value through lexer/parser for rich input
-			if err := setArgVariable(repl, name, val); err != nil {
-				return fmt.Errorf("error setting argument %s: %v", name, err)
-			}
-		} else {
-			// Prompt user for the argument
-			if prompt == "" {
-				prompt = fmt.Sprintf("Enter value for %s:", name)
-			}
-			fmt.Printf("%s ", prompt)
-			
-			reader := bufio.NewReader(os.Stdin)
-			response, err := reader.ReadString('\n')
-			if err != nil {
-				return fmt.Errorf("error reading argument %s: %v", name, err)
-			}
-			response = strings.TrimSpace(response)
-			
-			// Parse the response through lexer/parser for rich input
-			if err := setArgVariable(repl, name, response); err != nil {
-				return fmt.Errorf("error setting argument %s: %v", name, err)
-			}
+		f, err := os.Open(path)
+		if err != nil {
+			return report, err
 		}
+		defer f.Close()
+		src = f
 	}
 
-	// Second pass: execute the script
-	for _, ln := range lines {
+	var timings *display.Timings
+	if opts.showTimings {
+		timings = repl.EnableTimings()
+		timings.Threshold = opts.threshold
+	}
+
+	// Echo can also be switched on by the script itself with :set echo on
+	echoing := func() bool { return opts.echo || repl.Settings().Echo }
+
+	// The timeout starts once arguments are read, so prompting for them is not counted
+	ctx, cancel := withTimeout(opts.timeout)
+	defer cancel()
+	repl.SetContext(ctx)
+
+	// Execute the script
+	script := newScriptReader(src)
+	for {
+		ln, more := script.next()
+		if !more {
+			break
+		}
+		line := script.line
 		input := strings.TrimSpace(ln)
 		if input == "" || strings.HasPrefix(input, "#") {
+			// Comments and blank lines become headings and spacing in an echoed report
+			if echoing() {
+				fmt.Fprintln(stdout, ln)
+			}
 			continue
 		}
-		
-		// Parse to check if it's an :arg directive (skip execution)
-		expr, parseErr := parseLineToExpr(input, repl.Env())
-		if parseErr == nil && expr != nil {
-			if _, ok := expr.(*parser.ArgDirectiveExpr); ok {
-				// Skip :arg directives in execution phase
-				continue
+		if timings != nil {
+			timings.SetLine(line)
+		}
+
+		// :arg directives were resolved before the run, except on stdin
+		dir, err := argDirective(input, repl)
+		if err != nil {
+			return report, fmt.Errorf("line %d: %v", line, err)
+		}
+		if dir != nil {
+			if path == "-" {
+				if err := resolveArg(repl, dir, opts.args, false, nil, stdout); err != nil {
+					return report, err
+				}
 			}
+			continue
 		}
-		
+
+		report.run++
+		repl.SetSourceLine(line)
 		v := repl.EvaluateLine(input)
-		// Skip sentinel no-op (commands or comment-only handled by EvaluateLine)
-		if v.IsError() {
-			if v.Error == "" {
-				continue
+		level := repl.OutputLevel()
+		if level == settings.OutputSilent {
+			v = withoutWarnings(v)
+		}
+		ok := !v.IsError() || v.Error == ""
+		switch {
+		case echoing():
+			printEcho(stdout, repl.Formatter(), input, width, v)
+			ok = ok && !hasFailedItem(v)
+		case v.IsError():
+			// Skip the sentinel no-op of a command that worked or a comment-only
+			// line; print errors, including failed commands, to stderr
+			if p, ok := v.Provenance(); ok && v.Error != "" {
+				fmt.Fprintf(stderr, "Error in %s: %s\n", p, v.Error)
+			} else if v.Error != "" {
+				fmt.Fprintln(stderr, repl.Formatter().Format(v))
+			}
+		default:
+			// Print formatted value to stdout, one line per result for lists
+			ok = printResultLines(stdout, stderr, repl.Formatter(), v)
+		}
+		printNotes(stdout, resultNotes(repl.Formatter(), v, opts.also, opts.showSteps, level), echoing(), width)
+		if level == settings.OutputVerbose && !strings.HasPrefix(input, ":") {
+			fmt.Fprintf(stderr, "line %d: %s\n", line, repl.Formatter().Elapsed(repl.Elapsed()))
+		}
+		if !ok {
+			report.failed = append(report.failed, line)
+			if opts.failFast {
+				report.stopped = true
+				break
 			}
-			// Print errors to stderr to mimic typical CLI behavior
-			fmt.Fprintln(os.Stderr, repl.Formatter().Format(v))
-			continue
 		}
-		// Print formatted value to stdout
-		fmt.Println(repl.Formatter().Format(v))
+		if ctx.Err() != nil {
+			return report, fmt.Errorf("timed out after %s at line %d", opts.timeout, line)
+		}
+	}
+	if err := script.err(); err != nil {
+		return report, err
 	}
 
-	return nil
+	if timings != nil {
+		timings.WriteReport(stderr, timingsReportLines)
+	}
+
+	report.warnings = repl.Warnings()
+	report.output = repl.OutputLevel()
+	return report, nil
+}
+
+// resolveArg sets the variable an :arg directive names: to the value given
+// for it, to its default when stdin is not a terminal, or else to the answer
+// read from reader after prompting on stdout, asking again until it is valid.
+// A nil reader means there is no one to ask.
+func resolveArg(repl *display.REPL, dir *parser.ArgDirectiveExpr, args map[string]string, interactive bool, reader *bufio.Reader, stdout io.Writer) error {
+	name := dir.Name
+	if val, exists := args[name]; exists {
+		// Parse the provided value through lexer/parser for rich input
+		if err := setArgVariable(repl, dir, val); err != nil {
+			return fmt.Errorf("invalid value for argument %s: %v", name, err)
+		}
+		return nil
+	}
+	if dir.Default != nil && !interactive {
+		if err := setArgValue(repl, dir, repl.Env().Eval(dir.Default)); err != nil {
+			return fmt.Errorf("invalid default for argument %s: %v", name, err)
+		}
+		return nil
+	}
+	if reader == nil {
+		return fmt.Errorf("no value for argument %s; pass one with --arg %s=<value>", name, name)
+	}
+
+	// Prompt user for the argument, asking again until the value is valid
+	prompt := dir.Prompt
+	if prompt == "" {
+		prompt = fmt.Sprintf("Enter value for %s:", name)
+	}
+	if example := argExample(repl, dir); example != "" {
+		prompt = fmt.Sprintf("%s (e.g. %s):", strings.TrimSuffix(prompt, ":"), example)
+	}
+	if dir.Default != nil {
+		prompt = fmt.Sprintf("%s [%s]", prompt, repl.Formatter().Format(repl.Env().Eval(dir.Default)))
+	}
+	for {
+		fmt.Fprintf(stdout, "%s ", prompt)
+		response, err := reader.ReadString('\n')
+		if err != nil && (err != io.EOF || response == "") {
+			return fmt.Errorf("error reading argument %s: %v", name, err)
+		}
+		response = strings.TrimSpace(response)
+
+		// Parse the response through lexer/parser for rich input
+		if response == "" && dir.Default != nil {
+			err = setArgValue(repl, dir, repl.Env().Eval(dir.Default))
+		} else {
+			err = setArgVariable(repl, dir, response)
+		}
+		if err == nil {
+			return nil
+		}
+		if !interactive {
+			return fmt.Errorf("invalid value for argument %s: %v", name, err)
+		}
+		fmt.Fprintf(stdout, "%v\n", err)
+	}
 }
 
-// setArgVariable parses a string value and sets it as a variable in the REPL environment
-func setArgVariable(repl *display.REPL, name, value string) error {
+// setArgVariable parses a string value, checks it against the :arg directive and
+// sets it as a variable in the REPL environment
+func setArgVariable(repl *display.REPL, dir *parser.ArgDirectiveExpr, value string) error {
 	// Parse the value through lexer/parser to support units, currency, expressions, etc.
 	expr, err := parseLineToExpr(value, repl.Env())
 	if err != nil {
@@ -305,49 +642,347 @@ func setArgVariable(repl *display.REPL, name, value string) error {
 	}
 	
 	// Evaluate the expression
-	result := repl.Env().Eval(expr)
+	return setArgValue(repl, dir, repl.Env().Eval(expr))
+}
+
+// setArgValue validates an evaluated argument against its directive's type and
+// range, then sets the variable
+func setArgValue(repl *display.REPL, dir *parser.ArgDirectiveExpr, result evaluator.Value) error {
 	if result.IsError() {
 		return fmt.Errorf("%s", result.Error)
 	}
-	
-	// Set the variable
-	repl.Env().SetVariable(name, result)
+	result, err := applyArgUnit(repl, dir, result)
+	if err != nil {
+		return err
+	}
+	if err := checkArgType(dir, result, repl.Formatter()); err != nil {
+		return err
+	}
+
+	// Set the variable so the bounds can be compared through the evaluator,
+	// which converts between currencies and units
+	repl.Env().SetVariable(dir.Name, result)
+	if err := checkArgBound(repl, dir, dir.Min, "at least"); err != nil {
+		return err
+	}
+	return checkArgBound(repl, dir, dir.Max, "at most")
+}
+
+// checkArgType reports a mismatch between a value and the directive's declared type.
+func checkArgType(dir *parser.ArgDirectiveExpr, v evaluator.Value, f *formatter.Formatter) error {
+	want := map[string]evaluator.ValueType{
+		"number":   evaluator.ValueNumber,
+		"currency": evaluator.ValueCurrency,
+		"unit":     evaluator.ValueUnit,
+		"date":     evaluator.ValueDate,
+	}
+	if t, ok := want[dir.Type]; ok && v.Type != t {
+		article := "a"
+		if dir.Type == "unit" {
+			article = "a value with a"
+		}
+		return fmt.Errorf("%s must be %s %s, got %s", dir.Name, article, dir.Type, f.Format(v))
+	}
+	return nil
+}
+
+// argUnitHint resolves the unit or dimension named after the unit type of
+// an :arg directive, giving the unit a bare number takes, if any, and the
+// dimension the value must have. A dimension takes the unit of the default
+// value. It gives DimensionNone when the directive names neither.
+func argUnitHint(repl *display.REPL, dir *parser.ArgDirectiveExpr) (string, units.Dimension, error) {
+	if dir.Unit == "" {
+		return "", units.DimensionNone, nil
+	}
+	sys := repl.Env().Units()
+	if dim, ok := units.ParseDimension(dir.Unit); ok {
+		if dir.Default != nil {
+			def := repl.Env().Eval(dir.Default)
+			if d, err := sys.GetDimension(def.Unit); def.Type == evaluator.ValueUnit && err == nil && d == dim {
+				return def.Unit, dim, nil
+			}
+		}
+		return "", dim, nil
+	}
+	dim, err := sys.GetDimension(dir.Unit)
+	if err != nil {
+		return "", units.DimensionNone, fmt.Errorf("unknown unit or dimension %q in :arg %s", dir.Unit, dir.Name)
+	}
+	return dir.Unit, dim, nil
+}
+
+// argExample gives an example answer for an :arg directive with a unit
+// hint, such as "10 km", or "" for other directives.
+func argExample(repl *display.REPL, dir *parser.ArgDirectiveExpr) string {
+	unit, dim, err := argUnitHint(repl, dir)
+	if err != nil || dim == units.DimensionNone {
+		return ""
+	}
+	if unit == "" {
+		// Without a unit to hand, the dimension's base unit serves
+		in := repl.Env().Units().UnitsIn(dim)
+		if len(in) == 0 {
+			return ""
+		}
+		unit = in[0].Name
+		for _, u := range in {
+			if u.ToBase == 1 {
+				unit = u.Name
+				break
+			}
+		}
+	}
+	return "10 " + unit
+}
+
+// applyArgUnit fits an argument to its directive's unit hint: a bare number
+// takes the hinted unit, and a value in another unit of the hinted dimension
+// is converted to it. A value of the wrong dimension is refused.
+func applyArgUnit(repl *display.REPL, dir *parser.ArgDirectiveExpr, v evaluator.Value) (evaluator.Value, error) {
+	unit, dim, err := argUnitHint(repl, dir)
+	if err != nil || dim == units.DimensionNone {
+		return v, err
+	}
+	switch v.Type {
+	case evaluator.ValueNumber:
+		if unit == "" {
+			return v, fmt.Errorf("%s needs a unit of %s, e.g. %s", dir.Name, dim, argExample(repl, dir))
+		}
+		return evaluator.NewUnit(v.Number, unit), nil
+	case evaluator.ValueUnit:
+		sys := repl.Env().Units()
+		if d, err := sys.GetDimension(v.Unit); err != nil || d != dim {
+			return v, fmt.Errorf("%s must be in a unit of %s, e.g. %s, got %s", dir.Name, dim, argExample(repl, dir), repl.Formatter().Format(v))
+		}
+		if unit == "" {
+			return v, nil
+		}
+		n, err := sys.Convert(v.Number, v.Unit, unit)
+		if err != nil {
+			return v, err
+		}
+		return evaluator.NewUnit(n, unit), nil
+	}
+	// Other types are refused by checkArgType
+	return v, nil
+}
+
+// checkArgBound evaluates "<name> - <bound>" and reports when the value falls on
+// the wrong side of the bound.
+func checkArgBound(repl *display.REPL, dir *parser.ArgDirectiveExpr, bound parser.Expr, relation string) error {
+	if bound == nil {
+		return nil
+	}
+	diff := repl.Env().Eval(&parser.BinaryExpr{Left: &parser.IdentExpr{Name: dir.Name}, Operator: "-", Right: bound})
+	if diff.IsError() {
+		return fmt.Errorf("cannot compare %s with its bound: %s", dir.Name, diff.Error)
+	}
+	if (relation == "at least" && diff.Number < 0) || (relation == "at most" && diff.Number > 0) {
+		return fmt.Errorf("%s must be %s %s", dir.Name, relation, repl.Formatter().Format(repl.Env().Eval(bound)))
+	}
 	return nil
 }
 
-func executeAndExit(input string) {
+// loadSettings reads the settings file the REPL uses, warning about values
+// it ignores, or returns the defaults when there is none or it cannot be read.
+func loadSettings(stderr io.Writer) *settings.Settings {
+	path, err := config.Path(config.Settings)
+	if err != nil {
+		return settings.Default()
+	}
+	s, err := settings.Load(path)
+	if err != nil {
+		return settings.Default()
+	}
+	for _, w := range s.LoadWarnings {
+		fmt.Fprintf(stderr, "warning: %s\n", w)
+	}
+	return s
+}
+
+// executeExpr evaluates a single -c expression, printing the result to stdout
+// or the error to stderr, and returns the exit code. An unset output level
+// follows the default output setting.
+func executeExpr(input string, clock evaluator.Clock, also, steps bool, timeout time.Duration, level settings.OutputLevel, stdout, stderr io.Writer) int {
 	// Create environment first
 	env := evaluator.NewEnvironment()
+	env.SetClock(clock)
 	
 	// Create lexer and tokenise input
-	l := lexer.New(input)
+	l := lexer.NewWithUnits(input, env.IsUnit)
 	// Hook up constants checker
 	l.SetConstantChecker(env.Constants().IsConstant)
 	tokens := l.AllTokens()
 
-	// Load settings to get locale preference
-	s := settings.Default()
+	// The REPL's settings, so the result reads as it would there
+	s := loadSettings(stderr)
+	env.SetRounding(rounding.Mode(s.Rounding))
+	env.SetDecimal(s.Decimal)
+	env.SetStrict(s.Strict)
+	env.SetMonthOverflow(s.MonthArithmetic == "overflow")
+	if level != 0 {
+		s.Output = level.String()
+	}
+	level = s.OutputLevel()
 
 	// Parse tokens into AST
 	p := parser.NewWithLocale(tokens, s.Locale)
+	p.SetIngredientChecker(env.Units().IsIngredient)
+	p.SetLocationChecker(env.Timezones().IsLocation)
 	expr, err := p.Parse()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
 	}
 
-	// Create evaluator and evaluate expression
+	// Create evaluator and evaluate expression, with print placeholders
+	// formatted like the result
+	f := formatter.New(s)
+	env.SetFormatFunc(f.FormatPlaces)
 	eval := evaluator.New(env)
-	result := eval.Eval(expr)
+	ctx, cancel := withTimeout(timeout)
+	defer cancel()
+	start := time.Now()
+	result := eval.EvalContext(ctx, expr)
+	took := time.Since(start)
 
 	// Format and print result
-	f := formatter.New(s)
 	output := f.Format(result)
 
 	if result.IsError() {
-		fmt.Fprintf(os.Stderr, "%s\n", output)
-		os.Exit(1)
+		fmt.Fprintf(stderr, "%s\n", output)
+		return 1
+	}
+	if level == settings.OutputSilent {
+		result = withoutWarnings(result)
+	}
+	if level == settings.OutputVerbose {
+		defer fmt.Fprintln(stderr, f.Elapsed(took))
+	}
+	if len(parser.AssignedNames(expr)) > 0 && level < settings.OutputNormal {
+		// Assignments print nothing below the normal level, but keep their warnings
+		for _, w := range result.Warnings() {
+			fmt.Fprintf(stderr, "warning: %s\n", w)
+		}
+		return 0
+	}
+
+	if !printResultLines(stdout, stderr, f, result) {
+		return 1
+	}
+	printNotes(stdout, resultNotes(f, result, also, steps, level), false, 0)
+	return 0
+}
+
+// withTimeout returns a context that is done after timeout, or never when
+// timeout is zero.
+func withTimeout(timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), timeout)
+}
+
+// resultNotes returns the lines printed under a result: the steps of its
+// conversion chain for --show-steps, its companion units for --also, and at
+// the verbose output level also the exchange rates behind it.
+func resultNotes(f *formatter.Formatter, v evaluator.Value, also, steps bool, level settings.OutputLevel) []string {
+	var notes []string
+	if level == settings.OutputVerbose {
+		notes = f.RateNotes(v)
+	}
+	if steps {
+		if line := f.Steps(v); line != "" {
+			notes = append(notes, line)
+		}
+	}
+	if also || level == settings.OutputVerbose {
+		if line := f.Also(v); line != "" {
+			notes = append(notes, line)
+		}
+	}
+	return notes
+}
+
+// printNotes prints the lines from resultNotes, indented under the result
+// when echoing.
+func printNotes(w io.Writer, notes []string, echoing bool, width int) {
+	for _, note := range notes {
+		if echoing {
+			note = strings.Repeat(" ", width+3) + note
+		}
+		fmt.Fprintln(w, note)
+	}
+}
+
+// withoutWarnings returns v without its warnings, which the silent output
+// level does not print.
+func withoutWarnings(v evaluator.Value) evaluator.Value {
+	v.Warning = ""
+	if len(v.Items) > 0 {
+		items := make([]evaluator.Value, len(v.Items))
+		for i, item := range v.Items {
+			item.Warning = ""
+			items[i] = item
+		}
+		v.Items = items
 	}
+	return v
+}
 
-	fmt.Println(output)
+// printEcho prints an input line with its result, as the REPL would show it.
+// Errors and warnings appear inline on stdout rather than on stderr, and list
+// results continue on following lines aligned under the first.
+func printEcho(w io.Writer, f *formatter.Formatter, input string, width int, v evaluator.Value) {
+	if v.IsError() && v.Error == "" {
+		// Commands print nothing; quiet assignments still show their input
+		if !strings.HasPrefix(input, ":") {
+			fmt.Fprintln(w, input)
+		}
+		return
+	}
+	results := f.FormatLines(v)
+	if len(results) == 0 {
+		results = []string{f.Format(v)}
+	}
+	for _, w := range v.Warnings() {
+		results = append(results, "warning: "+w)
+	}
+	fmt.Fprintf(w, "%-*s = %s\n", width, input, results[0])
+	indent := strings.Repeat(" ", width+3)
+	for _, r := range results[1:] {
+		fmt.Fprintln(w, indent+r)
+	}
+}
+
+// hasFailedItem reports whether a list result contains an error.
+func hasFailedItem(v evaluator.Value) bool {
+	for _, item := range v.Items {
+		if item.IsError() {
+			return true
+		}
+	}
+	return false
+}
+
+// printResultLines prints a result to stdout, one line per item for lists so shell
+// scripts can split them. Failed items and warnings are reported on stderr; it returns
+// false if any item failed.
+func printResultLines(stdout, stderr io.Writer, f *formatter.Formatter, v evaluator.Value) bool {
+	for _, w := range v.Warnings() {
+		fmt.Fprintf(stderr, "warning: %s\n", w)
+	}
+	if v.Type != evaluator.ValueList {
+		fmt.Fprintln(stdout, f.Format(v))
+		return true
+	}
+	lines := f.FormatLines(v)
+	for i, item := range v.Items {
+		if item.IsError() {
+			fmt.Fprintln(stderr, lines[i])
+			continue
+		}
+		fmt.Fprintln(stdout, lines[i])
+	}
+	return !hasFailedItem(v)
 }
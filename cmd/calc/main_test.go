@@ -0,0 +1,660 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/andrewneudegg/calc/pkg/formatter"
+)
+
+// runCalc runs the program with isolated settings and returns its exit code and output.
+func runCalc(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func writeScript(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "script.calc")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	return path
+}
+
+const failingScript = "x = 5\nx * 2\n1 +\n# comment\ny + 1\nx + 1\n"
+
+func TestFileExitCodes(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		flags   []string
+		code    int
+		stdout  string
+		summary string
+	}{
+		{
+			name:   "clean script",
+			script: "x = 5\nx * 2\n",
+			code:   0,
+			stdout: "5.00\n10.00\n",
+		},
+		{
+			name:    "failures are summarised",
+			script:  failingScript,
+			code:    1,
+			stdout:  "5.00\n10.00\n6.00\n",
+			summary: "2 of 5 lines failed: lines 3, 5",
+		},
+		{
+			name:    "fail fast stops at the first error",
+			script:  failingScript,
+			flags:   []string{"--fail-fast"},
+			code:    1,
+			stdout:  "5.00\n10.00\n",
+			summary: "1 of 3 lines failed: line 3 (stopped at the first failure)",
+		},
+		{
+			name:   "quiet errors keeps the old behaviour",
+			script: failingScript,
+			flags:  []string{"--quiet-errors"},
+			code:   0,
+			stdout: "5.00\n10.00\n6.00\n",
+		},
+		{
+			name:    "failed list items count",
+			script:  "10 m in cm, kg\n",
+			code:    1,
+			stdout:  "1,000.00 cm\n",
+			summary: "1 of 1 lines failed: line 1",
+		},
+		{
+			name:    "failed commands count",
+			script:  ":open no-such-file.json\n2 + 2\n:budget lots\n:workspace switch nowhere\n:set\n",
+			code:    1,
+			stdout:  "4.00\n",
+			summary: "3 of 5 lines failed: lines 1, 3, 4",
+		},
+		{
+			name:    "fail fast stops at a failed command",
+			script:  ":open no-such-file.json\n2 + 2\n",
+			flags:   []string{"--fail-fast", "-v"},
+			code:    1,
+			summary: "1 of 1 lines failed: line 1 (stopped at the first failure)",
+		},
+		{
+			name:    "echo counts inline errors",
+			script:  "1 +\n2 + 2\n",
+			flags:   []string{"--echo"},
+			code:    1,
+			summary: "1 of 2 lines failed: line 1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"-f", writeScript(t, tt.script)}, tt.flags...)
+			code, stdout, stderr := runCalc(t, "", args...)
+			if code != tt.code {
+				t.Errorf("exit code = %d, want %d (stderr %q)", code, tt.code, stderr)
+			}
+			if tt.stdout != "" && stdout != tt.stdout {
+				t.Errorf("stdout = %q, want %q", stdout, tt.stdout)
+			}
+			if tt.summary == "" && strings.Contains(stderr, "failed") {
+				t.Errorf("unexpected summary in stderr %q", stderr)
+			}
+			if tt.summary != "" && !strings.HasSuffix(stderr, tt.summary+"\n") {
+				t.Errorf("stderr = %q, want it to end with %q", stderr, tt.summary)
+			}
+		})
+	}
+}
+
+func TestFileErrorsGoToStderr(t *testing.T) {
+	code, stdout, stderr := runCalc(t, "", "-f", writeScript(t, "1 +\n2\n"))
+	if code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+	if stdout != "2.00\n" {
+		t.Errorf("stdout = %q, want only the result", stdout)
+	}
+	if !strings.HasPrefix(stderr, "Error in line 1: `1 +`: ") {
+		t.Errorf("stderr = %q, want the error first, naming its line", stderr)
+	}
+}
+
+func TestFileCommandErrorsGoToStderr(t *testing.T) {
+	_, _, stderr := runCalc(t, "", "-f", writeScript(t, "x = 1\n:open no-such-file.json\n"), "-v")
+	if !strings.Contains(stderr, "Error in line 2: `:open no-such-file.json`: error loading no-such-file.json") {
+		t.Errorf("stderr = %q, want the failed :open with its line", stderr)
+	}
+}
+
+func TestFileFromStdin(t *testing.T) {
+	code, stdout, _ := runCalc(t, "2 + 2\n", "-f", "-")
+	if code != 0 || stdout != "4.00\n" {
+		t.Errorf("got code %d, stdout %q", code, stdout)
+	}
+}
+
+func TestFileThatCannotRun(t *testing.T) {
+	code, _, stderr := runCalc(t, "", "-f", filepath.Join(t.TempDir(), "missing.calc"))
+	if code != 1 || !strings.HasPrefix(stderr, "Error: ") {
+		t.Errorf("got code %d, stderr %q", code, stderr)
+	}
+}
+
+func TestExpressionExitCodes(t *testing.T) {
+	if code, stdout, _ := runCalc(t, "", "-c", "2 + 2"); code != 0 || stdout != "4.00\n" {
+		t.Errorf("got code %d, stdout %q", code, stdout)
+	}
+	if code, _, stderr := runCalc(t, "", "-c", "1 +"); code != 1 || stderr == "" {
+		t.Errorf("got code %d, stderr %q", code, stderr)
+	}
+}
+
+func TestUnknownFlag(t *testing.T) {
+	if code, _, _ := runCalc(t, "", "--no-such-flag"); code != 2 {
+		t.Errorf("exit code = %d, want 2", code)
+	}
+}
+
+func TestFileUsesAliases(t *testing.T) {
+	script := writeScript(t, ":alias define vat = 20%\n:alias define net x = decrease x by vat\nnet(£120)\n50 + vat\n")
+	code, stdout, stderr := runCalc(t, "", "-f", script)
+	if code != 0 || stdout != "£96.00\n60.00\n" {
+		t.Fatalf("exit %d, stdout %q, stderr %q", code, stdout, stderr)
+	}
+}
+
+func TestFileReportsFailedSettings(t *testing.T) {
+	script := writeScript(t, ":set precission 3\n:set precision 20\n1 / 3\n")
+	code, stdout, stderr := runCalc(t, "", "-f", script)
+	if code != 1 || stdout != "0.33\n" {
+		t.Errorf("exit %d, stdout %q", code, stdout)
+	}
+	for _, want := range []string{
+		"Error in line 1: `:set precission 3`: unknown setting: precission; did you mean precision?",
+		"Error in line 2: `:set precision 20`: precision must be a whole number from 0 to 15",
+	} {
+		if !strings.Contains(stderr, want) {
+			t.Errorf("stderr %q, want it to contain %q", stderr, want)
+		}
+	}
+}
+
+func TestFileTags(t *testing.T) {
+	script := writeScript(t, "# March\ncoffee = £3.20 #food\nlunch = $12.70 #food\ntaxi = £15 #travel\ncoffee = £3.50 #food\nsum #food\nsum #travel\n")
+	code, stdout, stderr := runCalc(t, "", "-f", script)
+	if code != 0 || stdout != "£3.20\n$12.70\n£15.00\n£3.50\n£13.50\n£15.00\n" {
+		t.Fatalf("exit %d, stdout %q, stderr %q", code, stdout, stderr)
+	}
+}
+
+func TestFileTagsWithLabels(t *testing.T) {
+	script := writeScript(t, "coffee: £3.20 #food\nlunch: $12.70 #food\ncoffee: £3.50 #food\nsum #food\n")
+	code, stdout, stderr := runCalc(t, "", "-f", script)
+	if code != 0 || stdout != "£3.20\n$12.70\n£3.50\n£13.50\n" {
+		t.Fatalf("exit %d, stdout %q, stderr %q", code, stdout, stderr)
+	}
+}
+
+func TestFileStrictMode(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	script := writeScript(t, "total = rent + food\nrent = 900\nrent + 1\n")
+
+	// Strict, the default: the undefined variables are an error
+	var stdout, stderr bytes.Buffer
+	report, err := executeFile(script, fileOptions{}, strings.NewReader(""), &stdout, &stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.failed) != 1 || report.failed[0] != 1 || len(report.warnings) != 0 {
+		t.Errorf("strict: report = %+v", report)
+	}
+	if !strings.Contains(stderr.String(), "undefined variable: rent") {
+		t.Errorf("strict: stderr = %q", stderr.String())
+	}
+	if stdout.String() != "900.00\n901.00\n" {
+		t.Errorf("strict: stdout = %q", stdout.String())
+	}
+
+	// Not strict: they read as 0, with a warning, and assignment still works
+	script = writeScript(t, ":set strict off\nspend = rent + food\nrent = 900\nrent + 1\n")
+	stdout.Reset()
+	stderr.Reset()
+	report, err = executeFile(script, fileOptions{}, strings.NewReader(""), &stdout, &stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.failed) != 0 {
+		t.Errorf("not strict: failed lines %v", report.failed)
+	}
+	if want := []string{"line 2: undefined variables rent, food treated as 0"}; strings.Join(report.warnings, "|") != want[0] {
+		t.Errorf("not strict: warnings = %q, want %q", report.warnings, want)
+	}
+	if stdout.String() != "0.00\n900.00\n901.00\n" {
+		t.Errorf("not strict: stdout = %q", stdout.String())
+	}
+	if stderr.String() != "warning: undefined variables rent, food treated as 0\n" {
+		t.Errorf("not strict: stderr = %q", stderr.String())
+	}
+}
+
+func TestFileWarningSummary(t *testing.T) {
+	script := writeScript(t, ":set strict off\nx + 1\ny * 2\n")
+	code, _, stderr := runCalc(t, "", "-f", script)
+	if code != 0 {
+		t.Errorf("exit code = %d, want 0", code)
+	}
+	want := "2 warnings:\n  line 2: undefined variable x treated as 0\n  line 3: undefined variable y treated as 0\n"
+	if !strings.HasSuffix(stderr, want) {
+		t.Errorf("stderr = %q, want it to end with %q", stderr, want)
+	}
+}
+
+func TestAlsoFlag(t *testing.T) {
+	_, stdout, _ := runCalc(t, "", "-c", "100 km in miles")
+	if strings.Contains(stdout, "also:") {
+		t.Errorf("without --also: stdout = %q", stdout)
+	}
+	_, stdout, _ = runCalc(t, "", "-c", "100 km in miles", "--also")
+	if !strings.Contains(stdout, "\nalso: 100.00 km") {
+		t.Errorf("with --also: stdout = %q", stdout)
+	}
+
+	script := writeScript(t, "5 kg\n3 * 4\n")
+	_, stdout, _ = runCalc(t, "", "-f", script, "--also")
+	if stdout != "5.00 kg\nalso: 11.02 lb · 0.79 st\n12.00\n" && stdout != "5.00 kg\nalso: 11.02 lb | 0.79 st\n12.00\n" {
+		t.Errorf("file with --also: stdout = %q", stdout)
+	}
+}
+
+func TestShowStepsFlag(t *testing.T) {
+	_, stdout, _ := runCalc(t, "", "-c", "5 miles in km in m")
+	if stdout != "8,046.72 m\n" {
+		t.Errorf("without --show-steps: stdout = %q", stdout)
+	}
+	_, stdout, _ = runCalc(t, "", "-c", "5 miles in km in m", "--show-steps")
+	if stdout != "8,046.72 m\nsteps: 5.00 miles = 8.05 km = 8,046.72 m\n" {
+		t.Errorf("with --show-steps: stdout = %q", stdout)
+	}
+
+	// The built-in rates are fixed, 1 GBP = 1.27 USD
+	script := writeScript(t, "£100 in usd in jpy\n3 * 4\n")
+	_, stdout, _ = runCalc(t, "", "-f", script, "--show-steps")
+	want := "¥18,955\nsteps: £100.00 = $127.00 = ¥18,955\n12.00\n"
+	if stdout != want && stdout != formatter.ToASCII(want) {
+		t.Errorf("file with --show-steps: stdout = %q", stdout)
+	}
+}
+
+func TestZeroDecimalCurrenciesPrintWhole(t *testing.T) {
+	_, stdout, _ := runCalc(t, "", "-c", "split ¥1000 in ratio 1:2")
+	if want := "¥333\n¥667\n"; stdout != want && stdout != formatter.ToASCII(want) {
+		t.Errorf("split ¥1000 in ratio 1:2: stdout = %q, want %q", stdout, want)
+	}
+}
+
+func TestScriptReaderDropsCarriageReturns(t *testing.T) {
+	script := newScriptReader(strings.NewReader("a = 10 m\r\n# note\r\n\r\nb\n"))
+	var got []string
+	for {
+		ln, ok := script.next()
+		if !ok {
+			break
+		}
+		got = append(got, ln)
+	}
+	want := []string{"a = 10 m", "# note", "", "b"}
+	if strings.Join(got, "|") != strings.Join(want, "|") || script.err() != nil {
+		t.Errorf("lines = %q, want %q (err %v)", got, want, script.err())
+	}
+}
+
+func TestCRLFScript(t *testing.T) {
+	script := writeScript(t, "# Distances\r\nd = 10 m\r\nd in cm\r\n")
+	code, stdout, stderr := runCalc(t, "", "-f", script, "--echo")
+	if code != 0 || stderr != "" {
+		t.Fatalf("exit code = %d, stderr = %q", code, stderr)
+	}
+	if strings.Contains(stdout, "\r") || !strings.Contains(stdout, "# Distances\n") || !strings.Contains(stdout, "1,000.00 cm") {
+		t.Errorf("stdout = %q", stdout)
+	}
+}
+
+func TestListFunctions(t *testing.T) {
+	code, stdout, _ := runCalc(t, "", "--list-functions")
+	if code != 0 {
+		t.Fatalf("exit code = %d", code)
+	}
+	for _, want := range []string{"sum(...)         Sum of all arguments\n", "round(x, y)      "} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("stdout lacks %q:\n%s", want, stdout)
+		}
+	}
+	if strings.Contains(stdout, "(extension)") {
+		t.Errorf("lists extensions in a build without any:\n%s", stdout)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	code, _, stderr := runCalc(t, "", "-c", "1 + 2", "--timeout", "1ns")
+	if code != 1 || !strings.Contains(stderr, "evaluation timed out") {
+		t.Errorf("-c: code %d, stderr %q", code, stderr)
+	}
+
+	script := writeScript(t, "a = 1\nb = 2\n")
+	code, stdout, stderr := runCalc(t, "", "-f", script, "--timeout", "1ns")
+	if code != 1 || stdout != "" || !strings.HasSuffix(stderr, "Error: timed out after 1ns at line 1\n") {
+		t.Errorf("-f: code %d, stdout %q, stderr %q", code, stdout, stderr)
+	}
+
+	code, stdout, _ = runCalc(t, "", "-f", script, "--timeout", "1m")
+	if code != 0 || stdout != "1.00\n2.00\n" {
+		t.Errorf("-f within the timeout: code %d, stdout %q", code, stdout)
+	}
+}
+
+func TestArgUnitHint(t *testing.T) {
+	script := writeScript(t, `:arg distance "Enter distance" unit km
+:arg weight "Weight" unit mass default 2 kg
+distance
+weight
+`)
+	tests := []struct {
+		name       string
+		args       []string
+		stdin      string
+		wantOutput string
+		wantStderr string
+	}{
+		{name: "bare number takes the unit", args: []string{"--arg", "distance=10"}, wantOutput: "10.00 km\n2.00 kg\n"},
+		{name: "other unit is converted", args: []string{"--arg", "distance=1000 m", "--arg", "weight=3"}, wantOutput: "1.00 km\n3.00 kg\n"},
+		{name: "dimension converts to the default's unit", args: []string{"--arg", "distance=1", "--arg", "weight=2 lb"}, wantOutput: "1.00 km\n0.91 kg\n"},
+		{name: "wrong dimension", args: []string{"--arg", "distance=5 kg"}, wantStderr: "distance must be in a unit of length, e.g. 10 km, got 5.00 kg"},
+		{name: "prompt shows an example", stdin: "3\n", wantOutput: "Enter distance (e.g. 10 km): 3.00 km\n2.00 kg\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, stdout, stderr := runCalc(t, tt.stdin, append([]string{"-f", script}, tt.args...)...)
+			if tt.wantStderr != "" {
+				if code == 0 || !strings.Contains(stderr, tt.wantStderr) {
+					t.Errorf("code %d, stderr %q, want %q", code, stderr, tt.wantStderr)
+				}
+				return
+			}
+			if code != 0 || stdout != tt.wantOutput {
+				t.Errorf("code %d, stdout %q, want %q (stderr %q)", code, stdout, tt.wantOutput, stderr)
+			}
+		})
+	}
+
+	bad := writeScript(t, ":arg distance unit parsecs\ndistance\n")
+	if code, _, stderr := runCalc(t, "", "-f", bad, "--arg", "distance=1"); code == 0 || !strings.Contains(stderr, `unknown unit or dimension "parsecs"`) {
+		t.Errorf("unknown hint: code %d, stderr %q", code, stderr)
+	}
+}
+
+func TestServeStaysOnLoopbackWithoutToken(t *testing.T) {
+	tests := []struct {
+		addr, token string
+		ok          bool
+	}{
+		{"127.0.0.1:7436", "", true},
+		{"localhost:7436", "", true},
+		{"[::1]:7436", "", true},
+		{"0.0.0.0:7436", "", false},
+		{":7436", "", false},
+		{"0.0.0.0:7436", "s3cret", true},
+		{"nonsense", "", false},
+	}
+	for _, tt := range tests {
+		if err := checkServeAddr(tt.addr, tt.token); (err == nil) != tt.ok {
+			t.Errorf("checkServeAddr(%q, %q) = %v, want ok %v", tt.addr, tt.token, err, tt.ok)
+		}
+	}
+
+	code, _, stderr := runCalc(t, "", "serve", "--addr", "0.0.0.0:0")
+	if code != 1 || !strings.Contains(stderr, "without --token") {
+		t.Errorf("serve on 0.0.0.0 = %d, %q; want a refusal", code, stderr)
+	}
+}
+
+func TestTimingFlag(t *testing.T) {
+	script := writeScript(t, "# sizes\na = 10 km in miles\nd = a * 2\n")
+	code, stdout, stderr := runCalc(t, "", "-f", script, "--timing", "--timing-threshold", "1ns")
+	if code != 0 {
+		t.Fatalf("exit code = %d, stderr = %q", code, stderr)
+	}
+	// The report goes to stderr, leaving results alone
+	if stdout != "6.21 miles\n12.43 miles\n" {
+		t.Errorf("stdout = %q", stdout)
+	}
+	summary := regexp.MustCompile(`^Timings: 2 lines in \S+ \(lex \S+, parse \S+, eval \S+\), 11 tokens at [0-9]+ tokens/s\n`)
+	if !summary.MatchString(stderr) {
+		t.Errorf("summary missing:\n%s", stderr)
+	}
+	row := `  line [23]\s+\S+  [ad] = .*\n`
+	report := regexp.MustCompile(`Slowest 2 lines:\n(` + row + `){2}Lines over 1ns:\n  line 2 .*\n  line 3 .*\n$`)
+	if !report.MatchString(stderr) {
+		t.Errorf("report rows missing:\n%s", stderr)
+	}
+
+	// Without a threshold low enough, only the slowest lines are listed
+	_, _, stderr = runCalc(t, "", "-f", script, "--timings", "--timing-threshold", "1h")
+	if strings.Contains(stderr, "Lines over") {
+		t.Errorf("lines listed over 1h:\n%s", stderr)
+	}
+}
+
+// tookPattern matches the timings printed at the verbose output level.
+var tookPattern = regexp.MustCompile(`took [0-9.]+(µs|ms|s)`)
+
+// outputLevelScript has a command, an assignment, a warning, a converted
+// currency, a unit result and an error, the things the output levels differ on.
+const outputLevelScript = ":set strict off\nrate = 2\nrate * missing\n£10 in USD\n5 km\n1 +\n"
+
+func TestFileOutputLevels(t *testing.T) {
+	const errors = "Error in line 6: `1 +`: unexpected token: EOF\n"
+	const warnings = "1 warning:\n  line 3: undefined variable missing treated as 0\n"
+	const summary = "1 of 6 lines failed: line 6\n"
+	tests := []struct {
+		name   string
+		flags  []string
+		stdout string
+		stderr string
+	}{
+		{
+			name:   "silent",
+			flags:  []string{"--output", "silent"},
+			stdout: "0.00\n$12.70\n5.00 km\n",
+			stderr: errors + summary,
+		},
+		{
+			name:   "quiet",
+			flags:  []string{"-q"},
+			stdout: "0.00\n$12.70\n5.00 km\n",
+			stderr: "warning: undefined variable missing treated as 0\n" + errors + warnings + summary,
+		},
+		{
+			name:   "normal by default",
+			flags:  nil,
+			stdout: "2.00\n0.00\n$12.70\n5.00 km\n",
+			stderr: "warning: undefined variable missing treated as 0\n" + errors + warnings + summary,
+		},
+		{
+			name:   "verbose",
+			flags:  []string{"-v"},
+			stdout: "2.00\n0.00\n$12.70\nrate: 1 GBP = 1.27 USD\n5.00 km\nalso: 3.11 mi · 16,404.20 ft\n",
+			stderr: "line 2: took T\nwarning: undefined variable missing treated as 0\nline 3: took T\n" +
+				"line 4: took T\nline 5: took T\n" + errors + "line 6: took T\n" + warnings + summary,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			script := writeScript(t, outputLevelScript)
+			code, stdout, stderr := runCalc(t, "", append([]string{"-f", script}, tt.flags...)...)
+			stderr = tookPattern.ReplaceAllString(stderr, "took T")
+			if code != 1 || stdout != tt.stdout || stderr != tt.stderr {
+				t.Errorf("code %d\nstdout %q\nwant   %q\nstderr %q\nwant   %q", code, stdout, tt.stdout, stderr, tt.stderr)
+			}
+		})
+	}
+
+	// :quiet in a script is the quiet level, whatever the flags
+	script := writeScript(t, "x = 2\n:quiet on\ny = 3\nx + y\n")
+	if code, stdout, _ := runCalc(t, "", "-f", script, "-v"); code != 0 || stdout != "2.00\n5.00\n" {
+		t.Errorf(":quiet on after -v: code %d, stdout %q", code, stdout)
+	}
+}
+
+func TestExpressionOutputLevels(t *testing.T) {
+	tests := []struct {
+		level  string
+		expr   string
+		stdout string
+		stderr string
+	}{
+		{"silent", "£10 in USD", "$12.70\n", ""},
+		{"silent", "x = 2", "", ""},
+		{"silent", "1 +", "", "Error: unexpected token: EOF\n"},
+		{"quiet", "£10 in USD", "$12.70\n", ""},
+		{"quiet", "x = 2", "", ""},
+		{"quiet", "1 +", "", "Error: unexpected token: EOF\n"},
+		{"normal", "£10 in USD", "$12.70\n", ""},
+		{"normal", "x = 2", "2.00\n", ""},
+		{"normal", "1 +", "", "Error: unexpected token: EOF\n"},
+		{"verbose", "£10 in USD", "$12.70\nrate: 1 GBP = 1.27 USD\n", "took T\n"},
+		{"verbose", "5 km", "5.00 km\nalso: 3.11 mi · 16,404.20 ft\n", "took T\n"},
+		{"verbose", "x = 2", "2.00\n", "took T\n"},
+		{"verbose", "1 +", "", "Error: unexpected token: EOF\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level+" "+tt.expr, func(t *testing.T) {
+			_, stdout, stderr := runCalc(t, "", "-c", tt.expr, "--output", tt.level)
+			stderr = tookPattern.ReplaceAllString(stderr, "took T")
+			if stdout != tt.stdout || stderr != tt.stderr {
+				t.Errorf("stdout %q, want %q; stderr %q, want %q", stdout, tt.stdout, stderr, tt.stderr)
+			}
+		})
+	}
+
+	// -q and -v are shorthands, and only one level may be given
+	if _, stdout, _ := runCalc(t, "", "-c", "x = 2", "-q"); stdout != "" {
+		t.Errorf("-q: stdout %q, want nothing for an assignment", stdout)
+	}
+	if code, _, stderr := runCalc(t, "", "-c", "1", "-q", "-v"); code != 2 || !strings.Contains(stderr, "only one of -q, -v and --output") {
+		t.Errorf("-q -v: code %d, stderr %q", code, stderr)
+	}
+	if code, _, stderr := runCalc(t, "", "-c", "1", "--output", "loud"); code != 2 || !strings.Contains(stderr, "silent, quiet, normal or verbose") {
+		t.Errorf("--output loud: code %d, stderr %q", code, stderr)
+	}
+}
+
+func TestParseOnly(t *testing.T) {
+	code, stdout, stderr := runCalc(t, "", "--parse-only", "-c", "x = 5 km in m")
+	if code != 0 || stderr != "" {
+		t.Fatalf("exit %d, stderr %q", code, stderr)
+	}
+	var tree struct {
+		Tokens []struct{ Type string }
+		AST    struct {
+			Type     string
+			Literals map[string]any
+		}
+	}
+	if err := json.Unmarshal([]byte(stdout), &tree); err != nil {
+		t.Fatalf("invalid JSON %q: %v", stdout, err)
+	}
+	if len(tree.Tokens) != 6 || tree.AST.Type != "assign" || tree.AST.Literals["name"] != "x" {
+		t.Errorf("unexpected tree %s", stdout)
+	}
+
+	code, stdout, _ = runCalc(t, "", "--parse-only", "-c", "1 +")
+	if code != 1 || !strings.Contains(stdout, `"error":{"message":`) {
+		t.Errorf("exit %d, stdout %q", code, stdout)
+	}
+
+	// Nothing is evaluated, so an error only evaluation finds is not reported
+	if code, _, _ := runCalc(t, "", "--parse-only", "-c", "1 / 0"); code != 0 {
+		t.Errorf("exit %d for 1 / 0, want 0", code)
+	}
+
+	if code, _, stderr := runCalc(t, "", "--parse-only"); code != 2 || stderr == "" {
+		t.Errorf("without -c: exit %d, stderr %q", code, stderr)
+	}
+}
+
+func TestExpressionUsesSettingsFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("CALC_CONFIG_DIR", dir)
+	settingsFile := `{"precision": 4, "trim_zeros": true, "percent_precision": 1, "currency_precision": "auto"}`
+	if err := os.WriteFile(filepath.Join(dir, "settings.json"), []byte(settingsFile), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	exprs := []string{"£12.5 * 1", "¥1234.5 + 0", "22 / 7", "5 km / 2", "1 / 8 as %"}
+	script := writeScript(t, strings.Join(exprs, "\n")+"\n")
+	var fileOut, stderr bytes.Buffer
+	if code := run([]string{"-f", script}, strings.NewReader(""), &fileOut, &stderr); code != 0 {
+		t.Fatalf("-f exit %d: %s", code, stderr.String())
+	}
+	want := "£12.50\n¥1,235\n3.1429\n2.5 km\n12.5%\n"
+	if fileOut.String() != want {
+		t.Errorf("-f stdout = %q, want %q", fileOut.String(), want)
+	}
+
+	var got []string
+	for _, expr := range exprs {
+		var stdout bytes.Buffer
+		if code := run([]string{"-c", expr}, strings.NewReader(""), &stdout, &stderr); code != 0 {
+			t.Fatalf("-c %q exit %d: %s", expr, code, stderr.String())
+		}
+		got = append(got, stdout.String())
+	}
+	if strings.Join(got, "") != fileOut.String() {
+		t.Errorf("-c stdout = %q, want the -f output %q", strings.Join(got, ""), fileOut.String())
+	}
+}
+
+func TestCheck(t *testing.T) {
+	path := writeScript(t, "subtotal = £10\ntotal = subtotal + shipping\nprint(\"{total}\")\n")
+	code, stdout, stderr := runCalc(t, "", "-f", path, "--check")
+	if code != 1 || stdout != "" {
+		t.Errorf("exit %d, stdout %q, want 1 and nothing run", code, stdout)
+	}
+	if want := path + ":2:20: error: undefined variable shipping\n"; stderr != want {
+		t.Errorf("stderr %q, want %q", stderr, want)
+	}
+
+	// An unused :arg is only a warning, and the check never prompts for it
+	path = writeScript(t, ":arg spare \"Spare\"\n10 + 5\n")
+	code, stdout, stderr = runCalc(t, "", "-f", path, "--check")
+	if code != 0 || stdout != "" || !strings.Contains(stderr, ":1:1: warning: :arg spare is never used") {
+		t.Errorf("exit %d, stdout %q, stderr %q", code, stdout, stderr)
+	}
+
+	if code, _, stderr := runCalc(t, "x = 2\nx * 3\n", "-f", "-", "--check"); code != 0 || stderr != "" {
+		t.Errorf("stdin: exit %d, stderr %q", code, stderr)
+	}
+
+	examples, _ := filepath.Glob(filepath.Join("..", "..", "examples", "*.calc"))
+	for _, example := range examples {
+		if code, _, stderr := runCalc(t, "", "-f", example, "--check"); code != 0 || stderr != "" {
+			t.Errorf("%s: exit %d, stderr %q", example, code, stderr)
+		}
+	}
+
+	if code, _, stderr := runCalc(t, "", "--check", "-c", "1 + 1"); code != 2 || !strings.Contains(stderr, "--check needs a script") {
+		t.Errorf("--check without -f: exit %d, stderr %q", code, stderr)
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/andrewneudegg/calc/pkg/evaluator"
+	"github.com/andrewneudegg/calc/pkg/lexer"
+	"github.com/andrewneudegg/calc/pkg/parser"
+	"github.com/andrewneudegg/calc/pkg/settings"
+)
+
+// parseOnly prints the tokens and AST of input as JSON on one line, without
+// evaluating it, for editors that highlight or check a line as it is typed. It exits
+// 1 when the input does not parse, after printing the error as JSON.
+func parseOnly(input string, stdout, stderr io.Writer) int {
+	// Units, constants and ingredients are known as they are for -c, but
+	// nothing is read from or written to the workspace
+	env := evaluator.NewEnvironment()
+	l := lexer.NewWithUnits(input, env.IsUnit)
+	l.SetConstantChecker(env.Constants().IsConstant)
+
+	p := parser.NewWithLocale(l.AllTokens(), settings.Default().Locale)
+	p.SetIngredientChecker(env.Units().IsIngredient)
+	p.SetLocationChecker(env.Timezones().IsLocation)
+	tree := p.ParseTree()
+
+	data, err := json.Marshal(tree)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "%s\n", data)
+	if tree.Error != nil {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/andrewneudegg/calc/pkg/display"
+	"github.com/andrewneudegg/calc/pkg/parser"
+)
+
+// maxScriptLine is the longest line a script may have. Scripts are read a
+// line at a time, so this, not the size of the script, bounds the memory
+// reading one takes.
+const maxScriptLine = 16 << 20
+
+// maxEchoWidth is the widest echoed inputs are padded to. Very long lines
+// are left to overflow rather than push every result right.
+const maxEchoWidth = 40
+
+// scriptReader reads a script a line at a time, however long it is.
+type scriptReader struct {
+	scanner *bufio.Scanner
+	line    int // Number of the line last read, from 1
+}
+
+func newScriptReader(r io.Reader) *scriptReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxScriptLine)
+	return &scriptReader{scanner: scanner}
+}
+
+// next returns the next line, without the carriage return that ends each
+// line of a file saved on Windows. It reports false at the end of the
+// script, or when it cannot be read, which err then explains.
+func (s *scriptReader) next() (string, bool) {
+	ln, ok := s.nextBytes()
+	return string(ln), ok
+}
+
+// nextBytes is next without copying the line, which is only valid until
+// the following call.
+func (s *scriptReader) nextBytes() ([]byte, bool) {
+	if !s.scanner.Scan() {
+		return nil, false
+	}
+	s.line++
+	return bytes.TrimSuffix(s.scanner.Bytes(), []byte("\r")), true
+}
+
+// err returns why reading stopped before the end of the script, if it did.
+func (s *scriptReader) err() error {
+	err := s.scanner.Err()
+	if errors.Is(err, bufio.ErrTooLong) {
+		return fmt.Errorf("line %d is longer than %d MB", s.line+1, maxScriptLine>>20)
+	}
+	return err
+}
+
+// scanScript makes a first pass over a script file for what must be known
+// before its first line runs: its :arg directives, in order, and the width
+// echoed inputs are padded to so that their results line up.
+func scanScript(path string, repl *display.REPL) ([]*parser.ArgDirectiveExpr, int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	var directives []*parser.ArgDirectiveExpr
+	width := 0
+	script := newScriptReader(f)
+	for {
+		ln, ok := script.nextBytes()
+		if !ok {
+			break
+		}
+		input := bytes.TrimSpace(ln)
+		width = max(width, echoInputWidth(input))
+		if !bytes.HasPrefix(input, []byte(":")) {
+			continue
+		}
+		dir, err := argDirective(string(input), repl)
+		if err != nil {
+			return nil, 0, fmt.Errorf("line %d: %v", script.line, err)
+		}
+		if dir != nil {
+			directives = append(directives, dir)
+		}
+	}
+	if err := script.err(); err != nil {
+		return nil, 0, err
+	}
+	return directives, width, nil
+}
+
+// argDirective returns the :arg directive on a trimmed line, or nil when
+// the line is not one. A directive that does not parse, or names an unknown
+// unit, is an error.
+func argDirective(input string, repl *display.REPL) (*parser.ArgDirectiveExpr, error) {
+	// Only commands are parsed, which keeps the scan quick on long scripts
+	if !strings.HasPrefix(input, ":") {
+		return nil, nil
+	}
+	expr, err := parseLineToExpr(input, repl.Env())
+	if err != nil {
+		if strings.HasPrefix(input, ":arg") {
+			return nil, err
+		}
+		return nil, nil
+	}
+	dir, ok := expr.(*parser.ArgDirectiveExpr)
+	if !ok {
+		return nil, nil
+	}
+	if _, _, err := argUnitHint(repl, dir); err != nil {
+		return nil, err
+	}
+	return dir, nil
+}
+
+// echoInputWidth is the room a trimmed line takes when echoed, 0 for lines
+// echoed without a result.
+func echoInputWidth(input []byte) int {
+	if len(input) == 0 || input[0] == '#' || input[0] == ':' {
+		return 0
+	}
+	return min(utf8.RuneCount(input), maxEchoWidth)
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrewneudegg/calc/pkg/display"
+)
+
+// readLineWithin reads a line from r, failing the test if none arrives in
+// time, as it would not if results waited for the whole script.
+func readLineWithin(t *testing.T, r *bufio.Reader, d time.Duration) string {
+	t.Helper()
+	lines := make(chan string, 1)
+	go func() {
+		ln, _ := r.ReadString('\n')
+		lines <- ln
+	}()
+	select {
+	case ln := <-lines:
+		return ln
+	case <-time.After(d):
+		t.Fatal("no output before the script ended")
+		return ""
+	}
+}
+
+func TestFileStreamsStdin(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	scriptIn, scriptOut := io.Pipe()
+	resultsIn, resultsOut := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		_, err := executeFile("-", fileOptions{args: map[string]string{"rate": "4"}}, scriptIn, resultsOut, io.Discard)
+		resultsOut.Close()
+		done <- err
+	}()
+
+	// Each line's result is read before the next line is written
+	results := bufio.NewReader(resultsIn)
+	for _, step := range []struct{ line, want string }{
+		{"x = 2", "2.00\n"},
+		{":arg rate", ""},
+		{"x * rate", "8.00\n"},
+	} {
+		fmt.Fprintln(scriptOut, step.line)
+		if step.want == "" {
+			continue
+		}
+		if got := readLineWithin(t, results, 5*time.Second); got != step.want {
+			t.Fatalf("after %q: got %q, want %q", step.line, got, step.want)
+		}
+	}
+	scriptOut.Close()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFileArgsOnStdin(t *testing.T) {
+	// A default is used, as stdin is the script and cannot be asked
+	code, stdout, stderr := runCalc(t, ":arg n default 3\nn * 2\n", "-f", "-")
+	if code != 0 || stdout != "6.00\n" {
+		t.Errorf("default: exit %d, stdout %q, stderr %q", code, stdout, stderr)
+	}
+
+	code, stdout, stderr = runCalc(t, "1 + 1\n:arg n\nn * 2\n", "-f", "-")
+	if code != 1 || stdout != "2.00\n" || !strings.Contains(stderr, "no value for argument n; pass one with --arg n=<value>") {
+		t.Errorf("missing: exit %d, stdout %q, stderr %q", code, stdout, stderr)
+	}
+}
+
+func TestFileLineTooLong(t *testing.T) {
+	script := writeScript(t, "1 + 1\n"+strings.Repeat("1", maxScriptLine+1)+"\n")
+	code, stdout, stderr := runCalc(t, "", "-f", script)
+	if code != 1 || stdout != "" || !strings.Contains(stderr, "line 2 is longer than 16 MB") {
+		t.Errorf("exit %d, stdout %q, stderr %q", code, stdout, stderr)
+	}
+
+	// Lines well past bufio's default limit of 64 KB are fine
+	script = writeScript(t, "# "+strings.Repeat("note ", 20000)+"\n1 + 1\n")
+	code, stdout, stderr = runCalc(t, "", "-f", script)
+	if code != 0 || stdout != "2.00\n" {
+		t.Errorf("80 KB line: exit %d, stdout %q, stderr %q", code, stdout, stderr)
+	}
+}
+
+// writeLongScript writes a script of n lines that each add to a total.
+func writeLongScript(b *testing.B, n int) string {
+	b.Helper()
+	var buf bytes.Buffer
+	buf.WriteString(":arg start default 1\nx = start\n")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&buf, "x + %d\n", i)
+	}
+	path := filepath.Join(b.TempDir(), "long.calc")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		b.Fatal(err)
+	}
+	return path
+}
+
+// BenchmarkScriptScan compares the memory of scanning a million-line script
+// for its directives a line at a time with reading it whole, as executeFile
+// once did.
+func BenchmarkScriptScan(b *testing.B) {
+	b.Setenv("CALC_CONFIG_DIR", b.TempDir())
+	path := writeLongScript(b, 1_000_000)
+	repl := display.NewREPL()
+
+	b.Run("stream", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, _, err := scanScript(path, repl); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("whole", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				b.Fatal(err)
+			}
+			for _, ln := range strings.Split(string(data), "\n") {
+				if _, err := argDirective(strings.TrimSpace(ln), repl); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+}
+
+// BenchmarkExecuteLongFile runs a million-line script end to end.
+func BenchmarkExecuteLongFile(b *testing.B) {
+	b.Setenv("CALC_CONFIG_DIR", b.TempDir())
+	path := writeLongScript(b, 1_000_000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := executeFile(path, fileOptions{}, strings.NewReader(""), io.Discard, io.Discard); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+
+	"github.com/andrewneudegg/calc/pkg/evaluator"
+	"github.com/andrewneudegg/calc/pkg/server"
+)
+
+// defaultServeAddr is where calc serve listens unless --addr says otherwise.
+const defaultServeAddr = "127.0.0.1:7436"
+
+const serveHelpText = `USAGE:
+	calc serve [options]
+
+Serve calc over HTTP for editor plugins and browser extensions.
+
+	POST /eval      Evaluate {"input": "10 m in cm", "session": "abc"}; session is optional
+	GET  /sessions  List the named sessions
+	GET  /healthz   Report that the server is up
+
+OPTIONS:
+	--addr host:port    Address to listen on (default 127.0.0.1:7436)
+	--token secret      Require this shared secret in the X-Calc-Token header
+	--idle duration     Forget a session after this long without requests (default 30m)
+	--timeout duration  Stop an evaluation after this long (default 5s)
+	--sessions n        Keep at most this many named sessions (default 100)
+	--now time          Fix the current time (RFC 3339)
+
+Listening on an address other than loopback needs --token.
+`
+
+// runServe runs calc serve, serving HTTP until interrupted.
+func runServe(argv []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("calc serve", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, serveHelpText)
+	}
+	addr := fs.String("addr", defaultServeAddr, "Address to listen on")
+	token := fs.String("token", "", "Shared secret required in the X-Calc-Token header")
+	idle := fs.Duration("idle", server.DefaultIdleTimeout, "Forget idle sessions after this long")
+	timeout := fs.Duration("timeout", server.DefaultEvalTimeout, "Stop an evaluation after this long")
+	sessions := fs.Int("sessions", server.DefaultMaxSessions, "Keep at most this many named sessions")
+	nowFlag := fs.String("now", "", "Fix the current time (RFC 3339)")
+	if err := fs.Parse(argv); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(stderr, "Error: unexpected argument %q\n", fs.Arg(0))
+		return 2
+	}
+
+	opts := server.Options{Token: *token, IdleTimeout: *idle, EvalTimeout: *timeout, MaxSessions: *sessions}
+	if *nowFlag != "" {
+		now, err := parseNow(*nowFlag)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		opts.Clock = evaluator.FixedClock(now)
+	}
+	if err := checkServeAddr(*addr, *token); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	ln, err := net.Listen("tcp", *addr)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	fmt.Fprintf(stdout, "calc serving on http://%s (Ctrl-C to stop)\n", ln.Addr())
+	if err := server.New(opts).Serve(ctx, ln); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// checkServeAddr keeps the server to loopback unless a token guards it.
+func checkServeAddr(addr, token string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid --addr %q: %v", addr, err)
+	}
+	if token != "" || host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return fmt.Errorf("refusing to listen on %s without --token; use a loopback address such as %s or set a token", addr, defaultServeAddr)
+}
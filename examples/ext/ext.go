@@ -0,0 +1,59 @@
+// Package ext is an example extension that adds two functions to calc.
+//
+// An extension is a Go package that calls evaluator.Register from init.
+// Linking it into the binary is enough to make its functions callable, and
+// to list them in --list-functions and autocomplete. This one is linked in
+// by cmd/calc/ext.go when calc is built with the ext tag:
+//
+//	go build -tags ext ./cmd/calc
+//	calc -c "margin(£25, £15)"   # 40%
+//	calc -c "hypot(3, 4)"        # 5
+package ext
+
+import (
+	"math"
+
+	"github.com/andrewneudegg/calc/pkg/evaluator"
+)
+
+func init() {
+	mustRegister(evaluator.Function{
+		Name:        "margin",
+		Description: "Profit margin of a price over its cost",
+		MinArgs:     2,
+		MaxArgs:     2,
+		Accepts:     []evaluator.ValueType{evaluator.ValueNumber, evaluator.ValueCurrency},
+		Call:        margin,
+	})
+	mustRegister(evaluator.Function{
+		Name:        "hypot",
+		Description: "Length of the hypotenuse of a right triangle",
+		MinArgs:     2,
+		MaxArgs:     2,
+		Accepts:     []evaluator.ValueType{evaluator.ValueNumber},
+		Call: func(args []evaluator.Value) evaluator.Value {
+			return evaluator.NewNumber(math.Hypot(args[0].Number, args[1].Number))
+		},
+	})
+}
+
+// mustRegister registers f, panicking if its name is taken, as a clash is a
+// mistake in how the binary was put together.
+func mustRegister(f evaluator.Function) {
+	if err := evaluator.Register(f); err != nil {
+		panic(err)
+	}
+}
+
+// margin returns (price - cost) / price as a percentage. The arguments
+// have already been checked to be two numbers or amounts of money.
+func margin(args []evaluator.Value) evaluator.Value {
+	price, cost := args[0], args[1]
+	if price.Currency != cost.Currency {
+		return evaluator.NewError("margin needs a price and cost in the same currency")
+	}
+	if price.Number == 0 {
+		return evaluator.NewError("margin of a zero price")
+	}
+	return evaluator.NewPercent((price.Number - cost.Number) / price.Number * 100)
+}
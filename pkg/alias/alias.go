@@ -0,0 +1,197 @@
+// Package alias expands user-defined shorthands in a line's tokens before it
+// is parsed. An alias is text rather than a value, so it can stand for syntax
+// such as "in usd" as well as for an amount such as 20%.
+package alias
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/andrewneudegg/calc/pkg/lexer"
+)
+
+// MaxDepth limits how many aliases may expand inside one another.
+const MaxDepth = 16
+
+// Alias is a shorthand for Body. An alias with Params is used like a
+// function, net(£100), and each parameter in Body is replaced by its argument.
+type Alias struct {
+	Params []string `json:"params,omitempty"`
+	Body   string   `json:"body"`
+}
+
+// Signature writes the alias as it is defined, e.g. "net(x) = decrease x by vat".
+func (a Alias) Signature(name string) string {
+	if len(a.Params) == 0 {
+		return fmt.Sprintf("%s = %s", name, a.Body)
+	}
+	return fmt.Sprintf("%s(%s) = %s", name, strings.Join(a.Params, ", "), a.Body)
+}
+
+// LexFunc tokenises alias bodies so they are read the same way as the line
+// they are used in.
+type LexFunc func(string) []lexer.Token
+
+// Expand replaces alias names in tokens with the tokens of their bodies.
+// Aliases may use other aliases up to MaxDepth deep; an alias that would
+// expand itself, directly or through others, is reported as a cycle.
+func Expand(tokens []lexer.Token, defs map[string]Alias, lex LexFunc) ([]lexer.Token, error) {
+	if len(defs) == 0 {
+		return tokens, nil
+	}
+	return expand(tokens, defs, lex, nil)
+}
+
+// expand does the work of Expand; chain lists the aliases being expanded.
+func expand(tokens []lexer.Token, defs map[string]Alias, lex LexFunc, chain []string) ([]lexer.Token, error) {
+	out := make([]lexer.Token, 0, len(tokens))
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		name := strings.ToLower(tok.Literal)
+		def, ok := defs[name]
+		if !ok || tok.Type != lexer.TokenIdent {
+			out = append(out, tok)
+			continue
+		}
+		if slices.Contains(chain, name) {
+			return nil, fmt.Errorf("alias cycle: %s -> %s", strings.Join(chain, " -> "), name)
+		}
+		if len(chain) >= MaxDepth {
+			return nil, fmt.Errorf("alias %s expands more than %d aliases deep", chain[0], MaxDepth)
+		}
+
+		body := trimEOF(lex(def.Body))
+		if len(def.Params) > 0 {
+			args, next, err := callArgs(tokens, i+1, name, def)
+			if err != nil {
+				return nil, err
+			}
+			// Arguments belong to the caller, so they expand outside this alias
+			for j, arg := range args {
+				if args[j], err = expand(arg, defs, lex, chain); err != nil {
+					return nil, err
+				}
+			}
+			body = substitute(body, def.Params, args)
+			i = next - 1
+		}
+
+		expanded, err := expand(body, defs, lex, append(slices.Clip(chain), name))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, expanded...)
+	}
+	return out, nil
+}
+
+// callArgs reads the parenthesised, comma-separated arguments of a call to a
+// parameterised alias starting at tokens[start]. It returns the arguments and
+// the index just past the closing parenthesis.
+func callArgs(tokens []lexer.Token, start int, name string, def Alias) ([][]lexer.Token, int, error) {
+	usage := fmt.Errorf("alias %s takes %d argument(s): %s(%s)", name, len(def.Params), name, strings.Join(def.Params, ", "))
+	if start >= len(tokens) || tokens[start].Type != lexer.TokenLParen {
+		return nil, 0, usage
+	}
+	var args [][]lexer.Token
+	var cur []lexer.Token
+	depth := 0
+	for i := start + 1; i < len(tokens); i++ {
+		tok := tokens[i]
+		switch {
+		case tok.Type == lexer.TokenLParen:
+			depth++
+		case tok.Type == lexer.TokenRParen && depth > 0:
+			depth--
+		case tok.Type == lexer.TokenRParen:
+			args = append(args, cur)
+			if len(args) != len(def.Params) || slices.ContainsFunc(args, func(a []lexer.Token) bool { return len(a) == 0 }) {
+				return nil, 0, usage
+			}
+			return args, i + 1, nil
+		case tok.Type == lexer.TokenComma && depth == 0:
+			args = append(args, cur)
+			cur = nil
+			continue
+		}
+		cur = append(cur, tok)
+	}
+	return nil, 0, fmt.Errorf("missing ) after arguments to alias %s", name)
+}
+
+// substitute replaces each parameter in body with its argument. Arguments of
+// more than one token are bracketed so that x * 2 with x = 1 + 2 stays (1 + 2) * 2.
+func substitute(body []lexer.Token, params []string, args [][]lexer.Token) []lexer.Token {
+	var out []lexer.Token
+	for _, tok := range body {
+		idx := -1
+		if tok.Type == lexer.TokenIdent {
+			idx = slices.IndexFunc(params, func(p string) bool { return strings.EqualFold(p, tok.Literal) })
+		}
+		if idx < 0 {
+			out = append(out, tok)
+			continue
+		}
+		arg := args[idx]
+		if len(arg) == 1 {
+			out = append(out, arg[0])
+			continue
+		}
+		out = append(out, lexer.Token{Type: lexer.TokenLParen, Literal: "("})
+		out = append(out, arg...)
+		out = append(out, lexer.Token{Type: lexer.TokenRParen, Literal: ")"})
+	}
+	return out
+}
+
+// Validate checks a new definition of name before it is added to defs: the
+// name and parameters must each be a single word, the body must not be empty
+// and the alias must not lead back to itself.
+func Validate(name string, def Alias, defs map[string]Alias, lex LexFunc) error {
+	if !isWord(name, lex) {
+		return fmt.Errorf("alias name must be a single word that is not a unit or keyword, got %q", name)
+	}
+	for i, p := range def.Params {
+		if !isWord(p, lex) {
+			return fmt.Errorf("parameter must be a single word that is not a unit or keyword, got %q", p)
+		}
+		if strings.EqualFold(p, name) {
+			return fmt.Errorf("parameter %s has the same name as the alias", p)
+		}
+		if slices.ContainsFunc(def.Params[:i], func(q string) bool { return strings.EqualFold(p, q) }) {
+			return fmt.Errorf("parameter %s is used twice", p)
+		}
+	}
+	body := trimEOF(lex(def.Body))
+	if len(body) == 0 {
+		return fmt.Errorf("alias %s needs a body, e.g. :alias define %s = 20%%", name, name)
+	}
+
+	// Expand the body as a use of the new alias would, with each parameter
+	// standing in for an argument
+	trial := make(map[string]Alias, len(defs)+1)
+	for k, v := range defs {
+		trial[k] = v
+	}
+	trial[strings.ToLower(name)] = def
+	args := make([][]lexer.Token, len(def.Params))
+	for i := range args {
+		args[i] = []lexer.Token{{Type: lexer.TokenNumber, Literal: "1"}}
+	}
+	_, err := expand(substitute(body, def.Params, args), trial, lex, []string{strings.ToLower(name)})
+	return err
+}
+
+// isWord reports whether s reads as a single identifier.
+func isWord(s string, lex LexFunc) bool {
+	toks := trimEOF(lex(s))
+	return len(toks) == 1 && toks[0].Type == lexer.TokenIdent && toks[0].Literal == s
+}
+
+func trimEOF(tokens []lexer.Token) []lexer.Token {
+	if n := len(tokens); n > 0 && tokens[n-1].Type == lexer.TokenEOF {
+		return tokens[:n-1]
+	}
+	return tokens
+}
@@ -0,0 +1,132 @@
+package alias
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrewneudegg/calc/pkg/lexer"
+)
+
+func lex(s string) []lexer.Token {
+	return lexer.New(s).AllTokens()
+}
+
+// literals joins the literals of tokens with spaces for comparison.
+func literals(tokens []lexer.Token) string {
+	parts := make([]string, 0, len(tokens))
+	for _, tok := range trimEOF(tokens) {
+		parts = append(parts, tok.Literal)
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestExpand(t *testing.T) {
+	defs := map[string]Alias{
+		"vat":   {Body: "20%"},
+		"net":   {Params: []string{"x"}, Body: "decrease x by vat"},
+		"tousd": {Body: "in usd"},
+		"area":  {Params: []string{"wide", "tall"}, Body: "wide * tall"},
+	}
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"100 + vat", "100 + 20 %"},
+		{"VAT", "20 %"},
+		{"net(120)", "decrease 120 by 20 %"},
+		{"£5 tousd", "£ 5 in usd"},
+		{"area(1 + 2, 3)", "( 1 + 2 ) * 3"},
+		{"net(net(100))", "decrease ( decrease 100 by 20 % ) by 20 %"},
+		{"x + 1", "x + 1"},
+	}
+	for _, tt := range tests {
+		got, err := Expand(trimEOF(lex(tt.input)), defs, lex)
+		if err != nil {
+			t.Fatalf("Expand(%q): %v", tt.input, err)
+		}
+		if literals(got) != tt.want {
+			t.Errorf("Expand(%q) = %q, want %q", tt.input, literals(got), tt.want)
+		}
+	}
+}
+
+func TestExpandErrors(t *testing.T) {
+	defs := map[string]Alias{
+		"foo": {Body: "baz + 1"},
+		"baz": {Body: "foo"},
+		"me":  {Body: "me"},
+		"net": {Params: []string{"x"}, Body: "x"},
+	}
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"foo", "alias cycle: foo -> baz -> foo"},
+		{"me", "alias cycle: me -> me"},
+		{"net", "alias net takes 1 argument(s): net(x)"},
+		{"net(1, 2)", "alias net takes 1 argument(s): net(x)"},
+		{"net(1", "missing ) after arguments to alias net"},
+	}
+	for _, tt := range tests {
+		_, err := Expand(trimEOF(lex(tt.input)), defs, lex)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("Expand(%q) error = %v, want %q", tt.input, err, tt.want)
+		}
+	}
+}
+
+func TestExpandDepthLimit(t *testing.T) {
+	defs := map[string]Alias{}
+	names := make([]string, MaxDepth+2)
+	for i := range names {
+		names[i] = "step" + strings.Repeat("x", i)
+	}
+	for i := 0; i < len(names)-1; i++ {
+		defs[names[i]] = Alias{Body: names[i+1]}
+	}
+	defs[names[len(names)-1]] = Alias{Body: "1"}
+
+	_, err := Expand(trimEOF(lex(names[0])), defs, lex)
+	if err == nil || !strings.Contains(err.Error(), "deep") {
+		t.Fatalf("expected depth error, got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	defs := map[string]Alias{"baz": {Body: "foo"}}
+	tests := []struct {
+		name string
+		def  Alias
+		want string
+	}{
+		{"vat", Alias{Body: "20%"}, ""},
+		{"net", Alias{Params: []string{"x"}, Body: "decrease x by 20%"}, ""},
+		{"two words", Alias{Body: "1"}, "single word"},
+		{"km", Alias{Body: "1"}, "single word"},
+		{"vat", Alias{Body: ""}, "needs a body"},
+		{"fn", Alias{Params: []string{"x", "X"}, Body: "x"}, "used twice"},
+		{"fn", Alias{Params: []string{"fn"}, Body: "1"}, "same name"},
+		{"foo", Alias{Body: "baz"}, "alias cycle: foo -> baz -> foo"},
+	}
+	for _, tt := range tests {
+		err := Validate(tt.name, tt.def, defs, lex)
+		if tt.want == "" {
+			if err != nil {
+				t.Errorf("Validate(%q) = %v, want nil", tt.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Validate(%q) = %v, want error containing %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestSignature(t *testing.T) {
+	if got := (Alias{Body: "20%"}).Signature("vat"); got != "vat = 20%" {
+		t.Errorf("Signature = %q", got)
+	}
+	if got := (Alias{Params: []string{"x", "y"}, Body: "x + y"}).Signature("add"); got != "add(x, y) = x + y" {
+		t.Errorf("Signature = %q", got)
+	}
+}
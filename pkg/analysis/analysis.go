@@ -0,0 +1,323 @@
+// Package analysis checks calc scripts without running them. Each line is
+// lexed and parsed as the REPL would read it, and the variables each line
+// reads and assigns are followed through the script, so that lines that do
+// not parse, variables read before anything gives them a value, misspelt
+// commands and :arg directives whose variable is never used are found
+// before the script is run. Nothing is evaluated, prompted for or saved.
+package analysis
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/andrewneudegg/calc/pkg/alias"
+	"github.com/andrewneudegg/calc/pkg/commands"
+	"github.com/andrewneudegg/calc/pkg/evaluator"
+	"github.com/andrewneudegg/calc/pkg/lexer"
+	"github.com/andrewneudegg/calc/pkg/parser"
+	"github.com/andrewneudegg/calc/pkg/settings"
+	"github.com/andrewneudegg/calc/pkg/units"
+)
+
+// Severity is how much a problem matters.
+type Severity int
+
+const (
+	// Error is a problem that stops the script running as written.
+	Error Severity = iota
+	// Warning is a problem worth a look that does not stop the script.
+	Warning
+)
+
+func (s Severity) String() string {
+	if s == Warning {
+		return "warning"
+	}
+	return "error"
+}
+
+// Problem is something found wrong with a line of a script.
+type Problem struct {
+	Line     int // Line of the script, from 1
+	Column   int // Column in the line, counting characters from 1
+	Severity Severity
+	Message  string
+}
+
+// String writes the problem as its position, severity and message, as in
+// "4:8: error: undefined variable rent".
+func (p Problem) String() string {
+	return fmt.Sprintf("%d:%d: %s: %s", p.Line, p.Column, p.Severity, p.Message)
+}
+
+// HasErrors reports whether any of problems is an error.
+func HasErrors(problems []Problem) bool {
+	for _, p := range problems {
+		if p.Severity == Error {
+			return true
+		}
+	}
+	return false
+}
+
+// budgetWords are the names that read a budget set with :budget.
+var budgetWords = map[string]bool{"remaining": true, "left": true, "spent": true}
+
+// use is a variable read on a line, or declared by an :arg directive.
+type use struct {
+	name         string
+	line, column int
+}
+
+// Checker checks a script a line at a time. Variables are matched ignoring
+// case, as calc matches them.
+type Checker struct {
+	settings *settings.Settings     // A copy of the settings, which the script's :set lines change
+	env      *evaluator.Environment // Units and constants; :unit define adds to its units
+	commands *commands.Handler      // Runs the script's :unit and :ingredient definitions against env
+
+	assigned map[string]int // Line each variable is first assigned on, by lower-cased name
+	args     []use          // Variables declared by :arg directives, in order
+	used     map[string]bool
+	// pending are the reads of variables not yet assigned. They are
+	// resolved at the end, since :arg declares a variable for the whole
+	// script wherever the directive is.
+	pending  []use
+	results  bool // A line has given a result, which _ reads
+	budget   bool // A budget has been set, which remaining, left and spent read
+	unknown  bool // A :open, :load or :workspace line has brought in variables that cannot be known
+	problems []Problem
+}
+
+// New returns a Checker that reads lines with the settings s and the units
+// and constants of env. The script's :set and :alias lines change a copy of
+// s, while its :unit and :ingredient definitions are added to env, which
+// should be one made for the check.
+func New(s *settings.Settings, env *evaluator.Environment) *Checker {
+	copied := *s
+	copied.Aliases = maps.Clone(s.Aliases)
+	c := &Checker{
+		settings: &copied,
+		env:      env,
+		commands: commands.New(&copied),
+		assigned: make(map[string]int),
+		used:     make(map[string]bool),
+	}
+	c.commands.Units = env.Units
+	return c
+}
+
+// Line checks line n of the script, given as written.
+func (c *Checker) Line(n int, text string) {
+	input := strings.TrimSpace(text)
+	if input == "" || strings.HasPrefix(input, "#") {
+		return
+	}
+	tokens := c.lex(text)
+	if len(tokens) > 0 && tokens[len(tokens)-1].Type == lexer.TokenEOF {
+		tokens = tokens[:len(tokens)-1]
+	}
+	if len(tokens) == 0 {
+		return
+	}
+
+	// Tags, aliases and a leading operator are read as the REPL reads them
+	if tokens[0].Type != lexer.TokenColon {
+		if tokens, _ = lexer.SplitTags(tokens); len(tokens) == 0 {
+			return
+		}
+		expanded, err := alias.Expand(tokens, c.settings.Aliases, c.lex)
+		if err != nil {
+			c.report(n, tokens[0].Column, Error, err.Error())
+			return
+		}
+		tokens = continueLast(expanded)
+	}
+
+	p := parser.NewWithLocale(tokens, c.settings.Locale)
+	p.SetImplicitMultiplication(c.settings.ImplicitMul)
+	p.SetVariableChecker(c.isVariable)
+	p.SetIngredientChecker(c.env.Units().IsIngredient)
+	p.SetLocationChecker(c.env.Timezones().IsLocation)
+	p.SetMaxDepth(c.settings.MaxDepth)
+	p.SetInformal(c.settings.InformalUnits)
+	p.SetFew(float64(c.settings.Few))
+	expr, err := p.Parse()
+	if err != nil {
+		c.report(n, p.StopPosition().Column, Error, err.Error())
+		return
+	}
+
+	switch node := expr.(type) {
+	case *parser.CommandExpr:
+		c.command(n, tokens[0].Column, node, text)
+	case *parser.ArgDirectiveExpr:
+		c.argDirective(n, node)
+	default:
+		c.visit(n, expr)
+		c.results = true
+	}
+}
+
+// Problems returns the problems found in the lines checked so far, in the
+// order of the script. Reads of variables that nothing gives a value are
+// errors, and :arg directives whose variable is never read are warnings.
+func (c *Checker) Problems() []Problem {
+	problems := slices.Clone(c.problems)
+	add := func(line, column int, severity Severity, message string) {
+		problems = append(problems, Problem{Line: line, Column: column, Severity: severity, Message: message})
+	}
+	declared := make(map[string]bool, len(c.args))
+	for _, a := range c.args {
+		declared[strings.ToLower(a.name)] = true
+	}
+	for _, r := range c.pending {
+		name := strings.ToLower(r.name)
+		switch line, later := c.assigned[name]; {
+		case declared[name]:
+		case later:
+			add(r.line, r.column, Error, fmt.Sprintf("undefined variable %s; it is first assigned on line %d", r.name, line))
+		default:
+			add(r.line, r.column, Error, fmt.Sprintf("undefined variable %s", r.name))
+		}
+	}
+	for _, a := range c.args {
+		if !c.used[strings.ToLower(a.name)] {
+			add(a.line, a.column, Warning, fmt.Sprintf(":arg %s is never used", a.name))
+		}
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Line != problems[j].Line {
+			return problems[i].Line < problems[j].Line
+		}
+		return problems[i].Column < problems[j].Column
+	})
+	return problems
+}
+
+func (c *Checker) report(line, column int, severity Severity, message string) {
+	c.problems = append(c.problems, Problem{Line: line, Column: column, Severity: severity, Message: message})
+}
+
+// lex tokenises text with the units the script knows at this point.
+func (c *Checker) lex(text string) []lexer.Token {
+	c.env.Units().SetInformal(c.settings.InformalUnits)
+	l := lexer.NewWithUnits(text, c.env.IsUnit)
+	l.SetConstantChecker(c.env.Constants().IsConstant)
+	return l.AllTokens()
+}
+
+// isVariable reports whether name has a value at this point of the script,
+// which decides how implicit multiplication reads it.
+func (c *Checker) isVariable(name string) bool {
+	if _, ok := c.assigned[strings.ToLower(name)]; ok {
+		return true
+	}
+	for _, a := range c.args {
+		if strings.EqualFold(a.name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// visit follows the variables expr reads and assigns on line n. A variable
+// is read before it is assigned, so x = x + 1 reads x first.
+func (c *Checker) visit(n int, expr parser.Expr) {
+	parser.Inspect(expr, func(node parser.Node) bool {
+		switch node := node.(type) {
+		case *parser.IdentExpr:
+			// total alone means total() when no variable has the name
+			if node.Fallback != nil {
+				c.used[strings.ToLower(node.Name)] = true
+				break
+			}
+			c.read(n, node.Name, node.Span.Start.Column)
+		case *parser.AssignExpr:
+			c.visit(n, node.Value)
+			c.assign(n, node.Name)
+			return false
+		case *parser.DestructureExpr:
+			c.visit(n, node.Value)
+			for _, name := range parser.AssignedNames(node) {
+				c.assign(n, name)
+			}
+			return false
+		}
+		return true
+	})
+}
+
+// read notes that line n reads the variable name at column col.
+func (c *Checker) read(n int, name string, col int) {
+	lower := strings.ToLower(name)
+	c.used[lower] = true
+	if _, ok := c.assigned[lower]; ok {
+		return
+	}
+	switch {
+	case lower == "_":
+		if !c.results {
+			c.report(n, col, Error, "_ is read before any line gives a result")
+		}
+	case budgetWords[lower] && c.budget:
+	case c.env.Constants().IsConstant(name):
+	case c.unknown:
+	default:
+		c.pending = append(c.pending, use{name: name, line: n, column: col})
+	}
+}
+
+// assign notes that line n assigns the variable name.
+func (c *Checker) assign(n int, name string) {
+	name = strings.ToLower(name)
+	if _, ok := c.assigned[name]; !ok {
+		c.assigned[name] = n
+	}
+	// budget = £500 sets a budget, as :budget £500 does
+	if name == "budget" {
+		c.budget = true
+	}
+}
+
+// argDirective declares the variable of an :arg directive, whose default
+// and bounds may read others, and checks the unit or dimension it names.
+func (c *Checker) argDirective(n int, dir *parser.ArgDirectiveExpr) {
+	for _, a := range c.args {
+		if strings.EqualFold(a.name, dir.Name) {
+			c.report(n, dir.Span.Start.Column, Error, fmt.Sprintf(":arg %s is already declared on line %d", dir.Name, a.line))
+			return
+		}
+	}
+	for _, expr := range []parser.Expr{dir.Default, dir.Min, dir.Max} {
+		if expr != nil {
+			c.visit(n, expr)
+		}
+	}
+	if dir.Unit != "" {
+		if _, ok := units.ParseDimension(dir.Unit); !ok {
+			if _, err := c.env.Units().GetDimension(dir.Unit); err != nil {
+				c.report(n, dir.Span.Start.Column, Error, fmt.Sprintf("unknown unit or dimension %q in :arg %s", dir.Unit, dir.Name))
+			}
+		}
+	}
+	c.args = append(c.args, use{name: dir.Name, line: n, column: dir.Span.Start.Column})
+}
+
+// continueLast reads a line that starts with +, * or / as carrying on from
+// the last result, as the REPL does, so that "* 1.2" reads _.
+func continueLast(tokens []lexer.Token) []lexer.Token {
+	if len(tokens) < 2 {
+		return tokens
+	}
+	switch first := tokens[0]; first.Type {
+	case lexer.TokenPlus, lexer.TokenMultiply, lexer.TokenDivide:
+		last := lexer.Token{Type: lexer.TokenIdent, Literal: "_", Line: first.Line, Column: first.Column}
+		return append([]lexer.Token{last}, tokens...)
+	}
+	return tokens
+}
@@ -0,0 +1,116 @@
+package analysis
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/andrewneudegg/calc/pkg/evaluator"
+	"github.com/andrewneudegg/calc/pkg/settings"
+)
+
+// checkFile checks testdata/name.calc with the default settings and returns
+// its problems as strings.
+func checkFile(t *testing.T, name string) []string {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", name+".calc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	c := New(settings.Default(), evaluator.NewEnvironment())
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		c.Line(n, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, p := range c.Problems() {
+		got = append(got, p.String())
+	}
+	return got
+}
+
+func TestCheckScripts(t *testing.T) {
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"clean", nil},
+		{"syntax", []string{
+			"1:8: error: unexpected token: EOF",
+			"2:11: error: expected ), got EOF",
+		}},
+		{"undefined", []string{
+			"1:1: error: _ is read before any line gives a result",
+			"2:12: error: undefined variable price; it is first assigned on line 3",
+			"4:25: error: undefined variable shiping",
+			"7:1: error: undefined variable left",
+			"9:9: error: undefined variable d",
+		}},
+		{"args", []string{
+			"4:1: warning: :arg spare is never used",
+			`5:1: error: unknown unit or dimension "furlongz" in :arg distance`,
+			"6:1: error: :arg count is already declared on line 3",
+			"7:6: error: expected variable name after :arg",
+		}},
+		{"commands", []string{
+			"1:1: error: unknown command :sett",
+			`2:1: error: precision must be a whole number from 0 to 15, got "lots"`,
+			"5:1: error: usage: :unit define <name> = <value> <base>, e.g. :unit define rackunit = 44.45 mm",
+			"8:12: error: undefined variable zz",
+			"12:1: error: undefined variable x",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := checkFile(t, tt.name)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("problems:\ngot  %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProblemsIsRepeatable(t *testing.T) {
+	c := New(settings.Default(), evaluator.NewEnvironment())
+	c.Line(1, "total = price * 2")
+	first, second := c.Problems(), c.Problems()
+	if !slices.Equal(first, second) || len(first) != 1 {
+		t.Errorf("Problems() = %v then %v, want the same single problem", first, second)
+	}
+}
+
+func TestCheckLeavesSettingsAlone(t *testing.T) {
+	s := settings.Default()
+	c := New(s, evaluator.NewEnvironment())
+	c.Line(1, ":set precision 5")
+	c.Line(2, ":alias define vat = 20%")
+	c.Line(3, "100 + vat")
+
+	if problems := c.Problems(); len(problems) != 0 {
+		t.Errorf("unexpected problems: %v", problems)
+	}
+	if s.Precision != settings.Default().Precision {
+		t.Errorf("precision = %d, want the settings left unchanged", s.Precision)
+	}
+	if _, ok := s.Aliases["vat"]; ok {
+		t.Error("alias vat was added to the settings given to New")
+	}
+}
+
+func TestHasErrors(t *testing.T) {
+	warning := Problem{Line: 1, Column: 1, Severity: Warning, Message: ":arg x is never used"}
+	if HasErrors([]Problem{warning}) {
+		t.Error("HasErrors reported an error among warnings only")
+	}
+	if !HasErrors([]Problem{warning, {Line: 2, Column: 1, Severity: Error, Message: "undefined variable y"}}) {
+		t.Error("HasErrors missed an error")
+	}
+}
@@ -0,0 +1,131 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/andrewneudegg/calc/pkg/alias"
+	"github.com/andrewneudegg/calc/pkg/commands"
+	"github.com/andrewneudegg/calc/pkg/lexer"
+	"github.com/andrewneudegg/calc/pkg/parser"
+	"github.com/andrewneudegg/calc/pkg/settings"
+)
+
+// command checks a command on line n, starting at column col. Commands
+// that change how later lines read, such as :set locale or :unit define,
+// are applied to the check's own settings and units; the rest are only
+// checked to be commands calc knows.
+func (c *Checker) command(n, col int, cmd *parser.CommandExpr, text string) {
+	if !commands.Known(cmd.Command) {
+		c.report(n, col, Error, fmt.Sprintf("unknown command :%s", cmd.Command))
+		return
+	}
+	var msg string
+	switch name := strings.ToLower(cmd.Command); name {
+	case "set":
+		msg = c.set(cmd.Args)
+	case "alias":
+		msg = c.alias(commandTail(text))
+	case "unit", "ingredient", "ingredients":
+		if len(cmd.Args) > 0 && isDefinition(cmd.Args[0]) {
+			msg = c.commands.Execute(name, cmd.Args)
+		}
+	case "clear", "cls":
+		c.assigned = make(map[string]int)
+		c.results = false
+	case "budget":
+		c.budget = true
+	case "chart":
+		c.chart(n, cmd, text)
+	case "open", "load", "workspace":
+		c.unknown = true
+	}
+	if commands.Failed(msg) {
+		c.report(n, col, Error, strings.TrimPrefix(msg, "error: "))
+	}
+}
+
+// set applies :set to the check's settings, so that a later line reads in
+// the locale or with the implicit multiplication the script asks for.
+func (c *Checker) set(args []string) string {
+	switch len(args) {
+	case 0:
+		return ""
+	case 1:
+		if _, ok := settings.Lookup(args[0]); !ok {
+			return "error: " + settings.UnknownSetting(args[0]).Error()
+		}
+		return ""
+	}
+	if err := c.settings.Set(args[0], strings.Join(args[1:], " ")); err != nil {
+		return "error: " + err.Error()
+	}
+	return ""
+}
+
+// alias applies :alias define and :alias delete to the check's aliases.
+func (c *Checker) alias(tail string) string {
+	fields := strings.Fields(tail)
+	if len(fields) == 0 {
+		return ""
+	}
+	switch strings.ToLower(fields[0]) {
+	case "define":
+		name, def, ok := commands.ParseAliasDefinition(tail[len(fields[0]):])
+		if !ok {
+			return "usage: :alias define <name> [params] = <text>"
+		}
+		if err := alias.Validate(name, def, c.settings.Aliases, lexAlias); err != nil {
+			return "error: " + err.Error()
+		}
+		if c.settings.Aliases == nil {
+			c.settings.Aliases = make(map[string]alias.Alias)
+		}
+		c.settings.Aliases[name] = def
+	case "delete", "remove":
+		if len(fields) == 2 {
+			delete(c.settings.Aliases, strings.ToLower(fields[1]))
+		}
+	}
+	return ""
+}
+
+// chart reads the variables :chart names. Its tags and options read none.
+func (c *Checker) chart(n int, cmd *parser.CommandExpr, text string) {
+	i := strings.Index(text, ":") + 1
+	i += strings.Index(text[i:], cmd.Command) + len(cmd.Command)
+	for _, word := range strings.Fields(text[i:]) {
+		i += strings.Index(text[i:], word)
+		if !strings.HasPrefix(word, "#") && !strings.HasPrefix(word, "--") {
+			c.read(n, word, utf8.RuneCountInString(text[:i])+1)
+		}
+		i += len(word)
+	}
+}
+
+// lexAlias tokenises alias text for validation, as :alias does.
+func lexAlias(s string) []lexer.Token {
+	return lexer.New(s).AllTokens()
+}
+
+// isDefinition reports whether a :unit or :ingredient subcommand defines
+// or deletes one, rather than listing, exporting or importing them.
+func isDefinition(sub string) bool {
+	switch strings.ToLower(sub) {
+	case "define", "delete", "remove":
+		return true
+	}
+	return false
+}
+
+// commandTail returns the text after a command's name, ":alias list" giving
+// "list".
+func commandTail(input string) string {
+	s := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(input), ":"))
+	if i := strings.IndexFunc(s, unicode.IsSpace); i >= 0 {
+		return strings.TrimSpace(s[i:])
+	}
+	return ""
+}
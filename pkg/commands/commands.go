@@ -2,12 +2,18 @@ package commands
 
 import (
 	"fmt"
+	"os"
 	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/andrewneudegg/calc/pkg/alias"
 	"github.com/andrewneudegg/calc/pkg/constants"
+	"github.com/andrewneudegg/calc/pkg/currency"
+	"github.com/andrewneudegg/calc/pkg/lexer"
 	"github.com/andrewneudegg/calc/pkg/settings"
 	"github.com/andrewneudegg/calc/pkg/timezone"
+	"github.com/andrewneudegg/calc/pkg/units"
 )
 
 // Handler handles command execution.
@@ -19,10 +25,42 @@ type Handler struct {
 	SaveWorkspace  func(filename string) error
 	LoadWorkspace  func(filename string) error
 	ClearWorkspace func() error
-	// Quiet mode controls provided by the REPL
-	SetQuiet    func(enabled bool)
-	ToggleQuiet func() bool
-	GetQuiet    func() bool
+	// Units returns the session's unit system for the :unit commands
+	Units func() *units.System
+	// SaveUnits persists the custom units after :unit changes them
+	SaveUnits func() error
+	// Currency returns the session's currency system for :rates
+	Currency func() *currency.System
+	// RateHistory returns the past rates that :rates import adds to
+	RateHistory func() *currency.RateHistory
+	// SaveRateHistory persists the past rates after :rates import
+	SaveRateHistory func() error
+	// Timezones returns the session's timezone system for :tz
+	Timezones func() *timezone.System
+	// SaveLocations persists the locations added with :tz add
+	SaveLocations func() error
+	// Output and SetOutput read and change the session's output level for
+	// :quiet and :set output; provided by the REPL
+	Output    func() settings.OutputLevel
+	SetOutput func(settings.OutputLevel)
+	// Explain runs :explain with the rest of the line; provided by the REPL
+	Explain func(tail string) string
+	// Warnings lists the session's warnings for :warnings; provided by the REPL
+	Warnings func() []string
+	// Tags lists the session's tags with their totals for :tags; provided by the REPL
+	Tags func() []string
+	// Vars lists the session's variables for :vars; provided by the REPL
+	Vars func() []string
+	// Paste starts :paste mode; provided by the REPL
+	Paste func() string
+	// Budget runs :budget with the rest of the line; provided by the REPL
+	Budget func(tail string) string
+	// Tutorial runs :tutorial with its arguments; provided by the REPL
+	Tutorial func(args string) string
+	// Chart runs :chart with the rest of the line; provided by the REPL
+	Chart func(tail string) string
+	// Workspace runs :workspace with its arguments; provided by the REPL
+	Workspace func(args []string) string
 	// shouldQuit is set to true when the quit command is executed
 	shouldQuit bool
 }
@@ -41,6 +79,27 @@ func (h *Handler) ShouldQuit() bool {
 	return h.shouldQuit
 }
 
+// names are the commands Execute runs, and the REPL with it.
+var names = map[string]bool{
+	"save": true, "open": true, "load": true, "set": true, "tz": true, "const": true,
+	"unit": true, "units": true, "ingredient": true, "ingredients": true, "rates": true,
+	"alias": true, "explain": true, "warnings": true, "tags": true, "vars": true,
+	"budget": true, "tutorial": true, "chart": true, "workspace": true, "paste": true,
+	"help": true, "clear": true, "cls": true, "quiet": true, "quit": true, "exit": true, "q": true,
+}
+
+// Failed reports whether msg, the text a command returned, says that it
+// failed: an error, a usage line or an unknown command.
+func Failed(msg string) bool {
+	return strings.HasPrefix(msg, "error") || strings.HasPrefix(msg, "usage: ") || strings.HasPrefix(msg, "unknown ")
+}
+
+// Known reports whether command names a command, ignoring case, so that a
+// script can be checked for misspelt ones without running them.
+func Known(command string) bool {
+	return names[strings.ToLower(command)]
+}
+
 // Execute executes a command and returns a message.
 func (h *Handler) Execute(command string, args []string) string {
 	cmd := strings.ToLower(command)
@@ -56,6 +115,52 @@ func (h *Handler) Execute(command string, args []string) string {
 		return h.timezone_cmd(args)
 	case "const":
 		return h.const_cmd(args)
+	case "unit":
+		return h.unit_cmd(args)
+	case "units":
+		return h.units_cmd(args)
+	case "ingredient", "ingredients":
+		return h.ingredient_cmd(args)
+	case "rates":
+		return h.rates(args)
+	case "alias":
+		return h.Alias(strings.Join(args, " "))
+	case "explain":
+		if h.Explain == nil {
+			return "explain is not supported in this context"
+		}
+		return h.Explain(strings.Join(args, " "))
+	case "warnings":
+		return h.warnings()
+	case "tags":
+		return h.tags()
+	case "vars":
+		return h.vars()
+	case "budget":
+		if h.Budget == nil {
+			return "budget is not supported in this context"
+		}
+		return h.Budget(strings.Join(args, " "))
+	case "tutorial":
+		if h.Tutorial == nil {
+			return "tutorial is not supported in this context"
+		}
+		return h.Tutorial(strings.Join(args, " "))
+	case "chart":
+		if h.Chart == nil {
+			return "chart is not supported in this context"
+		}
+		return h.Chart(strings.Join(args, " "))
+	case "workspace":
+		if h.Workspace == nil {
+			return "workspace is not supported in this context"
+		}
+		return h.Workspace(args)
+	case "paste":
+		if h.Paste == nil {
+			return "paste is not supported in this context"
+		}
+		return h.Paste()
 	case "help":
 		return h.help()
 	case "clear", "cls":
@@ -105,8 +210,15 @@ func (h *Handler) open(args []string) string {
 }
 
 func (h *Handler) set(args []string) string {
-	if len(args) < 2 {
-		return "usage: :set <setting> <value>"
+	if len(args) == 0 {
+		return h.listSettings()
+	}
+	if len(args) == 1 {
+		st, ok := settings.Lookup(args[0])
+		if !ok {
+			return fmt.Sprintf("error: %s", settings.UnknownSetting(args[0]))
+		}
+		return fmt.Sprintf("usage: :set %s %s", st.Key, st.Arg)
 	}
 
 	setting := args[0]
@@ -115,6 +227,10 @@ func (h *Handler) set(args []string) string {
 	if err := h.settings.Set(setting, value); err != nil {
 		return fmt.Sprintf("error: %s", err)
 	}
+	if st, _ := settings.Lookup(setting); st.Key == "output" && h.SetOutput != nil {
+		// The new level also replaces one given for the session by -q, -v or :quiet
+		h.SetOutput(h.settings.OutputLevel())
+	}
 
 	if err := h.settings.Save(); err != nil {
 		return fmt.Sprintf("warning: could not save settings: %s", err)
@@ -123,25 +239,117 @@ func (h *Handler) set(args []string) string {
 	return fmt.Sprintf("set %s = %s", setting, value)
 }
 
+// warnings lists the session's warnings, oldest first.
+func (h *Handler) warnings() string {
+	var list []string
+	if h.Warnings != nil {
+		list = h.Warnings()
+	}
+	if len(list) == 0 {
+		return "no warnings"
+	}
+	return strings.Join(list, "\n")
+}
+
+// tags lists the session's tags, with how many lines each is on and their
+// total.
+func (h *Handler) tags() string {
+	var list []string
+	if h.Tags != nil {
+		list = h.Tags()
+	}
+	if len(list) == 0 {
+		return "no tags (add one to a line, as in coffee = £3.20 #food)"
+	}
+	return strings.Join(list, "\n")
+}
+
+// vars lists the session's variables with their values.
+func (h *Handler) vars() string {
+	var list []string
+	if h.Vars != nil {
+		list = h.Vars()
+	}
+	if len(list) == 0 {
+		return "no variables (assign one, as in rent = £1200)"
+	}
+	return strings.Join(list, "\n")
+}
+
 func (h *Handler) help() string {
 	return `Available commands:
   :save <file>       Save current workspace
   :open <file>       Open a workspace file
   :set <key> <val>   Set a preference
 	:clear             Clear screen and reset current session
-	:quiet [on|off]    Toggle or set quiet mode (suppress assignment output)
+	:quiet [on|off]    Toggle quiet output for this session (:set output quiet to keep it)
+  :tz list           List all timezone locations
+  :tz search <text>  Find timezone locations by name
+  :tz add <name> <zone> Add or correct a location, e.g. :tz add Springfield America/Chicago
   :const list        List all physical constants
   :const show <name> Show details of a specific constant
+  :unit export <file> Save custom units as a shareable pack
+  :unit import <file> Load a units pack (--replace overwrites conflicts)
+  :unit define <name> = <value> <base>  Define a custom unit, e.g. rackunit = 44.45 mm
+  :unit delete <name> Remove a custom unit
+  :unit list         List custom units
+  :units [dimension] List unit dimensions, or every unit in one
+  :units search <text> Find units by name or alias
+  :ingredient define <name> = <density>  Define an ingredient, e.g. cocoa = 0.52 g/ml
+  :ingredient list   List ingredients and their densities
+  :rates show <from> <to> Show the exchange rate used between two currencies
+  :rates import <file.csv> Add past rates (date,from,to,rate) for conversions such as £100 in USD on 15/03/2024
+  :alias define <name> [params] = <text>  Define a shorthand, e.g. vat = 20%
+  :alias list        List aliases
+  :alias delete <name> Remove an alias
+  :explain [on|off]  Toggle a trace of each calculation before its result
+  :explain <expr>    Show the tokens, parse and steps for one calculation
+  :warnings          List warnings, such as undefined variables read as 0 or names shared with units
+  :tags              List tags such as #food with their line counts and totals
+  :vars              List variables, marking names shared with a unit, currency, function or keyword
+  :paste             Evaluate several lines at once; end with a lone .
+  :budget <amount>   Track spending; remaining, left and spent report on it
+  :budget reset      Stop tracking the budget
+  :tutorial [topic]  Start or continue the guided lessons (stop, skip)
+  :tutorial list     List the lessons and which are done
+  :chart <vars|#tag>  Draw variables, a list or a tag's lines as bars (--spark, --csv)
+  :workspace new <name> Start a separate workspace with its own variables and lines
+  :workspace switch <name> Return to a workspace
+  :workspace delete <name> Remove a workspace that is not active
+  :workspace list    List workspaces, marking the active one
   :help              Show this help
   :quit / :exit / :q Exit the program
 
 Available settings:
-  precision <n>         Number of decimal places (default: 2)
-  dateformat <fmt>      Date format string (default: "2 Jan 2006")
-  currency <code>       Default currency code (default: GBP)
-  locale <locale>       Locale for formatting (default: en_GB)
-  fuzzy <on|off>        Enable fuzzy phrase parsing (default: on)
-  autocomplete <on|off> Enable autocomplete suggestions (default: on)`
+` + settingsHelp()
+}
+
+// settingsHelp describes each setting for :help, generated from settings.Schema.
+func settingsHelp() string {
+	width := 22
+	for _, st := range settings.Schema() {
+		width = max(width, len(st.Key+" "+st.Arg)+2)
+	}
+	var lines []string
+	for _, st := range settings.Schema() {
+		lines = append(lines, fmt.Sprintf("  %-*s%s (default: %s)", width, st.Key+" "+st.Arg, st.Description, st.Default()))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// listSettings shows every setting with its current value, type and default for a bare :set.
+func (h *Handler) listSettings() string {
+	var b strings.Builder
+	b.WriteString("Current settings (change one with :set <setting> <value>):\n")
+	width := 13
+	for _, st := range settings.Schema() {
+		width = max(width, len(st.Key))
+	}
+	fmt.Fprintf(&b, "  %-*s %-13s %-7s %-13s %s\n", width, "SETTING", "VALUE", "TYPE", "DEFAULT", "DESCRIPTION")
+	for _, st := range settings.Schema() {
+		fmt.Fprintf(&b, "  %-*s %-13s %-7s %-13s %s\n", width, st.Key, st.Value(h.settings), st.Type, st.Default(), st.Description)
+	}
+	return strings.TrimRight(b.String(), "\n")
 }
 
 func (h *Handler) clear() string {
@@ -158,63 +366,91 @@ func (h *Handler) clear() string {
 
 func (h *Handler) timezone_cmd(args []string) string {
 	if len(args) == 0 {
-		return "usage: :tz list"
+		return "usage: :tz list | :tz search <text> | :tz add <name> <IANA zone>"
+	}
+
+	tz := h.timezone
+	if h.Timezones != nil {
+		tz = h.Timezones()
 	}
 
 	subcmd := strings.ToLower(args[0])
 
 	switch subcmd {
 	case "list":
-		locations := h.timezone.ListLocations()
+		locations := tz.ListLocations()
 		result := "Available timezones:\n"
 		for _, loc := range locations {
 			result += fmt.Sprintf("  %s\n", loc)
 		}
 		return result
+	case "search":
+		if len(args) < 2 {
+			return "usage: :tz search <text>"
+		}
+		query := strings.Join(args[1:], " ")
+		found := tz.Search(query)
+		if len(found) == 0 {
+			return fmt.Sprintf("no timezones match %q", query)
+		}
+		var b strings.Builder
+		for _, loc := range found {
+			fmt.Fprintf(&b, "  %-24s %s (UTC%+d)", loc.Name, loc.IanaName, loc.Offset)
+			if loc.Custom {
+				b.WriteString(" [custom]")
+			}
+			b.WriteString("\n")
+		}
+		return b.String()
+	case "add":
+		// The zone comes last, so names of several words need no quotes
+		if len(args) < 3 {
+			return "usage: :tz add <name> <IANA zone>"
+		}
+		name := strings.Trim(strings.Join(args[1:len(args)-1], " "), `"'`)
+		loc, err := tz.AddLocation(name, args[len(args)-1])
+		if err != nil {
+			return fmt.Sprintf("error: %s", err)
+		}
+		msg := fmt.Sprintf("added location %s = %s (UTC%+d)", loc.Name, loc.IanaName, loc.Offset)
+		if h.SaveLocations != nil {
+			if err := h.SaveLocations(); err != nil {
+				return fmt.Sprintf("%s\nwarning: could not save locations: %s", msg, err)
+			}
+		}
+		return msg
 	default:
 		return fmt.Sprintf("unknown timezone command: %s (use :tz list)", subcmd)
 	}
 }
 
+// quiet switches the session between the quiet and normal output levels.
+// It is shorthand for :set output quiet that lasts for the session only, so
+// a script that turns it on leaves later sessions alone.
 func (h *Handler) quiet(args []string) string {
-	// Require REPL to wire quiet controls
-	if h.SetQuiet == nil && h.ToggleQuiet == nil && h.GetQuiet == nil {
+	if h.Output == nil || h.SetOutput == nil {
 		return "quiet mode not supported in this context"
 	}
 
-	// No args: toggle
+	var on bool
 	if len(args) == 0 {
-		var on bool
-		if h.ToggleQuiet != nil {
-			on = h.ToggleQuiet()
-		} else if h.GetQuiet != nil && h.SetQuiet != nil {
-			// Fallback toggle via get/set
-			cur := h.GetQuiet()
-			on = !cur
-			h.SetQuiet(on)
-		}
-		if on {
-			return "quiet: on"
+		on = h.Output() != settings.OutputQuiet
+	} else {
+		switch strings.ToLower(args[0]) {
+		case "on", "true", "1", "yes", "y":
+			on = true
+		case "off", "false", "0", "no", "n":
+			on = false
+		default:
+			return "usage: :quiet [on|off]"
 		}
-		return "quiet: off"
 	}
-
-	// With arg: on/off
-	v := strings.ToLower(args[0])
-	switch v {
-	case "on", "true", "1", "yes", "y":
-		if h.SetQuiet != nil {
-			h.SetQuiet(true)
-		}
+	if on {
+		h.SetOutput(settings.OutputQuiet)
 		return "quiet: on"
-	case "off", "false", "0", "no", "n":
-		if h.SetQuiet != nil {
-			h.SetQuiet(false)
-		}
-		return "quiet: off"
-	default:
-		return "usage: :quiet [on|off]"
 	}
+	h.SetOutput(settings.OutputNormal)
+	return "quiet: off"
 }
 
 func (h *Handler) const_cmd(args []string) string {
@@ -237,6 +473,424 @@ func (h *Handler) const_cmd(args []string) string {
 	}
 }
 
+// rates runs ":rates show GBP USD", printing the rate conversions use, and
+// ":rates import rates.csv", adding past rates for conversions with on <date>.
+func (h *Handler) rates(args []string) string {
+	const usage = "usage: :rates show <from> <to> | :rates import <file.csv>"
+	if len(args) == 2 && strings.EqualFold(args[0], "import") {
+		return h.ratesImport(args[1])
+	}
+	if len(args) != 3 || !strings.EqualFold(args[0], "show") {
+		return usage
+	}
+	if h.Currency == nil {
+		return "rates are not supported in this context"
+	}
+	rate, err := h.Currency().Rate(args[1], args[2])
+	if err != nil {
+		return fmt.Sprintf("error: %s", err)
+	}
+	return rate.Describe()
+}
+
+// ratesImport adds the past rates in a CSV file of date, from, to and rate
+// columns, and saves them for later sessions.
+func (h *Handler) ratesImport(path string) string {
+	if h.RateHistory == nil {
+		return "past rates are not supported in this context"
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Sprintf("error importing rates: %s", err)
+	}
+	defer f.Close()
+	n, err := h.RateHistory().ImportCSV(f)
+	if err != nil {
+		return fmt.Sprintf("error importing rates: %s: %s", path, err)
+	}
+	msg := fmt.Sprintf("imported %s: %d rates, %d days in total", path, n, h.RateHistory().Days())
+	if h.SaveRateHistory == nil {
+		return msg
+	}
+	if err := h.SaveRateHistory(); err != nil {
+		return fmt.Sprintf("%s\nwarning: could not save past rates: %s", msg, err)
+	}
+	return msg
+}
+
+func (h *Handler) unit_cmd(args []string) string {
+	const usage = "usage: :unit define <name> = <value> <base> | :unit delete <name> | :unit list | :unit export <file> | :unit import <file> [--merge|--replace]"
+	if len(args) == 0 || h.Units == nil {
+		return usage
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "list":
+		return h.unitList()
+	case "define":
+		return h.unitDefine(args[1:])
+	case "delete", "remove":
+		if len(args) != 2 {
+			return usage
+		}
+		if !h.Units().RemoveCustomUnit(args[1]) {
+			return fmt.Sprintf("error: no custom unit named %s", args[1])
+		}
+		return h.saveUnits(fmt.Sprintf("deleted unit %s", strings.ToLower(args[1])))
+	}
+
+	if len(args) < 2 {
+		return usage
+	}
+	switch strings.ToLower(args[0]) {
+	case "export":
+		n, err := h.Units().WritePack(args[1])
+		if err != nil {
+			return fmt.Sprintf("error exporting units: %s", err)
+		}
+		return fmt.Sprintf("exported %d custom units to %s", n, args[1])
+	case "import":
+		replace := false
+		for _, opt := range args[2:] {
+			switch opt {
+			case "--replace":
+				replace = true
+			case "--merge":
+				replace = false
+			default:
+				return fmt.Sprintf("unknown option %s (%s)", opt, usage)
+			}
+		}
+		pack, err := units.ReadPack(args[1])
+		if err != nil {
+			return fmt.Sprintf("error importing units: %s", err)
+		}
+		report, err := h.Units().ImportPack(pack, replace)
+		if err != nil {
+			return fmt.Sprintf("error importing units: %s", err)
+		}
+		msg := formatImportReport(args[1], report)
+		if len(report.Added)+len(report.Replaced) == 0 {
+			return msg
+		}
+		return h.saveUnits(msg)
+	default:
+		return usage
+	}
+}
+
+// units_cmd runs :units, listing the dimensions with how many units each
+// has, the units in one dimension, or the units matching a search. Each unit
+// is one line with all of its names.
+func (h *Handler) units_cmd(args []string) string {
+	const usage = "usage: :units | :units <dimension> | :units search <text>"
+	if h.Units == nil {
+		return "units are not supported in this context"
+	}
+	u := h.Units()
+
+	switch {
+	case len(args) == 0:
+		lines := []string{"Unit dimensions:"}
+		for _, d := range u.Dimensions() {
+			n := len(u.UnitsIn(d))
+			noun := "units"
+			if n == 1 {
+				noun = "unit"
+			}
+			lines = append(lines, fmt.Sprintf("  %-12s %d %s", d, n, noun))
+		}
+		lines = append(lines, "Use :units <dimension> to list its units or :units search <text> to find one")
+		return strings.Join(lines, "\n")
+
+	case strings.EqualFold(args[0], "search"):
+		if len(args) < 2 {
+			return usage
+		}
+		query := strings.Join(args[1:], " ")
+		found := u.Search(query)
+		if len(found) == 0 {
+			return fmt.Sprintf("no units match %q", query)
+		}
+		lines := []string{fmt.Sprintf("Units matching %q:", query)}
+		for _, unit := range found {
+			lines = append(lines, h.unitLine(unit, true))
+		}
+		return strings.Join(lines, "\n")
+
+	case len(args) == 1:
+		dim, ok := units.ParseDimension(args[0])
+		if !ok {
+			var names []string
+			for _, d := range u.Dimensions() {
+				names = append(names, d.String())
+			}
+			return fmt.Sprintf("unknown dimension: %s (dimensions: %s)", args[0], strings.Join(names, ", "))
+		}
+		lines := []string{fmt.Sprintf("%s units:", dim)}
+		for _, unit := range u.UnitsIn(dim) {
+			lines = append(lines, h.unitLine(unit, false))
+		}
+		return strings.Join(lines, "\n")
+	}
+	return usage
+}
+
+// unitLine describes a unit for :units as its size in the dimension's base
+// unit followed by all of its names, optionally naming the dimension.
+func (h *Handler) unitLine(unit *units.Unit, withDimension bool) string {
+	var size string
+	switch {
+	case unit.IsScale():
+		size = "scale"
+	case strings.EqualFold(unit.Name, unit.BaseUnit):
+		size = "base unit"
+	default:
+		size = fmt.Sprintf("%g %s", unit.ToBase, unit.BaseUnit)
+	}
+	names := strings.Join(h.Units().Aliases(unit.Name), ", ")
+	if withDimension {
+		names += fmt.Sprintf(" (%s)", unit.Dimension)
+	}
+	if unit.IsCustom {
+		names += " [custom]"
+	}
+	return fmt.Sprintf("  %-16s %s", size, names)
+}
+
+// unitDefine runs :unit define <name> = <value> <base>, where the "=" is
+// optional, replacing any custom unit of the same name.
+func (h *Handler) unitDefine(args []string) string {
+	const usage = "usage: :unit define <name> = <value> <base>, e.g. :unit define rackunit = 44.45 mm"
+	if len(args) == 4 && args[1] == "=" {
+		args = append(args[:1], args[2:]...)
+	}
+	if len(args) != 3 {
+		return usage
+	}
+	name := strings.ToLower(args[0])
+	// Informal units such as pinch lex as units but may be redefined
+	toks := lexAlias(name)
+	word := len(toks) == 2 && toks[0].Literal == name &&
+		(toks[0].Type == lexer.TokenIdent || toks[0].Type == lexer.TokenUnit && units.IsInformalUnit(name))
+	if !word {
+		return fmt.Sprintf("error: unit name must be a single word that is not already a unit or keyword, got %q", args[0])
+	}
+	value, err := strconv.ParseFloat(args[1], 64)
+	if err != nil {
+		return fmt.Sprintf("error: %q is not a number (%s)", args[1], usage)
+	}
+
+	existed := h.Units().IsCustomUnit(name)
+	pack := units.Pack{Version: units.PackVersion, Units: []units.PackUnit{{Name: name, Value: value, Base: args[2]}}}
+	report, err := h.Units().ImportPack(pack, true)
+	if err != nil {
+		return fmt.Sprintf("error: %s", err)
+	}
+	if len(report.Failed) > 0 {
+		return fmt.Sprintf("error: %s", report.Failed[0])
+	}
+	verb := "defined"
+	if existed {
+		verb = "redefined"
+	}
+	return h.saveUnits(fmt.Sprintf("%s unit %s = %g %s", verb, name, value, strings.ToLower(args[2])))
+}
+
+// unitList shows the custom units relative to their dimension's base unit.
+func (h *Handler) unitList() string {
+	custom := h.Units().CustomUnits()
+	if len(custom) == 0 {
+		return "no custom units defined (use :unit define <name> = <value> <base>)"
+	}
+	lines := []string{"Custom units:"}
+	for _, u := range custom {
+		lines = append(lines, fmt.Sprintf("  %s = %g %s (%s)", u.Name, u.ToBase, u.BaseUnit, u.Dimension))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// saveUnits persists the custom units after a change, returning msg or msg
+// with a warning if they could not be saved.
+func (h *Handler) saveUnits(msg string) string {
+	if h.SaveUnits == nil {
+		return msg
+	}
+	if err := h.SaveUnits(); err != nil {
+		return fmt.Sprintf("%s\nwarning: could not save custom units: %s", msg, err)
+	}
+	return msg
+}
+
+// ingredient_cmd runs :ingredient, which defines and lists the ingredients
+// whose densities convert amounts such as 1 cup flour between volume and mass.
+func (h *Handler) ingredient_cmd(args []string) string {
+	const usage = "usage: :ingredient define <name> = <density> g/ml | :ingredient list"
+	if h.Units == nil {
+		return "ingredients are not supported in this context"
+	}
+	if len(args) == 0 || strings.EqualFold(args[0], "list") {
+		return h.ingredientList()
+	}
+	if !strings.EqualFold(args[0], "define") {
+		return usage
+	}
+
+	// :ingredient define cocoa = 0.52 g/ml, where the "=" and the g/ml are optional
+	args = args[1:]
+	if len(args) > 1 && args[1] == "=" {
+		args = append(args[:1], args[2:]...)
+	}
+	if len(args) != 2 && len(args) != 3 {
+		return usage
+	}
+	name := strings.ToLower(args[0])
+	// Informal units such as pinch lex as units but may be redefined
+	toks := lexAlias(name)
+	word := len(toks) == 2 && toks[0].Literal == name &&
+		(toks[0].Type == lexer.TokenIdent || toks[0].Type == lexer.TokenUnit && units.IsInformalUnit(name))
+	if !word {
+		return fmt.Sprintf("error: ingredient name must be a single word that is not a unit or keyword, got %q", args[0])
+	}
+	density, err := strconv.ParseFloat(args[1], 64)
+	if err != nil {
+		return fmt.Sprintf("error: %q is not a number (%s)", args[1], usage)
+	}
+	if len(args) == 3 {
+		// A density in another mass per volume, such as 125 g/cup
+		density, err = h.Units().ConvertCompoundUnit(density, args[2], "g/ml")
+		if err != nil {
+			return fmt.Sprintf("error: density must be a mass per volume such as g/ml: %s", err)
+		}
+	}
+
+	_, existed := h.Units().CustomIngredients()[name]
+	if err := h.Units().DefineIngredient(name, density); err != nil {
+		return fmt.Sprintf("error: %s", err)
+	}
+	verb := "defined"
+	if existed {
+		verb = "redefined"
+	}
+	return h.saveUnits(fmt.Sprintf("%s ingredient %s = %.4g g/ml", verb, name, density))
+}
+
+// ingredientList shows every ingredient with its density, marking the ones
+// defined with :ingredient define.
+func (h *Handler) ingredientList() string {
+	u := h.Units()
+	custom := u.CustomIngredients()
+	lines := []string{"Ingredients (g/ml):"}
+	for _, name := range u.Ingredients() {
+		density, _ := u.Ingredient(name)
+		line := fmt.Sprintf("  %-10s %.4g", name, density)
+		if _, ok := custom[name]; ok {
+			line += " [custom]"
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Alias runs :alias with the rest of the line as typed. Alias bodies are source
+// text, so the REPL passes the raw line rather than the command's tokens.
+func (h *Handler) Alias(tail string) string {
+	const usage = "usage: :alias define <name> [params] = <text> | :alias list | :alias delete <name>"
+	fields := strings.Fields(tail)
+	if len(fields) == 0 {
+		return h.aliasList()
+	}
+
+	switch strings.ToLower(fields[0]) {
+	case "list":
+		return h.aliasList()
+	case "define":
+		name, def, ok := ParseAliasDefinition(tail[len(fields[0]):])
+		if !ok {
+			return usage
+		}
+		if err := alias.Validate(name, def, h.settings.Aliases, lexAlias); err != nil {
+			return fmt.Sprintf("error: %s", err)
+		}
+		if h.settings.Aliases == nil {
+			h.settings.Aliases = make(map[string]alias.Alias)
+		}
+		h.settings.Aliases[name] = def
+		if err := h.settings.Save(); err != nil {
+			return fmt.Sprintf("warning: could not save settings: %s", err)
+		}
+		return "alias " + def.Signature(name)
+	case "delete", "remove":
+		if len(fields) != 2 {
+			return usage
+		}
+		name := strings.ToLower(fields[1])
+		if _, ok := h.settings.Aliases[name]; !ok {
+			return fmt.Sprintf("error: no alias named %s", fields[1])
+		}
+		delete(h.settings.Aliases, name)
+		if err := h.settings.Save(); err != nil {
+			return fmt.Sprintf("warning: could not save settings: %s", err)
+		}
+		return fmt.Sprintf("deleted alias %s", name)
+	default:
+		return usage
+	}
+}
+
+// ParseAliasDefinition reads the text after ":alias define", as in
+// "net x = decrease x by vat", returning the alias's name in lower case and
+// its definition. It reports false when there is no name or no "=".
+func ParseAliasDefinition(text string) (string, alias.Alias, bool) {
+	head, body, ok := strings.Cut(strings.TrimSpace(text), "=")
+	words := strings.Fields(head)
+	if !ok || len(words) == 0 {
+		return "", alias.Alias{}, false
+	}
+	return strings.ToLower(words[0]), alias.Alias{Params: words[1:], Body: strings.TrimSpace(body)}, true
+}
+
+// aliasList shows every alias in name order.
+func (h *Handler) aliasList() string {
+	if len(h.settings.Aliases) == 0 {
+		return "no aliases defined (use :alias define <name> = <text>)"
+	}
+	names := make([]string, 0, len(h.settings.Aliases))
+	for name := range h.settings.Aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	lines := make([]string, len(names))
+	for i, name := range names {
+		lines[i] = "  " + h.settings.Aliases[name].Signature(name)
+	}
+	return "Aliases:\n" + strings.Join(lines, "\n")
+}
+
+// lexAlias tokenises alias text for validation.
+func lexAlias(s string) []lexer.Token {
+	return lexer.New(s).AllTokens()
+}
+
+// formatImportReport summarises a units pack import, listing anything that was not applied.
+func formatImportReport(file string, r units.ImportReport) string {
+	lines := []string{fmt.Sprintf("imported %s: %d added, %d replaced, %d unchanged",
+		file, len(r.Added), len(r.Replaced), len(r.Unchanged))}
+	if len(r.Conflicts) > 0 {
+		lines = append(lines, fmt.Sprintf("%d conflicts kept (use --replace to overwrite):", len(r.Conflicts)))
+		for _, c := range r.Conflicts {
+			lines = append(lines, "  "+c)
+		}
+	}
+	if len(r.Failed) > 0 {
+		lines = append(lines, fmt.Sprintf("%d skipped:", len(r.Failed)))
+		for _, f := range r.Failed {
+			lines = append(lines, "  "+f)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
 func (h *Handler) constList(args []string) string {
 	var consts []*constants.Constant
 
@@ -2,12 +2,16 @@ package commands
 
 import (
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/andrewneudegg/calc/pkg/currency"
 	"github.com/andrewneudegg/calc/pkg/settings"
+	"github.com/andrewneudegg/calc/pkg/units"
 )
 
 func TestSaveWritesSettingsAndMessage(t *testing.T) {
@@ -111,6 +115,27 @@ func TestExecuteTzList(t *testing.T) {
 	}
 }
 
+func TestExecuteTzAddAndSearch(t *testing.T) {
+	h := New(settings.Default())
+	saved := 0
+	h.SaveLocations = func() error { saved++; return nil }
+
+	result := h.Execute("tz", []string{"add", "Port", "Springfield", "America/Chicago"})
+	if !strings.Contains(result, "added location Port Springfield = America/Chicago") || saved != 1 {
+		t.Errorf("add = %q (saved %d times)", result, saved)
+	}
+	if result := h.Execute("tz", []string{"list"}); !strings.Contains(result, "Port Springfield") {
+		t.Errorf("list should include the added location, got: %s", result)
+	}
+	result = h.Execute("tz", []string{"search", "spring"})
+	if !strings.Contains(result, "Port Springfield") || !strings.Contains(result, "[custom]") {
+		t.Errorf("search = %q", result)
+	}
+	if result := h.Execute("tz", []string{"add", "Atlantis", "Atlantic/Atlantis"}); !strings.HasPrefix(result, "error:") || saved != 1 {
+		t.Errorf("adding an unknown zone = %q (saved %d times)", result, saved)
+	}
+}
+
 func TestExecuteSave(t *testing.T) {
 	s := settings.Default()
 	s.ConfigPath = t.TempDir() + "/settings.json"
@@ -191,32 +216,413 @@ func TestExecuteQuietTogglesAndSets(t *testing.T) {
 	s := settings.Default()
 	h := New(s)
 
-	state := false
-	h.GetQuiet = func() bool { return state }
-	h.SetQuiet = func(b bool) { state = b }
-	h.ToggleQuiet = func() bool { state = !state; return state }
+	state := settings.OutputNormal
+	h.Output = func() settings.OutputLevel { return state }
+	h.SetOutput = func(l settings.OutputLevel) { state = l }
 
 	// Toggle with no args
 	out := h.Execute("quiet", nil)
-	if !state || !strings.Contains(out, "quiet: on") {
+	if state != settings.OutputQuiet || !strings.Contains(out, "quiet: on") {
 		t.Fatalf(":quiet should toggle on, got state=%v, out=%q", state, out)
 	}
 
 	// Explicit off
 	out = h.Execute("quiet", []string{"off"})
-	if state || !strings.Contains(out, "quiet: off") {
+	if state != settings.OutputNormal || !strings.Contains(out, "quiet: off") {
 		t.Fatalf(":quiet off should set off, got state=%v, out=%q", state, out)
 	}
 
 	// Explicit on
 	out = h.Execute("quiet", []string{"on"})
-	if !state || !strings.Contains(out, "quiet: on") {
+	if state != settings.OutputQuiet || !strings.Contains(out, "quiet: on") {
 		t.Fatalf(":quiet on should set on, got state=%v, out=%q", state, out)
 	}
 
+	// Toggling from another level turns quiet on
+	state = settings.OutputVerbose
+	h.Execute("quiet", nil)
+	if state != settings.OutputQuiet {
+		t.Fatalf(":quiet from verbose should turn quiet on, got state=%v", state)
+	}
+
+	// :quiet lasts for the session; only :set output is saved
+	if s.Output != "normal" {
+		t.Fatalf(":quiet should leave the output setting alone, got %q", s.Output)
+	}
+
 	// Bad arg
 	out = h.Execute("quiet", []string{"maybe"})
 	if !strings.Contains(out, "usage") {
 		t.Fatalf(":quiet with bad arg should show usage, got %q", out)
 	}
 }
+
+func TestExecuteSetOutputReplacesSessionLevel(t *testing.T) {
+	s := settings.Default()
+	s.ConfigPath = filepath.Join(t.TempDir(), "settings.json")
+	h := New(s)
+	state := settings.OutputQuiet
+	h.Output = func() settings.OutputLevel { return state }
+	h.SetOutput = func(l settings.OutputLevel) { state = l }
+
+	if out := h.Execute("set", []string{"output", "verbose"}); out != "set output = verbose" {
+		t.Fatalf(":set output verbose gave %q", out)
+	}
+	if state != settings.OutputVerbose || s.Output != "verbose" {
+		t.Fatalf("expected the session and setting at verbose, got %v and %q", state, s.Output)
+	}
+	if out := h.Execute("set", []string{"output", "loud"}); !strings.Contains(out, "output must be silent, quiet, normal or verbose") {
+		t.Fatalf("expected an error for an unknown level, got %q", out)
+	}
+	if state != settings.OutputVerbose {
+		t.Fatalf("an invalid level should leave the session alone, got %v", state)
+	}
+}
+
+func TestExecuteSetListsSettings(t *testing.T) {
+	s := settings.Default()
+	s.Precision = 5
+	h := New(s)
+
+	result := h.Execute("set", nil)
+	for _, want := range []string{"change one with :set <setting> <value>", "precision          5", "int", "Number of decimal places", "autocomplete"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("bare :set should contain %q, got:\n%s", want, result)
+		}
+	}
+}
+
+func TestExecuteSetRejectsInvalid(t *testing.T) {
+	s := settings.Default()
+	s.ConfigPath = t.TempDir() + "/settings.json"
+	h := New(s)
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"precission", "3"}, "valid settings: precision"},
+		{[]string{"precission"}, "valid settings: precision"},
+		{[]string{"precision"}, "usage: :set precision <n>"},
+		{[]string{"precision", "-1"}, "from 0 to 15"},
+		{[]string{"currency", "XYZ"}, "unknown currency code"},
+	}
+	for _, tt := range tests {
+		if result := h.Execute("set", tt.args); !strings.Contains(result, tt.want) {
+			t.Errorf("Execute(set, %v) = %q, want %q", tt.args, result, tt.want)
+		}
+	}
+	if s.Precision != 2 || s.Currency != "GBP" {
+		t.Errorf("invalid :set changed settings: %+v", s)
+	}
+}
+
+func TestExecuteUnitImportReportsProblems(t *testing.T) {
+	h := New(settings.Default())
+	if got := h.Execute("unit", []string{"import", "x.json"}); !strings.Contains(got, "usage: :unit") {
+		t.Errorf("without a unit system, got %q", got)
+	}
+
+	sys := units.NewSystem()
+	h.Units = func() *units.System { return sys }
+	dir := t.TempDir()
+
+	future := dir + "/future.json"
+	os.WriteFile(future, []byte(`{"version": 99, "units": []}`), 0644)
+	if got := h.Execute("unit", []string{"import", future}); !strings.Contains(got, "version 99 is newer") {
+		t.Errorf("future version: got %q", got)
+	}
+
+	partial := dir + "/partial.json"
+	os.WriteFile(partial, []byte(`{"version": 1, "units": [{"name": "pallet", "value": 500, "base": "kg"}, {"name": "bogus", "value": 1, "base": "nope"}]}`), 0644)
+	got := h.Execute("unit", []string{"import", partial})
+	for _, want := range []string{"1 added", "1 skipped", "bogus: unknown base unit"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("partial import output missing %q:\n%s", want, got)
+		}
+	}
+
+	if got := h.Execute("unit", []string{"import", partial, "--force"}); !strings.Contains(got, "unknown option --force") {
+		t.Errorf("unknown option: got %q", got)
+	}
+}
+
+func TestAliasDefineListDelete(t *testing.T) {
+	s := settings.Default()
+	s.ConfigPath = filepath.Join(t.TempDir(), "settings.json")
+	h := New(s)
+
+	if got := h.Alias("list"); !strings.Contains(got, "no aliases defined") {
+		t.Fatalf("empty list = %q", got)
+	}
+	if got := h.Alias("define vat = 20%"); got != "alias vat = 20%" {
+		t.Fatalf("define vat = %q", got)
+	}
+	if got := h.Alias("define net x = decrease x by vat"); got != "alias net(x) = decrease x by vat" {
+		t.Fatalf("define net = %q", got)
+	}
+	if got := h.Alias(""); got != "Aliases:\n  net(x) = decrease x by vat\n  vat = 20%" {
+		t.Fatalf("list = %q", got)
+	}
+
+	loaded, err := settings.Load(s.ConfigPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Aliases["vat"].Body != "20%" {
+		t.Fatalf("aliases should be saved with settings, got %+v", loaded.Aliases)
+	}
+
+	if got := h.Alias("delete vat"); got != "deleted alias vat" {
+		t.Fatalf("delete = %q", got)
+	}
+	if got := h.Alias("delete vat"); !strings.HasPrefix(got, "error:") {
+		t.Fatalf("deleting a missing alias = %q", got)
+	}
+}
+
+func TestAliasDefineErrors(t *testing.T) {
+	s := settings.Default()
+	s.ConfigPath = filepath.Join(t.TempDir(), "settings.json")
+	h := New(s)
+	h.Alias("define foo = baz")
+
+	for _, tail := range []string{"define", "define = 1", "define km = 1", "define baz = foo + 1"} {
+		got := h.Alias(tail)
+		if !strings.HasPrefix(got, "error:") && !strings.HasPrefix(got, "usage:") {
+			t.Errorf("Alias(%q) = %q, want an error", tail, got)
+		}
+	}
+	if _, ok := s.Aliases["baz"]; ok {
+		t.Error("a rejected alias should not be stored")
+	}
+}
+
+func TestUnitDefineListDelete(t *testing.T) {
+	s := settings.Default()
+	h := New(s)
+	sys := units.NewSystem()
+	h.Units = func() *units.System { return sys }
+	path := filepath.Join(t.TempDir(), "units.json")
+	h.SaveUnits = func() error { return sys.SaveCustomUnits(path) }
+
+	if got := h.Execute("unit", []string{"define", "rackunit", "=", "44.45", "mm"}); got != "defined unit rackunit = 44.45 mm" {
+		t.Fatalf("define = %q", got)
+	}
+	if got := h.Execute("unit", []string{"define", "rackunit", "50", "mm"}); got != "redefined unit rackunit = 50 mm" {
+		t.Fatalf("redefine = %q", got)
+	}
+	if got := h.Execute("unit", []string{"list"}); got != "Custom units:\n  rackunit = 0.05 m (length)" {
+		t.Fatalf("list = %q", got)
+	}
+
+	saved := units.NewSystem()
+	saved.LoadCustomUnits(path)
+	if !saved.IsCustomUnit("rackunit") {
+		t.Fatal("define should save the custom units")
+	}
+
+	if got := h.Execute("unit", []string{"delete", "rackunit"}); got != "deleted unit rackunit" {
+		t.Fatalf("delete = %q", got)
+	}
+	saved = units.NewSystem()
+	saved.LoadCustomUnits(path)
+	if saved.IsCustomUnit("rackunit") {
+		t.Fatal("delete should save the custom units")
+	}
+}
+
+func TestUnitDefineErrors(t *testing.T) {
+	h := New(settings.Default())
+	sys := units.NewSystem()
+	h.Units = func() *units.System { return sys }
+
+	for _, args := range [][]string{
+		{"define", "km", "=", "3", "m"},
+		{"define", "widget", "=", "lots", "m"},
+		{"define", "widget", "=", "-1", "m"},
+		{"define", "widget", "=", "1", "nosuchunit"},
+		{"define", "widget"},
+		{"delete", "widget"},
+	} {
+		got := h.Execute("unit", args)
+		if !strings.HasPrefix(got, "error:") && !strings.HasPrefix(got, "usage:") {
+			t.Errorf("unit %v = %q, want an error", args, got)
+		}
+	}
+	if len(sys.CustomUnits()) != 0 {
+		t.Errorf("rejected definitions should not be added: %v", sys.CustomUnits())
+	}
+}
+
+func TestRatesShow(t *testing.T) {
+	h := New(settings.Default())
+	cur := currency.NewSystem()
+	h.Currency = func() *currency.System { return cur }
+
+	if got := h.Execute("rates", []string{"show", "GBP", "USD"}); got != "1 GBP = 1.27 USD (built-in rate)" {
+		t.Errorf("unexpected output: %q", got)
+	}
+	if got := h.Execute("rates", []string{"show", "£", "xyz"}); !strings.Contains(got, "unknown currency: XYZ") {
+		t.Errorf("expected an unknown currency error, got %q", got)
+	}
+	for _, args := range [][]string{nil, {"show", "GBP"}, {"list"}} {
+		if got := h.Execute("rates", args); !strings.HasPrefix(got, "usage: :rates show") {
+			t.Errorf("%v: expected usage, got %q", args, got)
+		}
+	}
+}
+
+func TestRatesImport(t *testing.T) {
+	h := New(settings.Default())
+	history := currency.NewRateHistory()
+	saved := 0
+	h.RateHistory = func() *currency.RateHistory { return history }
+	h.SaveRateHistory = func() error { saved++; return nil }
+
+	dir := t.TempDir()
+	good := filepath.Join(dir, "rates.csv")
+	os.WriteFile(good, []byte("date,from,to,rate\n2024-03-15,GBP,USD,1.2734\n2024-03-18,GBP,USD,1.2720\n"), 0644)
+	if got := h.Execute("rates", []string{"import", good}); got != "imported "+good+": 2 rates, 2 days in total" {
+		t.Errorf("unexpected output: %q", got)
+	}
+	if saved != 1 {
+		t.Errorf("expected the history to be saved once, saved %d times", saved)
+	}
+	if _, ok := history.RateOn("USD", "GBP", time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC)); !ok {
+		t.Error("expected the imported rates in the history")
+	}
+
+	bad := filepath.Join(dir, "bad.csv")
+	os.WriteFile(bad, []byte("2024-03-19,GBP,USD,1.27\n19/03/2024,GBP,USD,1.27\n"), 0644)
+	if got := h.Execute("rates", []string{"import", bad}); !strings.Contains(got, "line 2: date must be written as 2024-03-15") {
+		t.Errorf("expected a line error, got %q", got)
+	}
+	if history.Days() != 2 || saved != 1 {
+		t.Errorf("a failed import should change nothing, got %d days and %d saves", history.Days(), saved)
+	}
+	if got := h.Execute("rates", []string{"import", filepath.Join(dir, "missing.csv")}); !strings.HasPrefix(got, "error importing rates:") {
+		t.Errorf("expected an error for a missing file, got %q", got)
+	}
+
+	h.SaveRateHistory = func() error { return errors.New("disk full") }
+	if got := h.Execute("rates", []string{"import", good}); !strings.HasSuffix(got, "warning: could not save past rates: disk full") {
+		t.Errorf("expected a save warning, got %q", got)
+	}
+}
+
+func TestUnitsListsDimensions(t *testing.T) {
+	h := New(settings.Default())
+	u := units.NewSystem()
+	h.Units = func() *units.System { return u }
+
+	msg := h.Execute("units", nil)
+	for _, want := range []string{"length", "volume", "temperature", ":units search"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("expected %q in:\n%s", want, msg)
+		}
+	}
+}
+
+func TestUnitsInDimensionGroupsAliases(t *testing.T) {
+	h := New(settings.Default())
+	u := units.NewSystem()
+	h.Units = func() *units.System { return u }
+	if err := u.AddCustomUnit("rackunit", 44.45, "mm"); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := h.Execute("units", []string{"length"})
+	if !strings.Contains(msg, "m, metre, metres, meter, meters") {
+		t.Errorf("expected metre aliases on one line:\n%s", msg)
+	}
+	if !strings.Contains(msg, "rackunit") || !strings.Contains(msg, "[custom]") {
+		t.Errorf("expected the custom unit to be listed:\n%s", msg)
+	}
+	if strings.Contains(msg, "kg") {
+		t.Errorf("mass unit listed under length:\n%s", msg)
+	}
+
+	if msg := h.Execute("units", []string{"lengths"}); !strings.Contains(msg, "unknown dimension") {
+		t.Errorf("unexpected reply for an unknown dimension: %q", msg)
+	}
+}
+
+func TestUnitsSearch(t *testing.T) {
+	h := New(settings.Default())
+	u := units.NewSystem()
+	h.Units = func() *units.System { return u }
+
+	msg := h.Execute("units", []string{"search", "gall"})
+	if !strings.Contains(msg, "usgal, usgallon, usgallons, gal, gallon, gallons") || !strings.Contains(msg, "(volume)") {
+		t.Errorf("expected gallons in:\n%s", msg)
+	}
+	if msg := h.Execute("units", []string{"search", "zzzz"}); !strings.Contains(msg, "no units match") {
+		t.Errorf("unexpected reply for no matches: %q", msg)
+	}
+}
+
+func TestIngredientDefineList(t *testing.T) {
+	h := New(settings.Default())
+	sys := units.NewSystem()
+	h.Units = func() *units.System { return sys }
+	path := filepath.Join(t.TempDir(), "units.json")
+	h.SaveUnits = func() error { return sys.SaveCustomUnits(path) }
+
+	if got := h.Execute("ingredient", []string{"define", "cocoa", "=", "0.52", "g/ml"}); got != "defined ingredient cocoa = 0.52 g/ml" {
+		t.Fatalf("define = %q", got)
+	}
+	if got := h.Execute("ingredient", []string{"define", "cocoa", "=", "0.5"}); got != "redefined ingredient cocoa = 0.5 g/ml" {
+		t.Fatalf("redefine = %q", got)
+	}
+	if got := h.Execute("ingredient", []string{"define", "oatmeal", "=", "90", "g/cup"}); got != "defined ingredient oatmeal = 0.3804 g/ml" {
+		t.Fatalf("define in g/cup = %q", got)
+	}
+	if got := h.Execute("ingredient", []string{"list"}); !strings.Contains(got, "  cocoa      0.5 [custom]") || !strings.Contains(got, "  flour      0.507\n") {
+		t.Fatalf("list = %q", got)
+	}
+
+	saved := units.NewSystem()
+	saved.LoadCustomUnits(path)
+	if d, ok := saved.Ingredient("cocoa"); !ok || d != 0.5 {
+		t.Fatalf("define should save the ingredient, got %v, %v", d, ok)
+	}
+
+	for _, args := range [][]string{
+		{"define", "cocoa", "=", "lots", "g/ml"},
+		{"define", "cocoa", "=", "-1"},
+		{"define", "cocoa", "=", "1", "km/h"},
+		{"define", "km", "=", "1"},
+		{"define", "cocoa"},
+	} {
+		got := h.Execute("ingredient", args)
+		if !strings.HasPrefix(got, "error:") && !strings.HasPrefix(got, "usage:") {
+			t.Errorf("ingredient %v = %q, want an error", args, got)
+		}
+	}
+}
+
+func TestKnownMatchesExecute(t *testing.T) {
+	h := New(settings.Default())
+	for name := range names {
+		if !Known(strings.ToUpper(name)) {
+			t.Errorf("Known(%q) = false", strings.ToUpper(name))
+		}
+		if msg := h.Execute(name, nil); strings.HasPrefix(msg, "unknown command") {
+			t.Errorf("%s is known but Execute says %q", name, msg)
+		}
+	}
+	if Known("sett") || !strings.HasPrefix(h.Execute("sett", nil), "unknown command") {
+		t.Error("expected sett to be unknown")
+	}
+}
+
+func TestParseAliasDefinition(t *testing.T) {
+	name, def, ok := ParseAliasDefinition(" Net x = decrease x by vat ")
+	if !ok || name != "net" || def.Signature(name) != "net(x) = decrease x by vat" {
+		t.Errorf("got %q, %+v, %v", name, def, ok)
+	}
+	if _, _, ok := ParseAliasDefinition("net decrease x by vat"); ok {
+		t.Error("expected a definition without = to be refused")
+	}
+}
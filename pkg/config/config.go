@@ -0,0 +1,209 @@
+// Package config resolves where calc keeps its files on disk. Every feature
+// that reads or writes its own files asks this package for the path, so the
+// platform conventions and the CALC_CONFIG_DIR override apply everywhere.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// appName is the directory name used under each platform location.
+const appName = "calc"
+
+// Kind classifies a file by how precious it is.
+type Kind int
+
+const (
+	// KindConfig holds user preferences, such as settings.json.
+	KindConfig Kind = iota
+	// KindData holds state the user would miss if deleted, such as saved sessions.
+	KindData
+	// KindCache holds files that can be rebuilt, such as exchange rate caches.
+	KindCache
+)
+
+// String returns the name of the kind.
+func (k Kind) String() string {
+	switch k {
+	case KindConfig:
+		return "config"
+	case KindData:
+		return "data"
+	case KindCache:
+		return "cache"
+	default:
+		return "unknown"
+	}
+}
+
+// File is a file calc manages, with the kind of directory it belongs in.
+type File struct {
+	Name string
+	Kind Kind
+}
+
+// Known files. Add new ones here so they are covered by Migrate.
+var (
+	Settings    = File{Name: "settings.json", Kind: KindConfig}
+	CustomUnits = File{Name: "units.json", Kind: KindConfig}
+	Locations   = File{Name: "locations.json", Kind: KindConfig}
+	RateCache   = File{Name: "rates.json", Kind: KindCache}
+)
+
+// files lists every known file, in migration order.
+var files = []File{Settings, CustomUnits, Locations, RateCache}
+
+// resolver holds the environment that decides the directories, so every
+// platform branch can be exercised in tests.
+type resolver struct {
+	goos   string
+	getenv func(string) string
+	home   string
+}
+
+func defaultResolver() (resolver, error) {
+	home, err := os.UserHomeDir()
+	if err != nil && os.Getenv("CALC_CONFIG_DIR") == "" {
+		return resolver{}, err
+	}
+	return resolver{goos: runtime.GOOS, getenv: os.Getenv, home: home}, nil
+}
+
+// Dir returns the directory for files of the given kind.
+func Dir(kind Kind) (string, error) {
+	r, err := defaultResolver()
+	if err != nil {
+		return "", err
+	}
+	return r.dir(kind), nil
+}
+
+// Path returns the full path of a known file.
+func Path(f File) (string, error) {
+	dir, err := Dir(f.Kind)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, f.Name), nil
+}
+
+// Migrate moves files from the legacy ~/.config/calc directory to their
+// current location, printing a notice for each file moved. Files already
+// present at the new location are left alone, so it only acts once. Nothing
+// is moved when CALC_CONFIG_DIR is set.
+func Migrate(w io.Writer) error {
+	r, err := defaultResolver()
+	if err != nil {
+		return err
+	}
+	return r.migrate(w)
+}
+
+func (r resolver) dir(kind Kind) string {
+	if override := r.getenv("CALC_CONFIG_DIR"); override != "" {
+		switch kind {
+		case KindData:
+			return filepath.Join(override, "data")
+		case KindCache:
+			return filepath.Join(override, "cache")
+		default:
+			return override
+		}
+	}
+
+	switch r.goos {
+	case "darwin":
+		if kind == KindCache {
+			return filepath.Join(r.home, "Library", "Caches", appName)
+		}
+		return filepath.Join(r.home, "Library", "Application Support", appName)
+
+	case "windows":
+		appData := r.getenv("AppData")
+		if appData == "" {
+			appData = filepath.Join(r.home, "AppData", "Roaming")
+		}
+		if kind == KindCache {
+			local := r.getenv("LocalAppData")
+			if local == "" {
+				local = filepath.Join(r.home, "AppData", "Local")
+			}
+			return filepath.Join(local, appName, "cache")
+		}
+		return filepath.Join(appData, appName)
+
+	default:
+		switch kind {
+		case KindData:
+			return r.xdg("XDG_DATA_HOME", ".local", "share")
+		case KindCache:
+			return r.xdg("XDG_CACHE_HOME", ".cache")
+		default:
+			return r.xdg("XDG_CONFIG_HOME", ".config")
+		}
+	}
+}
+
+// xdg resolves an XDG base directory, falling back to the given path under home.
+// Relative values are ignored, as the XDG specification requires.
+func (r resolver) xdg(env string, fallback ...string) string {
+	if base := r.getenv(env); base != "" && filepath.IsAbs(base) {
+		return filepath.Join(base, appName)
+	}
+	return filepath.Join(append(append([]string{r.home}, fallback...), appName)...)
+}
+
+// legacyDir is where calc kept every file before this package existed.
+func (r resolver) legacyDir() string {
+	return filepath.Join(r.home, ".config", appName)
+}
+
+func (r resolver) migrate(w io.Writer) error {
+	// An explicit directory is self-contained; never pull the user's files into it
+	if r.home == "" || r.getenv("CALC_CONFIG_DIR") != "" {
+		return nil
+	}
+	for _, f := range files {
+		from := filepath.Join(r.legacyDir(), f.Name)
+		to := filepath.Join(r.dir(f.Kind), f.Name)
+		if from == to {
+			continue
+		}
+		if _, err := os.Stat(from); err != nil {
+			continue
+		}
+		if _, err := os.Stat(to); err == nil {
+			continue
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(to), 0755); err != nil {
+			return err
+		}
+		if err := moveFile(from, to); err != nil {
+			return fmt.Errorf("moving %s to %s: %w", from, to, err)
+		}
+		fmt.Fprintf(w, "calc: moved %s to %s\n", from, to)
+	}
+	return nil
+}
+
+// moveFile renames a file, copying it when the rename crosses filesystems.
+func moveFile(from, to string) error {
+	if err := os.Rename(from, to); err == nil {
+		return nil
+	}
+	data, err := os.ReadFile(from)
+	if err != nil {
+		return err
+	}
+	if err := writeAtomic(to, data, 0644); err != nil {
+		return err
+	}
+	return os.Remove(from)
+}
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func envMap(m map[string]string) func(string) string {
+	return func(k string) string { return m[k] }
+}
+
+func TestDirPerPlatform(t *testing.T) {
+	home := filepath.FromSlash("/home/ada")
+	tests := []struct {
+		name string
+		goos string
+		env  map[string]string
+		kind Kind
+		want string
+	}{
+		{"linux config default", "linux", nil, KindConfig, "/home/ada/.config/calc"},
+		{"linux data default", "linux", nil, KindData, "/home/ada/.local/share/calc"},
+		{"linux cache default", "linux", nil, KindCache, "/home/ada/.cache/calc"},
+		{"linux XDG config", "linux", map[string]string{"XDG_CONFIG_HOME": "/xdg/config"}, KindConfig, "/xdg/config/calc"},
+		{"linux XDG data", "linux", map[string]string{"XDG_DATA_HOME": "/xdg/data"}, KindData, "/xdg/data/calc"},
+		{"linux XDG cache", "linux", map[string]string{"XDG_CACHE_HOME": "/xdg/cache"}, KindCache, "/xdg/cache/calc"},
+		{"linux relative XDG ignored", "linux", map[string]string{"XDG_CONFIG_HOME": "relative"}, KindConfig, "/home/ada/.config/calc"},
+		{"freebsd follows XDG", "freebsd", nil, KindCache, "/home/ada/.cache/calc"},
+		{"darwin config", "darwin", nil, KindConfig, "/home/ada/Library/Application Support/calc"},
+		{"darwin data", "darwin", nil, KindData, "/home/ada/Library/Application Support/calc"},
+		{"darwin cache", "darwin", nil, KindCache, "/home/ada/Library/Caches/calc"},
+		{"windows config", "windows", map[string]string{"AppData": "/win/Roaming"}, KindConfig, "/win/Roaming/calc"},
+		{"windows cache", "windows", map[string]string{"LocalAppData": "/win/Local"}, KindCache, "/win/Local/calc/cache"},
+		{"windows without env", "windows", nil, KindConfig, "/home/ada/AppData/Roaming/calc"},
+		{"override config", "linux", map[string]string{"CALC_CONFIG_DIR": "/portable", "XDG_CONFIG_HOME": "/xdg"}, KindConfig, "/portable"},
+		{"override data", "darwin", map[string]string{"CALC_CONFIG_DIR": "/portable"}, KindData, "/portable/data"},
+		{"override cache", "windows", map[string]string{"CALC_CONFIG_DIR": "/portable"}, KindCache, "/portable/cache"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := resolver{goos: tt.goos, getenv: envMap(tt.env), home: home}
+			if got := r.dir(tt.kind); got != filepath.FromSlash(tt.want) {
+				t.Errorf("dir(%s) = %q, want %q", tt.kind, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathUsesOverride(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("CALC_CONFIG_DIR", dir)
+
+	settings, err := Path(Settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings != filepath.Join(dir, "settings.json") {
+		t.Errorf("settings path = %q", settings)
+	}
+	rates, err := Path(RateCache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rates != filepath.Join(dir, "cache", "rates.json") {
+		t.Errorf("rate cache path = %q", rates)
+	}
+}
+
+func TestMigrateMovesLegacyFilesOnce(t *testing.T) {
+	home := t.TempDir()
+	legacy := filepath.Join(home, ".config", "calc")
+	if err := os.MkdirAll(legacy, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		if err := os.WriteFile(filepath.Join(legacy, f.Name), []byte(f.Name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := resolver{goos: "darwin", getenv: envMap(nil), home: home}
+	var out bytes.Buffer
+	if err := r.migrate(&out); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	for _, f := range files {
+		to := filepath.Join(r.dir(f.Kind), f.Name)
+		data, err := os.ReadFile(to)
+		if err != nil || string(data) != f.Name {
+			t.Errorf("%s not moved to %s: %v", f.Name, to, err)
+		}
+		if _, err := os.Stat(filepath.Join(legacy, f.Name)); !os.IsNotExist(err) {
+			t.Errorf("%s still present at legacy location", f.Name)
+		}
+	}
+	if strings.Count(out.String(), "moved") != len(files) {
+		t.Errorf("expected a notice per file, got %q", out.String())
+	}
+
+	// A second run has nothing left to do
+	out.Reset()
+	if err := r.migrate(&out); err != nil || out.Len() != 0 {
+		t.Errorf("second migrate: err=%v out=%q", err, out.String())
+	}
+}
+
+func TestMigrateKeepsExistingFiles(t *testing.T) {
+	home := t.TempDir()
+	r := resolver{goos: "linux", getenv: envMap(map[string]string{"XDG_CONFIG_HOME": filepath.Join(home, "xdg")}), home: home}
+
+	legacy := filepath.Join(home, ".config", "calc", "settings.json")
+	current := filepath.Join(r.dir(KindConfig), "settings.json")
+	for path, content := range map[string]string{legacy: "old", current: "new"} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := r.migrate(&out); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(current); string(data) != "new" {
+		t.Errorf("existing file overwritten, got %q", data)
+	}
+	if out.Len() != 0 {
+		t.Errorf("unexpected notice %q", out.String())
+	}
+}
+
+func TestMigrateSkipsWhenLegacyIsCurrent(t *testing.T) {
+	home := t.TempDir()
+	legacy := filepath.Join(home, ".config", "calc", "settings.json")
+	if err := os.MkdirAll(filepath.Dir(legacy), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(legacy, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := resolver{goos: "linux", getenv: envMap(nil), home: home}
+	var out bytes.Buffer
+	if err := r.migrate(&out); err != nil || out.Len() != 0 {
+		t.Errorf("expected no migration on linux defaults: err=%v out=%q", err, out.String())
+	}
+	if _, err := os.Stat(legacy); err != nil {
+		t.Errorf("settings moved unexpectedly: %v", err)
+	}
+}
+
+func TestMigrateSkipsWithOverride(t *testing.T) {
+	home := t.TempDir()
+	legacy := filepath.Join(home, ".config", "calc", "settings.json")
+	if err := os.MkdirAll(filepath.Dir(legacy), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(legacy, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := resolver{goos: "darwin", getenv: envMap(map[string]string{"CALC_CONFIG_DIR": t.TempDir()}), home: home}
+	var out bytes.Buffer
+	if err := r.migrate(&out); err != nil || out.Len() != 0 {
+		t.Errorf("expected no migration with override: err=%v out=%q", err, out.String())
+	}
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"encoding/json"
+	"maps"
+)
+
+// MergeChanges returns the entries of a shared file after applying one
+// calc's changes to what is saved there now. base is what that calc last
+// loaded or saved, ours what it holds now, and theirs what the file holds,
+// perhaps changed by another calc since. Entries ours added or changed
+// since base are taken from ours and ones it removed are removed; the rest
+// are as theirs has them, so another calc's additions survive.
+func MergeChanges[K, V comparable](base, ours, theirs map[K]V) map[K]V {
+	merged := maps.Clone(theirs)
+	if merged == nil {
+		merged = make(map[K]V)
+	}
+	for k, v := range ours {
+		if old, ok := base[k]; !ok || old != v {
+			merged[k] = v
+		}
+	}
+	for k := range base {
+		if _, ok := ours[k]; !ok {
+			delete(merged, k)
+		}
+	}
+	return merged
+}
+
+// UpdateJSON replaces the JSON file at path, which several calcs may share,
+// with what merge makes of its current contents, holding the lock from the
+// read to the write as Update does. merge is given the zero T when there is
+// no file yet, and also when the file is not valid JSON: a damaged file is
+// set aside when it is loaded, so nothing in it is this calc's to keep.
+func UpdateJSON[T any](path string, merge func(theirs T) T) error {
+	return Update(path, func(current []byte) ([]byte, error) {
+		var theirs T
+		if current != nil && json.Unmarshal(current, &theirs) != nil {
+			var zero T
+			theirs = zero
+		}
+		data, err := json.MarshalIndent(merge(theirs), "", "  ")
+		return append(data, '\n'), err
+	})
+}
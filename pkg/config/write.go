@@ -0,0 +1,142 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// lockWait is how long Lock waits for another calc to finish with a file.
+const lockWait = 5 * time.Second
+
+// lockStale is how old a lock must be before it is taken to have been left
+// by a calc that crashed, and broken.
+const lockStale = 30 * time.Second
+
+// WriteFile replaces the file at path with data, creating its directory.
+// The data goes to a temporary file that is renamed over path, so a reader
+// sees the old file or the new one and never a torn or empty one. Writers
+// take turns under Lock; when two calcs save the same file the last one
+// wins, so a file that several calcs add to is saved with Update instead.
+func WriteFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	unlock, err := Lock(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return writeAtomic(path, data, 0644)
+}
+
+// Update replaces the file at path with what change makes of its current
+// contents, creating its directory. The lock is held from the read to the
+// write, so a save by another calc in between cannot be lost; a store whose
+// file several calcs share should merge its changes into current rather
+// than write the state it loaded. change is given nil when there is no file
+// yet.
+func Update(path string, change func(current []byte) ([]byte, error)) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	unlock, err := Lock(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	current, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	data, err := change(current)
+	if err != nil {
+		return err
+	}
+	return writeAtomic(path, data, 0644)
+}
+
+// writeAtomic writes data to a temporary file beside path and renames it into place.
+func writeAtomic(path string, data []byte, perm os.FileMode) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	// Flush before the rename, or a crash could leave the new name on an empty file
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Chmod(tmp, perm); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// Lock takes an advisory lock on path for a read-modify-write cycle,
+// returning the function that releases it. The lock is a file beside path
+// holding the process ID, created only if no other calc holds it. Lock waits
+// a few seconds for another holder before giving up, and breaks a lock old
+// enough to have been left behind by a crash.
+func Lock(path string) (unlock func(), err error) {
+	name := path + ".lock"
+	deadline := time.Now().Add(lockWait)
+	for {
+		f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			fmt.Fprintf(f, "%d\n", os.Getpid())
+			f.Close()
+			return func() { os.Remove(name) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if info, err := os.Stat(name); err == nil && time.Since(info.ModTime()) > lockStale {
+			os.Remove(name)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%s is locked by another calc; remove %s if none is running", path, name)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// SetAside renames a file that could not be parsed to path.bad-<time>, so
+// that calc can start without it and nothing in it is lost, and returns the
+// new name.
+func SetAside(path string) (string, error) {
+	backup := fmt.Sprintf("%s.bad-%s", path, time.Now().Format("20060102-150405"))
+	if err := os.Rename(path, backup); err != nil {
+		return "", err
+	}
+	return backup, nil
+}
+
+// SetAsideWarning sets aside the unparseable file at path, returning a
+// warning that says why it was ignored and where it went.
+func SetAsideWarning(what, path string, cause error) string {
+	backup, err := SetAside(path)
+	if err != nil {
+		return fmt.Sprintf("ignoring %s in %s: %s", what, path, cause)
+	}
+	return fmt.Sprintf("ignoring %s in %s: %s; moved it to %s", what, path, cause, backup)
+}
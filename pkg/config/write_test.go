@@ -0,0 +1,228 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestWriteFileConcurrentWriters has many writers replace one file at once,
+// as two calcs saving their settings would, checking that readers only ever
+// see a whole file and that the last write wins.
+func TestWriteFileConcurrentWriters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "settings.json")
+	payload := func(i int) []byte {
+		// Large enough that a torn write would show
+		return bytes.Repeat([]byte(fmt.Sprintf("writer %02d\n", i)), 4096)
+	}
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	torn := make(chan string, 1)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			if len(data) != len(payload(0)) || !bytes.Equal(data, bytes.Repeat(data[:10], 4096)) {
+				select {
+				case torn <- fmt.Sprintf("read %d bytes starting %q", len(data), data[:min(len(data), 10)]):
+				default:
+				}
+			}
+		}
+	}()
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 5 {
+				if err := WriteFile(path, payload(i)); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(done)
+	select {
+	case msg := <-torn:
+		t.Fatalf("a reader saw a torn file: %s", msg)
+	default:
+	}
+
+	if err := WriteFile(path, payload(99)); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); !bytes.Equal(data, payload(99)) {
+		t.Error("the last write did not win")
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Errorf("temporary or lock files left behind: %v", names)
+	}
+}
+
+// TestUpdateConcurrentWriters has many writers add a line to one file at
+// once, checking that the lock covers each read and write so none is lost.
+func TestUpdateConcurrentWriters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "lines.txt")
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- Update(path, func(current []byte) ([]byte, error) {
+				return append(current, fmt.Sprintf("writer %02d\n", i)...), nil
+			})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range 20 {
+		if !strings.Contains(string(data), fmt.Sprintf("writer %02d\n", i)) {
+			t.Errorf("writer %02d's line was lost:\n%s", i, data)
+		}
+	}
+}
+
+func TestMergeChanges(t *testing.T) {
+	base := map[string]int{"kept": 1, "changed": 1, "deleted": 1, "theirs-deleted": 1}
+	ours := map[string]int{"kept": 1, "changed": 2, "theirs-deleted": 1, "added": 1}
+	theirs := map[string]int{"kept": 1, "changed": 1, "deleted": 1, "other": 3}
+	got := MergeChanges(base, ours, theirs)
+	want := map[string]int{"kept": 1, "changed": 2, "added": 1, "other": 3}
+	if !maps.Equal(got, want) {
+		t.Errorf("MergeChanges = %v, want %v", got, want)
+	}
+}
+
+func TestUpdateJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "shared.json")
+	add := func(key string) func(map[string]int) map[string]int {
+		return func(theirs map[string]int) map[string]int {
+			if theirs == nil {
+				theirs = make(map[string]int)
+			}
+			theirs[key] = len(theirs) + 1
+			return theirs
+		}
+	}
+	read := func() string {
+		t.Helper()
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	// A missing file starts empty, and what is there is kept
+	if err := UpdateJSON(path, add("a")); err != nil {
+		t.Fatal(err)
+	}
+	if err := UpdateJSON(path, add("b")); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := read(), "{\n  \"a\": 1,\n  \"b\": 2\n}\n"; got != want {
+		t.Errorf("after two updates the file holds %q, want %q", got, want)
+	}
+
+	// A damaged file has nothing to keep
+	if err := os.WriteFile(path, []byte(`{"a": 1, "b":`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := UpdateJSON(path, add("c")); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := read(), "{\n  \"c\": 1\n}\n"; got != want {
+		t.Errorf("after updating a damaged file it holds %q, want %q", got, want)
+	}
+}
+
+func TestLockWaitsForHolder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "units.json")
+	unlock, err := Lock(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	released := make(chan time.Time, 1)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		released <- time.Now()
+		unlock()
+	}()
+	unlock2, err := Lock(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unlock2()
+	if time.Now().Before(<-released) {
+		t.Error("the second lock was taken while the first was held")
+	}
+}
+
+func TestLockBreaksStaleLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "units.json")
+	lock := path + ".lock"
+	if err := os.WriteFile(lock, []byte("12345\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * lockStale)
+	if err := os.Chtimes(lock, old, old); err != nil {
+		t.Fatal(err)
+	}
+	unlock, err := Lock(path)
+	if err != nil {
+		t.Fatalf("a lock left by a crash should be broken: %v", err)
+	}
+	unlock()
+	if _, err := os.Stat(lock); !os.IsNotExist(err) {
+		t.Error("unlock should remove the lock file")
+	}
+}
+
+func TestSetAsideWarning(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	w := SetAsideWarning("settings", path, fmt.Errorf("unexpected end of JSON input"))
+	if !strings.Contains(w, "moved it to "+path+".bad-") {
+		t.Errorf("warning should name the backup, got %q", w)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("the damaged file should have been moved")
+	}
+	matches, _ := filepath.Glob(path + ".bad-*")
+	if len(matches) != 1 {
+		t.Errorf("expected one backup, got %v", matches)
+	}
+}
@@ -3,6 +3,7 @@ package constants
 import (
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/andrewneudegg/calc/pkg/units"
 )
@@ -24,7 +25,13 @@ type System struct {
 }
 
 // NewSystem creates a new constants system with all standard constants loaded.
+// The constants never change, so every system shares one table.
 func NewSystem() *System {
+	return &System{constants: standardConstants()}
+}
+
+// standardConstants builds the table of constants on first use.
+var standardConstants = sync.OnceValue(func() map[string]*Constant {
 	s := &System{
 		constants: make(map[string]*Constant),
 	}
@@ -32,8 +39,9 @@ func NewSystem() *System {
 	s.initFundamental()
 	s.initElectromagnetic()
 	s.initUniversal()
-	return s
-}
+	s.initMathematical()
+	return s.constants
+})
 
 // AddConstant adds a constant to the system.
 func (s *System) addConstant(name, symbol string, value float64, unit string, dim units.Dimension, description, category string) {
@@ -32,6 +32,8 @@ func TestIsConstant(t *testing.T) {
 		{"gravitational_constant", true},
 		{"e", true},
 		{"elementary_charge", true},
+		{"pi", true},
+		{"π", true},
 		{"σ", true},
 		{"stefan_boltzmann", true},
 		{"unknown", false},
@@ -0,0 +1,22 @@
+package constants
+
+import (
+	"math"
+
+	"github.com/andrewneudegg/calc/pkg/units"
+)
+
+// initMathematical initializes mathematical constants, which are plain
+// numbers without a unit.
+func (s *System) initMathematical() {
+	// Ratio of a circle's circumference to its diameter
+	s.addConstant(
+		"pi",
+		"π",
+		math.Pi,
+		"",
+		units.DimensionNone,
+		"Ratio of a circle's circumference to its diameter",
+		"mathematical",
+	)
+}
@@ -0,0 +1,160 @@
+package currency
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Currency is a currency calc converts without being told a rate.
+type Currency struct {
+	Code     string  // ISO 4217 code, e.g. "INR"
+	Symbol   string  // Written symbol, e.g. "₹", or "" to show the code
+	Decimals int     // Digits of the minor unit, as ISO 4217 lists them: 0 for yen
+	USD      float64 // Approximate value of one unit in US dollars
+}
+
+// builtIn lists the currencies every session knows, with their minor units
+// and approximate static rates relative to USD.
+var builtIn = []Currency{
+	{"USD", "$", 2, 1.0},
+	{"GBP", "£", 2, 1.27},
+	{"EUR", "€", 2, 1.10},
+	{"JPY", "¥", 0, 0.0067},
+
+	// Oceania
+	{"AUD", "", 2, 0.654},
+	{"NZD", "", 2, 0.595},
+
+	// Americas
+	{"CAD", "", 2, 0.730},
+	{"MXN", "", 2, 0.057},
+	{"BRL", "R$", 2, 0.196},
+	{"CLP", "", 0, 0.00105},
+
+	// Europe (non-EUR)
+	{"CHF", "", 2, 1.110},
+	{"ISK", "", 0, 0.0072},
+	{"SEK", "", 2, 0.091},
+	{"NOK", "", 2, 0.091},
+	{"DKK", "", 2, 0.143},
+	{"PLN", "", 2, 0.250},
+	{"CZK", "", 2, 0.043},
+	{"HUF", "", 2, 0.0028},
+	{"RON", "", 2, 0.217},
+	{"RUB", "", 2, 0.010},
+	{"TRY", "₺", 2, 0.036},
+
+	// Middle East
+	{"AED", "", 2, 0.272},
+	{"SAR", "", 2, 0.267},
+	{"ILS", "", 2, 0.263},
+	{"KWD", "", 3, 3.25},
+	{"BHD", "", 3, 2.65},
+	{"OMR", "", 3, 2.60},
+	{"JOD", "", 3, 1.41},
+
+	// Asia
+	{"CNY", "", 2, 0.137},
+	{"HKD", "", 2, 0.128},
+	{"SGD", "", 2, 0.735},
+	{"INR", "₹", 2, 0.012},
+	{"KRW", "₩", 0, 0.00074},
+	{"TWD", "", 2, 0.031},
+	{"THB", "", 2, 0.028},
+	{"MYR", "", 2, 0.213},
+	{"IDR", "", 2, 0.000064},
+	{"PHP", "", 2, 0.018},
+	{"VND", "", 0, 0.000039},
+
+	// Africa
+	{"ZAR", "", 2, 0.054},
+	{"TND", "", 3, 0.32},
+}
+
+// currencyWords are the names, besides codes, read as a currency. "pound"
+// is left out because it is also a unit of mass.
+var currencyWords = map[string]string{
+	"dollar": "USD", "dollars": "USD",
+	"euro": "EUR", "euros": "EUR",
+	"yen": "JPY",
+}
+
+// BuiltIn returns the currencies every session knows, in a stable order.
+func BuiltIn() []Currency {
+	out := make([]Currency, len(builtIn))
+	copy(out, builtIn)
+	return out
+}
+
+// Names returns every lower-case code and word read as a currency, such as
+// "chf" and "euros".
+func Names() []string {
+	names := make([]string, 0, len(builtIn)+len(currencyWords))
+	for _, c := range builtIn {
+		names = append(names, strings.ToLower(c.Code))
+	}
+	for w := range currencyWords {
+		names = append(names, w)
+	}
+	return names
+}
+
+// IsName reports whether s, ignoring case, is a code or word read as a
+// currency, such as "inr" or "dollars".
+func IsName(s string) bool {
+	s = strings.ToLower(s)
+	if _, ok := currencyWords[s]; ok {
+		return true
+	}
+	_, ok := byCode[strings.ToUpper(s)]
+	return ok
+}
+
+// IsSymbol reports whether s is the written symbol of a known currency, such
+// as "₹".
+func IsSymbol(s string) bool {
+	_, ok := bySymbol[s]
+	return ok
+}
+
+// Code returns the ISO 4217 code of a currency given by its code, symbol or
+// name, as "GBP" for "£" or "gbp".
+func Code(cur string) string {
+	return normaliseCode(cur)
+}
+
+// MinorUnits returns the decimal places amounts of a currency, given by its
+// code, symbol or name, are written to: 0 for yen, 2 for pounds and for
+// currencies calc does not know.
+func MinorUnits(cur string) int {
+	if c, ok := byCode[Code(cur)]; ok {
+		return c.Decimals
+	}
+	return 2
+}
+
+// IsCodeSymbol reports whether a currency shown as symbol is written with
+// its code, like "CHF", and so needs a space before the amount.
+func IsCodeSymbol(symbol string) bool {
+	if symbol == "" {
+		return false
+	}
+	for _, r := range symbol {
+		if !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return true
+}
+
+var byCode, bySymbol = func() (map[string]Currency, map[string]Currency) {
+	codes := make(map[string]Currency, len(builtIn))
+	symbols := make(map[string]Currency)
+	for _, c := range builtIn {
+		codes[c.Code] = c
+		if c.Symbol != "" {
+			symbols[c.Symbol] = c
+		}
+	}
+	return codes, symbols
+}()
@@ -118,3 +118,102 @@ func TestSetRateInvalidCurrency(t *testing.T) {
 		t.Error("SetRate with invalid target currency should return error")
 	}
 }
+
+func TestRate(t *testing.T) {
+	s := NewSystem()
+
+	r, err := s.Rate("£", "usd")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.From != "GBP" || r.To != "USD" || r.Rate != 1.27 || r.Source != SourceBuiltIn || !r.Updated.IsZero() {
+		t.Errorf("unexpected rate: %+v", r)
+	}
+	if got := r.String(); got != "1 GBP = 1.27 USD" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := r.Describe(); got != "1 GBP = 1.27 USD (built-in rate)" {
+		t.Errorf("Describe() = %q", got)
+	}
+
+	if _, err := s.Rate("GBP", "XYZ"); err == nil {
+		t.Error("expected an error for an unknown currency")
+	}
+}
+
+func TestRateAfterSetRate(t *testing.T) {
+	s := NewSystem()
+	if err := s.SetRate("USD", "GBP", 0.8); err != nil {
+		t.Fatalf("SetRate: %v", err)
+	}
+
+	r, err := s.Rate("USD", "GBP")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.Source != SourceCustom || r.Updated.IsZero() {
+		t.Errorf("expected a custom rate with a time, got %+v", r)
+	}
+	if got := r.String(); got != "1 USD = 0.8 GBP" {
+		t.Errorf("String() = %q", got)
+	}
+	converted, _ := s.Convert(1, "USD", "GBP")
+	if converted != r.Rate {
+		t.Errorf("Rate %v disagrees with Convert %v", r.Rate, converted)
+	}
+
+	// Pairs not involving the changed currency stay built-in
+	if r, _ := s.Rate("EUR", "USD"); r.Source != SourceBuiltIn {
+		t.Errorf("expected EUR/USD to stay built-in, got %+v", r)
+	}
+}
+
+func TestBuiltInCurrenciesAreConsistent(t *testing.T) {
+	s := NewSystem()
+	for _, c := range BuiltIn() {
+		if !s.IsCurrency(c.Code) || !IsName(c.Code) {
+			t.Errorf("%s is not recognised", c.Code)
+		}
+		want := c.Symbol
+		if want == "" {
+			want = c.Code
+		}
+		if got := s.GetSymbol(c.Code); got != want {
+			t.Errorf("GetSymbol(%s) = %q, want %q", c.Code, got, want)
+		}
+		if c.Symbol != "" && (!IsSymbol(c.Symbol) || !s.IsCurrency(c.Symbol)) {
+			t.Errorf("symbol %s of %s is not recognised", c.Symbol, c.Code)
+		}
+		if IsCodeSymbol(want) != (c.Symbol == "") {
+			t.Errorf("IsCodeSymbol(%q) = %v", want, IsCodeSymbol(want))
+		}
+	}
+	if got, _ := s.Convert(1000, "₹", "INR"); got != 1000 {
+		t.Errorf("₹1000 in INR = %v", got)
+	}
+}
+
+func TestSetRateStaysInItsSystem(t *testing.T) {
+	a, b := NewSystem(), NewSystem()
+	before, _ := b.Convert(1, "USD", "GBP")
+	if err := a.SetRate("USD", "GBP", 0.5); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := a.Convert(1, "USD", "GBP"); got != 0.5 {
+		t.Errorf("after SetRate, 1 USD = %v GBP, want 0.5", got)
+	}
+	if got, _ := b.Convert(1, "USD", "GBP"); got != before {
+		t.Errorf("another system's rate changed to %v, want %v", got, before)
+	}
+	if got, _ := NewSystem().Convert(1, "USD", "GBP"); got != before {
+		t.Errorf("a new system's rate is %v, want %v", got, before)
+	}
+}
+
+func TestMinorUnits(t *testing.T) {
+	for cur, want := range map[string]int{"£": 2, "GBP": 2, "¥": 0, "jpy": 0, "₩": 0, "KWD": 3, "XYZ": 2} {
+		if got := MinorUnits(cur); got != want {
+			t.Errorf("MinorUnits(%q) = %d, want %d", cur, got, want)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package currency
+
+import (
+	"fmt"
+	"time"
+)
+
+// ErrUnknownCurrency reports a currency code, symbol or name that has no
+// exchange rate. Code is as normalised, so "xyz" is reported as "XYZ".
+type ErrUnknownCurrency struct {
+	Code string
+}
+
+func (e *ErrUnknownCurrency) Error() string {
+	return fmt.Sprintf("unknown currency: %s", e.Code)
+}
+
+// ErrNoRate reports an exchange rate that cannot be used, such as one of
+// zero, which would make every conversion to the currency infinite.
+type ErrNoRate struct {
+	From, To string
+	Rate     float64
+}
+
+func (e *ErrNoRate) Error() string {
+	return fmt.Sprintf("no usable exchange rate from %s to %s: %g is not a positive number", e.From, e.To, e.Rate)
+}
+
+// ErrNoHistoricalRate reports a conversion at a past date that has no rate
+// for the pair, in that week, in the rate history.
+type ErrNoHistoricalRate struct {
+	From, To string
+	Date     time.Time
+}
+
+func (e *ErrNoHistoricalRate) Error() string {
+	return fmt.Sprintf("no %s to %s exchange rate on %s; add past rates with :rates import <file.csv>", e.From, e.To, e.Date.Format("2 Jan 2006"))
+}
@@ -0,0 +1,36 @@
+package currency
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUnknownCurrencyError(t *testing.T) {
+	s := NewSystem()
+	_, err := s.Rate("GBP", "xyz")
+	var unknown *ErrUnknownCurrency
+	if !errors.As(err, &unknown) || unknown.Code != "XYZ" {
+		t.Fatalf("expected ErrUnknownCurrency for XYZ, got %v", err)
+	}
+	if got := err.Error(); got != "unknown currency: XYZ" {
+		t.Errorf("Error() = %q", got)
+	}
+	if _, err := s.Convert(1, "ABC", "USD"); !errors.As(err, &unknown) || unknown.Code != "ABC" {
+		t.Errorf("Convert from ABC: expected ErrUnknownCurrency, got %v", err)
+	}
+}
+
+func TestSetRateRejectsUnusableRates(t *testing.T) {
+	s := NewSystem()
+	for _, rate := range []float64{0, -1.5} {
+		err := s.SetRate("USD", "GBP", rate)
+		var noRate *ErrNoRate
+		if !errors.As(err, &noRate) || noRate.From != "USD" || noRate.To != "GBP" || noRate.Rate != rate {
+			t.Errorf("SetRate(USD, GBP, %g): expected ErrNoRate, got %v", rate, err)
+		}
+	}
+	// The rejected rates must not replace the built-in one
+	if got, _ := s.Convert(1, "GBP", "USD"); got != 1.27 {
+		t.Errorf("Convert(1, GBP, USD) = %v, want the built-in 1.27", got)
+	}
+}
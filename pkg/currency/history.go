@@ -0,0 +1,269 @@
+package currency
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andrewneudegg/calc/pkg/config"
+)
+
+// SourceHistorical is the source of a rate looked up for a past date.
+const SourceHistorical = "historical"
+
+// dayLayout is how dates are written in history files, as in 2024-03-15.
+const dayLayout = "2006-01-02"
+
+// staleDays is how far back ConvertAt looks for a rate when a date has none,
+// enough to cover a weekend or a long holiday when markets were closed.
+const staleDays = 7
+
+// History supplies the exchange rates of past days, for ConvertAt.
+type History interface {
+	// RateOn returns how much of to one from bought on day, which is a
+	// date at midnight, reporting false if that day has no rate.
+	RateOn(from, to string, day time.Time) (float64, bool)
+}
+
+// RateHistory is a History kept in memory and saved as JSON keyed by date:
+//
+//	{"2024-03-15": {"GBP/USD": 1.2734, "EUR/USD": 1.0891}}
+//
+// A pair can be used either way round, and through a third currency quoted
+// on the same day, so GBP/USD and EUR/USD also give GBP to EUR.
+type RateHistory struct {
+	days map[string]map[string]float64 // Date -> "FROM/TO" -> rate
+	// saved is the rates as last loaded or saved, by "date FROM/TO", against
+	// which Save finds the changes to merge into the file
+	saved map[string]float64
+}
+
+// NewRateHistory returns an empty RateHistory.
+func NewRateHistory() *RateHistory {
+	return &RateHistory{days: make(map[string]map[string]float64)}
+}
+
+// Add records that one from bought rate of to on day.
+func (h *RateHistory) Add(day time.Time, from, to string, rate float64) error {
+	from, to = normaliseCode(from), normaliseCode(to)
+	if !IsName(from) {
+		return &ErrUnknownCurrency{Code: from}
+	}
+	if !IsName(to) {
+		return &ErrUnknownCurrency{Code: to}
+	}
+	if !(rate > 0) || math.IsInf(rate, 1) {
+		return &ErrNoRate{From: from, To: to, Rate: rate}
+	}
+	key := day.Format(dayLayout)
+	if h.days[key] == nil {
+		h.days[key] = make(map[string]float64)
+	}
+	h.days[key][from+"/"+to] = rate
+	return nil
+}
+
+// RateOn implements History.
+func (h *RateHistory) RateOn(from, to string, day time.Time) (float64, bool) {
+	pairs := h.days[day.Format(dayLayout)]
+	from, to = normaliseCode(from), normaliseCode(to)
+	if r, ok := pairRate(pairs, from, to); ok {
+		return r, true
+	}
+	// Go through a currency quoted against both, trying them in order so
+	// the answer does not depend on map iteration
+	var via []string
+	for pair := range pairs {
+		a, b, _ := strings.Cut(pair, "/")
+		via = append(via, a, b)
+	}
+	sort.Strings(via)
+	for _, c := range via {
+		first, ok1 := pairRate(pairs, from, c)
+		second, ok2 := pairRate(pairs, c, to)
+		if ok1 && ok2 {
+			return first * second, true
+		}
+	}
+	return 0, false
+}
+
+// pairRate finds the rate from one currency to another among a day's
+// pairs, quoted either way round.
+func pairRate(pairs map[string]float64, from, to string) (float64, bool) {
+	if r, ok := pairs[from+"/"+to]; ok {
+		return r, true
+	}
+	if r, ok := pairs[to+"/"+from]; ok {
+		return 1 / r, true
+	}
+	return 0, false
+}
+
+// Days returns the number of days with rates.
+func (h *RateHistory) Days() int {
+	return len(h.days)
+}
+
+// ImportCSV adds the rates in a CSV file with date, from, to and rate
+// columns, such as "2024-03-15,GBP,USD,1.2734", meaning one GBP bought
+// 1.2734 USD that day. A first line of column names is skipped. It returns
+// the number of rates added, and adds none if any line is invalid.
+func (h *RateHistory) ImportCSV(r io.Reader) (int, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = 4
+	cr.TrimLeadingSpace = true
+	cr.Comment = '#'
+	records, err := cr.ReadAll()
+	if err != nil {
+		return 0, err
+	}
+
+	staged := NewRateHistory()
+	added := 0
+	for i, rec := range records {
+		if i == 0 && strings.EqualFold(rec[0], "date") {
+			continue
+		}
+		day, err := time.Parse(dayLayout, rec[0])
+		if err != nil {
+			return 0, fmt.Errorf("line %d: date must be written as 2024-03-15, got %q", i+1, rec[0])
+		}
+		rate, err := strconv.ParseFloat(rec[3], 64)
+		if err != nil {
+			return 0, fmt.Errorf("line %d: invalid rate %q", i+1, rec[3])
+		}
+		if err := staged.Add(day, rec[1], rec[2], rate); err != nil {
+			return 0, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		added++
+	}
+	for day, pairs := range staged.days {
+		if h.days[day] == nil {
+			h.days[day] = make(map[string]float64)
+		}
+		for pair, rate := range pairs {
+			h.days[day][pair] = rate
+		}
+	}
+	return added, nil
+}
+
+// Save writes the history to path as JSON, creating its directory. Only the
+// rates imported since the history was last loaded or saved are written
+// over what the file holds now, so rates another calc has saved there
+// meanwhile are kept.
+func (h *RateHistory) Save(path string) error {
+	ours := h.flatten()
+	err := config.UpdateJSON(path, func(days map[string]map[string]float64) map[string]map[string]float64 {
+		theirs := (&RateHistory{days: days}).flatten()
+		merged := make(map[string]map[string]float64)
+		for key, rate := range config.MergeChanges(h.saved, ours, theirs) {
+			day, pair, _ := strings.Cut(key, " ")
+			if merged[day] == nil {
+				merged[day] = make(map[string]float64)
+			}
+			merged[day][pair] = rate
+		}
+		return merged
+	})
+	if err == nil {
+		h.saved = ours
+	}
+	return err
+}
+
+// flatten returns the rates by "date FROM/TO", as in "2024-03-15 GBP/USD".
+func (h *RateHistory) flatten() map[string]float64 {
+	flat := make(map[string]float64)
+	for day, pairs := range h.days {
+		for pair, rate := range pairs {
+			flat[day+" "+pair] = rate
+		}
+	}
+	return flat
+}
+
+// LoadRateHistory reads a history saved by Save. A missing file gives an
+// empty history.
+func LoadRateHistory(path string) (*RateHistory, error) {
+	h := NewRateHistory()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var days map[string]map[string]float64
+	if err := json.Unmarshal(data, &days); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	for key, pairs := range days {
+		day, err := time.Parse(dayLayout, key)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: invalid date %q", path, key)
+		}
+		for pair, rate := range pairs {
+			from, to, ok := strings.Cut(pair, "/")
+			if !ok {
+				return nil, fmt.Errorf("reading %s: %s: pair must be written as GBP/USD, got %q", path, key, pair)
+			}
+			if err := h.Add(day, from, to, rate); err != nil {
+				return nil, fmt.Errorf("reading %s: %s: %w", path, key, err)
+			}
+		}
+	}
+	h.saved = h.flatten()
+	return h, nil
+}
+
+// SetHistory sets where ConvertAt finds past rates; nil removes it.
+func (s *System) SetHistory(h History) {
+	s.history = h
+}
+
+// RateAt returns the rate ConvertAt uses between two currencies on a date.
+// When the date has no rate, the latest of the week before is used, and the
+// Rate's On says which day it came from.
+func (s *System) RateAt(from, to string, date time.Time) (Rate, error) {
+	from = s.normaliseCurrency(from)
+	to = s.normaliseCurrency(to)
+	for _, code := range []string{from, to} {
+		if _, ok := s.rates[code]; !ok {
+			return Rate{}, &ErrUnknownCurrency{Code: code}
+		}
+	}
+
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	if from == to {
+		return Rate{From: from, To: to, Rate: 1, Source: SourceHistorical, On: day}, nil
+	}
+	if s.history != nil {
+		for back := 0; back < staleDays; back++ {
+			on := day.AddDate(0, 0, -back)
+			if rate, ok := s.history.RateOn(from, to, on); ok {
+				return Rate{From: from, To: to, Rate: rate, Source: SourceHistorical, On: on}, nil
+			}
+		}
+	}
+	return Rate{}, &ErrNoHistoricalRate{From: from, To: to, Date: day}
+}
+
+// ConvertAt converts an amount from one currency to another at the rate of
+// a past date, as RateAt finds it. It never falls back to today's rate.
+func (s *System) ConvertAt(amount float64, from, to string, date time.Time) (float64, error) {
+	rate, err := s.RateAt(from, to, date)
+	if err != nil {
+		return 0, err
+	}
+	return amount * rate.Rate, nil
+}
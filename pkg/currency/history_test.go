@@ -0,0 +1,213 @@
+package currency
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fixtureHistory returns a System with the past rates in testdata/rates.csv,
+// which has rates for 15 and 18 March 2024.
+func fixtureHistory(t *testing.T) (*System, *RateHistory) {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", "rates.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	h := NewRateHistory()
+	n, err := h.ImportCSV(f)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if n != 4 || h.Days() != 2 {
+		t.Fatalf("expected 4 rates over 2 days, got %d over %d", n, h.Days())
+	}
+	s := NewSystem()
+	s.SetHistory(h)
+	return s, h
+}
+
+func day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestConvertAt(t *testing.T) {
+	s, _ := fixtureHistory(t)
+
+	tests := []struct {
+		name     string
+		from, to string
+		date     time.Time
+		want     float64
+		usedOn   time.Time
+	}{
+		{"exact day", "GBP", "USD", day(2024, 3, 15), 127.34, day(2024, 3, 15)},
+		{"second day", "GBP", "USD", day(2024, 3, 18), 127.20, day(2024, 3, 18)},
+		{"inverse", "USD", "GBP", day(2024, 3, 15), 100 / 1.2734, day(2024, 3, 15)},
+		{"through a third currency", "GBP", "EUR", day(2024, 3, 15), 100 * 1.2734 / 1.0891, day(2024, 3, 15)},
+		{"symbols", "£", "$", day(2024, 3, 18), 127.20, day(2024, 3, 18)},
+		{"weekend uses friday", "GBP", "USD", day(2024, 3, 17), 127.34, day(2024, 3, 15)},
+		{"time of day is ignored", "GBP", "USD", time.Date(2024, 3, 15, 18, 30, 0, 0, time.UTC), 127.34, day(2024, 3, 15)},
+		{"same currency", "GBP", "GBP", day(2001, 1, 1), 100, day(2001, 1, 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.ConvertAt(100, tt.from, tt.to, tt.date)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			rate, _ := s.RateAt(tt.from, tt.to, tt.date)
+			if !rate.On.Equal(tt.usedOn) || rate.Source != SourceHistorical {
+				t.Errorf("rate %+v, want one from %s", rate, tt.usedOn.Format(dayLayout))
+			}
+		})
+	}
+}
+
+func TestConvertAtWithoutARate(t *testing.T) {
+	s, _ := fixtureHistory(t)
+
+	for _, tt := range []struct {
+		name     string
+		from, to string
+		date     time.Time
+	}{
+		{"before the history", "GBP", "USD", day(2024, 1, 1)},
+		{"more than a week after", "GBP", "USD", day(2024, 3, 30)},
+		{"pair never quoted", "GBP", "CHF", day(2024, 3, 15)},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.ConvertAt(100, tt.from, tt.to, tt.date)
+			var missing *ErrNoHistoricalRate
+			if !errors.As(err, &missing) {
+				t.Fatalf("expected ErrNoHistoricalRate, got %v", err)
+			}
+			if missing.From != tt.from || missing.To != tt.to || !missing.Date.Equal(tt.date) {
+				t.Errorf("unexpected error fields: %+v", missing)
+			}
+		})
+	}
+
+	// Today's rates are never used in place of a past one
+	if _, err := NewSystem().ConvertAt(100, "GBP", "USD", day(2024, 3, 15)); err == nil {
+		t.Error("expected an error without a history")
+	}
+	var unknown *ErrUnknownCurrency
+	if _, err := s.ConvertAt(100, "GBP", "XYZ", day(2024, 3, 15)); !errors.As(err, &unknown) {
+		t.Errorf("expected ErrUnknownCurrency, got %v", err)
+	}
+}
+
+func TestRateHistorySaveAndLoad(t *testing.T) {
+	_, h := fixtureHistory(t)
+	path := filepath.Join(t.TempDir(), "cache", "rates.json")
+	if err := h.Save(path); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), `"2024-03-15": {`) || !strings.Contains(string(data), `"GBP/USD": 1.2734`) {
+		t.Errorf("expected rates keyed by date, got:\n%s", data)
+	}
+
+	loaded, err := LoadRateHistory(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if r, ok := loaded.RateOn("USD", "JPY", day(2024, 3, 18)); !ok || r != 149.05 {
+		t.Errorf("expected 149.05 after loading, got %v, %v", r, ok)
+	}
+
+	missing, err := LoadRateHistory(filepath.Join(t.TempDir(), "none.json"))
+	if err != nil || missing.Days() != 0 {
+		t.Errorf("expected an empty history for a missing file, got %v, %v", missing, err)
+	}
+
+	bad := filepath.Join(t.TempDir(), "bad.json")
+	os.WriteFile(bad, []byte(`{"15/03/2024": {"GBP/USD": 1.27}}`), 0644)
+	if _, err := LoadRateHistory(bad); err == nil || !strings.Contains(err.Error(), `invalid date "15/03/2024"`) {
+		t.Errorf("expected an invalid date error, got %v", err)
+	}
+}
+
+func TestImportCSVErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"date format", "15/03/2024,GBP,USD,1.27\n", "line 1: date must be written as 2024-03-15"},
+		{"rate", "2024-03-15,GBP,USD,lots\n", `line 1: invalid rate "lots"`},
+		{"currency", "2024-03-15,GBP,XYZ,1.27\n", "line 1: unknown currency: XYZ"},
+		{"zero rate", "2024-03-15,GBP,USD,0\n", "line 1:"},
+		{"later line", "2024-03-15,GBP,USD,1.27\n2024-03-16,GBP,USD\n", "record on line 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewRateHistory()
+			_, err := h.ImportCSV(strings.NewReader(tt.input))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected an error containing %q, got %v", tt.want, err)
+			}
+			if h.Days() != 0 {
+				t.Errorf("a failed import should add nothing, got %d days", h.Days())
+			}
+		})
+	}
+}
+
+func TestHistoricalRateDescribe(t *testing.T) {
+	s, _ := fixtureHistory(t)
+	rate, err := s.RateAt("GBP", "USD", day(2024, 3, 15))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := rate.Describe(); got != "1 GBP = 1.273 USD (historical rate, 15 Mar 2024)" {
+		t.Errorf("unexpected description: %q", got)
+	}
+}
+
+// TestRateHistorySaveKeepsOtherWriters has two calcs started on the same
+// file each import a rate and save, checking that neither loses the other's.
+func TestRateHistorySaveKeepsOtherWriters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	first, err := LoadRateHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := LoadRateHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := first.Add(day(2024, 3, 15), "GBP", "USD", 1.27); err != nil {
+		t.Fatal(err)
+	}
+	if err := first.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := second.Add(day(2024, 3, 18), "USD", "JPY", 149.05); err != nil {
+		t.Fatal(err)
+	}
+	if err := second.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadRateHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, ok := loaded.RateOn("GBP", "USD", day(2024, 3, 15)); !ok || r != 1.27 {
+		t.Errorf("GBP/USD = %v, %v; want the first calc's 1.27", r, ok)
+	}
+	if r, ok := loaded.RateOn("USD", "JPY", day(2024, 3, 18)); !ok || r != 149.05 {
+		t.Errorf("USD/JPY = %v, %v; want the second calc's 149.05", r, ok)
+	}
+}
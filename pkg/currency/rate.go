@@ -0,0 +1,71 @@
+package currency
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Rate sources reported with a Rate.
+const (
+	SourceBuiltIn = "built-in" // the approximate rates calc ships with
+	SourceCustom  = "custom"   // a rate changed with SetRate
+)
+
+// Rate is the exchange rate used to convert between two currencies, with
+// where it came from so that a result can say how far to trust it.
+type Rate struct {
+	From   string  // currency code converted from, e.g. "GBP"
+	To     string  // currency code converted to
+	Rate   float64 // units of To for one unit of From
+	Source string  // SourceBuiltIn, SourceCustom or SourceHistorical
+	// Updated is when a custom rate was set; it is zero for built-in rates.
+	Updated time.Time
+	// On is the day a historical rate applied; it is zero for current rates.
+	On time.Time
+}
+
+// String writes the rate as "1 GBP = 1.27 USD", to four significant figures.
+func (r Rate) String() string {
+	return fmt.Sprintf("1 %s = %s %s", r.From, strconv.FormatFloat(r.Rate, 'g', 4, 64), r.To)
+}
+
+// Describe writes the rate with its source, e.g.
+// "1 GBP = 1.27 USD (built-in rate)", "1 GBP = 1.3 USD (custom rate, set 16 Oct 2026 09:30)"
+// or "1 GBP = 1.273 USD (historical rate, 15 Mar 2024)".
+func (r Rate) Describe() string {
+	if !r.On.IsZero() {
+		return fmt.Sprintf("%s (%s rate, %s)", r, r.Source, r.On.Format("2 Jan 2006"))
+	}
+	if r.Updated.IsZero() {
+		return fmt.Sprintf("%s (%s rate)", r, r.Source)
+	}
+	return fmt.Sprintf("%s (%s rate, set %s)", r, r.Source, r.Updated.Format("2 Jan 2006 15:04"))
+}
+
+// Rate returns the rate Convert uses between two currencies.
+func (s *System) Rate(from, to string) (Rate, error) {
+	from = s.normaliseCurrency(from)
+	to = s.normaliseCurrency(to)
+
+	fromRate, ok := s.rates[from]
+	if !ok {
+		return Rate{}, &ErrUnknownCurrency{Code: from}
+	}
+	toRate, ok := s.rates[to]
+	if !ok {
+		return Rate{}, &ErrUnknownCurrency{Code: to}
+	}
+
+	r := Rate{From: from, To: to, Rate: fromRate / toRate, Source: SourceBuiltIn}
+	// A pair is custom if either side was set; report the later change
+	for _, code := range []string{from, to} {
+		if at, ok := s.set[code]; ok {
+			r.Source = SourceCustom
+			if at.After(r.Updated) {
+				r.Updated = at
+			}
+		}
+	}
+	return r, nil
+}
@@ -0,0 +1,193 @@
+// Package decimal is a minimal arbitrary-precision decimal type for the
+// opt-in decimal mode. Addition, subtraction and multiplication are exact;
+// division keeps DivisionPlaces decimal places, rounding ties to even.
+package decimal
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// DivisionPlaces is how many decimal places a quotient keeps beyond those
+// of its operands.
+const DivisionPlaces = 30
+
+// Decimal is the number coef × 10^-scale. The zero value is 0.
+type Decimal struct {
+	coef  *big.Int
+	scale int
+}
+
+var ten = big.NewInt(10)
+
+// New returns coef × 10^-scale, so New(125, 2) is 1.25.
+func New(coef int64, scale int) Decimal {
+	return Decimal{coef: big.NewInt(coef), scale: scale}.normalise()
+}
+
+// Parse reads a decimal written as digits with an optional sign, decimal
+// point and exponent, such as "-12.50" or "1e-3".
+func Parse(s string) (Decimal, error) {
+	mantissa, exp := s, 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.Atoi(s[i+1:])
+		if err != nil {
+			return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+		}
+		mantissa, exp = s[:i], e
+	}
+	whole, frac, _ := strings.Cut(mantissa, ".")
+	coef, ok := new(big.Int).SetString(whole+frac, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+	return Decimal{coef: coef, scale: len(frac) - exp}.normalise(), nil
+}
+
+// FromFloat returns the shortest decimal that reads back as f, so 0.1 gives
+// exactly 0.1 rather than the binary value nearest to it. It reports false
+// for infinities and NaN.
+func FromFloat(f float64) (Decimal, bool) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return Decimal{}, false
+	}
+	d, err := Parse(strconv.FormatFloat(f, 'g', -1, 64))
+	return d, err == nil
+}
+
+// Add returns d + o.
+func (d Decimal) Add(o Decimal) Decimal {
+	a, b, scale := align(d, o)
+	return Decimal{coef: a.Add(a, b), scale: scale}.normalise()
+}
+
+// Sub returns d - o.
+func (d Decimal) Sub(o Decimal) Decimal {
+	a, b, scale := align(d, o)
+	return Decimal{coef: a.Sub(a, b), scale: scale}.normalise()
+}
+
+// Mul returns d × o.
+func (d Decimal) Mul(o Decimal) Decimal {
+	coef := new(big.Int).Mul(d.int(), o.int())
+	return Decimal{coef: coef, scale: d.scale + o.scale}.normalise()
+}
+
+// Div returns d ÷ o to DivisionPlaces more places than the operands carry.
+// It reports false when o is zero.
+func (d Decimal) Div(o Decimal) (Decimal, bool) {
+	if o.IsZero() {
+		return Decimal{}, false
+	}
+	scale := max(d.scale, o.scale, 0) + DivisionPlaces
+	// d/o = (d.coef × 10^(scale - d.scale + o.scale) / o.coef) × 10^-scale
+	num := new(big.Int).Set(d.int())
+	shift := scale - d.scale + o.scale
+	if shift >= 0 {
+		num.Mul(num, pow10(shift))
+	}
+	den := new(big.Int).Set(o.int())
+	if shift < 0 {
+		den.Mul(den, pow10(-shift))
+	}
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	// Round half to even on the remainder
+	twice := new(big.Int).Abs(r)
+	twice.Lsh(twice, 1)
+	if c := twice.Cmp(new(big.Int).Abs(den)); c > 0 || c == 0 && q.Bit(0) == 1 {
+		if num.Sign()*den.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return Decimal{coef: q, scale: scale}.normalise(), true
+}
+
+// Neg returns -d.
+func (d Decimal) Neg() Decimal {
+	return Decimal{coef: new(big.Int).Neg(d.int()), scale: d.scale}
+}
+
+// Cmp compares d and o, returning -1, 0 or +1.
+func (d Decimal) Cmp(o Decimal) int {
+	a, b, _ := align(d, o)
+	return a.Cmp(b)
+}
+
+// IsZero reports whether d is 0.
+func (d Decimal) IsZero() bool {
+	return d.int().Sign() == 0
+}
+
+// Float64 returns the float64 nearest to d.
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// String writes d in plain notation without trailing zeros, such as "-0.3".
+func (d Decimal) String() string {
+	digits := new(big.Int).Abs(d.int()).String()
+	sign := ""
+	if d.int().Sign() < 0 {
+		sign = "-"
+	}
+	if d.scale <= 0 {
+		return sign + digits + strings.Repeat("0", -d.scale)
+	}
+	if len(digits) <= d.scale {
+		digits = strings.Repeat("0", d.scale-len(digits)+1) + digits
+	}
+	point := len(digits) - d.scale
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// int returns the coefficient, treating the zero Decimal as 0.
+func (d Decimal) int() *big.Int {
+	if d.coef == nil {
+		return new(big.Int)
+	}
+	return d.coef
+}
+
+// normalise drops trailing zeros from the coefficient so equal values share
+// one representation and repeated multiplication does not grow the scale.
+func (d Decimal) normalise() Decimal {
+	coef := d.int()
+	if coef.Sign() == 0 {
+		return Decimal{coef: coef}
+	}
+	q, r := new(big.Int), new(big.Int)
+	for d.scale > 0 {
+		q.QuoRem(coef, ten, r)
+		if r.Sign() != 0 {
+			break
+		}
+		coef = new(big.Int).Set(q)
+		d.scale--
+	}
+	d.coef = coef
+	return d
+}
+
+// align returns copies of the coefficients of d and o at a common scale.
+func align(d, o Decimal) (*big.Int, *big.Int, int) {
+	a, b := new(big.Int).Set(d.int()), new(big.Int).Set(o.int())
+	switch {
+	case d.scale < o.scale:
+		a.Mul(a, pow10(o.scale-d.scale))
+		return a, b, o.scale
+	case o.scale < d.scale:
+		b.Mul(b, pow10(d.scale-o.scale))
+	}
+	return a, b, d.scale
+}
+
+// pow10 returns 10^n for n >= 0.
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(ten, big.NewInt(int64(n)), nil)
+}
@@ -0,0 +1,88 @@
+package decimal
+
+import "testing"
+
+func mustParse(t *testing.T, s string) Decimal {
+	t.Helper()
+	d, err := Parse(s)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", s, err)
+	}
+	return d
+}
+
+func TestParseAndString(t *testing.T) {
+	tests := map[string]string{
+		"0.1":                           "0.1",
+		"-12.50":                        "-12.5",
+		"1e-3":                          "0.001",
+		"2.5E2":                         "250",
+		"100":                           "100",
+		"-.5":                           "-0.5",
+		"0.000":                         "0",
+		"1234567890123456789.123456789": "1234567890123456789.123456789",
+	}
+	for in, want := range tests {
+		if got := mustParse(t, in).String(); got != want {
+			t.Errorf("Parse(%q).String() = %q, want %q", in, got, want)
+		}
+	}
+	for _, bad := range []string{"", "abc", "1.2.3", "1e", "1.-5"} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("Parse(%q) should fail", bad)
+		}
+	}
+}
+
+func TestArithmeticIsExact(t *testing.T) {
+	a, b := mustParse(t, "0.1"), mustParse(t, "0.2")
+	if got := a.Add(b); got.Cmp(mustParse(t, "0.3")) != 0 {
+		t.Errorf("0.1 + 0.2 = %s", got)
+	}
+	// £0.10 * 3 - £0.30 is exactly zero
+	if got := a.Mul(New(3, 0)).Sub(mustParse(t, "0.3")); !got.IsZero() {
+		t.Errorf("0.1 * 3 - 0.3 = %s", got)
+	}
+	if got := New(1, 0).Sub(mustParse(t, "0.9")); got.String() != "0.1" {
+		t.Errorf("1 - 0.9 = %s", got)
+	}
+	if got := mustParse(t, "1.1").Mul(mustParse(t, "1.1")); got.String() != "1.21" {
+		t.Errorf("1.1 * 1.1 = %s", got)
+	}
+}
+
+func TestDiv(t *testing.T) {
+	third, ok := New(1, 0).Div(New(3, 0))
+	if !ok || third.String() != "0.333333333333333333333333333333" {
+		t.Errorf("1 / 3 = %s", third)
+	}
+	if got, _ := New(2, 0).Div(New(3, 0)); got.String() != "0.666666666666666666666666666667" {
+		t.Errorf("2 / 3 = %s", got)
+	}
+	if got, _ := New(-2, 0).Div(New(3, 0)); got.String() != "-0.666666666666666666666666666667" {
+		t.Errorf("-2 / 3 = %s", got)
+	}
+	if got, _ := mustParse(t, "10.5").Div(mustParse(t, "0.5")); got.String() != "21" {
+		t.Errorf("10.5 / 0.5 = %s", got)
+	}
+	if _, ok := New(1, 0).Div(Decimal{}); ok {
+		t.Error("division by zero should fail")
+	}
+}
+
+func TestFromFloat(t *testing.T) {
+	if d, ok := FromFloat(0.1); !ok || d.String() != "0.1" {
+		t.Errorf("FromFloat(0.1) = %s", d)
+	}
+	if d, _ := FromFloat(1e21); d.String() != "1000000000000000000000" {
+		t.Errorf("FromFloat(1e21) = %s", d)
+	}
+	if _, ok := FromFloat(1 / zero()); ok {
+		t.Error("FromFloat(+Inf) should fail")
+	}
+	if got := mustParse(t, "0.3").Float64(); got != 0.3 {
+		t.Errorf("Float64() = %v", got)
+	}
+}
+
+func zero() float64 { return 0 }
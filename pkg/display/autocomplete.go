@@ -14,7 +14,7 @@ import (
 type Suggestion struct {
 	Text        string // The text to insert
 	Display     string // The text to display (may include description)
-	Category    string // Category label (command, variable, function, unit, currency, keyword)
+	Category    string // Category label (command, setting, variable, function, unit, currency, keyword)
 	Description string // Optional short description
 }
 
@@ -50,9 +50,16 @@ func (ac *AutocompleteEngine) initCommands() {
 		{Text: ":open ", Display: ":open <file>", Category: "command", Description: "Open a workspace file"},
 		{Text: ":set ", Display: ":set <key> <val>", Category: "command", Description: "Set a preference"},
 		{Text: ":clear", Display: ":clear", Category: "command", Description: "Clear screen and reset session"},
-		{Text: ":quiet ", Display: ":quiet [on|off]", Category: "command", Description: "Toggle quiet mode"},
-		{Text: ":tz ", Display: ":tz list", Category: "command", Description: "List timezones"},
+		{Text: ":quiet ", Display: ":quiet [on|off]", Category: "command", Description: "Toggle quiet output for this session"},
+		{Text: ":tz ", Display: ":tz list|search|add", Category: "command", Description: "List, search or add timezones"},
 		{Text: ":const ", Display: ":const list|show", Category: "command", Description: "List or show physical constants"},
+		{Text: ":units ", Display: ":units [dimension|search]", Category: "command", Description: "List or search units"},
+		{Text: ":ingredient ", Display: ":ingredient define|list", Category: "command", Description: "Define or list ingredient densities"},
+		{Text: ":warnings", Display: ":warnings", Category: "command", Description: "List undefined variables read as 0"},
+		{Text: ":paste", Display: ":paste", Category: "command", Description: "Evaluate several pasted lines"},
+		{Text: ":budget ", Display: ":budget <amount>|reset", Category: "command", Description: "Track spending against a budget"},
+		{Text: ":chart ", Display: ":chart <vars|#tag> [--spark|--csv]", Category: "command", Description: "Draw values as a bar chart or sparkline"},
+		{Text: ":workspace ", Display: ":workspace new|switch|delete|list", Category: "command", Description: "Keep separate sets of variables and lines"},
 		{Text: ":quit", Display: ":quit", Category: "command", Description: "Exit the program"},
 		{Text: ":exit", Display: ":exit", Category: "command", Description: "Exit the program"},
 		{Text: ":q", Display: ":q", Category: "command", Description: "Exit the program"},
@@ -68,6 +75,10 @@ func (ac *AutocompleteEngine) initFunctions() {
 		{Text: "max(", Display: "max(...)", Category: "function", Description: "Maximum of arguments"},
 		{Text: "print(\"", Display: "print(\"...\")", Category: "function", Description: "Print with variable interpolation"},
 	}
+	// Functions added by extensions linked into the binary
+	for _, fn := range evaluator.RegisteredFunctions() {
+		ac.functions = append(ac.functions, Suggestion{Text: fn.Name + "(", Display: fn.Usage(), Category: "function", Description: fn.Description})
+	}
 }
 
 func (ac *AutocompleteEngine) initKeywords() {
@@ -87,6 +98,8 @@ func (ac *AutocompleteEngine) initKeywords() {
 		{Text: "today", Display: "today", Category: "keyword", Description: "Current date"},
 		{Text: "tomorrow", Display: "tomorrow", Category: "keyword", Description: "Tomorrow's date"},
 		{Text: "yesterday", Display: "yesterday", Category: "keyword", Description: "Yesterday's date"},
+		{Text: "noon", Display: "noon", Category: "keyword", Description: "12:00, also midday"},
+		{Text: "midnight", Display: "midnight", Category: "keyword", Description: "00:00"},
 		{Text: "next week", Display: "next week", Category: "keyword", Description: "Date one week ahead"},
 		{Text: "last week", Display: "last week", Category: "keyword", Description: "Date one week ago"},
 		{Text: "next month", Display: "next month", Category: "keyword", Description: "Date one month ahead"},
@@ -99,7 +112,14 @@ func (ac *AutocompleteEngine) initKeywords() {
 		{Text: "prev#", Display: "prev#N", Category: "keyword", Description: "Result at line N"},
 	}
 
+	// Approximation keywords (always available)
+	approxKeywords := []Suggestion{
+		{Text: "about ", Display: "about", Category: "keyword", Description: "Ballpark of the result"},
+		{Text: "roughly ", Display: "roughly", Category: "keyword", Description: "Ballpark of the result"},
+	}
+
 	ac.keywords = append(dateKeywords, prevKeywords...)
+	ac.keywords = append(ac.keywords, approxKeywords...)
 	if ac.settings.FuzzyMode {
 		ac.keywords = append(ac.keywords, fuzzyKeywords...)
 	}
@@ -111,6 +131,11 @@ func (ac *AutocompleteEngine) GetSuggestions(input string) []Suggestion {
 		return nil
 	}
 
+	// Setting names after ":set ", including straight after the space
+	if rest, ok := strings.CutPrefix(strings.TrimLeft(input, " "), ":set "); ok {
+		return ac.getSettings(strings.TrimLeft(rest, " "))
+	}
+
 	input = strings.TrimSpace(input)
 	if input == "" {
 		return nil
@@ -178,6 +203,25 @@ func (ac *AutocompleteEngine) GetSuggestions(input string) []Suggestion {
 	return suggestions
 }
 
+// getSettings suggests setting names for ":set", taken from settings.Schema.
+func (ac *AutocompleteEngine) getSettings(prefix string) []Suggestion {
+	if strings.Contains(prefix, " ") {
+		return nil
+	}
+	var suggestions []Suggestion
+	for _, st := range settings.Schema() {
+		if strings.HasPrefix(st.Key, strings.ToLower(prefix)) {
+			suggestions = append(suggestions, Suggestion{
+				Text:        st.Key + " ",
+				Display:     st.Key + " " + st.Arg,
+				Category:    "setting",
+				Description: st.Description,
+			})
+		}
+	}
+	return suggestions
+}
+
 func (ac *AutocompleteEngine) getVariables() []Suggestion {
 	var suggestions []Suggestion
 	// Access environment variables
@@ -192,27 +236,23 @@ func (ac *AutocompleteEngine) getVariables() []Suggestion {
 	return suggestions
 }
 
+// getUnits suggests every unit name, custom units included, that starts with prefix.
 func (ac *AutocompleteEngine) getUnits(prefix string) []Suggestion {
 	var suggestions []Suggestion
-	// Common units to suggest
-	commonUnits := []string{
-		"m", "cm", "mm", "km", "ft", "in", "mi",
-		"kg", "g", "mg", "lb", "oz",
-		"s", "min", "h", "day", "week", "month", "year",
-		"l", "ml", "gal", "pt",
-		"°c", "°f", "k",
-	}
-
-	for _, u := range commonUnits {
-		if strings.HasPrefix(strings.ToLower(u), strings.ToLower(prefix)) && ac.units.IsUnit(u) {
-			suggestions = append(suggestions, Suggestion{
-				Text:     u,
-				Display:  u,
-				Category: "unit",
-			})
+	for _, dim := range ac.units.Dimensions() {
+		for _, unit := range ac.units.UnitsIn(dim) {
+			for _, name := range ac.units.Aliases(unit.Name) {
+				if strings.HasPrefix(strings.ToLower(name), strings.ToLower(prefix)) {
+					suggestions = append(suggestions, Suggestion{
+						Text:        name,
+						Display:     name,
+						Category:    "unit",
+						Description: dim.String(),
+					})
+				}
+			}
 		}
 	}
-
 	return suggestions
 }
 
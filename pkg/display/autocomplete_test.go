@@ -35,6 +35,24 @@ func TestAutocompleteSuggestsCommands(t *testing.T) {
 	}
 }
 
+func TestAutocompleteSuggestsSettings(t *testing.T) {
+	ac := NewAutocompleteEngine(evaluator.NewEnvironment(), units.NewSystem(), currency.NewSystem(), settings.Default())
+
+	all := ac.GetSuggestions(":set ")
+	if len(all) != len(settings.Schema()) {
+		t.Fatalf("Expected one suggestion per setting after ':set ', got %d", len(all))
+	}
+
+	suggestions := ac.GetSuggestions(":set prec")
+	if len(suggestions) != 1 || suggestions[0].Text != "precision " || suggestions[0].Category != "setting" {
+		t.Errorf("Expected 'precision ' for ':set prec', got %+v", suggestions)
+	}
+
+	if got := ac.GetSuggestions(":set precision 4"); len(got) != 0 {
+		t.Errorf("Expected no suggestions while typing a value, got %+v", got)
+	}
+}
+
 func TestAutocompleteSuggestsVariables(t *testing.T) {
 	env := evaluator.NewEnvironment()
 	u := units.NewSystem()
@@ -227,3 +245,27 @@ func TestAutocompleteSuggestsUnits(t *testing.T) {
 		t.Error("Expected 'km' and 'kg' units in suggestions for 'k'")
 	}
 }
+
+func TestAutocompleteSuggestsRegisteredFunctions(t *testing.T) {
+	err := evaluator.Register(evaluator.Function{
+		Name:        "zmargin",
+		Description: "Profit margin",
+		MinArgs:     2,
+		MaxArgs:     2,
+		Call:        func(args []evaluator.Value) evaluator.Value { return args[0] },
+	})
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	ac := NewAutocompleteEngine(evaluator.NewEnvironment(), units.NewSystem(), currency.NewSystem(), settings.Default())
+	for _, sugg := range ac.GetSuggestions("zmar") {
+		if sugg.Text == "zmargin(" && sugg.Category == "function" {
+			if sugg.Display != "zmargin(x, y)" || sugg.Description != "Profit margin" {
+				t.Errorf("suggestion = %+v", sugg)
+			}
+			return
+		}
+	}
+	t.Error("Expected 'zmargin(' in suggestions")
+}
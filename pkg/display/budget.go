@@ -0,0 +1,116 @@
+package display
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/andrewneudegg/calc/pkg/evaluator"
+	"github.com/andrewneudegg/calc/pkg/lexer"
+	"github.com/andrewneudegg/calc/pkg/parser"
+)
+
+// budget tracks spending against an amount set with :budget or a line such
+// as budget = £500. Every money result on a later line counts as spent.
+type budget struct {
+	amount  evaluator.Value // The money amount being spent against
+	since   int             // Line ID the budget was set on; later lines count
+	queries map[int]bool    // Lines that read remaining, left or spent, which are not spending
+}
+
+// budgetWords are the names that read the budget. A variable of the same
+// name takes precedence.
+var budgetWords = map[string]bool{"remaining": true, "left": true, "spent": true}
+
+// budgetValue gives remaining and left, the budget less what has been
+// spent, and spent, the sum of the money lines since the budget was set.
+func (r *REPL) budgetValue(name string) (evaluator.Value, bool) {
+	word := strings.ToLower(name)
+	if r.budget == nil || !budgetWords[word] {
+		return evaluator.Value{}, false
+	}
+	r.readBudget = true
+	spent, err := r.budgetSpent()
+	if err != nil {
+		return evaluator.NewError(err.Error()), true
+	}
+	cur := r.budget.amount.Currency
+	if word == "spent" {
+		return evaluator.NewCurrency(spent, cur), true
+	}
+	return evaluator.NewCurrency(r.budget.amount.Number-spent, cur), true
+}
+
+// budgetSpent adds up the money results since the budget was set, in the
+// budget's currency. Lines that failed or that read the budget are left out.
+func (r *REPL) budgetSpent() (float64, error) {
+	var total float64
+	for id := r.budget.since + 1; id < r.nextID; id++ {
+		line, ok := r.lines[id]
+		if !ok || r.budget.queries[id] || line.Result.Type != evaluator.ValueCurrency {
+			continue
+		}
+		amount, err := r.env.Currency().Convert(line.Result.Number, line.Result.Currency, r.budget.amount.Currency)
+		if err != nil {
+			return 0, fmt.Errorf("line %d: %s", id, err)
+		}
+		total += amount
+	}
+	return total, nil
+}
+
+// trackBudget updates the budget once line lineID has been evaluated: a line
+// that read the budget is noted so it is not counted as spending, and an
+// assignment to budget of a money amount starts tracking afresh.
+func (r *REPL) trackBudget(lineID int, expr parser.Expr, result evaluator.Value) {
+	if r.readBudget {
+		r.readBudget = false
+		if r.budget != nil {
+			r.budget.queries[lineID] = true
+		}
+	}
+	if assign, ok := expr.(*parser.AssignExpr); ok && strings.EqualFold(assign.Name, "budget") && result.Type == evaluator.ValueCurrency {
+		r.budget = &budget{amount: result, since: lineID, queries: map[int]bool{}}
+	}
+}
+
+// budgetCommand runs :budget. With an amount it starts tracking from the
+// next line, "reset" stops tracking, and on its own it reports progress.
+func (r *REPL) budgetCommand(tail string) string {
+	switch strings.ToLower(tail) {
+	case "":
+		if r.budget == nil {
+			return "no budget set (use :budget £500 or budget = £500)"
+		}
+		spent, err := r.budgetSpent()
+		if err != nil {
+			return fmt.Sprintf("error: %s", err)
+		}
+		cur := r.budget.amount.Currency
+		return fmt.Sprintf("budget %s, spent %s, remaining %s",
+			r.formatter.Format(r.budget.amount),
+			r.formatter.Format(evaluator.NewCurrency(spent, cur)),
+			r.formatter.Format(evaluator.NewCurrency(r.budget.amount.Number-spent, cur)))
+	case "reset":
+		r.budget = nil
+		return "budget cleared"
+	}
+
+	tokens := r.lex(tail)
+	if n := len(tokens); n > 0 && tokens[n-1].Type == lexer.TokenEOF {
+		tokens = tokens[:n-1]
+	}
+	expr, err := r.parse(tokens)
+	if err != nil {
+		return fmt.Sprintf("error: %s", err)
+	}
+	amount := r.eval.Eval(expr)
+	r.readBudget = false
+	if amount.IsError() {
+		return fmt.Sprintf("error: %s", amount.Error)
+	}
+	if amount.Type != evaluator.ValueCurrency {
+		return "error: a budget must be an amount of money, e.g. :budget £500"
+	}
+	r.budget = &budget{amount: amount, since: r.nextID - 1, queries: map[int]bool{}}
+	return fmt.Sprintf("budget %s set; money on the lines that follow counts as spent", r.formatter.Format(amount))
+}
@@ -0,0 +1,278 @@
+package display
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/andrewneudegg/calc/pkg/evaluator"
+	"github.com/andrewneudegg/calc/pkg/lexer"
+	"github.com/andrewneudegg/calc/pkg/parser"
+)
+
+// chartPoint is one labelled value of a chart.
+type chartPoint struct {
+	label string
+	value float64 // What the bar is drawn from, in the first point's unit or currency
+	text  string  // The value as shown, e.g. £1,200.00
+}
+
+// chartStyle is how a chart is drawn.
+type chartStyle struct {
+	width int                 // Columns the chart may take
+	ascii bool                // Draw with # and punctuation rather than block characters
+	paint func(string) string // Colours the bars; nil leaves them plain
+}
+
+// minBarWidth is the fewest columns bars get, however narrow the terminal.
+const minBarWidth = 10
+
+// eighthBlocks are the blocks one to seven eighths of a column wide, which
+// end a bar part way through a column.
+var eighthBlocks = []rune("▏▎▍▌▋▊▉")
+
+// sparkLevels are the heights of a sparkline, lowest first.
+var (
+	sparkLevels      = []rune("▁▂▃▄▅▆▇█")
+	asciiSparkLevels = []rune("_.-:=+*#")
+)
+
+// renderBars draws points as a horizontal bar chart, one line each: the
+// label, the bar and the value as shown. Bars are scaled so the longest
+// fills the columns the labels and values leave. Negative values extend
+// left of a zero column, and values that are all zero draw no bars.
+func renderBars(points []chartPoint, style chartStyle) []string {
+	labelWidth, textWidth := 0, 0
+	lo, hi := 0.0, 0.0
+	for _, p := range points {
+		labelWidth = max(labelWidth, len([]rune(p.label)))
+		textWidth = max(textWidth, len([]rune(p.text)))
+		lo, hi = min(lo, p.value), max(hi, p.value)
+	}
+	barWidth := max(style.width-labelWidth-textWidth-2, minBarWidth)
+	span := hi - lo
+	zero := 0
+	if span > 0 {
+		zero = int(math.Round(-lo / span * float64(barWidth)))
+	}
+
+	full := "█"
+	if style.ascii {
+		full = "#"
+	}
+	lines := make([]string, 0, len(points))
+	for _, p := range points {
+		var bar string
+		switch {
+		case span == 0:
+		case p.value < 0:
+			// Rounding must not carry a bar past either end
+			n := min(int(math.Round(-p.value/span*float64(barWidth))), zero)
+			bar = strings.Repeat(" ", zero-n) + strings.Repeat(full, n)
+		default:
+			cells := min(p.value/span*float64(barWidth), float64(barWidth-zero))
+			bar = strings.Repeat(" ", zero) + barCells(cells, style.ascii)
+		}
+		bar += strings.Repeat(" ", barWidth-len([]rune(bar)))
+		if style.paint != nil {
+			bar = style.paint(bar)
+		}
+		lines = append(lines, fmt.Sprintf("%-*s %s %*s", labelWidth, p.label, bar, textWidth, p.text))
+	}
+	return lines
+}
+
+// barCells draws a bar cells columns long, ending in a partial block unless
+// ascii limits it to whole columns.
+func barCells(cells float64, ascii bool) string {
+	if ascii {
+		return strings.Repeat("#", int(math.Round(cells)))
+	}
+	whole := int(cells)
+	eighths := int(math.Round((cells - float64(whole)) * 8))
+	if eighths == 8 {
+		whole, eighths = whole+1, 0
+	}
+	bar := strings.Repeat("█", whole)
+	if eighths > 0 {
+		bar += string(eighthBlocks[eighths-1])
+	}
+	return bar
+}
+
+// renderSparkline draws points as a sparkline, one character each, from the
+// lowest value to the highest, followed by a line naming both. Values that
+// are all the same sit halfway up.
+func renderSparkline(points []chartPoint, style chartStyle) []string {
+	levels := sparkLevels
+	if style.ascii {
+		levels = asciiSparkLevels
+	}
+	lo, hi := points[0], points[0]
+	for _, p := range points {
+		if p.value < lo.value {
+			lo = p
+		}
+		if p.value > hi.value {
+			hi = p
+		}
+	}
+	var spark strings.Builder
+	for _, p := range points {
+		level := len(levels) / 2
+		if span := hi.value - lo.value; span > 0 {
+			level = int(math.Round((p.value - lo.value) / span * float64(len(levels)-1)))
+		}
+		spark.WriteRune(levels[level])
+	}
+	line := spark.String()
+	if style.paint != nil {
+		line = style.paint(line)
+	}
+	return []string{line, fmt.Sprintf("min %s (%s), max %s (%s)", lo.text, lo.label, hi.text, hi.label)}
+}
+
+// renderCSV writes points as label,value rows, the values as plain numbers.
+func renderCSV(points []chartPoint) []string {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	for _, p := range points {
+		w.Write([]string{p.label, strconv.FormatFloat(p.value, 'f', -1, 64)})
+	}
+	w.Flush()
+	return strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+}
+
+// chartCommand runs :chart. It charts the variables named, expanding one
+// that holds several values, such as a list of conversions, and the lines
+// filed under a #tag. --csv prints label,value rows instead and --spark a
+// sparkline.
+func (r *REPL) chartCommand(tail string) string {
+	var args []string
+	csvRows, spark := false, false
+	for _, arg := range strings.Fields(tail) {
+		switch strings.ToLower(arg) {
+		case "--csv":
+			csvRows = true
+		case "--spark":
+			spark = true
+		default:
+			args = append(args, arg)
+		}
+	}
+	if len(args) == 0 {
+		return "usage: :chart <variable or #tag>... [--spark] [--csv]"
+	}
+
+	points, err := r.chartPoints(args)
+	if err != nil {
+		return fmt.Sprintf("error: %s", err)
+	}
+	style := chartStyle{width: chartWidth(), ascii: r.formatter.ASCII()}
+	if colourStdout() {
+		style.paint = func(s string) string { return r.theme.wrap(s, r.theme.Number) }
+	}
+	var lines []string
+	switch {
+	case csvRows:
+		lines = renderCSV(points)
+	case spark:
+		lines = renderSparkline(points, style)
+	default:
+		lines = renderBars(points, style)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// chartPoints gathers the values :chart draws, in the order named. They
+// must be numbers or amounts of one kind; amounts in other units or
+// currencies are converted to the first's so their bars compare.
+func (r *REPL) chartPoints(args []string) ([]chartPoint, error) {
+	var values []evaluator.Value
+	var labels []string
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "#") {
+			lines := r.taggedLines(lexer.TagName(arg))
+			if len(lines) == 0 {
+				return nil, fmt.Errorf("no lines are tagged %s", arg)
+			}
+			for _, line := range lines {
+				label := fmt.Sprintf("line %d", line.ID)
+				if assign, ok := line.Expr.(*parser.AssignExpr); ok {
+					label = assign.Name
+				}
+				values = append(values, line.Result)
+				labels = append(labels, label)
+			}
+			continue
+		}
+		v, ok := r.env.GetVariable(arg)
+		if !ok {
+			return nil, fmt.Errorf("undefined variable: %s", arg)
+		}
+		if name, ok := r.env.VariableName(arg); ok {
+			arg = name
+		}
+		if v.Type != evaluator.ValueList {
+			values = append(values, v)
+			labels = append(labels, arg)
+			continue
+		}
+		for i, item := range v.Items {
+			label := item.Label
+			if label == "" {
+				label = fmt.Sprintf("%s %d", arg, i+1)
+			}
+			values = append(values, item)
+			labels = append(labels, label)
+		}
+	}
+
+	first := values[0]
+	points := make([]chartPoint, len(values))
+	for i, v := range values {
+		n, err := r.chartValue(v, first)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", labels[i], err)
+		}
+		v.Label = ""
+		points[i] = chartPoint{label: labels[i], value: n, text: strings.TrimSpace(r.formatter.Format(v))}
+	}
+	return points, nil
+}
+
+// chartValue returns the number v is charted at beside first: its own for
+// a plain number or percentage, or converted to first's currency or unit.
+func (r *REPL) chartValue(v, first evaluator.Value) (float64, error) {
+	switch v.Type {
+	case evaluator.ValueNumber, evaluator.ValuePercent, evaluator.ValueCurrency, evaluator.ValueUnit:
+	default:
+		return 0, fmt.Errorf("only numbers and amounts can be charted")
+	}
+	if v.Type != first.Type {
+		return 0, fmt.Errorf("cannot chart %s beside %s", strings.TrimSpace(r.formatter.Format(v)), strings.TrimSpace(r.formatter.Format(first)))
+	}
+	switch {
+	case v.Type == evaluator.ValueCurrency && v.Currency != first.Currency:
+		return r.env.Currency().Convert(v.Number, v.Currency, first.Currency)
+	case v.Type == evaluator.ValueUnit && v.Unit != first.Unit:
+		return r.env.Units().Convert(v.Number, v.Unit, first.Unit)
+	}
+	return v.Number, nil
+}
+
+// chartWidth is the width charts are drawn to: the terminal's, or else
+// $COLUMNS, or 80 columns.
+func chartWidth() int {
+	if w := terminalWidth(os.Stdout.Fd()); w > 0 {
+		return w
+	}
+	if w, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && w > 0 {
+		return w
+	}
+	return 80
+}
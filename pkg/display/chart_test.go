@@ -0,0 +1,200 @@
+package display
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderBars(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []chartPoint
+		want   []string
+	}{
+		{
+			name: "scaled to the largest",
+			points: []chartPoint{
+				{label: "jan", value: 10, text: "£10.00"},
+				{label: "feb", value: 5, text: "£5.00"},
+				{label: "march", value: 2.5, text: "£2.50"},
+			},
+			want: []string{
+				"jan   ███████████████████ £10.00",
+				"feb   █████████▌           £5.00",
+				"march ████▊                £2.50",
+			},
+		},
+		{
+			name: "partial blocks round to whole ones",
+			points: []chartPoint{
+				{label: "a", value: 20, text: "20"},
+				{label: "b", value: 1.5, text: "1.5"},
+			},
+			want: []string{
+				"a ██████████████████████████  20",
+				"b ██                         1.5",
+			},
+		},
+		{
+			name: "negative values left of zero",
+			points: []chartPoint{
+				{label: "in", value: 15, text: "15"},
+				{label: "out", value: -5, text: "-5"},
+			},
+			want: []string{
+				"in        ██████████████████▊ 15",
+				"out ██████                    -5",
+			},
+		},
+		{
+			name: "all negative",
+			points: []chartPoint{
+				{label: "a", value: -4, text: "-4"},
+				{label: "b", value: -2, text: "-2"},
+			},
+			want: []string{
+				"a ███████████████████████████ -4",
+				"b              ██████████████ -2",
+			},
+		},
+		{
+			name: "all equal",
+			points: []chartPoint{
+				{label: "a", value: 7, text: "7"},
+				{label: "b", value: 7, text: "7"},
+			},
+			want: []string{
+				"a ████████████████████████████ 7",
+				"b ████████████████████████████ 7",
+			},
+		},
+		{
+			name:   "single point",
+			points: []chartPoint{{label: "only", value: 3, text: "3"}},
+			want:   []string{"only █████████████████████████ 3"},
+		},
+		{
+			name: "all zero",
+			points: []chartPoint{
+				{label: "a", value: 0, text: "0"},
+				{label: "b", value: 0, text: "0"},
+			},
+			want: []string{
+				"a                              0",
+				"b                              0",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := renderBars(tt.points, chartStyle{width: 32})
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestRenderBarsStyle(t *testing.T) {
+	points := []chartPoint{
+		{label: "a", value: 4, text: "4"},
+		{label: "b", value: 1, text: "1"},
+	}
+
+	got := renderBars(points, chartStyle{width: 16, ascii: true})
+	want := []string{"a ############ 4", "b ###          1"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("ascii: got %q, want %q", got, want)
+	}
+
+	// Narrow terminals still get a readable bar
+	if got := renderBars(points, chartStyle{width: 5, ascii: true}); got[0] != "a ########## 4" {
+		t.Errorf("narrow: got %q", got[0])
+	}
+
+	paint := func(s string) string { return "<" + s + ">" }
+	if got := renderBars(points, chartStyle{width: 16, ascii: true, paint: paint}); got[1] != "b <###         > 1" {
+		t.Errorf("painted: got %q", got[1])
+	}
+}
+
+func TestRenderSparkline(t *testing.T) {
+	points := []chartPoint{
+		{label: "jan", value: 1, text: "£1.00"},
+		{label: "feb", value: 8, text: "£8.00"},
+		{label: "mar", value: -6, text: "-£6.00"},
+		{label: "apr", value: 4, text: "£4.00"},
+	}
+	got := renderSparkline(points, chartStyle{})
+	want := []string{"▅█▁▆", "min -£6.00 (mar), max £8.00 (feb)"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if got := renderSparkline(points, chartStyle{ascii: true}); got[0] != "=#_+" {
+		t.Errorf("ascii: got %q", got[0])
+	}
+
+	same := []chartPoint{{label: "a", value: 2, text: "2"}, {label: "b", value: 2, text: "2"}}
+	if got := renderSparkline(same, chartStyle{}); got[0] != "▅▅" {
+		t.Errorf("all equal: got %q", got[0])
+	}
+	if got := renderSparkline(same[:1], chartStyle{}); got[0] != "▅" {
+		t.Errorf("single point: got %q", got[0])
+	}
+}
+
+func TestRenderCSV(t *testing.T) {
+	got := renderCSV([]chartPoint{
+		{label: "jan", value: 1200.5},
+		{label: "rent, flat", value: -3},
+	})
+	want := []string{"jan,1200.5", `"rent, flat",-3`}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestChartCommand(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+
+	for _, line := range []string{
+		"jan = £100",
+		"feb = £200",
+		"coffee = £3 #food",
+		"$10 #food",
+		"split = £100 in usd, eur",
+		"walk = 5 km",
+	} {
+		r.EvaluateLine(line)
+	}
+
+	tests := []struct {
+		tail string
+		want string
+	}{
+		{"jan feb --csv", "jan,100\nfeb,200"},
+		{"#food --csv", "coffee,3\nline 4,7.874015748031496"},
+		{"split --csv", "split 1,127\nsplit 2,127"},
+		{"JAN --csv", "jan,100"},
+		{"jan feb --spark", "▁█\nmin £100.00 (jan), max £200.00 (feb)"},
+		{"", "usage: :chart <variable or #tag>... [--spark] [--csv]"},
+		{"jan rent", "error: undefined variable: rent"},
+		{"#travel", "error: no lines are tagged #travel"},
+		{"jan walk", "error: walk: cannot chart 5.00 km beside £100.00"},
+	}
+	for _, tt := range tests {
+		if got := r.chartCommand(tt.tail); got != tt.want {
+			t.Errorf(":chart %s = %q, want %q", tt.tail, got, tt.want)
+		}
+	}
+
+	// Bars fill the terminal, whose width varies
+	bars := strings.Split(r.chartCommand("jan feb"), "\n")
+	if len(bars) != 2 || !strings.HasPrefix(bars[0], "jan █") || !strings.HasSuffix(bars[1], "█ £200.00") {
+		t.Errorf(":chart jan feb = %q", bars)
+	}
+}
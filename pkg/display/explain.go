@@ -0,0 +1,111 @@
+package display
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/andrewneudegg/calc/pkg/alias"
+	"github.com/andrewneudegg/calc/pkg/evaluator"
+	"github.com/andrewneudegg/calc/pkg/formatter"
+	"github.com/andrewneudegg/calc/pkg/lexer"
+	"github.com/andrewneudegg/calc/pkg/parser"
+	"github.com/andrewneudegg/calc/pkg/rounding"
+)
+
+// explainCommand runs :explain. "on" and "off" switch explain mode, which
+// prints a trace before each result, and no argument toggles it. Anything
+// else is an expression to explain once.
+func (r *REPL) explainCommand(tail string) string {
+	switch strings.ToLower(tail) {
+	case "":
+		r.explain = !r.explain
+	case "on":
+		r.explain = true
+	case "off":
+		r.explain = false
+	default:
+		return r.explainOnce(tail)
+	}
+	if r.explain {
+		return "explain: on"
+	}
+	return "explain: off"
+}
+
+// explainOnce traces a single expression without adding it to the session's
+// lines. Assignments still set their variable.
+func (r *REPL) explainOnce(input string) string {
+	tokens := r.lex(input)
+	if n := len(tokens); n > 0 && tokens[n-1].Type == lexer.TokenEOF {
+		tokens = tokens[:n-1]
+	}
+	if len(tokens) > 0 && tokens[0].Type == lexer.TokenColon {
+		return "error: :explain takes an expression, not a command"
+	}
+	tokens, err := alias.Expand(tokens, r.settings.Aliases, r.lex)
+	if err != nil {
+		return fmt.Sprintf("error: %s", err)
+	}
+	expr, err := r.parse(tokens)
+	if err != nil {
+		return r.explainReport(tokens, nil, nil) + fmt.Sprintf("\nerror: %s", err)
+	}
+
+	r.env.SetRounding(rounding.Mode(r.settings.Rounding))
+	r.env.SetDecimal(r.settings.Decimal)
+	r.env.SetStrict(r.settings.Strict)
+	r.env.SetMonthOverflow(r.settings.MonthArithmetic == "overflow")
+	result, steps := r.evalTraced(expr)
+	r.env.TakeUndefined()
+	return r.explainReport(tokens, expr, steps) + "\nresult: " + r.formatter.Format(result)
+}
+
+// evalTraced evaluates expr, collecting the evaluator's steps.
+func (r *REPL) evalTraced(expr parser.Expr) (evaluator.Value, []evaluator.Step) {
+	var steps []evaluator.Step
+	r.eval.SetTrace(func(s evaluator.Step) { steps = append(steps, s) })
+	defer r.eval.SetTrace(nil)
+	return r.eval.EvalContext(r.evalContext(), expr), steps
+}
+
+// explainReport lays out the tokens, parse tree and evaluation steps behind a
+// result, one section per line:
+//
+//	tokens: NUMBER(50) UNIT(cm) IN(in) UNIT(m)
+//	parsed: (in (unit 50 cm) m)
+//	steps:
+//	  converted 50.00 cm → 0.50 m
+func (r *REPL) explainReport(tokens []lexer.Token, expr parser.Expr, steps []evaluator.Step) string {
+	var b strings.Builder
+	b.WriteString("tokens:")
+	for _, tok := range tokens {
+		fmt.Fprintf(&b, " %s(%s)", tok.Type, tok.Literal)
+	}
+	if expr != nil {
+		b.WriteString("\nparsed: " + parser.SExpr(expr))
+		b.WriteString("\nsteps:")
+		if len(steps) == 0 {
+			b.WriteString(" none")
+		}
+		for _, s := range steps {
+			b.WriteString("\n  " + r.formatStep(s))
+		}
+	}
+	if r.formatter.ASCII() {
+		return formatter.ToASCII(b.String())
+	}
+	return b.String()
+}
+
+// formatStep writes a step with its values formatted as results are.
+func (r *REPL) formatStep(s evaluator.Step) string {
+	args := make([]any, len(s.Args))
+	for i, v := range s.Args {
+		args[i] = r.formatter.Format(v)
+	}
+	line := fmt.Sprintf(s.Text, args...) + " → " + r.formatter.Format(s.Result)
+	if s.Note != "" {
+		line += " (" + s.Note + ")"
+	}
+	return line
+}
@@ -8,7 +8,8 @@ import (
 
 // Highlighter colorizes input using a Theme and the project's lexer.
 type Highlighter struct {
-	theme *Theme
+	theme  *Theme
+	isUnit func(string) bool // Recognises units for the lexer; nil uses its built-in table
 }
 
 func NewHighlighter(theme *Theme) *Highlighter {
@@ -44,6 +45,9 @@ func (h *Highlighter) Colorize(input string) string {
 
 	// Tokenize with the lexer and stitch back with colors applied per token type
 	l := lexer.New(input)
+	if h.isUnit != nil {
+		l = lexer.NewWithUnits(input, h.isUnit)
+	}
 	toks := l.AllTokens()
 	var b strings.Builder
 	pos := 0
@@ -79,7 +83,7 @@ func (h *Highlighter) Colorize(input string) string {
 func (h *Highlighter) colorToken(tt lexer.TokenType, s string) string {
 	t := h.theme
 	switch tt {
-	case lexer.TokenNumber:
+	case lexer.TokenNumber, lexer.TokenOrdinal, lexer.TokenDuration:
 		return t.wrap(s, t.Number)
 	case lexer.TokenUnit:
 		return t.wrap(s, t.Unit)
@@ -88,11 +92,11 @@ func (h *Highlighter) colorToken(tt lexer.TokenType, s string) string {
 	case lexer.TokenPlus, lexer.TokenMinus, lexer.TokenMultiply, lexer.TokenDivide, lexer.TokenPercent, lexer.TokenEquals,
 		lexer.TokenLParen, lexer.TokenRParen, lexer.TokenComma:
 		return t.wrap(s, t.Operator)
-	case lexer.TokenIn, lexer.TokenOf, lexer.TokenPer, lexer.TokenBy, lexer.TokenWhat, lexer.TokenIs,
+	case lexer.TokenIn, lexer.TokenTo, lexer.TokenOf, lexer.TokenPer, lexer.TokenBy, lexer.TokenWhat, lexer.TokenIs,
 		lexer.TokenIncrease, lexer.TokenDecrease, lexer.TokenSum, lexer.TokenAverage, lexer.TokenMean, lexer.TokenTotal,
 		lexer.TokenHalf, lexer.TokenDouble, lexer.TokenTwice, lexer.TokenQuarters, lexer.TokenThree, lexer.TokenAfter,
 		lexer.TokenBefore, lexer.TokenFrom, lexer.TokenAgo, lexer.TokenNow, lexer.TokenToday, lexer.TokenTomorrow,
-		lexer.TokenYesterday, lexer.TokenNext, lexer.TokenLast:
+		lexer.TokenYesterday, lexer.TokenNext, lexer.TokenLast, lexer.TokenAbout:
 		return t.wrap(s, t.Keyword)
 	case lexer.TokenDate:
 		return t.wrap(s, t.Date)
@@ -0,0 +1,40 @@
+package display
+
+import (
+	"context"
+	"os"
+	"os/signal"
+)
+
+// SetContext sets a context that stops evaluations once done, such as one
+// with the deadline of --timeout. Lines evaluated after it is done give an
+// "evaluation timed out" or "evaluation cancelled" error.
+func (r *REPL) SetContext(ctx context.Context) {
+	r.ctx = ctx
+}
+
+// evalContext returns the context evaluations run under.
+func (r *REPL) evalContext() context.Context {
+	if r.ctx == nil {
+		return context.Background()
+	}
+	return r.ctx
+}
+
+// interruptibly runs f with Ctrl-C cancelling the evaluations it makes,
+// which then stop with an "evaluation cancelled" error, rather than ending
+// the session. A terminal in raw mode, whose normal state is given, is put
+// back in its normal mode meanwhile so that Ctrl-C raises an interrupt.
+func (r *REPL) interruptibly(state *RawState, f func()) {
+	ctx, stop := signal.NotifyContext(r.evalContext(), os.Interrupt)
+	defer stop()
+	if state != nil {
+		fd := int(os.Stdin.Fd())
+		restoreRawMode(fd, state)
+		defer enableRawMode(fd)
+	}
+	prev := r.ctx
+	r.ctx = ctx
+	defer func() { r.ctx = prev }()
+	f()
+}
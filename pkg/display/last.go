@@ -0,0 +1,76 @@
+package display
+
+import (
+	"github.com/andrewneudegg/calc/pkg/evaluator"
+	"github.com/andrewneudegg/calc/pkg/lexer"
+)
+
+// lastName is the name that reads the most recent result that was not an
+// error. A variable of the same name takes precedence.
+const lastName = "_"
+
+// nameValue gives values to names that are neither variables nor
+// constants: _ for the last result, and the budget words.
+func (r *REPL) nameValue(name string) (evaluator.Value, bool) {
+	if name == lastName {
+		last, ok := r.lastResult()
+		if !ok {
+			return evaluator.NewError("no previous result for _"), true
+		}
+		return last, true
+	}
+	return r.budgetValue(name)
+}
+
+// lastResult returns the result of the most recent line that did not fail,
+// without the warning or rounding it was shown with.
+func (r *REPL) lastResult() (evaluator.Value, bool) {
+	for id := r.nextID - 1; id >= 1; id-- {
+		line, ok := r.lines[id]
+		if !ok || line.Result.IsError() {
+			continue
+		}
+		last := line.Result.Precise()
+		last.Warning = ""
+		return last, true
+	}
+	return evaluator.Value{}, false
+}
+
+// continueLast lets a line that starts with an operator carry on from the
+// last result, so "* 1.2" means "_ * 1.2". A leading +, * or / always does,
+// since no expression starts with one. A leading - only does when a space
+// follows it and then a money or unit amount, as in "- £12" or "- 5 km",
+// and there is a last result to take it from: "-5" and "- 5" are negative
+// five, and on the first line "- £12" is negative £12.
+func (r *REPL) continueLast(tokens []lexer.Token) []lexer.Token {
+	if len(tokens) < 2 {
+		return tokens
+	}
+	switch first := tokens[0]; first.Type {
+	case lexer.TokenPlus, lexer.TokenMultiply, lexer.TokenDivide:
+	case lexer.TokenMinus:
+		if tokens[1].Column == first.Column+1 || !isAmount(tokens[1:]) {
+			return tokens
+		}
+		if _, ok := r.lastResult(); !ok {
+			return tokens
+		}
+	default:
+		return tokens
+	}
+	last := lexer.Token{Type: lexer.TokenIdent, Literal: lastName, Line: tokens[0].Line, Column: tokens[0].Column}
+	return append([]lexer.Token{last}, tokens...)
+}
+
+// isAmount reports whether tokens start with a money or unit amount, such
+// as £12, 12 GBP or 5 km.
+func isAmount(tokens []lexer.Token) bool {
+	if tokens[0].Type == lexer.TokenCurrency {
+		return true
+	}
+	if tokens[0].Type != lexer.TokenNumber || len(tokens) < 2 {
+		return false
+	}
+	return tokens[1].Type == lexer.TokenUnit || tokens[1].Type == lexer.TokenCurrency
+}
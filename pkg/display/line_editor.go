@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"strings"
 	"unicode"
 )
 
@@ -20,6 +21,8 @@ type Editor struct {
 	suggestions    []Suggestion
 	suggestIndex   int    // Current suggestion index (-1 means no active suggestion)
 	originalBuf    []rune // Buffer state when suggestions were first generated
+	search         *historySearch // Active Ctrl-R search, nil when not searching
+	pasted         string         // Bracketed paste spanning lines, returned whole by ReadLine
 }
 
 // NewEditor creates a new editor instance for a single line entry.
@@ -41,6 +44,7 @@ func (e *Editor) SetHighlighter(fn func(string) string) { e.hlFn = fn }
 func (e *Editor) SetAutocompleteFn(fn func(string) []Suggestion) { e.autocompleteFn = fn }
 
 // ReadLine reads a line using raw key processing. It returns the line, whether it was aborted (Ctrl-C), and whether EOF (Ctrl-D on empty).
+// A bracketed paste of several lines is returned at once, so the line may contain newlines.
 func (e *Editor) ReadLine(r *bufio.Reader, w io.Writer) (string, bool, bool) {
 	e.render(w)
 	for {
@@ -48,9 +52,21 @@ func (e *Editor) ReadLine(r *bufio.Reader, w io.Writer) (string, bool, bool) {
 		if err != nil {
 			return "", false, true
 		}
+		if e.search != nil && e.searchKey(b, r) {
+			e.render(w)
+			continue
+		}
 		switch b {
 		case '\r', '\n':
-			// Submit line
+			// Submit line. A Windows console sends CRLF for text pasted
+			// without bracketing; take the LF with its CR rather than as an
+			// empty line of its own. Only a byte already read is checked, as
+			// waiting for one would hang after a lone Enter.
+			if b == '\r' && r.Buffered() > 0 {
+				if next, _ := r.Peek(1); next[0] == '\n' {
+					r.ReadByte()
+				}
+			}
 			fmt.Fprint(w, "\r\n")
 			return string(e.buf), false, false
 		case 0x01: // Ctrl-A
@@ -91,39 +107,55 @@ func (e *Editor) ReadLine(r *bufio.Reader, w io.Writer) (string, bool, bool) {
 			if e.cur < len(e.buf) {
 				e.buf = append(e.buf[:e.cur], e.buf[e.cur+1:]...)
 			}
-		case 0x03: // Ctrl-C abort line
+		case 0x0c: // Ctrl-L clear screen, keeping the line being edited
+			fmt.Fprint(w, "\x1b[H\x1b[2J")
+		case 0x12: // Ctrl-R reverse history search
+			e.clearSuggestions()
+			e.startSearch()
+		case 0x03: // Ctrl-C abort line, leaving the prompt empty
+			e.clearSuggestions()
 			e.buf = e.buf[:0]
 			e.cur = 0
+			e.render(w)
 			return "", true, false
 		case 0x09: // Tab - trigger autocomplete
 			e.handleTab()
 		case 0x1b: // ESC sequence
 			e.handleEscape(r)
+			if e.pasted != "" {
+				fmt.Fprint(w, "\r\n")
+				return e.pasted, false, false
+			}
 		default:
-			// Insert printable rune (assuming UTF-8 single-byte for ASCII; extend minimal multi-byte support)
-			if b < 0x80 && (b == ' ' || b >= 0x21) {
-				e.insertRune(rune(b))
-			} else if b&0xE0 == 0xC0 { // 2-byte UTF-8 start
-				rest := make([]byte, 1)
-				io.ReadFull(r, rest)
-				rr := utf8Decode([]byte{b, rest[0]})
-				e.insertRune(rr)
-			} else if b&0xF0 == 0xE0 { // 3-byte
-				rest := make([]byte, 2)
-				io.ReadFull(r, rest)
-				rr := utf8Decode(append([]byte{b}, rest...))
-				e.insertRune(rr)
-			} else if b&0xF8 == 0xF0 { // 4-byte
-				rest := make([]byte, 3)
-				io.ReadFull(r, rest)
-				rr := utf8Decode(append([]byte{b}, rest...))
-				e.insertRune(rr)
+			if rn, ok := readRune(b, r); ok {
+				e.insertRune(rn)
 			}
 		}
 		e.render(w)
 	}
 }
 
+// readRune decodes the printable rune starting with byte b, reading any
+// continuation bytes of a multi-byte UTF-8 sequence such as £ or µ from r.
+func readRune(b byte, r *bufio.Reader) (rune, bool) {
+	var n int
+	switch {
+	case b < 0x80:
+		return rune(b), b == ' ' || b >= 0x21 && b != 0x7f
+	case b&0xE0 == 0xC0: // 2-byte UTF-8 start
+		n = 1
+	case b&0xF0 == 0xE0: // 3-byte
+		n = 2
+	case b&0xF8 == 0xF0: // 4-byte
+		n = 3
+	default:
+		return 0, false
+	}
+	rest := make([]byte, n)
+	io.ReadFull(r, rest)
+	return utf8Decode(append([]byte{b}, rest...)), true
+}
+
 func (e *Editor) insertRune(rn rune) {
 	// Clear suggestions when user types something new
 	e.clearSuggestions()
@@ -155,6 +187,10 @@ func (e *Editor) handleEscape(r *bufio.Reader) {
 				// command byte
 				cmd := b
 				param := buf.String()
+				if cmd == '~' && param == "200" { // Bracketed paste start: ESC [200~
+					e.readPaste(r)
+					return
+				}
 				e.handleCSI(cmd, param)
 				return
 			}
@@ -164,13 +200,23 @@ func (e *Editor) handleEscape(r *bufio.Reader) {
 			}
 		}
 	} else {
-		// Possibly ESC b / ESC f
+		// Possibly ESC b / ESC f, or ESC ESC [ C/D sent for Alt-arrows by some terminals
 		b, _ := r.ReadByte()
 		switch b {
 		case 'b':
 			e.cur = e.wordLeft()
 		case 'f':
 			e.cur = e.wordRight()
+		case 0x1b:
+			if next, _ := r.Peek(2); len(next) == 2 && next[0] == '[' {
+				r.Discard(2)
+				switch next[1] {
+				case 'C':
+					e.cur = e.wordRight()
+				case 'D':
+					e.cur = e.wordLeft()
+				}
+			}
 		}
 	}
 }
@@ -185,14 +231,14 @@ func (e *Editor) handleCSI(cmd byte, param string) {
 		e.historyNext()
 	case 'C': // Right
 		e.clearSuggestions()
-		if stringsHasSuffix(param, "1;5") { // Ctrl-Right
+		if isWordModifier(param) { // Ctrl-Right or Alt-Right
 			e.cur = e.wordRight()
 		} else if e.cur < len(e.buf) {
 			e.cur++
 		}
 	case 'D': // Left
 		e.clearSuggestions()
-		if stringsHasSuffix(param, "1;5") { // Ctrl-Left
+		if isWordModifier(param) { // Ctrl-Left or Alt-Left
 			e.cur = e.wordLeft()
 		} else if e.cur > 0 {
 			e.cur--
@@ -273,11 +319,29 @@ func (e *Editor) wordRight() int {
 	return i
 }
 
+// isWordModifier reports whether CSI parameters carry the Ctrl (5) or Alt
+// (3, or 9 on some macOS terminals) modifier that turns arrows into word moves.
+func isWordModifier(param string) bool {
+	return stringsHasSuffix(param, "1;5") || stringsHasSuffix(param, "1;3") || stringsHasSuffix(param, "1;9")
+}
+
 func isWordRune(r rune) bool {
 	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
 }
 
+// render draws the prompt and buffer in a single write, so that nothing
+// printed meanwhile, such as a tip, can land in the middle of them.
 func (e *Editor) render(w io.Writer) {
+	var b strings.Builder
+	if e.search != nil {
+		e.renderSearch(&b)
+	} else {
+		e.renderLine(&b)
+	}
+	writeFrame(w, b.String())
+}
+
+func (e *Editor) renderLine(w io.Writer) {
 	// Move to line start, clear line, print prompt and buffer, then move cursor back if needed
 	fmt.Fprint(w, "\r\x1b[2K")
 	fmt.Fprint(w, e.prompt)
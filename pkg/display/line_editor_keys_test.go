@@ -0,0 +1,110 @@
+package display
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestEditor_CtrlL_KeepsInput(t *testing.T) {
+	ed := NewEditor("> ", nil)
+	r := bufio.NewReader(strings.NewReader("10 km\x0c in miles\n"))
+	var out bytes.Buffer
+	line, _, _ := ed.ReadLine(r, &out)
+	if line != "10 km in miles" {
+		t.Fatalf("expected input to survive Ctrl-L, got %q", line)
+	}
+	if !strings.Contains(out.String(), "\x1b[H\x1b[2J") {
+		t.Errorf("expected a clear-screen sequence, got %q", out.String())
+	}
+}
+
+func TestEditor_ReverseSearch(t *testing.T) {
+	history := []string{"10 km in miles", "£20 + 5%", "3 * 4", "5 km in miles"}
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"newest match", "\x12km\n", "5 km in miles"},
+		{"ctrl-r steps to older match", "\x12km\x12\n", "10 km in miles"},
+		{"multi-byte query", "\x12£\n", "£20 + 5%"},
+		{"backspace widens the search", "\x12*x\x7f\n", "3 * 4"},
+		{"no match keeps last match", "\x12kmz\n", "5 km in miles"},
+		{"ctrl-g restores the line", "typed\x12km\x07\n", "typed"},
+		{"other keys edit the match", "\x12£20\x05 * 2\n", "£20 + 5% * 2"},
+		{"arrow leaves search at match", "\x12+\x1b[CX\n", "£20 +X 5%"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line, aborted, eof := runEditor(t, history, []byte(tt.input))
+			if aborted || eof {
+				t.Fatalf("unexpected aborted=%v eof=%v", aborted, eof)
+			}
+			if line != tt.want {
+				t.Errorf("got %q, want %q", line, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditor_ReverseSearchPrompt(t *testing.T) {
+	ed := NewEditor("> ", []string{"5 km in miles"})
+	r := bufio.NewReader(strings.NewReader("\x12km\x12x\n"))
+	var out bytes.Buffer
+	ed.ReadLine(r, &out)
+	s := out.String()
+	if !strings.Contains(s, "(reverse-i-search)`km': 5 km in miles") {
+		t.Errorf("expected the match inline with the query, got %q", s)
+	}
+	if !strings.Contains(s, "(failed reverse-i-search)`kmx'") {
+		t.Errorf("expected a failed search prompt, got %q", s)
+	}
+}
+
+func TestEditor_AltArrowWordMovement(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"xterm alt-left", "10 km in miles\x1b[1;3D\x1b[1;3DX\n", "10 km Xin miles"},
+		{"macOS alt-left", "10 km in miles\x1b[1;9DX\n", "10 km in Xmiles"},
+		{"escape-prefixed alt-left", "10 km in miles\x1b\x1b[DX\n", "10 km in Xmiles"},
+		{"alt-right", "10 km in miles\x01\x1b[1;3C\x1b[1;3CX\n", "10 kmX in miles"},
+		{"escape-prefixed alt-right", "10 km\x01\x1b\x1b[CX\n", "10X km"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line, _, _ := runEditor(t, nil, []byte(tt.input))
+			if line != tt.want {
+				t.Errorf("got %q, want %q", line, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditor_MultiByteEditing(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"insert", "£5 + €3 + 2µs\n", "£5 + €3 + 2µs"},
+		{"backspace removes a whole rune", "€£\x7f\n", "€"},
+		{"ctrl-u before cursor", "£5 €3\x1b[D\x1b[D\x15\n", "€3"},
+		{"ctrl-k after cursor", "£5 €3\x1b[D\x1b[D\x0b\n", "£5 "},
+		{"ctrl-w previous word", "£5 µg\x17\n", "£5 "},
+		{"insert between multi-byte runes", "£€\x1b[Dµ\n", "£µ€"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line, _, _ := runEditor(t, nil, []byte(tt.input))
+			if line != tt.want {
+				t.Errorf("got %q, want %q", line, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package display
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// historySearch is the state of an incremental reverse search started with Ctrl-R.
+type historySearch struct {
+	query   []rune
+	match   int    // Index in history of the current match, -1 before the first
+	failed  bool   // The query matches nothing at or before match
+	saveBuf []rune // Line being edited when the search started, restored by Ctrl-G
+	saveCur int
+}
+
+// startSearch enters reverse search mode, remembering the line being edited.
+func (e *Editor) startSearch() {
+	e.search = &historySearch{
+		match:   -1,
+		saveBuf: append([]rune{}, e.buf...),
+		saveCur: e.cur,
+	}
+}
+
+// searchKey handles byte b while a reverse search is active. It reports false
+// when the key ends the search, leaving the match in the buffer for the key's
+// usual action: Enter submits it, arrows and Ctrl-A/Ctrl-E edit it.
+func (e *Editor) searchKey(b byte, r *bufio.Reader) bool {
+	s := e.search
+	switch b {
+	case 0x12: // Ctrl-R: next older match
+		from := s.match - 1
+		if s.match == -1 {
+			from = len(e.hist) - 1
+		}
+		e.findMatch(from)
+		return true
+	case 0x07: // Ctrl-G: abandon the search
+		e.buf, e.cur = s.saveBuf, s.saveCur
+		e.search = nil
+		return true
+	case 0x7f, 0x08: // Backspace shortens the query and searches again from the newest entry
+		if len(s.query) > 0 {
+			s.query = s.query[:len(s.query)-1]
+			e.findMatch(len(e.hist) - 1)
+		}
+		return true
+	}
+	if b < 0x20 || b == 0x7f || b == 0x1b {
+		e.search = nil
+		return false
+	}
+	rn, ok := readRune(b, r)
+	if !ok {
+		return true
+	}
+	s.query = append(s.query, rn)
+	from := s.match
+	if from == -1 {
+		from = len(e.hist) - 1
+	}
+	e.findMatch(from)
+	return true
+}
+
+// findMatch looks for the query in history from index from towards the
+// oldest entry, showing the match in the buffer with the cursor on it.
+func (e *Editor) findMatch(from int) {
+	s := e.search
+	query := string(s.query)
+	for i := min(from, len(e.hist)-1); i >= 0; i-- {
+		if idx := strings.Index(e.hist[i], query); idx >= 0 {
+			s.match = i
+			s.failed = false
+			e.buf = []rune(e.hist[i])
+			e.cur = utf8.RuneCountInString(e.hist[i][:idx])
+			return
+		}
+	}
+	s.failed = true
+}
+
+// renderSearch draws the search prompt with the query and the matching line inline.
+func (e *Editor) renderSearch(w io.Writer) {
+	label := "(reverse-i-search)"
+	if e.search.failed {
+		label = "(failed reverse-i-search)"
+	}
+	prefix := fmt.Sprintf("%s`%s': ", label, string(e.search.query))
+	fmt.Fprint(w, "\r\x1b[2K"+prefix+string(e.buf))
+	if e.cur < len(e.buf) {
+		fmt.Fprint(w, "\r"+prefix+string(e.buf[:e.cur]))
+	}
+}
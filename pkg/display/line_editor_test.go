@@ -76,3 +76,21 @@ func TestEditor_CtrlA_CtrlE(t *testing.T) {
 		t.Fatalf("expected 'XabcY', got %q", line)
 	}
 }
+
+func TestEditor_CRLFIsOneEnter(t *testing.T) {
+	// Unbracketed text pasted into a Windows console ends lines with CRLF
+	r := bufio.NewReader(bytes.NewReader([]byte("10 m\r\n20 m\r\n")))
+	var out bytes.Buffer
+	for _, want := range []string{"10 m", "20 m"} {
+		line, aborted, eof := NewEditor("> ", nil).ReadLine(r, &out)
+		if aborted || eof {
+			t.Fatalf("unexpected aborted=%v eof=%v", aborted, eof)
+		}
+		if line != want {
+			t.Fatalf("expected %q, got %q", want, line)
+		}
+	}
+	if _, _, eof := NewEditor("> ", nil).ReadLine(r, &out); !eof {
+		t.Fatal("expected EOF after the last CRLF, not an empty line")
+	}
+}
@@ -0,0 +1,95 @@
+package display
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Bracketed paste: once enabled, the terminal wraps pasted text in
+// pasteStart and pasteEnd so it can be told apart from typing.
+const (
+	bracketedPasteOn  = "\x1b[?2004h"
+	bracketedPasteOff = "\x1b[?2004l"
+	pasteEnd          = "\x1b[201~"
+)
+
+// readPaste reads pasted text up to the end marker. Text on one line is
+// inserted at the cursor as if typed; text spanning lines is kept, with the
+// line being edited around it, for ReadLine to return whole.
+func (e *Editor) readPaste(r *bufio.Reader) {
+	var b strings.Builder
+	for !strings.HasSuffix(b.String(), pasteEnd) {
+		c, err := r.ReadByte()
+		if err != nil {
+			break
+		}
+		b.WriteByte(c)
+	}
+	text := strings.TrimSuffix(b.String(), pasteEnd)
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	e.clearSuggestions()
+	if strings.Contains(text, "\n") {
+		e.pasted = string(e.buf[:e.cur]) + text + string(e.buf[e.cur:])
+		return
+	}
+	for _, rn := range text {
+		if rn == '\t' {
+			rn = ' '
+		}
+		if rn >= ' ' && rn != 0x7f {
+			e.insertRune(rn)
+		}
+	}
+}
+
+// startPaste begins :paste mode, in which lines are collected rather than
+// evaluated until a lone "." ends it.
+func (r *REPL) startPaste() string {
+	r.pasting = true
+	r.pasted = nil
+	return "Paste mode: enter or paste lines, then a lone . to evaluate them."
+}
+
+// collectPaste takes a line read in :paste mode. The lone "." that ends the
+// mode evaluates everything collected, writing each line and result to w.
+func (r *REPL) collectPaste(line string, w io.Writer) {
+	if strings.TrimSpace(line) != "." {
+		r.pasted = append(r.pasted, line)
+		return
+	}
+	text := strings.Join(r.pasted, "\n")
+	r.pasting = false
+	r.pasted = nil
+	r.evaluatePaste(text, w)
+}
+
+// evaluatePaste evaluates pasted text line by line, as a .calc file would:
+// blank lines and # comments are skipped and commands run. Each line is
+// written to w after its own prompt, followed by its result, so every
+// evaluated line takes the next line number and prev#N still refers to it.
+func (r *REPL) evaluatePaste(text string, w io.Writer) {
+	for _, ln := range strings.Split(text, "\n") {
+		if r.pasting {
+			// A pasted :paste collects the lines after it
+			r.collectPaste(ln, w)
+			continue
+		}
+		input := strings.TrimSpace(ln)
+		if input == "" || strings.HasPrefix(input, "#") {
+			continue
+		}
+		printWithCRLF(w, r.prompt()+input)
+		result := r.EvaluateLine(input)
+		if !result.IsError() || result.Error != "" {
+			printWithCRLF(w, fmt.Sprintf("   = %s\n", r.formatResult(result)))
+		}
+		printWithCRLF(w, r.takeTutorialNote())
+		if r.commands.ShouldQuit() || r.evalContext().Err() != nil {
+			// Quitting or Ctrl-C leaves the rest of the paste unevaluated
+			return
+		}
+	}
+}
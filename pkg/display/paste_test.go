@@ -0,0 +1,81 @@
+package display
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestEditor_BracketedPaste(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"single line is inserted at the cursor", "1 + \x1b[200~2 * 3\x1b[201~\n", "1 + 2 * 3"},
+		{"several lines are returned whole", "\x1b[200~a = 2\r\nb = 3\r\n\x1b[201~", "a = 2\nb = 3\n"},
+		{"line being edited surrounds the paste", "x\x1b[D\x1b[200~1\n2\x1b[201~", "1\n2x"},
+		{"bare carriage returns split lines", "\x1b[200~1\r2\x1b[201~", "1\n2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ed := NewEditor("> ", nil)
+			var out bytes.Buffer
+			line, aborted, eof := ed.ReadLine(bufio.NewReader(strings.NewReader(tt.input)), &out)
+			if aborted || eof {
+				t.Fatalf("unexpected aborted=%v eof=%v", aborted, eof)
+			}
+			if line != tt.want {
+				t.Errorf("got %q, want %q", line, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluatePaste(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+	r.EvaluateLine("1 + 1")
+
+	var out bytes.Buffer
+	r.evaluatePaste("# prices\nprice = 10\n\n:set precision 1\nprice * 3 // tripled\nprev#2 + prev\n", &out)
+
+	want := "2> price = 10\r\n   = 10.00\r\n" +
+		"3> :set precision 1\r\n" +
+		"3> price * 3 // tripled\r\n   = 30.0\r\n" +
+		"4> prev#2 + prev\r\n   = 40.0\r\n"
+	if out.String() != want {
+		t.Errorf("got:\n%q\nwant:\n%q", out.String(), want)
+	}
+	if r.nextID != 5 {
+		t.Errorf("expected one line number per evaluated line, next is %d", r.nextID)
+	}
+}
+
+func TestPasteCommand(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+	if v := r.EvaluateLine(":paste"); !v.IsError() || v.Error != "" {
+		t.Fatalf("expected :paste to act as a command, got %+v", v)
+	}
+	if !r.pasting {
+		t.Fatal("expected :paste to start paste mode")
+	}
+
+	var out bytes.Buffer
+	for _, ln := range []string{"a = 4", "# ignored", "a * 2", "."} {
+		r.collectPaste(ln, &out)
+	}
+	if r.pasting {
+		t.Error("expected a lone . to end paste mode")
+	}
+	if !strings.Contains(out.String(), "2> a * 2\r\n   = 8.00") {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+	if v := r.EvaluateLine("a + 1"); v.IsError() || v.Number != 5 {
+		t.Errorf("expected pasted variables to stay defined, got %+v", v)
+	}
+}
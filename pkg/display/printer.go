@@ -0,0 +1,77 @@
+package display
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+// printer is the one writer the REPL's terminal output goes through. Each
+// write is whole, so output from elsewhere, such as a tip, never lands in
+// the middle of one. It keeps the input line the editor last drew, so a
+// message printed while a line is being typed goes above it and the prompt
+// and what has been typed so far are drawn again below: the input line is
+// never left broken, and its number never shows twice.
+//
+// The zero value writes to os.Stdout as it is at the time of each write.
+type printer struct {
+	mu   sync.Mutex
+	w    io.Writer // Where output goes; nil writes to os.Stdout
+	line string    // The input line as last drawn; "" when none is being typed
+}
+
+// lineDrawer is a writer that remembers the input line drawn through it, so
+// that it can draw it again after printing something else.
+type lineDrawer interface {
+	drawLine(frame string)
+}
+
+// writeFrame draws an editor frame, the escape sequences and text that put
+// the prompt and input line on screen, in a single write.
+func writeFrame(w io.Writer, frame string) {
+	if d, ok := w.(lineDrawer); ok {
+		d.drawLine(frame)
+		return
+	}
+	io.WriteString(w, frame)
+}
+
+func (p *printer) dest() io.Writer {
+	if p.w == nil {
+		return os.Stdout
+	}
+	return p.w
+}
+
+// Write writes b, which ends any input line on screen: what follows is
+// drawn below it.
+func (p *printer) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.line = ""
+	return p.dest().Write(b)
+}
+
+// drawLine draws the input line and remembers it.
+func (p *printer) drawLine(frame string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.line = frame
+	io.WriteString(p.dest(), frame)
+}
+
+// notice prints msg on lines of its own. When a line is being typed, msg
+// takes its place and the line is drawn again below, as it was.
+func (p *printer) notice(msg string) {
+	if msg == "" {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	w := p.dest()
+	if p.line != "" {
+		io.WriteString(w, "\r\x1b[2K")
+	}
+	printWithCRLF(w, msg)
+	io.WriteString(w, p.line)
+}
@@ -0,0 +1,207 @@
+package display
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/andrewneudegg/calc/pkg/settings"
+)
+
+// fakeTerminal keeps the screen that the bytes written to it would draw,
+// following carriage returns, newlines and the escape sequences the editor
+// uses. Colours and other sequences are ignored.
+type fakeTerminal struct {
+	mu       sync.Mutex
+	rows     [][]rune
+	row, col int
+	esc      []byte // An escape sequence read so far
+}
+
+func (t *fakeTerminal) Write(b []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, r := range string(b) {
+		if len(t.esc) > 0 {
+			t.esc = append(t.esc, byte(r))
+			if len(t.esc) > 2 && r >= 0x40 && r <= 0x7e {
+				t.escape(string(t.esc[2:len(t.esc)-1]), r)
+				t.esc = nil
+			}
+			continue
+		}
+		switch r {
+		case 0x1b:
+			t.esc = []byte{0x1b}
+		case '\r':
+			t.col = 0
+		case '\n':
+			t.row++
+		default:
+			t.put(r)
+		}
+	}
+	return len(b), nil
+}
+
+func (t *fakeTerminal) escape(param string, cmd rune) {
+	switch {
+	case cmd == 'K' && param == "2":
+		t.grow()
+		t.rows[t.row] = nil
+	case cmd == 'J' && param == "2":
+		t.rows = nil
+	case cmd == 'H':
+		t.row, t.col = 0, 0
+	}
+}
+
+func (t *fakeTerminal) grow() {
+	for len(t.rows) <= t.row {
+		t.rows = append(t.rows, nil)
+	}
+}
+
+func (t *fakeTerminal) put(r rune) {
+	t.grow()
+	line := t.rows[t.row]
+	for len(line) <= t.col {
+		line = append(line, ' ')
+	}
+	line[t.col] = r
+	t.rows[t.row] = line
+	t.col++
+}
+
+// screen returns the screen's lines, and the cursor as "row:col".
+func (t *fakeTerminal) screen() ([]string, string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	lines := make([]string, len(t.rows))
+	for i, row := range t.rows {
+		lines[i] = strings.TrimRight(string(row), " ")
+	}
+	return lines, fmt.Sprintf("%d:%d", t.row, t.col)
+}
+
+func TestPrinterNoticeRepaintsLine(t *testing.T) {
+	term := &fakeTerminal{}
+	p := &printer{w: term}
+
+	fmt.Fprint(p, "   = 3.00\r\n\r\n")
+	ed := NewEditor("[2]> ", nil)
+	ed.buf, ed.cur = []rune("12 + 3"), 4
+	ed.render(p)
+
+	p.notice("Tip: Ctrl-C cancels the current line.")
+	lines, cursor := term.screen()
+	want := []string{"   = 3.00", "", "Tip: Ctrl-C cancels the current line.", "[2]> 12 + 3"}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("screen:\n%s\nwant:\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+	// The cursor is back where it was in the line, before the 3
+	if cursor != "3:9" {
+		t.Errorf("cursor at %s, want 3:9", cursor)
+	}
+
+	// Notices of several lines keep each on a line of its own
+	p.notice("rates updated\nfrom the ECB")
+	lines, _ = term.screen()
+	want = append(want[:3], "rates updated", "from the ECB", "[2]> 12 + 3")
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("screen:\n%s\nwant:\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestPrinterNoticeBetweenLines(t *testing.T) {
+	term := &fakeTerminal{}
+	p := &printer{w: term}
+
+	ed := NewEditor("[1]> ", nil)
+	ed.buf, ed.cur = []rune("5 km"), 4
+	ed.render(p)
+	// Submitting the line ends it; a notice then has nothing to repaint
+	fmt.Fprint(p, "\r\n   = 5.00 km\r\n")
+	p.notice("watching budget.calc")
+
+	lines, cursor := term.screen()
+	want := []string{"[1]> 5 km", "   = 5.00 km", "watching budget.calc"}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("screen:\n%s\nwant:\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+	if cursor != "3:0" {
+		t.Errorf("cursor at %s, want 3:0", cursor)
+	}
+}
+
+func TestPrinterNoticeWhileTyping(t *testing.T) {
+	term := &fakeTerminal{}
+	p := &printer{w: term}
+	keys, typing := io.Pipe()
+	ed := NewEditor("[1]> ", nil)
+
+	done := make(chan string)
+	go func() {
+		line, _, _ := ed.ReadLine(bufio.NewReader(keys), p)
+		done <- line
+	}()
+
+	io.WriteString(typing, "12 ")
+	// Notices come from other goroutines while keys are still arriving
+	var wg sync.WaitGroup
+	for i := 1; i <= 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.notice(fmt.Sprintf("notice %d", i))
+		}()
+	}
+	wg.Wait()
+	io.WriteString(typing, "+ 3\r")
+
+	if line := <-done; line != "12 + 3" {
+		t.Fatalf("ReadLine = %q, want %q", line, "12 + 3")
+	}
+	lines, _ := term.screen()
+	if len(lines) != 4 || lines[3] != "[1]> 12 + 3" {
+		t.Fatalf("screen:\n%s\nwant three notices, then the line typed", strings.Join(lines, "\n"))
+	}
+	for i := 1; i <= 3; i++ {
+		if n := strings.Count(strings.Join(lines[:3], "\n"), fmt.Sprintf("notice %d", i)); n != 1 {
+			t.Errorf("notice %d shown %d times:\n%s", i, n, strings.Join(lines, "\n"))
+		}
+	}
+}
+
+func TestTipsShownOnce(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+	term := &fakeTerminal{}
+	r.out.w = term
+
+	r.tip("ctrl-c", ctrlCTip())
+	r.tip("ctrl-c", ctrlCTip())
+	lines, _ := term.screen()
+	if len(lines) != 1 || lines[0] != ctrlCTip() {
+		t.Errorf("expected the tip once, got %q", lines)
+	}
+
+	r.EvaluateLine(":set tips off")
+	term = &fakeTerminal{}
+	r.out.w = term
+	r.tip("other", "Tip: another")
+	if lines, _ := term.screen(); len(strings.Join(lines, "")) != 0 {
+		t.Errorf("expected no tip with tips off, got %q", lines)
+	}
+
+	r.EvaluateLine(":set tips on")
+	r.SetOutputLevel(settings.OutputQuiet)
+	r.tip("other", "Tip: another")
+	if lines, _ := term.screen(); len(strings.Join(lines, "")) != 0 {
+		t.Errorf("expected no tip when quiet, got %q", lines)
+	}
+}
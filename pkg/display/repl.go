@@ -2,18 +2,31 @@ package display
 
 import (
 	"bufio"
+	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"os"
 	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/andrewneudegg/calc/pkg/alias"
 	"github.com/andrewneudegg/calc/pkg/commands"
+	"github.com/andrewneudegg/calc/pkg/config"
+	"github.com/andrewneudegg/calc/pkg/currency"
 	"github.com/andrewneudegg/calc/pkg/evaluator"
 	"github.com/andrewneudegg/calc/pkg/formatter"
 	"github.com/andrewneudegg/calc/pkg/graph"
 	"github.com/andrewneudegg/calc/pkg/lexer"
 	"github.com/andrewneudegg/calc/pkg/parser"
+	"github.com/andrewneudegg/calc/pkg/rounding"
 	"github.com/andrewneudegg/calc/pkg/settings"
+	"github.com/andrewneudegg/calc/pkg/timezone"
+	"github.com/andrewneudegg/calc/pkg/tutorial"
+	"github.com/andrewneudegg/calc/pkg/units"
 )
 
 // Line represents a single calculation line.
@@ -22,6 +35,7 @@ type Line struct {
 	Input  string
 	Result evaluator.Value
 	Expr   parser.Expr
+	Tags   []string // Tags the line is filed under, as in #food, without the '#'
 }
 
 // REPL manages the read-eval-print loop.
@@ -33,38 +47,79 @@ type REPL struct {
 	formatter    *formatter.Formatter
 	commands     *commands.Handler
 	settings     *settings.Settings
+	unitsPath    string                // File that custom units are saved to and loaded from
+	startUnits   units.Pack            // Custom units loaded at start, which each new workspace begins with
+	placesPath   string                // File that locations added with :tz add are saved to and loaded from
+	ratesPath    string                // File that past rates added with :rates import are saved to and loaded from
+	history      *currency.RateHistory // Past rates for conversions with on <date>
 	depGraph     *graph.Graph
 	theme        *Theme
 	silent       bool
-	quiet        bool
+	output       settings.OutputLevel // Session level from -q, -v or :quiet; unset follows the output setting
+	explain      bool                 // Print a trace of each line's evaluation before its result
 	autocomplete *AutocompleteEngine
+	timings      *Timings                          // Optional per-line stage timings; nil when disabled
+	clock        evaluator.Clock                   // Clock for dates; nil uses the system time
+	evalHook     func(parser.Expr) evaluator.Value // Evaluates parsed lines; replaceable in tests
+	pasting      bool                              // In :paste mode, collecting lines until a lone "."
+	pasted       []string                          // Lines collected in :paste mode
+	budget       *budget                           // Spending tracked for remaining, left and spent; nil when none is set
+	readBudget   bool                              // The line being evaluated read the budget
+	sourceLine   int                               // Script line number for the next line's provenance; 0 uses the line ID
+	warnings     []string                          // Undefined variables read as 0, by line, for :warnings
+	shadowWarned map[string]bool                   // Lower-cased variable names already warned of sharing a unit's or keyword's name
+	ctx          context.Context                   // Stops evaluations when done; nil never stops them
+	elapsed      time.Duration                     // How long the last line took, measured at the verbose output level or with :set timing on
+	tutorial     *tutorial.Runner                  // Lessons for :tutorial; nil until it is first used
+	tutorialNote string                            // Reply to the last answer in a lesson, printed after its result
+	workspace    string                            // Name of the active workspace; "" is the default, main
+	workspaces   map[string]*workspace             // Workspaces other than the active one, by name
+	out          printer                           // Everything the session prints to the terminal goes through this
+	tipsShown    map[string]bool                   // Tips already shown this session, by name
 }
 
 // NewREPL creates a new REPL instance.
 func NewREPL() *REPL {
 	// Load settings
-	homeDir, _ := os.UserHomeDir()
-	configPath := fmt.Sprintf("%s/.config/calc/settings.json", homeDir)
+	if err := config.Migrate(os.Stderr); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not migrate settings: %s\n", err)
+	}
+	configPath, _ := config.Path(config.Settings)
+	unitsPath, _ := config.Path(config.CustomUnits)
+	placesPath, _ := config.Path(config.Locations)
+	ratesPath, _ := config.Path(config.RateCache)
 
 	sett, err := settings.Load(configPath)
 	if err != nil {
 		sett = settings.Default()
 		sett.ConfigPath = configPath
 	}
+	for _, w := range sett.LoadWarnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+	}
 
 	env := evaluator.NewEnvironment()
 
 	r := &REPL{
-		lines:     make(map[int]*Line),
-		nextID:    1,
-		env:       env,
-		eval:      evaluator.New(env),
-		formatter: formatter.New(sett),
-		commands:  commands.New(sett),
-		settings:  sett,
-		depGraph:  graph.NewGraph(),
-		theme:     DefaultTheme(),
+		lines:      make(map[int]*Line),
+		nextID:     1,
+		env:        env,
+		eval:       evaluator.New(env),
+		formatter:  formatter.New(sett),
+		commands:   commands.New(sett),
+		settings:   sett,
+		unitsPath:  unitsPath,
+		placesPath: placesPath,
+		ratesPath:  ratesPath,
+		depGraph:   graph.NewGraph(),
+		theme:      DefaultTheme(),
+	}
+	warnings := append(r.loadCustomUnits(), r.loadCustomLocations()...)
+	r.startUnits = env.Units().ExportPack()
+	for _, w := range append(warnings, r.loadRateHistory()...) {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", w)
 	}
+	env.Currency().SetHistory(r.history)
 	
 	// Initialize autocomplete engine
 	r.autocomplete = NewAutocompleteEngine(env, env.Units(), env.Currency(), sett)
@@ -72,55 +127,101 @@ func NewREPL() *REPL {
 	// Set up history function for prev support
 	env.SetHistoryFunc(r.getHistoryValue)
 	env.SetAbsoluteHistoryFunc(r.getAbsoluteHistoryValue)
+	env.SetNameFunc(r.nameValue)
+	env.SetFormatFunc(r.formatter.FormatPlaces)
+	env.SetTagFunc(r.taggedValues)
 	
 	// Wire workspace handlers for :save and :open
 	r.commands.SaveWorkspace = r.saveWorkspace
 	r.commands.LoadWorkspace = r.loadWorkspace
 	// Wire clear handler for :clear
 	r.commands.ClearWorkspace = r.clearWorkspace
-	// Wire quiet controls
-	r.commands.SetQuiet = r.SetQuiet
-	r.commands.ToggleQuiet = r.ToggleQuiet
-	r.commands.GetQuiet = r.IsQuiet
+	r.commands.Units = func() *units.System { return r.env.Units() }
+	r.commands.SaveUnits = func() error { return r.env.Units().SaveCustomUnits(r.unitsPath) }
+	r.commands.Currency = func() *currency.System { return r.env.Currency() }
+	r.commands.RateHistory = func() *currency.RateHistory { return r.history }
+	r.commands.SaveRateHistory = r.saveRateHistory
+	r.commands.Timezones = func() *timezone.System { return r.env.Timezones() }
+	r.commands.SaveLocations = func() error { return r.env.Timezones().SaveCustomLocations(r.placesPath) }
+	// Wire output level controls
+	r.commands.Output = r.OutputLevel
+	r.commands.SetOutput = r.SetOutputLevel
+	r.commands.Explain = r.explainCommand
+	r.commands.Warnings = r.Warnings
+	r.commands.Tags = r.Tags
+	r.commands.Vars = r.Vars
+	r.commands.Paste = r.startPaste
+	r.commands.Budget = r.budgetCommand
+	r.commands.Tutorial = r.tutorialCommand
+	r.commands.Workspace = r.workspaceCommand
+	r.commands.Chart = r.chartCommand
 	return r
 }
 
 // Run starts the REPL loop.
 func (r *REPL) Run() {
-	fmt.Println("Calc - A terminal notepad calculator")
-	fmt.Println("Type :help for available commands, :quit to exit")
-	fmt.Println()
+	if r.OutputLevel() >= settings.OutputNormal {
+		fmt.Fprintln(&r.out, "Calc - A terminal notepad calculator")
+		fmt.Fprintln(&r.out, "Type :help for available commands, :quit to exit")
+		fmt.Fprintln(&r.out)
+	}
 
 	// Try to use interactive line editor with control key support.
 	// If it fails (e.g., not a TTY), fall back to simple Scanner.
-	if isATTY(os.Stdin.Fd()) && isATTY(os.Stdout.Fd()) {
+	// The line editor draws with escape sequences, so a console that cannot
+	// show them, such as conhost before Windows 10, gets plain lines.
+	if isATTY(os.Stdin.Fd()) && colourStdout() {
 		r.runInteractive()
 		return
 	}
+	r.runBasic()
+}
 
-	// Fallback: basic line-by-line input
-	scanner := bufio.NewScanner(os.Stdin)
+// runBasic reads plain lines without key handling, for pipes and terminals
+// that cannot be put into raw mode.
+func (r *REPL) runBasic() {
+	// A Reader rather than a Scanner, so an overlong line reaches the line
+	// length check instead of ending the session
+	reader := bufio.NewReader(os.Stdin)
 	for {
-		fmt.Printf("%d> ", r.nextID)
-		if !scanner.Scan() {
+		if r.pasting {
+			fmt.Fprint(&r.out, "... ")
+		} else {
+			fmt.Fprint(&r.out, r.prompt())
+		}
+		line, err := reader.ReadString('\n')
+		if err != nil && line == "" {
+			if err != io.EOF {
+				fmt.Fprintf(os.Stderr, "Error reading input: %s\n", err)
+			}
 			break
 		}
-		input := strings.TrimSpace(scanner.Text())
+		if r.pasting {
+			r.interruptibly(nil, func() {
+				r.collectPaste(strings.TrimRight(line, "\r\n"), &r.out)
+			})
+			if r.commands.ShouldQuit() {
+				break
+			}
+			continue
+		}
+		input := strings.TrimSpace(line)
 		if input == "" {
 			continue
 		}
-		result := r.EvaluateLine(input)
+		var result evaluator.Value
+		r.interruptibly(nil, func() { result = r.EvaluateLine(input) })
 		if !result.IsError() || result.Error != "" {
-			fmt.Printf("   = %s\n\n", r.formatter.Format(result))
+			fmt.Fprintf(&r.out, "   = %s\n\n", r.formatResult(result))
+		}
+		if note := r.takeTutorialNote(); note != "" {
+			fmt.Fprintf(&r.out, "%s\n\n", note)
 		}
 		// Check if quit command was executed
 		if r.commands.ShouldQuit() {
 			break
 		}
 	}
-	if err := scanner.Err(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error reading input: %s\n", err)
-	}
 }
 
 // runInteractive runs the REPL with a minimal line editor that supports control characters.
@@ -130,41 +231,75 @@ func (r *REPL) runInteractive() {
 	state, err := enableRawMode(int(os.Stdin.Fd()))
 	if err != nil {
 		// Fall back if raw mode cannot be enabled
-		r.Run()
+		r.runBasic()
 		return
 	}
 	defer restoreRawMode(int(os.Stdin.Fd()), state)
+	// Ask the terminal to mark pasted text, so a multi-line paste is
+	// evaluated line by line rather than as one line
+	fmt.Fprint(&r.out, bracketedPasteOn)
+	defer fmt.Fprint(&r.out, bracketedPasteOff)
 
 	for {
-		rawPrompt := fmt.Sprintf("%d> ", r.nextID)
+		rawPrompt := r.prompt()
+		if r.pasting {
+			rawPrompt = "... "
+		}
 		prompt := r.theme.wrap(rawPrompt, r.theme.Prompt) + r.theme.Reset
 		ed := NewEditor(prompt, r.collectHistory())
 		// Install syntax highlighter for the buffer
 		hl := NewHighlighter(r.theme)
+		hl.isUnit = func(s string) bool { return r.env.IsUnit(s) }
 		ed.SetHighlighter(hl.Colorize)
 		// Install autocomplete function if enabled
 		if r.settings.Autocomplete {
 			ed.SetAutocompleteFn(r.autocomplete.GetSuggestions)
 		}
-		line, aborted, eof := ed.ReadLine(reader, os.Stdout)
+		line, aborted, eof := ed.ReadLine(reader, &r.out)
 		if eof {
-			fmt.Fprintln(os.Stdout)
+			fmt.Fprintln(&r.out)
 			break
 		}
+		// Ctrl-C leaves the prompt where it was, emptied, and the next one
+		// is drawn over it, so that its line number is not shown twice
+		if aborted && r.pasting {
+			// Ctrl-C abandons :paste mode and the lines collected so far
+			r.pasting, r.pasted = false, nil
+			r.out.notice("paste cancelled")
+			continue
+		}
 		if aborted {
-			// Show a helpful tip when Ctrl-C is pressed in raw mode
-			printWithCRLF(os.Stdout, ctrlCTip())
+			r.tip("ctrl-c", ctrlCTip())
+			continue
+		}
+		if r.pasting || strings.Contains(line, "\n") {
+			// A bracketed paste of several lines, or a line typed in :paste mode
+			r.interruptibly(state, func() {
+				if r.pasting {
+					for _, ln := range strings.Split(line, "\n") {
+						r.collectPaste(ln, &r.out)
+					}
+				} else {
+					r.evaluatePaste(line, &r.out)
+				}
+			})
+			if r.commands.ShouldQuit() {
+				break
+			}
 			continue
 		}
 		input := strings.TrimSpace(line)
 		if input == "" {
-			fmt.Fprintln(os.Stdout)
+			fmt.Fprintln(&r.out)
 			continue
 		}
-		result := r.EvaluateLine(input)
+		// Ctrl-C while the line is evaluated cancels it, keeping the session
+		var result evaluator.Value
+		r.interruptibly(state, func() { result = r.EvaluateLine(input) })
 		if !result.IsError() || result.Error != "" {
-			fmt.Fprintf(os.Stdout, "   = %s\n\n", r.formatter.Format(result))
+			printWithCRLF(&r.out, fmt.Sprintf("   = %s\n", r.formatResult(result)))
 		}
+		printWithCRLF(&r.out, r.takeTutorialNote())
 		// Check if quit command was executed
 		if r.commands.ShouldQuit() {
 			break
@@ -184,78 +319,259 @@ func (r *REPL) collectHistory() []string {
 	return h
 }
 
-// EvaluateLine processes a single line of input.
-func (r *REPL) EvaluateLine(input string) evaluator.Value {
-	// Tokenise
-	lex := lexer.New(input)
+// lex tokenises input with the session's constants and custom units.
+func (r *REPL) lex(input string) []lexer.Token {
+	// Units come from the live unit system, so custom units lex as units
+	r.env.Units().SetInformal(r.settings.InformalUnits)
+	lex := lexer.NewWithUnits(input, r.env.IsUnit)
 	// Hook up constants checker
 	lex.SetConstantChecker(r.env.Constants().IsConstant)
-	tokens := lex.AllTokens()
+	return lex.AllTokens()
+}
+
+// parse builds the expression for tokens with the session's parser options.
+func (r *REPL) parse(tokens []lexer.Token) (parser.Expr, error) {
+	p := parser.NewWithLocale(tokens, r.settings.Locale)
+	p.SetImplicitMultiplication(r.settings.ImplicitMul)
+	p.SetVariableChecker(r.env.HasVariable)
+	p.SetIngredientChecker(r.env.Units().IsIngredient)
+	p.SetLocationChecker(r.env.Timezones().IsLocation)
+	p.SetMaxDepth(r.settings.MaxDepth)
+	p.SetInformal(r.settings.InformalUnits)
+	p.SetFew(float64(r.settings.Few))
+	return p.Parse()
+}
+
+// commandTail returns the text after a command's name, ":alias list" giving "list".
+func commandTail(input string) string {
+	s := strings.TrimPrefix(strings.TrimSpace(input), ":")
+	s = strings.TrimSpace(s)
+	if i := strings.IndexFunc(s, unicode.IsSpace); i >= 0 {
+		return strings.TrimSpace(s[i:])
+	}
+	return ""
+}
+
+// EvaluateLine processes a single line of input. Its result, including a
+// parse error, carries the line's provenance. While a :tutorial lesson runs,
+// the line is also checked as an answer.
+func (r *REPL) EvaluateLine(input string) evaluator.Value {
+	next := r.nextID
+	result := r.evaluateLine(input)
+	r.checkTutorial(input, next, result)
+	return result
+}
+
+func (r *REPL) evaluateLine(input string) evaluator.Value {
+	sourceLine := r.sourceLine
+	r.sourceLine = 0
+	origin := func(line int, assign bool) evaluator.Provenance {
+		if sourceLine != 0 {
+			line = sourceLine
+		}
+		return evaluator.Provenance{Input: input, Line: line, Assign: assign}
+	}
+	// Stage timing is only collected when enabled, keeping the default path free of clock reads
+	var start, lexed, parsed time.Time
+	var lexedTokens int
+	if r.timings != nil {
+		start = r.timings.clock()
+		defer func() {
+			r.timings.record(input, lexedTokens, start, lexed, parsed, r.timings.clock())
+		}()
+	}
+	r.elapsed = 0
+	if r.OutputLevel() == settings.OutputVerbose || r.settings.Timing {
+		began := time.Now()
+		defer func() { r.elapsed = time.Since(began) }()
+	}
+
+	// Refuse lines too long to be worth tokenising. Commands are exempt so a
+	// low limit can always be raised again with :set.
+	isCommand := strings.HasPrefix(strings.TrimSpace(input), ":")
+	if n := utf8.RuneCountInString(input); n > r.settings.MaxLineLength && !isCommand {
+		return evaluator.NewError(fmt.Sprintf("line too long: %d characters (limit %d)", n, r.settings.MaxLineLength)).WithProvenance(origin(r.nextID, false))
+	}
+
+	// Tokenise
+	tokens := r.lex(input)
+	if r.timings != nil {
+		lexed = r.timings.clock()
+	}
 
 	// Remove EOF token for parsing
 	if len(tokens) > 0 && tokens[len(tokens)-1].Type == lexer.TokenEOF {
 		tokens = tokens[:len(tokens)-1]
 	}
+	lexedTokens = len(tokens)
 
 	// If the line reduces to nothing (e.g., comment-only or whitespace), treat as no-op
 	if len(tokens) == 0 {
 		return evaluator.NewError("")
 	}
 
+	// Expand aliases, leaving commands as typed so :alias can name them
+	var tags []string
+	if tokens[0].Type != lexer.TokenColon {
+		if tokens, tags = lexer.SplitTags(tokens); len(tokens) == 0 {
+			return evaluator.NewError("")
+		}
+		expanded, err := alias.Expand(tokens, r.settings.Aliases, r.lex)
+		if err != nil {
+			return evaluator.NewError(err.Error()).WithProvenance(origin(r.nextID, false))
+		}
+		tokens = expanded
+		tokens = r.continueLast(tokens)
+	}
+
+	// Very long expressions would nest too deeply to evaluate safely
+	if len(tokens) > r.settings.MaxTokens && !isCommand {
+		return evaluator.NewError(fmt.Sprintf("too many tokens: %d (limit %d)", len(tokens), r.settings.MaxTokens)).WithProvenance(origin(r.nextID, false))
+	}
+
 	// Parse
-	p := parser.NewWithLocale(tokens, r.settings.Locale)
-	expr, err := p.Parse()
+	expr, err := r.parse(tokens)
+	if r.timings != nil {
+		parsed = r.timings.clock()
+	}
 	if err != nil {
-		return evaluator.NewError(err.Error())
+		return evaluator.NewError(err.Error()).WithProvenance(origin(r.nextID, false))
 	}
 
 	// Check if it's a command
 	if cmd, ok := expr.(*parser.CommandExpr); ok {
-		msg := r.commands.Execute(cmd.Command, cmd.Args)
-		if !r.silent {
-			printWithCRLF(os.Stdout, msg)
+		var msg string
+		switch {
+		case strings.EqualFold(cmd.Command, "alias"):
+			// Alias bodies need the text as typed, e.g. 20% rather than "20 %"
+			msg = r.commands.Alias(commandTail(input))
+		case strings.EqualFold(cmd.Command, "explain"):
+			msg = r.explainCommand(commandTail(input))
+		case strings.EqualFold(cmd.Command, "budget"):
+			// The amount needs the text as typed, e.g. £500 rather than "£ 500"
+			msg = r.budgetCommand(commandTail(input))
+		case strings.EqualFold(cmd.Command, "chart"):
+			// Tags need the text as typed, as #food would otherwise be lexed away
+			msg = r.chartCommand(commandTail(input))
+		default:
+			msg = r.commands.Execute(cmd.Command, cmd.Args)
+		}
+		// A script has no one watching for a command's message, so one that
+		// failed becomes an error the script's runner reports
+		if r.silent && commands.Failed(msg) {
+			return evaluator.NewError(strings.TrimPrefix(msg, "error: ")).WithProvenance(origin(r.nextID, false))
+		}
+		// A chart is what a script asked to see, so it prints even when silent
+		shown := !r.silent || strings.EqualFold(cmd.Command, "chart")
+		if shown && r.OutputLevel() >= settings.OutputQuiet {
+			printWithCRLF(&r.out, msg)
 		}
 		// Return a sentinel error value with empty message so caller skips printing a result line.
 		return evaluator.NewError("")
 	}
 
 	// Evaluate
-	result := r.eval.Eval(expr)
+	r.env.SetRounding(rounding.Mode(r.settings.Rounding))
+	r.env.SetDecimal(r.settings.Decimal)
+	r.env.SetStrict(r.settings.Strict)
+	r.env.SetMonthOverflow(r.settings.MonthArithmetic == "overflow")
+	r.readBudget = false
+	var result evaluator.Value
+	switch {
+	case r.evalHook != nil:
+		result = r.evalHook(expr)
+	case r.explain:
+		var steps []evaluator.Step
+		result, steps = r.evalTraced(expr)
+		if !r.silent {
+			printWithCRLF(&r.out, r.explainReport(tokens, expr, steps))
+		}
+	default:
+		result = r.eval.EvalContext(r.evalContext(), expr)
+	}
 
-	// Store the line
+	// Store the line, recording where its result came from
 	lineID := r.nextID
 	r.nextID++
+	assigned := parser.AssignedNames(expr)
+	isAssign := len(assigned) > 0
+	result = result.WithProvenance(origin(lineID, isAssign))
+	if names := r.env.TakeUndefined(); len(names) > 0 {
+		result = r.warnUndefined(result, names, origin(lineID, isAssign).Line)
+	}
+	for _, name := range assigned {
+		result = r.warnShadow(result, name, origin(lineID, isAssign).Line)
+	}
 
 	r.lines[lineID] = &Line{
 		ID:     lineID,
 		Input:  input,
 		Result: result,
 		Expr:   expr,
+		Tags:   tags,
 	}
+	r.trackBudget(lineID, expr, result)
 
-	// Quiet mode: suppress printing for assignment lines
-	if r.quiet {
-		if _, isAssign := expr.(*parser.AssignExpr); isAssign {
-			return evaluator.NewError("")
-		}
+	// Below the normal output level, assignments print nothing
+	if r.OutputLevel() < settings.OutputNormal && isAssign {
+		return evaluator.NewError("")
 	}
 
 	return result
 }
 
-// clearWorkspace resets the current REPL session: history, variables, and evaluation state.
+// warnUndefined notes on result, and in the list :warnings shows, that the
+// named variables were read as 0 because strict mode is off.
+func (r *REPL) warnUndefined(result evaluator.Value, names []string, line int) evaluator.Value {
+	msg := evaluator.UndefinedWarning(names)
+	r.warnings = append(r.warnings, fmt.Sprintf("line %d: %s", line, msg))
+	if result.IsError() {
+		return result
+	}
+	if result.Warning != "" {
+		msg = result.Warning + "; " + msg
+	}
+	result.Warning = msg
+	return result
+}
+
+// Warnings returns the warnings given so far this session, oldest first,
+// such as "line 3: undefined variable rent treated as 0".
+func (r *REPL) Warnings() []string {
+	return r.warnings
+}
+
+// clearWorkspace resets the current REPL session: history, variables, and
+// evaluation state. The workspace's custom units are kept.
 func (r *REPL) clearWorkspace() error {
+	return r.resetWorkspace(r.env.Units().ExportPack())
+}
+
+// resetWorkspace resets the session as clearWorkspace does, starting over
+// with the custom units of pack rather than rereading the file, which may
+// hold units defined in other workspaces since.
+func (r *REPL) resetWorkspace(pack units.Pack) error {
 	// Reset stored lines and prompt counter
 	r.lines = make(map[int]*Line)
 	r.nextID = 1
+	r.warnings = nil
+	r.shadowWarned = nil
+	r.budget = nil
 
 	// Reset evaluation environment and evaluator (clears variables and systems)
 	r.env = evaluator.NewEnvironment()
 	r.eval = evaluator.New(r.env)
 	
-	// Re-wire history function
+	// Re-wire history function and clock
 	r.env.SetHistoryFunc(r.getHistoryValue)
 	r.env.SetAbsoluteHistoryFunc(r.getAbsoluteHistoryValue)
+	r.env.SetNameFunc(r.nameValue)
+	r.env.SetFormatFunc(r.formatter.FormatPlaces)
+	r.env.SetTagFunc(r.taggedValues)
+	r.env.SetClock(r.clock)
+	r.env.Currency().SetHistory(r.history)
+	r.env.Units().StartFrom(pack)
+	r.loadCustomLocations()
 
 	// Reset dependency graph
 	r.depGraph = graph.NewGraph()
@@ -266,15 +582,56 @@ func (r *REPL) clearWorkspace() error {
 	return nil
 }
 
-// saveWorkspace writes the current REPL inputs to a file.
-func (r *REPL) saveWorkspace(filename string) error {
-	f, err := os.Create(filename)
+// loadCustomUnits adds the custom units saved by earlier sessions to the
+// environment, returning warnings for any that were skipped.
+func (r *REPL) loadCustomUnits() []string {
+	if r.unitsPath == "" {
+		return nil
+	}
+	return r.env.Units().LoadCustomUnits(r.unitsPath)
+}
+
+// loadRateHistory reads the past rates added with :rates import by earlier
+// sessions. The history is empty, but usable, if they cannot be read, and a
+// damaged file is set aside; the returned warnings say so.
+func (r *REPL) loadRateHistory() []string {
+	r.history = currency.NewRateHistory()
+	if r.ratesPath == "" {
+		return nil
+	}
+	h, err := currency.LoadRateHistory(r.ratesPath)
 	if err != nil {
-		return err
+		return []string{config.SetAsideWarning("past rates", r.ratesPath, err)}
+	}
+	r.history = h
+	return nil
+}
+
+// saveRateHistory writes the past rates for the next session.
+func (r *REPL) saveRateHistory() error {
+	if r.ratesPath == "" {
+		return nil
 	}
-	defer f.Close()
+	return r.history.Save(r.ratesPath)
+}
+
+// loadCustomLocations adds the locations saved with :tz add by earlier
+// sessions, returning warnings for any that were skipped.
+func (r *REPL) loadCustomLocations() []string {
+	if r.placesPath == "" {
+		return nil
+	}
+	return r.env.Timezones().LoadCustomLocations(r.placesPath)
+}
+
+// saveWorkspace writes the current REPL inputs to a file, replacing it whole.
+func (r *REPL) saveWorkspace(filename string) error {
+	var buf bytes.Buffer
 	// Optional header
-	fmt.Fprintln(f, "# calc workspace")
+	fmt.Fprintln(&buf, "# calc workspace")
+	for _, d := range r.workspaceDirectives() {
+		fmt.Fprintln(&buf, d)
+	}
 	for _, line := range r.ListLines() {
 		if strings.TrimSpace(line.Input) == "" {
 			continue
@@ -283,43 +640,70 @@ func (r *REPL) saveWorkspace(filename string) error {
 			// Do not persist command lines
 			continue
 		}
-		fmt.Fprintln(f, line.Input)
+		fmt.Fprintln(&buf, line.Input)
 	}
-	return nil
+	return config.WriteFile(filename, buf.Bytes())
 }
 
-// loadWorkspace loads inputs from a file, replacing current session.
+// loadWorkspace loads inputs from a file, replacing current session. The
+// file's directives apply first, settings then units then variables, so the
+// lines read and evaluate as they did when saved. Lines are evaluated in
+// dependency order, so a variable used before the line that defines it still
+// resolves; they keep their file order in the session. Variables defined in
+// terms of each other leave the session untouched.
 func (r *REPL) loadWorkspace(filename string) error {
 	b, err := os.ReadFile(filename)
 	if err != nil {
 		return err
 	}
+	directives := readDirectives(string(b))
+	previous := *r.settings
+	warnings := r.applySettingDirectives(directives)
+	lines := r.readWorkspace(string(b))
+	order, err := workspaceOrder(lines)
+	if err != nil {
+		*r.settings = previous
+		return err
+	}
+
 	// Reset state
 	r.lines = make(map[int]*Line)
 	r.nextID = 1
+	r.budget = nil
 	r.env = evaluator.NewEnvironment()
 	r.eval = evaluator.New(r.env)
 	
-	// Re-wire history function
+	// Re-wire history function and clock
 	r.env.SetHistoryFunc(r.getHistoryValue)
 	r.env.SetAbsoluteHistoryFunc(r.getAbsoluteHistoryValue)
+	r.env.SetNameFunc(r.nameValue)
+	r.env.SetFormatFunc(r.formatter.FormatPlaces)
+	r.env.SetTagFunc(r.taggedValues)
+	r.env.SetClock(r.clock)
+	r.env.Currency().SetHistory(r.history)
+	r.loadCustomUnits()
+	warnings = append(warnings, r.applyUnitDirectives(directives)...)
+	warnings = append(warnings, r.applyVariableDirectives(directives)...)
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s:%d: %s\n", filename, w.number, w.msg)
+	}
 
 	// Reinitialize autocomplete engine with the new environment
 	r.autocomplete = NewAutocompleteEngine(r.env, r.env.Units(), r.env.Currency(), r.settings)
 
-	lines := strings.Split(string(b), "\n")
-	for _, ln := range lines {
-		t := strings.TrimSpace(ln)
-		if t == "" || strings.HasPrefix(t, "#") {
-			continue
-		}
-		// Skip embedded commands in workspace files
-		if strings.HasPrefix(t, ":") {
-			continue
+	for _, i := range order {
+		// Evaluate silently (no printing here), but surface warnings such as a
+		// file that relied on "Rate" and "rate" being different variables
+		id := i + 1
+		r.nextID = id
+		_ = r.EvaluateLine(lines[i].input)
+		if line, ok := r.lines[id]; ok {
+			for _, w := range line.Result.Warnings() {
+				fmt.Fprintf(os.Stderr, "warning: %s:%d: %s\n", filename, lines[i].number, w)
+			}
 		}
-		// Evaluate silently (no printing here)
-		_ = r.EvaluateLine(t)
 	}
+	r.nextID = len(lines) + 1
 	return nil
 }
 
@@ -344,6 +728,73 @@ func printWithCRLF(w io.Writer, s string) {
 	fmt.Fprint(w, s)
 }
 
+// colourStdout reports whether stdout is a terminal that shows ANSI escape
+// sequences, turning them on the first time it is asked on Windows.
+var colourStdout = sync.OnceValue(func() bool {
+	return isATTY(os.Stdout.Fd()) && enableANSI(os.Stdout.Fd())
+})
+
+// IsTerminal reports whether f is connected to an interactive terminal.
+func IsTerminal(f *os.File) bool {
+	return isATTY(f.Fd())
+}
+
+// formatResult formats a result for display after the "   = " marker. Lists of
+// results are placed one per line, aligned under the first, followed by any warnings
+// and, with :set also on, the result in companion units; with :set show-steps
+// on, the steps of a conversion chain. The verbose output level adds the
+// exchange rates used, companion units and the time taken; :set timing on
+// adds the time taken alone.
+func (r *REPL) formatResult(v evaluator.Value) string {
+	level := r.OutputLevel()
+	verbose := level == settings.OutputVerbose
+	lines := r.formatter.FormatLines(v)
+	if level > settings.OutputSilent {
+		for _, w := range v.Warnings() {
+			lines = append(lines, "warning: "+w)
+		}
+	}
+	var notes []string
+	if verbose {
+		notes = r.formatter.RateNotes(v)
+	}
+	if r.settings.ShowSteps {
+		if steps := r.formatter.Steps(v); steps != "" {
+			notes = append(notes, steps)
+		}
+	}
+	if r.settings.Also || verbose {
+		// Companion conversions are shown only, never stored as the line's value
+		if also := r.formatter.Also(v); also != "" {
+			notes = append(notes, also)
+		}
+	}
+	if (verbose || r.settings.Timing) && r.elapsed > 0 {
+		notes = append(notes, r.formatter.Elapsed(r.elapsed))
+	}
+	for _, note := range notes {
+		if colourStdout() {
+			note = r.theme.wrap(note, r.theme.Hint)
+		}
+		lines = append(lines, note)
+	}
+	return strings.Join(lines, "\n     ")
+}
+
+// tip prints msg above the line being typed, the first time the tip called
+// name comes up in the session. :set tips off and the quiet output level
+// leave tips out.
+func (r *REPL) tip(name, msg string) {
+	if !r.settings.Tips || r.OutputLevel() < settings.OutputNormal || r.tipsShown[name] {
+		return
+	}
+	if r.tipsShown == nil {
+		r.tipsShown = make(map[string]bool)
+	}
+	r.tipsShown[name] = true
+	r.out.notice(msg)
+}
+
 // ctrlCTip returns the message shown when the user presses Ctrl-C in raw mode.
 func ctrlCTip() string {
 	return "Tip: Ctrl-C cancels the current line. Press Ctrl-D to exit, or type :help for commands."
@@ -372,25 +823,62 @@ func (r *REPL) Formatter() *formatter.Formatter {
 	return r.formatter
 }
 
+// Settings returns the live settings, including changes made by :set during the session.
+func (r *REPL) Settings() *settings.Settings {
+	return r.settings
+}
+
 // SetSilent toggles printing of command outputs during EvaluateLine. Useful for batch/script mode.
 func (r *REPL) SetSilent(s bool) {
 	r.silent = s
 }
 
-// SetQuiet enables or disables quiet mode (suppresses assignment output).
-func (r *REPL) SetQuiet(q bool) {
-	r.quiet = q
+// EnableTimings starts collecting per-line lex/parse/eval durations and returns the collector.
+func (r *REPL) EnableTimings() *Timings {
+	if r.timings == nil {
+		r.timings = &Timings{}
+	}
+	return r.timings
+}
+
+// SetClock fixes the time used for now, today and other relative dates, so
+// script output is reproducible. It survives :clear and :open.
+func (r *REPL) SetClock(c evaluator.Clock) {
+	r.clock = c
+	r.env.SetClock(c)
+}
+
+// SetSourceLine numbers the next evaluated line's provenance by its line in a
+// script rather than by its line ID, which skips blanks and commands.
+func (r *REPL) SetSourceLine(n int) {
+	r.sourceLine = n
+}
+
+// SetOutputLevel sets the output level for the session, in place of the
+// output setting, without saving it.
+func (r *REPL) SetOutputLevel(l settings.OutputLevel) {
+	r.output = l
+}
+
+// OutputLevel returns the session's output level: the one set for the
+// session if any, otherwise the output setting.
+func (r *REPL) OutputLevel() settings.OutputLevel {
+	if r.output != 0 {
+		return r.output
+	}
+	return r.settings.OutputLevel()
 }
 
-// ToggleQuiet flips quiet mode and returns the new state.
-func (r *REPL) ToggleQuiet() bool {
-	r.quiet = !r.quiet
-	return r.quiet
+// Elapsed returns how long the last line took to evaluate. It is only
+// measured at the verbose output level, and is zero otherwise.
+func (r *REPL) Elapsed() time.Duration {
+	return r.elapsed
 }
 
-// IsQuiet reports whether quiet mode is enabled.
+// IsQuiet reports whether assignments are printing nothing, as they do at
+// the quiet and silent output levels.
 func (r *REPL) IsQuiet() bool {
-	return r.quiet
+	return r.OutputLevel() < settings.OutputNormal
 }
 
 // Env returns the evaluator environment, allowing access to variables and evaluation.
@@ -0,0 +1,42 @@
+package display
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAliasExpansion(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+	r.EvaluateLine(":alias define vat = 20%")
+	r.EvaluateLine(":alias define net x = decrease x by vat")
+
+	if v := r.EvaluateLine("net(£120)"); v.IsError() || math.Abs(v.Number-96) > 1e-9 {
+		t.Fatalf("net(£120) = %+v, want £96", v)
+	}
+	if v := r.EvaluateLine("100 + vat"); v.IsError() || math.Abs(v.Number-120) > 1e-9 {
+		t.Fatalf("100 + vat = %+v, want 120", v)
+	}
+
+	// Aliases are saved with settings, so a new session sees them
+	r2 := NewREPL()
+	r2.SetSilent(true)
+	if v := r2.EvaluateLine("net(£50)"); v.IsError() || math.Abs(v.Number-40) > 1e-9 {
+		t.Fatalf("net(£50) in a new session = %+v, want £40", v)
+	}
+}
+
+func TestAliasCycleIsAnError(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+	r.EvaluateLine(":alias define foo = 1")
+	r.EvaluateLine(":alias define baz = foo")
+	r.EvaluateLine(":alias define foo = baz")
+
+	// The cyclic redefinition is refused, leaving foo as it was
+	if v := r.EvaluateLine("baz + 1"); v.IsError() || v.Number != 2 {
+		t.Fatalf("baz + 1 = %+v, want 2", v)
+	}
+}
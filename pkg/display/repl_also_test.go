@@ -0,0 +1,33 @@
+package display
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAlsoLine(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+
+	v := r.EvaluateLine("100 km")
+	if got := r.formatResult(v); strings.Contains(got, "also:") {
+		t.Errorf("also off: got %q", got)
+	}
+
+	r.EvaluateLine(":set also on")
+	v = r.EvaluateLine("100 km")
+	got := r.formatResult(v)
+	if !strings.Contains(got, "\n     also: 62.14 mi") {
+		t.Errorf("also on: got %q, want a companion line in miles", got)
+	}
+	// The companions are display only; prev is still the kilometres
+	prev := r.EvaluateLine("prev")
+	if prev.Unit != "km" || prev.Number != 100 {
+		t.Errorf("prev = %v %s, want 100 km", prev.Number, prev.Unit)
+	}
+
+	if got := r.formatResult(r.EvaluateLine("12 * 3")); strings.Contains(got, "also:") {
+		t.Errorf("also on a plain number: got %q", got)
+	}
+}
@@ -0,0 +1,82 @@
+package display
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrewneudegg/calc/pkg/evaluator"
+)
+
+func TestBudgetSession(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+
+	steps := []struct {
+		input string
+		want  string
+	}{
+		{"£20 + £5", "£25.00"},
+		{"budget = £500", "£500.00"},
+		{"groceries = £42.50", "£42.50"},
+		{"£120", "£120.00"},
+		{"3 * 2", "6.00"},
+		{"remaining", "£337.50"},
+		{"left", "£337.50"},
+		{"spent", "£162.50"},
+		{"left / 2", "£168.75"},
+		{"$50", "$50.00"},
+		{"spent", "£201.87"},
+	}
+	for _, s := range steps {
+		v := r.EvaluateLine(s.input)
+		if v.IsError() {
+			t.Fatalf("%s: unexpected error: %s", s.input, v.Error)
+		}
+		if got := strings.TrimSpace(r.formatter.Format(v)); got != s.want {
+			t.Errorf("%s = %s, want %s", s.input, got, s.want)
+		}
+	}
+
+	if msg := r.budgetCommand(""); !strings.Contains(msg, "remaining £298.13") {
+		t.Errorf("unexpected :budget report %q", msg)
+	}
+	r.EvaluateLine(":budget reset")
+	if v := r.EvaluateLine("left"); !v.IsError() {
+		t.Errorf("expected left to be undefined after :budget reset, got %+v", v)
+	}
+}
+
+func TestBudgetCommand(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+
+	r.EvaluateLine("£1000")
+	if msg := r.budgetCommand("£100"); !strings.Contains(msg, "£100.00") {
+		t.Fatalf("unexpected message %q", msg)
+	}
+	r.EvaluateLine("£30")
+	if v := r.EvaluateLine("remaining"); strings.TrimSpace(r.formatter.Format(v)) != "£70.00" {
+		t.Errorf("expected only lines after :budget to count, got %s", r.formatter.Format(v))
+	}
+	if msg := r.budgetCommand("42"); !strings.Contains(msg, "amount of money") {
+		t.Errorf("expected a plain number to be refused, got %q", msg)
+	}
+}
+
+func TestBudgetWordsYieldToVariables(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+
+	r.EvaluateLine("budget = £100")
+	r.EvaluateLine("left = 3")
+	r.EvaluateLine("£10")
+	if v := r.EvaluateLine("left"); v.Type != evaluator.ValueNumber || v.Number != 3 {
+		t.Errorf("expected the variable left to win, got %+v", v)
+	}
+	if v := r.EvaluateLine("remaining"); strings.TrimSpace(r.formatter.Format(v)) != "£90.00" {
+		t.Errorf("remaining = %s, want £90.00", r.formatter.Format(v))
+	}
+}
@@ -0,0 +1,32 @@
+package display
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestCancelledContextStopsEvaluation(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r.SetContext(ctx)
+	if v := r.EvaluateLine("2 + 3"); v.Error != "evaluation cancelled" {
+		t.Errorf("cancelled: got %+v", v)
+	}
+	var out bytes.Buffer
+	r.evaluatePaste("1 + 1\n2 + 2", &out)
+	if strings.Contains(out.String(), "2 + 2") {
+		t.Errorf("paste carried on after cancelling:\n%s", out.String())
+	}
+
+	// The session is still usable
+	r.SetContext(context.Background())
+	if v := r.EvaluateLine("2 + 3"); v.IsError() || v.Number != 5 {
+		t.Errorf("after cancelling: got %+v", v)
+	}
+}
@@ -0,0 +1,81 @@
+package display
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWorkspaceOpenOrdersDestructuring(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	path := t.TempDir() + "/tip.calc"
+
+	// The line using the names comes before the line that assigns them
+	r := NewREPL()
+	r.SetSilent(true)
+	for _, line := range []string{"tip * 2", "tip, bill = 10% tip on meal", "meal = £40"} {
+		_ = r.EvaluateLine(line)
+	}
+	if err := r.saveWorkspace(path); err != nil {
+		t.Fatalf("saveWorkspace: %v", err)
+	}
+
+	r2 := NewREPL()
+	r2.SetSilent(true)
+	if err := r2.loadWorkspace(path); err != nil {
+		t.Fatalf("loadWorkspace: %v", err)
+	}
+	lines := r2.ListLines()
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d", len(lines))
+	}
+	if got := lines[0].Result; got.IsError() || got.Number != 8 {
+		t.Errorf("tip * 2 = %+v, want £8", got)
+	}
+	if v := r2.EvaluateLine("bill"); v.Number != 44 {
+		t.Errorf("bill after open = %+v, want £44", v)
+	}
+}
+
+func TestShadowWarningForEachDestructuredName(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+
+	v := r.EvaluateLine("m, sum = 10% tip on £40")
+	if v.IsError() {
+		t.Fatalf("unexpected error: %s", v.Error)
+	}
+	if !strings.Contains(v.Warning, "'m'") || !strings.Contains(v.Warning, "function sum()") {
+		t.Errorf("warning %q, want one for m and one for sum", v.Warning)
+	}
+}
+
+func TestDestructuredTotalReadsBack(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+
+	// Alone, total is the running total of no values until a variable has the name
+	if got := resultOf(t, r, "total"); got != "0.00" {
+		t.Errorf("total before any assignment = %s", got)
+	}
+	v := r.EvaluateLine("tip, total = 15% tip on £42.50")
+	if v.IsError() || !strings.Contains(v.Warning, "function total()") {
+		t.Fatalf("tip, total = ...: %+v", v)
+	}
+	for input, want := range map[string]string{
+		"total":          "£48.88",
+		"total * 2":      "£97.76",
+		"tip + total":    "£55.26",
+		"total(1, 2, 3)": "6.00",
+	} {
+		if got := resultOf(t, r, input); got != want {
+			t.Errorf("%s = %s, want %s", input, got, want)
+		}
+	}
+
+	// Both kinds of assignment warn alike of a word that keeps its meaning
+	if v := r.EvaluateLine("today, b = £1 in usd, eur"); !strings.Contains(v.Warning, "reading today gives the keyword's meaning") {
+		t.Errorf("today, b = ...: warning %q", v.Warning)
+	}
+}
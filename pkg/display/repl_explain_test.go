@@ -0,0 +1,69 @@
+package display
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExplainOnce(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+	r.settings.ASCII = "off"
+
+	got := r.explainCommand("1 m + 50 cm")
+	want := strings.Join([]string{
+		"tokens: NUMBER(1) UNIT(m) +(+) NUMBER(50) UNIT(cm)",
+		"parsed: (+ (unit 1 m) (unit 50 cm))",
+		"steps:",
+		"  converted 50.00 cm → 0.50 m",
+		"  1.00 m + 50.00 cm → 1.50 m",
+		"result: 1.50 m",
+	}, "\n")
+	if got != want {
+		t.Fatalf("explain report:\n%s\nwant:\n%s", got, want)
+	}
+	if len(r.ListLines()) != 0 {
+		t.Fatal(":explain <expr> should not add a line to the session")
+	}
+}
+
+func TestExplainASCII(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+	r.settings.ASCII = "on"
+
+	got := r.explainCommand("10% of £200")
+	if !strings.Contains(got, "applied 10.00% of GBP 200.00 -> GBP 20.00") {
+		t.Fatalf("ASCII explain report:\n%s", got)
+	}
+}
+
+func TestExplainParseError(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+
+	got := r.explainCommand("1 +")
+	if !strings.HasPrefix(got, "tokens: NUMBER(1) +(+)") || !strings.Contains(got, "\nerror: ") {
+		t.Fatalf("parse error report:\n%s", got)
+	}
+}
+
+func TestExplainMode(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+
+	if msg := r.explainCommand("on"); msg != "explain: on" {
+		t.Fatalf(":explain on = %q", msg)
+	}
+	r.EvaluateLine("x = 4")
+	if v := r.EvaluateLine("x * 2"); v.IsError() || v.Number != 8 {
+		t.Fatalf("x * 2 in explain mode = %+v, want 8", v)
+	}
+	if msg := r.explainCommand(""); msg != "explain: off" {
+		t.Fatalf("bare :explain should toggle off, got %q", msg)
+	}
+}
@@ -0,0 +1,51 @@
+package display
+
+import (
+	"math"
+	"testing"
+)
+
+func TestImplicitMultiplicationSetting(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+
+	r.EvaluateLine("x = 4")
+	if v := r.EvaluateLine("2x"); v.Number != 2 {
+		t.Fatalf("expected 2x to read as 2 while the setting is off, got %+v", v)
+	}
+
+	r.EvaluateLine(":set implicitmul on")
+	tests := []struct {
+		input string
+		want  float64
+	}{
+		{"2x", 8},
+		{"2(3+4)", 14},
+		{"(2+3)(4+5)", 45},
+		{"2pi", 2 * math.Pi},
+		{"2 pi", 2 * math.Pi},
+	}
+	for _, tt := range tests {
+		if v := r.EvaluateLine(tt.input); v.IsError() || math.Abs(v.Number-tt.want) > 1e-12 {
+			t.Errorf("%s: expected %v, got %+v", tt.input, tt.want, v)
+		}
+	}
+}
+
+func TestPiIsAConstant(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+
+	for _, input := range []string{"pi", "π", "2 * pi / 2"} {
+		if v := r.EvaluateLine(input); v.IsError() || math.Abs(v.Number-math.Pi) > 1e-12 || v.Unit != "" {
+			t.Errorf("%s = %+v, want pi", input, v)
+		}
+	}
+	// A variable of the name still wins, as it did before pi was a constant
+	r.EvaluateLine("pi = 3")
+	if v := r.EvaluateLine("pi * 2"); v.Number != 6 {
+		t.Errorf("pi * 2 with pi = 3 gave %+v", v)
+	}
+}
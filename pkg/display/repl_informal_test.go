@@ -0,0 +1,43 @@
+package display
+
+import "testing"
+
+func TestInformalUnits(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"a dozen eggs at £0.25 each", "£3.00"},
+		{"2 dozen + 6", "30.00"},
+		{"half a dozen", "6.00"},
+		{"a couple of", "2.00"},
+		{"3 pinches of salt in grams", "1.13 grams"},
+		{"a splash in ml", "5.00 ml"},
+	}
+	for _, tt := range tests {
+		v := r.EvaluateLine(tt.input)
+		if v.IsError() {
+			t.Fatalf("%s: %s", tt.input, v.Error)
+		}
+		if got := r.Formatter().Format(v); got != tt.want {
+			t.Errorf("%s = %s, want %s", tt.input, got, tt.want)
+		}
+	}
+
+	r.EvaluateLine(":set few 4")
+	if got := r.Formatter().Format(r.EvaluateLine("a few")); got != "4.00" {
+		t.Errorf("a few = %s with few 4, want 4.00", got)
+	}
+
+	r.EvaluateLine(":set informal-units off")
+	if v := r.EvaluateLine("a dozen"); !v.IsError() {
+		t.Errorf("a dozen = %s with informal units off, want an error", r.Formatter().Format(v))
+	}
+	if got := r.Formatter().Format(r.EvaluateLine("3 pinches in ml")); got != "3.00" {
+		t.Errorf("3 pinches in ml = %s with informal units off, want 3.00", got)
+	}
+}
@@ -0,0 +1,122 @@
+package display
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLastResult(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+
+	if v := r.EvaluateLine("_ * 2"); !v.IsError() || v.Error != "no previous result for _" {
+		t.Fatalf("expected an error before any result, got %+v", v)
+	}
+
+	steps := []struct {
+		input string
+		want  string
+	}{
+		{"£10 + £2", "£12.00"},
+		{"_ * 2", "£24.00"},
+		{"1 / 0", "Error: division by zero"},
+		{"_", "£24.00"}, // Errors are skipped
+		{"x = 5 km", "5.00 km"},
+		{"_ in m", "5,000.00 m"},
+	}
+	for _, s := range steps {
+		v := r.EvaluateLine(s.input)
+		if got := strings.TrimSpace(r.formatter.Format(v)); got != s.want {
+			t.Errorf("%s = %s, want %s", s.input, got, s.want)
+		}
+	}
+
+	// A variable called _ takes precedence
+	r.EvaluateLine("_ = 7")
+	r.EvaluateLine("100")
+	if v := r.EvaluateLine("_"); strings.TrimSpace(r.formatter.Format(v)) != "7.00" {
+		t.Errorf("expected the variable _ to win, got %+v", v)
+	}
+}
+
+func TestLastResultIsPrecise(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+
+	steps := []struct {
+		input string
+		want  string
+	}{
+		{"about 37 * 42", "≈ 1,600"},
+		{"_", "1,554.00"},
+		{"about £1274.50", "≈ £1,300"},
+		{"prev", "£1,274.50"},
+	}
+	for _, s := range steps {
+		v := r.EvaluateLine(s.input)
+		if got := strings.TrimSpace(r.formatter.Format(v)); got != s.want {
+			t.Errorf("%s = %s, want %s", s.input, got, s.want)
+		}
+	}
+}
+
+func TestLastResultKeepsWarningsToTheirLine(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+
+	r.EvaluateLine(":set strict off")
+	if v := r.EvaluateLine("missing + 2"); v.Warning == "" {
+		t.Fatalf("expected a warning for the undefined variable, got %+v", v)
+	}
+	if v := r.EvaluateLine("_ + 1"); v.Warning != "" || v.Number != 3 {
+		t.Errorf("expected 3 without a warning, got %+v", v)
+	}
+}
+
+func TestLeadingOperatorContinues(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  string
+	}{
+		{"times", []string{"£10", "* 1.2"}, "£12.00"},
+		{"divide", []string{"£10", "/ 4"}, "£2.50"},
+		{"plus", []string{"£10", "+ £12"}, "£22.00"},
+		{"plus without a space", []string{"£10", "+5"}, "£15.00"},
+		{"minus money", []string{"£10", "- £3"}, "£7.00"},
+		{"minus unit amount", []string{"10 km", "- 500 m"}, "9.50 km"},
+		{"minus currency code", []string{"£10", "- 4 GBP"}, "£6.00"},
+		{"negative number", []string{"£10", "-5"}, "-5.00"},
+		{"negative number with a space", []string{"£10", "- 5"}, "-5.00"},
+		{"negative money", []string{"£10", "-£3"}, "£-3.00"},
+		{"minus money on the first line", []string{"- £3"}, "£-3.00"},
+		{"after an error", []string{"£10", "1 +", "* 3"}, "£30.00"},
+		{"continues a continuation", []string{"2", "* 3", "* 4"}, "24.00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+			r := NewREPL()
+			r.SetSilent(true)
+			var got string
+			for _, line := range tt.lines {
+				got = strings.TrimSpace(r.formatter.Format(r.EvaluateLine(line)))
+			}
+			if got != tt.want {
+				t.Errorf("%q = %s, want %s", tt.lines, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("nothing to continue", func(t *testing.T) {
+		t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+		r := NewREPL()
+		r.SetSilent(true)
+		if v := r.EvaluateLine("* 2"); v.Error != "no previous result for _" {
+			t.Errorf("expected no previous result, got %+v", v)
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package display
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInputLimitsAreErrors(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"line length", strings.Repeat("9", 200001), "line too long: 200001 characters (limit 100000)"},
+		{"tokens", strings.Repeat("1+", 6000) + "1", "too many tokens: 12001 (limit 10000)"},
+		{"depth", strings.Repeat("(", 500) + "1" + strings.Repeat(")", 500), "too deeply nested"},
+	}
+	for _, tt := range tests {
+		v := r.EvaluateLine(tt.input)
+		if !v.IsError() || !strings.Contains(v.Error, tt.want) {
+			t.Errorf("%s: got %v, want error containing %q", tt.name, v, tt.want)
+		}
+		if p, ok := v.Provenance(); !ok || !strings.HasSuffix(p.String(), "…`") {
+			t.Errorf("%s: provenance should quote a shortened line, got %q", tt.name, p.String())
+		}
+	}
+
+	// The session carries on normally afterwards
+	if v := r.EvaluateLine("2 * 3"); v.IsError() || v.Number != 6 {
+		t.Errorf("after limit errors got %v", v)
+	}
+}
+
+func TestInputLimitsFollowSettings(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+
+	r.EvaluateLine(":set max-tokens 5")
+	if v := r.EvaluateLine("1 + 2 + 3 + 4"); !v.IsError() || !strings.Contains(v.Error, "too many tokens") {
+		t.Errorf("max-tokens 5: got %v", v)
+	}
+	r.EvaluateLine(":set max-tokens 10000")
+	r.EvaluateLine(":set max-depth 3")
+	if v := r.EvaluateLine("((((1))))"); !v.IsError() || !strings.Contains(v.Error, "more than 3 levels") {
+		t.Errorf("max-depth 3: got %v", v)
+	}
+	r.EvaluateLine(":set max-line-length 4")
+	if v := r.EvaluateLine("1 + 2"); !v.IsError() || !strings.Contains(v.Error, "line too long") {
+		t.Errorf("max-line-length 4: got %v", v)
+	}
+}
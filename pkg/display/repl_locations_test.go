@@ -0,0 +1,29 @@
+package display
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTimeInPlacesOfSeveralWords(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+
+	plain := r.EvaluateLine("time in Sao Paulo")
+	if plain.IsError() {
+		t.Fatalf("time in Sao Paulo: %s", plain.Error)
+	}
+	for _, input := range []string{"time in São Paulo", "time in Rio de Janeiro"} {
+		if v := r.EvaluateLine(input); v.IsError() || v.Date.Sub(plain.Date).Abs().Minutes() > 1 {
+			t.Errorf("%s = %+v, want the time in Sao Paulo", input, v)
+		}
+	}
+
+	if msg := r.commands.Execute("tz", []string{"add", "Rio", "Office", "America/Sao_Paulo"}); !strings.HasPrefix(msg, "added") {
+		t.Fatalf(":tz add = %q", msg)
+	}
+	if v := r.EvaluateLine("time in Rio Office"); v.IsError() || v.Date.Sub(plain.Date).Abs().Minutes() > 1 {
+		t.Errorf("time in Rio Office = %+v, want the time in Sao Paulo", v)
+	}
+}
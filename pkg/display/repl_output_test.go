@@ -0,0 +1,102 @@
+package display
+
+import (
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/andrewneudegg/calc/pkg/settings"
+)
+
+// replOutput evaluates script in a new REPL at the given output level, as if
+// pasted, and returns everything it printed, command messages included.
+func replOutput(t *testing.T, level settings.OutputLevel, script string) string {
+	t.Helper()
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetOutputLevel(level)
+
+	stdout := os.Stdout
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: %v", err)
+	}
+	os.Stdout = pw
+	r.evaluatePaste(script, os.Stdout)
+	pw.Close()
+	os.Stdout = stdout
+	out, _ := io.ReadAll(pr)
+	pr.Close()
+
+	out = regexp.MustCompile(`took [0-9.]+(µs|ms|s)`).ReplaceAll(out, []byte("took T"))
+	return strings.ReplaceAll(string(out), "\r\n", "\n")
+}
+
+func TestREPLOutputLevels(t *testing.T) {
+	const script = ":set strict off\nrate = 2\nrate * missing\n£10 in USD\n5 km\n1 +\n"
+	const errorLine = "5> 1 +\n   = Error: unexpected token: EOF\n"
+	tests := []struct {
+		level settings.OutputLevel
+		want  string
+	}{
+		{settings.OutputSilent, "1> :set strict off\n" +
+			"1> rate = 2\n" +
+			"2> rate * missing\n   = 0.00\n" +
+			"3> £10 in USD\n   = $12.70\n" +
+			"4> 5 km\n   = 5.00 km\n" + errorLine},
+		{settings.OutputQuiet, "1> :set strict off\nset strict = off\n" +
+			"1> rate = 2\n" +
+			"2> rate * missing\n   = 0.00\n     warning: undefined variable missing treated as 0\n" +
+			"3> £10 in USD\n   = $12.70\n" +
+			"4> 5 km\n   = 5.00 km\n" + errorLine},
+		{settings.OutputNormal, "1> :set strict off\nset strict = off\n" +
+			"1> rate = 2\n   = 2.00\n" +
+			"2> rate * missing\n   = 0.00\n     warning: undefined variable missing treated as 0\n" +
+			"3> £10 in USD\n   = $12.70\n" +
+			"4> 5 km\n   = 5.00 km\n" + errorLine},
+		{settings.OutputVerbose, "1> :set strict off\nset strict = off\n" +
+			"1> rate = 2\n   = 2.00\n     took T\n" +
+			"2> rate * missing\n   = 0.00\n     warning: undefined variable missing treated as 0\n     took T\n" +
+			"3> £10 in USD\n   = $12.70\n     rate: 1 GBP = 1.27 USD\n     took T\n" +
+			"4> 5 km\n   = 5.00 km\n     also: 3.11 mi · 16,404.20 ft\n     took T\n" +
+			"5> 1 +\n   = Error: unexpected token: EOF\n     took T\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level.String(), func(t *testing.T) {
+			if got := replOutput(t, tt.level, script); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestREPLOutputLevelCommands(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+
+	if got := r.OutputLevel(); got != settings.OutputNormal {
+		t.Fatalf("expected the normal level by default, got %v", got)
+	}
+	// :quiet is the quiet level for the session only
+	r.EvaluateLine(":quiet on")
+	if r.OutputLevel() != settings.OutputQuiet || r.Settings().Output != "normal" {
+		t.Errorf(":quiet on: level %v, setting %q", r.OutputLevel(), r.Settings().Output)
+	}
+	r.EvaluateLine(":quiet")
+	if r.OutputLevel() != settings.OutputNormal {
+		t.Errorf(":quiet should toggle back to normal, got %v", r.OutputLevel())
+	}
+	// :set output is kept, and replaces a level given by -q or -v
+	r.SetOutputLevel(settings.OutputQuiet)
+	r.EvaluateLine(":set output verbose")
+	if r.OutputLevel() != settings.OutputVerbose || r.Settings().Output != "verbose" {
+		t.Errorf(":set output verbose: level %v, setting %q", r.OutputLevel(), r.Settings().Output)
+	}
+	r.EvaluateLine(":set output silent")
+	if !r.IsQuiet() {
+		t.Errorf("assignments should print nothing at the silent level")
+	}
+}
@@ -0,0 +1,72 @@
+package display
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrewneudegg/calc/pkg/evaluator"
+)
+
+func TestLineResultsCarryProvenance(t *testing.T) {
+	r := NewREPL()
+	r.SetSilent(true)
+
+	r.EvaluateLine("a = 2")
+	r.EvaluateLine(":help")
+	v := r.EvaluateLine("total = a + 3")
+
+	p, ok := v.Provenance()
+	if !ok {
+		t.Fatal("line result has no provenance")
+	}
+	if p.Input != "total = a + 3" || p.Line != 2 || !p.Assign {
+		t.Errorf("Provenance() = %+v", p)
+	}
+	if stored, _ := r.lines[2].Result.Provenance(); stored != p {
+		t.Errorf("stored line provenance = %+v, want %+v", stored, p)
+	}
+
+	// Formatting reads the value without consuming its provenance
+	if got := r.Formatter().Format(v); got != "5.00" {
+		t.Errorf("Format() = %q", got)
+	}
+	if _, ok := v.Provenance(); !ok {
+		t.Error("formatting dropped the provenance")
+	}
+
+	// Variables and earlier results used as operands are not line results
+	if a, _ := r.env.GetVariable("a"); hasProvenance(a) {
+		t.Error("variable a carries provenance")
+	}
+	if v := r.EvaluateLine("prev * 2"); !hasProvenance(v) {
+		t.Error("prev * 2 has no provenance of its own")
+	} else if p, _ := v.Provenance(); p.Line != 3 || p.Assign {
+		t.Errorf("prev * 2 provenance = %+v", p)
+	}
+}
+
+func TestErrorProvenanceNamesScriptLine(t *testing.T) {
+	r := NewREPL()
+	r.SetSilent(true)
+
+	r.SetSourceLine(7)
+	v := r.EvaluateLine("total = a + b")
+	p, ok := v.Provenance()
+	if !v.IsError() || !ok {
+		t.Fatalf("got %+v, want an error with provenance", v)
+	}
+	if got := "error in " + p.String() + ": " + v.Error; !strings.HasPrefix(got, "error in line 7: `total = a + b`: undefined variable") {
+		t.Errorf("message = %q", got)
+	}
+
+	// Parse errors are numbered too, and the script line applies only once
+	v = r.EvaluateLine("1 +")
+	if p, ok := v.Provenance(); !ok || p.Line != 2 {
+		t.Errorf("parse error provenance = %+v, %v", p, ok)
+	}
+}
+
+func hasProvenance(v evaluator.Value) bool {
+	_, ok := v.Provenance()
+	return ok
+}
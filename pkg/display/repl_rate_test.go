@@ -0,0 +1,44 @@
+package display
+
+import "testing"
+
+// TestStoredRatesConvertLikeLiterals replays a session that defines a rate,
+// scales it and converts it, checking each line against the same expression
+// written out in full.
+func TestStoredRatesConvertLikeLiterals(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+
+	session := []struct {
+		input   string
+		literal string
+		want    string
+	}{
+		{"rate = $32/day", "", "32.00 $/day"},
+		{"rate in gbp/month", "$32/day in gbp/month", ""},
+		{"rate * 1.1", "", "35.20 $/day"},
+		{"rate * 1.1 in gbp/month", "$35.20/day in gbp/month", ""},
+		{"raised = rate * 1.1", "", "35.20 $/day"},
+		{"raised in gbp/month", "$35.20/day in gbp/month", ""},
+		{"rate + rate", "", "64.00 $/day"},
+		{"rate + $2/hour", "", "80.00 $/day"},
+		{"rate - $1/hour", "", "8.00 $/day"},
+		{"rate + $2/hour in $/week", "", "$560.00"},
+		{"rate / 2", "", "16.00 $/day"},
+	}
+	for _, step := range session {
+		v := r.EvaluateLine(step.input)
+		if v.IsError() {
+			t.Fatalf("%s: %s", step.input, v.Error)
+		}
+		got := r.Formatter().Format(v)
+		want := step.want
+		if step.literal != "" {
+			want = r.Formatter().Format(r.EvaluateLine(step.literal))
+		}
+		if got != want {
+			t.Errorf("%s = %s, want %s", step.input, got, want)
+		}
+	}
+}
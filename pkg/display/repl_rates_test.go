@@ -0,0 +1,67 @@
+package display
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestShowRatesAnnotatesConversionsOnce(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+
+	if got := r.formatter.Format(r.EvaluateLine("£100 in usd")); got != "$127.00" {
+		t.Fatalf("expected no annotation while show-rates is off, got %q", got)
+	}
+
+	r.EvaluateLine(":set show-rates on")
+	r.EvaluateLine("x = £100 in usd")
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"x", "$127.00 (1 GBP = 1.27 USD)"},
+		{"x * 2 + x", "$381.00 (1 GBP = 1.27 USD)"},
+		{"x + £100 in usd", "$254.00 (1 GBP = 1.27 USD)"},
+		{"£100 + £1", "£101.00"},
+		{"$127 / £25", "4.00 (1 GBP = 1.27 USD)"},
+		{"£500 / £25", "20.00"},
+	}
+	for _, tt := range tests {
+		got := r.formatter.Format(r.EvaluateLine(tt.input))
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.input, got, tt.want)
+		}
+		if n := strings.Count(got, "1 GBP"); n > 1 {
+			t.Errorf("%s: rate shown %d times", tt.input, n)
+		}
+	}
+}
+
+func TestPastRatesPersistAcrossSessions(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	csv := filepath.Join(t.TempDir(), "rates.csv")
+	if err := os.WriteFile(csv, []byte("2024-03-15,GBP,USD,1.2734\n2024-03-18,GBP,USD,1.2720\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewREPL()
+	r.SetSilent(true)
+	if v := r.EvaluateLine("£100 in usd on 15/03/2024"); !v.IsError() {
+		t.Fatalf("expected an error before any rates are imported, got %+v", v)
+	}
+	r.EvaluateLine(":rates import " + csv)
+
+	for name, session := range map[string]*REPL{"this session": r, "next session": NewREPL()} {
+		session.SetSilent(true)
+		if got := session.formatter.Format(session.EvaluateLine("£100 in usd on 18/03/2024")); got != "$127.20 (18 Mar 2024 rate)" {
+			t.Errorf("%s: got %q", name, got)
+		}
+		session.EvaluateLine(":clear")
+		if got := session.formatter.Format(session.EvaluateLine("£100 in usd on 15/03/2024")); got != "$127.34 (15 Mar 2024 rate)" {
+			t.Errorf("%s: after :clear got %q", name, got)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package display
+
+import "testing"
+
+func TestRoundingSetting(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+
+	if v := r.EvaluateLine("round(2.5)"); v.Number != 3 {
+		t.Fatalf("expected round(2.5) to be 3 by default, got %+v", v)
+	}
+
+	r.EvaluateLine(":set rounding half-even")
+	if v := r.EvaluateLine("round(2.5)"); v.Number != 2 {
+		t.Errorf("expected round(2.5) to be 2 with half-even, got %+v", v)
+	}
+	if got := r.formatter.Format(r.EvaluateLine("2.665")); got != "2.66" {
+		t.Errorf("expected 2.665 to display as 2.66 with half-even, got %q", got)
+	}
+
+	r.EvaluateLine(":set rounding floor")
+	v := r.EvaluateLine("x = 2.679")
+	if got := r.formatter.Format(v); got != "2.67" {
+		t.Errorf("expected 2.679 to display as 2.67 with floor, got %q", got)
+	}
+	if v := r.EvaluateLine("x * 1000"); v.Number != 2679 {
+		t.Errorf("expected the stored variable to keep every digit, got %+v", v)
+	}
+}
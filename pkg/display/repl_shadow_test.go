@@ -0,0 +1,84 @@
+package display
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestShadowWarningOncePerName(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+
+	v := r.EvaluateLine("m = 5")
+	if want := "'m' is also the unit metres; the unit meaning still applies after numbers"; v.Warning != want {
+		t.Errorf("m = 5: warning %q, want %q", v.Warning, want)
+	}
+	if v := r.EvaluateLine("m = 6"); v.Warning != "" {
+		t.Errorf("second assignment to m warned again: %q", v.Warning)
+	}
+	if v := r.EvaluateLine("rent = 900"); v.Warning != "" {
+		t.Errorf("rent = 900 warned: %q", v.Warning)
+	}
+	if v := r.EvaluateLine("sum = 2"); !strings.Contains(v.Warning, "function sum()") {
+		t.Errorf("sum = 2: warning %q", v.Warning)
+	}
+	if got := r.commands.Execute("warnings", nil); strings.Count(got, "\n") != 1 || !strings.HasPrefix(got, "line 1: 'm'") {
+		t.Errorf(":warnings = %q", got)
+	}
+
+	// A cleared session warns afresh
+	r.clearWorkspace()
+	if v := r.EvaluateLine("m = 5"); v.Warning == "" {
+		t.Error("m = 5 after :clear did not warn")
+	}
+}
+
+func TestShadowingLeavesMeaningsAlone(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+
+	r.EvaluateLine("m = 5")
+	if v := r.EvaluateLine("m * 2"); v.Number != 10 || v.Unit != "" {
+		t.Errorf("m * 2 = %v %s, want the variable", v.Number, v.Unit)
+	}
+	if v := r.EvaluateLine("2 m"); v.Number != 2 || v.Unit != "m" {
+		t.Errorf("2 m = %v %s, want the unit", v.Number, v.Unit)
+	}
+	r.EvaluateLine("sum = 2")
+	if v := r.EvaluateLine("sum(1, 2)"); v.Number != 3 {
+		t.Errorf("sum(1, 2) = %v, want the function", v.Number)
+	}
+}
+
+func TestShadowWarningsOff(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+
+	r.EvaluateLine(":set shadow-warnings off")
+	if v := r.EvaluateLine("m = 5"); v.Warning != "" {
+		t.Errorf("warned with shadow-warnings off: %q", v.Warning)
+	}
+	if got := r.commands.Execute("warnings", nil); got != "no warnings" {
+		t.Errorf(":warnings = %q", got)
+	}
+}
+
+func TestVarsMarksShadowingNames(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+
+	if got := r.commands.Execute("vars", nil); !strings.HasPrefix(got, "no variables") {
+		t.Errorf(":vars with none = %q", got)
+	}
+	r.EvaluateLine("rent = 900")
+	r.EvaluateLine("m = 5")
+	got := r.commands.Execute("vars", nil)
+	lines := strings.Split(got, "\n")
+	if len(lines) != 3 || lines[0] != "m = 5.00 *" || lines[1] != "rent = 900.00" || !strings.Contains(lines[2], "unit or currency after a number") {
+		t.Errorf(":vars = %q", got)
+	}
+}
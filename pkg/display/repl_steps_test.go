@@ -0,0 +1,37 @@
+package display
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestShowSteps(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+
+	if got := r.formatResult(r.EvaluateLine("5 miles in km in m")); strings.Contains(got, "steps:") {
+		t.Errorf("show-steps off: got %q", got)
+	}
+
+	r.EvaluateLine(":set show-steps on")
+	r.EvaluateLine(":set ascii off")
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"5 miles in km in m", "8,046.72 m\n     steps: 5.00 miles = 8.05 km = 8,046.72 m"},
+		{"£100 in usd in jpy", "¥18,955\n     steps: £100.00 = $127.00 = ¥18,955"},
+		// A stored result carries on its chain
+		{"d = 3 ft in cm", "91.44 cm\n     steps: 3.00 ft = 91.44 cm"},
+		{"d in m", "0.91 m\n     steps: 3.00 ft = 91.44 cm = 0.91 m"},
+		// Arithmetic on a converted value is not a conversion step
+		{"(5 miles in km) * 2", "16.09 km"},
+		{"12 * 3", "36.00"},
+	}
+	for _, tt := range tests {
+		if got := r.formatResult(r.EvaluateLine(tt.input)); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package display
+
+import "testing"
+
+func TestStrictOffWarnsAndListsWarnings(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+
+	if v := r.EvaluateLine("rent * 12"); !v.IsError() {
+		t.Fatalf("strict: got %v, want an error", v)
+	}
+	r.EvaluateLine(":set strict off")
+	v := r.EvaluateLine("rent * 12")
+	if v.IsError() || v.Number != 0 || v.Warning != "undefined variable rent treated as 0" {
+		t.Errorf("not strict: got %+v", v)
+	}
+	r.EvaluateLine("rent = 900")
+	if v := r.EvaluateLine("rent * 12"); v.Number != 10800 || v.Warning != "" {
+		t.Errorf("after defining rent: got %+v", v)
+	}
+
+	if got := r.commands.Execute("warnings", nil); got != "line 2: undefined variable rent treated as 0" {
+		t.Errorf(":warnings = %q", got)
+	}
+	r.clearWorkspace()
+	if got := r.commands.Execute("warnings", nil); got != "no warnings" {
+		t.Errorf(":warnings after clear = %q", got)
+	}
+}
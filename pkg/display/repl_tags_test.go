@@ -0,0 +1,124 @@
+package display
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSumByTag(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+
+	steps := []struct {
+		input string
+		want  string
+	}{
+		{"coffee = £3.20 #food", "£3.20"},
+		{"lunch = $12.70 #food #work", "$12.70"},
+		{"£5 #Food", "£5.00"},
+		{"taxi = 12 km #travel", "12.00 km"},
+		{"500 m #travel", "500.00 m"},
+		{"1 / 0 #food", "Error: division by zero"},
+		{"sum #food", "£18.20"},
+		{"average #food", "£6.07"},
+		{"total of #food in usd", "$23.11"},
+		{"sum #travel", "12.50 km"},
+		{"sum #work", "$12.70"},
+		{"coffee * 2", "£6.40"},
+	}
+	for _, s := range steps {
+		v := r.EvaluateLine(s.input)
+		if got := strings.TrimSpace(r.formatter.Format(v)); got != s.want {
+			t.Errorf("%s = %s, want %s", s.input, got, s.want)
+		}
+	}
+
+	if v := r.EvaluateLine("sum #nothing"); v.Error != "no lines tagged #nothing" {
+		t.Errorf("expected no tagged lines, got %+v", v)
+	}
+	if v := r.EvaluateLine("prev#1"); strings.TrimSpace(r.formatter.Format(v)) != "£3.20" {
+		t.Errorf("prev#1 should still read line 1, got %+v", v)
+	}
+}
+
+func TestSumByTagWithLabels(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+
+	steps := []struct {
+		input string
+		want  string
+	}{
+		{"coffee: £3.20 #food", "£3.20"},
+		{"lunch: $12.70 #food", "$12.70"},
+		{"sum #food", "£13.20"},
+		{"coffee: £3.50 #food", "£3.50"},
+		{"sum #food", "£13.50"},
+		{"coffee * 2", "£7.00"},
+	}
+	for _, s := range steps {
+		v := r.EvaluateLine(s.input)
+		if got := strings.TrimSpace(r.formatter.Format(v)); got != s.want {
+			t.Errorf("%s = %s, want %s", s.input, got, s.want)
+		}
+	}
+}
+
+func TestTaggedLinesReplaceTheirOldValue(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+
+	r.EvaluateLine("coffee = £3.20 #food")
+	r.EvaluateLine("lunch = £10 #food")
+	if v := r.EvaluateLine("sum #food"); strings.TrimSpace(r.formatter.Format(v)) != "£13.20" {
+		t.Fatalf("sum #food = %+v, want £13.20", v)
+	}
+
+	// Assigning coffee again replaces its old value rather than adding to it
+	r.EvaluateLine("coffee = £3.50 #food")
+	if v := r.EvaluateLine("sum #food"); strings.TrimSpace(r.formatter.Format(v)) != "£13.50" {
+		t.Errorf("after reassigning coffee, sum #food = %s, want £13.50", r.formatter.Format(v))
+	}
+
+	// Re-evaluating a line, as loading a workspace does, replaces its tags
+	r.nextID = 2
+	r.EvaluateLine("lunch = £8 #work")
+	r.nextID = 6
+	if v := r.EvaluateLine("sum #food"); strings.TrimSpace(r.formatter.Format(v)) != "£3.50" {
+		t.Errorf("after re-evaluating lunch, sum #food = %s, want £3.50", r.formatter.Format(v))
+	}
+	if v := r.EvaluateLine("sum #work"); strings.TrimSpace(r.formatter.Format(v)) != "£8.00" {
+		t.Errorf("sum #work = %s, want £8.00", r.formatter.Format(v))
+	}
+
+	// Assigning it again without the tag takes it out of the tag
+	r.EvaluateLine("coffee = £4")
+	if v := r.EvaluateLine("sum #food"); v.Error != "no lines tagged #food" {
+		t.Errorf("expected coffee to leave #food, got %+v", v)
+	}
+}
+
+func TestTagsCommand(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+
+	if got := r.commands.Execute("tags", nil); !strings.HasPrefix(got, "no tags") {
+		t.Errorf("expected no tags, got %q", got)
+	}
+	r.EvaluateLine("£3.20 #food")
+	r.EvaluateLine("$12.70 #food #work")
+	r.EvaluateLine("12 km #travel")
+	want := "#food: 2 lines, total £13.20\n#travel: 1 line, total 12.00 km\n#work: 1 line, total $12.70"
+	if got := r.commands.Execute("tags", nil); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	r.EvaluateLine(":clear")
+	if got := r.commands.Execute("tags", nil); !strings.HasPrefix(got, "no tags") {
+		t.Errorf("expected :clear to forget the tags, got %q", got)
+	}
+}
@@ -0,0 +1,62 @@
+package display
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTutorialLessonSavesProgress(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+
+	if got := r.tutorialCommand("currency"); !strings.HasPrefix(got, "Lesson: Currency") {
+		t.Fatalf(":tutorial currency = %q", got)
+	}
+	steps := []struct {
+		input string
+		want  string
+	}{
+		{"£12.50 + £7.52", "Not quite."},
+		{"# comments are not answers", ""},
+		{"£12.50 +", "That gave an error."},
+		{"£12.50 + £7.25", "Correct!"},
+		{"£100 in USD", "Correct!"},
+		{"£84 / 3", "Correct!"},
+	}
+	for _, s := range steps {
+		r.EvaluateLine(s.input)
+		if got := r.takeTutorialNote(); !strings.HasPrefix(got, s.want) {
+			t.Errorf("%s: reply %q, want it to start %q", s.input, got, s.want)
+		}
+	}
+	if r.tutorial.Active() {
+		t.Error("lesson still running after its last step")
+	}
+
+	// A new session remembers the completed lesson
+	next := NewREPL()
+	next.SetSilent(true)
+	if got := next.tutorialCommand("list"); !strings.Contains(got, "currency     Currency, 3 steps  done") {
+		t.Errorf(":tutorial list in a new session:\n%s", got)
+	}
+	if got := next.tutorialCommand(""); !strings.Contains(got, "Lesson: Arithmetic") {
+		t.Errorf(":tutorial in a new session = %q", got)
+	}
+}
+
+func TestTutorialCommandsAreNotAnswers(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+
+	r.EvaluateLine(":tutorial arithmetic")
+	r.EvaluateLine(":set precision 3")
+	if got := r.takeTutorialNote(); got != "" {
+		t.Errorf("a command was checked as an answer: %q", got)
+	}
+	r.EvaluateLine("12 + 30")
+	if got := r.takeTutorialNote(); !strings.HasPrefix(got, "Correct!") {
+		t.Errorf("12 + 30: reply %q", got)
+	}
+}
@@ -0,0 +1,100 @@
+package display
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/andrewneudegg/calc/pkg/lexer"
+)
+
+func TestUnitPackImportAndExport(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	dir := t.TempDir()
+	pack := filepath.Join(dir, "pack.json")
+	if err := os.WriteFile(pack, []byte(`{"version": 1, "units": [{"name": "rackunit", "value": 44.45, "base": "mm"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewREPL()
+	r.SetSilent(true)
+	r.EvaluateLine(":unit import " + pack)
+	if v := r.EvaluateLine("3 rackunit in cm"); v.IsError() || math.Abs(v.Number-13.335) > 1e-9 {
+		t.Fatalf("3 rackunit in cm = %+v, want 13.335 cm", v)
+	}
+
+	// --replace overwrites the existing definition
+	if err := os.WriteFile(pack, []byte(`{"version": 1, "units": [{"name": "rackunit", "value": 50, "base": "mm"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	r.EvaluateLine(":unit import " + pack)
+	if v := r.EvaluateLine("2 rackunit in mm"); math.Abs(v.Number-88.9) > 1e-9 {
+		t.Fatalf("merge import should keep the old definition, got %+v", v)
+	}
+	r.EvaluateLine(":unit import " + pack + " --replace")
+	if v := r.EvaluateLine("2 rackunit in mm"); math.Abs(v.Number-100) > 1e-9 {
+		t.Fatalf("--replace should overwrite the definition, got %+v", v)
+	}
+
+	out := filepath.Join(dir, "out.json")
+	r.EvaluateLine(":unit export " + out)
+	r2 := NewREPL()
+	r2.SetSilent(true)
+	r2.EvaluateLine(":unit import " + out)
+	if v := r2.EvaluateLine("1 rackunit in mm"); math.Abs(v.Number-50) > 1e-9 {
+		t.Fatalf("exported pack should round-trip, got %+v", v)
+	}
+}
+
+func TestCustomUnitsPersistAcrossSessions(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("CALC_CONFIG_DIR", dir)
+
+	r := NewREPL()
+	r.SetSilent(true)
+	r.EvaluateLine(":unit define rackunit = 44.45 mm")
+
+	r2 := NewREPL()
+	r2.SetSilent(true)
+	if v := r2.EvaluateLine("2 rackunit in mm"); v.IsError() || math.Abs(v.Number-88.9) > 1e-9 {
+		t.Fatalf("2 rackunit in mm in a new session = %+v, want 88.9 mm", v)
+	}
+
+	// Custom units survive :clear, which rebuilds the environment
+	r2.EvaluateLine(":clear")
+	if v := r2.EvaluateLine("1 rackunit in mm"); v.IsError() {
+		t.Fatalf("rackunit lost after :clear: %+v", v)
+	}
+
+	// Once deleted the name reads as an identifier again
+	r2.EvaluateLine(":unit delete rackunit")
+	if v := r2.EvaluateLine("rackunit = 3"); v.IsError() || v.Number != 3 {
+		t.Fatalf("rackunit should be usable as a variable after delete, got %+v", v)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "units.json")); err != nil {
+		t.Fatalf("units.json not written: %v", err)
+	}
+	r3 := NewREPL()
+	r3.SetSilent(true)
+	if r3.env.Units().IsCustomUnit("rackunit") {
+		t.Fatal("deleted unit came back in a new session")
+	}
+}
+
+func TestCustomUnitLexesAsUnitInSameSession(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+
+	if toks := r.lex("10 box"); toks[1].Type != lexer.TokenIdent {
+		t.Fatalf("box before definition = %s, want an identifier", toks[1].Type)
+	}
+	r.EvaluateLine(":unit define box = 0.5 m")
+	if toks := r.lex("10 box"); toks[1].Type != lexer.TokenUnit {
+		t.Fatalf("box after definition = %s, want UNIT", toks[1].Type)
+	}
+	if v := r.EvaluateLine("10 box in m"); v.IsError() || math.Abs(v.Number-5) > 1e-9 {
+		t.Fatalf("10 box in m = %+v, want 5 m", v)
+	}
+}
@@ -0,0 +1,117 @@
+package display
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/andrewneudegg/calc/pkg/evaluator"
+	"github.com/andrewneudegg/calc/pkg/settings"
+)
+
+func TestWorkspaceSaveKeepsSettingsUnitsAndVariables(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	path := filepath.Join(t.TempDir(), "fidelity.calc")
+
+	r := NewREPL()
+	r.SetSilent(true)
+	for _, line := range []string{
+		":set precision 4",
+		":set rounding half-even",
+		":set currency USD",
+		":unit define rackunit = 44.45 mm",
+		":ingredient define cocoa = 0.52 g/ml",
+		"height = 12 rackunit",
+		"price = £3.20",
+		"total = price * quantity",
+	} {
+		r.EvaluateLine(line)
+	}
+	// As a script's :arg directive or --arg would
+	r.Env().SetVariable("quantity", evaluator.NewNumber(1.0/3))
+	r.Env().SetVariable("Label", evaluator.NewString(`rack "A"`))
+	r.Env().SetVariable("share", evaluator.NewPercent(12.5))
+	r.EvaluateLine("total = price * quantity")
+	if err := r.saveWorkspace(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// Wipe everything: a new session with its own, empty, settings and units
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	opened := NewREPL()
+	opened.SetSilent(true)
+	if err := opened.loadWorkspace(path); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, st := range settings.Schema() {
+		if got, want := st.Value(opened.settings), st.Value(r.settings); got != want {
+			t.Errorf("setting %s = %s, want %s", st.Key, got, want)
+		}
+	}
+	if got, want := opened.env.Units().ExportPack(), r.env.Units().ExportPack(); !reflect.DeepEqual(got, want) {
+		t.Errorf("custom units = %+v, want %+v", got, want)
+	}
+	names := r.env.GetVariableNames()
+	if got := opened.env.GetVariableNames(); len(got) != len(names) {
+		t.Errorf("variables %v, want %v", got, names)
+	}
+	for _, name := range names {
+		want, _ := r.env.GetVariable(name)
+		got, ok := opened.env.GetVariable(name)
+		if !ok || got.Type != want.Type || got.Number != want.Number || got.Unit != want.Unit ||
+			got.Currency != want.Currency || got.Text != want.Text {
+			t.Errorf("%s = %+v, want %+v", name, got, want)
+		}
+		if display, _ := opened.env.VariableName(name); display != name {
+			t.Errorf("variable %s reopened as %s", name, display)
+		}
+	}
+
+	// Opening a workspace does not change the saved settings or units
+	if again := NewREPL(); again.settings.Precision != 2 || len(again.env.Units().CustomUnits()) != 0 {
+		t.Error("opening a workspace saved its settings or units")
+	}
+}
+
+func TestWorkspaceOpensPlainFilesAndWarnsOnBadDirectives(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "plain.calc")
+	if err := os.WriteFile(plain, []byte("# calc workspace\nx = 2\ny = x * 3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	r := NewREPL()
+	r.SetSilent(true)
+	if err := r.loadWorkspace(plain); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := r.env.GetVariable("y"); v.Number != 6 {
+		t.Errorf("y = %v, want 6", v.Number)
+	}
+
+	bad := filepath.Join(dir, "bad.calc")
+	data := "#: set precision 40\n#: set precision 3\n#: unit furlongish = many m\n#: colour blue\nx = 1\n"
+	if err := os.WriteFile(bad, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	directives := readDirectives(data)
+	var msgs []string
+	for _, w := range r.applySettingDirectives(directives) {
+		msgs = append(msgs, w.msg)
+	}
+	for _, w := range r.applyUnitDirectives(directives) {
+		msgs = append(msgs, w.msg)
+	}
+	got := strings.Join(msgs, "\n")
+	for _, want := range []string{"precision must be", `unknown directive "colour"`, `"many" is not a number`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("warnings %q do not mention %q", got, want)
+		}
+	}
+	if r.settings.Precision != 3 {
+		t.Errorf("precision = %d, want the valid directive's 3", r.settings.Precision)
+	}
+}
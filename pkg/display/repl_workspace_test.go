@@ -51,3 +51,140 @@ func TestWorkspaceSaveAndOpenRoundTrip(t *testing.T) {
 		t.Fatalf("expected x+y == 5, got %+v", v)
 	}
 }
+
+func TestWorkspaceOpenWarnsOnCaseCollision(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	path := t.TempDir() + "/mixed.calc"
+	if err := os.WriteFile(path, []byte("Rate = 5\nrate = 7\ntotal = Rate * 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewREPL()
+	if err := r.loadWorkspace(path); err != nil {
+		t.Fatalf("loadWorkspace: %v", err)
+	}
+
+	lines := r.ListLines()
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d", len(lines))
+	}
+	if !strings.Contains(lines[1].Result.Warning, "variable names ignore case") {
+		t.Errorf("expected a collision warning on line 2, got %q", lines[1].Result.Warning)
+	}
+	if lines[2].Result.Number != 14 {
+		t.Errorf("expected both casings to share one variable, got %v", lines[2].Result.Number)
+	}
+}
+
+func TestWorkspaceOpenResolvesForwardReferences(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	path := t.TempDir() + "/forward.calc"
+
+	// Build a session whose lines were edited out of order, then save it
+	r := NewREPL()
+	r.SetSilent(true)
+	for _, line := range []string{"cost = rent + bills", "rent = 900", "bills = rent / 4", `print("cost {cost}")`} {
+		_ = r.EvaluateLine(line)
+	}
+	if err := r.saveWorkspace(path); err != nil {
+		t.Fatalf("saveWorkspace: %v", err)
+	}
+
+	r2 := NewREPL()
+	r2.SetSilent(true)
+	if err := r2.loadWorkspace(path); err != nil {
+		t.Fatalf("loadWorkspace: %v", err)
+	}
+
+	lines := r2.ListLines()
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 lines, got %d", len(lines))
+	}
+	if lines[0].Input != "cost = rent + bills" {
+		t.Errorf("lines should keep their file order, first is %q", lines[0].Input)
+	}
+	if lines[0].Result.IsError() || lines[0].Result.Number != 1125 {
+		t.Errorf("cost = %+v, want 1125", lines[0].Result)
+	}
+	if got := lines[3].Result.Text; got != "cost 1,125.00" {
+		t.Errorf("print = %q, want %q", got, "cost 1,125.00")
+	}
+	if v := r2.EvaluateLine("cost"); v.Number != 1125 {
+		t.Errorf("cost after open = %+v", v)
+	}
+	if v := r2.EvaluateLine("prev"); v.Number != 1125 {
+		t.Errorf("new lines should follow the loaded ones, prev = %+v", v)
+	}
+}
+
+func TestWorkspaceOpenKeepsRedefinitionOrder(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	path := t.TempDir() + "/redefine.calc"
+	data := "y = x * 2\nx = 1\nz = x + 1\nx = 10\nw = x\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewREPL()
+	r.SetSilent(true)
+	if err := r.loadWorkspace(path); err != nil {
+		t.Fatalf("loadWorkspace: %v", err)
+	}
+	want := []float64{2, 1, 2, 10, 10}
+	for i, line := range r.ListLines() {
+		if line.Result.Number != want[i] {
+			t.Errorf("%s = %v, want %v", line.Input, line.Result.Number, want[i])
+		}
+	}
+}
+
+func TestWorkspaceOpenKeepsBrokenLines(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	path := t.TempDir() + "/broken.calc"
+	data := "total = a + b\na = 1\nbroken = a + missing\nb = 2\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewREPL()
+	r.SetSilent(true)
+	if err := r.loadWorkspace(path); err != nil {
+		t.Fatalf("loadWorkspace: %v", err)
+	}
+
+	lines := r.ListLines()
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 lines, got %d", len(lines))
+	}
+	if !lines[2].Result.IsError() || lines[2].Input != "broken = a + missing" {
+		t.Errorf("broken line = %q %+v, want it kept with its error", lines[2].Input, lines[2].Result)
+	}
+	if lines[0].Result.Number != 3 {
+		t.Errorf("total = %+v, want 3", lines[0].Result)
+	}
+}
+
+func TestWorkspaceOpenReportsCycles(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	path := t.TempDir() + "/cycle.calc"
+	data := "rent = bills + 1\ncost = rent * 2\nbills = cost - 3\nfoo = 4\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewREPL()
+	r.SetSilent(true)
+	_ = r.EvaluateLine("kept = 5")
+	err := r.loadWorkspace(path)
+	if err == nil || err.Error() != "circular reference: rent -> bills -> cost -> rent" {
+		t.Fatalf("error = %v, want the cycle named", err)
+	}
+	if v := r.EvaluateLine("kept"); v.Number != 5 {
+		t.Errorf("a failed open should leave the session alone, kept = %+v", v)
+	}
+
+	msg := r.commands.Execute("open", []string{path})
+	if !strings.Contains(msg, "circular reference: rent -> bills -> cost -> rent") {
+		t.Errorf(":open message = %q", msg)
+	}
+}
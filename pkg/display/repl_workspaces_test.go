@@ -0,0 +1,199 @@
+package display
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// resultOf evaluates input and returns its formatted result, failing the
+// test on an error.
+func resultOf(t *testing.T, r *REPL, input string) string {
+	t.Helper()
+	v := r.EvaluateLine(input)
+	if v.IsError() {
+		t.Fatalf("%s: unexpected error: %s", input, v.Error)
+	}
+	return strings.TrimSpace(r.formatter.Format(v))
+}
+
+func TestWorkspacesAreIsolated(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+
+	resultOf(t, r, "spend = £100")
+	resultOf(t, r, "nights = 3")
+
+	if msg := r.workspaceCommand([]string{"new", "travel"}); msg != "created workspace travel" {
+		t.Fatalf("new: %q", msg)
+	}
+	if r.nextID != 1 {
+		t.Errorf("a new workspace numbers lines from 1, got %d", r.nextID)
+	}
+	if v := r.EvaluateLine("nights"); !v.IsError() {
+		t.Errorf("nights leaked into travel: %+v", v)
+	}
+	resultOf(t, r, "spend = £40")
+	resultOf(t, r, "unit_rate = 7")
+
+	if msg := r.workspaceCommand([]string{"new", "budget"}); msg != "created workspace budget" {
+		t.Fatalf("new: %q", msg)
+	}
+	resultOf(t, r, "spend = £2500")
+	if got := resultOf(t, r, "prev"); got != "£2,500.00" {
+		t.Errorf("prev in budget = %s", got)
+	}
+
+	if msg := r.workspaceCommand([]string{"switch", "travel"}); msg != "switched to workspace travel" {
+		t.Fatalf("switch: %q", msg)
+	}
+	if got := resultOf(t, r, "spend * 2"); got != "£80.00" {
+		t.Errorf("spend in travel = %s, want £80.00", got)
+	}
+	// The failed read of nights was line 1 of travel
+	if r.nextID != 5 {
+		t.Errorf("travel should carry on from line 5, got %d", r.nextID)
+	}
+
+	r.workspaceCommand([]string{"switch", "main"})
+	if got := resultOf(t, r, "spend + nights * £1"); got != "£103.00" {
+		t.Errorf("spend in main = %s, want £103.00", got)
+	}
+	if got := r.ListLines(); len(got) != 3 || got[0].Input != "spend = £100" {
+		t.Errorf("main has %d lines, want 3", len(got))
+	}
+	if v := r.EvaluateLine("unit_rate"); !v.IsError() {
+		t.Errorf("unit_rate leaked into main: %+v", v)
+	}
+}
+
+func TestWorkspaceSharesSettings(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+
+	r.EvaluateLine(":set precision 3")
+	r.workspaceCommand([]string{"new", "other"})
+	if got := resultOf(t, r, "1 / 3"); got != "0.333" {
+		t.Errorf("precision in a new workspace = %s, want 0.333", got)
+	}
+}
+
+func TestWorkspaceCommand(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+
+	if got := r.prompt(); got != "1> " {
+		t.Errorf("prompt before any workspace = %q", got)
+	}
+	resultOf(t, r, "x = 1")
+	r.EvaluateLine(":workspace new Travel")
+	resultOf(t, r, "y = 2")
+	resultOf(t, r, "y + 1")
+	if got := r.prompt(); got != "travel 3> " {
+		t.Errorf("prompt in travel = %q", got)
+	}
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{nil, "  main (1 line)\n* travel (2 lines)"},
+		{[]string{"list"}, "  main (1 line)\n* travel (2 lines)"},
+		{[]string{"new", "main"}, "error: workspace main already exists; use :workspace switch main"},
+		{[]string{"switch", "travel"}, "already in workspace travel"},
+		{[]string{"switch", "work"}, "error: no workspace named work; :workspace new work creates one"},
+		{[]string{"delete", "travel"}, "error: cannot delete the active workspace travel; switch to another first"},
+		{[]string{"delete", "work"}, "error: no workspace named work"},
+		{[]string{"new", "a b"}, `error: workspace names are letters, digits, - and _, got "a b"`},
+		{[]string{"new"}, "usage: :workspace new <name>"},
+		{[]string{"rename", "x"}, "unknown workspace command: rename (use new, switch, delete or list)"},
+		{[]string{"switch", "main"}, "switched to workspace main"},
+		{[]string{"delete", "travel"}, "deleted workspace travel"},
+		{[]string{"list"}, "* main (1 line)"},
+	}
+	for _, tt := range tests {
+		if got := r.workspaceCommand(tt.args); got != tt.want {
+			t.Errorf(":workspace %s = %q, want %q", strings.Join(tt.args, " "), got, tt.want)
+		}
+	}
+	if got := r.prompt(); got != "2> " {
+		t.Errorf("prompt back in main alone = %q", got)
+	}
+}
+
+func TestWorkspaceSaveAndOpenActiveOnly(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	dir := t.TempDir()
+	r := NewREPL()
+	r.SetSilent(true)
+
+	resultOf(t, r, "rent = £1200")
+	r.EvaluateLine(":workspace new trip")
+	resultOf(t, r, "flights = £300")
+	path := filepath.Join(dir, "trip.calc")
+	r.EvaluateLine(":save " + path)
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(b); !strings.Contains(s, "flights = £300") || strings.Contains(s, "rent") {
+		t.Errorf("saved trip workspace = %q", s)
+	}
+
+	// Opening replaces only the active workspace
+	r.EvaluateLine(":workspace switch main")
+	r.EvaluateLine(":workspace new copy")
+	r.EvaluateLine(":open " + path)
+	if got := resultOf(t, r, "flights"); got != "£300.00" {
+		t.Errorf("flights after :open = %s", got)
+	}
+	r.EvaluateLine(":workspace switch main")
+	if got := resultOf(t, r, "rent"); got != "£1,200.00" {
+		t.Errorf("rent in main = %s", got)
+	}
+	if v := r.EvaluateLine("flights"); !v.IsError() {
+		t.Errorf("flights leaked into main: %+v", v)
+	}
+}
+
+func TestWorkspaceCustomUnitsAreIsolated(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	earlier := NewREPL()
+	earlier.SetSilent(true)
+	earlier.EvaluateLine(":unit define smoot = 1.7018 m")
+
+	r := NewREPL()
+	r.SetSilent(true)
+	r.EvaluateLine(":unit define rackunit = 44.45 mm")
+	r.workspaceCommand([]string{"new", "travel"})
+	if got := resultOf(t, r, "2 smoot in m"); got != "3.40 m" {
+		t.Errorf("a unit saved by an earlier session = %s, want 3.40 m", got)
+	}
+	if r.env.Units().IsCustomUnit("rackunit") {
+		t.Error("rackunit leaked into travel")
+	}
+	r.EvaluateLine(":unit define league = 3 mi")
+
+	r.workspaceCommand([]string{"switch", "main"})
+	if r.env.Units().IsCustomUnit("league") {
+		t.Error("league leaked into main")
+	}
+	// :clear drops the lines but keeps the workspace's units
+	r.EvaluateLine(":clear")
+	if got := resultOf(t, r, "1 rackunit in mm"); got != "44.45 mm" {
+		t.Errorf("rackunit after :clear = %s", got)
+	}
+
+	// Both are saved, so a later session has both
+	later := NewREPL()
+	for _, name := range []string{"rackunit", "league", "smoot"} {
+		if !later.env.Units().IsCustomUnit(name) {
+			t.Errorf("%s was not saved", name)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package display
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/andrewneudegg/calc/pkg/evaluator"
+)
+
+// warnShadow notes on result, and in the list :warnings shows, that the
+// variable just assigned shares its name with a unit, currency, function or
+// keyword. Each name is warned of once a session, and not at all with
+// :set shadow-warnings off.
+func (r *REPL) warnShadow(result evaluator.Value, name string, line int) evaluator.Value {
+	key := strings.ToLower(name)
+	if !r.settings.ShadowWarnings || result.IsError() || r.shadowWarned[key] {
+		return result
+	}
+	msg := r.env.ShadowWarning(name)
+	if msg == "" {
+		return result
+	}
+	if r.shadowWarned == nil {
+		r.shadowWarned = make(map[string]bool)
+	}
+	r.shadowWarned[key] = true
+	r.warnings = append(r.warnings, fmt.Sprintf("line %d: %s", line, msg))
+	if result.Warning != "" {
+		msg = result.Warning + "; " + msg
+	}
+	result.Warning = msg
+	return result
+}
+
+// Vars lists the session's variables by name with their values for :vars,
+// marking those that share a name with a unit, currency, function or
+// keyword, and ending with the rule that decides which meaning applies.
+func (r *REPL) Vars() []string {
+	names := r.env.GetVariableNames()
+	sort.Slice(names, func(i, j int) bool { return strings.ToLower(names[i]) < strings.ToLower(names[j]) })
+	var out []string
+	shadowing := false
+	for _, name := range names {
+		v, _ := r.env.GetVariable(name)
+		line := fmt.Sprintf("%s = %s", name, strings.TrimSpace(r.formatter.Format(v)))
+		if r.env.ShadowWarning(name) != "" {
+			line += " *"
+			shadowing = true
+		}
+		out = append(out, line)
+	}
+	if shadowing {
+		out = append(out, "* shares its name: the variable where a value is expected (m * 2), the unit or currency after a number (10 m), the function before ( and the keyword where one fits")
+	}
+	return out
+}
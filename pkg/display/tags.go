@@ -0,0 +1,94 @@
+package display
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/andrewneudegg/calc/pkg/evaluator"
+	"github.com/andrewneudegg/calc/pkg/parser"
+)
+
+// taggedValues returns the results of the lines filed under tag, in line
+// order, leaving out lines that failed. Assigning a variable again replaces
+// its earlier value in place rather than adding to it, so re-entering
+// "coffee = £3.50 #food" corrects the coffee line; without the tag, it
+// takes coffee out of #food.
+func (r *REPL) taggedValues(tag string) []evaluator.Value {
+	var vals []evaluator.Value
+	for _, line := range r.taggedLines(tag) {
+		value := line.Result
+		value.Warning = ""
+		vals = append(vals, value)
+	}
+	return vals
+}
+
+// taggedLines returns the lines whose results taggedValues gives, a line
+// that assigned a variable again standing in the place of the first.
+func (r *REPL) taggedLines(tag string) []*Line {
+	type slot struct {
+		line *Line
+		keep bool
+	}
+	var slots []slot
+	assigned := make(map[string]int) // Variable -> its slot
+	for id := 1; id < r.nextID; id++ {
+		line, ok := r.lines[id]
+		if !ok {
+			continue
+		}
+		tagged := slices.Contains(line.Tags, tag) && !line.Result.IsError()
+		if assign, ok := line.Expr.(*parser.AssignExpr); ok {
+			name := strings.ToLower(assign.Name)
+			if i, ok := assigned[name]; ok {
+				slots[i] = slot{line, tagged}
+				continue
+			}
+			if tagged {
+				assigned[name] = len(slots)
+			}
+		}
+		if tagged {
+			slots = append(slots, slot{line, true})
+		}
+	}
+
+	var lines []*Line
+	for _, s := range slots {
+		if s.keep {
+			lines = append(lines, s.line)
+		}
+	}
+	return lines
+}
+
+// Tags lists the session's tags for :tags, each with how many lines it is
+// on and their total, such as "#food: 3 lines, total £12.40".
+func (r *REPL) Tags() []string {
+	var names []string
+	for _, line := range r.lines {
+		for _, tag := range line.Tags {
+			if !slices.Contains(names, tag) {
+				names = append(names, tag)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	var out []string
+	for _, tag := range names {
+		n := len(r.taggedValues(tag))
+		if n == 0 {
+			continue
+		}
+		lines := "lines"
+		if n == 1 {
+			lines = "line"
+		}
+		total := r.eval.Eval(&parser.TagExpr{Func: "sum", Tag: tag})
+		out = append(out, fmt.Sprintf("#%s: %d %s, total %s", tag, n, lines, strings.TrimSpace(r.formatter.Format(total))))
+	}
+	return out
+}
@@ -24,6 +24,8 @@ type Theme struct {
 	Ident string
 	// Error text
 	Error string
+	// Secondary lines under a result, such as :set also conversions
+	Hint string
 	// Reset sequence
 	Reset string
 }
@@ -42,6 +44,7 @@ func DefaultTheme() *Theme {
 		Time:     "\x1b[36m", // cyan
 		Ident:    "\x1b[37m", // white (default-ish)
 		Error:    "\x1b[31m", // red
+		Hint:     "\x1b[2m",  // dim
 		Reset:    "\x1b[0m",
 	}
 }
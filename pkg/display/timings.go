@@ -0,0 +1,156 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// LineTiming records how long each stage took for a single evaluated line.
+type LineTiming struct {
+	Line   int // Source line number (as set via SetLine), or the sequence number if unset
+	Input  string
+	Tokens int // Tokens the line lexed to
+	Lex    time.Duration
+	Parse  time.Duration
+	Eval   time.Duration
+}
+
+// Total returns the combined duration of all stages.
+func (t LineTiming) Total() time.Duration {
+	return t.Lex + t.Parse + t.Eval
+}
+
+// Timings collects per-line stage durations for a REPL with timings enabled.
+type Timings struct {
+	Lines []LineTiming
+	// Threshold lists every line that took at least this long in the
+	// report, in script order. Zero lists none.
+	Threshold time.Duration
+	line      int
+	now       func() time.Time // Reads the clock for stage marks; time.Now when nil
+}
+
+// clock returns the time for a stage mark.
+func (t *Timings) clock() time.Time {
+	if t.now != nil {
+		return t.now()
+	}
+	return time.Now()
+}
+
+// SetLine sets the source line number attributed to subsequently evaluated input.
+func (t *Timings) SetLine(n int) {
+	t.line = n
+}
+
+// record stores the stage durations for one line. Stage marks that were never
+// reached (e.g. parse for a comment-only line) collapse onto the previous mark.
+func (t *Timings) record(input string, tokens int, start, lexed, parsed, end time.Time) {
+	if lexed.IsZero() {
+		lexed = end
+	}
+	if parsed.IsZero() {
+		parsed = lexed
+	}
+	line := t.line
+	if line == 0 {
+		line = len(t.Lines) + 1
+	}
+	t.Lines = append(t.Lines, LineTiming{
+		Line:   line,
+		Input:  input,
+		Tokens: tokens,
+		Lex:    lexed.Sub(start),
+		Parse:  parsed.Sub(lexed),
+		Eval:   end.Sub(parsed),
+	})
+}
+
+// Totals returns the summed lex, parse, and eval durations across all lines.
+func (t *Timings) Totals() (lex, parse, eval time.Duration) {
+	for _, l := range t.Lines {
+		lex += l.Lex
+		parse += l.Parse
+		eval += l.Eval
+	}
+	return lex, parse, eval
+}
+
+// Tokens returns the number of tokens lexed across all lines.
+func (t *Timings) Tokens() int {
+	n := 0
+	for _, l := range t.Lines {
+		n += l.Tokens
+	}
+	return n
+}
+
+// Over returns the lines that took at least d, in the order they ran.
+func (t *Timings) Over(d time.Duration) []LineTiming {
+	var over []LineTiming
+	for _, l := range t.Lines {
+		if l.Total() >= d {
+			over = append(over, l)
+		}
+	}
+	return over
+}
+
+// Slowest returns up to n lines ordered from slowest to fastest.
+func (t *Timings) Slowest(n int) []LineTiming {
+	sorted := append([]LineTiming(nil), t.Lines...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Total() > sorted[j].Total()
+	})
+	if n < len(sorted) {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
+
+// WriteReport writes a summary of stage totals and throughput, the n
+// slowest lines and any lines over the threshold.
+func (t *Timings) WriteReport(w io.Writer, n int) {
+	lex, parse, eval := t.Totals()
+	total := lex + parse + eval
+	tokens := t.Tokens()
+	fmt.Fprintf(w, "Timings: %d lines in %s (lex %s, parse %s, eval %s), %d tokens",
+		len(t.Lines), total, lex, parse, eval, tokens)
+	if total > 0 {
+		fmt.Fprintf(w, " at %.0f tokens/s", float64(tokens)/total.Seconds())
+	}
+	fmt.Fprintln(w)
+	slowest := t.Slowest(n)
+	if len(slowest) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "Slowest %d lines:\n", len(slowest))
+	writeTimedLines(w, slowest)
+	if t.Threshold <= 0 {
+		return
+	}
+	if over := t.Over(t.Threshold); len(over) > 0 {
+		fmt.Fprintf(w, "Lines over %s:\n", t.Threshold)
+		writeTimedLines(w, over)
+	}
+}
+
+// writeTimedLines writes one report row per line: its number, time and input.
+func writeTimedLines(w io.Writer, lines []LineTiming) {
+	for _, l := range lines {
+		fmt.Fprintf(w, "  line %-5d %12s  %s\n", l.Line, l.Total(), inputPrefix(l.Input, 40))
+	}
+}
+
+// inputPrefix shortens input to at most max runes, marking truncation with an ellipsis.
+func inputPrefix(input string, max int) string {
+	input = strings.TrimSpace(input)
+	runes := []rune(input)
+	if len(runes) <= max {
+		return input
+	}
+	return string(runes[:max-1]) + "…"
+}
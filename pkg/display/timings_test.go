@@ -0,0 +1,126 @@
+package display
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrewneudegg/calc/pkg/evaluator"
+	"github.com/andrewneudegg/calc/pkg/parser"
+)
+
+// slowEvalHook evaluates normally but stalls on assignments to "slow".
+func slowEvalHook(r *REPL, delay time.Duration) func(parser.Expr) evaluator.Value {
+	return func(expr parser.Expr) evaluator.Value {
+		if assign, ok := expr.(*parser.AssignExpr); ok && assign.Name == "slow" {
+			time.Sleep(delay)
+		}
+		return r.eval.Eval(expr)
+	}
+}
+
+func TestTimingsReportSlowestLineFirst(t *testing.T) {
+	r := NewREPL()
+	r.evalHook = slowEvalHook(r, 20*time.Millisecond)
+	timings := r.EnableTimings()
+
+	script := []string{"a = 1", "b = a + 2", "slow = b * 3", "c = 10 m in cm"}
+	for i, line := range script {
+		timings.SetLine(i + 1)
+		r.EvaluateLine(line)
+	}
+
+	if len(timings.Lines) != len(script) {
+		t.Fatalf("expected %d timed lines, got %d", len(script), len(timings.Lines))
+	}
+	slowest := timings.Slowest(2)
+	if slowest[0].Line != 3 || slowest[0].Input != "slow = b * 3" {
+		t.Fatalf("expected line 3 to be slowest, got %+v", slowest[0])
+	}
+	if slowest[0].Eval < 20*time.Millisecond {
+		t.Errorf("expected eval stage to include the injected delay, got %s", slowest[0].Eval)
+	}
+
+	var buf bytes.Buffer
+	timings.WriteReport(&buf, 2)
+	out := buf.String()
+	if !strings.Contains(out, "4 lines") {
+		t.Errorf("report missing line count:\n%s", out)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) < 3 || !strings.Contains(lines[2], "line 3") {
+		t.Errorf("expected line 3 at the top of the slowest list:\n%s", out)
+	}
+}
+
+func TestTimingsReportWithFakeClock(t *testing.T) {
+	r := NewREPL()
+	r.SetSilent(true)
+	timings := r.EnableTimings()
+	// Each reading of the clock is a millisecond after the last, so every
+	// stage of every line takes exactly 1ms
+	var ticks time.Duration
+	timings.now = func() time.Time {
+		ticks += time.Millisecond
+		return time.Unix(0, 0).Add(ticks)
+	}
+	timings.Threshold = 3 * time.Millisecond
+
+	for i, line := range []string{"a = 1", "b = a + 2 * 3"} {
+		timings.SetLine(i + 1)
+		r.EvaluateLine(line)
+	}
+
+	var buf bytes.Buffer
+	timings.WriteReport(&buf, 10)
+	want := "Timings: 2 lines in 6ms (lex 2ms, parse 2ms, eval 2ms), 10 tokens at 1667 tokens/s\n" +
+		"Slowest 2 lines:\n" +
+		"  line 1              3ms  a = 1\n" +
+		"  line 2              3ms  b = a + 2 * 3\n" +
+		"Lines over 3ms:\n" +
+		"  line 1              3ms  a = 1\n" +
+		"  line 2              3ms  b = a + 2 * 3\n"
+	if got := buf.String(); got != want {
+		t.Errorf("report:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSetTimingShowsTimeTaken(t *testing.T) {
+	t.Setenv("CALC_CONFIG_DIR", t.TempDir())
+	r := NewREPL()
+	r.SetSilent(true)
+
+	if got := r.formatResult(r.EvaluateLine("10 km in miles")); strings.Contains(got, "took") {
+		t.Errorf("timing off: got %q", got)
+	}
+	r.EvaluateLine(":set timing on")
+	got := r.formatResult(r.EvaluateLine("10 km in miles"))
+	if !regexp.MustCompile(`^6\.21 miles\n     took [0-9.]+(µs|ms|s)$`).MatchString(got) {
+		t.Errorf("timing on: got %q", got)
+	}
+}
+
+func TestTimingsDisabledByDefault(t *testing.T) {
+	r := NewREPL()
+	r.EvaluateLine("x = 1")
+	if r.timings != nil {
+		t.Fatalf("timings should not be collected unless enabled")
+	}
+}
+
+func BenchmarkEvaluateLineTimingsOff(b *testing.B) {
+	r := NewREPL()
+	for i := 0; i < b.N; i++ {
+		r.EvaluateLine("x = 10 km in miles")
+	}
+}
+
+func BenchmarkEvaluateLineTimingsOn(b *testing.B) {
+	r := NewREPL()
+	r.EnableTimings()
+	for i := 0; i < b.N; i++ {
+		r.EvaluateLine("x = 10 km in miles")
+	}
+}
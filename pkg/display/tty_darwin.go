@@ -51,3 +51,17 @@ func restoreRawMode(fd int, state *RawState) {
 	}
 	syscall.Syscall6(syscall.SYS_IOCTL, uintptr(fd), uintptr(syscall.TIOCSETA), uintptr(unsafe.Pointer(state)), 0, 0, 0)
 }
+
+// enableANSI reports whether the terminal shows escape sequences as colours
+// and cursor movement, which Unix terminals always do.
+func enableANSI(fd uintptr) bool { return true }
+
+// terminalWidth returns the number of columns of the terminal on fd, or 0
+// when fd is not a terminal.
+func terminalWidth(fd uintptr) int {
+	var ws struct{ Row, Col, Xpixel, Ypixel uint16 }
+	if _, _, e := syscall.Syscall6(syscall.SYS_IOCTL, fd, uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&ws)), 0, 0, 0); e != 0 {
+		return 0
+	}
+	return int(ws.Col)
+}
@@ -59,3 +59,18 @@ func restoreRawMode(fd int, state *RawState) {
 	}
 	syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), TCSETS, uintptr(unsafe.Pointer(state)))
 }
+
+// enableANSI reports whether the terminal shows escape sequences as colours
+// and cursor movement, which Unix terminals always do.
+func enableANSI(fd uintptr) bool { return true }
+
+// terminalWidth returns the number of columns of the terminal on fd, or 0
+// when fd is not a terminal.
+func terminalWidth(fd uintptr) int {
+	var ws struct{ Row, Col, Xpixel, Ypixel uint16 }
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, syscall.TIOCGWINSZ, uintptr(unsafe.Pointer(&ws)))
+	if errno != 0 {
+		return 0
+	}
+	return int(ws.Col)
+}
@@ -1,4 +1,4 @@
-//go:build !darwin && !linux
+//go:build !darwin && !linux && !windows
 
 package display
 
@@ -14,3 +14,7 @@ func enableRawMode(fd int) (*RawState, error) {
 }
 
 func restoreRawMode(fd int, _ *RawState) {}
+
+func enableANSI(fd uintptr) bool { return false }
+
+func terminalWidth(fd uintptr) int { return 0 }
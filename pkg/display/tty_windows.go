@@ -0,0 +1,102 @@
+//go:build windows
+
+package display
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// RawState is the console input mode saved for restoration.
+type RawState struct {
+	mode uint32
+}
+
+// Console mode flags, from the Windows SDK's wincon.h.
+const (
+	enableProcessedInput            = 0x0001
+	enableLineInput                 = 0x0002
+	enableEchoInput                 = 0x0004
+	enableVirtualTerminalInput      = 0x0200
+	enableProcessedOutput           = 0x0001
+	enableVirtualTerminalProcessing = 0x0004
+)
+
+var (
+	kernel32                       = syscall.NewLazyDLL("kernel32.dll")
+	procSetConsoleMode             = kernel32.NewProc("SetConsoleMode")
+	procGetConsoleScreenBufferInfo = kernel32.NewProc("GetConsoleScreenBufferInfo")
+)
+
+// consoleScreenBufferInfo is CONSOLE_SCREEN_BUFFER_INFO from wincon.h.
+type consoleScreenBufferInfo struct {
+	size, cursor             [2]int16
+	attributes               uint16
+	left, top, right, bottom int16
+	maxSize                  [2]int16
+}
+
+// isATTY checks if the given handle is a console.
+func isATTY(fd uintptr) bool {
+	var mode uint32
+	return syscall.GetConsoleMode(syscall.Handle(fd), &mode) == nil
+}
+
+// setConsoleMode sets the mode of a console handle.
+func setConsoleMode(h syscall.Handle, mode uint32) error {
+	if ok, _, err := procSetConsoleMode.Call(uintptr(h), uintptr(mode)); ok == 0 {
+		return os.NewSyscallError("SetConsoleMode", err)
+	}
+	return nil
+}
+
+// enableRawMode switches the console to unbuffered input without echo, in
+// which Ctrl-C arrives as a key and arrow keys as the escape sequences
+// the line editor reads on other platforms, and returns the previous mode.
+func enableRawMode(fd int) (*RawState, error) {
+	h := syscall.Handle(fd)
+	var orig uint32
+	if err := syscall.GetConsoleMode(h, &orig); err != nil {
+		return nil, os.NewSyscallError("GetConsoleMode", err)
+	}
+	raw := orig&^(enableEchoInput|enableLineInput|enableProcessedInput) | enableVirtualTerminalInput
+	if err := setConsoleMode(h, raw); err != nil {
+		return nil, err
+	}
+	return &RawState{mode: orig}, nil
+}
+
+// restoreRawMode restores a previously saved console mode.
+func restoreRawMode(fd int, state *RawState) {
+	if state == nil {
+		return
+	}
+	setConsoleMode(syscall.Handle(fd), state.mode)
+}
+
+// enableANSI turns on virtual terminal processing for the console, so that
+// colours and cursor movement are drawn rather than printed as escape
+// sequences. It reports false on consoles too old to support it, before
+// Windows 10.
+func enableANSI(fd uintptr) bool {
+	h := syscall.Handle(fd)
+	var mode uint32
+	if syscall.GetConsoleMode(h, &mode) != nil {
+		return false
+	}
+	if mode&enableVirtualTerminalProcessing != 0 {
+		return true
+	}
+	return setConsoleMode(h, mode|enableProcessedOutput|enableVirtualTerminalProcessing) == nil
+}
+
+// terminalWidth returns the number of columns of the console window on fd,
+// or 0 when fd is not a console.
+func terminalWidth(fd uintptr) int {
+	var info consoleScreenBufferInfo
+	if ok, _, _ := procGetConsoleScreenBufferInfo.Call(fd, uintptr(unsafe.Pointer(&info))); ok == 0 {
+		return 0
+	}
+	return int(info.right-info.left) + 1
+}
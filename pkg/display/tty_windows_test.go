@@ -0,0 +1,38 @@
+//go:build windows
+
+package display
+
+import (
+	"os"
+	"testing"
+)
+
+// TestConsoleInvalidHandle tests that a handle that is not a console is
+// neither a terminal nor able to show colours
+func TestConsoleInvalidHandle(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "notconsole")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if isATTY(f.Fd()) {
+		t.Error("Expected a file not to be a console")
+	}
+	if enableANSI(f.Fd()) {
+		t.Error("Expected enableANSI to fail on a file")
+	}
+	if _, err := enableRawMode(int(f.Fd())); err == nil {
+		t.Error("Expected error when enabling raw mode on a file")
+	}
+}
+
+// TestEnableANSIConsole tests turning on escape sequences for a real console
+func TestEnableANSIConsole(t *testing.T) {
+	if !isATTY(os.Stdout.Fd()) {
+		t.Skip("Skipping test: stdout is not a console")
+	}
+	// Legacy consoles may refuse; asking twice must give the same answer
+	if enableANSI(os.Stdout.Fd()) != enableANSI(os.Stdout.Fd()) {
+		t.Error("Expected enableANSI to be repeatable")
+	}
+}
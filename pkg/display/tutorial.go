@@ -0,0 +1,68 @@
+package display
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/andrewneudegg/calc/pkg/evaluator"
+	"github.com/andrewneudegg/calc/pkg/lexer"
+	"github.com/andrewneudegg/calc/pkg/tutorial"
+)
+
+// tutorialCommand runs :tutorial, loading the lessons the first time.
+func (r *REPL) tutorialCommand(args string) string {
+	if r.tutorial == nil {
+		lessons, err := tutorial.Lessons()
+		if err != nil {
+			return fmt.Sprintf("error: %s", err)
+		}
+		r.tutorial = tutorial.NewRunner(lessons, r.tutorialValue, r.settings.Tutorial)
+	}
+	return r.tutorial.Command(args)
+}
+
+// tutorialValue evaluates a lesson's expected answer in the session, so it
+// uses the same rates and clock as the answer, without storing a line.
+func (r *REPL) tutorialValue(input string) evaluator.Value {
+	tokens := r.lex(input)
+	if n := len(tokens); n > 0 && tokens[n-1].Type == lexer.TokenEOF {
+		tokens = tokens[:n-1]
+	}
+	expr, err := r.parse(tokens)
+	if err != nil {
+		return evaluator.NewError(err.Error())
+	}
+	return r.eval.Eval(expr)
+}
+
+// checkTutorial passes the line just evaluated to a running lesson. next is
+// the line number the line would have taken, so a line that was stored is
+// checked by its stored result, even when quiet output hid it.
+func (r *REPL) checkTutorial(input string, next int, result evaluator.Value) {
+	if r.tutorial == nil || !r.tutorial.Active() || strings.HasPrefix(strings.TrimSpace(input), ":") {
+		return
+	}
+	got := result
+	if line, ok := r.lines[next]; ok && r.nextID > next {
+		got = line.Result
+	} else if !result.IsError() || result.Error == "" {
+		// A comment or blank line is not an answer
+		return
+	}
+	reply, finished := r.tutorial.Answer(input, got)
+	if finished {
+		r.settings.Tutorial = r.tutorial.Completed()
+		if err := r.settings.Save(); err != nil {
+			reply += fmt.Sprintf("\nwarning: could not save tutorial progress: %s", err)
+		}
+	}
+	r.tutorialNote = reply
+}
+
+// takeTutorialNote returns the reply to the last answer, to print after its
+// result, and forgets it.
+func (r *REPL) takeTutorialNote() string {
+	note := r.tutorialNote
+	r.tutorialNote = ""
+	return note
+}
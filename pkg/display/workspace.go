@@ -0,0 +1,154 @@
+package display
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/andrewneudegg/calc/pkg/graph"
+	"github.com/andrewneudegg/calc/pkg/lexer"
+	"github.com/andrewneudegg/calc/pkg/parser"
+)
+
+// workspaceLine is an expression line read from a workspace file.
+type workspaceLine struct {
+	number  int      // 1-based line number in the file, for warnings
+	input   string   // the line as written
+	defines []string // lower-cased variables the line assigns, if any
+	reads   []string // lower-cased names the line refers to
+}
+
+// placeholderPattern finds the {name} and {name:spec} placeholders that
+// print interpolates.
+var placeholderPattern = regexp.MustCompile(`\{\s*([^{}\s:]+)\s*(?::[^{}]*)?\}`)
+
+// readWorkspace returns the expression lines of a workspace file, skipping
+// blank lines, comments and commands, with the variables each one assigns
+// and reads.
+func (r *REPL) readWorkspace(data string) []workspaceLine {
+	var lines []workspaceLine
+	for i, ln := range strings.Split(data, "\n") {
+		t := strings.TrimSpace(ln)
+		if t == "" || strings.HasPrefix(t, "#") || strings.HasPrefix(t, ":") {
+			continue
+		}
+		line := workspaceLine{number: i + 1, input: t}
+		tokens := r.lex(t)
+		targets := 0 // Tokens naming the variables assigned, up to the '='
+		if expr, err := r.parse(tokens); err == nil {
+			for _, name := range parser.AssignedNames(expr) {
+				line.defines = append(line.defines, strings.ToLower(name))
+			}
+			if len(line.defines) > 0 {
+				targets = slices.IndexFunc(tokens, func(tok lexer.Token) bool { return tok.Type == lexer.TokenEquals })
+			}
+		}
+		// Variables may share a name with a unit or keyword, such as a or
+		// total, so any word can be a read; only defined names count later
+		for j, tok := range tokens {
+			switch {
+			case j < targets:
+			case tok.Type == lexer.TokenString:
+				for _, m := range placeholderPattern.FindAllStringSubmatch(tok.Literal, -1) {
+					line.reads = append(line.reads, strings.ToLower(m[1]))
+				}
+			default:
+				line.reads = append(line.reads, strings.ToLower(tok.Literal))
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// workspaceOrder returns the order to evaluate workspace lines in, as indexes
+// into lines, so that a variable is defined before it is read even when the
+// file defines it further down. A line reads the nearest earlier definition
+// of a name, or failing that the first later one, and a redefinition waits
+// for the lines that read the value it replaces. Lines that do not depend on
+// each other keep their file order. Variables defined in terms of each other
+// are an error naming the cycle.
+func workspaceOrder(lines []workspaceLine) ([]int, error) {
+	defs := make(map[string][]int) // variable -> indexes of the lines assigning it, ascending
+	for i, l := range lines {
+		for _, name := range l.defines {
+			defs[name] = append(defs[name], i)
+		}
+	}
+
+	deps := make([][]int, len(lines))
+	for i, l := range lines {
+		for _, name := range l.defines {
+			if prev := lastBefore(defs[name], i); prev >= 0 {
+				deps[i] = append(deps[i], prev)
+			}
+		}
+		for _, name := range l.reads {
+			def := lastBefore(defs[name], i)
+			if def < 0 {
+				def = firstAfter(defs[name], i)
+			}
+			if def < 0 {
+				continue
+			}
+			deps[i] = append(deps[i], def)
+			if next := firstAfter(defs[name], def); next >= 0 && next != i {
+				deps[next] = append(deps[next], i)
+			}
+		}
+	}
+
+	// Graph IDs are 1-based so that they read as line positions
+	g := graph.NewGraph()
+	for i, l := range lines {
+		ids := make([]int, len(deps[i]))
+		for j, d := range deps[i] {
+			ids[j] = d + 1
+		}
+		g.AddNode(i+1, l.input, ids)
+	}
+	order, err := g.TopologicalSort()
+	var cycle *graph.CycleError
+	if errors.As(err, &cycle) {
+		names := make([]string, len(cycle.Path))
+		for i, id := range cycle.Path {
+			l := lines[id-1]
+			names[i] = l.input
+			if len(l.defines) > 0 {
+				names[i] = strings.Join(l.defines, ", ")
+			}
+		}
+		return nil, fmt.Errorf("circular reference: %s", strings.Join(names, " -> "))
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range order {
+		order[i]--
+	}
+	return order, nil
+}
+
+// lastBefore returns the last of the ascending indexes that is below i, or -1.
+func lastBefore(indexes []int, i int) int {
+	found := -1
+	for _, idx := range indexes {
+		if idx >= i {
+			break
+		}
+		found = idx
+	}
+	return found
+}
+
+// firstAfter returns the first of the ascending indexes that is above i, or -1.
+func firstAfter(indexes []int, i int) int {
+	for _, idx := range indexes {
+		if idx > i {
+			return idx
+		}
+	}
+	return -1
+}
@@ -0,0 +1,208 @@
+package display
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/andrewneudegg/calc/pkg/evaluator"
+	"github.com/andrewneudegg/calc/pkg/lexer"
+	"github.com/andrewneudegg/calc/pkg/parser"
+	"github.com/andrewneudegg/calc/pkg/settings"
+	"github.com/andrewneudegg/calc/pkg/units"
+)
+
+// directivePrefix starts the lines of a workspace file that restore what its
+// lines alone do not: settings changed from their defaults, custom units and
+// ingredients, and variables set from outside the lines, such as script
+// arguments. They read as comments, so the file still runs with calc -f.
+const directivePrefix = "#:"
+
+// workspaceDirective is a directive line read from a workspace file.
+type workspaceDirective struct {
+	number int    // 1-based line number in the file, for warnings
+	verb   string // set, unit, ingredient or var
+	args   string // the rest of the line
+}
+
+// workspaceDirectives returns the directive lines for a saved workspace:
+//
+//	#: set precision 4
+//	#: unit rackunit = 0.04445 m
+//	#: ingredient cocoa = 0.52 g/ml
+//	#: var rate = 0.2
+func (r *REPL) workspaceDirectives() []string {
+	var out []string
+	for _, st := range settings.Schema() {
+		if v := st.Value(r.settings); v != st.Default() {
+			out = append(out, fmt.Sprintf("%s set %s %s", directivePrefix, st.Key, v))
+		}
+	}
+
+	pack := r.env.Units().ExportPack()
+	for _, u := range pack.Units {
+		out = append(out, fmt.Sprintf("%s unit %s = %s %s", directivePrefix, u.Name, formatFloat(u.Value), u.Base))
+	}
+	for _, in := range pack.Ingredients {
+		out = append(out, fmt.Sprintf("%s ingredient %s = %s g/ml", directivePrefix, in.Name, formatFloat(in.Density)))
+	}
+
+	// Variables no line assigns, such as script arguments
+	assigned := make(map[string]bool)
+	for _, line := range r.lines {
+		for _, name := range parser.AssignedNames(line.Expr) {
+			assigned[strings.ToLower(name)] = true
+		}
+	}
+	names := r.env.GetVariableNames()
+	sort.Strings(names)
+	for _, name := range names {
+		if assigned[strings.ToLower(name)] {
+			continue
+		}
+		v, _ := r.env.GetVariable(name)
+		if text, ok := valueLiteral(v); ok {
+			out = append(out, fmt.Sprintf("%s var %s = %s", directivePrefix, name, text))
+		}
+	}
+	return out
+}
+
+// readDirectives returns the directive lines of a workspace file.
+func readDirectives(data string) []workspaceDirective {
+	var out []workspaceDirective
+	for i, ln := range strings.Split(data, "\n") {
+		rest, ok := strings.CutPrefix(strings.TrimSpace(ln), directivePrefix)
+		if !ok {
+			continue
+		}
+		verb, args, _ := strings.Cut(strings.TrimSpace(rest), " ")
+		out = append(out, workspaceDirective{number: i + 1, verb: strings.ToLower(verb), args: strings.TrimSpace(args)})
+	}
+	return out
+}
+
+// applySettingDirectives changes the session's settings as the set
+// directives say, without saving them, and returns warnings for those that
+// could not be applied and for directives it does not know.
+func (r *REPL) applySettingDirectives(directives []workspaceDirective) []directiveWarning {
+	var warnings []directiveWarning
+	for _, d := range directives {
+		switch d.verb {
+		case "set", "unit", "ingredient", "var":
+		default:
+			warnings = append(warnings, directiveWarning{d.number, fmt.Sprintf("unknown directive %q", d.verb)})
+		}
+		if d.verb != "set" {
+			continue
+		}
+		key, value, _ := strings.Cut(d.args, " ")
+		if err := r.settings.Set(key, strings.TrimSpace(value)); err != nil {
+			warnings = append(warnings, directiveWarning{d.number, err.Error()})
+		}
+	}
+	return warnings
+}
+
+// applyUnitDirectives adds the units and ingredients of the unit and
+// ingredient directives to the session, without saving them with the custom
+// units, replacing any of the same name.
+func (r *REPL) applyUnitDirectives(directives []workspaceDirective) []directiveWarning {
+	var warnings []directiveWarning
+	for _, d := range directives {
+		if d.verb != "unit" && d.verb != "ingredient" {
+			continue
+		}
+		name, def, _ := strings.Cut(d.args, "=")
+		fields := strings.Fields(def)
+		if len(fields) != 2 {
+			warnings = append(warnings, directiveWarning{d.number, fmt.Sprintf("%s directive should be <name> = <value> <unit>", d.verb)})
+			continue
+		}
+		value, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			warnings = append(warnings, directiveWarning{d.number, fmt.Sprintf("%q is not a number", fields[0])})
+			continue
+		}
+		pack := units.Pack{Version: units.PackVersion}
+		name = strings.TrimSpace(name)
+		if d.verb == "unit" {
+			pack.Units = []units.PackUnit{{Name: name, Value: value, Base: fields[1]}}
+		} else {
+			pack.Ingredients = []units.PackIngredient{{Name: name, Density: value}}
+		}
+		report, err := r.env.Units().ImportPack(pack, true)
+		if err == nil && len(report.Failed) > 0 {
+			err = fmt.Errorf("%s", report.Failed[0])
+		}
+		if err != nil {
+			warnings = append(warnings, directiveWarning{d.number, err.Error()})
+		}
+	}
+	return warnings
+}
+
+// applyVariableDirectives sets the variables of the var directives. Their
+// values are written as calc reads them whatever the locale, so they are
+// parsed without it.
+func (r *REPL) applyVariableDirectives(directives []workspaceDirective) []directiveWarning {
+	var warnings []directiveWarning
+	for _, d := range directives {
+		if d.verb != "var" {
+			continue
+		}
+		name, text, _ := strings.Cut(d.args, "=")
+		name, text = strings.TrimSpace(name), strings.TrimSpace(text)
+		expr, err := parser.New(lexer.NewWithUnits(text, r.env.IsUnit).AllTokens()).Parse()
+		if err != nil {
+			warnings = append(warnings, directiveWarning{d.number, err.Error()})
+			continue
+		}
+		v := r.eval.Eval(expr)
+		if v.IsError() {
+			warnings = append(warnings, directiveWarning{d.number, v.Error})
+			continue
+		}
+		r.env.SetVariable(name, v)
+	}
+	return warnings
+}
+
+// directiveWarning is a directive that could not be applied.
+type directiveWarning struct {
+	number int
+	msg    string
+}
+
+// valueLiteral writes v as a line that evaluates to it again, reporting
+// false for values that cannot be, such as a date with a time of day.
+func valueLiteral(v evaluator.Value) (string, bool) {
+	if v.Per != 0 || v.Unit != "" && v.Currency != "" {
+		return "", false
+	}
+	switch v.Type {
+	case evaluator.ValueNumber:
+		return formatFloat(v.Number), true
+	case evaluator.ValuePercent:
+		return formatFloat(v.Number) + "%", true
+	case evaluator.ValueUnit:
+		return formatFloat(v.Number) + " " + v.Unit, true
+	case evaluator.ValueCurrency:
+		return formatFloat(v.Number) + " " + v.Currency, true
+	case evaluator.ValueString:
+		r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+		return `"` + r.Replace(v.Text) + `"`, true
+	case evaluator.ValueDate:
+		if h, m, s := v.Date.Clock(); h != 0 || m != 0 || s != 0 || v.Date.Nanosecond() != 0 {
+			return "", false
+		}
+		return v.Date.Format("2 January 2006"), true
+	}
+	return "", false
+}
+
+// formatFloat writes n with every digit needed to read it back exactly.
+func formatFloat(n float64) string {
+	return strconv.FormatFloat(n, 'g', -1, 64)
+}
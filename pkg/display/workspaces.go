@@ -0,0 +1,182 @@
+package display
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/andrewneudegg/calc/pkg/evaluator"
+	"github.com/andrewneudegg/calc/pkg/graph"
+)
+
+// defaultWorkspace is the name of the workspace a session starts in.
+const defaultWorkspace = "main"
+
+// workspace is the state of a named workspace while another is active: its
+// lines, variables, custom units and all that was worked out from them.
+// Settings are shared by every workspace.
+type workspace struct {
+	lines        map[int]*Line
+	nextID       int
+	env          *evaluator.Environment
+	eval         *evaluator.Evaluator
+	depGraph     *graph.Graph
+	autocomplete *AutocompleteEngine
+	budget       *budget
+	warnings     []string
+	shadowWarned map[string]bool
+}
+
+// workspaceName returns the name of the active workspace.
+func (r *REPL) workspaceName() string {
+	if r.workspace == "" {
+		return defaultWorkspace
+	}
+	return r.workspace
+}
+
+// prompt is the prompt for the next line: its number, after the name of the
+// active workspace once there is more than one or it is not the default.
+func (r *REPL) prompt() string {
+	if len(r.workspaces) == 0 && r.workspaceName() == defaultWorkspace {
+		return fmt.Sprintf("%d> ", r.nextID)
+	}
+	return fmt.Sprintf("%s %d> ", r.workspaceName(), r.nextID)
+}
+
+// stashWorkspace returns the active workspace's state, to be kept while
+// another is active.
+func (r *REPL) stashWorkspace() *workspace {
+	return &workspace{
+		lines:        r.lines,
+		nextID:       r.nextID,
+		env:          r.env,
+		eval:         r.eval,
+		depGraph:     r.depGraph,
+		autocomplete: r.autocomplete,
+		budget:       r.budget,
+		warnings:     r.warnings,
+		shadowWarned: r.shadowWarned,
+	}
+}
+
+// restoreWorkspace makes ws's state the active workspace's.
+func (r *REPL) restoreWorkspace(ws *workspace) {
+	r.lines = ws.lines
+	r.nextID = ws.nextID
+	r.env = ws.env
+	r.eval = ws.eval
+	r.depGraph = ws.depGraph
+	r.autocomplete = ws.autocomplete
+	r.budget = ws.budget
+	r.warnings = ws.warnings
+	r.shadowWarned = ws.shadowWarned
+}
+
+// workspaceCommand runs :workspace. "new" starts an empty workspace, with
+// only the custom units the session started with, and switches to it;
+// "switch" returns to one kept in memory; "delete" drops one that is not
+// active; and "list", or no arguments, lists them all.
+func (r *REPL) workspaceCommand(args []string) string {
+	if len(args) == 0 {
+		return r.listWorkspaces()
+	}
+	sub := strings.ToLower(args[0])
+	if sub == "list" {
+		return r.listWorkspaces()
+	}
+	if sub != "new" && sub != "switch" && sub != "delete" {
+		return fmt.Sprintf("unknown workspace command: %s (use new, switch, delete or list)", args[0])
+	}
+	if len(args) != 2 {
+		return fmt.Sprintf("usage: :workspace %s <name>", sub)
+	}
+	name := strings.ToLower(args[1])
+	if err := validWorkspaceName(name); err != nil {
+		return fmt.Sprintf("error: %s", err)
+	}
+	exists := name == r.workspaceName() || r.workspaces[name] != nil
+
+	switch sub {
+	case "new":
+		if exists {
+			return fmt.Sprintf("error: workspace %s already exists; use :workspace switch %s", name, name)
+		}
+		r.leaveWorkspace()
+		r.workspace = name
+		if err := r.resetWorkspace(r.startUnits); err != nil {
+			return fmt.Sprintf("error: %s", err)
+		}
+		return fmt.Sprintf("created workspace %s", name)
+	case "switch":
+		if name == r.workspaceName() {
+			return fmt.Sprintf("already in workspace %s", name)
+		}
+		ws := r.workspaces[name]
+		if ws == nil {
+			return fmt.Sprintf("error: no workspace named %s; :workspace new %s creates one", name, name)
+		}
+		r.leaveWorkspace()
+		delete(r.workspaces, name)
+		r.workspace = name
+		r.restoreWorkspace(ws)
+		return fmt.Sprintf("switched to workspace %s", name)
+	default:
+		if name == r.workspaceName() {
+			return fmt.Sprintf("error: cannot delete the active workspace %s; switch to another first", name)
+		}
+		if !exists {
+			return fmt.Sprintf("error: no workspace named %s", name)
+		}
+		delete(r.workspaces, name)
+		return fmt.Sprintf("deleted workspace %s", name)
+	}
+}
+
+// leaveWorkspace keeps the active workspace's state under its name.
+func (r *REPL) leaveWorkspace() {
+	if r.workspaces == nil {
+		r.workspaces = make(map[string]*workspace)
+	}
+	r.workspaces[r.workspaceName()] = r.stashWorkspace()
+}
+
+// listWorkspaces lists the workspaces by name with how many lines each
+// holds, marking the active one.
+func (r *REPL) listWorkspaces() string {
+	counts := map[string]int{r.workspaceName(): len(r.lines)}
+	for name, ws := range r.workspaces {
+		counts[name] = len(ws.lines)
+	}
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var out []string
+	for _, name := range names {
+		mark := " "
+		if name == r.workspaceName() {
+			mark = "*"
+		}
+		unit := "lines"
+		if counts[name] == 1 {
+			unit = "line"
+		}
+		out = append(out, fmt.Sprintf("%s %s (%d %s)", mark, name, counts[name], unit))
+	}
+	return strings.Join(out, "\n")
+}
+
+// validWorkspaceName checks that a workspace name, which the prompt shows,
+// is a single word of letters, digits, hyphens and underscores.
+func validWorkspaceName(name string) error {
+	for _, c := range name {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '-' && c != '_' {
+			return fmt.Errorf("workspace names are letters, digits, - and _, got %q", name)
+		}
+	}
+	return nil
+}
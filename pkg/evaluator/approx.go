@@ -0,0 +1,25 @@
+package evaluator
+
+import (
+	"slices"
+
+	"github.com/andrewneudegg/calc/pkg/parser"
+)
+
+// evalApprox evaluates "about 37 * 42" in full and marks the result to be
+// shown rounded. The items of a list, as from about 5 km in miles, feet,
+// are each marked.
+func (e *Evaluator) evalApprox(node *parser.ApproxExpr) Value {
+	val := e.Eval(node.Value)
+	if val.IsError() {
+		return val
+	}
+	val.Approx = true
+	if len(val.Items) > 0 {
+		val.Items = slices.Clone(val.Items)
+		for i := range val.Items {
+			val.Items[i].Approx = true
+		}
+	}
+	return val
+}
@@ -0,0 +1,42 @@
+package evaluator
+
+import (
+	"context"
+	"errors"
+
+	"github.com/andrewneudegg/calc/pkg/parser"
+)
+
+// The errors of an evaluation stopped through its context.
+const (
+	cancelledError = "evaluation cancelled"
+	timedOutError  = "evaluation timed out"
+)
+
+// EvalContext evaluates expr as Eval does, but stops once ctx is done: each
+// node checks ctx before it is evaluated, so even a long evaluation ends
+// promptly with an "evaluation cancelled" error, or "evaluation timed out"
+// when ctx's deadline passed. The environment is left as it was, apart from
+// any assignments that completed first.
+func (e *Evaluator) EvalContext(ctx context.Context, expr parser.Expr) Value {
+	prev := e.ctx
+	e.ctx = ctx
+	defer func() { e.ctx = prev }()
+	return e.Eval(expr)
+}
+
+// interrupted returns the error value for an evaluation whose context is
+// done, and false while it may carry on.
+func (e *Evaluator) interrupted() (Value, bool) {
+	if e.ctx == nil {
+		return Value{}, false
+	}
+	err := e.ctx.Err()
+	if err == nil {
+		return Value{}, false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return NewError(timedOutError), true
+	}
+	return NewError(cancelledError), true
+}
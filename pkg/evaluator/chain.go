@@ -0,0 +1,35 @@
+package evaluator
+
+import "slices"
+
+// conversionChain returns the values a conversion from one value to another
+// passed through, carrying on from's own chain when from is the end of one,
+// so 5 miles in km in m gives 5 miles, 8.05 km and 8,046.72 m.
+func conversionChain(from, to Value) []Value {
+	chain := from.Chain
+	if len(chain) == 0 || !chain[len(chain)-1].sameQuantity(from) {
+		chain = []Value{chainLink(from)}
+	}
+	return append(slices.Clip(chain), chainLink(to))
+}
+
+// chainLink is v as one link of a conversion chain, shown as it was without
+// its own chain or exchange rates.
+func chainLink(v Value) Value {
+	v.Chain = nil
+	v.Rates = nil
+	v.Explicit = true
+	return v
+}
+
+// sameQuantity reports whether v and w are the same amount of the same
+// thing, however they were arrived at.
+func (v Value) sameQuantity(w Value) bool {
+	return v.Type == w.Type && v.Number == w.Number && v.Unit == w.Unit && v.Currency == w.Currency
+}
+
+// ChainEnds reports whether v is the value its conversion chain ended at,
+// rather than a value computed from it, such as the chain's result doubled.
+func (v Value) ChainEnds() bool {
+	return len(v.Chain) >= 2 && v.Chain[len(v.Chain)-1].sameQuantity(v)
+}
@@ -0,0 +1,80 @@
+package evaluator
+
+import (
+	"math"
+
+	"github.com/andrewneudegg/calc/pkg/parser"
+)
+
+// evalCompare answers "is X more than Y" with yes or no and the figures
+// behind it, X converted to Y's unit or currency when they differ:
+//
+//	is 5 km more than 3 miles  →  yes, 5.00 km ≈ 3.11 miles > 3.00 miles
+//
+// "which is bigger: X or Y" gives the winner as written instead, so it can
+// be converted or assigned; a tie gives X. Values compare as min and max
+// compare them.
+func (e *Evaluator) evalCompare(node *parser.CompareExpr) Value {
+	left := e.Eval(node.Left)
+	if left.IsError() {
+		return left
+	}
+	right := e.Eval(node.Right)
+	if right.IsError() {
+		return right
+	}
+
+	l, err := e.comparable(left, right)
+	if err != nil {
+		return NewError(err.Error())
+	}
+	r, _ := e.comparable(right, right)
+	tie := math.Abs(l-r) <= 1e-12*math.Max(math.Abs(l), math.Abs(r))
+
+	if node.Which {
+		if tie || (l > r) == node.More {
+			return left
+		}
+		return right
+	}
+
+	sign, yes := "<", !node.More
+	switch {
+	case tie:
+		sign, yes = "=", false
+	case l > r:
+		sign, yes = ">", node.More
+	}
+
+	answer := "no, "
+	if yes {
+		answer = "yes, "
+	}
+	answer += e.render(left, -1)
+	var rates Value
+	if converted, ok := e.inTermsOf(left, right); ok {
+		answer += " ≈ " + e.render(converted, -1)
+		rates = converted
+	}
+	answer += " " + sign + " " + e.render(right, -1)
+
+	result := NewString(answer)
+	result.Rates = mergeRates(left.Rates, right.Rates, rates.Rates)
+	return result
+}
+
+// inTermsOf returns v in the unit or currency of ref, reporting false when
+// they already share one or one of them has none.
+func (e *Evaluator) inTermsOf(v, ref Value) (Value, bool) {
+	var target string
+	switch {
+	case v.Type == ValueCurrency && ref.Type == ValueCurrency && v.Currency != ref.Currency:
+		target = ref.Currency
+	case v.Type == ValueUnit && ref.Type == ValueUnit && v.Unit != ref.Unit:
+		target = ref.Unit
+	default:
+		return Value{}, false
+	}
+	converted := e.convertTo(v, target)
+	return converted, !converted.IsError()
+}
@@ -0,0 +1,57 @@
+package evaluator
+
+import (
+	"fmt"
+
+	"github.com/andrewneudegg/calc/pkg/parser"
+	"github.com/andrewneudegg/calc/pkg/units"
+)
+
+// evalComposite adds up an amount written in several units, as in 5 ft 10 in,
+// giving it in the first unit. The parts must measure the same thing, each
+// in a smaller unit than the one before, so that 5 kg 3 m or 6 in 5 ft are
+// errors rather than sums nobody meant.
+func (e *Evaluator) evalComposite(node *parser.CompositeExpr) Value {
+	var total, prev Value
+	for i, expr := range node.Parts {
+		part := e.Eval(expr)
+		if part.IsError() {
+			return part
+		}
+		if part.Type != ValueUnit || part.Per != 0 {
+			return NewError(fmt.Sprintf("%s cannot be part of an amount such as 5 ft 10 in", part.String()))
+		}
+		if i == 0 {
+			dim, err := e.env.units.GetDimension(part.Unit)
+			if err != nil {
+				return NewError(err.Error())
+			}
+			if dim == units.DimensionTemperature {
+				return NewError("a temperature cannot be written in parts")
+			}
+			total, prev = NewUnit(part.Number, part.Unit), part
+			continue
+		}
+
+		if !e.sameDimension(prev.Unit, part.Unit) {
+			return NewError(fmt.Sprintf("cannot write %s and %s as one amount; they measure different things", prev.Unit, part.Unit))
+		}
+		if ratio, err := e.env.units.Convert(1, part.Unit, prev.Unit); err != nil || ratio >= 1 {
+			return NewError(fmt.Sprintf("%s is not smaller than %s; write the larger unit first, as in 5 ft 10 in", part.Unit, prev.Unit))
+		}
+		n, err := e.env.units.Convert(part.Number, part.Unit, total.Unit)
+		if err != nil {
+			return NewError(err.Error())
+		}
+		total.Number += n
+		prev = part
+	}
+	return total
+}
+
+// sameDimension reports whether units a and b measure the same thing.
+func (e *Evaluator) sameDimension(a, b string) bool {
+	da, errA := e.env.units.GetDimension(a)
+	db, errB := e.env.units.GetDimension(b)
+	return errA == nil && errB == nil && da == db
+}
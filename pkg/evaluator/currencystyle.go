@@ -0,0 +1,47 @@
+package evaluator
+
+import (
+	"strings"
+
+	"github.com/andrewneudegg/calc/pkg/currency"
+)
+
+// An amount's Currency holds its symbol, or its code when it has none, and
+// CurrencyStyle how it was written. Results worked out only from amounts
+// written one way are shown that way; mixing the two leaves it to the
+// currency-display setting.
+
+// writtenCurrencyStyle is the style of a currency as written after or before
+// an amount: "£" is a symbol and "gbp" or "GBP" a code. Names such as
+// "dollars" state neither.
+func writtenCurrencyStyle(written string) CurrencyStyle {
+	written = strings.TrimSpace(written)
+	switch {
+	case currency.IsSymbol(written):
+		return CurrencyStyleSymbol
+	case currency.Code(written) == strings.ToUpper(written):
+		return CurrencyStyleCode
+	}
+	return CurrencyStyleDefault
+}
+
+// newCurrencyLike creates an amount of n in the currency of like, shown the
+// way like is.
+func newCurrencyLike(n float64, like Value) Value {
+	v := NewCurrency(n, like.Currency)
+	v.CurrencyStyle = like.CurrencyStyle
+	return v
+}
+
+// mixCurrencyStyles is the style of a result worked out from a and b: the
+// style of their amounts when they agree, and the default when they do not.
+// An operand that is not money, such as the 2 in 12 gbp * 2, has no say.
+func mixCurrencyStyles(a, b Value) CurrencyStyle {
+	switch {
+	case a.Type != ValueCurrency:
+		return b.CurrencyStyle
+	case b.Type != ValueCurrency, a.CurrencyStyle == b.CurrencyStyle:
+		return a.CurrencyStyle
+	}
+	return CurrencyStyleDefault
+}
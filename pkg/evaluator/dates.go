@@ -0,0 +1,39 @@
+package evaluator
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// dateTargets are the forms a date converts to with "in" or "as", in the
+// order an error lists them.
+var dateTargets = []string{"weekday", "iso", "unix", "week"}
+
+// convertDate reads a date in one of the dateTargets: "25/12/2025 in weekday"
+// gives Thursday, "today as iso" 2025-09-09, "today in unix" the seconds since
+// the epoch and "today in week" the ISO week number.
+func convertDate(d time.Time, target string) Value {
+	switch strings.ToLower(target) {
+	case "weekday":
+		return NewString(d.Weekday().String())
+	case "iso":
+		if d.Hour() == 0 && d.Minute() == 0 && d.Second() == 0 && d.Nanosecond() == 0 {
+			return NewString(d.Format("2006-01-02"))
+		}
+		return NewString(d.Format(time.RFC3339))
+	case "unix":
+		return wholeNumber(d.Unix())
+	case "week":
+		_, week := d.ISOWeek()
+		return wholeNumber(int64(week))
+	}
+	return NewError(fmt.Sprintf("cannot convert a date to %s; try %s", target, strings.Join(dateTargets, ", ")))
+}
+
+// wholeNumber returns n as a number shown without decimals.
+func wholeNumber(n int64) Value {
+	v := NewNumber(float64(n))
+	v.Whole = true
+	return v
+}
@@ -0,0 +1,119 @@
+package evaluator
+
+import (
+	"github.com/andrewneudegg/calc/pkg/decimal"
+)
+
+// SetDecimal turns decimal mode on or off. In decimal mode, +, -, * and /
+// on plain numbers and amounts of one currency are done in decimal, so
+// £0.10 * 3 - £0.30 is exactly zero. Switching converts the variables
+// already defined: on gives each number its shortest decimal form, off
+// keeps only the float.
+func (e *Environment) SetDecimal(on bool) {
+	if e.decimal == on {
+		return
+	}
+	e.decimal = on
+	for name, v := range e.variables {
+		if on {
+			e.variables[name] = withExact(v)
+		} else {
+			v.exact = nil
+			e.variables[name] = v
+		}
+	}
+}
+
+// Decimal reports whether decimal mode is on.
+func (e *Environment) Decimal() bool {
+	return e.decimal
+}
+
+// Exact returns the decimal value behind a number or currency amount
+// computed in decimal mode.
+func (v Value) Exact() (decimal.Decimal, bool) {
+	if v.exact == nil || v.exact.Float64() != v.Number {
+		return decimal.Decimal{}, false
+	}
+	return *v.exact, true
+}
+
+// exactOf returns v as a decimal: the value it was computed as when it still
+// matches Number, or else the shortest decimal that reads back as Number.
+func exactOf(v Value) (decimal.Decimal, bool) {
+	if d, ok := v.Exact(); ok {
+		return d, true
+	}
+	return decimal.FromFloat(v.Number)
+}
+
+// withExact attaches the decimal form of v's number, for values decimal
+// arithmetic applies to.
+func withExact(v Value) Value {
+	if v.Type != ValueNumber && v.Type != ValueCurrency {
+		return v
+	}
+	if d, ok := exactOf(v); ok {
+		v.exact = &d
+	}
+	return v
+}
+
+// setExact stores d as v's value.
+func setExact(v Value, d decimal.Decimal) Value {
+	v.Number = d.Float64()
+	v.exact = &d
+	return v
+}
+
+// decimalArithmetic applies op in decimal when both operands are plain
+// numbers or amounts in the same currency, reporting false for anything the
+// float arithmetic in evalBinary should handle instead.
+func (e *Evaluator) decimalArithmetic(left Value, op string, right Value) (Value, bool) {
+	if !decimalOperand(left) || !decimalOperand(right) {
+		return Value{}, false
+	}
+	if left.Type == ValueCurrency && right.Type == ValueCurrency && left.Currency != right.Currency {
+		return Value{}, false
+	}
+	l, ok := exactOf(left)
+	if !ok {
+		return Value{}, false
+	}
+	r, ok := exactOf(right)
+	if !ok {
+		return Value{}, false
+	}
+
+	// The float arithmetic decides the result's type, rates and errors
+	var result Value
+	if left.Type == ValueCurrency || right.Type == ValueCurrency {
+		result = e.evalCurrencyBinary(left, op, right)
+	} else {
+		result = NewNumber(0)
+	}
+	if result.IsError() {
+		return result, true
+	}
+
+	switch op {
+	case "+":
+		return setExact(result, l.Add(r)), true
+	case "-":
+		return setExact(result, l.Sub(r)), true
+	case "*":
+		return setExact(result, l.Mul(r)), true
+	case "/":
+		q, ok := l.Div(r)
+		if !ok {
+			return NewError("division by zero"), true
+		}
+		return setExact(result, q), true
+	}
+	return Value{}, false
+}
+
+// decimalOperand reports whether v takes part in decimal arithmetic.
+func decimalOperand(v Value) bool {
+	return v.Type == ValueNumber || v.Type == ValueCurrency
+}
@@ -0,0 +1,46 @@
+package evaluator
+
+import (
+	"fmt"
+
+	"github.com/andrewneudegg/calc/pkg/parser"
+)
+
+// evalDestructure assigns each value of a result with several parts, such
+// as the tip and total of 15% tip on £42.50 or the amounts of £100 in usd,
+// eur, to a variable of its own. The names must match the values one for
+// one; "_" discards a value. The result lists the values assigned, each
+// labelled with its variable's name.
+func (e *Evaluator) evalDestructure(node *parser.DestructureExpr) Value {
+	val := e.Eval(node.Value)
+	if val.IsError() {
+		return val
+	}
+
+	items := []Value{val}
+	if val.Type == ValueList {
+		items = val.Items
+	}
+	if len(items) != len(node.Names) {
+		return NewError(fmt.Sprintf("expected %d values, expression produced %d", len(node.Names), len(items)))
+	}
+	// Nothing is assigned if any part failed, as with a rate that is missing
+	for _, item := range items {
+		if item.IsError() {
+			return item
+		}
+	}
+
+	var assigned []Value
+	for i, name := range node.Names {
+		if name == "_" {
+			continue
+		}
+		item := items[i]
+		item.Label = ""
+		item = e.assign(name, item)
+		item.Label = name
+		assigned = append(assigned, item)
+	}
+	return NewList(assigned)
+}
@@ -1,40 +1,93 @@
 package evaluator
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/andrewneudegg/calc/pkg/constants"
 	"github.com/andrewneudegg/calc/pkg/currency"
 	"github.com/andrewneudegg/calc/pkg/parser"
+	"github.com/andrewneudegg/calc/pkg/rounding"
 	"github.com/andrewneudegg/calc/pkg/timezone"
 	"github.com/andrewneudegg/calc/pkg/units"
 )
 
 // Environment stores variables and state.
 type Environment struct {
-	variables           map[string]Value
+	variables           map[string]Value  // Keyed by lower-cased name; variables ignore case like units and currencies
+	names               map[string]string // Lower-cased name -> casing the variable was first defined with
 	units               *units.System
 	currency            *currency.System
 	timezone            *timezone.System
 	constants           *constants.System
 	historyFunc         func(offset int) (Value, error)   // Function to get previous results by relative offset
 	absoluteHistoryFunc func(lineID int) (Value, error)   // Function to get result by absolute line ID
+	nameFunc            func(name string) (Value, bool)   // Resolves names that are neither variables nor constants
+	formatFunc          func(v Value, places int) string  // Renders print placeholders; see SetFormatFunc
+	tagFunc             func(tag string) []Value          // Results of the lines filed under a tag; see SetTagFunc
+	clock               Clock                             // Source of the current time for now, today and weekdays
+	rounding            rounding.Mode                     // Rule used by round, roundto and roundcash
+	decimal             bool                              // Do number and currency arithmetic in decimal; see SetDecimal
+	strict              bool                              // Undefined variables are errors rather than 0; see SetStrict
+	monthOverflow       bool                              // 31 Jan + 1 month runs into March; see SetMonthOverflow
+	undefined           []string                          // Variables read as 0 since the last TakeUndefined
 }
 
+// Clock supplies the current time. Swapping it makes dates deterministic.
+type Clock interface {
+	Now() time.Time
+}
+
+// realClock reads the system time.
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+// FixedClock is a Clock that always reports the same instant.
+type FixedClock time.Time
+
+// Now returns the fixed instant.
+func (c FixedClock) Now() time.Time { return time.Time(c) }
+
 // NewEnvironment creates a new evaluation environment.
 func NewEnvironment() *Environment {
 	return &Environment{
 		variables: make(map[string]Value),
+		names:     make(map[string]string),
 		units:     units.NewSystem(),
 		currency:  currency.NewSystem(),
 		timezone:  timezone.NewSystem(),
 		constants: constants.NewSystem(),
+		clock:     realClock{},
+		rounding:  rounding.Default,
+		strict:    true,
 	}
 }
 
+// SetClock sets the clock used for now, today and other relative dates.
+// A nil clock restores the system time.
+func (e *Environment) SetClock(c Clock) {
+	if c == nil {
+		c = realClock{}
+	}
+	e.clock = c
+}
+
+// Now returns the current time according to the environment's clock.
+func (e *Environment) Now() time.Time {
+	return e.clock.Now()
+}
+
+// SetRounding sets the rule used by round, roundto and roundcash.
+func (e *Environment) SetRounding(m rounding.Mode) {
+	e.rounding = m
+}
+
 // SetHistoryFunc sets the function to retrieve previous results.
 func (e *Environment) SetHistoryFunc(f func(offset int) (Value, error)) {
 	e.historyFunc = f
@@ -45,15 +98,46 @@ func (e *Environment) SetAbsoluteHistoryFunc(f func(lineID int) (Value, error))
 	e.absoluteHistoryFunc = f
 }
 
-// SetVariable sets a variable in the environment.
+// SetNameFunc sets a function that gives values to names which are neither
+// variables nor constants, such as the REPL's budget words. Variables of the
+// same name take precedence.
+func (e *Environment) SetNameFunc(f func(name string) (Value, bool)) {
+	e.nameFunc = f
+}
+
+// SetVariable sets a variable in the environment. Names ignore case, so
+// "Rate" and "rate" are the same variable; the first casing is kept for display.
 func (e *Environment) SetVariable(name string, value Value) {
-	e.variables[name] = value
+	key := strings.ToLower(name)
+	if _, ok := e.names[key]; !ok {
+		e.names[key] = name
+	}
+	e.variables[key] = value
+}
+
+// GetVariable looks up a variable, ignoring case.
+func (e *Environment) GetVariable(name string) (Value, bool) {
+	val, ok := e.variables[strings.ToLower(name)]
+	return val, ok
+}
+
+// HasVariable reports whether name is a defined variable, ignoring case.
+func (e *Environment) HasVariable(name string) bool {
+	_, ok := e.variables[strings.ToLower(name)]
+	return ok
 }
 
-// GetVariableNames returns a list of all variable names in the environment.
+// VariableName returns the casing a variable was first defined with.
+func (e *Environment) VariableName(name string) (string, bool) {
+	display, ok := e.names[strings.ToLower(name)]
+	return display, ok
+}
+
+// GetVariableNames returns a list of all variable names in the environment,
+// in the casing each was first defined with.
 func (e *Environment) GetVariableNames() []string {
-	names := make([]string, 0, len(e.variables))
-	for name := range e.variables {
+	names := make([]string, 0, len(e.names))
+	for _, name := range e.names {
 		names = append(names, name)
 	}
 	return names
@@ -64,6 +148,11 @@ func (e *Environment) Units() *units.System {
 	return e.units
 }
 
+// Timezones returns the timezone system.
+func (e *Environment) Timezones() *timezone.System {
+	return e.timezone
+}
+
 // Currency returns the currency system.
 func (e *Environment) Currency() *currency.System {
 	return e.currency
@@ -74,6 +163,12 @@ func (e *Environment) Constants() *constants.System {
 	return e.constants
 }
 
+// IsUnit reports whether the lexer should read name as a unit: a unit,
+// including custom units, or a currency code or name known to the environment.
+func (e *Environment) IsUnit(name string) bool {
+	return e.units.IsUnit(name) || e.currency.IsCurrency(name)
+}
+
 // Eval evaluates an expression using this environment.
 func (e *Environment) Eval(expr parser.Expr) Value {
 	evaluator := New(e)
@@ -82,7 +177,10 @@ func (e *Environment) Eval(expr parser.Expr) Value {
 
 // Evaluator evaluates expressions.
 type Evaluator struct {
-	env *Environment
+	env    *Environment
+	trace  TraceFunc
+	traced map[parser.Expr]Value // values of traced nodes, for their parents' steps
+	ctx    context.Context       // Stops the evaluation when done; nil outside EvalContext
 }
 
 // New creates a new evaluator.
@@ -92,6 +190,17 @@ func New(env *Environment) *Evaluator {
 
 // Eval evaluates an expression and returns a value.
 func (e *Evaluator) Eval(expr parser.Expr) Value {
+	if v, ok := e.interrupted(); ok {
+		return v
+	}
+	v := e.eval(expr)
+	if e.trace != nil {
+		e.traceNode(expr, v)
+	}
+	return v
+}
+
+func (e *Evaluator) eval(expr parser.Expr) Value {
 	if expr == nil {
 		return NewError("nil expression")
 	}
@@ -112,12 +221,21 @@ func (e *Evaluator) Eval(expr parser.Expr) Value {
 	case *parser.AssignExpr:
 		return e.evalAssign(node)
 
+	case *parser.DestructureExpr:
+		return e.evalDestructure(node)
+
 	case *parser.UnitExpr:
 		return e.evalUnit(node)
 
+	case *parser.CompositeExpr:
+		return e.evalComposite(node)
+
 	case *parser.ConversionExpr:
 		return e.evalConversion(node)
 
+	case *parser.MultiConversionExpr:
+		return e.evalMultiConversion(node)
+
 	case *parser.CurrencyExpr:
 		return e.evalCurrency(node)
 
@@ -127,6 +245,9 @@ func (e *Evaluator) Eval(expr parser.Expr) Value {
 	case *parser.PercentOfExpr:
 		return e.evalPercentOf(node)
 
+	case *parser.NamedPercentOfExpr:
+		return e.evalNamedPercentOf(node)
+
 	case *parser.PercentChangeExpr:
 		return e.evalPercentChange(node)
 
@@ -140,9 +261,17 @@ func (e *Evaluator) Eval(expr parser.Expr) Value {
 		return NewString(node.Value)
 
 	case *parser.DateExpr:
+		if node.Relative {
+			now := e.env.Now()
+			today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+			return NewDate(today.AddDate(0, 0, node.DayOffset))
+		}
 		return NewDate(node.Date)
 
 	case *parser.TimeExpr:
+		if node.Now {
+			return NewDate(e.env.Now())
+		}
 		return NewDate(node.Time)
 
 	case *parser.DateArithmeticExpr:
@@ -154,6 +283,12 @@ func (e *Evaluator) Eval(expr parser.Expr) Value {
 	case *parser.WeekdayExpr:
 		return e.evalWeekday(node)
 
+	case *parser.NthWeekdayExpr:
+		return e.evalNthWeekday(node)
+
+	case *parser.CalendarDateExpr:
+		return e.evalCalendarDate(node)
+
 	case *parser.MonthExpr:
 		return e.evalMonth(node)
 
@@ -166,12 +301,33 @@ func (e *Evaluator) Eval(expr parser.Expr) Value {
 	case *parser.TimeConversionExpr:
 		return e.evalTimeConversion(node)
 
+	case *parser.DateAtTimeExpr:
+		return e.evalDateAtTime(node)
+
 	case *parser.RateExpr:
 		return e.evalRate(node)
 
 	case *parser.PrevExpr:
 		return e.evalPrev(node)
 
+	case *parser.SplitExpr:
+		return e.evalSplit(node)
+
+	case *parser.ShareExpr:
+		return e.evalShare(node)
+
+	case *parser.TipExpr:
+		return e.evalTip(node)
+
+	case *parser.CompareExpr:
+		return e.evalCompare(node)
+
+	case *parser.TagExpr:
+		return e.evalTag(node)
+
+	case *parser.ApproxExpr:
+		return e.evalApprox(node)
+
 	default:
 		return NewError(fmt.Sprintf("unknown expression type: %T", expr))
 	}
@@ -188,6 +344,32 @@ func (e *Evaluator) evalBinary(node *parser.BinaryExpr) Value {
 		return right
 	}
 
+	if left.Type == ValueList || right.Type == ValueList {
+		return NewError("cannot use a list of values in arithmetic")
+	}
+
+	// bps beside money or a percentage is basis points, not a data rate
+	left, right = basisPointOperands(left, right)
+
+	if e.env.decimal {
+		if exact, ok := e.decimalArithmetic(left, node.Operator, right); ok {
+			return exact
+		}
+	}
+
+	// Percentages go by the values' types, so a variable holding one, as
+	// in price + vat, works as the literal 20% does
+	if left.Type == ValuePercent || right.Type == ValuePercent {
+		if result, ok := percentArithmetic(left, node.Operator, right); ok {
+			return result
+		}
+	}
+
+	// Handle a date combined with a clock time, e.g. "now - 09:00"
+	if (left.Type == ValueDate && isClockTime(right)) || (isClockTime(left) && right.Type == ValueDate) {
+		return e.evalDateClockBinary(left, node.Operator, right)
+	}
+
 	// Handle date + unit or date - unit (date arithmetic)
 	if left.Type == ValueDate && right.Type == ValueUnit && (node.Operator == "+" || node.Operator == "-") {
 		// Extract offset value and unit
@@ -206,15 +388,18 @@ func (e *Evaluator) evalBinary(node *parser.BinaryExpr) Value {
 		case "week", "weeks", "w":
 			newDate = left.Date.AddDate(0, 0, int(offset*7))
 		case "month", "months", "mo":
-			newDate = left.Date.AddDate(0, int(offset), 0)
+			newDate = e.addMonths(left.Date, 0, int(offset))
 		case "year", "years", "y":
-			newDate = left.Date.AddDate(int(offset), 0, 0)
+			newDate = e.addMonths(left.Date, int(offset), 0)
 		case "hour", "hours", "h", "hr":
 			newDate = left.Date.Add(time.Duration(offset * float64(time.Hour)))
 		case "minute", "minutes", "min":
 			newDate = left.Date.Add(time.Duration(offset * float64(time.Minute)))
 		case "second", "seconds", "s", "sec":
 			newDate = left.Date.Add(time.Duration(offset * float64(time.Second)))
+		case "time":
+			// Elapsed time between two clock times, in hours
+			newDate = left.Date.Add(time.Duration(offset * float64(time.Hour)))
 		default:
 			return NewError(fmt.Sprintf("cannot add unit '%s' to date", unit))
 		}
@@ -222,11 +407,14 @@ func (e *Evaluator) evalBinary(node *parser.BinaryExpr) Value {
 		return NewDate(newDate)
 	}
 
-	// Handle date-date subtraction (returns days with unit)
+	// Handle date-date subtraction: whole calendar dates give days, anything
+	// carrying a time of day (such as now) keeps sub-day resolution in hours
 	if left.Type == ValueDate && right.Type == ValueDate && node.Operator == "-" {
 		duration := left.Date.Sub(right.Date)
-		days := duration.Hours() / 24.0
-		return NewUnit(days, "days")
+		if isMidnight(left.Date) && isMidnight(right.Date) {
+			return NewUnit(duration.Hours()/24.0, "days")
+		}
+		return NewUnit(duration.Hours(), "hours")
 	}
 
 	// Handle currency operations
@@ -239,17 +427,6 @@ func (e *Evaluator) evalBinary(node *parser.BinaryExpr) Value {
 		return e.evalUnitBinary(left, node.Operator, right)
 	}
 
-	// Handle percentage operations
-	if right.Type == ValuePercent && node.Operator == "+" {
-		// e.g., "30 + 20%" = 30 + (30 * 0.20)
-		return NewNumber(left.Number + (left.Number * right.Number / 100))
-	}
-
-	if right.Type == ValuePercent && node.Operator == "-" {
-		// e.g., "30 - 20%" = 30 - (30 * 0.20)
-		return NewNumber(left.Number - (left.Number * right.Number / 100))
-	}
-
 	// Standard numeric operations
 	switch node.Operator {
 	case "+":
@@ -276,6 +453,9 @@ func (e *Evaluator) evalUnary(node *parser.UnaryExpr) Value {
 
 	switch node.Operator {
 	case "-":
+		if d, ok := operand.Exact(); ok {
+			return setExact(operand, d.Neg())
+		}
 		operand.Number = -operand.Number
 		return operand
 	default:
@@ -284,17 +464,28 @@ func (e *Evaluator) evalUnary(node *parser.UnaryExpr) Value {
 }
 
 func (e *Evaluator) evalIdent(node *parser.IdentExpr) Value {
-	val, ok := e.env.variables[node.Name]
+	val, ok := e.env.GetVariable(node.Name)
 	if !ok {
 		// Check if it's a physical constant
 		if e.env.constants != nil && e.env.constants.IsConstant(node.Name) {
 			c, err := e.env.constants.GetConstant(node.Name)
+			if err == nil && c.Unit == "" {
+				return NewNumber(c.Value)
+			}
 			if err == nil {
 				// Return constant as a unit value
 				return NewUnit(c.Value, c.Unit)
 			}
 		}
-		return NewError(fmt.Sprintf("undefined variable: %s", node.Name))
+		if e.env.nameFunc != nil {
+			if v, ok := e.env.nameFunc(node.Name); ok {
+				return v
+			}
+		}
+		if node.Fallback != nil {
+			return e.Eval(node.Fallback)
+		}
+		return e.undefinedVariable(node.Name)
 	}
 	return val
 }
@@ -304,8 +495,22 @@ func (e *Evaluator) evalAssign(node *parser.AssignExpr) Value {
 	if val.IsError() {
 		return val
 	}
+	return e.assign(node.Name, val)
+}
+
+// assign sets the variable name to val, returning val with any warning the
+// assignment gives.
+func (e *Evaluator) assign(name string, val Value) Value {
+	// The warning belongs to this line; later references to the variable stay quiet.
+	stored := val.Precise()
+	stored.Warning = ""
 
-	e.env.variables[node.Name] = val
+	// Names ignore case, so a differently cased assignment replaces the existing variable
+	if existing, ok := e.env.VariableName(name); ok && existing != name && val.Warning == "" {
+		val.Warning = fmt.Sprintf("%s replaces %s; variable names ignore case", name, existing)
+	}
+
+	e.env.SetVariable(name, stored)
 	return val
 }
 
@@ -315,7 +520,28 @@ func (e *Evaluator) evalUnit(node *parser.UnitExpr) Value {
 		return val
 	}
 
-	return NewUnit(val.Number, node.Unit)
+	// A rate quoted per a quantity, such as £1.89 per 100g, is held per single
+	// unit so that it converts and multiplies like any other rate
+	if node.Per != 0 {
+		rate := NewUnit(val.Number/node.Per, node.Unit)
+		rate.Per = node.Per
+		return rate
+	}
+	result := NewUnit(val.Number, node.Unit)
+	if node.Ingredient != "" {
+		if _, ok := e.env.units.Ingredient(node.Ingredient); !ok {
+			return NewError(e.env.units.UnknownIngredientError(node.Ingredient).Error())
+		}
+		dim, err := e.env.units.GetDimension(node.Unit)
+		if err != nil {
+			return NewError(err.Error())
+		}
+		if dim != units.DimensionMass && dim != units.DimensionVolume {
+			return NewError(fmt.Sprintf("%s is measured by mass or volume, not %s", node.Ingredient, dim))
+		}
+		result.Ingredient = node.Ingredient
+	}
+	return result
 }
 
 func (e *Evaluator) evalConversion(node *parser.ConversionExpr) Value {
@@ -323,22 +549,109 @@ func (e *Evaluator) evalConversion(node *parser.ConversionExpr) Value {
 	if val.IsError() {
 		return val
 	}
+	if node.On != nil {
+		return e.convertOn(val, node.ToUnit, node.On)
+	}
+
+	converted := e.convertExplicit(val, node.ToUnit)
+	converted.Explicit = true
+	if !converted.IsError() && converted.Type != ValueList {
+		converted.Chain = conversionChain(val, converted)
+	}
+	return converted
+}
+
+// evalMultiConversion converts a value to each target in turn. A failing target
+// yields an error item in the list without hiding the successful conversions.
+func (e *Evaluator) evalMultiConversion(node *parser.MultiConversionExpr) Value {
+	val := e.Eval(node.Value)
+	if val.IsError() {
+		return val
+	}
+
+	items := make([]Value, len(node.ToUnits))
+	for i, toUnit := range node.ToUnits {
+		items[i] = e.convertExplicit(val, toUnit)
+		items[i].Explicit = true
+	}
+	return NewList(items)
+}
+
+// convertValue converts an evaluated value to the given target unit or currency.
+func (e *Evaluator) convertValue(val Value, toUnit string) Value {
+	converted := e.convertTo(val, toUnit)
+	e.recordConversion(val, converted)
+	return converted
+}
+
+// convertExplicit is convertValue for a conversion asked for by name with
+// "in". Only these may take torque to energy or back, or read nm after a
+// torque as Nm, since arithmetic must not relabel one quantity as another.
+func (e *Evaluator) convertExplicit(val Value, toUnit string) Value {
+	// Each part of a result with several, such as a tip and its total,
+	// converts on its own
+	if val.Type == ValueList {
+		items := make([]Value, len(val.Items))
+		for i, item := range val.Items {
+			items[i] = e.convertExplicit(item, toUnit)
+			if items[i].IsError() {
+				return items[i]
+			}
+			items[i].Label = item.Label
+			items[i].Explicit = true
+		}
+		return NewList(items)
+	}
+	if val.Type == ValueUnit && val.Ingredient == "" && !isClockTime(val) {
+		if n, unit, ok := e.env.units.ConvertAcross(val.Number, val.Unit, toUnit); ok {
+			converted := NewUnit(n, unit)
+			e.recordConversion(val, converted)
+			return converted
+		}
+	}
+	return e.convertValue(val, toUnit)
+}
+
+// convertNumber returns the number val has in toUnit, following the same
+// rules as "in" whether val's unit is simple or a rate such as $/day.
+func (e *Evaluator) convertNumber(val Value, toUnit string) (float64, error) {
+	converted := e.convertTo(val, toUnit)
+	if converted.IsError() {
+		return 0, errors.New(converted.Error)
+	}
+	return converted.Number, nil
+}
+
+// convertTo does the work of convertValue.
+func (e *Evaluator) convertTo(val Value, toUnit string) Value {
+	if val.Type == ValueDate {
+		return convertDate(val.Date, toUnit)
+	}
+	if converted, ok := convertPercent(val, toUnit); ok {
+		return converted
+	}
 
 	// Handle currency conversion
 	if val.Type == ValueCurrency {
-		result, err := e.env.currency.Convert(val.Number, val.Currency, node.ToUnit)
+		result, err := e.env.currency.Convert(val.Number, val.Currency, toUnit)
 		if err != nil {
 			return NewError(err.Error())
 		}
-		return NewCurrency(result, e.env.currency.GetSymbol(node.ToUnit))
+		converted := NewCurrency(result, e.env.currency.GetSymbol(toUnit))
+		converted.CurrencyStyle = val.CurrencyStyle
+		converted.Rates = val.Rates
+		if rate, err := e.env.currency.Rate(val.Currency, toUnit); err == nil && rate.From != rate.To {
+			converted.Rates = mergeRates(val.Rates, []currency.Rate{rate})
+		}
+		return converted
 	}
 
 	// Handle unit conversion
 	if val.Type == ValueUnit {
 		// Special case: currency/time rates (e.g., $/day) to other currency/time (e.g., gbp/month)
-		if units.IsCompoundUnit(val.Unit) || units.IsCompoundUnit(node.ToUnit) {
+		if units.IsCompoundUnit(val.Unit) || units.IsCompoundUnit(toUnit) {
 			fromParts := strings.Split(val.Unit, "/")
-			toParts := strings.Split(node.ToUnit, "/")
+			toParts := strings.Split(toUnit, "/")
 
 			// Handle currency rate: currency in numerator and time in denominator
 			if len(fromParts) == 2 && e.env.currency.IsCurrency(strings.TrimSpace(fromParts[0])) {
@@ -366,42 +679,69 @@ func (e *Evaluator) evalConversion(node *parser.ConversionExpr) Value {
 					}
 
 					// Return total amount per target period as a currency value (e.g., monthly amount)
-					return NewCurrency(converted, e.env.currency.GetSymbol(toCur))
+					amount := NewCurrency(converted, e.env.currency.GetSymbol(toCur))
+					if rate, err := e.env.currency.Rate(fromCur, toCur); err == nil && rate.From != rate.To {
+						amount.Rates = []currency.Rate{rate}
+					}
+					return amount
 				}
 
 				// If target is a different time unit but same currency rate
 				if len(toParts) == 2 && !e.env.currency.IsCurrency(strings.TrimSpace(toParts[0])) {
 					// Non-currency compound target: delegate to unit conversion if possible
-					result, err := e.env.units.ConvertCompoundUnit(val.Number, val.Unit, node.ToUnit)
+					result, err := e.env.units.ConvertCompoundUnit(val.Number, val.Unit, toUnit)
 					if err != nil {
 						return NewError(err.Error())
 					}
-					return NewUnit(result, node.ToUnit)
+					return NewUnit(result, toUnit)
 				}
 			}
 
 			// Generic compound unit conversions (non-currency)
-			result, err := e.env.units.ConvertCompoundUnit(val.Number, val.Unit, node.ToUnit)
+			result, err := e.env.units.ConvertCompoundUnit(val.Number, val.Unit, toUnit)
 			if err != nil {
 				return NewError(err.Error())
 			}
-			return NewUnit(result, node.ToUnit)
+			return NewUnit(result, toUnit)
 		}
 
-		// Regular simple unit conversion
-		result, err := e.env.units.Convert(val.Number, val.Unit, node.ToUnit)
+		// Regular simple unit conversion, going between mass and volume by
+		// the density of an ingredient such as flour
+		var result float64
+		var err error
+		if val.Ingredient != "" {
+			result, err = e.env.units.ConvertIngredient(val.Number, val.Unit, toUnit, val.Ingredient)
+		} else {
+			result, err = e.env.units.Convert(val.Number, val.Unit, toUnit)
+		}
 		if err != nil {
 			return NewError(err.Error())
 		}
-		return NewUnit(result, node.ToUnit)
+		converted := NewUnit(result, toUnit)
+		converted.Ingredient = val.Ingredient
+		if isClockTime(val) && toUnit != "time" {
+			converted.Warning = "clock time treated as a duration since midnight; use 'duration of' or subtract two times to be explicit"
+		}
+		return converted
+	}
+
+	// A plain number has nothing to convert from. 12 in cm reads this way
+	// too, so a length target points to the inches reading.
+	if val.Type == ValueNumber {
+		n := strconv.FormatFloat(val.Number, 'f', -1, 64)
+		msg := fmt.Sprintf("%s has no unit to convert to %s", n, toUnit)
+		if dim, err := e.env.units.GetDimension(toUnit); err == nil && dim == units.DimensionLength {
+			msg += fmt.Sprintf("; for inches, write %s in in %s", n, toUnit)
+		}
+		return NewError(msg)
 	}
 
 	// Try converting a plain number with a unit
-	result, err := e.env.units.Convert(val.Number, "unknown", node.ToUnit)
+	result, err := e.env.units.Convert(val.Number, "unknown", toUnit)
 	if err != nil {
 		return NewError(err.Error())
 	}
-	return NewUnit(result, node.ToUnit)
+	return NewUnit(result, toUnit)
 }
 
 func (e *Evaluator) evalCurrency(node *parser.CurrencyExpr) Value {
@@ -410,9 +750,11 @@ func (e *Evaluator) evalCurrency(node *parser.CurrencyExpr) Value {
 		return val
 	}
 
-	// Normalize the currency code to a symbol for display
+	// Normalize the currency code to a symbol, remembering which was written
 	symbol := e.env.currency.GetSymbol(node.Currency)
-	return NewCurrency(val.Number, symbol)
+	amount := NewCurrency(val.Number, symbol)
+	amount.CurrencyStyle = writtenCurrencyStyle(node.Currency)
+	return amount
 }
 
 func (e *Evaluator) evalPercent(node *parser.PercentExpr) Value {
@@ -421,7 +763,7 @@ func (e *Evaluator) evalPercent(node *parser.PercentExpr) Value {
 		return val
 	}
 
-	return NewPercent(val.Number)
+	return newScaledPercent(val.Number, node.Unit)
 }
 
 func (e *Evaluator) evalPercentOf(node *parser.PercentOfExpr) Value {
@@ -434,13 +776,17 @@ func (e *Evaluator) evalPercentOf(node *parser.PercentOfExpr) Value {
 	if of.IsError() {
 		return of
 	}
+	return percentOf(percent.Number, of)
+}
 
-	result := of.Number * (percent.Number / 100)
+// percentOf returns percent per cent of the value of, in its unit or currency.
+func percentOf(percent float64, of Value) Value {
+	result := of.Number * (percent / 100)
 
 	// Preserve the type of the "of" value
 	switch of.Type {
 	case ValueCurrency:
-		return NewCurrency(result, of.Currency)
+		return newCurrencyLike(result, of)
 	case ValueUnit:
 		return NewUnit(result, of.Unit)
 	default:
@@ -448,28 +794,77 @@ func (e *Evaluator) evalPercentOf(node *parser.PercentOfExpr) Value {
 	}
 }
 
+// evalPercentChange handles "increase X by Y" and "decrease X by Y". A percentage
+// scales the base; a plain number or a typed amount in a compatible unit or
+// currency is added or subtracted after converting to the base's unit.
 func (e *Evaluator) evalPercentChange(node *parser.PercentChangeExpr) Value {
 	base := e.Eval(node.Base)
 	if base.IsError() {
 		return base
 	}
 
-	percent := e.Eval(node.Percent)
-	if percent.IsError() {
-		return percent
+	by := e.Eval(node.Percent)
+	if by.IsError() {
+		return by
+	}
+	if base.Type == ValueCurrency {
+		by = asBasisPoints(by)
+	}
+
+	verb, sign := "increase", 1.0
+	if !node.Increase {
+		verb, sign = "decrease", -1.0
 	}
 
 	var result float64
-	if node.Increase {
-		result = base.Number * (1 + percent.Number/100)
-	} else {
-		result = base.Number * (1 - percent.Number/100)
+	switch by.Type {
+	case ValuePercent:
+		result = base.Number * (1 + sign*by.Number/100)
+
+	case ValueNumber:
+		result = base.Number + sign*by.Number
+
+	case ValueCurrency:
+		if base.Type != ValueCurrency {
+			return NewError(fmt.Sprintf("cannot %s %s by a currency amount", verb, describeValueKind(base)))
+		}
+		amount, err := e.env.currency.Convert(by.Number, by.Currency, base.Currency)
+		if err != nil {
+			return NewError(err.Error())
+		}
+		if by.Currency != base.Currency {
+			e.recordConversion(by, newCurrencyLike(amount, base))
+		}
+		result = base.Number + sign*amount
+		base.CurrencyStyle = mixCurrencyStyles(base, by)
+
+	case ValueUnit:
+		if base.Type != ValueUnit {
+			return NewError(fmt.Sprintf("cannot %s %s by %s", verb, describeValueKind(base), by.Unit))
+		}
+		amount, err := e.env.units.Convert(by.Number, by.Unit, base.Unit)
+		var incompatible *units.ErrIncompatibleDimensions
+		var unknown *units.ErrUnknownUnit
+		if errors.As(err, &incompatible) || errors.As(err, &unknown) {
+			return NewError(fmt.Sprintf("cannot %s %s by %s: incompatible units", verb, base.Unit, by.Unit))
+		}
+		if err != nil {
+			// Such as a negative speed on the Beaufort scale
+			return NewError(err.Error())
+		}
+		if by.Unit != base.Unit {
+			e.recordConversion(by, NewUnit(amount, base.Unit))
+		}
+		result = base.Number + sign*amount
+
+	default:
+		return NewError(fmt.Sprintf("cannot %s by %s", verb, describeValueKind(by)))
 	}
 
 	// Preserve the type
 	switch base.Type {
 	case ValueCurrency:
-		return NewCurrency(result, base.Currency)
+		return newCurrencyLike(result, base)
 	case ValueUnit:
 		return NewUnit(result, base.Unit)
 	default:
@@ -477,6 +872,53 @@ func (e *Evaluator) evalPercentChange(node *parser.PercentChangeExpr) Value {
 	}
 }
 
+// evalTip works out a percentage tip on a bill and returns the tip and the
+// total with it. A currency tip is rounded to the minor unit first, so the
+// two lines add up as they would on a receipt.
+func (e *Evaluator) evalTip(node *parser.TipExpr) Value {
+	percent := e.Eval(node.Percent)
+	if percent.IsError() {
+		return percent
+	}
+	base := e.Eval(node.Base)
+	if base.IsError() {
+		return base
+	}
+
+	var tip, total Value
+	switch base.Type {
+	case ValueCurrency:
+		amount := e.env.rounding.Places(base.Number*percent.Number/100, currency.MinorUnits(base.Currency))
+		tip = newCurrencyLike(amount, base)
+		total = newCurrencyLike(base.Number+amount, base)
+	case ValueNumber:
+		tip = NewNumber(base.Number * percent.Number / 100)
+		total = NewNumber(base.Number + tip.Number)
+	default:
+		return NewError(fmt.Sprintf("can only tip on an amount of money, not %s", describeValueKind(base)))
+	}
+	tip.Label, total.Label = "tip", "total"
+	return NewList([]Value{tip, total})
+}
+
+// describeValueKind names a value's kind for error messages.
+func describeValueKind(v Value) string {
+	switch v.Type {
+	case ValueNumber:
+		return "a plain number"
+	case ValueCurrency:
+		return "a currency amount"
+	case ValueUnit:
+		return v.Unit
+	case ValuePercent:
+		return "a percentage"
+	case ValueDate:
+		return "a date"
+	default:
+		return "this value"
+	}
+}
+
 func (e *Evaluator) evalWhatPercent(node *parser.WhatPercentExpr) Value {
 	part := e.Eval(node.Part)
 	if part.IsError() {
@@ -508,12 +950,24 @@ func (e *Evaluator) evalFunctionCall(node *parser.FunctionCallExpr) Value {
 		return e.evalMax(node.Args)
 	case "print":
 		return e.evalPrint(node.Args)
+	case "round":
+		return e.evalRound(node.Args)
+	case "roundto":
+		return e.evalRoundTo(node.Args)
+	case "roundcash":
+		return e.evalRoundCash(node.Args)
+	case "trunc":
+		return e.evalTrunc(node.Args)
 	default:
+		if f, ok := lookupFunction(strings.ToLower(node.Name)); ok {
+			return e.evalRegistered(f, node.Args)
+		}
 		return NewError(fmt.Sprintf("unknown function: %s", node.Name))
 	}
 }
 
-// evalPrint returns a string after interpolating {var} placeholders using current variables.
+// evalPrint returns a string after interpolating {var} placeholders using
+// current variables; see interpolate.
 // It does not produce side effects; the REPL will print the returned string value.
 func (e *Evaluator) evalPrint(args []parser.Expr) Value {
 	if len(args) != 1 {
@@ -526,53 +980,19 @@ func (e *Evaluator) evalPrint(args []parser.Expr) Value {
 	if val.Type != ValueString {
 		return NewError("print expects a string literal")
 	}
-	s := val.Text
-	// Find {identifier} placeholders and replace
-	// Simple single-pass replacement; does not support nested braces
-	var out strings.Builder
-	for i := 0; i < len(s); {
-		if s[i] == '{' {
-			// find closing brace
-			j := i + 1
-			for j < len(s) && s[j] != '}' {
-				j++
-			}
-			if j >= len(s) {
-				// unmatched '{' - leave as-is
-				out.WriteString(s[i:])
-				break
-			}
-			name := strings.TrimSpace(s[i+1 : j])
-			if name == "" {
-				out.WriteString(s[i : j+1])
-				i = j + 1
-				continue
-			}
-			// Look up variable
-			v, ok := e.env.variables[name]
-			if !ok {
-				return NewError(fmt.Sprintf("undefined variable: %s", name))
-			}
-			out.WriteString(v.String())
-			i = j + 1
-		} else {
-			out.WriteByte(s[i])
-			i++
-		}
-	}
-	return NewString(out.String())
+	return e.interpolate(val.Text)
 }
 
 func (e *Evaluator) evalSum(args []parser.Expr) Value {
-	var sum float64
+	var sum kahanSum
 	for _, arg := range args {
 		val := e.Eval(arg)
 		if val.IsError() {
 			return val
 		}
-		sum += val.Number
+		sum.add(val.Number)
 	}
-	return NewNumber(sum)
+	return NewNumber(sum.total())
 }
 
 func (e *Evaluator) evalAverage(args []parser.Expr) Value {
@@ -589,53 +1009,94 @@ func (e *Evaluator) evalAverage(args []parser.Expr) Value {
 }
 
 func (e *Evaluator) evalMin(args []parser.Expr) Value {
-	if len(args) == 0 {
-		return NewError("min requires at least one argument")
-	}
+	return e.evalExtreme("min", args, func(a, b float64) bool { return a < b })
+}
+
+func (e *Evaluator) evalMax(args []parser.Expr) Value {
+	return e.evalExtreme("max", args, func(a, b float64) bool { return a > b })
+}
 
-	// Evaluate first argument to initialize
-	first := e.Eval(args[0])
-	if first.IsError() {
-		return first
+// evalExtreme returns the argument that beats all the others under better,
+// for min and max. Arguments are compared in the unit or currency of the
+// first one that has one, so max(3 kg, 5 lb, 2000 g) is 3 kg, and the winner
+// comes back as written so that it can be converted or assigned. A single
+// list argument, such as a conversion to several units, is compared item by
+// item. Ties keep the earlier argument.
+func (e *Evaluator) evalExtreme(name string, args []parser.Expr, better func(a, b float64) bool) Value {
+	if len(args) == 0 {
+		return NewError(fmt.Sprintf("%s requires at least one argument", name))
 	}
-	minVal := first.Number
 
-	for i := 1; i < len(args); i++ {
-		v := e.Eval(args[i])
+	vals := make([]Value, 0, len(args))
+	for _, arg := range args {
+		v := e.Eval(arg)
 		if v.IsError() {
 			return v
 		}
-		if v.Number < minVal {
-			minVal = v.Number
+		vals = append(vals, v)
+	}
+	if len(vals) == 1 && vals[0].Type == ValueList {
+		vals = vals[0].Items
+		if len(vals) == 0 {
+			return NewError(fmt.Sprintf("%s requires at least one argument", name))
 		}
 	}
 
-	return NewNumber(minVal)
-}
-
-func (e *Evaluator) evalMax(args []parser.Expr) Value {
-	if len(args) == 0 {
-		return NewError("max requires at least one argument")
+	ref := vals[0]
+	for _, v := range vals {
+		if v.Type != ValueNumber {
+			ref = v
+			break
+		}
 	}
 
-	// Evaluate first argument to initialize
-	first := e.Eval(args[0])
-	if first.IsError() {
-		return first
+	var best Value
+	var bestKey float64
+	for i, v := range vals {
+		key, err := e.comparable(v, ref)
+		if err != nil {
+			return NewError(fmt.Sprintf("%s: %s", name, err))
+		}
+		tie := math.Abs(key-bestKey) <= 1e-12*math.Max(math.Abs(key), math.Abs(bestKey))
+		if i == 0 || (!tie && better(key, bestKey)) {
+			best, bestKey = v, key
+		}
 	}
-	maxVal := first.Number
+	return best
+}
 
-	for i := 1; i < len(args); i++ {
-		v := e.Eval(args[i])
-		if v.IsError() {
-			return v
+// comparable returns v as a number that can be ordered against ref: v's
+// magnitude in ref's unit or currency, or its time for dates. Plain numbers
+// compare as they are against numbers, units, currencies and percentages.
+func (e *Evaluator) comparable(v, ref Value) (float64, error) {
+	mismatch := fmt.Errorf("cannot compare %s with %s", v.String(), ref.String())
+	switch {
+	case v.Type == ValueString:
+		return 0, fmt.Errorf("cannot compare text %q", v.Text)
+	case v.Type == ValueList:
+		return 0, fmt.Errorf("cannot compare a list of values")
+	case v.Type == ValueDate && ref.Type == ValueDate:
+		return float64(v.Date.UnixNano()), nil
+	case v.Type == ValueDate || ref.Type == ValueDate:
+		return 0, mismatch
+	case v.Type == ValueNumber || ref.Type == ValueNumber:
+		return v.Number, nil
+	case v.Type != ref.Type:
+		return 0, mismatch
+	case v.Type == ValueCurrency && v.Currency != ref.Currency:
+		converted := e.convertTo(v, ref.Currency)
+		if converted.IsError() {
+			return 0, mismatch
 		}
-		if v.Number > maxVal {
-			maxVal = v.Number
+		return converted.Number, nil
+	case v.Type == ValueUnit && v.Unit != ref.Unit:
+		converted := e.convertTo(v, ref.Unit)
+		if converted.IsError() {
+			return 0, mismatch
 		}
+		return converted.Number, nil
 	}
-
-	return NewNumber(maxVal)
+	return v.Number, nil
 }
 
 func (e *Evaluator) evalDateArithmetic(node *parser.DateArithmeticExpr) Value {
@@ -663,14 +1124,14 @@ func (e *Evaluator) evalDateArithmetic(node *parser.DateArithmeticExpr) Value {
 	case "week", "weeks":
 		result = base.Date.AddDate(0, 0, offsetVal*7)
 	case "month", "months":
-		result = base.Date.AddDate(0, offsetVal, 0)
+		result = e.addMonths(base.Date, 0, offsetVal)
 	case "year", "years":
-		result = base.Date.AddDate(offsetVal, 0, 0)
-	case "hour", "hours", "h", "hr":
+		result = e.addMonths(base.Date, offsetVal, 0)
+	case "hour", "hours", "h", "hr", "hrs":
 		result = base.Date.Add(time.Duration(offsetVal) * time.Hour)
-	case "minute", "minutes", "min":
+	case "minute", "minutes", "min", "mins":
 		result = base.Date.Add(time.Duration(offsetVal) * time.Minute)
-	case "second", "seconds", "s", "sec":
+	case "second", "seconds", "s", "sec", "secs":
 		result = base.Date.Add(time.Duration(offsetVal) * time.Second)
 	default:
 		return NewError(fmt.Sprintf("unknown time unit: %s", node.Unit))
@@ -686,6 +1147,10 @@ func (e *Evaluator) evalFuzzy(node *parser.FuzzyExpr) Value {
 	}
 
 	pattern := strings.ToLower(node.Pattern)
+	if pattern == "duration" {
+		return e.evalDurationOf(val)
+	}
+
 	var result float64
 
 	switch pattern {
@@ -702,7 +1167,7 @@ func (e *Evaluator) evalFuzzy(node *parser.FuzzyExpr) Value {
 	// Preserve type
 	switch val.Type {
 	case ValueCurrency:
-		return NewCurrency(result, val.Currency)
+		return newCurrencyLike(result, val)
 	case ValueUnit:
 		return NewUnit(result, val.Unit)
 	default:
@@ -710,8 +1175,160 @@ func (e *Evaluator) evalFuzzy(node *parser.FuzzyExpr) Value {
 	}
 }
 
+// evalDurationOf reads a clock time as the duration since midnight, so
+// "duration of 14:00" is 14 hours. Durations pass through unchanged.
+func (e *Evaluator) evalDurationOf(val Value) Value {
+	if val.Type == ValueUnit {
+		if val.Unit == "time" {
+			return NewUnit(val.Number, "hours")
+		}
+		if dim, err := e.env.units.GetDimension(val.Unit); err == nil && dim == units.DimensionTime {
+			return val
+		}
+	}
+	return NewError("duration of expects a clock time such as 14:00")
+}
+
+// evalSplit divides a value into shares proportional to node.Parts.
+// Currency amounts are split to the minor unit so the shares add back up exactly.
+func (e *Evaluator) evalSplit(node *parser.SplitExpr) Value {
+	val := e.Eval(node.Value)
+	if val.IsError() {
+		return val
+	}
+
+	var total float64
+	for _, part := range node.Parts {
+		if part < 0 {
+			return NewError("split ratio parts must not be negative")
+		}
+		total += part
+	}
+	if total == 0 {
+		return NewError("split ratio must have at least one non-zero part")
+	}
+
+	items := make([]Value, len(node.Parts))
+	switch val.Type {
+	case ValueCurrency:
+		decimals := currency.MinorUnits(val.Currency)
+		shares := allocateExact(val.Number, node.Parts, decimals)
+		for i, share := range shares {
+			items[i] = newCurrencyLike(share, val)
+		}
+	case ValueUnit:
+		for i, part := range node.Parts {
+			items[i] = NewUnit(val.Number*part/total, val.Unit)
+		}
+	case ValueNumber:
+		for i, part := range node.Parts {
+			items[i] = NewNumber(val.Number * part / total)
+		}
+	default:
+		return NewError("split requires a number, unit, or currency value")
+	}
+
+	return NewList(items)
+}
+
+// evalShare divides a value equally between a number of people or items. A
+// count unit on the number is dropped, so "£86.40 between 4 people" is £21.60
+// rather than a rate per person. When currency shares rounded to the minor
+// unit do not add back up to the amount, the result carries a warning.
+func (e *Evaluator) evalShare(node *parser.ShareExpr) Value {
+	count := e.Eval(node.Count)
+	if count.IsError() {
+		return count
+	}
+	if count.Type == ValueUnit && e.isCountUnit(count.Unit) {
+		count = NewNumber(count.Number)
+	}
+	if count.Type != ValueNumber || count.Number <= 0 {
+		return NewError("can only share between a positive number of people or items")
+	}
+
+	share := e.evalBinary(&parser.BinaryExpr{
+		Left:     node.Value,
+		Operator: "/",
+		Right:    &parser.NumberExpr{Value: count.Number},
+	})
+	if share.Type != ValueCurrency || count.Number != math.Trunc(count.Number) {
+		return share
+	}
+
+	decimals := currency.MinorUnits(share.Currency)
+	scale := math.Pow(10, float64(decimals))
+	each := e.env.rounding.Places(share.Number, decimals)
+	total := math.Round(share.Number*count.Number*scale) / scale
+	paid := math.Round(each*count.Number*scale) / scale
+	if paid != total {
+		over := "left over"
+		if paid > total {
+			over = "over"
+		}
+		share.Warning = fmt.Sprintf("shares don't divide exactly: %g × %s%.*f = %s%.*f (%s%.*f %s)",
+			count.Number, share.Currency, decimals, each, share.Currency, decimals, paid,
+			share.Currency, decimals, math.Abs(total-paid), over)
+	}
+	return share
+}
+
+// isCountUnit reports whether unit counts people or items, such as "people".
+func (e *Evaluator) isCountUnit(unit string) bool {
+	dim, err := e.env.units.GetDimension(unit)
+	return err == nil && dim == units.DimensionCount
+}
+
+// allocateExact distributes amount across weights using the largest-remainder method
+// so that the shares, each rounded to the given decimals, sum exactly to the rounded amount.
+func allocateExact(amount float64, weights []float64, decimals int) []float64 {
+	scale := math.Pow(10, float64(decimals))
+	units := int64(math.Round(math.Abs(amount) * scale))
+	sign := 1.0
+	if amount < 0 {
+		sign = -1.0
+	}
+
+	var total float64
+	for _, w := range weights {
+		total += w
+	}
+
+	whole := make([]int64, len(weights))
+	remainders := make([]float64, len(weights))
+	var allocated int64
+	for i, w := range weights {
+		exact := float64(units) * w / total
+		whole[i] = int64(math.Floor(exact))
+		remainders[i] = exact - float64(whole[i])
+		allocated += whole[i]
+	}
+
+	// Hand out the leftover minor units to the largest remainders, earliest first on ties
+	for left := units - allocated; left > 0; left-- {
+		best := -1
+		for i := range remainders {
+			if weights[i] == 0 {
+				continue
+			}
+			if best == -1 || remainders[i] > remainders[best] {
+				best = i
+			}
+		}
+		whole[best]++
+		remainders[best] = -1
+	}
+
+	shares := make([]float64, len(weights))
+	for i, w := range whole {
+		shares[i] = sign * float64(w) / scale
+	}
+	return shares
+}
+
 func (e *Evaluator) evalCurrencyBinary(left Value, op string, right Value) Value {
 	// Convert both to the same currency if needed
+	var converting []currency.Rate
 	if left.Type == ValueCurrency && right.Type == ValueCurrency {
 		if left.Currency != right.Currency {
 			// Convert right to left's currency
@@ -719,11 +1336,33 @@ func (e *Evaluator) evalCurrencyBinary(left Value, op string, right Value) Value
 			if err != nil {
 				return NewError(err.Error())
 			}
+			e.recordConversion(right, newCurrencyLike(converted, left))
+			if rate, err := e.env.currency.Rate(right.Currency, left.Currency); err == nil {
+				converting = []currency.Rate{rate}
+				right.Rates = mergeRates(right.Rates, converting)
+			}
 			right.Number = converted
 			right.Currency = left.Currency
 		}
 	}
 
+	result := e.currencyArithmetic(left, op, right)
+	switch {
+	case result.Type == ValueCurrency:
+		result.Rates = mergeRates(left.Rates, right.Rates)
+		result.CurrencyStyle = mixCurrencyStyles(left, right)
+	case !result.IsError():
+		// Dividing one currency by another gives a plain number, which
+		// keeps the rate used to convert between them so show-rates
+		// reveals it; a ratio of amounts in one currency has no rate
+		result.Rates = converting
+	}
+	return result
+}
+
+// currencyArithmetic does the work of evalCurrencyBinary once both sides
+// are in the same currency.
+func (e *Evaluator) currencyArithmetic(left Value, op string, right Value) Value {
 	switch op {
 	case "+":
 		return NewCurrency(left.Number+right.Number, left.Currency)
@@ -746,8 +1385,12 @@ func (e *Evaluator) evalCurrencyBinary(left Value, op string, right Value) Value
 		if right.Number == 0 {
 			return NewError("division by zero")
 		}
-		if right.Type == ValueCurrency {
+		switch {
+		case left.Type == ValueCurrency && right.Type == ValueCurrency:
+			// A ratio or count, such as how many £25 tickets £500 buys
 			return NewNumber(left.Number / right.Number)
+		case right.Type == ValueCurrency:
+			return NewError("cannot divide a number by a currency")
 		}
 		return NewCurrency(left.Number/right.Number, left.Currency)
 	default:
@@ -756,16 +1399,51 @@ func (e *Evaluator) evalCurrencyBinary(left Value, op string, right Value) Value
 }
 
 func (e *Evaluator) evalUnitBinary(left Value, op string, right Value) Value {
+	if isClockTime(left) || isClockTime(right) {
+		return e.evalClockBinary(left, op, right)
+	}
+
+	result := e.evalPlainUnitBinary(left, op, right)
+	// Arithmetic on an elapsed span stays a span, e.g. (17:30 - 09:15) * 2
+	if result.Unit == "time" && (left.Elapsed || right.Elapsed) {
+		result.Elapsed = true
+	}
+	// Scaling or adding to an amount of one ingredient keeps it that
+	// ingredient, so (1 cup flour) * 2 in g still knows its density
+	if result.Type == ValueUnit && !strings.ContainsAny(result.Unit, "/·") {
+		result.Ingredient = commonIngredient(left, right)
+	}
+	return result
+}
+
+// commonIngredient returns the ingredient shared by the operands of unit
+// arithmetic, or "" when they measure different ingredients.
+func commonIngredient(left, right Value) string {
+	switch {
+	case left.Ingredient == "":
+		return right.Ingredient
+	case right.Ingredient == "" || right.Ingredient == left.Ingredient:
+		return left.Ingredient
+	}
+	return ""
+}
+
+// evalPlainUnitBinary applies an operator to unit values that are not clock times.
+func (e *Evaluator) evalPlainUnitBinary(left Value, op string, right Value) Value {
 	switch op {
 	case "+", "-":
 		// For addition/subtraction, units must be compatible
 		if left.Type == ValueUnit && right.Type == ValueUnit {
+			// The same unit on both sides, as in each line of a running
+			// total = total + 0.1 mm, adds as it stands: converting it would
+			// cost a lookup and a trip through the base unit for nothing
 			if left.Unit != right.Unit {
-				// Try to convert right to left's unit
-				converted, err := e.env.units.Convert(right.Number, right.Unit, left.Unit)
+				// Try to convert right to left's unit, which may be a rate such as $/day
+				converted, err := e.convertNumber(right, left.Unit)
 				if err != nil {
 					return NewError(err.Error())
 				}
+				e.recordConversion(right, NewUnit(converted, left.Unit))
 				right.Number = converted
 				right.Unit = left.Unit
 			}
@@ -783,7 +1461,16 @@ func (e *Evaluator) evalUnitBinary(left Value, op string, right Value) Value {
 			if left.Type != ValueUnit {
 				return NewUnit(left.Number*right.Number, right.Unit)
 			}
-			// Both are units - creating compound unit
+			// Both are units - a named derived unit if there is one, else a compound unit
+			if derived, ok := e.simplifyUnits(left, op, right); ok {
+				return derived
+			}
+			if product, ok := e.cancelRate(left, right); ok {
+				return product
+			}
+			if product, ok := e.cancelRate(right, left); ok {
+				return product
+			}
 			return NewUnit(left.Number*right.Number, left.Unit+"·"+right.Unit)
 		}
 		return NewUnit(left.Number*right.Number, left.Unit)
@@ -798,6 +1485,7 @@ func (e *Evaluator) evalUnitBinary(left Value, op string, right Value) Value {
 				converted, err := e.env.units.Convert(right.Number, right.Unit, left.Unit)
 				if err == nil {
 					// Units are compatible, convert and divide
+					e.recordConversion(right, NewUnit(converted, left.Unit))
 					right.Number = converted
 					right.Unit = left.Unit
 				}
@@ -809,6 +1497,9 @@ func (e *Evaluator) evalUnitBinary(left Value, op string, right Value) Value {
 			if left.Unit == right.Unit {
 				return NewNumber(result)
 			}
+			if derived, ok := e.simplifyUnits(left, op, right); ok {
+				return derived
+			}
 			// Otherwise, create rate unit for incompatible units
 			rateUnit := left.Unit + "/" + right.Unit
 			return NewUnit(result, rateUnit)
@@ -820,15 +1511,194 @@ func (e *Evaluator) evalUnitBinary(left Value, op string, right Value) Value {
 	}
 }
 
+// cancelRate multiplies a rate such as £/person or £/kg by a quantity in the
+// rate's denominator, so £21.60/person * 4 people is £86.40. The quantity may
+// itself be a rate, leaving its own denominator: £12.50/hr * 37.5 hours/week
+// is £468.75/week. It reports false when qty cannot be converted to the
+// denominator.
+func (e *Evaluator) cancelRate(rate, qty Value) (Value, bool) {
+	slash := strings.LastIndex(rate.Unit, "/")
+	if slash <= 0 {
+		return Value{}, false
+	}
+	per, denom := rate.Unit[:slash], rate.Unit[slash+1:]
+	n, err := e.env.units.Convert(qty.Number, qty.Unit, denom)
+	if err != nil {
+		return e.cancelRateOfRate(rate.Number, per, denom, qty)
+	}
+	if e.env.currency.IsCurrency(per) {
+		return NewCurrency(rate.Number*n, per), true
+	}
+	return NewUnit(rate.Number*n, per), true
+}
+
+// cancelRateOfRate is cancelRate for a quantity that is a rate whose
+// numerator is in the rate's denominator, as 37.5 hours/week is beside £/hr.
+// A rate quoted per a quantity, as in 75 hours per 2 weeks, stays so.
+func (e *Evaluator) cancelRateOfRate(n float64, per, denom string, qty Value) (Value, bool) {
+	slash := strings.LastIndex(qty.Unit, "/")
+	if slash <= 0 {
+		return Value{}, false
+	}
+	m, err := e.env.units.Convert(qty.Number, qty.Unit[:slash], denom)
+	if err != nil {
+		return Value{}, false
+	}
+	product := NewUnit(n*m, per+"/"+qty.Unit[slash+1:])
+	product.Per = qty.Per
+	return product, true
+}
+
+// simplifyUnits combines the dimensions of two unit values under * or /. When
+// the result is a named SI derived unit, as N·m is J, it returns the value in
+// that unit. Other combinations report false and keep their compound name.
+func (e *Evaluator) simplifyUnits(left Value, op string, right Value) (Value, bool) {
+	lv, lf, err := e.env.units.UnitVector(left.Unit)
+	if err != nil {
+		return Value{}, false
+	}
+	rv, rf, err := e.env.units.UnitVector(right.Unit)
+	if err != nil {
+		return Value{}, false
+	}
+	vec, n := lv.Mul(rv), left.Number*lf*right.Number*rf
+	if op == "/" {
+		vec, n = lv.Div(rv), left.Number*lf/(right.Number*rf)
+	}
+	name, ok := units.DerivedUnit(vec)
+	if !ok {
+		return Value{}, false
+	}
+	return NewUnit(n, name), true
+}
+
+// Clock times (HH:MM literals, unit "time") are times of day, not durations:
+//
+//	clock + duration   -> clock      (14:00 + 2, 14:00 + 30 minutes)
+//	clock - duration   -> clock      (14:00 - 2)
+//	clock - clock      -> elapsed    (17:30 - 09:15 = 08:15)
+//	clock + clock      -> error
+//	duration - clock   -> error
+//	clock * or / n     -> error      (use "duration of 14:00" or a subtraction)
+//
+// Plain numbers count as hours. Elapsed values behave like ordinary durations.
+// A clock time keeps counting past midnight either way rather than wrapping,
+// so 23:30 + 2 is 25.5 hours after the first day's midnight; the formatter
+// shows it as 01:30 (+1 day), and subtracting 23:00 from it gives 02:30. A
+// later time subtracted from an earlier one is a negative span, as in
+// 09:00 - 17:30 = -08:30, never wrapped back to the day before.
+func (e *Evaluator) evalClockBinary(left Value, op string, right Value) Value {
+	leftClock, rightClock := isClockTime(left), isClockTime(right)
+
+	switch op {
+	case "+":
+		if leftClock && rightClock {
+			return NewError("cannot add two clock times; add a duration instead, e.g. 14:00 + 2 hours")
+		}
+		clock, offset := left, right
+		if rightClock {
+			clock, offset = right, left
+		}
+		hours, err := e.durationHours(offset)
+		if err != nil {
+			return NewError(err.Error())
+		}
+		return NewUnit(clock.Number+hours, "time")
+
+	case "-":
+		if leftClock && rightClock {
+			elapsed := NewUnit(left.Number-right.Number, "time")
+			elapsed.Elapsed = true
+			return elapsed
+		}
+		if rightClock {
+			return NewError("cannot subtract a clock time from a duration")
+		}
+		hours, err := e.durationHours(right)
+		if err != nil {
+			return NewError(err.Error())
+		}
+		return NewUnit(left.Number-hours, "time")
+
+	case "*", "/":
+		verb := "multiply"
+		if op == "/" {
+			verb = "divide"
+		}
+		return NewError(fmt.Sprintf("cannot %s a clock time; use 'duration of 14:00' or subtract two times to get a duration", verb))
+
+	default:
+		return NewError(fmt.Sprintf("unknown operator: %s", op))
+	}
+}
+
+// evalDateClockBinary reads a clock time as that time of day on the date's
+// calendar day, so "now - 09:00" is the time since 9am today and
+// "17:30 - now" is the time left until half five. Only subtraction makes sense.
+func (e *Evaluator) evalDateClockBinary(left Value, op string, right Value) Value {
+	if op != "-" {
+		return NewError("cannot combine a date and a clock time with " + op + "; subtract them to get the time between, e.g. now - 09:00")
+	}
+	if left.Type == ValueDate {
+		return NewUnit(left.Date.Sub(clockOnDate(right, left.Date)).Hours(), "hours")
+	}
+	return NewUnit(clockOnDate(left, right.Date).Sub(right.Date).Hours(), "hours")
+}
+
+// evalDateAtTime places a clock time on a date, as in "tomorrow at noon".
+func (e *Evaluator) evalDateAtTime(node *parser.DateAtTimeExpr) Value {
+	date := e.Eval(node.Date)
+	if date.IsError() {
+		return date
+	}
+	clock := e.Eval(node.Time)
+	if clock.IsError() {
+		return clock
+	}
+	if date.Type != ValueDate || !isClockTime(clock) {
+		return NewError("expected a date followed by at and a clock time, e.g. tomorrow at 09:00")
+	}
+	return NewDate(clockOnDate(clock, date.Date))
+}
+
+// clockOnDate places a clock time on the calendar day of date.
+func clockOnDate(clock Value, date time.Time) time.Time {
+	midnight := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	return midnight.Add(time.Duration(clock.Number * float64(time.Hour)))
+}
+
+// isMidnight reports whether t falls exactly on the start of a day, as dates
+// such as today or 25/12/2025 do.
+func isMidnight(t time.Time) bool {
+	return t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0
+}
+
+// isClockTime reports whether v is a time of day rather than a duration.
+func isClockTime(v Value) bool {
+	return v.Type == ValueUnit && v.Unit == "time" && !v.Elapsed
+}
+
+// durationHours returns an offset added to or taken from a clock time in hours.
+// Plain numbers are already hours; units must have the time dimension.
+func (e *Evaluator) durationHours(v Value) (float64, error) {
+	if v.Type != ValueUnit || v.Unit == "time" {
+		return v.Number, nil
+	}
+	hours, err := e.env.units.Convert(v.Number, v.Unit, "hours")
+	if err != nil {
+		return 0, fmt.Errorf("cannot offset a clock time by %s", v.Unit)
+	}
+	return hours, nil
+}
+
 // GetVariable retrieves a variable from the environment.
 func (e *Evaluator) GetVariable(name string) (Value, bool) {
-	val, ok := e.env.variables[name]
-	return val, ok
+	return e.env.GetVariable(name)
 }
 
 // SetVariable sets a variable in the environment.
 func (e *Evaluator) SetVariable(name string, val Value) {
-	e.env.variables[name] = val
+	e.env.SetVariable(name, val)
 }
 
 // Round rounds a value to the specified number of decimal places.
@@ -838,7 +1708,18 @@ func Round(val float64, decimals int) float64 {
 }
 
 func (e *Evaluator) evalWeekday(node *parser.WeekdayExpr) Value {
-	now := time.Now()
+	now := e.env.Now()
+	if node.From != nil {
+		// "friday after 25/12/2025" counts from the date rather than today
+		from := e.Eval(node.From)
+		if from.IsError() {
+			return from
+		}
+		if from.Type != ValueDate {
+			return NewError(fmt.Sprintf("%s %s needs a date", strings.ToLower(node.Weekday.String()), weekdayDirection(node.Modifier)))
+		}
+		now = from.Date
+	}
 	currentWeekday := now.Weekday()
 	targetWeekday := node.Weekday
 
@@ -875,10 +1756,54 @@ func (e *Evaluator) evalWeekday(node *parser.WeekdayExpr) Value {
 	return NewDate(result)
 }
 
+// weekdayDirection is the word a weekday counted from a date was written
+// with: "after" for the next one and "before" for the last.
+func weekdayDirection(modifier string) string {
+	if modifier == "last" {
+		return "before"
+	}
+	return "after"
+}
+
+// evalNthWeekday finds the nth weekday of a month, or the last when n is -1,
+// in the current year unless the expression names one.
+func (e *Evaluator) evalNthWeekday(node *parser.NthWeekdayExpr) Value {
+	now := e.env.Now()
+	year := node.Year
+	if year == 0 {
+		year = now.Year()
+	}
+	if node.N < 0 {
+		// Step back from the month's last day
+		last := time.Date(year, node.Month+1, 0, 0, 0, 0, 0, now.Location())
+		back := (int(last.Weekday()) - int(node.Weekday) + 7) % 7
+		return NewDate(last.AddDate(0, 0, -back))
+	}
+	first := time.Date(year, node.Month, 1, 0, 0, 0, 0, now.Location())
+	ahead := (int(node.Weekday) - int(first.Weekday()) + 7) % 7
+	return NewDate(first.AddDate(0, 0, ahead+7*(node.N-1)))
+}
+
+// evalCalendarDate gives the date of "25th december" or "dec 25 2025". A
+// date without a year falls in the current year, even if it has passed.
+func (e *Evaluator) evalCalendarDate(node *parser.CalendarDateExpr) Value {
+	now := e.env.Now()
+	year := node.Year
+	if year == 0 {
+		year = now.Year()
+	}
+	date := time.Date(year, node.Month, node.Day, 0, 0, 0, 0, now.Location())
+	if date.Month() != node.Month {
+		// time.Date rolls 31 june over into july
+		return NewError(fmt.Sprintf("%s %d has no day %d", node.Month, year, node.Day))
+	}
+	return NewDate(date)
+}
+
 func (e *Evaluator) evalMonth(node *parser.MonthExpr) Value {
 	// Return the number of days in the specified month
 	// We'll use the current year, or next year if we're past that month
-	now := time.Now()
+	now := e.env.Now()
 
 	// Map month name to month number
 	monthMap := map[string]time.Month{
@@ -913,7 +1838,7 @@ func (e *Evaluator) evalTimeInLocation(node *parser.TimeInLocationExpr) Value {
 	}
 
 	// Get current UTC time and convert to target location
-	now := time.Now().UTC()
+	now := e.env.Now().UTC()
 	targetTime := now.Add(time.Duration(loc.Offset) * time.Hour)
 
 	return NewDate(targetTime)
@@ -958,7 +1883,16 @@ func (e *Evaluator) evalTimeConversion(node *parser.TimeConversionExpr) Value {
 		if timeVal.IsError() {
 			return timeVal
 		}
-		baseTime = timeVal.Date
+		if isClockTime(timeVal) {
+			// A clock time is read on today's date in the source location
+			fromLoc, err := e.env.timezone.GetLocation(node.From)
+			if err != nil {
+				return NewError(err.Error())
+			}
+			baseTime = clockOnDate(timeVal, e.env.Now().UTC().Add(time.Duration(fromLoc.Offset)*time.Hour))
+		} else {
+			baseTime = timeVal.Date
+		}
 	} else {
 		// Get current time in source location (as UTC + offset)
 		fromLoc, err := e.env.timezone.GetLocation(node.From)
@@ -966,7 +1900,7 @@ func (e *Evaluator) evalTimeConversion(node *parser.TimeConversionExpr) Value {
 			return NewError(err.Error())
 		}
 		// Current time in the source location
-		baseTime = time.Now().UTC().Add(time.Duration(fromLoc.Offset) * time.Hour)
+		baseTime = e.env.Now().UTC().Add(time.Duration(fromLoc.Offset) * time.Hour)
 	}
 
 	// Apply offset if provided
@@ -1066,7 +2000,7 @@ func (e *Evaluator) evalPrev(node *parser.PrevExpr) Value {
 			return NewError(err.Error())
 		}
 		
-		return val
+		return val.withoutProvenance().Precise()
 	} else {
 		// Relative offset: prev, prev~N
 		if e.env.historyFunc == nil {
@@ -1078,6 +2012,6 @@ func (e *Evaluator) evalPrev(node *parser.PrevExpr) Value {
 			return NewError(err.Error())
 		}
 		
-		return val
+		return val.withoutProvenance().Precise()
 	}
 }
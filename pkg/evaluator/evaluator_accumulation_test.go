@@ -0,0 +1,90 @@
+package evaluator
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestKahanSum(t *testing.T) {
+	var naive float64
+	var k kahanSum
+	for i := 0; i < 10000; i++ {
+		naive += 0.1
+		k.add(0.1)
+	}
+	if naive == 1000 {
+		t.Fatal("expected plain addition to drift, so the test shows nothing")
+	}
+	if got := k.total(); got != 1000 {
+		t.Errorf("kahanSum of 10000 × 0.1 = %.17g, want 1000", got)
+	}
+
+	// Large and small values mixed in either order keep the small ones
+	k = kahanSum{}
+	for _, x := range []float64{1, 1e100, 1, -1e100} {
+		k.add(x)
+	}
+	if got := k.total(); got != 2 {
+		t.Errorf("kahanSum of 1, 1e100, 1, -1e100 = %g, want 2", got)
+	}
+}
+
+func TestRunningTotalDoesNotDrift(t *testing.T) {
+	const steps = 10000
+	tests := []struct {
+		name  string
+		start string
+		step  string
+		unit  string
+		want  float64
+	}{
+		{"same unit", "0 mm", "0.1 mm", "mm", 1000},
+		{"same unit subtracted", "1000 mm", "-0.1 mm", "mm", 0},
+		{"another spelling", "0 mm", "0.1 millimetres", "mm", 1000},
+		{"converted", "0 m", "0.1 mm", "m", 1},
+		{"time", "0 h", "0.01 h", "h", 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := New(NewEnvironment())
+			evalLines(t, e, "acc = "+tt.start)
+			op, step := "+", tt.step
+			if strings.HasPrefix(step, "-") {
+				op, step = "-", step[1:]
+			}
+			var got Value
+			for i := 0; i < steps; i++ {
+				got = evalLines(t, e, "acc = acc "+op+" "+step)
+			}
+			if got.IsError() {
+				t.Fatalf("unexpected error: %s", got.Error)
+			}
+			if got.Unit != tt.unit {
+				t.Errorf("unit = %q, want %q", got.Unit, tt.unit)
+			}
+			if !withinRelative(got.Number, tt.want, 1e-9) {
+				t.Errorf("got %.17g, want %g", got.Number, tt.want)
+			}
+		})
+	}
+}
+
+func TestSumAndAverageCompensate(t *testing.T) {
+	args := strings.TrimSuffix(strings.Repeat("0.1, ", 1000), ", ")
+	e := New(NewEnvironment())
+
+	if got := evalLines(t, e, "sum("+args+")"); got.Number != 100 {
+		t.Errorf("sum of 1000 × 0.1 = %.17g, want 100", got.Number)
+	}
+	if got := evalLines(t, e, "average("+args+")"); got.Number != 0.1 {
+		t.Errorf("average of 1000 × 0.1 = %.17g, want 0.1", got.Number)
+	}
+}
+
+// withinRelative reports whether got is want to within tol of want, or of
+// 1 when want is 0.
+func withinRelative(got, want, tol float64) bool {
+	return math.Abs(got-want) <= tol*math.Max(math.Abs(want), 1)
+}
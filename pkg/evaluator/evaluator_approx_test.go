@@ -0,0 +1,46 @@
+package evaluator
+
+import "testing"
+
+func TestApprox(t *testing.T) {
+	e := New(NewEnvironment())
+
+	got := evalLines(t, e, "about 37 * 42")
+	if !got.Approx || got.Number != 1554 {
+		t.Errorf("about 37 * 42 = %+v, want 1554 marked approximate", got)
+	}
+	got = evalLines(t, e, "roughly 1234 km in miles")
+	if !got.Approx || !got.Explicit || got.Unit != "miles" {
+		t.Errorf("roughly 1234 km in miles = %+v, want miles marked approximate", got)
+	}
+	got = evalLines(t, e, "about 5 km in miles, feet")
+	if len(got.Items) != 2 || !got.Items[0].Approx || !got.Items[1].Approx {
+		t.Errorf("about 5 km in miles, feet = %+v, want both items marked approximate", got)
+	}
+
+	// The line is shown rounded; the variable keeps the exact value
+	if got := evalLines(t, e, "x = about 37 * 42"); !got.Approx {
+		t.Errorf("x = about 37 * 42 = %+v, want it marked approximate", got)
+	}
+	if got := evalLines(t, e, "x"); got.Approx || got.Number != 1554 {
+		t.Errorf("x = %+v, want 1554 unmarked", got)
+	}
+	if got := evalLines(t, e, "x * 2"); got.Approx {
+		t.Errorf("x * 2 = %+v, want it unmarked", got)
+	}
+
+	if got := evalLines(t, e, "about 1 / 0"); !got.IsError() {
+		t.Errorf("about 1 / 0 = %+v, want an error", got)
+	}
+}
+
+func TestApproxPrev(t *testing.T) {
+	env := NewEnvironment()
+	e := New(env)
+	last := evalLines(t, e, "about 37 * 42")
+	env.SetHistoryFunc(func(offset int) (Value, error) { return last, nil })
+
+	if got := evalLines(t, e, "prev"); got.Approx || got.Number != 1554 {
+		t.Errorf("prev = %+v, want 1554 unmarked", got)
+	}
+}
@@ -0,0 +1,69 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/andrewneudegg/calc/pkg/lexer"
+	"github.com/andrewneudegg/calc/pkg/parser"
+)
+
+// benchScript is a short budgeting script of the kind calc -f runs.
+var benchScript = []string{
+	"rent = £1200",
+	"food = £45.50 * 4",
+	"travel = 12 km * 2 * 20",
+	"travel in miles",
+	"fuel = £1.45 * 40",
+	"bills = £95 + £32.40 + £18",
+	"spend = rent + food + bills",
+	"20% of spend",
+	"spend / 4",
+	"3 hours + 45 minutes",
+	"today + 2 weeks",
+	"100 f in c",
+	"2 GB in MB",
+}
+
+// BenchmarkEvalScript evaluates benchScript in a new environment, as each
+// calc -c and calc -f run does.
+func BenchmarkEvalScript(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		env := NewEnvironment()
+		e := New(env)
+		for _, line := range benchScript {
+			l := lexer.NewWithUnits(line, env.IsUnit)
+			l.SetConstantChecker(env.Constants().IsConstant)
+			expr, err := parser.New(l.AllTokens()).Parse()
+			if err != nil {
+				b.Fatalf("parse %q: %v", line, err)
+			}
+			e.Eval(expr)
+		}
+	}
+}
+
+// BenchmarkRunningTotal evaluates a line of a long running-total script,
+// acc = acc + 0.1 mm, whose units match and so skip conversion, beside one
+// whose step has to be converted.
+func BenchmarkRunningTotal(b *testing.B) {
+	for _, bm := range []struct{ name, line string }{
+		{"same unit", "acc = acc + 0.1 mm"},
+		{"converted", "acc = acc + 0.0001 m"},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			env := NewEnvironment()
+			e := New(env)
+			expr, err := parser.New(lexer.NewWithUnits(bm.line, env.IsUnit).AllTokens()).Parse()
+			if err != nil {
+				b.Fatalf("parse %q: %v", bm.line, err)
+			}
+			env.SetVariable("acc", NewUnit(0, "mm"))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				e.Eval(expr)
+			}
+		})
+	}
+}
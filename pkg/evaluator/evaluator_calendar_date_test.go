@@ -0,0 +1,53 @@
+package evaluator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalendarDates(t *testing.T) {
+	// testClock is Saturday 1 June 2024
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"25th december", "2024-12-25"},
+		{"december 25th 2025", "2025-12-25"},
+		{"25 dec", "2024-12-25"},
+		{"dec 25 2025", "2025-12-25"},
+		{"3rd of june", "2024-06-03"},
+		{"1 jan", "2024-01-01"}, // The current year, although it has passed
+		{"29 feb", "2024-02-29"},
+		{"dec 25 + 1 week", "2025-01-01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			v := evalExprAt(tt.input, testClock)
+			if v.IsError() {
+				t.Fatalf("unexpected error: %s", v.Error)
+			}
+			if v.Type != ValueDate || v.Date.Format("2006-01-02") != tt.want {
+				t.Errorf("got %v, want %s", v.Date, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalendarDateArithmetic(t *testing.T) {
+	v := evalExprAt("25th december - today", testClock)
+	if v.IsError() || v.Unit != "days" || v.Number != 207 {
+		t.Errorf("25th december - today = %+v, want 207 days", v)
+	}
+}
+
+func TestCalendarDateErrors(t *testing.T) {
+	for _, input := range []string{"31 june", "29 feb 2025", "feb 30"} {
+		if v := evalExprAt(input, testClock); !v.IsError() {
+			t.Errorf("%s = %v, want an error", input, v.Date.Format(time.DateOnly))
+		}
+	}
+	// A month on its own still counts its days
+	if v := evalExprAt("december", testClock); v.Type != ValueUnit || v.Number != 31 {
+		t.Errorf("december = %+v, want 31 days", v)
+	}
+}
@@ -0,0 +1,58 @@
+package evaluator
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrewneudegg/calc/pkg/lexer"
+	"github.com/andrewneudegg/calc/pkg/parser"
+)
+
+func parseLine(t *testing.T, line string) parser.Expr {
+	t.Helper()
+	expr, err := parser.New(lexer.New(line).AllTokens()).Parse()
+	if err != nil {
+		t.Fatalf("parse %q: %v", line, err)
+	}
+	return expr
+}
+
+func TestEvalContextCancelled(t *testing.T) {
+	e := New(NewEnvironment())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if v := e.EvalContext(ctx, parseLine(t, "x = 1 + 2")); v.Error != "evaluation cancelled" {
+		t.Errorf("cancelled: got %+v", v)
+	}
+	if e.env.HasVariable("x") {
+		t.Error("a cancelled assignment still set x")
+	}
+	// The evaluator carries on once given a live context again
+	if v := e.Eval(parseLine(t, "1 + 2")); v.Number != 3 {
+		t.Errorf("after cancelling: got %+v", v)
+	}
+}
+
+func TestEvalContextTimesOut(t *testing.T) {
+	env := NewEnvironment()
+	// A slow name, read many times, stands in for a pathological expression
+	env.SetNameFunc(func(name string) (Value, bool) {
+		time.Sleep(2 * time.Millisecond)
+		return NewNumber(1), name == "slow"
+	})
+	e := New(env)
+	expr := parseLine(t, strings.Repeat("slow + ", 1000)+"slow")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	v := e.EvalContext(ctx, expr)
+	if v.Error != "evaluation timed out" {
+		t.Errorf("got %+v, want a timeout", v)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("stopping took %s", elapsed)
+	}
+}
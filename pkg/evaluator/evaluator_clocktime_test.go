@@ -0,0 +1,99 @@
+package evaluator
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/andrewneudegg/calc/pkg/lexer"
+	"github.com/andrewneudegg/calc/pkg/parser"
+)
+
+func TestClockTimeOperations(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    float64
+		unit        string
+		elapsed     bool
+		wantWarning bool
+		wantErr     string
+	}{
+		{name: "literal", input: "14:00", expected: 14, unit: "time"},
+		{name: "add plain hours", input: "14:00 + 2", expected: 16, unit: "time"},
+		{name: "add hours on the left", input: "2 + 14:00", expected: 16, unit: "time"},
+		{name: "add minutes", input: "14:00 + 30 minutes", expected: 14.5, unit: "time"},
+		{name: "subtract hours", input: "14:00 - 2", expected: 12, unit: "time"},
+		{name: "subtract duration unit", input: "14:00 - 90 minutes", expected: 12.5, unit: "time"},
+		{name: "subtract clock times", input: "17:30 - 09:15", expected: 8.25, unit: "time", elapsed: true},
+		{name: "subtract a later clock time", input: "09:00 - 17:30", expected: -8.5, unit: "time", elapsed: true},
+		{name: "add past midnight", input: "23:30 + 2", expected: 25.5, unit: "time"},
+		{name: "subtract before midnight", input: "01:00 - 2 hours", expected: -1, unit: "time"},
+		{name: "elapsed from a carried time", input: "(23:30 + 2) - 23:00", expected: 2.5, unit: "time", elapsed: true},
+		{name: "pm", input: "9pm", expected: 21, unit: "time"},
+		{name: "am and pm", input: "9:00pm - 8:15am", expected: 12.75, unit: "time", elapsed: true},
+		{name: "pm plus hours", input: "11pm + 3 hours", expected: 26, unit: "time"},
+		{name: "elapsed converts quietly", input: "(17:30 - 09:15) in minutes", expected: 495, unit: "minutes"},
+		{name: "elapsed scales", input: "(17:30 - 09:15) * 2", expected: 16.5, unit: "time", elapsed: true},
+		{name: "clock plus elapsed", input: "09:00 + (11:00 - 10:00)", expected: 10, unit: "time"},
+		{name: "duration of", input: "duration of 14:00", expected: 14, unit: "hours"},
+		{name: "duration of converted", input: "duration of 14:30 in minutes", expected: 870, unit: "minutes"},
+		{name: "duration of a duration", input: "duration of 3 hours", expected: 3, unit: "hours"},
+		{name: "in minutes warns", input: "14:00 in minutes", expected: 840, unit: "minutes", wantWarning: true},
+		{name: "in days warns", input: "14:00 in days", expected: 14.0 / 24, unit: "days", wantWarning: true},
+		{name: "add two clock times", input: "14:00 + 09:00", wantErr: "cannot add two clock times"},
+		{name: "duration minus clock", input: "2 hours - 14:00", wantErr: "cannot subtract a clock time"},
+		{name: "multiply by scalar", input: "2 * 14:00", wantErr: "cannot multiply a clock time"},
+		{name: "multiply scalar on right", input: "14:00 * 2", wantErr: "cannot multiply a clock time"},
+		{name: "divide by scalar", input: "14:00 / 2", wantErr: "cannot divide a clock time"},
+		{name: "divide clock times", input: "14:00 / 07:00", wantErr: "cannot divide a clock time"},
+		{name: "offset by non-duration", input: "14:00 + 5 kg", wantErr: "cannot offset a clock time by kg"},
+		{name: "duration of non-time", input: "duration of 5 kg", wantErr: "duration of expects a clock time"},
+		{name: "hour past 12 with pm", input: "13:00pm", wantErr: "hours before am or pm run from 1 to 12"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := evalExpr(tt.input)
+
+			if tt.wantErr != "" {
+				if !result.IsError() || !strings.Contains(result.Error, tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, result)
+				}
+				return
+			}
+			if result.IsError() {
+				t.Fatalf("unexpected error: %s", result.Error)
+			}
+			if math.Abs(result.Number-tt.expected) > 0.0001 {
+				t.Errorf("expected %v, got %v", tt.expected, result.Number)
+			}
+			if result.Unit != tt.unit {
+				t.Errorf("expected unit %q, got %q", tt.unit, result.Unit)
+			}
+			if result.Elapsed != tt.elapsed {
+				t.Errorf("expected elapsed=%v, got %v", tt.elapsed, result.Elapsed)
+			}
+			if (result.Warning != "") != tt.wantWarning {
+				t.Errorf("expected warning=%v, got %q", tt.wantWarning, result.Warning)
+			}
+		})
+	}
+}
+
+func TestClockTimeWarningNotStoredInVariable(t *testing.T) {
+	expr, err := parser.New(lexer.New("x = 14:00 in minutes").AllTokens()).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	e := New(NewEnvironment())
+
+	result := e.Eval(expr)
+	if result.Warning == "" {
+		t.Fatal("expected the assignment result to carry the warning")
+	}
+	stored, _ := e.GetVariable("x")
+	if stored.Warning != "" {
+		t.Errorf("expected stored variable without warning, got %q", stored.Warning)
+	}
+}
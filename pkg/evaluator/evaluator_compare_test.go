@@ -0,0 +1,82 @@
+package evaluator
+
+import "testing"
+
+func TestCompareAnswers(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"is 5 km more than 3 miles", "yes, 5.00 km ≈ 3.11 miles > 3.00 miles"},
+		{"is 5 km less than 3 miles", "no, 5.00 km ≈ 3.11 miles > 3.00 miles"},
+		{"is £20 less than $30", "yes, £20.00 ≈ $25.40 < $30.00"},
+		{"is 2 more than 3", "no, 2.00 < 3.00"},
+		{"is 3 kg bigger than 3 kg", "no, 3.00 kg = 3.00 kg"},
+		{"is 1 km smaller than 1000 m", "no, 1.00 km ≈ 1000.00 m = 1000.00 m"},
+		{"is 10% more than 5", "yes, 10.00% > 5.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := evalLines(t, New(NewEnvironment()), tt.input)
+			if got.IsError() {
+				t.Fatalf("unexpected error: %s", got.Error)
+			}
+			if got.Text != tt.want {
+				t.Errorf("got %q, want %q", got.Text, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareKeepsRates(t *testing.T) {
+	got := evalLines(t, New(NewEnvironment()), "is £20 less than $30")
+	if len(got.Rates) != 1 || got.Rates[0].From != "GBP" || got.Rates[0].To != "USD" {
+		t.Errorf("expected the GBP to USD rate, got %+v", got.Rates)
+	}
+}
+
+func TestCompareWhich(t *testing.T) {
+	tests := []struct {
+		input string
+		want  float64
+		unit  string
+	}{
+		{"which is bigger: 1 gb or 900 mb", 1, "gb"},
+		{"which is smaller: 1 gb or 900 mb", 900, "mb"},
+		{"which is bigger: 1 gb or 900 mb in mb", 1024, "mb"},
+		{"which is more: 1000 m or 1 km", 1000, "m"}, // A tie gives the first
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := evalLines(t, New(NewEnvironment()), tt.input)
+			if got.IsError() {
+				t.Fatalf("unexpected error: %s", got.Error)
+			}
+			if got.Unit != tt.unit || got.Number != tt.want {
+				t.Errorf("got %v %s, want %v %s", got.Number, got.Unit, tt.want, tt.unit)
+			}
+		})
+	}
+}
+
+func TestCompareErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"is 5 kg more than 3 m", "cannot compare 5.00 kg with 3.00 m"},
+		{"which is bigger: £5 or 2 kg", "cannot compare £5.00 with 2.00 kg"},
+		{"is 1 / 0 more than 2", "division by zero"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := evalLines(t, New(NewEnvironment()), tt.input)
+			if !got.IsError() || got.Error != tt.want {
+				t.Errorf("got %+v, want error %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package evaluator
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCompositeQuantities(t *testing.T) {
+	tests := []struct {
+		input string
+		want  float64
+		unit  string
+	}{
+		{"5 ft 10 in in cm", 177.8, "cm"},
+		{"5 ft 6 in", 5.5, "ft"},
+		{"6 pounds 4 ounces in grams", 2834.95, "grams"},
+		{"1 st 7 lb", 1.5, "st"},
+		{"1 hour 30 minutes in seconds", 5400, "seconds"},
+		{"2 minutes 30 seconds", 2.5, "minutes"},
+		{"1 h 30 min 15 s in s", 5415, "s"},
+		{"1 m 50 cm * 2", 3, "m"},
+	}
+	for _, tt := range tests {
+		got := evalLines(t, New(NewEnvironment()), tt.input)
+		if got.IsError() || got.Unit != tt.unit || math.Abs(got.Number-tt.want) > 1e-6 {
+			t.Errorf("%q = %v, want %g %s", tt.input, got, tt.want, tt.unit)
+		}
+	}
+}
+
+func TestCompositeErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"5 kg 3 m", "cannot write kg and m as one amount; they measure different things"},
+		{"6 in 5 ft", "ft is not smaller than in; write the larger unit first, as in 5 ft 10 in"},
+		{"5 ft 5 ft", "ft is not smaller than ft; write the larger unit first, as in 5 ft 10 in"},
+		{"20 c 5 f", "a temperature cannot be written in parts"},
+		{"5 ft 3 usd", "$3.00 cannot be part of an amount such as 5 ft 10 in"},
+	}
+	for _, tt := range tests {
+		got := evalLines(t, New(NewEnvironment()), tt.input)
+		if got.Error != tt.want {
+			t.Errorf("%q = %v, want error %q", tt.input, got, tt.want)
+		}
+	}
+}
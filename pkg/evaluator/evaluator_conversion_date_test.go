@@ -0,0 +1,101 @@
+package evaluator
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrewneudegg/calc/pkg/currency"
+)
+
+// historyEnv returns an environment with past rates for 15 and 18 March 2024.
+func historyEnv(t *testing.T) *Environment {
+	t.Helper()
+	h := currency.NewRateHistory()
+	for _, r := range []struct {
+		day      int
+		from, to string
+		rate     float64
+	}{
+		{15, "GBP", "USD", 1.2734},
+		{15, "EUR", "USD", 1.0891},
+		{18, "GBP", "USD", 1.2720},
+	} {
+		if err := h.Add(time.Date(2024, 3, r.day, 0, 0, 0, 0, time.UTC), r.from, r.to, r.rate); err != nil {
+			t.Fatal(err)
+		}
+	}
+	env := NewEnvironment()
+	env.Currency().SetHistory(h)
+	return env
+}
+
+func TestConversionOnDate(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    float64
+		symbol  string
+		rateOn  string
+		warning string
+	}{
+		{"£100 in usd on 15/03/2024", 127.34, "$", "2024-03-15", ""},
+		{"£100 in usd on 18/03/2024", 127.20, "$", "2024-03-18", ""},
+		{"£100 in eur on 15/03/2024", 100 * 1.2734 / 1.0891, "€", "2024-03-15", ""},
+		{"$127.34 to gbp on 15/03/2024", 100, "£", "2024-03-15", ""},
+		{"£100 in usd on 17/03/2024", 127.34, "$", "2024-03-15", "no rate on 17 Mar 2024; used the 15 Mar 2024 rate"},
+		{"(£50 + £50) in usd on 15/03/2024", 127.34, "$", "2024-03-15", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := evalLines(t, New(historyEnv(t)), tt.input)
+			if got.IsError() {
+				t.Fatalf("unexpected error: %s", got.Error)
+			}
+			if got.Type != ValueCurrency || got.Currency != tt.symbol || math.Abs(got.Number-tt.want) > 1e-9 {
+				t.Errorf("got %v %s, want %v %s", got.Number, got.Currency, tt.want, tt.symbol)
+			}
+			if len(got.Rates) != 1 || got.Rates[0].On.Format("2006-01-02") != tt.rateOn {
+				t.Errorf("expected the %s rate, got %+v", tt.rateOn, got.Rates)
+			}
+			if got.Warning != tt.warning {
+				t.Errorf("warning %q, want %q", got.Warning, tt.warning)
+			}
+		})
+	}
+}
+
+func TestConversionOnDateVariable(t *testing.T) {
+	got := evalLines(t, New(historyEnv(t)), "paid = 15/03/2024", "£10 in usd on paid")
+	if got.IsError() || math.Abs(got.Number-12.734) > 1e-9 {
+		t.Errorf("expected $12.73, got %+v", got)
+	}
+}
+
+func TestConversionOnDateErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"£100 in usd on 01/01/2020", "no GBP to USD exchange rate on 1 Jan 2020; add past rates with :rates import <file.csv>"},
+		{"£100 in chf on 15/03/2024", "no GBP to CHF exchange rate on 15 Mar 2024"},
+		{"£100 in usd on 3", "on needs a date, got 3"},
+		{"5 in usd on 15/03/2024", "only money has exchange rates"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := evalLines(t, New(historyEnv(t)), tt.input)
+			if !got.IsError() || !strings.Contains(got.Error, tt.want) {
+				t.Errorf("expected an error containing %q, got %+v", tt.want, got)
+			}
+		})
+	}
+
+	// Without a history there is no past rate, and today's is not used instead
+	got := evalLines(t, New(NewEnvironment()), "£100 in usd on 15/03/2024")
+	if !got.IsError() || !strings.Contains(got.Error, "no GBP to USD exchange rate on 15 Mar 2024") {
+		t.Errorf("expected a missing rate error, got %+v", got)
+	}
+}
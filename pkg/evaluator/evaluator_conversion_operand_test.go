@@ -0,0 +1,61 @@
+package evaluator
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParenthesisedConversionAsOperand(t *testing.T) {
+	lb := evalExpr("3 kg in lb").Number
+
+	tests := []struct {
+		input    string
+		expected float64
+		unit     string
+	}{
+		{"(3 kg in lb) * 5", lb * 5, "lb"},
+		{"5 * (3 kg in lb)", lb * 5, "lb"},
+		{"(3 kg in lb) / 2", lb / 2, "lb"},
+		{"(3 kg in lb) + 1 lb", lb + 1, "lb"},
+		{"(3 kg in lb) - 1 lb", lb - 1, "lb"},
+		{"-(3 kg in lb)", -lb, "lb"},
+		{"10% of (1 km in m)", 100, "m"},
+		{"half of (1 km in m)", 500, "m"},
+		{"max((3 kg in lb), 1)", lb, "lb"},
+		{"(1 km in m) / (1 m in cm)", 1000, ""},
+		{"(3 kg in lb) * 5 in kg", 15, "kg"},
+		{"((1 hour in minutes) * 2) + 30 minutes", 150, "minutes"},
+		{"3 kg in lb * 2", lb * 2, "lb"},
+		{"3 kg in lb * 2 in kg", 6, "kg"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result := evalExpr(tt.input)
+			if result.IsError() {
+				t.Fatalf("unexpected error: %s", result.Error)
+			}
+			if math.Abs(result.Number-tt.expected) > 1e-6 {
+				t.Errorf("expected %v, got %v", tt.expected, result.Number)
+			}
+			if result.Unit != tt.unit {
+				t.Errorf("expected unit %q, got %q", tt.unit, result.Unit)
+			}
+		})
+	}
+}
+
+func TestParenthesisedCurrencyRateConversion(t *testing.T) {
+	monthly := evalExpr("£40/day in gbp/month")
+	if monthly.IsError() {
+		t.Fatalf("unexpected error: %s", monthly.Error)
+	}
+
+	result := evalExpr("((£40/day in gbp/month) * 0.8) + £100")
+	if result.IsError() {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+	if want := monthly.Number*0.8 + 100; math.Abs(result.Number-want) > 1e-6 {
+		t.Errorf("expected %v, got %v", want, result.Number)
+	}
+}
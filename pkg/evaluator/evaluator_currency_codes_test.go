@@ -0,0 +1,31 @@
+package evaluator
+
+import (
+	"math"
+	"testing"
+
+	"github.com/andrewneudegg/calc/pkg/currency"
+)
+
+// TestEveryCurrencyNameRoundTrips converts 100 of every code and word the
+// parser reads as a currency to US dollars and back.
+func TestEveryCurrencyNameRoundTrips(t *testing.T) {
+	for _, name := range currency.Names() {
+		t.Run(name, func(t *testing.T) {
+			e := New(NewEnvironment())
+			amount := evalLines(t, e, "100 "+name)
+			if amount.Type != ValueCurrency {
+				t.Fatalf("100 %s = %+v, want a currency amount", name, amount)
+			}
+			usd := evalLines(t, e, "100 "+name+" in usd")
+			if usd.IsError() || usd.Number <= 0 {
+				t.Fatalf("100 %s in usd = %+v", name, usd)
+			}
+			e.env.SetVariable("amount", usd)
+			back := evalLines(t, e, "amount in "+name)
+			if back.IsError() || math.Abs(back.Number-100) > 1e-9 || back.Currency != amount.Currency {
+				t.Errorf("back to %s = %+v, want %s100", name, back, amount.Currency)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package evaluator
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCurrencyDivision(t *testing.T) {
+	tests := []struct {
+		input    string
+		want     Value
+		wantErr  string
+		hasRates bool
+	}{
+		// currency / currency
+		{input: "£500 / £25", want: NewNumber(20)},
+		{input: "$127 / £25", want: NewNumber(4), hasRates: true},
+		{input: "£500 / £0", wantErr: "division by zero"},
+		{input: "£500 / $0", wantErr: "division by zero"},
+		// currency / number
+		{input: "£500 / 4", want: NewCurrency(125, "£")},
+		{input: "£500 / 0", wantErr: "division by zero"},
+		// number / currency
+		{input: "100 / £5", wantErr: "cannot divide a number by a currency"},
+		{input: "100 / £0", wantErr: "division by zero"},
+		// number / number
+		{input: "100 / 5", want: NewNumber(20)},
+		{input: "100 / 0", wantErr: "division by zero"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := evalLines(t, New(NewEnvironment()), tt.input)
+			if tt.wantErr != "" {
+				if !got.IsError() || got.Error != tt.wantErr {
+					t.Fatalf("expected error %q, got %+v", tt.wantErr, got)
+				}
+				return
+			}
+			if got.IsError() {
+				t.Fatalf("unexpected error: %s", got.Error)
+			}
+			if got.Type != tt.want.Type || got.Currency != tt.want.Currency || math.Abs(got.Number-tt.want.Number) > 1e-9 {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+			if hasRates := len(got.Rates) > 0; hasRates != tt.hasRates {
+				t.Errorf("got rates %v, want rates %v", got.Rates, tt.hasRates)
+			}
+		})
+	}
+}
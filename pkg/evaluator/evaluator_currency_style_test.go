@@ -0,0 +1,67 @@
+package evaluator
+
+import "testing"
+
+func TestCurrencyStyleFollowsInput(t *testing.T) {
+	tests := []struct {
+		input string
+		want  CurrencyStyle
+	}{
+		{"£12", CurrencyStyleSymbol},
+		{"12 gbp", CurrencyStyleCode},
+		{"12 GBP", CurrencyStyleCode},
+		{"100 dollars", CurrencyStyleDefault},
+		{"12 gbp + 3 gbp", CurrencyStyleCode},
+		{"£12 + £3", CurrencyStyleSymbol},
+		{"100 CHF + 50 CHF", CurrencyStyleCode},
+		{"12 gbp * 2", CurrencyStyleCode},
+		{"2 * 12 gbp", CurrencyStyleCode},
+		{"£12 + 3 gbp", CurrencyStyleDefault},
+		{"12 gbp - £3", CurrencyStyleDefault},
+		{"12 gbp + $3", CurrencyStyleDefault},
+		{"12 gbp in usd", CurrencyStyleCode},
+		{"£12 in usd", CurrencyStyleSymbol},
+		{"(12 gbp + 3 gbp) in eur", CurrencyStyleCode},
+		{"10% of 40 gbp", CurrencyStyleCode},
+		{"20% off 80 gbp", CurrencyStyleCode},
+		{"half of 80 gbp", CurrencyStyleCode},
+		{"increase 100 gbp by £5", CurrencyStyleDefault},
+		{"increase 100 gbp by 5%", CurrencyStyleCode},
+	}
+	for _, tt := range tests {
+		got := evalLines(t, New(NewEnvironment()), tt.input)
+		if got.Type != ValueCurrency {
+			t.Errorf("%q = %+v, want an amount of money", tt.input, got)
+			continue
+		}
+		if got.CurrencyStyle != tt.want {
+			t.Errorf("%q: style %d, want %d", tt.input, got.CurrencyStyle, tt.want)
+		}
+	}
+}
+
+func TestCurrencyStyleKeepsIdentity(t *testing.T) {
+	// How a currency is written changes how it is shown, not which it is
+	got := evalLines(t, New(NewEnvironment()), "£12 + 3 gbp")
+	if got.Currency != "£" || got.Number != 15 || len(got.Rates) != 0 {
+		t.Errorf("£12 + 3 gbp = %+v, want £15 with no exchange", got)
+	}
+}
+
+func TestCurrencyStyleThroughParts(t *testing.T) {
+	e := New(NewEnvironment())
+	for _, input := range []string{"15% tip on 40 gbp", "split 100 gbp in ratio 1:1"} {
+		got := evalLines(t, e, input)
+		for _, item := range got.Items {
+			if item.CurrencyStyle != CurrencyStyleCode {
+				t.Errorf("%q: %s has style %d, want code", input, item.Label, item.CurrencyStyle)
+			}
+		}
+	}
+
+	// A variable keeps the style it was assigned with
+	evalLines(t, e, "rent = 900 gbp")
+	if got := evalLines(t, e, "rent / 2"); got.CurrencyStyle != CurrencyStyleCode {
+		t.Errorf("rent / 2: style %d, want code", got.CurrencyStyle)
+	}
+}
@@ -0,0 +1,53 @@
+package evaluator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDateConversionTargets(t *testing.T) {
+	tests := []struct {
+		input string
+		text  string
+		num   float64
+	}{
+		{"25/12/2025 in weekday", "Thursday", 0},
+		{"today in weekday", "Saturday", 0},
+		{"today + 90 days as iso", "2024-08-30", 0},
+		{"25/12/2025 as ISO", "2025-12-25", 0},
+		{"today in unix", "", 1717200000},
+		{"today in week", "", 22},
+		{"31/12/2024 in week", "", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			v := evalExprAt(tt.input, testClock)
+			if v.IsError() {
+				t.Fatalf("got error: %s", v.Error)
+			}
+			if tt.text != "" {
+				if v.Type != ValueString || v.Text != tt.text {
+					t.Errorf("got %+v, want %q", v, tt.text)
+				}
+				return
+			}
+			if v.Type != ValueNumber || v.Number != tt.num || !v.Whole {
+				t.Errorf("got %+v, want whole number %v", v, tt.num)
+			}
+		})
+	}
+}
+
+func TestDateConversionWithTimeIsRFC3339(t *testing.T) {
+	v := evalExprAt("now as iso", testClock)
+	if v.Type != ValueString || v.Text != "2024-06-01T23:59:30Z" {
+		t.Errorf("now as iso = %+v", v)
+	}
+}
+
+func TestDateConversionUnknownTarget(t *testing.T) {
+	v := evalExprAt("today in fortnight", testClock)
+	if !v.IsError() || !strings.Contains(v.Error, "try weekday, iso, unix, week") {
+		t.Errorf("today in fortnight = %+v", v)
+	}
+}
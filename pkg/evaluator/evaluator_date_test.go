@@ -8,10 +8,25 @@ import (
 	"github.com/andrewneudegg/calc/pkg/parser"
 )
 
+// testClock is the fixed "now" used by date tests. It sits just before
+// midnight so any test still reading the real clock would show up as an
+// off-by-one-day failure rather than passing by luck.
+var testClock = time.Date(2024, time.June, 1, 23, 59, 30, 0, time.UTC)
+
+// evalExprAt evaluates input with the environment clock fixed at now.
+func evalExprAt(input string, now time.Time) Value {
+	expr, err := parser.New(lexer.New(input).AllTokens()).Parse()
+	if err != nil {
+		return NewError(err.Error())
+	}
+	env := NewEnvironment()
+	env.SetClock(FixedClock(now))
+	return New(env).Eval(expr)
+}
+
 // TestDateArithmeticWithKeywords tests date arithmetic using keywords like "today"
 func TestDateArithmeticWithKeywords(t *testing.T) {
-	now := time.Now()
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	today := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
 
 	tests := []struct {
 		name     string
@@ -34,7 +49,7 @@ func TestDateArithmeticWithKeywords(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := evalExpr(tt.input)
+			result := evalExprAt(tt.input, testClock)
 			if result.IsError() {
 				t.Errorf("got error: %s", result.Error)
 				return
@@ -45,12 +60,8 @@ func TestDateArithmeticWithKeywords(t *testing.T) {
 				return
 			}
 
-			// Compare dates (ignoring time component)
-			resultDate := time.Date(result.Date.Year(), result.Date.Month(), result.Date.Day(), 0, 0, 0, 0, result.Date.Location())
-			expectedDate := time.Date(tt.expected.Year(), tt.expected.Month(), tt.expected.Day(), 0, 0, 0, 0, tt.expected.Location())
-
-			if !resultDate.Equal(expectedDate) {
-				t.Errorf("expected %v, got %v", expectedDate.Format("2006-01-02"), resultDate.Format("2006-01-02"))
+			if !result.Date.Equal(tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected.Format("2006-01-02"), result.Date.Format("2006-01-02"))
 			}
 		})
 	}
@@ -186,12 +197,12 @@ func TestDateArithmeticWithLiterals(t *testing.T) {
 		{
 			name:     "31/01/2024 + 1 month",
 			input:    "31/01/2024 + 1 month",
-			expected: time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), // Go's AddDate behavior: Jan 31 + 1 month = Mar 2
+			expected: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), // Clamped to the end of February
 		},
 		{
 			name:     "29/02/2024 + 1 year",
 			input:    "29/02/2024 + 1 year",
-			expected: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), // Go's AddDate behavior: Feb 29 + 1 year = Mar 1 (2025 not leap)
+			expected: time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC), // Clamped, as 2025 is not a leap year
 		},
 	}
 
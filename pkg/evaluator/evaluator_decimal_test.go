@@ -0,0 +1,102 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/andrewneudegg/calc/pkg/lexer"
+	"github.com/andrewneudegg/calc/pkg/parser"
+)
+
+// decimalEvaluator returns an evaluator with decimal mode on.
+func decimalEvaluator() *Evaluator {
+	env := NewEnvironment()
+	env.SetDecimal(true)
+	return New(env)
+}
+
+func TestDecimalModeIsExact(t *testing.T) {
+	tests := []struct {
+		lines []string
+		want  string
+	}{
+		{[]string{"0.1 + 0.2"}, "0.3"},
+		{[]string{"£0.10 * 3 - £0.30"}, "0"},
+		{[]string{"1 - 0.9"}, "0.1"},
+		{[]string{"1.1 * 1.1"}, "1.21"},
+		{[]string{"0.7 + 0.1"}, "0.8"},
+		{[]string{"4.35 * 100"}, "435"},
+		// Division keeps 30 places, so a third times three falls just short
+		{[]string{"x = 1 / 3", "x * 3"}, "0.999999999999999999999999999999"},
+		{[]string{"-0.1 - 0.2"}, "-0.3"},
+		{[]string{"$19.99 * 3"}, "59.97"},
+		{[]string{"a = £0.01", "b = a + £0.01", "c = b + £0.01", "c - £0.03"}, "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.lines[len(tt.lines)-1], func(t *testing.T) {
+			v := evalLines(t, decimalEvaluator(), tt.lines...)
+			d, ok := v.Exact()
+			if !ok {
+				t.Fatalf("got %+v without a decimal value", v)
+			}
+			if d.String() != tt.want {
+				t.Errorf("got %s, want %s", d, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecimalModeLeavesOtherArithmeticAlone(t *testing.T) {
+	e := decimalEvaluator()
+	if v := evalLines(t, e, "10 kg + 1 kg"); v.Type != ValueUnit || v.Number != 11 {
+		t.Errorf("10 kg + 1 kg = %+v", v)
+	}
+	if v := evalLines(t, e, "1 / 0"); !v.IsError() || v.Error != "division by zero" {
+		t.Errorf("1 / 0 = %+v", v)
+	}
+	if v := evalLines(t, e, "£2 * £3"); !v.IsError() {
+		t.Errorf("£2 * £3 = %+v", v)
+	}
+	if v := evalLines(t, New(NewEnvironment()), "0.1 + 0.2"); v.Number == 0.3 {
+		t.Error("float mode should keep binary rounding error")
+	}
+}
+
+func TestSwitchingDecimalModeConvertsVariables(t *testing.T) {
+	env := NewEnvironment()
+	e := New(env)
+	evalLines(t, e, "a = 0.1", "rate = 5 m")
+
+	env.SetDecimal(true)
+	a, _ := env.GetVariable("a")
+	if d, ok := a.Exact(); !ok || d.String() != "0.1" {
+		t.Errorf("a after switching on = %+v", a)
+	}
+	if r, _ := env.GetVariable("rate"); r.exact != nil {
+		t.Errorf("unit variable gained a decimal value: %+v", r)
+	}
+
+	evalLines(t, e, "b = 1 / 3")
+	env.SetDecimal(false)
+	if b, _ := env.GetVariable("b"); b.exact != nil || b.Number != 1.0/3 {
+		t.Errorf("b after switching off = %+v", b)
+	}
+}
+
+// benchmarkArithmetic evaluates a chain of currency arithmetic, for
+// comparing decimal mode with float.
+func benchmarkArithmetic(b *testing.B, decimal bool) {
+	expr, err := parser.New(lexer.New("£19.99 * 3 + £0.10 * 7 - £4.35 / 3 + 1.1 * 1.1").AllTokens()).Parse()
+	if err != nil {
+		b.Fatal(err)
+	}
+	env := NewEnvironment()
+	env.SetDecimal(decimal)
+	e := New(env)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		e.Eval(expr)
+	}
+}
+
+func BenchmarkArithmeticFloat(b *testing.B)   { benchmarkArithmetic(b, false) }
+func BenchmarkArithmeticDecimal(b *testing.B) { benchmarkArithmetic(b, true) }
@@ -0,0 +1,59 @@
+package evaluator
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDerivedUnitSimplification(t *testing.T) {
+	tests := []struct {
+		input  string
+		number float64
+		unit   string
+	}{
+		{"10 n * 3 m", 30, "J"},
+		{"3 m * 10 n", 30, "J"},
+		{"2 kilonewtons * 50 cm", 1000, "J"},
+		{"100 j / 10 s", 10, "W"},
+		{"60 j / 1 min", 1, "W"},
+		{"2 kw * 3 hours", 2.16e7, "J"},
+		{"1 kg * 1 m / 1 s / 1 s", 1, "N"},
+		{"500 n / 2 sqm", 250, "Pa"},
+		{"10 n * 3 m in kj", 0.03, "kj"},
+		{"2 kw * 3 hours in kwh", 6, "kwh"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := evalExpr(tt.input)
+			if got.IsError() {
+				t.Fatalf("unexpected error: %s", got.Error)
+			}
+			if got.Unit != tt.unit || math.Abs(got.Number-tt.number) > 1e-9*tt.number {
+				t.Errorf("got %v %s, want %v %s", got.Number, got.Unit, tt.number, tt.unit)
+			}
+		})
+	}
+}
+
+func TestUnknownUnitCombinationsStayCompound(t *testing.T) {
+	tests := []struct {
+		input string
+		unit  string
+	}{
+		{"3 m * 4 m", "m·m"},
+		{"2 n * 3 kg", "n·kg"},
+		{"100 mb / 10 s", "mb/s"},
+		{"10 km / 2 hours", "km/hours"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := evalExpr(tt.input)
+			if got.IsError() {
+				t.Fatalf("unexpected error: %s", got.Error)
+			}
+			if got.Unit != tt.unit {
+				t.Errorf("got unit %q, want %q", got.Unit, tt.unit)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package evaluator
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDestructureTip(t *testing.T) {
+	e := New(NewEnvironment())
+	result := evalLines(t, e, "tip, bill = 15% tip on £42.50")
+	if result.IsError() {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+	if len(result.Items) != 2 || result.Items[0].Label != "tip" || result.Items[1].Label != "bill" {
+		t.Fatalf("result = %v, want items labelled tip and bill", result)
+	}
+
+	for name, want := range map[string]float64{"tip": 6.38, "bill": 48.88} {
+		got := evalLines(t, e, name)
+		if got.Type != ValueCurrency || got.Currency != "£" || math.Abs(got.Number-want) > 1e-9 {
+			t.Errorf("%s = %v, want £%g", name, got, want)
+		}
+		if got.Label != "" {
+			t.Errorf("%s keeps label %q", name, got.Label)
+		}
+	}
+}
+
+func TestDestructureConversions(t *testing.T) {
+	e := New(NewEnvironment())
+	evalLines(t, e, "usd, eur = £100 in usd, eur")
+	for name, direct := range map[string]string{"usd": "£100 in usd", "eur": "£100 in eur"} {
+		got, want := evalLines(t, e, name), evalLines(t, e, direct)
+		if got.Currency != want.Currency || math.Abs(got.Number-want.Number) > 1e-9 {
+			t.Errorf("%s = %v, want %v", name, got, want)
+		}
+	}
+
+	// A conversion after the result converts each part
+	evalLines(t, e, "t, tt = 10% tip on £40 in usd")
+	for name, direct := range map[string]string{"t": "£4 in usd", "tt": "£44 in usd"} {
+		got, want := evalLines(t, e, name), evalLines(t, e, direct)
+		if got.Currency != "$" || math.Abs(got.Number-want.Number) > 1e-9 {
+			t.Errorf("%s = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestDestructureDiscard(t *testing.T) {
+	e := New(NewEnvironment())
+	result := evalLines(t, e, "_, bill = 10% tip on £40")
+	if len(result.Items) != 1 || result.Items[0].Label != "bill" {
+		t.Fatalf("result = %v, want only bill", result)
+	}
+	if got := evalLines(t, e, "bill"); math.Abs(got.Number-44) > 1e-9 {
+		t.Errorf("bill = %v, want £44", got)
+	}
+	if e.env.HasVariable("_") {
+		t.Error("_ was assigned")
+	}
+}
+
+func TestDestructureArity(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"a, b = 100 in usd, eur, jpy", "expected 2 values, expression produced 3"},
+		{"a, b, x = 10% tip on £40", "expected 3 values, expression produced 2"},
+		{"a, b = 5", "expected 2 values, expression produced 1"},
+	}
+	for _, tt := range tests {
+		e := New(NewEnvironment())
+		result := evalLines(t, e, tt.input)
+		if !result.IsError() || result.Error != tt.want {
+			t.Errorf("%q: got %v, want error %q", tt.input, result, tt.want)
+		}
+		if e.env.HasVariable("a") {
+			t.Errorf("%q: a was assigned despite the error", tt.input)
+		}
+	}
+}
+
+func TestAssignListToOneName(t *testing.T) {
+	// A single name keeps every part, as before destructuring
+	e := New(NewEnvironment())
+	evalLines(t, e, "both = 10% tip on £40")
+	got := evalLines(t, e, "both")
+	if got.Type != ValueList || len(got.Items) != 2 {
+		t.Errorf("both = %v, want a list of two", got)
+	}
+}
@@ -0,0 +1,52 @@
+package evaluator
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDiscounts(t *testing.T) {
+	tests := []struct {
+		input string
+		want  float64
+	}{
+		{"20% off £80", 64},
+		{"£80 after 20% discount", 64},
+		{"10% off (20% off £100)", 72},
+		{"price = 20% off £80", 64},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := evalLines(t, New(NewEnvironment()), tt.input)
+			if got.Type != ValueCurrency || got.Currency != "£" {
+				t.Fatalf("got %+v, want a £ amount", got)
+			}
+			if math.Abs(got.Number-tt.want) > 1e-9 {
+				t.Errorf("got %v, want %v", got.Number, tt.want)
+			}
+		})
+	}
+}
+
+func TestTipGivesTipAndTotal(t *testing.T) {
+	for _, input := range []string{"15% tip on £42.50", "tip 15% on £42.50"} {
+		t.Run(input, func(t *testing.T) {
+			got := evalLines(t, New(NewEnvironment()), input)
+			if got.Type != ValueList || len(got.Items) != 2 {
+				t.Fatalf("got %+v, want the tip and the total", got)
+			}
+			tip, total := got.Items[0], got.Items[1]
+			if tip.Label != "tip" || tip.Currency != "£" || tip.Number != 6.38 {
+				t.Errorf("tip = %+v, want tip £6.38", tip)
+			}
+			if total.Label != "total" || total.Currency != "£" || math.Abs(total.Number-48.88) > 1e-9 {
+				t.Errorf("total = %+v, want total £48.88", total)
+			}
+		})
+	}
+
+	if got := evalLines(t, New(NewEnvironment()), "tip 10% on 3 kg"); !got.IsError() {
+		t.Errorf("tip on a weight = %+v, want an error", got)
+	}
+}
@@ -0,0 +1,61 @@
+package evaluator
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDurationLiterals(t *testing.T) {
+	tests := []struct {
+		input string
+		want  float64
+		unit  string
+	}{
+		{"1h30m", 1.5, "h"},
+		{"1h30m + 45m", 2.25, "h"},
+		{"45m + 1h30m", 135, "min"},
+		{"1h30m45s in s", 5445, "s"},
+		{"2s500ms", 2.5, "s"},
+		{"PT2H30M in minutes", 150, "minutes"},
+		{"P1DT12H in hours", 36, "hours"},
+		{"PT1.5H in min", 90, "min"},
+		{"P2W in days", 14, "days"},
+		{"90s in ms", 90000, "ms"},
+		{"2 * 1h30m", 3, "h"},
+	}
+	for _, tt := range tests {
+		got := evalLines(t, New(NewEnvironment()), tt.input)
+		if got.IsError() || got.Unit != tt.unit || math.Abs(got.Number-tt.want) > 1e-9 {
+			t.Errorf("%q = %v, want %g %s", tt.input, got, tt.want, tt.unit)
+		}
+	}
+
+	// Parts must still run from largest to smallest, and metres written
+	// apart from the number are not minutes
+	for _, input := range []string{"30m1h", "1h30m + 10 m", "10 m - 1h30m"} {
+		if got := evalLines(t, New(NewEnvironment()), input); !got.IsError() {
+			t.Errorf("%s = %v, want an error", input, got)
+		}
+	}
+}
+
+func TestDurationLiteralsOnDates(t *testing.T) {
+	at := time.Date(2024, time.January, 31, 9, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		input string
+		want  time.Time
+	}{
+		{"today + P1D", time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"now + 1h30m", time.Date(2024, time.January, 31, 10, 30, 0, 0, time.UTC)},
+		{"31/01/2024 + P1M", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+	} {
+		got := evalExprAt(tt.input, at)
+		if got.IsError() {
+			t.Fatalf("%s: %s", tt.input, got.Error)
+		}
+		if !got.Date.Equal(tt.want) {
+			t.Errorf("%s = %v, want %v", tt.input, got.Date, tt.want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package evaluator
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrewneudegg/calc/pkg/lexer"
+	"github.com/andrewneudegg/calc/pkg/parser"
+)
+
+// evalAtClock evaluates input in env with the clock fixed at clock.
+func evalAtClock(env *Environment, input string, clock time.Time) Value {
+	expr, err := parser.New(lexer.New(input).AllTokens()).Parse()
+	if err != nil {
+		return NewError(err.Error())
+	}
+	env.SetClock(FixedClock(clock))
+	return env.Eval(expr)
+}
+
+func TestElapsedSinceNow(t *testing.T) {
+	start := time.Date(2025, 3, 14, 9, 15, 0, 0, time.UTC)
+	later := start.Add(2*time.Hour + 30*time.Minute)
+
+	env := NewEnvironment()
+	if result := evalAtClock(env, "start = now", start); result.IsError() {
+		t.Fatalf("assign: %s", result.Error)
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		want    float64
+		unit    string
+		wantErr string
+	}{
+		{name: "now minus stored start", input: "now - start", want: 2.5, unit: "hours"},
+		{name: "stored start minus now", input: "start - now", want: -2.5, unit: "hours"},
+		{name: "converted to minutes", input: "(now - start) in minutes", want: 150, unit: "minutes"},
+		{name: "now minus clock time", input: "now - 09:00", want: 2.75, unit: "hours"},
+		{name: "clock time minus now", input: "17:30 - now", want: 5.75, unit: "hours"},
+		{name: "clock time with seconds", input: "now - 11:44:30", want: 1.0 / 120, unit: "hours"},
+		{name: "calendar dates stay in days", input: "15/03/2025 - 14/03/2025", want: 1, unit: "days"},
+		{name: "now plus elapsed clock span", input: "now - (now + (17:00 - 16:00))", want: -1, unit: "hours"},
+		{name: "adding a clock time to now", input: "now + 09:00", wantErr: "subtract them"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := evalAtClock(env, tt.input, later)
+			if tt.wantErr != "" {
+				if !result.IsError() || !strings.Contains(result.Error, tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, result)
+				}
+				return
+			}
+			if result.IsError() {
+				t.Fatalf("unexpected error: %s", result.Error)
+			}
+			if math.Abs(result.Number-tt.want) > 1e-9 {
+				t.Errorf("expected %v, got %v", tt.want, result.Number)
+			}
+			if result.Unit != tt.unit {
+				t.Errorf("expected unit %q, got %q", tt.unit, result.Unit)
+			}
+		})
+	}
+}
+
+func TestClockTimeUsesDateCalendarDay(t *testing.T) {
+	// Just after midnight, 23:00 refers to the evening that is still to come
+	clock := time.Date(2025, 3, 14, 0, 30, 0, 0, time.UTC)
+	result := evalAtClock(NewEnvironment(), "23:00 - now", clock)
+	if result.IsError() {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+	if result.Number != 22.5 || result.Unit != "hours" {
+		t.Errorf("expected 22.5 hours, got %v %s", result.Number, result.Unit)
+	}
+}
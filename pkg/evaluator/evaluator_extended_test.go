@@ -398,8 +398,7 @@ func TestExtendedFunctions(t *testing.T) {
 
 // TestDateKeywords tests date keywords
 func TestDateKeywords(t *testing.T) {
-	now := time.Now()
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	today := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
 	tomorrow := today.AddDate(0, 0, 1)
 	yesterday := today.AddDate(0, 0, -1)
 
@@ -413,7 +412,7 @@ func TestDateKeywords(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		result := evalExpr(tt.input)
+		result := evalExprAt(tt.input, testClock)
 		if result.IsError() {
 			t.Errorf("%q: got error %s", tt.input, result.Error)
 			continue
@@ -422,8 +421,7 @@ func TestDateKeywords(t *testing.T) {
 			t.Errorf("%q: expected date, got %v", tt.input, result.Type)
 			continue
 		}
-		// Compare just the date parts (year, month, day)
-		if !result.Date.Truncate(24 * time.Hour).Equal(tt.expected) {
+		if !result.Date.Equal(tt.expected) {
 			t.Errorf("%q: got %v, want %v", tt.input, result.Date, tt.expected)
 		}
 	}
@@ -0,0 +1,122 @@
+package evaluator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegisterFunction(t *testing.T) {
+	err := Register(Function{
+		Name:        "Double_It",
+		Description: "Twice the argument",
+		MinArgs:     1,
+		MaxArgs:     1,
+		Accepts:     []ValueType{ValueNumber, ValueCurrency},
+		Call: func(args []Value) Value {
+			v := args[0]
+			v.Number *= 2
+			return v
+		},
+	})
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	defer unregisterFunction("double_it")
+
+	if got := evalExpr("double_it(21) + 1"); got.IsError() || got.Number != 43 {
+		t.Errorf("double_it(21) + 1 = %+v, want 43", got)
+	}
+	if got := evalExpr("DOUBLE_IT(£5)"); got.Type != ValueCurrency || got.Number != 10 {
+		t.Errorf("DOUBLE_IT(£5) = %+v, want £10", got)
+	}
+
+	errors := map[string]string{
+		"double_it()":         "double_it requires exactly 1 argument",
+		"double_it(1, 2)":     "double_it requires exactly 1 argument",
+		"double_it(5 km)":     "double_it requires a plain number or a currency amount, not km",
+		"double_it(1 / 0)":    "division by zero",
+		"no_such_function(1)": "unknown function: no_such_function",
+	}
+	for input, want := range errors {
+		if got := evalExpr(input); !got.IsError() || got.Error != want {
+			t.Errorf("%s = %+v, want error %q", input, got, want)
+		}
+	}
+
+	var listed bool
+	for _, f := range Functions() {
+		if f.Name == "double_it" {
+			listed = true
+			if f.Builtin() || f.Usage() != "double_it(x)" {
+				t.Errorf("listed as %+v", f)
+			}
+		}
+	}
+	if !listed {
+		t.Error("Functions() does not list double_it")
+	}
+}
+
+func TestRegisterFunctionAnyArguments(t *testing.T) {
+	err := RegisterFunction("count", func(args []Value) Value {
+		return NewNumber(float64(len(args)))
+	})
+	if err != nil {
+		t.Fatalf("RegisterFunction: %v", err)
+	}
+	defer unregisterFunction("count")
+
+	if got := evalExpr("count(1, 2 km, \"three\")"); got.Number != 3 {
+		t.Errorf("count(...) = %+v, want 3", got)
+	}
+	if got := evalExpr("count()"); got.IsError() || got.Number != 0 {
+		t.Errorf("count() = %+v, want 0", got)
+	}
+}
+
+func TestRegisterFunctionRejects(t *testing.T) {
+	identity := func(args []Value) Value { return args[0] }
+	if err := RegisterFunction("twin", identity); err != nil {
+		t.Fatalf("RegisterFunction: %v", err)
+	}
+	defer unregisterFunction("twin")
+
+	tests := []struct {
+		name string
+		fn   Function
+		want string
+	}{
+		{"duplicate", Function{Name: "twin", MaxArgs: -1, Call: identity}, "already registered"},
+		{"duplicate in another case", Function{Name: "TWIN", MaxArgs: -1, Call: identity}, "already registered"},
+		{"nil fn", Function{Name: "nothing", MaxArgs: -1}, "no implementation"},
+		{"shadows a builtin", Function{Name: "sum", MaxArgs: -1, Call: identity}, "built-in"},
+		{"shadows a builtin alias", Function{Name: "Mean", MaxArgs: -1, Call: identity}, "built-in"},
+		{"empty name", Function{MaxArgs: -1, Call: identity}, "must be a word"},
+		{"not a word", Function{Name: "net-profit", MaxArgs: -1, Call: identity}, "must be a word"},
+		{"leading digit", Function{Name: "2x", MaxArgs: -1, Call: identity}, "must be a word"},
+		{"max below min", Function{Name: "backwards", MinArgs: 2, MaxArgs: 1, Call: identity}, "invalid number of arguments"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Register(tt.fn)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Register(%q) error = %v, want one containing %q", tt.fn.Name, err, tt.want)
+			}
+		})
+	}
+
+	if got := evalExpr("sum(1, 2)"); got.Number != 3 {
+		t.Errorf("sum(1, 2) = %+v after a shadow attempt, want 3", got)
+	}
+}
+
+// TestBuiltinFunctionsAreHandled keeps the list that registrations may not
+// shadow in step with the functions evalFunctionCall handles.
+func TestBuiltinFunctionsAreHandled(t *testing.T) {
+	for _, f := range builtinFunctions {
+		got := evalExpr(f.Name + "()")
+		if got.IsError() && strings.HasPrefix(got.Error, "unknown function") {
+			t.Errorf("%s is listed as built in but evaluates as %q", f.Name, got.Error)
+		}
+	}
+}
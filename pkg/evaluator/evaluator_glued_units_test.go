@@ -0,0 +1,46 @@
+package evaluator
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGluedUnits(t *testing.T) {
+	tests := []struct {
+		lines []string
+		want  float64
+		unit  string
+	}{
+		{[]string{"10km"}, 10, "km"},
+		{[]string{"2.5kg in lb"}, 5.5116, "lb"},
+		{[]string{"30c in f"}, 86, "f"},
+		{[]string{"t = 50 * 3mps"}, 150, "mps"},
+		{[]string{"1.5km"}, 1.5, "km"},
+		{[]string{"1.5k"}, 1.5, "k"},
+		{[]string{"5ft10 in cm"}, 177.8, "cm"},
+		{[]string{"5ft10in in cm"}, 177.8, "cm"},
+		{[]string{"6ft2 in cm"}, 187.96, "cm"},
+		{[]string{"6 ft2 in m2"}, 0.5574, "m2"},
+	}
+	for _, tt := range tests {
+		got := evalLines(t, New(NewEnvironment()), tt.lines...)
+		if got.IsError() {
+			t.Errorf("%q: %s", tt.lines, got.Error)
+			continue
+		}
+		if math.Abs(got.Number-tt.want) > 1e-3 || got.Unit != tt.unit {
+			t.Errorf("%q = %v %s, want %v %s", tt.lines, got.Number, got.Unit, tt.want, tt.unit)
+		}
+	}
+}
+
+func TestGluedMoneyUnits(t *testing.T) {
+	e := New(NewEnvironment())
+	if got := evalLines(t, e, "£3.50/hr * 40 hours"); got.Type != ValueCurrency || got.Number != 140 {
+		t.Errorf("£3.50/hr * 40 hours = %+v, want £140", got)
+	}
+	// Money takes k, m and bn as multipliers
+	if got := evalLines(t, e, "£1.2k/hr"); got.Number != 1200 || got.Unit != "£/hr" {
+		t.Errorf("£1.2k/hr = %v %s, want 1200 £/hr", got.Number, got.Unit)
+	}
+}
@@ -0,0 +1,50 @@
+package evaluator
+
+import (
+	"math"
+	"testing"
+)
+
+func TestInchesOrConversion(t *testing.T) {
+	tests := []struct {
+		input string
+		want  float64
+		unit  string
+	}{
+		{"5 ft 6 in", 5.5, "ft"},
+		{"5 ft + 12 in", 6, "ft"},
+		{"12 in in cm", 30.48, "cm"},
+		{"5 ft 6 in in cm", 167.64, "cm"},
+		{"5 ft 6 in * 2", 11, "ft"},
+		{"2 hours 30 minutes in minutes", 150, "minutes"},
+		{"10 m in cm", 1000, "cm"},
+	}
+	for _, tt := range tests {
+		got := evalLines(t, New(NewEnvironment()), tt.input)
+		if got.IsError() || got.Unit != tt.unit || math.Abs(got.Number-tt.want) > 1e-9 {
+			t.Errorf("%q = %v, want %g %s", tt.input, got, tt.want, tt.unit)
+		}
+	}
+
+	e := New(NewEnvironment())
+	evalLines(t, e, "x = 3 in")
+	if got := evalLines(t, e, "x in cm"); math.Abs(got.Number-7.62) > 1e-9 {
+		t.Errorf("x = 3 in, x in cm = %v, want 7.62 cm", got)
+	}
+}
+
+func TestConvertPlainNumber(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"12 in cm", "12 has no unit to convert to cm; for inches, write 12 in in cm"},
+		{"12 to kg", "12 has no unit to convert to kg"},
+	}
+	for _, tt := range tests {
+		got := evalLines(t, New(NewEnvironment()), tt.input)
+		if got.Error != tt.want {
+			t.Errorf("%q = %v, want error %q", tt.input, got, tt.want)
+		}
+	}
+}
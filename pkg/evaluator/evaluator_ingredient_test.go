@@ -0,0 +1,79 @@
+package evaluator
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/andrewneudegg/calc/pkg/lexer"
+	"github.com/andrewneudegg/calc/pkg/parser"
+)
+
+// evalIngredientLine evaluates line with ingredients recognised as the REPL
+// recognises them.
+func evalIngredientLine(t *testing.T, env *Environment, line string) Value {
+	t.Helper()
+	p := parser.New(lexer.NewWithUnits(line, env.IsUnit).AllTokens())
+	p.SetIngredientChecker(env.Units().IsIngredient)
+	expr, err := p.Parse()
+	if err != nil {
+		t.Fatalf("parse %q: %v", line, err)
+	}
+	return New(env).Eval(expr)
+}
+
+func TestIngredientConversions(t *testing.T) {
+	tests := []struct {
+		input  string
+		number float64
+		unit   string
+	}{
+		{"1 cup flour in g", 119.95, "g"},
+		{"250 g butter in cups", 1.10, "cups"},
+		{"2 cups sugar in kg", 0.40, "kg"},
+		{"(1 cup flour) * 2 in g", 239.90, "g"},
+		{"1 cup flour + 100 g flour", 1.83, "cup"},
+		{"1 cup flour in ml", 236.59, "ml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := evalIngredientLine(t, NewEnvironment(), tt.input)
+			if got.IsError() {
+				t.Fatalf("unexpected error: %s", got.Error)
+			}
+			if got.Unit != tt.unit || math.Abs(got.Number-tt.number) > 0.01 {
+				t.Errorf("got %.4f %s, want %.2f %s", got.Number, got.Unit, tt.number, tt.unit)
+			}
+		})
+	}
+}
+
+func TestIngredientErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"1 cup apples in g", `unknown ingredient "apples" (known: butter,`},
+		{"1 cup in g", "cannot convert cup to g"},
+		{"5 km flour in m", "flour is measured by mass or volume, not length"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := evalIngredientLine(t, NewEnvironment(), tt.input)
+			if !got.IsError() || !strings.HasPrefix(got.Error, tt.want) {
+				t.Errorf("got %+v, want error starting %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefinedIngredientConverts(t *testing.T) {
+	env := NewEnvironment()
+	if err := env.Units().DefineIngredient("cocoa", 0.52); err != nil {
+		t.Fatal(err)
+	}
+	got := evalIngredientLine(t, env, "1 cup cocoa in g")
+	if got.IsError() || math.Abs(got.Number-123.03) > 0.01 {
+		t.Errorf("1 cup cocoa in g = %+v, want 123.03 g", got)
+	}
+}
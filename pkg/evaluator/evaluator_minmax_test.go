@@ -0,0 +1,70 @@
+package evaluator
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMinMaxReturnTheWinningArgument(t *testing.T) {
+	tests := []struct {
+		input    string
+		number   float64
+		unit     string
+		currency string
+	}{
+		{"max(3 kg, 5 lb, 2000 g)", 3, "kg", ""},
+		{"min(3 kg, 5 lb, 2000 g)", 2000, "g", ""},
+		{"max(£10, £12, £11)", 12, "", "£"},
+		{"min(90 minutes, 2 hours)", 90, "minutes", ""},
+		{"max(1, 3 kg)", 3, "kg", ""},
+		{"max(3, 7, 2)", 7, "", ""},
+		// Ties keep the first occurrence
+		{"max(1 kg, 1000 g)", 1, "kg", ""},
+		{"min(1000 g, 1 kg)", 1000, "g", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := evalLines(t, New(NewEnvironment()), tt.input)
+			if got.IsError() {
+				t.Fatalf("unexpected error: %s", got.Error)
+			}
+			if got.Number != tt.number || got.Unit != tt.unit || got.Currency != tt.currency {
+				t.Errorf("got %v %q %q, want %v %q %q", got.Number, got.Unit, got.Currency, tt.number, tt.unit, tt.currency)
+			}
+		})
+	}
+}
+
+func TestMinMaxResultChains(t *testing.T) {
+	got := evalLines(t, New(NewEnvironment()), "max(3 kg, 5 lb, 2000 g) in lb")
+	if got.IsError() || got.Unit != "lb" || math.Abs(got.Number-6.61387) > 1e-4 {
+		t.Errorf("max(...) in lb = %+v", got)
+	}
+
+	e := New(NewEnvironment())
+	evalLines(t, e, "heaviest = max(3 kg, 5 lb, 2000 g)")
+	heaviest, _ := e.GetVariable("heaviest")
+	if heaviest.Number != 3 || heaviest.Unit != "kg" {
+		t.Errorf("heaviest = %+v, want 3 kg", heaviest)
+	}
+	if got := evalLines(t, e, "heaviest in g"); got.Number != 3000 || got.Unit != "g" {
+		t.Errorf("heaviest in g = %+v", got)
+	}
+}
+
+func TestMinMaxOfAList(t *testing.T) {
+	e := New(NewEnvironment())
+	e.env.SetVariable("weights", NewList([]Value{NewUnit(3, "kg"), NewUnit(5, "lb"), NewUnit(2000, "g")}))
+	if got := evalLines(t, e, "min(weights)"); got.Number != 2000 || got.Unit != "g" {
+		t.Errorf("min(weights) = %+v", got)
+	}
+}
+
+func TestMinMaxErrors(t *testing.T) {
+	for _, input := range []string{"max(3 kg, 2 m)", "min(£5, 3 kg)", `max("a", 2)`, "max(today, 3)"} {
+		if got := evalLines(t, New(NewEnvironment()), input); !got.IsError() {
+			t.Errorf("%s: expected an error, got %+v", input, got)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package evaluator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMonthArithmetic(t *testing.T) {
+	tests := []struct {
+		input    string
+		clamp    string
+		overflow string
+	}{
+		// Leap years
+		{"31/01/2024 + 1 month", "2024-02-29", "2024-03-02"},
+		{"30/01/2024 + 1 month", "2024-02-29", "2024-03-01"},
+		{"29/01/2024 + 1 month", "2024-02-29", "2024-02-29"},
+		{"31/01/2023 + 1 month", "2023-02-28", "2023-03-03"},
+		{"29/02/2024 + 1 year", "2025-02-28", "2025-03-01"},
+		{"29/02/2024 + 4 years", "2028-02-29", "2028-02-29"},
+		{"29/02/2024 - 1 year", "2023-02-28", "2023-03-01"},
+		{"31/03/2024 - 1 month", "2024-02-29", "2024-03-02"},
+		// 31 into 30-day months
+		{"31/05/2024 + 1 month", "2024-06-30", "2024-07-01"},
+		{"31/08/2024 + 1 month", "2024-09-30", "2024-10-01"},
+		{"31/12/2024 - 3 months", "2024-09-30", "2024-10-01"},
+		{"31/10/2024 + 13 months", "2025-11-30", "2025-12-01"},
+		// Days every month has are unchanged
+		{"15/01/2024 + 1 month", "2024-02-15", "2024-02-15"},
+		// Date arithmetic written in words
+		{"1 month after 31/01/2024", "2024-02-29", "2024-03-02"},
+		{"1 month before 31/03/2024", "2024-02-29", "2024-03-02"},
+		{"1 year after 29/02/2024", "2025-02-28", "2025-03-01"},
+	}
+
+	for _, tt := range tests {
+		for _, mode := range []struct {
+			overflow bool
+			want     string
+		}{{false, tt.clamp}, {true, tt.overflow}} {
+			env := NewEnvironment()
+			env.SetMonthOverflow(mode.overflow)
+			got := evalLines(t, New(env), tt.input)
+			if got.IsError() {
+				t.Fatalf("%s (overflow %v): %s", tt.input, mode.overflow, got.Error)
+			}
+			if s := got.Date.Format("2006-01-02"); s != mode.want {
+				t.Errorf("%s (overflow %v) = %s, want %s", tt.input, mode.overflow, s, mode.want)
+			}
+		}
+	}
+}
+
+func TestMonthArithmeticFromToday(t *testing.T) {
+	end := time.Date(2024, time.January, 31, 12, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		input string
+		want  string
+	}{
+		{"today + 1 month", "2024-02-29"},
+		{"today + 3 months", "2024-04-30"},
+		{"today - 2 months", "2023-11-30"},
+	} {
+		got := evalExprAt(tt.input, end)
+		if got.IsError() {
+			t.Fatalf("%s: %s", tt.input, got.Error)
+		}
+		if s := got.Date.Format("2006-01-02"); s != tt.want {
+			t.Errorf("%s on 31 Jan = %s, want %s", tt.input, s, tt.want)
+		}
+	}
+}
+
+func TestMonthArithmeticKeepsTime(t *testing.T) {
+	got := evalExprAt("now + 1 month", time.Date(2024, time.January, 31, 9, 30, 0, 0, time.UTC))
+	want := time.Date(2024, time.February, 29, 9, 30, 0, 0, time.UTC)
+	if !got.Date.Equal(want) {
+		t.Errorf("now + 1 month = %v, want %v", got.Date, want)
+	}
+}
@@ -0,0 +1,44 @@
+package evaluator
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMultiConversion(t *testing.T) {
+	result := parseAndEval("100 kg in lb, stone")
+	if result.Type != ValueList || len(result.Items) != 2 {
+		t.Fatalf("expected list of 2 items, got %+v", result)
+	}
+	if result.Items[0].Unit != "lb" || math.Abs(result.Items[0].Number-220.462) > 0.01 {
+		t.Errorf("expected ~220.46 lb, got %.2f %s", result.Items[0].Number, result.Items[0].Unit)
+	}
+	if result.Items[1].Unit != "stone" || math.Abs(result.Items[1].Number-15.747) > 0.01 {
+		t.Errorf("expected ~15.75 stone, got %.2f %s", result.Items[1].Number, result.Items[1].Unit)
+	}
+}
+
+func TestMultiConversionCurrency(t *testing.T) {
+	result := parseAndEval("£100 in usd, eur, jpy")
+	if result.Type != ValueList || len(result.Items) != 3 {
+		t.Fatalf("expected list of 3 items, got %+v", result)
+	}
+	for i, want := range []string{"$", "€", "¥"} {
+		if result.Items[i].Type != ValueCurrency || result.Items[i].Currency != want {
+			t.Errorf("item %d: expected currency %s, got %+v", i, want, result.Items[i])
+		}
+	}
+}
+
+func TestMultiConversionKeepsOtherTargetsOnError(t *testing.T) {
+	result := parseAndEval("100 kg in lb, furlongs, stone")
+	if result.Type != ValueList || len(result.Items) != 3 {
+		t.Fatalf("expected list of 3 items, got %+v", result)
+	}
+	if result.Items[0].IsError() || result.Items[2].IsError() {
+		t.Errorf("expected valid targets to convert, got %+v", result.Items)
+	}
+	if !result.Items[1].IsError() {
+		t.Errorf("expected unknown target to be an error, got %+v", result.Items[1])
+	}
+}
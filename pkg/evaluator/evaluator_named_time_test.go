@@ -0,0 +1,79 @@
+package evaluator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNamedTimesMatchClockLiterals(t *testing.T) {
+	tests := []struct {
+		named   string
+		literal string
+	}{
+		{"noon", "12:00"},
+		{"midday", "12:00"},
+		{"Noon", "12:00"},
+		{"midnight", "00:00"},
+		{"noon + 3 hours", "12:00 + 3 hours"},
+		{"noon - 90 minutes", "12:00 - 90 minutes"},
+		{"midnight - 00:30", "00:00 - 00:30"},
+		{"midnight + 30 minutes", "00:00 + 30 minutes"},
+		{"noon - midnight", "12:00 - 00:00"},
+		{"now - noon", "now - 12:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.named, func(t *testing.T) {
+			got := evalExprAt(tt.named, testClock)
+			want := evalExprAt(tt.literal, testClock)
+			if got.IsError() || want.IsError() {
+				t.Fatalf("%q = %v, %q = %v", tt.named, got.Error, tt.literal, want.Error)
+			}
+			if got.Type != want.Type || got.Number != want.Number || got.Unit != want.Unit || got.Elapsed != want.Elapsed {
+				t.Errorf("%q = %+v, want the same as %q = %+v", tt.named, got, tt.literal, want)
+			}
+		})
+	}
+}
+
+func TestDateAtClockTime(t *testing.T) {
+	tests := []struct {
+		input string
+		want  time.Time
+	}{
+		{"tomorrow at noon", time.Date(2024, time.June, 2, 12, 0, 0, 0, time.UTC)},
+		{"today at midnight", time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)},
+		{"yesterday at 18:30", time.Date(2024, time.May, 31, 18, 30, 0, 0, time.UTC)},
+		{"25/12/2025 at midday", time.Date(2025, time.December, 25, 12, 0, 0, 0, time.UTC)},
+		{"tomorrow at noon + 2 days", time.Date(2024, time.June, 4, 12, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := evalExprAt(tt.input, testClock)
+			if got.IsError() {
+				t.Fatalf("%q: unexpected error: %s", tt.input, got.Error)
+			}
+			if got.Type != ValueDate || !got.Date.Equal(tt.want) {
+				t.Errorf("%q = %v, want %v", tt.input, got.Date, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeInLocationAtClockTime(t *testing.T) {
+	// London is UTC+0 and Tokyo UTC+9 in the timezone table
+	got := evalExprAt("time in Tokyo at noon London time", testClock)
+	if got.IsError() {
+		t.Fatalf("unexpected error: %s", got.Error)
+	}
+	want := time.Date(2024, time.June, 1, 21, 0, 0, 0, time.UTC)
+	if !got.Date.Equal(want) {
+		t.Errorf("got %v, want %v", got.Date, want)
+	}
+
+	same := evalExprAt("time in Tokyo at 12:00 London time", testClock)
+	if !same.Date.Equal(got.Date) {
+		t.Errorf("noon and 12:00 differ: %v vs %v", got.Date, same.Date)
+	}
+}
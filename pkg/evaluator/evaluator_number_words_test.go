@@ -0,0 +1,58 @@
+package evaluator
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSpokenFractionsAndDecimals(t *testing.T) {
+	tests := []struct {
+		input string
+		want  float64
+		unit  string // unit or currency symbol of the result
+	}{
+		// Phrases that already worked
+		{"twenty one", 21, ""},
+		{"one hundred and five", 105, ""},
+		{"half of 80", 40, ""},
+		{"three quarters of 80", 60, ""},
+		{"three quarters of £80", 60, "£"},
+		{"five million", 5e6, ""},
+		{"twelve cm", 12, "cm"},
+		{"1 quarter in months", 3, "months"},
+		{"a quarter", 1, "quarter"},
+
+		{"one and a half", 1.5, ""},
+		{"two point five", 2.5, ""},
+		{"a third of 90", 30, ""},
+		{"three fifths of 200", 120, ""},
+		{"a quarter of 80", 20, ""},
+		{"three quarters", 0.75, ""},
+		{"half", 0.5, ""},
+		{"1 + three quarters of 8", 7, ""},
+		{"two thirds of £90", 60, "£"},
+		{"a tenth of 5 kg", 0.5, "kg"},
+		{"seven eighths of 1 km in m", 875, "m"},
+		{"one and a half hours in minutes", 90, "minutes"},
+		{"two and a quarter hours", 2.25, "hours"},
+		{"2 and a half hours", 2.5, "hours"},
+		{"two point five kg in g", 2500, "g"},
+		{"one point two five metres in cm", 125, "cm"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result := evalExpr(tt.input)
+			if result.IsError() {
+				t.Fatalf("error: %s", result.Error)
+			}
+			unit := result.Unit
+			if result.Type == ValueCurrency {
+				unit = result.Currency
+			}
+			if math.Abs(result.Number-tt.want) > 1e-9 || unit != tt.unit {
+				t.Errorf("got %v %q, want %v %q", result.Number, unit, tt.want, tt.unit)
+			}
+		})
+	}
+}
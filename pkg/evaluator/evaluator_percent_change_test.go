@@ -0,0 +1,85 @@
+package evaluator
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestIncreaseDecreaseByAmount(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+		kind     ValueType
+		unit     string // unit or currency symbol of the result
+	}{
+		// Percent operand (existing behaviour)
+		{"increase 100 by 10%", 110, ValueNumber, ""},
+		{"decrease £200 by 25%", 150, ValueCurrency, "£"},
+		{"increase 80 kg by 5%", 84, ValueUnit, "kg"},
+
+		// Plain number operand is absolute
+		{"increase 100 by 15", 115, ValueNumber, ""},
+		{"decrease 100 by 15", 85, ValueNumber, ""},
+		{"increase £2,000 by 150", 2150, ValueCurrency, "£"},
+		{"decrease 90 kg by 2.5", 87.5, ValueUnit, "kg"},
+
+		// Typed operand is converted to the base's unit or currency
+		{"increase £2,000 by £150", 2150, ValueCurrency, "£"},
+		{"decrease $100 by $40", 60, ValueCurrency, "$"},
+		{"decrease 90 kg by 2.5 kg", 87.5, ValueUnit, "kg"},
+		{"increase 1 km by 500 m", 1.5, ValueUnit, "km"},
+		{"decrease 2 hours by 30 minutes", 1.5, ValueUnit, "hours"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result := evalExpr(tt.input)
+			if result.IsError() {
+				t.Fatalf("unexpected error: %s", result.Error)
+			}
+			if result.Type != tt.kind {
+				t.Fatalf("expected type %v, got %v", tt.kind, result.Type)
+			}
+			if math.Abs(result.Number-tt.expected) > 0.0001 {
+				t.Errorf("expected %v, got %v", tt.expected, result.Number)
+			}
+			if got := result.Unit + result.Currency; got != tt.unit {
+				t.Errorf("expected unit %q, got %q", tt.unit, got)
+			}
+		})
+	}
+}
+
+func TestIncreaseByConvertedCurrency(t *testing.T) {
+	result := evalExpr("increase £100 by $10")
+	if result.IsError() || result.Currency != "£" {
+		t.Fatalf("expected a pound amount, got %v", result)
+	}
+	if result.Number <= 100 || result.Number >= 110 {
+		t.Errorf("expected $10 converted to pounds and added, got %v", result.Number)
+	}
+}
+
+func TestIncreaseDecreaseMismatches(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr string
+	}{
+		{"increase 100 by £5", "cannot increase a plain number by a currency amount"},
+		{"increase 100 by 5 kg", "cannot increase a plain number by kg"},
+		{"increase £5 by 2 kg", "cannot increase a currency amount by kg"},
+		{"decrease 5 kg by £2", "cannot decrease kg by a currency amount"},
+		{"increase 90 kg by 2 m", "cannot increase kg by m: incompatible units"},
+		{"decrease 90 kg by 2 m", "cannot decrease kg by m"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result := evalExpr(tt.input)
+			if !result.IsError() || !strings.Contains(result.Error, tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package evaluator
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBasisPointsAndPerMille(t *testing.T) {
+	tests := []struct {
+		input string
+		typ   ValueType
+		want  float64
+		unit  string
+	}{
+		// Money, the point of it all
+		{"£1,000,000 + 25 bps", ValueCurrency, 1002500, ""},
+		{"£1,000,000 - 25 bps", ValueCurrency, 997500, ""},
+		{"50 bps of £2m", ValueCurrency, 10000, ""},
+		{"3‰ of £1000", ValueCurrency, 3, ""},
+		{"£100 + 5 permille", ValueCurrency, 100.5, ""},
+		{"£200 * 50 bp", ValueCurrency, 1, ""},
+		{"increase £100 by 25 bps", ValueCurrency, 100.25, ""},
+
+		// Percentages keep per cent in Number and the unit they were written in
+		{"25 bps in %", ValuePercent, 0.25, ""},
+		{"0.25% in bps", ValuePercent, 0.25, "bps"},
+		{"3‰ in %", ValuePercent, 0.3, ""},
+		{"10% in ‰", ValuePercent, 10, "‰"},
+		{"5 permille in bps", ValuePercent, 0.5, "bps"},
+		{"25 bp in bps", ValuePercent, 0.25, "bps"},
+		{"0.05 in bps", ValuePercent, 5, "bps"},
+		{"25 bp", ValuePercent, 0.25, "bp"},
+		{"2‱", ValuePercent, 0.02, "‱"},
+
+		// Arithmetic between them goes in the left side's unit
+		{"25 bp + 10 bp", ValuePercent, 0.35, "bp"},
+		{"25 bps + 1%", ValuePercent, 1.25, "bps"},
+		{"1% + 25 bps", ValuePercent, 1.25, ""},
+		{"25 bp + 5", ValuePercent, 0.30, "bp"},
+		{"25 bp * 2", ValuePercent, 0.5, "bp"},
+		{"round(7.4 bp)", ValuePercent, 0.07, "bp"},
+
+		// On its own, or among data rates, bps is still bytes per second
+		{"1000 bps", ValueUnit, 1000, "bps"},
+		{"1 kbps in bps", ValueUnit, 1024, "bps"},
+		{"1000 bps + 24 bps", ValueUnit, 1024, "bps"},
+	}
+	for _, tt := range tests {
+		got := evalExpr(tt.input)
+		if got.IsError() {
+			t.Errorf("%s: %s", tt.input, got.Error)
+			continue
+		}
+		if got.Type != tt.typ || got.Unit != tt.unit || math.Abs(got.Number-tt.want) > 1e-9*math.Max(1, tt.want) {
+			t.Errorf("%s = %v %q (type %v), want %v %q (type %v)", tt.input, got.Number, got.Unit, got.Type, tt.want, tt.unit, tt.typ)
+		}
+	}
+}
+
+func TestPercentConversionErrors(t *testing.T) {
+	for _, input := range []string{"5 m in %", "£5 in bp"} {
+		if got := evalExpr(input); !got.IsError() {
+			t.Errorf("%s = %v %s, want an error", input, got.Number, got.Unit)
+		}
+	}
+}
+
+func TestScaledPercentString(t *testing.T) {
+	if got := evalExpr("25 bps in bp").String(); got != "25.00 bp" {
+		t.Errorf("25 bps in bp = %q, want 25.00 bp", got)
+	}
+	if got := evalExpr("3‰").String(); got != "3.00‰" {
+		t.Errorf("3‰ = %q, want 3.00‰", got)
+	}
+}
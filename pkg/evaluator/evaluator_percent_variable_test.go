@@ -0,0 +1,66 @@
+package evaluator
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestPercentInVariable(t *testing.T) {
+	tests := []struct {
+		input string
+		want  float64
+		kind  ValueType
+		unit  string // unit or currency symbol of the result
+	}{
+		{"£100 + vat", 120, ValueCurrency, "£"},
+		{"price + vat", 96, ValueCurrency, "£"},
+		{"price - vat", 64, ValueCurrency, "£"},
+		{"250 + vat", 300, ValueNumber, ""},
+		{"5 km - vat", 4, ValueUnit, "km"},
+		{"vat of 250", 50, ValueNumber, ""},
+		{"vat of price", 16, ValueCurrency, "£"},
+		{"vat of 5 km", 1, ValueUnit, "km"},
+		{"increase price by vat", 96, ValueCurrency, "£"},
+		{"decrease price by vat", 64, ValueCurrency, "£"},
+		{"vat * 2", 40, ValuePercent, ""},
+		{"vat / 4", 5, ValuePercent, ""},
+		{"vat + 5%", 25, ValuePercent, ""},
+		{"price * vat", 16, ValueCurrency, "£"},
+		{"vat * price", 16, ValueCurrency, "£"},
+		{"£16 / vat", 80, ValueCurrency, "£"},
+		{"vat / 40%", 0.5, ValueNumber, ""},
+		// The literal percentage behaves the same
+		{"£80 + 20%", 96, ValueCurrency, "£"},
+		{"20% * 2", 40, ValuePercent, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result := evalLines(t, New(NewEnvironment()), "vat = 20%", "price = £80", tt.input)
+			if result.IsError() {
+				t.Fatalf("unexpected error: %s", result.Error)
+			}
+			if result.Type != tt.kind {
+				t.Fatalf("expected type %v, got %v", tt.kind, result.Type)
+			}
+			if math.Abs(result.Number-tt.want) > 1e-9 {
+				t.Errorf("expected %v, got %v", tt.want, result.Number)
+			}
+			if got := result.Unit + result.Currency; got != tt.unit {
+				t.Errorf("expected unit %q, got %q", tt.unit, got)
+			}
+		})
+	}
+}
+
+func TestNamedPercentOfNeedsPercentage(t *testing.T) {
+	result := evalLines(t, New(NewEnvironment()), "rate = 0.2", "rate of 250")
+	if !result.IsError() || !strings.Contains(result.Error, "rate is not a percentage") {
+		t.Errorf("expected an error saying rate is not a percentage, got %+v", result)
+	}
+	result = evalLines(t, New(NewEnvironment()), "unset of 250")
+	if !result.IsError() || !strings.Contains(result.Error, "unset") {
+		t.Errorf("expected an undefined variable error, got %+v", result)
+	}
+}
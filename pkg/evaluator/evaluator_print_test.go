@@ -0,0 +1,79 @@
+package evaluator
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestPrintSpecifiers(t *testing.T) {
+	setup := []string{"total = £1234.5678", "w = 2 kg", "when = 25/12/2025", "n = 7"}
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"{total}", "£1234.57"},
+		{"{total:0}", "£1235"},
+		{"{ total : 3 }", "£1234.568"},
+		{"{w:unit=lb}", "4.41 lb"},
+		{"{w:unit=lb,1}", "4.4 lb"},
+		{"{total:unit=USD}", "$1567.90"},
+		{"{when}", "25 Dec 2025"},
+		{"{when:iso}", "2025-12-25"},
+		{"{when:weekday}", "Thursday"},
+		{"{n:1}%", "7.0%"},
+		{"{{n}} is {n}", "{n} is 7.00"},
+		{"}} and {{", "} and {"},
+		{"{} and {", "{} and {"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			lines := append(append([]string{}, setup...), fmt.Sprintf("print(%q)", tt.template))
+			v := evalLines(t, New(NewEnvironment()), lines...)
+			if v.IsError() {
+				t.Fatalf("unexpected error: %s", v.Error)
+			}
+			if v.Text != tt.want {
+				t.Errorf("got %q, want %q", v.Text, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintSpecifierErrors(t *testing.T) {
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"{total:xyz}", `placeholder {total:xyz}: unknown format "xyz"`},
+		{"{total:iso}", "placeholder {total:iso}: iso needs a date"},
+		{"{total:unit=kg}", "placeholder {total:unit=kg}: "},
+		{"{total:2,}", "placeholder {total:2,}: empty format specifier"},
+		{"{:2}", "placeholder {:2}: missing a variable name"},
+		{"{nope}", "placeholder {nope}: "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			v := evalLines(t, New(NewEnvironment()), "total = £12", fmt.Sprintf("print(%q)", tt.template))
+			if !v.IsError() || !strings.HasPrefix(v.Error, tt.want) {
+				t.Errorf("got %+v, want an error starting %q", v, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintUsesFormatFunc(t *testing.T) {
+	env := NewEnvironment()
+	var gotPlaces []int
+	env.SetFormatFunc(func(v Value, places int) string {
+		gotPlaces = append(gotPlaces, places)
+		return "<" + v.stringPlaces(places) + ">"
+	})
+	v := evalLines(t, New(env), "x = 3", `print("{x} {x:4}")`)
+	if v.Text != "<3.00> <3.0000>" {
+		t.Errorf("got %q", v.Text)
+	}
+	if fmt.Sprint(gotPlaces) != "[-1 4]" {
+		t.Errorf("expected places -1 then 4, got %v", gotPlaces)
+	}
+}
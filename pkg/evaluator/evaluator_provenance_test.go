@@ -0,0 +1,36 @@
+package evaluator
+
+import "testing"
+
+func TestProvenanceIsOptional(t *testing.T) {
+	v := NewNumber(3)
+	if _, ok := v.Provenance(); ok {
+		t.Fatal("new values should have no provenance")
+	}
+
+	marked := v.WithProvenance(Provenance{Input: "total = a + b", Line: 7, Assign: true})
+	p, ok := marked.Provenance()
+	if !ok || p.Line != 7 || !p.Assign {
+		t.Fatalf("Provenance() = %+v, %v", p, ok)
+	}
+	if got, want := p.String(), "line 7: `total = a + b`"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if _, ok := v.Provenance(); ok {
+		t.Error("WithProvenance should not change the original value")
+	}
+}
+
+func TestPrevDropsProvenance(t *testing.T) {
+	env := NewEnvironment()
+	line := NewNumber(10).WithProvenance(Provenance{Input: "10", Line: 1})
+	env.SetHistoryFunc(func(int) (Value, error) { return line, nil })
+	env.SetAbsoluteHistoryFunc(func(int) (Value, error) { return line, nil })
+
+	for _, input := range []string{"prev", "prev#1"} {
+		got := evalLines(t, New(env), input)
+		if _, ok := got.Provenance(); ok {
+			t.Errorf("%s kept the earlier line's provenance", input)
+		}
+	}
+}
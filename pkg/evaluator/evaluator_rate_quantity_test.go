@@ -0,0 +1,126 @@
+package evaluator
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRatePerQuantity(t *testing.T) {
+	tests := []struct {
+		input    string
+		want     float64
+		currency string
+		unit     string
+	}{
+		{"£1.89 per 100g", 0.0189, "", "£/g"},
+		{"£1.89 per 100g in gbp/kg", 18.9, "£", ""},
+		{"£1.89 per 100 g in £/kg", 18.9, "£", ""},
+		{"£2 per 500ml in £/l", 4, "£", ""},
+		{"£2 per 500 ml in £/ml", 0.004, "£", ""},
+		{"6 l per 100 km in l/km", 0.06, "", "l/km"},
+		{"£1.89 per 100g * 500 g", 9.45, "£", ""},
+		{"500 g * £1.89 per 100g", 9.45, "£", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := evalLines(t, New(NewEnvironment()), tt.input)
+			if got.IsError() {
+				t.Fatalf("unexpected error: %s", got.Error)
+			}
+			if got.Currency != tt.currency || got.Unit != tt.unit {
+				t.Errorf("got currency %q unit %q, want %q %q", got.Currency, got.Unit, tt.currency, tt.unit)
+			}
+			if math.Abs(got.Number-tt.want) > 1e-9 {
+				t.Errorf("got %v, want %v", got.Number, tt.want)
+			}
+		})
+	}
+}
+
+func TestRatePerQuantityKeepsQuote(t *testing.T) {
+	got := evalLines(t, New(NewEnvironment()), "£1.89 per 100g")
+	if got.Per != 100 {
+		t.Errorf("got Per %v, want 100", got.Per)
+	}
+	converted := evalLines(t, New(NewEnvironment()), "£1.89 per 100g in £/kg")
+	if converted.Per != 0 {
+		t.Errorf("converted rate kept Per %v", converted.Per)
+	}
+}
+
+func TestComparePricesPerQuantity(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string // unit of the winning argument
+	}{
+		{"min(£1.89 per 100g, £15 per kg)", "£/kg"},
+		{"max(£1.89 per 100g, £15 per kg)", "£/g"},
+		{"min(£0.90 per 500ml, £2 per l)", "£/ml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := evalLines(t, New(NewEnvironment()), tt.input)
+			if got.IsError() {
+				t.Fatalf("unexpected error: %s", got.Error)
+			}
+			if got.Unit != tt.want {
+				t.Errorf("got %v %s, want the %s price", got.Number, got.Unit, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateTimesRate(t *testing.T) {
+	tests := []struct {
+		input string
+		want  float64
+		unit  string
+		per   float64
+	}{
+		{"£12.50/hr * 37.5 hours per week", 468.75, "£/week", 0},
+		{"37.5 hours per week * £12.50/hr", 468.75, "£/week", 0},
+		{"£12.50 per hour * 7.5 hours per day", 93.75, "£/day", 0},
+		{"£0.20/min * 2 hours per day", 24, "£/day", 0},
+		{"£12.50/hr * 75 hours per 2 weeks", 468.75, "£/weeks", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := evalLines(t, New(NewEnvironment()), tt.input)
+			if got.IsError() {
+				t.Fatalf("unexpected error: %s", got.Error)
+			}
+			if got.Type != ValueUnit || got.Unit != tt.unit || got.Per != tt.per {
+				t.Errorf("got unit %q per %v, want %q per %v", got.Unit, got.Per, tt.unit, tt.per)
+			}
+			if math.Abs(got.Number-tt.want) > 1e-9 {
+				t.Errorf("got %v, want %v", got.Number, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnnualisedRates(t *testing.T) {
+	tests := []struct {
+		input string
+		want  float64
+	}{
+		{"£45,000 pa in gbp/month", 3750},
+		{"£45,000 per annum in £/week", 45000 * 7 / 365.25},
+		{"£12.50/hr * 37.5 hours per week in gbp/year", 468.75 * 365.25 / 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := evalLines(t, New(NewEnvironment()), tt.input)
+			if got.IsError() {
+				t.Fatalf("unexpected error: %s", got.Error)
+			}
+			if got.Type != ValueCurrency || math.Abs(got.Number-tt.want) > 1e-6 {
+				t.Errorf("got %+v, want £%v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package evaluator
+
+import "testing"
+
+func TestConversionRecordsRate(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"£100 in usd", []string{"1 GBP = 1.27 USD"}},
+		{"(£100 in usd) * 2", []string{"1 GBP = 1.27 USD"}},
+		{"£100 in usd + £50 in usd", []string{"1 GBP = 1.27 USD"}},
+		{"-(£100 in usd)", []string{"1 GBP = 1.27 USD"}},
+		{"£100 + $50", []string{"1 USD = 0.7874 GBP"}},
+		{"£100 in usd in eur", []string{"1 GBP = 1.27 USD", "1 USD = 0.9091 EUR"}},
+		{"£100 in gbp", nil},
+		{"£100 + £50", nil},
+		{"£100 * 2", nil},
+		{"10 km in miles", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := evalLines(t, New(NewEnvironment()), tt.input)
+			if got.IsError() {
+				t.Fatalf("unexpected error: %s", got.Error)
+			}
+			if len(got.Rates) != len(tt.want) {
+				t.Fatalf("got rates %v, want %v", got.Rates, tt.want)
+			}
+			for i, r := range got.Rates {
+				if r.String() != tt.want[i] {
+					t.Errorf("rate %d = %q, want %q", i, r.String(), tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRateDroppedWhenResultIsNotCurrency(t *testing.T) {
+	got := evalLines(t, New(NewEnvironment()), "(£100 in usd) / $1")
+	if got.Type != ValueNumber || len(got.Rates) != 0 {
+		t.Errorf("expected a plain number without rates, got %+v", got)
+	}
+}
@@ -0,0 +1,60 @@
+package evaluator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRelativeDatePhrases(t *testing.T) {
+	today := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		input    string
+		expected time.Time
+	}{
+		{"3 weeks from today", today.AddDate(0, 0, 21)},
+		{"2 days ago", today.AddDate(0, 0, -2)},
+		{"a day ago", today.AddDate(0, 0, -1)},
+		{"4 days after 25/12/2025", time.Date(2025, 12, 29, 0, 0, 0, 0, time.Local)},
+		{"a week before 01/03/2024", time.Date(2024, 2, 23, 0, 0, 0, 0, time.Local)},
+		{"2 months from 31/01/2025", time.Date(2025, 3, 31, 0, 0, 0, 0, time.Local)},
+		{"in 3 weeks", today.AddDate(0, 0, 21)},
+		{"1 year from now", today.AddDate(1, 0, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result := evalExprAt(tt.input, testClock)
+			if result.IsError() {
+				t.Fatalf("unexpected error: %s", result.Error)
+			}
+			if result.Type != ValueDate {
+				t.Fatalf("expected date, got %v", result)
+			}
+			if !result.Date.Equal(tt.expected) {
+				t.Errorf("expected %s, got %s", tt.expected, result.Date)
+			}
+		})
+	}
+}
+
+func TestRelativeDateSubDayUnitsCountFromNow(t *testing.T) {
+	result := evalExprAt("an hour ago", testClock)
+	if result.IsError() {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+	if want := testClock.Add(-time.Hour); !result.Date.Equal(want) {
+		t.Errorf("expected %s, got %s", want, result.Date)
+	}
+}
+
+func TestRelativeDateBeforeWeekday(t *testing.T) {
+	// 1 June 2024 is a Saturday, so next Friday is 7 June
+	result := evalExprAt("a week before next friday", testClock)
+	if result.IsError() {
+		t.Fatalf("unexpected error: %v", result)
+	}
+	if want := time.Date(2024, time.May, 31, 0, 0, 0, 0, time.UTC); !result.Date.Equal(want) {
+		t.Errorf("expected %s, got %s", want, result.Date)
+	}
+}
@@ -0,0 +1,92 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/andrewneudegg/calc/pkg/rounding"
+)
+
+func TestRoundFunctionsFollowRoundingMode(t *testing.T) {
+	tests := []struct {
+		mode  rounding.Mode
+		input string
+		want  float64
+	}{
+		{rounding.HalfUp, "round(2.5)", 3},
+		{rounding.HalfUp, "round(-2.5)", -3},
+		{rounding.HalfEven, "round(2.5)", 2},
+		{rounding.HalfEven, "round(-2.5)", -2},
+		{rounding.HalfEven, "round(3.5)", 4},
+		{rounding.Floor, "round(2.5)", 2},
+		{rounding.Floor, "round(-2.5)", -3},
+		{rounding.Ceil, "round(2.5)", 3},
+		{rounding.Ceil, "round(-2.5)", -2},
+		{rounding.Truncate, "round(2.5)", 2},
+		{rounding.Truncate, "round(-2.5)", -2},
+		{rounding.HalfUp, "round(3.14159, 3)", 3.142},
+		{rounding.Floor, "round(3.14159, 3)", 3.141},
+		{rounding.HalfUp, "roundto(17, 5)", 15},
+		{rounding.Ceil, "roundto(17, 5)", 20},
+		{rounding.HalfEven, "roundto(12.5, 5)", 10},
+		{rounding.HalfUp, "roundto(£12.37, 0.05)", 12.35},
+		{rounding.HalfUp, "roundto(£12.37, £0.05)", 12.35},
+		{rounding.HalfUp, "roundto(1234 m, 1 km)", 1000},
+		{rounding.HalfUp, "roundcash(£12.345)", 12.35},
+		{rounding.HalfEven, "roundcash(£12.345)", 12.34},
+		{rounding.HalfUp, "roundcash(¥1234.5)", 1235},
+		{rounding.HalfUp, "trunc(2.7)", 2},
+		{rounding.HalfUp, "trunc(-2.7)", -2},
+		{rounding.Ceil, "trunc(2.7)", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode)+" "+tt.input, func(t *testing.T) {
+			env := NewEnvironment()
+			env.SetRounding(tt.mode)
+			got := evalLines(t, New(env), tt.input)
+			if got.IsError() {
+				t.Fatalf("unexpected error: %s", got.Error)
+			}
+			if got.Number != tt.want {
+				t.Errorf("got %v, want %v", got.Number, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoundKeepsUnitAndCurrency(t *testing.T) {
+	got := evalLines(t, New(NewEnvironment()), "round(2.6 km)")
+	if got.Type != ValueUnit || got.Unit != "km" || got.Number != 3 {
+		t.Errorf("round(2.6 km) = %+v", got)
+	}
+	got = evalLines(t, New(NewEnvironment()), "roundto(£12.37, 0.05)")
+	if got.Type != ValueCurrency || got.Currency != "£" {
+		t.Errorf("roundto(£12.37, 0.05) = %+v", got)
+	}
+}
+
+func TestRoundLeavesVariablesUnrounded(t *testing.T) {
+	e := New(NewEnvironment())
+	evalLines(t, e, "x = 2.5", "round(x)")
+	if x, _ := e.GetVariable("x"); x.Number != 2.5 {
+		t.Errorf("x = %v after round(x), want 2.5", x.Number)
+	}
+}
+
+func TestRoundErrors(t *testing.T) {
+	tests := []string{
+		"round()",
+		"round(2.5, 1.5)",
+		"roundto(17, 0)",
+		"roundto(17)",
+		"roundto(17 m, £1)",
+		"roundcash(12.345)",
+		"trunc(1, 2)",
+		`round("text")`,
+	}
+	for _, input := range tests {
+		if got := evalLines(t, New(NewEnvironment()), input); !got.IsError() {
+			t.Errorf("%s: expected an error, got %+v", input, got)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package evaluator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestShadowWarning(t *testing.T) {
+	env := NewEnvironment()
+	tests := []struct {
+		name string
+		want string // part of the warning; "" for none
+	}{
+		{"m", "the unit metres"},
+		{"km", "the unit km"},
+		{"usd", "a currency"},
+		{"total", "the function total()"},
+		{"average", "the function average()"},
+		{"today", "reading today gives the keyword's meaning"},
+		{"max", "the function max()"},
+		{"in", "a keyword"},
+		{"noon", "a keyword"},
+		{"rent", ""},
+		{"x", ""},
+	}
+	for _, tt := range tests {
+		got := env.ShadowWarning(tt.name)
+		if tt.want == "" && got != "" || !strings.Contains(got, tt.want) {
+			t.Errorf("ShadowWarning(%q) = %q, want it to mention %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package evaluator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrewneudegg/calc/pkg/rounding"
+)
+
+func TestShareDividesEqually(t *testing.T) {
+	tests := []struct {
+		input    string
+		want     float64
+		currency string
+		unit     string
+	}{
+		{"£86.40 between 4", 21.6, "£", ""},
+		{"split £86.40 between 4", 21.6, "£", ""},
+		{"£86.40 / 4 each", 21.6, "£", ""},
+		{"£86.40 per person for 4 people", 21.6, "£", ""},
+		{"£86.40 between 4 people", 21.6, "£", ""},
+		{"£86.40 / 4 people", 21.6, "£", ""},
+		{"12 m between 4 people", 3, "", "m"},
+		{"£21.60/person * 4 people", 86.4, "£", ""},
+		{"4 people * £21.60/person", 86.4, "£", ""},
+		{"£10/kg * 500 g", 5, "£", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := evalLines(t, New(NewEnvironment()), tt.input)
+			if got.IsError() {
+				t.Fatalf("unexpected error: %s", got.Error)
+			}
+			if got.Currency != tt.currency || got.Unit != tt.unit {
+				t.Errorf("got currency %q unit %q, want %q %q", got.Currency, got.Unit, tt.currency, tt.unit)
+			}
+			if diff := got.Number - tt.want; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("got %v, want %v", got.Number, tt.want)
+			}
+			if got.Warning != "" {
+				t.Errorf("unexpected warning: %s", got.Warning)
+			}
+		})
+	}
+}
+
+func TestShareWarnsAboutRemainders(t *testing.T) {
+	tests := []struct {
+		mode  rounding.Mode
+		input string
+		want  string
+	}{
+		{rounding.HalfUp, "£100 between 3", "3 × £33.33 = £99.99 (£0.01 left over)"},
+		{rounding.HalfUp, "£200 between 3", "3 × £66.67 = £200.01 (£0.01 over)"},
+		{rounding.Floor, "£200 between 3", "3 × £66.66 = £199.98 (£0.02 left over)"},
+		{rounding.HalfUp, "£10 / 6 each", "6 × £1.67 = £10.02 (£0.02 over)"},
+		{rounding.HalfUp, "¥1000 between 3 people", "3 × ¥333 = ¥999 (¥1 left over)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode)+" "+tt.input, func(t *testing.T) {
+			env := NewEnvironment()
+			env.SetRounding(tt.mode)
+			got := evalLines(t, New(env), tt.input)
+			if got.IsError() {
+				t.Fatalf("unexpected error: %s", got.Error)
+			}
+			if !strings.Contains(got.Warning, tt.want) {
+				t.Errorf("got warning %q, want it to contain %q", got.Warning, tt.want)
+			}
+		})
+	}
+}
+
+func TestShareErrors(t *testing.T) {
+	for _, input := range []string{"£10 between 0", "£10 between 2 kg", "£10 between -2"} {
+		if got := evalLines(t, New(NewEnvironment()), input); !got.IsError() {
+			t.Errorf("%s: expected an error, got %+v", input, got)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package evaluator
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSIPrefixesAndScientificNotation(t *testing.T) {
+	tests := []struct {
+		input string
+		want  float64
+		unit  string
+	}{
+		{"3 nm in m", 3e-9, "m"},
+		{"2 GPa in psi", 2e9 / 6894.76, "psi"},
+		{"5 µm + 1 mm", 1005, "µm"},
+		{"1 min in s", 60, "s"},
+		{"3e-6 m in µm", 3, "µm"},
+		{"1.5e3 g in kg", 1.5, "kg"},
+		{"2 * 1e-3", 0.002, ""},
+	}
+	for _, tt := range tests {
+		got := evalLines(t, New(NewEnvironment()), tt.input)
+		if got.IsError() {
+			t.Errorf("%s: %s", tt.input, got.Error)
+			continue
+		}
+		if math.Abs(got.Number-tt.want) > math.Abs(tt.want)*1e-9 || got.Unit != tt.unit {
+			t.Errorf("%s = %g %s, want %g %s", tt.input, got.Number, got.Unit, tt.want, tt.unit)
+		}
+	}
+}
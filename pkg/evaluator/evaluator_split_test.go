@@ -0,0 +1,89 @@
+package evaluator
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSplitInRatio(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []float64
+		typ      ValueType
+	}{
+		{"split £900 in ratio 2:3:4", []float64{200, 300, 400}, ValueCurrency},
+		{"split 180 kg in ratio 1:2", []float64{60, 120}, ValueUnit},
+		{"split 100 in ratio 1:3", []float64{25, 75}, ValueNumber},
+		{"split £100 in ratio 1.5:2.5", []float64{37.5, 62.5}, ValueCurrency},
+		{"split £100 in ratio 1:2.5:1.5", []float64{20, 50, 30}, ValueCurrency},
+		{"split £100 as 70/30", []float64{70, 30}, ValueCurrency},
+		{"split £100 as 50/30/20", []float64{50, 30, 20}, ValueCurrency},
+	}
+
+	for _, tt := range tests {
+		result := parseAndEval(tt.input)
+		if result.IsError() {
+			t.Errorf("input %q: unexpected error: %s", tt.input, result.Error)
+			continue
+		}
+		if result.Type != ValueList || len(result.Items) != len(tt.expected) {
+			t.Errorf("input %q: expected list of %d items, got %+v", tt.input, len(tt.expected), result)
+			continue
+		}
+		for i, item := range result.Items {
+			if item.Type != tt.typ {
+				t.Errorf("input %q: item %d expected type %v, got %v", tt.input, i, tt.typ, item.Type)
+			}
+			if math.Abs(item.Number-tt.expected[i]) > 1e-9 {
+				t.Errorf("input %q: item %d expected %.2f, got %.2f", tt.input, i, tt.expected[i], item.Number)
+			}
+		}
+	}
+}
+
+func TestSplitCurrencyIsPennyExact(t *testing.T) {
+	result := parseAndEval("split £100 in ratio 1:1:1")
+	if result.IsError() {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+	want := []float64{33.34, 33.33, 33.33}
+	var sum float64
+	for i, item := range result.Items {
+		if math.Abs(item.Number-want[i]) > 1e-9 {
+			t.Errorf("item %d: expected %.2f, got %.4f", i, want[i], item.Number)
+		}
+		sum += item.Number
+	}
+	if math.Abs(sum-100) > 1e-9 {
+		t.Errorf("shares should sum to 100, got %.4f", sum)
+	}
+}
+
+func TestSplitRejectsZeroRatio(t *testing.T) {
+	result := parseAndEval("split £100 in ratio 0:0")
+	if !result.IsError() {
+		t.Fatalf("expected error for all-zero ratio, got %+v", result)
+	}
+}
+
+func TestSplitUsesCurrencyMinorUnits(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []float64
+	}{
+		{"split ¥1000 in ratio 1:2", []float64{333, 667}},
+		{"split 1000 krw in ratio 1:1:1", []float64{334, 333, 333}},
+		{"split 10 kwd in ratio 1:2", []float64{3.333, 6.667}},
+	}
+	for _, tt := range tests {
+		result := parseAndEval(tt.input)
+		if result.IsError() {
+			t.Fatalf("%s: unexpected error: %s", tt.input, result.Error)
+		}
+		for i, item := range result.Items {
+			if math.Abs(item.Number-tt.want[i]) > 1e-9 {
+				t.Errorf("%s: item %d = %v, want %v", tt.input, i, item.Number, tt.want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package evaluator
+
+import "testing"
+
+func TestNonStrictReadsUndefinedAsZero(t *testing.T) {
+	env := NewEnvironment()
+	e := New(env)
+	if v := evalLines(t, e, "missing + 1"); !v.IsError() {
+		t.Errorf("strict: missing + 1 = %+v, want an error", v)
+	}
+
+	env.SetStrict(false)
+	if v := evalLines(t, e, "a + b * 2 + A"); v.IsError() || v.Number != 0 {
+		t.Errorf("a + b * 2 + A = %+v, want 0", v)
+	}
+	if got := env.TakeUndefined(); len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("TakeUndefined() = %q, want [a b]", got)
+	}
+	if got := env.TakeUndefined(); got != nil {
+		t.Errorf("second TakeUndefined() = %q, want none", got)
+	}
+
+	if v := evalLines(t, e, "a = a + 5", "a * 2"); v.Number != 10 {
+		t.Errorf("a * 2 after a = a + 5 = %+v, want 10", v)
+	}
+}
@@ -0,0 +1,81 @@
+package evaluator
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestTagAggregates(t *testing.T) {
+	tagged := map[string][]Value{
+		"food":   {NewCurrency(3.20, "£"), NewCurrency(12.70, "$"), NewCurrency(5, "£")},
+		"travel": {NewUnit(5, "km"), NewUnit(500, "m"), NewNumber(1)},
+		"misc":   {NewNumber(2), NewCurrency(10, "€")},
+	}
+	env := NewEnvironment()
+	env.SetTagFunc(func(tag string) []Value { return tagged[tag] })
+
+	tests := []struct {
+		input string
+		want  float64
+		kind  ValueType
+		unit  string
+	}{
+		{"sum #food", 18.20, ValueCurrency, "£"},
+		{"average #food", 18.20 / 3, ValueCurrency, "£"},
+		{"sum #food in usd", 18.20 * 1.27, ValueCurrency, "$"},
+		{"sum #travel", 6.5, ValueUnit, "km"},
+		{"sum #misc", 12, ValueCurrency, "€"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := evalLines(t, New(env), tt.input)
+			if got.IsError() {
+				t.Fatalf("unexpected error: %s", got.Error)
+			}
+			unit := got.Unit
+			if got.Type == ValueCurrency {
+				unit = got.Currency
+			}
+			if got.Type != tt.kind || unit != tt.unit || math.Abs(got.Number-tt.want) > 1e-9 {
+				t.Errorf("got %v %s, want %v %s", got.Number, unit, tt.want, tt.unit)
+			}
+		})
+	}
+
+	// The dollars were converted, so their rate comes with the total
+	if got := evalLines(t, New(env), "sum #food"); len(got.Rates) != 1 || got.Rates[0].String() != "1 USD = 0.7874 GBP" {
+		t.Errorf("expected the USD rate, got %v", got.Rates)
+	}
+}
+
+func TestTagAggregateErrors(t *testing.T) {
+	env := NewEnvironment()
+	env.SetTagFunc(func(tag string) []Value {
+		if tag == "mixed" {
+			return []Value{NewCurrency(3, "£"), NewUnit(5, "km")}
+		}
+		return nil
+	})
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"sum #mixed", "sum #mixed: cannot add 5.00 km to a currency amount"},
+		{"average #none", "no lines tagged #none"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := evalLines(t, New(env), tt.input)
+			if !got.IsError() || !strings.Contains(got.Error, tt.want) {
+				t.Errorf("expected an error containing %q, got %+v", tt.want, got)
+			}
+		})
+	}
+
+	// Without a session nothing is tagged
+	if got := evalLines(t, New(NewEnvironment()), "sum #food"); got.Error != "no lines tagged #food" {
+		t.Errorf("expected no tagged lines, got %+v", got)
+	}
+}
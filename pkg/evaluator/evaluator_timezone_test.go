@@ -24,14 +24,9 @@ func TestTimeInLocation(t *testing.T) {
 				if v.Date.IsZero() {
 					t.Error("Expected non-zero date")
 				}
-				// Check that we get a time close to now
-				now := time.Now().UTC()
-				diff := v.Date.Sub(now)
-				if diff < 0 {
-					diff = -diff
-				}
-				if diff > time.Minute {
-					t.Errorf("Time difference too large: %v", diff)
+				// London is UTC+0, so this is the clock itself
+				if !v.Date.Equal(testClock) {
+					t.Errorf("got %v, want %v", v.Date, testClock)
 				}
 			},
 		},
@@ -60,6 +55,7 @@ func TestTimeInLocation(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			env := NewEnvironment()
+			env.SetClock(FixedClock(testClock))
 			e := New(env)
 
 			l := lexer.New(tt.input)
@@ -120,6 +116,7 @@ func TestTimeDifference(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			env := NewEnvironment()
+			env.SetClock(FixedClock(testClock))
 			e := New(env)
 
 			l := lexer.New(tt.input)
@@ -256,6 +253,7 @@ func TestTimeDifferenceWithUnitConversion(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			env := NewEnvironment()
+			env.SetClock(FixedClock(testClock))
 			e := New(env)
 
 			l := lexer.New(tt.input)
@@ -307,15 +305,9 @@ func TestTimeConversion(t *testing.T) {
 				// Convert to London: We want the same moment in time, just expressed in London's timezone
 				// London is UTC+0, Sydney is UTC+10, so London is 10 hours behind
 				// The same moment (now + 13 in Sydney time) = (now + 3) in UTC/London time
-				now := time.Now().UTC()
-				expected := now.Add(3 * time.Hour)
-				diff := v.Date.Sub(expected)
-				if diff < 0 {
-					diff = -diff
-				}
-				// Allow 1 minute tolerance
-				if diff > time.Minute {
-					t.Errorf("Time difference too large: %v, got %v, expected around %v", diff, v.Date, expected)
+				expected := testClock.Add(3 * time.Hour)
+				if !v.Date.Equal(expected) {
+					t.Errorf("got %v, expected %v", v.Date, expected)
 				}
 			},
 		},
@@ -330,15 +322,9 @@ func TestTimeConversion(t *testing.T) {
 				// New York is UTC-5, subtract 2 hours, convert to Sydney (UTC+10)
 				// Offset: 10 - (-5) = 15
 				// Result: now - 5 - 2 + 15 = now + 8 hours
-				now := time.Now().UTC()
-				expected := now.Add(8 * time.Hour)
-				diff := v.Date.Sub(expected)
-				if diff < 0 {
-					diff = -diff
-				}
-				// Allow 1 minute tolerance
-				if diff > time.Minute {
-					t.Errorf("Time difference too large: %v, got %v, expected around %v", diff, v.Date, expected)
+				expected := testClock.Add(8 * time.Hour)
+				if !v.Date.Equal(expected) {
+					t.Errorf("got %v, expected %v", v.Date, expected)
 				}
 			},
 		},
@@ -357,6 +343,7 @@ func TestTimeConversion(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			env := NewEnvironment()
+			env.SetClock(FixedClock(testClock))
 			e := New(env)
 
 			l := lexer.New(tt.input)
@@ -404,6 +391,7 @@ func TestTimezoneErrorCases(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			env := NewEnvironment()
+			env.SetClock(FixedClock(testClock))
 			e := New(env)
 
 			l := lexer.New(tt.input)
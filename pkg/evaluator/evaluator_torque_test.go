@@ -0,0 +1,46 @@
+package evaluator
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestTorqueAndEnergy(t *testing.T) {
+	tests := []struct {
+		input string
+		want  float64
+		unit  string
+	}{
+		{"50 lbft in nm", 67.7909, "Nm"},
+		{"50 lbft in Nm", 67.7909, "Nm"},
+		{"10 n * 5 m in nm", 50, "Nm"},
+		{"10 n * 5 m in j", 50, "j"},
+		{"50 Nm in j", 50, "j"},
+		{"50 nm in m", 5e-8, "m"},
+		{"5 Nm + 3 lbft", 9.0675, "Nm"},
+		{"100 Nm / 2 m", 50, "N"},
+	}
+	for _, tt := range tests {
+		got := evalExpr(tt.input)
+		if got.IsError() {
+			t.Errorf("%s: %s", tt.input, got.Error)
+			continue
+		}
+		if got.Unit != tt.unit || math.Abs(got.Number-tt.want) > 1e-4*math.Max(1, tt.want) {
+			t.Errorf("%s = %v %s, want %v %s", tt.input, got.Number, got.Unit, tt.want, tt.unit)
+		}
+	}
+}
+
+func TestTorqueIsNeverSilentlyEnergy(t *testing.T) {
+	for _, input := range []string{"5 Nm + 3 J", "3 J - 1 Nm", "5 Nm in m"} {
+		got := evalExpr(input)
+		if !got.IsError() {
+			t.Errorf("%s = %v %s, want an error", input, got.Number, got.Unit)
+		}
+	}
+	if got := evalExpr("5 Nm + 3 J"); !strings.Contains(got.Error, "in Nm") {
+		t.Errorf("error %q should say how to convert explicitly", got.Error)
+	}
+}
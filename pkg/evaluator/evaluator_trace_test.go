@@ -0,0 +1,68 @@
+package evaluator
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/andrewneudegg/calc/pkg/lexer"
+	"github.com/andrewneudegg/calc/pkg/parser"
+)
+
+// traceExpr evaluates input with tracing on and returns each step rendered
+// with the values' default formatting.
+func traceExpr(t *testing.T, input string) []string {
+	t.Helper()
+	expr, err := parser.New(lexer.New(input).AllTokens()).Parse()
+	if err != nil {
+		t.Fatalf("parse %q: %v", input, err)
+	}
+	eval := New(NewEnvironment())
+	var steps []string
+	eval.SetTrace(func(s Step) {
+		args := make([]any, len(s.Args))
+		for i, v := range s.Args {
+			args[i] = v.String()
+		}
+		line := fmt.Sprintf(s.Text, args...) + " -> " + s.Result.String()
+		if s.Note != "" {
+			line += " (" + s.Note + ")"
+		}
+		steps = append(steps, line)
+	})
+	eval.Eval(expr)
+	return steps
+}
+
+func TestTraceSteps(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"2 + 3 * 4", []string{"3.00 * 4.00 -> 12.00", "2.00 + 12.00 -> 14.00"}},
+		{"50 cm in m", []string{"converted 50.00 cm -> 0.50 m"}},
+		{"1 m + 50 cm", []string{"converted 50.00 cm -> 0.50 m", "1.00 m + 50.00 cm -> 1.50 m"}},
+		{"10% of £200", []string{"applied 10.00% of £200.00 -> £20.00"}},
+		{"30 + 20%", []string{"30.00 + 20.00% -> 36.00"}},
+	}
+	for _, tt := range tests {
+		got := traceExpr(t, tt.input)
+		if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("trace of %q:\n got %q\nwant %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestTraceCurrencyConversionNamesRate(t *testing.T) {
+	got := traceExpr(t, "£10 + $5")
+	if len(got) != 2 || !strings.HasPrefix(got[0], "converted $5.00 -> £") || !strings.Contains(got[0], "(at $1 = £") {
+		t.Fatalf("expected the implicit conversion and its rate first, got %q", got)
+	}
+}
+
+func TestTraceSkipsFailedOperands(t *testing.T) {
+	got := traceExpr(t, "1 + nosuchvar")
+	if len(got) != 1 || !strings.HasPrefix(got[0], "nosuchvar -> ") {
+		t.Fatalf("only the failing lookup should be recorded, got %q", got)
+	}
+}
@@ -0,0 +1,47 @@
+package evaluator
+
+import (
+	"math"
+	"testing"
+
+	"github.com/andrewneudegg/calc/pkg/lexer"
+	"github.com/andrewneudegg/calc/pkg/parser"
+)
+
+func TestPastedUnitSymbols(t *testing.T) {
+	tests := []struct {
+		input string
+		want  float64
+		unit  string
+	}{
+		{"20°C in °F", 68, "F"},
+		{"20 °C in F", 68, "F"},
+		{"68 ° F in C", 20, "C"},
+		{"5 ℃ in ℉", 41, "F"},
+		{"5 µm in nm", 5000, "nm"}, // Micro sign
+		{"5 μm in nm", 5000, "nm"}, // Greek mu
+		{"3 ㎞ in m", 3000, "m"},
+		{"2 ㎏ + 500 g", 2.5, "kg"},
+		{"1 ㎡ in cm²", 10000, "cm²"},
+		{"10 N·m in J", 10, "J"},
+		{"1 kW·h in kJ", 3600, "kJ"},
+	}
+	for _, tt := range tests {
+		// Products such as N·m are known to the unit system, not the
+		// lexer's own table
+		env := NewEnvironment()
+		expr, err := parser.New(lexer.NewWithUnits(tt.input, env.IsUnit).AllTokens()).Parse()
+		if err != nil {
+			t.Errorf("%s: %v", tt.input, err)
+			continue
+		}
+		got := New(env).Eval(expr)
+		if got.IsError() {
+			t.Errorf("%s: %s", tt.input, got.Error)
+			continue
+		}
+		if math.Abs(got.Number-tt.want) > math.Abs(tt.want)*1e-9 || got.Unit != tt.unit {
+			t.Errorf("%s = %g %s, want %g %s", tt.input, got.Number, got.Unit, tt.want, tt.unit)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package evaluator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrewneudegg/calc/pkg/lexer"
+	"github.com/andrewneudegg/calc/pkg/parser"
+)
+
+func evalLines(t *testing.T, e *Evaluator, lines ...string) Value {
+	t.Helper()
+	var result Value
+	for _, line := range lines {
+		expr, err := parser.New(lexer.New(line).AllTokens()).Parse()
+		if err != nil {
+			t.Fatalf("parse %q: %v", line, err)
+		}
+		result = e.Eval(expr)
+	}
+	return result
+}
+
+func TestVariablesIgnoreCase(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  float64
+	}{
+		{"defined capitalised, used lower", []string{"Rate = 5", "rate * 2"}, 10},
+		{"defined lower, used upper", []string{"rate = 5", "RATE + 1"}, 6},
+		{"camel case", []string{"hourlyRate = 40", "HourlyRate * 2"}, 80},
+		{"reassignment in another casing", []string{"Rate = 5", "rate = 7", "Rate"}, 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := evalLines(t, New(NewEnvironment()), tt.lines...)
+			if result.IsError() {
+				t.Fatalf("unexpected error: %s", result.Error)
+			}
+			if result.Number != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, result.Number)
+			}
+		})
+	}
+}
+
+func TestVariableNamesKeepFirstCasing(t *testing.T) {
+	env := NewEnvironment()
+	e := New(env)
+	evalLines(t, e, "Rate = 5", "rate = 6", "total = 1")
+
+	names := env.GetVariableNames()
+	if len(names) != 2 {
+		t.Fatalf("expected two variables, got %v", names)
+	}
+	if got, _ := env.VariableName("RATE"); got != "Rate" {
+		t.Errorf("expected display name Rate, got %q", got)
+	}
+	if !env.HasVariable("TOTAL") {
+		t.Error("expected HasVariable to ignore case")
+	}
+}
+
+func TestVariableCaseCollisionWarns(t *testing.T) {
+	e := New(NewEnvironment())
+
+	first := evalLines(t, e, "Rate = 5")
+	if first.Warning != "" {
+		t.Errorf("unexpected warning on first assignment: %q", first.Warning)
+	}
+	same := evalLines(t, e, "Rate = 6")
+	if same.Warning != "" {
+		t.Errorf("unexpected warning when reassigning with the same casing: %q", same.Warning)
+	}
+
+	collision := evalLines(t, e, "rate = 7")
+	if !strings.Contains(collision.Warning, "rate replaces Rate") {
+		t.Errorf("expected collision warning, got %q", collision.Warning)
+	}
+	stored, _ := e.GetVariable("RATE")
+	if stored.Number != 7 || stored.Warning != "" {
+		t.Errorf("expected stored value 7 without warning, got %+v", stored)
+	}
+}
+
+func TestPrintInterpolationIgnoresCase(t *testing.T) {
+	result := evalLines(t, New(NewEnvironment()), "Total = 12", `print("total is {TOTAL}")`)
+	if result.IsError() || result.String() != "total is 12.00" {
+		t.Errorf("unexpected print result: %+v", result)
+	}
+}
@@ -0,0 +1,47 @@
+package evaluator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWeekdayPhrases(t *testing.T) {
+	// testClock is Saturday 1 June 2024
+	date := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		input string
+		want  time.Time
+	}{
+		{"next monday + 2 weeks", date(2024, time.June, 17)},
+		{"next monday - 1 day", date(2024, time.June, 2)},
+		{"friday after 01/06/2024", date(2024, time.June, 7)},
+		{"saturday after 01/06/2024", date(2024, time.June, 8)},
+		{"friday before 01/06/2024", date(2024, time.May, 31)},
+		{"monday after today", date(2024, time.June, 3)},
+		{"first monday of march", date(2024, time.March, 4)},
+		{"second tuesday of june", date(2024, time.June, 11)},
+		{"third wednesday of march 2027", date(2027, time.March, 17)},
+		{"fourth thursday of november 2025", date(2025, time.November, 27)},
+		{"last friday of march 2026", date(2026, time.March, 27)},
+		{"last sunday of june", date(2024, time.June, 30)},
+		{"first monday of march + 2 days", date(2024, time.March, 6)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			v := evalExprAt(tt.input, testClock)
+			if v.IsError() {
+				t.Fatalf("unexpected error: %s", v.Error)
+			}
+			// Dates written out are local and the clock UTC, so compare days
+			if v.Type != ValueDate || v.Date.Format("2006-01-02") != tt.want.Format("2006-01-02") {
+				t.Errorf("got %v, want %s", v.Date, tt.want.Format("2 Jan 2006"))
+			}
+		})
+	}
+
+	if v := evalExprAt("friday after 5", testClock); v.Error != "friday after needs a date" {
+		t.Errorf("friday after 5 = %+v, want an error", v)
+	}
+}
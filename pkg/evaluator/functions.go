@@ -0,0 +1,247 @@
+package evaluator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/andrewneudegg/calc/pkg/parser"
+)
+
+// Function describes a function that can be called as name(args), either
+// built in or added by an extension with Register.
+type Function struct {
+	Name string
+	// Description is the one-line summary shown by --list-functions and
+	// autocomplete.
+	Description string
+	// MinArgs and MaxArgs bound the number of arguments. A MaxArgs of -1
+	// allows any number.
+	MinArgs, MaxArgs int
+	// Accepts lists the types every argument may have. Empty accepts any.
+	Accepts []ValueType
+	// Call computes the result from arguments that have already been
+	// evaluated and checked against MinArgs, MaxArgs and Accepts. It reports
+	// failures by returning NewError.
+	Call func(args []Value) Value
+	// builtin marks the functions evalFunctionCall handles itself.
+	builtin bool
+}
+
+// builtinFunctions are the functions evalFunctionCall handles itself, which
+// registered functions may not shadow.
+var builtinFunctions = []Function{
+	{Name: "sum", Description: "Sum of all arguments", MaxArgs: -1},
+	{Name: "total", Description: "Sum of all arguments", MaxArgs: -1},
+	{Name: "average", Description: "Average of arguments", MinArgs: 1, MaxArgs: -1},
+	{Name: "mean", Description: "Mean of arguments", MinArgs: 1, MaxArgs: -1},
+	{Name: "min", Description: "Minimum of arguments", MinArgs: 1, MaxArgs: -1},
+	{Name: "max", Description: "Maximum of arguments", MinArgs: 1, MaxArgs: -1},
+	{Name: "print", Description: "Print with variable interpolation", MinArgs: 1, MaxArgs: 1},
+	{Name: "round", Description: "Round to whole numbers or decimal places", MinArgs: 1, MaxArgs: 2},
+	{Name: "roundto", Description: "Round to a multiple of a step", MinArgs: 2, MaxArgs: 2},
+	{Name: "roundcash", Description: "Round money to its smallest coin", MinArgs: 1, MaxArgs: 1},
+	{Name: "trunc", Description: "Drop the fractional part", MinArgs: 1, MaxArgs: 1},
+}
+
+// registry holds the functions added with Register, by lower-case name.
+var registry = struct {
+	sync.RWMutex
+	funcs map[string]Function
+}{funcs: map[string]Function{}}
+
+// RegisterFunction adds a function that takes any number of arguments of
+// any type. Use Register to declare its arity and argument types, which
+// gives better error messages.
+func RegisterFunction(name string, fn func(args []Value) Value) error {
+	return Register(Function{Name: name, MaxArgs: -1, Call: fn})
+}
+
+// Register adds a function for calc to call by name, usually from an init
+// function in a package linked into the binary. Names are matched without
+// regard to case, must be words of letters, digits and underscores, and may
+// not be taken already, by a built-in function or an earlier registration.
+func Register(f Function) error {
+	name := strings.ToLower(f.Name)
+	if !isFunctionName(name) {
+		return fmt.Errorf("function name %q must be a word of letters, digits and underscores", f.Name)
+	}
+	if f.Call == nil {
+		return fmt.Errorf("function %s has no implementation", name)
+	}
+	if f.MinArgs < 0 || (f.MaxArgs >= 0 && f.MaxArgs < f.MinArgs) || f.MaxArgs < -1 {
+		return fmt.Errorf("function %s has an invalid number of arguments: %d to %d", name, f.MinArgs, f.MaxArgs)
+	}
+	if isBuiltinFunction(name) {
+		return fmt.Errorf("%s is a built-in function", name)
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+	if _, ok := registry.funcs[name]; ok {
+		return fmt.Errorf("function %s is already registered", name)
+	}
+	f.Name = name
+	f.builtin = false
+	registry.funcs[name] = f
+	return nil
+}
+
+// Functions lists the functions calc can call: the built-in ones first,
+// then registered ones by name.
+func Functions() []Function {
+	list := make([]Function, 0, len(builtinFunctions))
+	for _, f := range builtinFunctions {
+		f.builtin = true
+		list = append(list, f)
+	}
+	return append(list, RegisteredFunctions()...)
+}
+
+// RegisteredFunctions lists the functions added with Register, by name.
+func RegisteredFunctions() []Function {
+	registry.RLock()
+	defer registry.RUnlock()
+	list := make([]Function, 0, len(registry.funcs))
+	for _, f := range registry.funcs {
+		list = append(list, f)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Builtin reports whether f is one of calc's own functions.
+func (f Function) Builtin() bool {
+	return f.builtin
+}
+
+// Usage shows how f is called, as in round(x, y) or sum(...).
+func (f Function) Usage() string {
+	if f.MaxArgs < 0 || f.MaxArgs > 3 {
+		return f.Name + "(...)"
+	}
+	params := []string{"x", "y", "z"}[:f.MaxArgs]
+	return f.Name + "(" + strings.Join(params, ", ") + ")"
+}
+
+// isBuiltinFunction reports whether evalFunctionCall handles name itself.
+func isBuiltinFunction(name string) bool {
+	for _, f := range builtinFunctions {
+		if f.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isFunctionName reports whether name can be written as a call.
+func isFunctionName(name string) bool {
+	for i, r := range name {
+		if !unicode.IsLetter(r) && r != '_' && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return name != ""
+}
+
+// lookupFunction finds a registered function by lower-case name.
+func lookupFunction(name string) (Function, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	f, ok := registry.funcs[name]
+	return f, ok
+}
+
+// unregisterFunction removes a registered function, for tests.
+func unregisterFunction(name string) {
+	registry.Lock()
+	defer registry.Unlock()
+	delete(registry.funcs, strings.ToLower(name))
+}
+
+// evalRegistered calls a registered function once its arguments have been
+// evaluated and checked against what it declared.
+func (e *Evaluator) evalRegistered(f Function, args []parser.Expr) Value {
+	if len(args) < f.MinArgs || (f.MaxArgs >= 0 && len(args) > f.MaxArgs) {
+		return NewError(fmt.Sprintf("%s requires %s", f.Name, arityText(f.MinArgs, f.MaxArgs)))
+	}
+	vals := make([]Value, 0, len(args))
+	for _, arg := range args {
+		v := e.Eval(arg)
+		if v.IsError() {
+			return v
+		}
+		if len(f.Accepts) > 0 && !acceptsType(f.Accepts, v.Type) {
+			return NewError(fmt.Sprintf("%s requires %s, not %s", f.Name, typesText(f.Accepts), describeValueKind(v)))
+		}
+		vals = append(vals, v)
+	}
+	return f.Call(vals)
+}
+
+// arityText describes a number of arguments, as in "exactly 2 arguments".
+func arityText(min, max int) string {
+	plural := func(n int) string {
+		if n == 1 {
+			return "1 argument"
+		}
+		return fmt.Sprintf("%d arguments", n)
+	}
+	switch {
+	case min == max:
+		return "exactly " + plural(min)
+	case max < 0:
+		return "at least " + plural(min)
+	case min == 0:
+		return "at most " + plural(max)
+	default:
+		return fmt.Sprintf("%d to %d arguments", min, max)
+	}
+}
+
+// acceptsType reports whether t is among types.
+func acceptsType(types []ValueType, t ValueType) bool {
+	for _, a := range types {
+		if a == t {
+			return true
+		}
+	}
+	return false
+}
+
+// typesText lists value types for an error message, as in "a number or a
+// currency amount".
+func typesText(types []ValueType) string {
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = typeNoun(t)
+	}
+	if len(names) == 1 {
+		return names[0]
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " or " + names[len(names)-1]
+}
+
+// typeNoun names a value type with its article.
+func typeNoun(t ValueType) string {
+	switch t {
+	case ValueNumber:
+		return "a plain number"
+	case ValueUnit:
+		return "a quantity with a unit"
+	case ValueCurrency:
+		return "a currency amount"
+	case ValuePercent:
+		return "a percentage"
+	case ValueDate:
+		return "a date"
+	case ValueString:
+		return "a string"
+	case ValueList:
+		return "a list"
+	default:
+		return "a value"
+	}
+}
@@ -0,0 +1,41 @@
+package evaluator
+
+import (
+	"fmt"
+
+	"github.com/andrewneudegg/calc/pkg/currency"
+	"github.com/andrewneudegg/calc/pkg/parser"
+)
+
+// convertOn converts money at the exchange rate of a past date, as in
+// "£100 in USD on 15/03/2024". It never falls back to today's rate: a date
+// without a rate in the history is an error. A rate from an earlier day,
+// as over a weekend, is noted in a warning.
+func (e *Evaluator) convertOn(val Value, toCurrency string, on parser.Expr) Value {
+	date := e.Eval(on)
+	if date.IsError() {
+		return date
+	}
+	if date.Type != ValueDate {
+		return NewError(fmt.Sprintf("on needs a date, got %s", date.String()))
+	}
+	if val.Type != ValueCurrency {
+		return NewError(fmt.Sprintf("cannot convert %s to %s at a past rate; only money has exchange rates", val.String(), toCurrency))
+	}
+
+	rate, err := e.env.currency.RateAt(val.Currency, toCurrency, date.Date)
+	if err != nil {
+		return NewError(err.Error())
+	}
+	converted := NewCurrency(val.Number*rate.Rate, e.env.currency.GetSymbol(toCurrency))
+	converted.CurrencyStyle = val.CurrencyStyle
+	converted.Explicit = true
+	if rate.From != rate.To {
+		converted.Rates = []currency.Rate{rate}
+	}
+	if rate.On.Format("2006-01-02") != date.Date.Format("2006-01-02") {
+		converted.Warning = fmt.Sprintf("no rate on %s; used the %s rate", date.Date.Format("2 Jan 2006"), rate.On.Format("2 Jan 2006"))
+	}
+	e.recordConversion(val, converted)
+	return converted
+}
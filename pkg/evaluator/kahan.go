@@ -0,0 +1,26 @@
+package evaluator
+
+import "math"
+
+// kahanSum adds floats with compensated (Kahan–Babuška) summation, carrying
+// the low-order bits each addition loses so that long runs of small values,
+// as in sum(...) over thousands of 0.1s, do not drift.
+type kahanSum struct {
+	sum, carry float64
+}
+
+// add adds x to the running total.
+func (k *kahanSum) add(x float64) {
+	t := k.sum + x
+	if math.Abs(k.sum) >= math.Abs(x) {
+		k.carry += (k.sum - t) + x
+	} else {
+		k.carry += (x - t) + k.sum
+	}
+	k.sum = t
+}
+
+// total returns the sum with the carried error put back.
+func (k *kahanSum) total() float64 {
+	return k.sum + k.carry
+}
@@ -0,0 +1,29 @@
+package evaluator
+
+import "time"
+
+// SetMonthOverflow chooses what adding months or years does to a day that
+// the target month lacks. By default the day is clamped to the month's last,
+// so 31 Jan + 1 month is 29 Feb in a leap year; with overflow on the extra
+// days run into the next month, giving 2 Mar, as time.AddDate does.
+func (e *Environment) SetMonthOverflow(on bool) {
+	e.monthOverflow = on
+}
+
+// addMonths adds years and months to t, clamping the day to the end of the
+// target month unless the environment overflows instead.
+func (e *Evaluator) addMonths(t time.Time, years, months int) time.Time {
+	if e.env.monthOverflow {
+		return t.AddDate(years, months, 0)
+	}
+	// Day 1 never overflows, so this is always the target month
+	first := time.Date(t.Year(), t.Month(), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	first = first.AddDate(years, months, 0)
+	last := daysIn(first.Month(), first.Year())
+	return first.AddDate(0, 0, min(t.Day(), last)-1)
+}
+
+// daysIn returns the number of days in month of year.
+func daysIn(month time.Month, year int) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
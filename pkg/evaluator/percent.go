@@ -0,0 +1,97 @@
+package evaluator
+
+import (
+	"fmt"
+
+	"github.com/andrewneudegg/calc/pkg/parser"
+)
+
+// percentArithmetic applies op when either side is a percentage, reporting
+// false for combinations it leaves to the rest of evalBinary, such as a
+// percentage plus an amount of money. Adding or subtracting a percentage
+// changes the left side by that share of itself, so £80 + 20% is £96.
+// Multiplying an amount by a percentage takes that share of it, in its unit
+// or currency, while multiplying a percentage by a plain number scales the
+// percentage, so vat * 2 is 40%. Percentages added to each other, or to a
+// plain number, add as points, in the left side's unit, so 25 bps + 5 is
+// 30 bps and 25 bps + 1% is 125 bps.
+func percentArithmetic(left Value, op string, right Value) (Value, bool) {
+	amount := func(v Value) bool {
+		return v.Type == ValueNumber || v.Type == ValueCurrency || v.Type == ValueUnit
+	}
+	scaled := func(v Value, n float64) Value {
+		v.Number = n
+		return v
+	}
+
+	switch {
+	case left.Type == ValuePercent && (right.Type == ValuePercent || right.Type == ValueNumber):
+		percent := func(n float64) Value {
+			p := NewPercent(n)
+			p.Unit = left.Unit
+			return p
+		}
+		points := right.Number
+		if right.Type == ValueNumber {
+			points /= percentScale(left.Unit)
+		}
+		switch op {
+		case "+":
+			return percent(left.Number + points), true
+		case "-":
+			return percent(left.Number - points), true
+		case "*":
+			if right.Type == ValuePercent {
+				return percent(left.Number * right.Number / 100), true
+			}
+			return percent(left.Number * right.Number), true
+		case "/":
+			if right.Number == 0 {
+				return NewError("division by zero"), true
+			}
+			if right.Type == ValuePercent {
+				return NewNumber(left.Number / right.Number), true
+			}
+			return percent(left.Number / right.Number), true
+		}
+
+	case right.Type == ValuePercent && amount(left):
+		share := right.Number / 100
+		switch op {
+		// Written as the amount plus its share, so 100 + 10% is exactly 110
+		case "+":
+			return scaled(left, left.Number+left.Number*right.Number/100), true
+		case "-":
+			return scaled(left, left.Number-left.Number*right.Number/100), true
+		case "*":
+			return scaled(left, left.Number*share), true
+		case "/":
+			if share == 0 {
+				return NewError("division by zero"), true
+			}
+			return scaled(left, left.Number/share), true
+		}
+
+	case left.Type == ValuePercent && amount(right) && op == "*":
+		return scaled(right, right.Number*left.Number/100), true
+	}
+	return Value{}, false
+}
+
+// evalNamedPercentOf evaluates "vat of £100", which needs vat to hold a
+// percentage.
+func (e *Evaluator) evalNamedPercentOf(node *parser.NamedPercentOfExpr) Value {
+	percent := e.Eval(node.Percent)
+	if percent.IsError() {
+		return percent
+	}
+	if percent.Type != ValuePercent {
+		name := parser.SExpr(node.Percent)
+		return NewError(fmt.Sprintf("%s is not a percentage; %s of ... needs one, as in %s = 20%%", name, name, name))
+	}
+	of := e.Eval(node.Of)
+	if of.IsError() {
+		return of
+	}
+	return percentOf(percent.Number, of)
+}
@@ -0,0 +1,105 @@
+package evaluator
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/andrewneudegg/calc/pkg/units"
+)
+
+// Basis points and per mille are percentages written on finer scales. A
+// percentage keeps its Number in per cent whatever it was written in, so
+// 25 bps holds 0.25, and its Unit names the scale to show it in.
+
+// newScaledPercent makes a percentage of n in the percent-like unit, as in
+// 25 bps. An empty unit is per cent.
+func newScaledPercent(n float64, unit string) Value {
+	p := NewPercent(n / percentScale(unit))
+	p.Unit = unit
+	return p
+}
+
+// percentScale returns how many of unit make one per cent, 1 for "".
+func percentScale(unit string) float64 {
+	if scale, ok := units.PercentScale(unit); ok {
+		return scale
+	}
+	return 1
+}
+
+// PercentParts returns a percentage's number in the unit it was written
+// in, and what follows the number: "%", "‰", or a word such as " bps".
+func (v Value) PercentParts() (float64, string) {
+	if v.Unit == "" {
+		return v.Number, "%"
+	}
+	n := v.Number * percentScale(v.Unit)
+	if r, _ := utf8.DecodeRuneInString(v.Unit); !unicode.IsLetter(r) {
+		return n, v.Unit
+	}
+	return n, " " + v.Unit
+}
+
+// asBasisPoints reads a bare data rate in bps as basis points, which is what
+// it means beside money or a percentage, as in £1m + 25 bps.
+func asBasisPoints(v Value) Value {
+	if v.Type != ValueUnit || v.Unit != "bps" || v.Per != 0 {
+		return v
+	}
+	return newScaledPercent(v.Number, "bps")
+}
+
+// basisPointOperands applies asBasisPoints to either side of an operator
+// whose other side is money or a percentage.
+func basisPointOperands(left, right Value) (Value, Value) {
+	besidePercent := func(v Value) bool {
+		return v.Type == ValueCurrency || v.Type == ValuePercent
+	}
+	if besidePercent(right) {
+		left = asBasisPoints(left)
+	}
+	if besidePercent(left) {
+		right = asBasisPoints(right)
+	}
+	return left, right
+}
+
+// convertPercent converts a percentage between %, basis points and per
+// mille, reporting false when toUnit is not one of them. A data rate in bps
+// converted to another of them is read as basis points, so 25 bps in % is
+// 0.25%, while 1 kbps in bps stays a data rate.
+func convertPercent(val Value, toUnit string) (Value, bool) {
+	if _, ok := units.PercentScale(toUnit); !ok {
+		return Value{}, false
+	}
+	if toUnit != "bps" {
+		val = asBasisPoints(val)
+	}
+	switch val.Type {
+	case ValuePercent:
+		converted := NewPercent(val.Number)
+		converted.Unit = percentUnitName(toUnit)
+		return converted, true
+	case ValueNumber:
+		// A plain number is a fraction, so 0.05 in bps is 500 bps
+		converted := NewPercent(val.Number * 100)
+		converted.Unit = percentUnitName(toUnit)
+		return converted, true
+	case ValueUnit:
+		if toUnit == "bps" {
+			return Value{}, false
+		}
+	}
+	return NewError(fmt.Sprintf("cannot convert %s to %s", describeValueKind(val), toUnit)), true
+}
+
+// percentUnitName is the Unit a percentage converted to target holds, ""
+// for per cent.
+func percentUnitName(target string) string {
+	if target == "%" {
+		return ""
+	}
+	return strings.ToLower(target)
+}
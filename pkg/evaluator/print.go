@@ -0,0 +1,117 @@
+package evaluator
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SetFormatFunc sets how print renders the value of a placeholder, so that
+// it matches the results around it. places is the decimal places asked for
+// with {name:2}, or -1 for the usual precision. Without one, placeholders
+// render with Value.String.
+func (e *Environment) SetFormatFunc(f func(v Value, places int) string) {
+	e.formatFunc = f
+}
+
+// interpolate replaces the {name} placeholders in s with the values of the
+// variables they name. A placeholder can add specifiers after a colon,
+// separated by commas:
+//
+//	{total:2}         two decimal places
+//	{weight:unit=lb}  converted to pounds, or any unit or currency
+//	{when:iso}        a date as iso, unix, weekday or week
+//
+// {{ and }} are literal braces. An unmatched { and an empty {} are left as
+// they are.
+func (e *Evaluator) interpolate(s string) Value {
+	var out strings.Builder
+	for i := 0; i < len(s); {
+		switch {
+		case strings.HasPrefix(s[i:], "{{"), strings.HasPrefix(s[i:], "}}"):
+			out.WriteByte(s[i])
+			i += 2
+			continue
+		case s[i] != '{':
+			out.WriteByte(s[i])
+			i++
+			continue
+		}
+		j := strings.IndexByte(s[i:], '}')
+		if j < 0 {
+			out.WriteString(s[i:])
+			break
+		}
+		j += i
+		if body := strings.TrimSpace(s[i+1 : j]); body == "" {
+			out.WriteString(s[i : j+1])
+		} else {
+			text, err := e.placeholder(body)
+			if err != nil {
+				return NewError(fmt.Sprintf("placeholder {%s}: %s", body, err))
+			}
+			out.WriteString(text)
+		}
+		i = j + 1
+	}
+	return NewString(out.String())
+}
+
+// placeholder renders the body of one placeholder, such as "total:2".
+func (e *Evaluator) placeholder(body string) (string, error) {
+	name, specs, _ := strings.Cut(body, ":")
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", errors.New("missing a variable name")
+	}
+	v, ok := e.env.GetVariable(name)
+	if !ok {
+		if v = e.undefinedVariable(name); v.IsError() {
+			return "", errors.New(v.Error)
+		}
+	}
+
+	places := -1
+	if strings.TrimSpace(specs) != "" {
+		for _, spec := range strings.Split(specs, ",") {
+			spec = strings.TrimSpace(spec)
+			key, arg, hasArg := strings.Cut(spec, "=")
+			switch {
+			case spec == "":
+				return "", errors.New("empty format specifier")
+			case isPlaces(spec):
+				places, _ = strconv.Atoi(spec)
+			case hasArg && strings.EqualFold(strings.TrimSpace(key), "unit"):
+				if v = e.convertValue(v, strings.TrimSpace(arg)); v.IsError() {
+					return "", errors.New(v.Error)
+				}
+			case slices.Contains(dateTargets, strings.ToLower(spec)):
+				if v.Type != ValueDate {
+					return "", fmt.Errorf("%s needs a date", spec)
+				}
+				v = convertDate(v.Date, spec)
+			default:
+				return "", fmt.Errorf("unknown format %q; use decimal places such as 2, unit=<unit> or %s", spec, strings.Join(dateTargets, ", "))
+			}
+		}
+	}
+
+	return e.render(v, places), nil
+}
+
+// render formats v for text built from values, such as print's output, as
+// results are formatted. places is as for SetFormatFunc.
+func (e *Evaluator) render(v Value, places int) string {
+	if e.env.formatFunc != nil {
+		return e.env.formatFunc(v, places)
+	}
+	return v.stringPlaces(places)
+}
+
+// isPlaces reports whether spec is a number of decimal places, 0 to 20.
+func isPlaces(spec string) bool {
+	n, err := strconv.Atoi(spec)
+	return err == nil && n >= 0 && n <= 20 && spec[0] != '+'
+}
@@ -0,0 +1,120 @@
+package evaluator
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/andrewneudegg/calc/pkg/currency"
+	"github.com/andrewneudegg/calc/pkg/parser"
+	"github.com/andrewneudegg/calc/pkg/rounding"
+)
+
+// roundable evaluates the value a rounding function works on. Numbers,
+// units, currencies and percentages can be rounded; the result keeps the
+// value's type and unit.
+func (e *Evaluator) roundable(name string, arg parser.Expr) Value {
+	val := e.Eval(arg)
+	if val.IsError() {
+		return val
+	}
+	switch val.Type {
+	case ValueNumber, ValueUnit, ValueCurrency, ValuePercent:
+		return val
+	default:
+		return NewError(fmt.Sprintf("%s requires a number, unit, currency or percentage", name))
+	}
+}
+
+// evalRound handles round(x) and round(x, places) under the rounding setting.
+func (e *Evaluator) evalRound(args []parser.Expr) Value {
+	if len(args) < 1 || len(args) > 2 {
+		return NewError("round requires a value and optionally a number of decimal places")
+	}
+	val := e.roundable("round", args[0])
+	if val.IsError() {
+		return val
+	}
+	places := 0
+	if len(args) == 2 {
+		p := e.Eval(args[1])
+		if p.IsError() {
+			return p
+		}
+		if p.Type != ValueNumber || p.Number != math.Trunc(p.Number) {
+			return NewError("round places must be a whole number")
+		}
+		places = int(p.Number)
+	}
+	// A percentage rounds in the unit it is shown in, so 7.4 bps is 7 bps
+	scale := 1.0
+	if val.Type == ValuePercent {
+		scale = percentScale(val.Unit)
+	}
+	val.Number = e.env.rounding.Places(val.Number*scale, places) / scale
+	return val
+}
+
+// evalRoundTo handles roundto(x, step), rounding x to a multiple of step,
+// as in roundto(£12.37, 0.05) for cash without pennies. A step with a unit
+// or currency is converted to x's first, so roundto(1234 m, 1 km) works.
+func (e *Evaluator) evalRoundTo(args []parser.Expr) Value {
+	if len(args) != 2 {
+		return NewError("roundto requires a value and a step")
+	}
+	val := e.roundable("roundto", args[0])
+	if val.IsError() {
+		return val
+	}
+	step := e.Eval(args[1])
+	if step.IsError() {
+		return step
+	}
+	switch {
+	case step.Type == ValueCurrency && val.Type == ValueCurrency && step.Currency != val.Currency:
+		step = e.convertValue(step, val.Currency)
+	case step.Type == ValueUnit && val.Type == ValueUnit && step.Unit != val.Unit:
+		step = e.convertValue(step, val.Unit)
+	}
+	if step.IsError() {
+		return step
+	}
+	if step.Type != ValueNumber && step.Type != val.Type {
+		return NewError("roundto step must be a number or match the value being rounded")
+	}
+	if step.Number <= 0 {
+		return NewError("roundto step must be greater than zero")
+	}
+	val.Number = e.env.rounding.Step(val.Number, step.Number)
+	return val
+}
+
+// evalRoundCash handles roundcash(x), rounding a currency to its smallest
+// unit: pence and cents, or whole yen.
+func (e *Evaluator) evalRoundCash(args []parser.Expr) Value {
+	if len(args) != 1 {
+		return NewError("roundcash requires exactly one argument")
+	}
+	val := e.Eval(args[0])
+	if val.IsError() {
+		return val
+	}
+	if val.Type != ValueCurrency {
+		return NewError("roundcash requires a currency value")
+	}
+	val.Number = e.env.rounding.Places(val.Number, currency.MinorUnits(val.Currency))
+	return val
+}
+
+// evalTrunc handles trunc(x), dropping the fractional part whatever the
+// rounding setting.
+func (e *Evaluator) evalTrunc(args []parser.Expr) Value {
+	if len(args) != 1 {
+		return NewError("trunc requires exactly one argument")
+	}
+	val := e.roundable("trunc", args[0])
+	if val.IsError() {
+		return val
+	}
+	val.Number = rounding.Truncate.Round(val.Number)
+	return val
+}
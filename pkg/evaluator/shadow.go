@@ -0,0 +1,46 @@
+package evaluator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/andrewneudegg/calc/pkg/lexer"
+	"github.com/andrewneudegg/calc/pkg/parser"
+)
+
+// ShadowWarning describes what else name means when a variable takes it,
+// and which meaning wins where, or returns "" when the name is free. The
+// rule is by position: a name read where a value is expected is the
+// variable, as in m * 2, while after a number it is still the unit or
+// currency, as in 10 m, and before "(" it is still the function. A keyword
+// that keeps its meaning everywhere, such as today, cannot be read back at
+// all, which the warning says first.
+func (e *Environment) ShadowWarning(name string) string {
+	key := strings.ToLower(name)
+	_, registered := lookupFunction(key)
+	switch {
+	case lexer.IsKeyword(key) && !parser.ReadsBack(name):
+		return fmt.Sprintf("'%s' is a keyword, so reading %s gives the keyword's meaning rather than this value; use another name", name, name)
+	case isBuiltinFunction(key) || registered:
+		return fmt.Sprintf("'%s' is also the function %s(); %s(...) still calls it", name, key, key)
+	case lexer.IsKeyword(key):
+		return fmt.Sprintf("'%s' is also a keyword; the keyword meaning still applies where it fits", name)
+	case e.currency.IsCurrency(name):
+		return fmt.Sprintf("'%s' is also a currency; the currency meaning still applies after numbers", name)
+	case e.units.IsUnit(name):
+		return fmt.Sprintf("'%s' is also the unit %s; the unit meaning still applies after numbers", name, e.unitName(name))
+	}
+	return ""
+}
+
+// unitName spells out a unit for a message, using its longest alias, so m
+// is metres.
+func (e *Environment) unitName(name string) string {
+	long := name
+	for _, alias := range e.units.Aliases(name) {
+		if len(alias) > len(long) {
+			long = alias
+		}
+	}
+	return long
+}
@@ -0,0 +1,45 @@
+package evaluator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SetStrict chooses what an undefined variable gives: an error when strict,
+// which is the default, or else 0, with the name noted for TakeUndefined.
+// Assigning to a variable works the same either way.
+func (e *Environment) SetStrict(on bool) {
+	e.strict = on
+}
+
+// TakeUndefined returns the undefined variables read as 0 since it was last
+// called, each once, in the order they were first read.
+func (e *Environment) TakeUndefined() []string {
+	names := e.undefined
+	e.undefined = nil
+	return names
+}
+
+// undefinedVariable is the value of a variable that has not been defined.
+func (e *Evaluator) undefinedVariable(name string) Value {
+	if e.env.strict {
+		return NewError(fmt.Sprintf("undefined variable: %s", name))
+	}
+	for _, seen := range e.env.undefined {
+		if strings.EqualFold(seen, name) {
+			return NewNumber(0)
+		}
+	}
+	e.env.undefined = append(e.env.undefined, name)
+	return NewNumber(0)
+}
+
+// UndefinedWarning describes variables read as 0, e.g. "undefined variable
+// rent treated as 0".
+func UndefinedWarning(names []string) string {
+	noun := "variable"
+	if len(names) > 1 {
+		noun = "variables"
+	}
+	return fmt.Sprintf("undefined %s %s treated as 0", noun, strings.Join(names, ", "))
+}
@@ -0,0 +1,75 @@
+package evaluator
+
+import (
+	"fmt"
+
+	"github.com/andrewneudegg/calc/pkg/parser"
+)
+
+// SetTagFunc sets where sum #tag and average #tag find the results of the
+// lines filed under a tag, in the order they were written. Without one, no
+// line has a tag.
+func (e *Environment) SetTagFunc(f func(tag string) []Value) {
+	e.tagFunc = f
+}
+
+// evalTag adds up, or averages, the results of every line tagged with
+// node's tag. They are added in the currency or unit of the first that has
+// one, converting the others, so £3.20 and $4 under #food give pounds.
+func (e *Evaluator) evalTag(node *parser.TagExpr) Value {
+	var vals []Value
+	if e.env.tagFunc != nil {
+		vals = e.env.tagFunc(node.Tag)
+	}
+	if len(vals) == 0 {
+		return NewError(fmt.Sprintf("no lines tagged #%s", node.Tag))
+	}
+
+	total, err := e.sumValues(vals)
+	if err != nil {
+		return NewError(fmt.Sprintf("%s #%s: %s", node.Func, node.Tag, err))
+	}
+	if node.Func == "average" {
+		total.Number /= float64(len(vals))
+	}
+	return total
+}
+
+// sumValues adds vals in the currency or unit of the first one that has
+// one. Plain numbers add as they are.
+func (e *Evaluator) sumValues(vals []Value) (Value, error) {
+	total := NewNumber(0)
+	for _, v := range vals {
+		if v.Type == ValueCurrency {
+			total = newCurrencyLike(0, v)
+			break
+		}
+		if v.Type == ValueUnit {
+			total = NewUnit(0, v.Unit)
+			break
+		}
+	}
+
+	var sum kahanSum
+	for _, v := range vals {
+		switch {
+		case v.Type == ValueNumber:
+		case v.Type == ValueCurrency && total.Type == ValueCurrency && v.Currency != total.Currency:
+			v = e.convertTo(v, total.Currency)
+		case v.Type == ValueUnit && total.Type == ValueUnit && v.Unit != total.Unit:
+			v = e.convertTo(v, total.Unit)
+		case v.Type != total.Type:
+			return Value{}, fmt.Errorf("cannot add %s to %s", v.String(), describeValueKind(total))
+		}
+		if v.IsError() {
+			return Value{}, fmt.Errorf("%s", v.Error)
+		}
+		sum.add(v.Number)
+		total.Rates = mergeRates(total.Rates, v.Rates)
+		if total.Type == ValueCurrency {
+			total.CurrencyStyle = mixCurrencyStyles(total, v)
+		}
+	}
+	total.Number = sum.total()
+	return total, nil
+}
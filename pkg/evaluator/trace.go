@@ -0,0 +1,147 @@
+package evaluator
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/andrewneudegg/calc/pkg/parser"
+)
+
+// Step is one record in an evaluation trace, such as "converted %v" for
+// 50 cm giving 0.5 m. Text holds a %v for each of Args so that the display
+// layer can format the values the way it formats results.
+type Step struct {
+	Text   string
+	Args   []Value
+	Result Value
+	// Note adds detail that is not a value, such as the exchange rate used.
+	Note string
+}
+
+// TraceFunc receives the steps of an evaluation as they happen.
+type TraceFunc func(Step)
+
+// SetTrace sets a function to receive a Step for each operation the
+// evaluator performs, or nil to stop tracing. Steps arrive innermost first,
+// so an operand's conversion is reported before the sum that needed it.
+func (e *Evaluator) SetTrace(f TraceFunc) {
+	e.trace = f
+	e.traced = nil
+	if f != nil {
+		e.traced = make(map[parser.Expr]Value)
+	}
+}
+
+// record reports a step to the trace function, if there is one.
+func (e *Evaluator) record(step Step) {
+	if e.trace != nil {
+		e.trace(step)
+	}
+}
+
+// recordConversion reports a conversion made on the way to a result, naming
+// the rate for currencies, whose conversions are otherwise easy to miss.
+func (e *Evaluator) recordConversion(from, to Value) {
+	if e.trace == nil || to.IsError() {
+		return
+	}
+	step := Step{Text: "converted %v", Args: []Value{from}, Result: to}
+	if from.Type == ValueCurrency && to.Type == ValueCurrency && from.Number != 0 {
+		rate := strconv.FormatFloat(to.Number/from.Number, 'g', 4, 64)
+		step.Note = fmt.Sprintf("at %s1 = %s%s", from.Currency, to.Currency, rate)
+	}
+	e.record(step)
+}
+
+// traceNode records the step for an evaluated node, using the values its
+// operands were traced with. Literals and conversions, which record their
+// own steps, are skipped, as is about, whose value is its operand's.
+func (e *Evaluator) traceNode(expr parser.Expr, v Value) {
+	e.traced[expr] = v
+	step := Step{Result: v}
+	switch n := expr.(type) {
+	case *parser.NumberExpr, *parser.StringExpr, *parser.UnitExpr, *parser.CurrencyExpr,
+		*parser.PercentExpr, *parser.ConversionExpr, *parser.MultiConversionExpr, *parser.ApproxExpr:
+		return
+	case *parser.IdentExpr:
+		step.Text = escapeVerbs(n.Name)
+	case *parser.AssignExpr:
+		step.Text = "set " + escapeVerbs(n.Name)
+	case *parser.DestructureExpr:
+		step.Text = "set " + escapeVerbs(strings.Join(n.Names, ", "))
+	case *parser.CompositeExpr:
+		step.Text = strings.TrimSuffix(strings.Repeat("%v + ", len(n.Parts)), " + ")
+		step.Args = e.operands(n.Parts...)
+	case *parser.BinaryExpr:
+		step.Text = "%v " + escapeVerbs(n.Operator) + " %v"
+		step.Args = e.operands(n.Left, n.Right)
+	case *parser.UnaryExpr:
+		step.Text = escapeVerbs(n.Operator) + "%v"
+		step.Args = e.operands(n.Operand)
+	case *parser.PercentOfExpr:
+		step.Text = "applied %v of %v"
+		step.Args = e.operands(n.Percent, n.Of)
+		// "10% of" parses the 10 alone
+		if len(step.Args) > 0 && step.Args[0].Type == ValueNumber {
+			step.Args[0] = NewPercent(step.Args[0].Number)
+		}
+	case *parser.NamedPercentOfExpr:
+		step.Text = "applied %v of %v"
+		step.Args = e.operands(n.Percent, n.Of)
+	case *parser.PercentChangeExpr:
+		step.Text = "increased %v by %v"
+		if !n.Increase {
+			step.Text = "decreased %v by %v"
+		}
+		step.Args = e.operands(n.Base, n.Percent)
+	case *parser.TipExpr:
+		step.Text = "tipped %v on %v"
+		step.Args = e.operands(n.Percent, n.Base)
+	case *parser.CompareExpr:
+		step.Text = "compared %v with %v"
+		step.Args = e.operands(n.Left, n.Right)
+	case *parser.TagExpr:
+		step.Text = n.Func + " of the lines tagged #" + escapeVerbs(n.Tag)
+	case *parser.WhatPercentExpr:
+		step.Text = "%v as a percentage of %v"
+		step.Args = e.operands(n.Part, n.Whole)
+	case *parser.RateExpr:
+		step.Text = "%v per %v"
+		step.Args = e.operands(n.Numerator, n.Denominator)
+	case *parser.FuzzyExpr:
+		step.Text = escapeVerbs(n.Pattern) + " %v"
+		step.Args = e.operands(n.Value)
+	case *parser.FunctionCallExpr:
+		step.Args = e.operands(n.Args...)
+		step.Text = escapeVerbs(n.Name) + "(" + strings.TrimSuffix(strings.Repeat("%v, ", len(n.Args)), ", ") + ")"
+	default:
+		step.Text = escapeVerbs(parser.SExpr(expr))
+	}
+	// Skip nodes whose operands did not all evaluate; an operand's failure
+	// has already been reported
+	if strings.Count(step.Text, "%v") != len(step.Args) || (v.IsError() && slices.ContainsFunc(step.Args, Value.IsError)) {
+		return
+	}
+	e.record(step)
+}
+
+// operands returns the traced values of exprs, stopping at the first that
+// was not evaluated because an earlier one failed.
+func (e *Evaluator) operands(exprs ...parser.Expr) []Value {
+	vals := make([]Value, 0, len(exprs))
+	for _, expr := range exprs {
+		v, ok := e.traced[expr]
+		if !ok {
+			break
+		}
+		vals = append(vals, v)
+	}
+	return vals
+}
+
+// escapeVerbs protects literal text in a Step's Text from formatting.
+func escapeVerbs(s string) string {
+	return strings.ReplaceAll(s, "%", "%%")
+}
@@ -2,7 +2,12 @@ package evaluator
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 	"time"
+
+	"github.com/andrewneudegg/calc/pkg/currency"
+	"github.com/andrewneudegg/calc/pkg/decimal"
 )
 
 // ValueType represents the type of a value.
@@ -16,6 +21,17 @@ const (
 	ValueDate
 	ValueString
 	ValueError
+	ValueList
+)
+
+// CurrencyStyle says whether an amount's currency is shown as its symbol or
+// its code, following how the amounts it was worked out from were written.
+type CurrencyStyle int
+
+const (
+	CurrencyStyleDefault CurrencyStyle = iota // Mixed or unstated; the currency-display setting decides
+	CurrencyStyleSymbol                       // Written with a symbol, as in £12
+	CurrencyStyleCode                         // Written with a code, as in 12 gbp
 )
 
 // Value represents an evaluated value.
@@ -27,8 +43,66 @@ type Value struct {
 	Date     time.Time
 	Text     string
 	Error    string
+	Items    []Value
+	// CurrencyStyle is how an amount's currency was written, so that
+	// 12 gbp + 3 gbp shows GBP 15.00 rather than £15.00.
+	CurrencyStyle CurrencyStyle
+	// Elapsed marks a "time" unit value as a span between two clock times
+	// (e.g. 17:30 - 09:15) rather than a time of day.
+	Elapsed bool
+	// Warning is an advisory note shown alongside an otherwise valid result.
+	Warning string
+	// Explicit marks a unit chosen with "in", which display preferences leave alone.
+	Explicit bool
+	// Rates are the exchange rates behind a converted currency amount. Only
+	// conversions set them; currency arithmetic carries its operands' rates.
+	Rates []currency.Rate
+	// Chain holds the values a chain of conversions passed through, source
+	// first, as in 5 miles in km in m. Only conversions set it.
+	Chain []Value
+	// Per is the quantity a rate was quoted against, as in £1.89 per 100g,
+	// where Number holds the rate per single unit. It is 0 for other values.
+	Per float64
+	// Label names an item of a result with several parts, such as the "tip"
+	// and "total" of 15% tip on £42.50.
+	Label string
+	// Whole marks a number that is an identifier-like count, such as epoch
+	// seconds or a week number, shown without decimals or grouping.
+	Whole bool
+	// Ingredient names what a mass or volume measures, as in 1 cup flour,
+	// so that it converts between the two by the ingredient's density.
+	Ingredient string
+	// Approx marks the result of a line starting with "about" or "roughly",
+	// shown rounded to a few significant figures. Number stays exact, and
+	// reading the value back, as with prev or a variable, drops the mark.
+	Approx bool
+	// exact is the decimal value of Number when it was computed in decimal
+	// mode; see Exact.
+	exact *decimal.Decimal
+	// origin is where a top-level result came from; see Provenance.
+	origin *Provenance
+}
+
+// Provenance records the line a result was evaluated from.
+type Provenance struct {
+	Input  string // The line as typed
+	Line   int    // Session line number, or line in the script when running a file
+	Assign bool   // The line assigned a variable
+}
+
+// String describes the line, e.g. "line 7: `total = a + b`".
+func (p Provenance) String() string {
+	input := p.Input
+	if r := []rune(input); len(r) > maxProvenanceInput {
+		input = string(r[:maxProvenanceInput]) + "…"
+	}
+	return fmt.Sprintf("line %d: `%s`", p.Line, input)
 }
 
+// maxProvenanceInput is how much of a line's text String quotes, so an error
+// about a very long line does not repeat all of it.
+const maxProvenanceInput = 60
+
 // NewNumber creates a new number value.
 func NewNumber(n float64) Value {
 	return Value{Type: ValueNumber, Number: n}
@@ -64,22 +138,88 @@ func NewError(msg string) Value {
 	return Value{Type: ValueError, Error: msg}
 }
 
+// NewList creates a new list value holding several results, e.g. the shares of a split.
+func NewList(items []Value) Value {
+	return Value{Type: ValueList, Items: items}
+}
+
+// Provenance reports the line a result came from. Only the REPL attaches it,
+// to the result of a whole line, so values met during evaluation have none.
+func (v Value) Provenance() (Provenance, bool) {
+	if v.origin == nil {
+		return Provenance{}, false
+	}
+	return *v.origin, true
+}
+
+// WithProvenance returns v marked as the result of the line p describes.
+func (v Value) WithProvenance(p Provenance) Value {
+	v.origin = &p
+	return v
+}
+
+// withoutProvenance returns v as an operand, without the line it came from.
+func (v Value) withoutProvenance() Value {
+	v.origin = nil
+	return v
+}
+
+// Precise returns v without the rounding "about" asked for, as the value
+// prev or a variable reads back.
+func (v Value) Precise() Value {
+	v.Approx = false
+	if len(v.Items) > 0 {
+		v.Items = slices.Clone(v.Items)
+		for i := range v.Items {
+			v.Items[i].Approx = false
+		}
+	}
+	return v
+}
+
 // IsError returns true if the value is an error.
 func (v Value) IsError() bool {
 	return v.Type == ValueError
 }
 
+// Warnings returns the distinct warnings attached to the value and, for lists, its items.
+func (v Value) Warnings() []string {
+	var out []string
+	seen := map[string]bool{}
+	add := func(w string) {
+		if w != "" && !seen[w] {
+			seen[w] = true
+			out = append(out, w)
+		}
+	}
+	add(v.Warning)
+	for _, item := range v.Items {
+		add(item.Warning)
+	}
+	return out
+}
+
 // String returns a string representation of the value.
 func (v Value) String() string {
+	return v.stringPlaces(2)
+}
+
+// stringPlaces is String with numbers to the given decimal places, or two
+// if places is negative.
+func (v Value) stringPlaces(places int) string {
+	if places < 0 {
+		places = 2
+	}
 	switch v.Type {
 	case ValueNumber:
-		return fmt.Sprintf("%.2f", v.Number)
+		return fmt.Sprintf("%.*f", places, v.Number)
 	case ValueUnit:
-		return fmt.Sprintf("%.2f %s", v.Number, v.Unit)
+		return fmt.Sprintf("%.*f %s", places, v.Number, v.Unit)
 	case ValueCurrency:
-		return fmt.Sprintf("%s%.2f", v.Currency, v.Number)
+		return fmt.Sprintf("%s%.*f", v.Currency, places, v.Number)
 	case ValuePercent:
-		return fmt.Sprintf("%.2f%%", v.Number)
+		n, suffix := v.PercentParts()
+		return fmt.Sprintf("%.*f%s", places, n, suffix)
 	case ValueDate:
 		// If time-of-day is non-zero, include time and timezone like formatter.formatDate
 		if v.Date.Hour() != 0 || v.Date.Minute() != 0 || v.Date.Second() != 0 {
@@ -90,7 +230,34 @@ func (v Value) String() string {
 		return v.Text
 	case ValueError:
 		return fmt.Sprintf("Error: %s", v.Error)
+	case ValueList:
+		parts := make([]string, len(v.Items))
+		for i, item := range v.Items {
+			parts[i] = item.stringPlaces(places)
+		}
+		return strings.Join(parts, ", ")
 	default:
 		return "unknown"
 	}
 }
+
+// mergeRates combines rate lists, keeping the first rate for each currency
+// pair so that a rate used twice is reported once.
+func mergeRates(lists ...[]currency.Rate) []currency.Rate {
+	var merged []currency.Rate
+	for _, rates := range lists {
+		for _, r := range rates {
+			dup := false
+			for _, m := range merged {
+				if m.From == r.From && m.To == r.To {
+					dup = true
+					break
+				}
+			}
+			if !dup {
+				merged = append(merged, r)
+			}
+		}
+	}
+	return merged
+}
@@ -0,0 +1,65 @@
+package formatter
+
+import (
+	"math"
+	"strings"
+
+	"github.com/andrewneudegg/calc/pkg/evaluator"
+)
+
+// alsoUnits lists, under the first name of a unit, the companion units a
+// result in that unit is also shown in by :set also on. Metric lengths,
+// masses, volumes and speeds gain imperial companions and the reverse,
+// temperatures the other scales, and data sizes the next size down and up.
+var alsoUnits = map[string][]string{
+	"km": {"mi", "ft"}, "m": {"ft", "in"}, "cm": {"in", "mm"}, "mm": {"in", "cm"},
+	"mi": {"km", "ft"}, "yd": {"m", "ft"}, "ft": {"m", "in"}, "in": {"cm", "mm"},
+
+	"tonne": {"ton", "kg"}, "kg": {"lb", "st"}, "g": {"oz", "mg"},
+	"ton": {"tonne", "lb"}, "st": {"kg", "lb"}, "lb": {"kg", "oz"}, "oz": {"g", "lb"},
+
+	"c": {"f", "k"}, "f": {"c", "k"}, "k": {"c", "f"},
+
+	"l": {"gal", "pint"}, "ml": {"floz", "cup"},
+	"gal": {"l", "ukgal"}, "pint": {"ml", "l"}, "floz": {"ml", "tbsp"},
+
+	"kph": {"mph", "mps"}, "mph": {"kph", "mps"}, "mps": {"kph", "mph"},
+
+	"b": {"KB"}, "kb": {"B", "MB"}, "mb": {"KB", "GB"}, "gb": {"MB", "TB"},
+	"tb": {"GB", "PB"}, "pb": {"TB"},
+}
+
+// Also returns val in its companion units for :set also on, such as
+// "also: 62.14 mi · 328,083.99 ft" for 100 km, or "" when val is not a plain
+// unit result or its unit has no companions.
+func (f *Formatter) Also(val evaluator.Value) string {
+	if val.IsError() || val.Type != evaluator.ValueUnit || val.Per != 0 || val.Unit == "time" {
+		return ""
+	}
+	names := f.units.Aliases(val.Unit)
+	if len(names) == 0 {
+		return ""
+	}
+	var parts []string
+	for _, unit := range alsoUnits[strings.ToLower(names[0])] {
+		n, err := f.units.Convert(val.Number, val.Unit, unit)
+		if err != nil {
+			continue
+		}
+		if math.Abs(n) < 0.01 && math.Abs(val.Number) >= 0.01 {
+			// Too small a figure in this unit to be a useful companion
+			continue
+		}
+		companion := evaluator.NewUnit(n, unit)
+		companion.Explicit = true // Shown as named, whatever the display preferences
+		parts = append(parts, f.Format(companion))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	sep := " · "
+	if f.useASCII() {
+		sep = " | "
+	}
+	return "also: " + strings.Join(parts, sep)
+}
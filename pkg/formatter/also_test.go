@@ -0,0 +1,44 @@
+package formatter
+
+import (
+	"testing"
+
+	"github.com/andrewneudegg/calc/pkg/evaluator"
+	"github.com/andrewneudegg/calc/pkg/settings"
+)
+
+func TestAlso(t *testing.T) {
+	s := settings.Default()
+	s.ASCII = "off"
+	s.Prefer = "imperial" // companions are shown as named regardless
+	f := New(s)
+
+	rate := unitValue(1.89, "£/g")
+	rate.Per = 100
+	tests := []struct {
+		val  evaluator.Value
+		want string
+	}{
+		{unitValue(100, "km"), "also: 62.14 mi · 328,083.99 ft"},
+		{unitValue(62.14, "miles"), "also: 100.00 km · 328,099.20 ft"},
+		{unitValue(20, "celsius"), "also: 68.00 f · 293.15 k"},
+		{unitValue(2, "GB"), "also: 2,048.00 MB"},
+		{unitValue(1, "B"), ""},
+		{unitValue(0.001, "B"), "also: 9.77e-07 KB"},
+		{unitValue(3, "hours"), ""},
+		{evaluator.NewNumber(5), ""},
+		{evaluator.NewCurrency(5, "£"), ""},
+		{rate, ""},
+		{evaluator.NewError("boom"), ""},
+	}
+	for _, tt := range tests {
+		if got := f.Also(tt.val); got != tt.want {
+			t.Errorf("Also(%v %s) = %q, want %q", tt.val.Number, tt.val.Unit, got, tt.want)
+		}
+	}
+
+	s.ASCII = "on"
+	if got := f.Also(unitValue(1, "kg")); got != "also: 2.20 lb | 0.16 st" {
+		t.Errorf("ASCII also line = %q", got)
+	}
+}
@@ -0,0 +1,77 @@
+package formatter
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/andrewneudegg/calc/pkg/currency"
+	"github.com/andrewneudegg/calc/pkg/evaluator"
+	"github.com/andrewneudegg/calc/pkg/rounding"
+)
+
+// formatApprox shows the result of a line starting with "about" or
+// "roughly" rounded to the approx-sigfigs setting's significant figures,
+// after a ≈, as in ≈ 1,600 or ≈ £1,300. It reports false for values that
+// are not marked or are not amounts, such as dates and clock times.
+func (f *Formatter) formatApprox(val evaluator.Value) (string, bool) {
+	if !val.Approx || math.IsInf(val.Number, 0) || math.IsNaN(val.Number) {
+		return "", false
+	}
+	var out string
+	switch val.Type {
+	case evaluator.ValueNumber:
+		out = f.approxNumber(val.Number)
+	case evaluator.ValueUnit:
+		if val.Unit == "time" || val.Per != 0 {
+			return "", false
+		}
+		if val.Unit == "" {
+			out = f.approxNumber(val.Number)
+			break
+		}
+		if pref, ok := f.preferred(val); ok {
+			val = pref
+		}
+		out = fmt.Sprintf("%s %s", f.approxNumber(val.Number), val.Unit)
+	case evaluator.ValueCurrency:
+		cur := f.currencyLabel(val)
+		if currency.IsCodeSymbol(cur) {
+			out = fmt.Sprintf("%s %s", cur, f.approxNumber(val.Number))
+		} else {
+			out = cur + f.approxNumber(val.Number)
+		}
+	case evaluator.ValuePercent:
+		n, suffix := val.PercentParts()
+		out = f.approxNumber(n) + suffix
+	default:
+		return "", false
+	}
+	return "≈ " + out, true
+}
+
+// approxNumber rounds n to the approx-sigfigs setting's significant
+// figures, so 1554 shows as 1,600 and 0.012345 as 0.012.
+func (f *Formatter) approxNumber(n float64) string {
+	if n == 0 {
+		return "0"
+	}
+	sigfigs := max(f.settings.ApproxSigFigs, 1)
+	places := sigfigs - 1 - int(math.Floor(math.Log10(math.Abs(n))))
+	rounded := roundToPlaces(rounding.Mode(f.settings.Rounding), n, places)
+	// Rounding up to the next power of ten, as 996 to 1,000, leaves a
+	// decimal place too many
+	if places > 0 && math.Abs(rounded) >= math.Pow(10, float64(sigfigs-places)) {
+		places--
+	}
+	return f.formatDecimal(rounded, max(places, 0), true)
+}
+
+// roundToPlaces rounds x to places decimal places under m. Places below
+// zero round to tens, hundreds and so on, as -2 rounds 1554 to 1600.
+func roundToPlaces(m rounding.Mode, x float64, places int) float64 {
+	if places >= 0 {
+		return m.Places(x, places)
+	}
+	step := math.Pow(10, float64(-places))
+	return m.Round(x/step) * step
+}
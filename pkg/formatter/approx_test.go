@@ -0,0 +1,73 @@
+package formatter
+
+import (
+	"testing"
+
+	"github.com/andrewneudegg/calc/pkg/evaluator"
+	"github.com/andrewneudegg/calc/pkg/settings"
+)
+
+func approx(v evaluator.Value) evaluator.Value {
+	v.Approx = true
+	return v
+}
+
+func TestFormatApprox(t *testing.T) {
+	s := settings.Default()
+	s.ASCII = "off"
+	f := New(s)
+
+	miles := evaluator.NewUnit(766.77, "miles")
+	miles.Explicit = true
+	code := evaluator.NewCurrency(44.4, "£")
+	code.CurrencyStyle = evaluator.CurrencyStyleCode
+
+	tests := []struct {
+		val  evaluator.Value
+		want string
+	}{
+		{approx(evaluator.NewNumber(1554)), "≈ 1,600"},
+		{approx(evaluator.NewNumber(-1554)), "≈ -1,600"},
+		{approx(evaluator.NewNumber(0.012345)), "≈ 0.012"},
+		{approx(evaluator.NewNumber(3.04)), "≈ 3"},
+		{approx(evaluator.NewNumber(9.96)), "≈ 10"},
+		{approx(evaluator.NewNumber(0)), "≈ 0"},
+		{approx(miles), "≈ 770 miles"},
+		{approx(evaluator.NewCurrency(1274.5, "£")), "≈ £1,300"},
+		{approx(code), "≈ GBP 44"},
+		{approx(evaluator.NewPercent(12.34)), "≈ 12%"},
+		// Dates and clock times have no figures to round
+		{approx(evaluator.NewUnit(9.5, "time")), "09:30"},
+		// Unmarked values are shown in full
+		{evaluator.NewNumber(1554), "1,554.00"},
+	}
+	for _, tt := range tests {
+		if got := f.Format(tt.val); got != tt.want {
+			t.Errorf("Format(%v) = %q, want %q", tt.val, got, tt.want)
+		}
+	}
+}
+
+func TestFormatApproxSigFigs(t *testing.T) {
+	s := settings.Default()
+	s.ASCII = "off"
+	s.ApproxSigFigs = 3
+	f := New(s)
+
+	if got := f.Format(approx(evaluator.NewNumber(1554))); got != "≈ 1,550" {
+		t.Errorf("3 figures: got %q, want ≈ 1,550", got)
+	}
+	if got := f.Format(approx(evaluator.NewNumber(0.0012345))); got != "≈ 0.00123" {
+		t.Errorf("3 figures: got %q, want ≈ 0.00123", got)
+	}
+
+	s.ApproxSigFigs = 1
+	if got := f.Format(approx(evaluator.NewCurrency(1554, "£"))); got != "≈ £2,000" {
+		t.Errorf("1 figure: got %q, want ≈ £2,000", got)
+	}
+
+	s.Rounding = "floor"
+	if got := f.Format(approx(evaluator.NewNumber(1999))); got != "≈ 1,000" {
+		t.Errorf("floor: got %q, want ≈ 1,000", got)
+	}
+}
@@ -0,0 +1,103 @@
+package formatter
+
+import (
+	"os"
+	"runtime"
+	"strings"
+)
+
+// asciiFallbacks maps the non-ASCII symbols that can appear in results to
+// forms that survive terminals without UTF-8 support. Currency symbols become
+// code prefixes so "£100.00" reads "GBP 100.00".
+var asciiFallbacks = []struct {
+	symbol string
+	ascii  string
+}{
+	{"£", "GBP "},
+	{"€", "EUR "},
+	{"¥", "JPY "},
+	{"₹", "INR "},
+	{"₩", "KRW "},
+	{"₺", "TRY "},
+	{"µ", "u"}, // micro sign
+	{"μ", "u"}, // Greek mu
+	{"²", "^2"},
+	{"³", "^3"},
+	{"⁴", "^4"},
+	{"°", "deg"},
+	{"·", "*"},
+	{"×", "x"},
+	{"…", "..."},
+	{"≈", "~"},
+	{"→", "->"},
+	{"Ω", "ohm"},
+	{"α", "alpha"},
+	{"ε", "epsilon"},
+	{"σ", "sigma"},
+	{"π", "pi"},
+	{"ℏ", "hbar"},
+	{"∞", "inf"},
+	{"⊕", "earth"},
+	{"☉", "sun"},
+}
+
+var asciiReplacer = func() *strings.Replacer {
+	pairs := make([]string, 0, len(asciiFallbacks)*2)
+	for _, fb := range asciiFallbacks {
+		pairs = append(pairs, fb.symbol, fb.ascii)
+	}
+	return strings.NewReplacer(pairs...)
+}()
+
+// ToASCII rewrites s using only ASCII characters. Known symbols use their
+// fallback forms; anything else outside ASCII, such as box-drawing or
+// alignment characters, is dropped.
+func ToASCII(s string) string {
+	s = asciiReplacer.Replace(s)
+	return strings.Map(func(r rune) rune {
+		if r > 0x7f {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// localeIsUTF8 reports whether the terminal locale from the environment can
+// display UTF-8. LC_ALL overrides LC_CTYPE, which overrides LANG. With no
+// locale set, UTF-8 is assumed except on Windows consoles outside Windows Terminal.
+func localeIsUTF8(getenv func(string) string, goos string) bool {
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if v := getenv(name); v != "" {
+			v = strings.ToLower(v)
+			return strings.Contains(v, "utf-8") || strings.Contains(v, "utf8")
+		}
+	}
+	if goos == "windows" {
+		return getenv("WT_SESSION") != ""
+	}
+	return true
+}
+
+// ASCII reports whether output is restricted to ASCII symbols, for callers
+// that print symbols of their own alongside formatted values.
+func (f *Formatter) ASCII() bool {
+	return f.useASCII()
+}
+
+// useASCII reports whether results should be restricted to ASCII, honouring
+// the "ascii" setting before falling back to the detected locale.
+func (f *Formatter) useASCII() bool {
+	switch f.settings.ASCII {
+	case "on":
+		return true
+	case "off":
+		return false
+	default:
+		return !f.utf8
+	}
+}
+
+// detectUTF8 inspects the process environment once per formatter.
+func detectUTF8() bool {
+	return localeIsUTF8(os.Getenv, runtime.GOOS)
+}
@@ -0,0 +1,127 @@
+package formatter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andrewneudegg/calc/pkg/evaluator"
+	"github.com/andrewneudegg/calc/pkg/settings"
+)
+
+func isASCII(s string) bool {
+	for _, r := range s {
+		if r > 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+func asciiTestValues() []struct {
+	val   evaluator.Value
+	utf8  string
+	ascii string
+} {
+	return []struct {
+		val   evaluator.Value
+		utf8  string
+		ascii string
+	}{
+		{evaluator.NewNumber(1234.5), "1,234.50", "1,234.50"},
+		{evaluator.NewUnit(10, "m²"), "10.00 m²", "10.00 m^2"},
+		{evaluator.NewUnit(3, "m³"), "3.00 m³", "3.00 m^3"},
+		{evaluator.NewUnit(5, "µs"), "5.00 µs", "5.00 us"},
+		{evaluator.NewUnit(20, "°"), "20.00 °", "20.00 deg"},
+		{evaluator.NewUnit(2, "kg·m"), "2.00 kg·m", "2.00 kg*m"},
+		{evaluator.NewUnit(14.5, "time"), "14:30", "14:30"},
+		{evaluator.NewCurrency(100, "£"), "£100.00", "GBP 100.00"},
+		{evaluator.NewCurrency(-5, "€"), "€-5.00", "EUR -5.00"},
+		{evaluator.NewCurrency(1000, "¥"), "¥1,000", "JPY 1,000"},
+		{evaluator.NewCurrency(10, "$"), "$10.00", "$10.00"},
+		{evaluator.NewPercent(20), "20.00%", "20.00%"},
+		{evaluator.NewDate(time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)), "2 Jan 2025", "2 Jan 2025"},
+		{evaluator.NewString("Ω ≈ 1…"), "Ω ≈ 1…", "ohm ~ 1..."},
+		{evaluator.NewString("│ box ─"), "│ box ─", " box "},
+		{evaluator.NewError("cannot add £ to kg"), "Error: cannot add £ to kg", "Error: cannot add GBP  to kg"},
+		{evaluator.NewList([]evaluator.Value{evaluator.NewCurrency(1, "£"), evaluator.NewCurrency(2, "€")}), "£1.00, €2.00", "GBP 1.00, EUR 2.00"},
+	}
+}
+
+func TestASCIIModeEveryValueType(t *testing.T) {
+	s := settings.Default()
+	s.ASCII = "on"
+	f := New(s)
+
+	for _, tt := range asciiTestValues() {
+		got := f.Format(tt.val)
+		if got != tt.ascii {
+			t.Errorf("ascii Format(%v) = %q, want %q", tt.val, got, tt.ascii)
+		}
+		if !isASCII(got) {
+			t.Errorf("ascii Format(%v) = %q contains non-ASCII", tt.val, got)
+		}
+	}
+}
+
+func TestUTF8ModeIsUnchanged(t *testing.T) {
+	for _, mode := range []string{"off", "auto"} {
+		s := settings.Default()
+		s.ASCII = mode
+		f := New(s)
+		f.utf8 = true
+
+		for _, tt := range asciiTestValues() {
+			if got := f.Format(tt.val); got != tt.utf8 {
+				t.Errorf("%s: Format(%v) = %q, want %q", mode, tt.val, got, tt.utf8)
+			}
+		}
+	}
+}
+
+func TestAutoModeFollowsLocale(t *testing.T) {
+	s := settings.Default()
+	f := New(s)
+	f.utf8 = false
+
+	if got := f.Format(evaluator.NewCurrency(100, "£")); got != "GBP 100.00" {
+		t.Errorf("auto mode without UTF-8 = %q, want %q", got, "GBP 100.00")
+	}
+}
+
+func TestASCIIFallbacksAreASCII(t *testing.T) {
+	for _, fb := range asciiFallbacks {
+		if isASCII(fb.symbol) {
+			t.Errorf("fallback for %q is for a symbol that is already ASCII", fb.symbol)
+		}
+		if !isASCII(fb.ascii) {
+			t.Errorf("fallback %q for %q is not ASCII", fb.ascii, fb.symbol)
+		}
+		if got := ToASCII(fb.symbol); got != fb.ascii {
+			t.Errorf("ToASCII(%q) = %q, want %q", fb.symbol, got, fb.ascii)
+		}
+	}
+}
+
+func TestLocaleIsUTF8(t *testing.T) {
+	tests := []struct {
+		env  map[string]string
+		goos string
+		want bool
+	}{
+		{map[string]string{"LANG": "en_GB.UTF-8"}, "linux", true},
+		{map[string]string{"LANG": "en_US.utf8"}, "linux", true},
+		{map[string]string{"LANG": "C"}, "linux", false},
+		{map[string]string{"LC_ALL": "C", "LANG": "en_GB.UTF-8"}, "linux", false},
+		{map[string]string{"LC_CTYPE": "en_GB.UTF-8", "LANG": "C"}, "linux", true},
+		{map[string]string{}, "linux", true},
+		{map[string]string{}, "windows", false},
+		{map[string]string{"WT_SESSION": "1"}, "windows", true},
+	}
+
+	for _, tt := range tests {
+		getenv := func(k string) string { return tt.env[k] }
+		if got := localeIsUTF8(getenv, tt.goos); got != tt.want {
+			t.Errorf("localeIsUTF8(%v, %s) = %v, want %v", tt.env, tt.goos, got, tt.want)
+		}
+	}
+}
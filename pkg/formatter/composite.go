@@ -0,0 +1,66 @@
+package formatter
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/andrewneudegg/calc/pkg/evaluator"
+)
+
+// compositeUnits maps each unit :set composite splits to the next unit down,
+// written the same way, so 5.5 ft shows as 5 ft 6 in and 5.5 feet as
+// 5 feet 6 inches.
+var compositeUnits = map[string]string{
+	"ft": "in", "foot": "inches", "feet": "inches",
+	"yd": "ft", "yard": "feet", "yards": "feet",
+	"lb": "oz", "lbs": "oz", "pound": "ounces", "pounds": "ounces",
+	"st": "lb", "stone": "pounds", "stones": "pounds",
+	"h": "min", "hour": "minutes", "hours": "minutes",
+	"min": "s", "minute": "seconds", "minutes": "seconds",
+	"day": "hours", "days": "hours",
+}
+
+// formatComposite shows a value as whole units and a remainder in the next
+// unit down, as in 5 ft 10.08 in, when :set composite is on and its unit has
+// one. A part that would be zero is left out.
+func (f *Formatter) formatComposite(val evaluator.Value) (string, bool) {
+	small, ok := compositeUnits[val.Unit]
+	if !ok || !f.settings.Composite || val.Per != 0 || math.IsInf(val.Number, 0) || math.IsNaN(val.Number) {
+		return "", false
+	}
+	factor, err := f.units.Convert(1, val.Unit, small)
+	if err != nil || factor <= 1 {
+		return "", false
+	}
+
+	n := math.Abs(val.Number)
+	whole := math.Floor(n)
+	rest := f.round((n-whole)*factor, f.settings.Precision)
+	if rest >= factor {
+		// 5.9999 ft is 6 ft, not 5 ft 12.00 in
+		whole, rest = whole+1, 0
+	}
+
+	var parts []string
+	if whole != 0 {
+		parts = append(parts, f.formatWithCommas(whole, 0)+" "+val.Unit)
+	}
+	if rest != 0 || whole == 0 {
+		parts = append(parts, trimZeros(strconv.FormatFloat(rest, 'f', f.settings.Precision, 64))+" "+small)
+	}
+	out := strings.Join(parts, " ")
+	if val.Number < 0 && (whole != 0 || rest != 0) {
+		out = "-" + out
+	}
+	return out, true
+}
+
+// trimZeros drops the zeros after a decimal point, and the point if nothing
+// follows it, so 10.50 reads 10.5 and 6.00 reads 6.
+func trimZeros(s string) string {
+	if !strings.Contains(s, ".") {
+		return s
+	}
+	return strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+}
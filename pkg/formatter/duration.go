@@ -0,0 +1,73 @@
+package formatter
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/andrewneudegg/calc/pkg/evaluator"
+	"github.com/andrewneudegg/calc/pkg/units"
+)
+
+// subSecondUnits are the units a compact duration under a second is shown
+// in, largest first, with how many make a second.
+var subSecondUnits = []struct {
+	unit  string
+	scale float64
+}{{"ms", 1e3}, {"µs", 1e6}, {"ns", 1e9}}
+
+// formatCompactDuration shows an amount of time as a Go duration, as in
+// 2h15m or 1.5s, when :set durations compact is on. Amounts converted with
+// "in" keep the unit asked for, and clock times keep their HH:MM.
+func (f *Formatter) formatCompactDuration(val evaluator.Value) (string, bool) {
+	if f.settings.Durations != "compact" || val.Unit == "time" || val.Explicit || val.Per != 0 ||
+		math.IsInf(val.Number, 0) || math.IsNaN(val.Number) {
+		return "", false
+	}
+	if dim, err := f.units.GetDimension(val.Unit); err != nil || dim != units.DimensionTime {
+		return "", false
+	}
+	secs, err := f.units.Convert(val.Number, val.Unit, "s")
+	if err != nil {
+		return "", false
+	}
+
+	sign := ""
+	if secs < 0 {
+		sign, secs = "-", -secs
+	}
+	places := f.settings.Precision
+	if secs > 0 && secs < 1 {
+		for _, u := range subSecondUnits {
+			if n := secs * u.scale; n >= 1 || u.unit == "ns" {
+				return sign + f.durationNumber(n, places) + u.unit, true
+			}
+		}
+	}
+
+	secs = f.round(secs, places)
+	hours := math.Floor(secs / 3600)
+	minutes := math.Floor((secs - hours*3600) / 60)
+	rest := f.round(secs-hours*3600-minutes*60, places)
+
+	var b strings.Builder
+	if hours > 0 {
+		b.WriteString(strconv.FormatFloat(hours, 'f', 0, 64) + "h")
+	}
+	if minutes > 0 {
+		b.WriteString(strconv.FormatFloat(minutes, 'f', 0, 64) + "m")
+	}
+	if rest > 0 || b.Len() == 0 {
+		b.WriteString(f.durationNumber(rest, places) + "s")
+	}
+	if b.String() == "0s" {
+		sign = ""
+	}
+	return sign + b.String(), true
+}
+
+// durationNumber writes n to places decimals without trailing zeros, as in
+// 1.5 or 45.
+func (f *Formatter) durationNumber(n float64, places int) string {
+	return trimZeros(strconv.FormatFloat(f.round(n, places), 'f', places, 64))
+}
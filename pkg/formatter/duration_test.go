@@ -0,0 +1,51 @@
+package formatter
+
+import (
+	"testing"
+
+	"github.com/andrewneudegg/calc/pkg/evaluator"
+	"github.com/andrewneudegg/calc/pkg/settings"
+)
+
+func TestFormatCompactDuration(t *testing.T) {
+	s := settings.Default()
+	s.ASCII = "off"
+	f := New(s)
+
+	explicit := evaluator.NewUnit(150, "minutes")
+	explicit.Explicit = true
+	clock := evaluator.NewUnit(9.25, "time")
+	tests := []struct {
+		val  evaluator.Value
+		want string
+	}{
+		{evaluator.NewUnit(2.25, "h"), "2h15m"},
+		{evaluator.NewUnit(90, "s"), "1m30s"},
+		{evaluator.NewUnit(1.5, "s"), "1.5s"},
+		{evaluator.NewUnit(45, "min"), "45m"},
+		{evaluator.NewUnit(2, "hours"), "2h"},
+		{evaluator.NewUnit(1, "days"), "24h"},
+		{evaluator.NewUnit(-0.5, "h"), "-30m"},
+		{evaluator.NewUnit(0, "s"), "0s"},
+		{evaluator.NewUnit(0.0015, "s"), "1.5ms"},
+		{evaluator.NewUnit(250, "us"), "250µs"},
+		{evaluator.NewUnit(3, "ns"), "3ns"},
+		{evaluator.NewUnit(3599.999, "s"), "1h"},
+		// Units asked for, clock times and other kinds are left alone
+		{explicit, "150.00 minutes"},
+		{clock, "09:15"},
+		{evaluator.NewUnit(5, "km"), "5.00 km"},
+	}
+
+	s.Durations = "compact"
+	for _, tt := range tests {
+		if got := f.Format(tt.val); got != tt.want {
+			t.Errorf("Format(%v %s) = %q, want %q", tt.val.Number, tt.val.Unit, got, tt.want)
+		}
+	}
+
+	s.Durations = "units"
+	if got := f.Format(evaluator.NewUnit(2.25, "h")); got != "2.25 h" {
+		t.Errorf("durations units: Format(2.25 h) = %q", got)
+	}
+}
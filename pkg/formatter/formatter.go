@@ -3,56 +3,178 @@ package formatter
 import (
 	"fmt"
 	"math"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/andrewneudegg/calc/pkg/currency"
 	"github.com/andrewneudegg/calc/pkg/evaluator"
+	"github.com/andrewneudegg/calc/pkg/rounding"
 	"github.com/andrewneudegg/calc/pkg/settings"
+	"github.com/andrewneudegg/calc/pkg/units"
 )
 
 // Formatter formats values according to settings.
 type Formatter struct {
 	settings *settings.Settings
+	utf8     bool // Whether the terminal locale can display UTF-8
+	units    *units.System
 }
 
 // New creates a new formatter.
 func New(s *settings.Settings) *Formatter {
-	return &Formatter{settings: s}
+	return &Formatter{settings: s, utf8: detectUTF8(), units: units.NewSystem()}
 }
 
-// Format formats a value according to settings.
+// Format formats a value according to settings. When the terminal cannot be
+// assumed to handle UTF-8, symbols are replaced with ASCII fallbacks.
 func (f *Formatter) Format(val evaluator.Value) string {
+	out := f.format(val)
+	if f.settings.ShowRates && !val.IsError() {
+		for _, r := range val.Rates {
+			out += " (" + f.rateText(r) + ")"
+		}
+	} else if !val.IsError() {
+		out += f.historicalNote(val.Rates)
+	}
+	if f.useASCII() {
+		return ToASCII(out)
+	}
+	return out
+}
+
+func (f *Formatter) format(val evaluator.Value) string {
 	if val.IsError() {
 		return fmt.Sprintf("Error: %s", val.Error)
 	}
+	if approx, ok := f.formatApprox(val); ok {
+		return approx
+	}
 
 	switch val.Type {
 	case evaluator.ValueNumber:
+		if val.Whole {
+			return strconv.FormatFloat(val.Number, 'f', 0, 64)
+		}
 		return f.formatNumber(val.Number)
 	case evaluator.ValueUnit:
 		// Special formatting for "time" unit - display as HH:MM
-		if val.Unit == "time" {
+		if val.Unit == "time" && val.Elapsed {
 			return f.formatTime(val.Number)
 		}
+		if val.Unit == "time" {
+			return f.formatClock(val.Number)
+		}
 		// Use scientific notation for very small or very large numbers in units
 		if val.Unit == "" {
 			return f.formatNumberSmart(val.Number)
 		}
+		if val.Per != 0 && f.settings.RateForm != "normalised" {
+			return f.formatQuotedRate(val)
+		}
+		if compact, ok := f.formatCompactDuration(val); ok {
+			return compact
+		}
+		if pace, ok := f.formatPace(val); ok {
+			return pace
+		}
+		if pref, ok := f.preferred(val); ok {
+			if f.settings.PreferOriginal {
+				return fmt.Sprintf("%s %s (%s %s)", f.formatNumberSmart(pref.Number), pref.Unit, f.formatNumberSmart(val.Number), val.Unit)
+			}
+			val = pref
+		}
+		if composite, ok := f.formatComposite(val); ok {
+			return composite
+		}
 		return fmt.Sprintf("%s %s", f.formatNumberSmart(val.Number), val.Unit)
 	case evaluator.ValueCurrency:
-		return fmt.Sprintf("%s%s", val.Currency, f.formatNumber(val.Number))
+		cur := f.currencyLabel(val)
+		amount := f.formatDecimal(val.Number, f.currencyPlaces(val.Currency), false)
+		if currency.IsCodeSymbol(cur) {
+			// Codes are set apart from the amount, as in CHF 100.00
+			return fmt.Sprintf("%s %s", cur, amount)
+		}
+		return fmt.Sprintf("%s%s", cur, amount)
 	case evaluator.ValuePercent:
-		return fmt.Sprintf("%s%%", f.formatNumber(val.Number))
+		n, suffix := val.PercentParts()
+		return f.formatDecimal(n, f.settings.PercentPrecision, f.settings.TrimZeros) + suffix
 	case evaluator.ValueDate:
 		return f.formatDate(val.Date)
 	case evaluator.ValueString:
 		// Return the string as-is
 		return val.Text
+	case evaluator.ValueList:
+		parts := make([]string, len(val.Items))
+		for i, item := range val.Items {
+			parts[i] = f.formatItem(item)
+		}
+		return strings.Join(parts, ", ")
 	default:
 		return "unknown"
 	}
 }
 
+// currencyLabel is what an amount's currency is shown as: its code when it
+// was written with one, as in 12 gbp, and its symbol when it was written
+// with that. Amounts mixing the two follow the currency-display setting.
+func (f *Formatter) currencyLabel(val evaluator.Value) string {
+	switch val.CurrencyStyle {
+	case evaluator.CurrencyStyleSymbol:
+		return val.Currency
+	case evaluator.CurrencyStyleCode:
+		return currency.Code(val.Currency)
+	}
+	if f.settings.CurrencyDisplay == "code" {
+		return currency.Code(val.Currency)
+	}
+	return val.Currency
+}
+
+// formatQuotedRate shows a rate against the quantity it was quoted per, so
+// £1.89 per 100g reads 1.89 £/100 g rather than 0.0189 £/g.
+func (f *Formatter) formatQuotedRate(val evaluator.Value) string {
+	num, denom, _ := strings.Cut(val.Unit, "/")
+	per := strconv.FormatFloat(val.Per, 'f', -1, 64)
+	return fmt.Sprintf("%s %s/%s %s", f.formatNumberSmart(val.Number*val.Per), num, per, denom)
+}
+
+// paceMinutes are the minute units a pace is written in, as in 5:30 min/km.
+var paceMinutes = map[string]bool{"min": true, "mins": true, "minute": true, "minutes": true}
+
+// formatPace shows minutes per distance as M:SS, as runners write a pace, so
+// 5.5 min/km reads 5:30 min/km. It reports false for other units.
+func (f *Formatter) formatPace(val evaluator.Value) (string, bool) {
+	num, _, _ := strings.Cut(strings.ToLower(val.Unit), "/")
+	if !paceMinutes[num] || !f.units.IsPace(val.Unit) || val.Number < 0 || math.IsInf(val.Number, 0) || math.IsNaN(val.Number) {
+		return "", false
+	}
+	secs := int64(math.Round(val.Number * 60))
+	return fmt.Sprintf("%d:%02d %s", secs/60, secs%60, val.Unit), true
+}
+
+// FormatLines formats a value as one line per result. List values, such as
+// conversions to several targets, produce a line per item; other values a single line.
+func (f *Formatter) FormatLines(val evaluator.Value) []string {
+	if val.Type != evaluator.ValueList {
+		return []string{f.Format(val)}
+	}
+	lines := make([]string, len(val.Items))
+	for i, item := range val.Items {
+		lines[i] = f.formatItem(item)
+	}
+	return lines
+}
+
+// formatItem formats one item of a list, after its label if it has one, as
+// in "tip £6.38".
+func (f *Formatter) formatItem(item evaluator.Value) string {
+	if item.Label != "" && !item.IsError() {
+		return item.Label + " " + f.Format(item)
+	}
+	return f.Format(item)
+}
+
 func (f *Formatter) formatDate(d time.Time) string {
 	// If the time has a non-zero time component (hours, minutes, seconds),
 	// show the time as well as the date
@@ -79,19 +201,67 @@ func (f *Formatter) formatTime(decimalHours float64) string {
 	return fmt.Sprintf("%02d:%02d", hours, minutes)
 }
 
+// formatClock shows a time of day as HH:MM on the 24-hour clock. A time
+// taken past midnight either way, as by 23:30 + 2, notes the days it moved,
+// as in "01:30 (+1 day)". Seconds are dropped, as a clock would.
+func (f *Formatter) formatClock(decimalHours float64) string {
+	const day = 24 * 60
+	// Whole seconds first, so that float error cannot lose a minute
+	minutes := int(math.Floor(math.Round(decimalHours*3600) / 60))
+	days := minutes / day
+	if minutes < 0 && minutes%day != 0 {
+		days--
+	}
+	minutes -= days * day
+	clock := fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+	switch days {
+	case 0:
+		return clock
+	case 1, -1:
+		return fmt.Sprintf("%s (%+d day)", clock, days)
+	default:
+		return fmt.Sprintf("%s (%+d days)", clock, days)
+	}
+}
+
 func (f *Formatter) formatNumber(n float64) string {
+	return f.formatDecimal(n, f.settings.Precision, f.settings.TrimZeros)
+}
+
+// formatDecimal formats n to places decimal places, dropping the zeros at
+// the end when trim is set.
+func (f *Formatter) formatDecimal(n float64, places int, trim bool) string {
+	// Past the sci-above setting, digits beyond float64's ~16 significant
+	// figures are noise, so show the magnitude instead
+	if math.Abs(n) >= math.Pow(10, float64(f.settings.SciAbove)) {
+		return fmt.Sprintf("%.*e", places, n)
+	}
+
 	// Round to precision
-	rounded := f.round(n, f.settings.Precision)
+	rounded := f.round(n, places)
 
 	// Format with thousand separators for UK/US locales
 	// Both UK and US use commas for thousands and periods for decimals
+	var out string
 	if f.settings.Locale == "en_GB" || f.settings.Locale == "en_UK" || f.settings.Locale == "en_US" {
-		return f.formatWithCommas(rounded, f.settings.Precision)
+		out = f.formatWithCommas(rounded, places)
+	} else {
+		// Default format
+		out = fmt.Sprintf("%.*f", places, rounded)
+	}
+	if trim {
+		return trimZeros(out)
 	}
+	return out
+}
 
-	// Default format
-	format := fmt.Sprintf("%%.%df", f.settings.Precision)
-	return fmt.Sprintf(format, rounded)
+// currencyPlaces returns the decimal places amounts of cur are shown to: the
+// currency-precision setting, or the currency's own under auto.
+func (f *Formatter) currencyPlaces(cur string) int {
+	if places, err := strconv.Atoi(f.settings.CurrencyPrecision); err == nil {
+		return places
+	}
+	return currency.MinorUnits(cur)
 }
 
 // formatNumberSmart formats a number, using scientific notation for very small/large values
@@ -99,11 +269,11 @@ func (f *Formatter) formatNumberSmart(n float64) string {
 	absN := math.Abs(n)
 	
 	// Use scientific notation when the number would round to zero with current precision
-	// or when the number is very large (>= 1 million)
+	// or when the number is very large (>= 1 million, or sooner under sci-above)
 	// This helps display physical constants properly
 	if absN != 0 {
 		rounded := f.round(n, f.settings.Precision)
-		if (rounded == 0 && absN > 0) || absN >= 1e6 {
+		if (rounded == 0 && absN > 0) || absN >= math.Min(1e6, math.Pow(10, float64(f.settings.SciAbove))) {
 			// Number is too small or too large for normal formatting, use scientific notation
 			// Use the user's precision setting for consistency
 			return fmt.Sprintf("%.*e", f.settings.Precision, n)
@@ -114,18 +284,16 @@ func (f *Formatter) formatNumberSmart(n float64) string {
 	return f.formatNumber(n)
 }
 
+// round rounds val for display under the "rounding" setting.
 func (f *Formatter) round(val float64, decimals int) float64 {
-	pow := math.Pow(10, float64(decimals))
-	return math.Round(val*pow) / pow
+	return rounding.Mode(f.settings.Rounding).Places(val, decimals)
 }
 
 func (f *Formatter) formatWithCommas(n float64, decimals int) string {
-	// Split into integer and decimal parts
-	integer := int64(math.Abs(n))
-	decimal := n - float64(int64(n))
+	// Format the digits as text, which stays exact beyond the range of int64
+	intStr, decStr, _ := strings.Cut(strconv.FormatFloat(math.Abs(n), 'f', decimals, 64), ".")
 
 	// Format integer part with commas
-	intStr := fmt.Sprintf("%d", integer)
 	var parts []string
 
 	for i := len(intStr); i > 0; i -= 3 {
@@ -145,13 +313,7 @@ func (f *Formatter) formatWithCommas(n float64, decimals int) string {
 
 	// Add decimal part if needed
 	if decimals > 0 {
-		decStr := fmt.Sprintf("%.*f", decimals, math.Abs(decimal))
-		// Remove leading "0"
-		if len(decStr) > 2 {
-			result += decStr[1:]
-		} else {
-			result += ".00"
-		}
+		result += "." + decStr
 	}
 
 	return result
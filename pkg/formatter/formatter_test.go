@@ -88,9 +88,37 @@ func TestFormatUnit(t *testing.T) {
 	}
 }
 
+func TestFormatClockTime(t *testing.T) {
+	f := New(settings.Default())
+	tests := []struct {
+		hours   float64
+		elapsed bool
+		want    string
+	}{
+		{14.5, false, "14:30"},
+		{17.3, false, "17:18"},
+		{12.5125, false, "12:30"},
+		{25.5, false, "01:30 (+1 day)"},
+		{24, false, "00:00 (+1 day)"},
+		{82, false, "10:00 (+3 days)"},
+		{-1, false, "23:00 (-1 day)"},
+		{-24, false, "00:00 (-1 day)"},
+		{-25, false, "23:00 (-2 days)"},
+		// Spans between two times are not wrapped
+		{-8.5, true, "-08:30"},
+		{30, true, "30:00"},
+	}
+	for _, tt := range tests {
+		val := evaluator.Value{Type: evaluator.ValueUnit, Number: tt.hours, Unit: "time", Elapsed: tt.elapsed}
+		if got := f.Format(val); got != tt.want {
+			t.Errorf("Format(%v hours, elapsed %v) = %q, want %q", tt.hours, tt.elapsed, got, tt.want)
+		}
+	}
+}
+
 func TestFormatPercent(t *testing.T) {
 	s := settings.Default()
-	s.Precision = 0
+	s.PercentPrecision = 0
 	f := New(s)
 
 	tests := []struct {
@@ -149,3 +177,227 @@ func TestFormatDateWithTime(t *testing.T) {
 		t.Errorf("Format(date without time) = %q, want %q", result2, expected2)
 	}
 }
+
+func TestFormatRoundingModes(t *testing.T) {
+	tests := []struct {
+		rounding  string
+		precision int
+		input     float64
+		expected  string
+	}{
+		{"half-up", 0, 2.5, "3"},
+		{"half-up", 0, -2.5, "-3"},
+		{"half-even", 0, 2.5, "2"},
+		{"half-even", 0, -2.5, "-2"},
+		{"half-even", 2, 2.665, "2.66"},
+		{"floor", 0, -2.5, "-3"},
+		{"floor", 2, 0.29, "0.29"},
+		{"ceil", 0, 2.1, "3"},
+		{"ceil", 2, -2.555, "-2.55"},
+		{"truncate", 0, -2.9, "-2"},
+		{"truncate", 2, 1234.5678, "1,234.56"},
+	}
+
+	for _, tt := range tests {
+		s := settings.Default()
+		s.Rounding = tt.rounding
+		s.Precision = tt.precision
+		f := New(s)
+		val := evaluator.NewNumber(tt.input)
+		if got := f.Format(val); got != tt.expected {
+			t.Errorf("%s: Format(%v) = %q, want %q", tt.rounding, tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestFormatSciAbove(t *testing.T) {
+	tests := []struct {
+		sciAbove int
+		val      evaluator.Value
+		expected string
+	}{
+		{15, evaluator.NewNumber(123456789012345), "123,456,789,012,345.00"},
+		{15, evaluator.NewNumber(1e15), "1.00e+15"},
+		{15, evaluator.NewNumber(-1e20), "-1.00e+20"},
+		{15, evaluator.NewCurrency(2.5e16, "£"), "£2.50e+16"},
+		{15, evaluator.NewUnit(9.4607304725808e18, "mm"), "9.46e+18 mm"},
+		{15, evaluator.NewUnit(2500000, "m"), "2.50e+06 m"},
+		{4, evaluator.NewNumber(12345), "1.23e+04"},
+		{4, evaluator.NewNumber(9999), "9,999.00"},
+		{4, evaluator.NewUnit(12345, "m"), "1.23e+04 m"},
+		{30, evaluator.NewNumber(1e20), "100,000,000,000,000,000,000.00"},
+	}
+
+	for _, tt := range tests {
+		s := settings.Default()
+		s.SciAbove = tt.sciAbove
+		f := New(s)
+		if got := f.Format(tt.val); got != tt.expected {
+			t.Errorf("sci-above %d: Format(%v) = %q, want %q", tt.sciAbove, tt.val.Number, got, tt.expected)
+		}
+	}
+}
+
+func TestFormatRatePerQuantity(t *testing.T) {
+	rate := evaluator.Value{Type: evaluator.ValueUnit, Number: 0.0189, Unit: "£/g", Per: 100}
+
+	s := settings.Default()
+	if got, want := New(s).Format(rate), "1.89 £/100 g"; got != want {
+		t.Errorf("original form: got %q, want %q", got, want)
+	}
+
+	s.RateForm = "normalised"
+	s.Precision = 4
+	if got, want := New(s).Format(rate), "0.0189 £/g"; got != want {
+		t.Errorf("normalised form: got %q, want %q", got, want)
+	}
+}
+
+func TestFormatLabelledItems(t *testing.T) {
+	f := New(settings.Default())
+	tip := evaluator.NewCurrency(6.38, "£")
+	tip.Label = "tip"
+	total := evaluator.NewCurrency(48.88, "£")
+	total.Label = "total"
+	val := evaluator.NewList([]evaluator.Value{tip, total})
+
+	if got, want := f.Format(val), "tip £6.38, total £48.88"; got != want {
+		t.Errorf("Format() = %q, want %q", got, want)
+	}
+	lines := f.FormatLines(val)
+	if len(lines) != 2 || lines[0] != "tip £6.38" || lines[1] != "total £48.88" {
+		t.Errorf("FormatLines() = %q", lines)
+	}
+}
+
+func TestFormatPace(t *testing.T) {
+	f := New(settings.Default())
+	tests := []struct {
+		val  evaluator.Value
+		want string
+	}{
+		{evaluator.NewUnit(5.5, "min/km"), "5:30 min/km"},
+		{evaluator.NewUnit(8.0467, "min/mile"), "8:03 min/mile"},
+		{evaluator.NewUnit(9.999, "min/mile"), "10:00 min/mile"},
+		{evaluator.NewUnit(90, "s/km"), "90.00 s/km"},
+		{evaluator.NewUnit(2.5, "min/kg"), "2.50 min/kg"},
+	}
+	for _, tt := range tests {
+		if got := f.Format(tt.val); got != tt.want {
+			t.Errorf("Format(%v %s) = %q, want %q", tt.val.Number, tt.val.Unit, got, tt.want)
+		}
+	}
+}
+
+func TestFormatWholeNumber(t *testing.T) {
+	f := New(settings.Default())
+	unix := evaluator.NewNumber(1717200000)
+	unix.Whole = true
+	if got := f.Format(unix); got != "1717200000" {
+		t.Errorf("Format(whole) = %q, want 1717200000", got)
+	}
+}
+
+func TestFormatCurrencySymbolsAndCodes(t *testing.T) {
+	f := New(settings.Default())
+	tests := []struct {
+		symbol string
+		want   string
+	}{
+		{"₹", "₹1,234.50"},
+		{"₩", "₩1,235"},
+		{"R$", "R$1,234.50"},
+		{"CHF", "CHF 1,234.50"},
+	}
+	for _, tt := range tests {
+		if got := f.Format(evaluator.NewCurrency(1234.5, tt.symbol)); got != tt.want {
+			t.Errorf("Format(%s) = %q, want %q", tt.symbol, got, tt.want)
+		}
+	}
+}
+
+func TestFormatCurrencyStyle(t *testing.T) {
+	s := settings.Default()
+	f := New(s)
+	style := func(symbol string, cs evaluator.CurrencyStyle) evaluator.Value {
+		v := evaluator.NewCurrency(15, symbol)
+		v.CurrencyStyle = cs
+		return v
+	}
+
+	tests := []struct {
+		name    string
+		value   evaluator.Value
+		display string // The currency-display setting
+		want    string
+	}{
+		{"symbol", style("£", evaluator.CurrencyStyleSymbol), "code", "£15.00"},
+		{"code", style("£", evaluator.CurrencyStyleCode), "symbol", "GBP 15.00"},
+		{"code without a symbol", style("CHF", evaluator.CurrencyStyleCode), "symbol", "CHF 15.00"},
+		{"mixed, shown as symbol", style("£", evaluator.CurrencyStyleDefault), "symbol", "£15.00"},
+		{"mixed, shown as code", style("€", evaluator.CurrencyStyleDefault), "code", "EUR 15.00"},
+	}
+	for _, tt := range tests {
+		s.CurrencyDisplay = tt.display
+		if got := f.Format(tt.value); got != tt.want {
+			t.Errorf("%s: Format = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFormatComposite(t *testing.T) {
+	s := settings.Default()
+	f := New(s)
+	tests := []struct {
+		value float64
+		unit  string
+		want  string
+	}{
+		{5.84, "ft", "5 ft 10.08 in"},
+		{5.5, "feet", "5 feet 6 inches"},
+		{2.5, "hours", "2 hours 30 minutes"},
+		{6.25, "lb", "6 lb 4 oz"},
+		{-1.5, "ft", "-1 ft 6 in"},
+		{0.25, "ft", "3 in"},
+		{6, "ft", "6 ft"},
+		{5.99999, "ft", "6 ft"},
+		{3, "km", "3.00 km"},
+	}
+
+	for _, tt := range tests {
+		val := evaluator.NewUnit(tt.value, tt.unit)
+		s.Composite = false
+		if got, want := f.Format(val), f.formatNumberSmart(tt.value)+" "+tt.unit; got != want {
+			t.Errorf("composite off: Format(%v %s) = %q, want %q", tt.value, tt.unit, got, want)
+		}
+		s.Composite = true
+		if got := f.Format(val); got != tt.want {
+			t.Errorf("Format(%v %s) = %q, want %q", tt.value, tt.unit, got, tt.want)
+		}
+	}
+}
+
+func TestFormatScaledPercent(t *testing.T) {
+	s := settings.Default()
+	s.PercentPrecision = 0
+	f := New(s)
+
+	tests := []struct {
+		value    float64
+		unit     string
+		expected string
+	}{
+		{0.25, "bps", "25 bps"},
+		{0.3, "‰", "3‰"},
+		{0.5, "permille", "5 permille"},
+		{0.02, "‱", "2‱"},
+	}
+
+	for _, tt := range tests {
+		val := evaluator.Value{Type: evaluator.ValuePercent, Number: tt.value, Unit: tt.unit}
+		result := f.Format(val)
+		if result != tt.expected {
+			t.Errorf("Format(%v %s) = %q, want %q", tt.value, tt.unit, result, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package formatter
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/andrewneudegg/calc/pkg/currency"
+)
+
+// rateText writes a rate as "1 GBP = 1.27 USD", followed by the day for a
+// past rate, as in "1 GBP = 1.273 USD on 15 Mar 2024".
+func (f *Formatter) rateText(r currency.Rate) string {
+	if r.On.IsZero() {
+		return r.String()
+	}
+	return r.String() + " on " + r.On.Format(f.settings.DateFormat)
+}
+
+// historicalNote notes the days of any past exchange rates behind a result,
+// as in " (15 Mar 2024 rate)", so a converted amount says when it applied.
+func (f *Formatter) historicalNote(rates []currency.Rate) string {
+	var days []string
+	for _, r := range rates {
+		if day := r.On.Format(f.settings.DateFormat); !r.On.IsZero() && !slices.Contains(days, day) {
+			days = append(days, day)
+		}
+	}
+	switch len(days) {
+	case 0:
+		return ""
+	case 1:
+		return " (" + days[0] + " rate)"
+	default:
+		return " (" + strings.Join(days, ", ") + " rates)"
+	}
+}
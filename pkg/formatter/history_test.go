@@ -0,0 +1,48 @@
+package formatter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andrewneudegg/calc/pkg/currency"
+	"github.com/andrewneudegg/calc/pkg/evaluator"
+	"github.com/andrewneudegg/calc/pkg/settings"
+)
+
+func TestFormatHistoricalRate(t *testing.T) {
+	march15 := currency.Rate{From: "GBP", To: "USD", Rate: 1.2734, Source: currency.SourceHistorical, On: time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)}
+	march18 := currency.Rate{From: "USD", To: "EUR", Rate: 0.92, Source: currency.SourceHistorical, On: time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC)}
+	today := currency.Rate{From: "GBP", To: "USD", Rate: 1.27, Source: currency.SourceBuiltIn}
+
+	tests := []struct {
+		name      string
+		rates     []currency.Rate
+		showRates bool
+		want      string
+	}{
+		{"past rate", []currency.Rate{march15}, false, "$127.34 (15 Mar 2024 rate)"},
+		{"two past rates", []currency.Rate{march15, march18}, false, "$127.34 (15 Mar 2024, 18 Mar 2024 rates)"},
+		{"same day twice", []currency.Rate{march15, march15}, false, "$127.34 (15 Mar 2024 rate)"},
+		{"today's rate", []currency.Rate{today}, false, "$127.34"},
+		{"show rates", []currency.Rate{march15}, true, "$127.34 (1 GBP = 1.273 USD on 15 Mar 2024)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := settings.Default()
+			s.ShowRates = tt.showRates
+			val := evaluator.NewCurrency(127.34, "$")
+			val.Rates = tt.rates
+			if got := New(s).Format(val); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	s := settings.Default()
+	val := evaluator.NewCurrency(127.34, "$")
+	val.Rates = []currency.Rate{march15}
+	notes := New(s).RateNotes(val)
+	if len(notes) != 1 || notes[0] != "rate: 1 GBP = 1.273 USD on 15 Mar 2024" {
+		t.Errorf("unexpected rate notes: %q", notes)
+	}
+}
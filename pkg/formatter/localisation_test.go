@@ -148,7 +148,7 @@ func TestLocalisationPercentFormatting(t *testing.T) {
 	for _, tt := range tests {
 		s := settings.Default()
 		s.Locale = tt.locale
-		s.Precision = tt.precision
+		s.PercentPrecision = tt.precision
 		f := New(s)
 
 		val := evaluator.Value{Type: evaluator.ValuePercent, Number: tt.percent}
@@ -0,0 +1,27 @@
+package formatter
+
+import (
+	"strconv"
+
+	"github.com/andrewneudegg/calc/pkg/evaluator"
+)
+
+// FormatPlaces formats a value as Format does, to the given decimal places
+// or the precision setting if places is negative, without exchange rates.
+// print uses it for placeholders such as {total} and {total:2}.
+func (f *Formatter) FormatPlaces(val evaluator.Value, places int) string {
+	g := f
+	if places >= 0 {
+		s := *f.settings
+		s.Precision = places
+		s.CurrencyPrecision = strconv.Itoa(places)
+		s.PercentPrecision = places
+		g = &Formatter{settings: &s, utf8: f.utf8, units: f.units}
+		val.Whole = false
+	}
+	out := g.format(val)
+	if g.useASCII() {
+		return ToASCII(out)
+	}
+	return out
+}
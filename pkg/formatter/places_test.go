@@ -0,0 +1,42 @@
+package formatter
+
+import (
+	"testing"
+
+	"github.com/andrewneudegg/calc/pkg/currency"
+	"github.com/andrewneudegg/calc/pkg/evaluator"
+	"github.com/andrewneudegg/calc/pkg/settings"
+)
+
+func TestFormatPlaces(t *testing.T) {
+	s := settings.Default()
+	s.ASCII = "off"
+	s.ShowRates = true
+	f := New(s)
+
+	// Rates shown by show-rates are left to the line's result
+	money := evaluator.NewCurrency(1234.5678, "£")
+	money.Rates = []currency.Rate{{From: "USD", To: "GBP", Rate: 0.79, Source: currency.SourceBuiltIn}}
+	whole := evaluator.NewNumber(7)
+	whole.Whole = true
+	tests := []struct {
+		val    evaluator.Value
+		places int
+		want   string
+	}{
+		{money, -1, "£1,234.57"},
+		{money, 0, "£1,235"},
+		{money, 3, "£1,234.568"},
+		{unitValue(2.5, "kg"), 1, "2.5 kg"},
+		{whole, -1, "7"},
+		{whole, 2, "7.00"},
+	}
+	for _, tt := range tests {
+		if got := f.FormatPlaces(tt.val, tt.places); got != tt.want {
+			t.Errorf("FormatPlaces(%v, %d) = %q, want %q", tt.val.Number, tt.places, got, tt.want)
+		}
+	}
+	if s.Precision != 2 {
+		t.Errorf("FormatPlaces changed the precision setting to %d", s.Precision)
+	}
+}
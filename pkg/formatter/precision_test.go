@@ -0,0 +1,95 @@
+package formatter
+
+import (
+	"testing"
+
+	"github.com/andrewneudegg/calc/pkg/evaluator"
+	"github.com/andrewneudegg/calc/pkg/settings"
+)
+
+func TestFormatPrecisionByType(t *testing.T) {
+	pounds := evaluator.NewCurrency(12.5, "£")
+	yen := evaluator.NewCurrency(1234.5, "¥")
+	chf := evaluator.NewCurrency(7, "CHF")
+	number := evaluator.NewNumber(3.14159)
+	round := evaluator.NewNumber(2.5)
+	distance := evaluator.NewUnit(2.5, "km")
+	percent := evaluator.NewPercent(12.5)
+	bps := evaluator.Value{Type: evaluator.ValuePercent, Number: 0.25, Unit: "bps"}
+	values := []evaluator.Value{pounds, yen, chf, number, round, distance, percent, bps}
+
+	tests := []struct {
+		name     string
+		settings func(*settings.Settings)
+		want     []string
+	}{
+		{
+			name:     "defaults",
+			settings: func(*settings.Settings) {},
+			want:     []string{"£12.50", "¥1,235", "CHF 7.00", "3.14", "2.50", "2.50 km", "12.50%", "25.00 bps"},
+		},
+		{
+			name:     "precision 4",
+			settings: func(s *settings.Settings) { s.Precision = 4 },
+			want:     []string{"£12.50", "¥1,235", "CHF 7.00", "3.1416", "2.5000", "2.5000 km", "12.50%", "25.00 bps"},
+		},
+		{
+			name:     "precision 0",
+			settings: func(s *settings.Settings) { s.Precision = 0 },
+			want:     []string{"£12.50", "¥1,235", "CHF 7.00", "3", "3", "3 km", "12.50%", "25.00 bps"},
+		},
+		{
+			name:     "precision 4 trimmed",
+			settings: func(s *settings.Settings) { s.Precision, s.TrimZeros = 4, true },
+			want:     []string{"£12.50", "¥1,235", "CHF 7.00", "3.1416", "2.5", "2.5 km", "12.5%", "25 bps"},
+		},
+		{
+			name:     "currency precision 2",
+			settings: func(s *settings.Settings) { s.CurrencyPrecision = "2" },
+			want:     []string{"£12.50", "¥1,234.50", "CHF 7.00", "3.14", "2.50", "2.50 km", "12.50%", "25.00 bps"},
+		},
+		{
+			name: "currency precision 3, percent precision 0",
+			settings: func(s *settings.Settings) {
+				s.CurrencyPrecision, s.PercentPrecision, s.TrimZeros = "3", 0, true
+			},
+			want: []string{"£12.500", "¥1,234.500", "CHF 7.000", "3.14", "2.5", "2.5 km", "13%", "25 bps"},
+		},
+		{
+			name:     "other locale",
+			settings: func(s *settings.Settings) { s.Locale, s.Precision, s.TrimZeros = "de_DE", 3, true },
+			want:     []string{"£12.50", "¥1235", "CHF 7.00", "3.142", "2.5", "2.5 km", "12.5%", "25 bps"},
+		},
+	}
+
+	for _, tt := range tests {
+		s := settings.Default()
+		s.ASCII = "off"
+		tt.settings(s)
+		f := New(s)
+		for i, val := range values {
+			if got := f.Format(val); got != tt.want[i] {
+				t.Errorf("%s: Format(%v) = %q, want %q", tt.name, val, got, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestFormatPlacesCoversEveryType(t *testing.T) {
+	s := settings.Default()
+	s.ASCII = "off"
+	s.TrimZeros = true
+	f := New(s)
+	for _, tt := range []struct {
+		val  evaluator.Value
+		want string
+	}{
+		{evaluator.NewCurrency(12.5, "£"), "£13"},
+		{evaluator.NewNumber(2.5), "3"},
+		{evaluator.NewPercent(12.5), "13%"},
+	} {
+		if got := f.FormatPlaces(tt.val, 0); got != tt.want {
+			t.Errorf("FormatPlaces(%v, 0) = %q, want %q", tt.val, got, tt.want)
+		}
+	}
+}
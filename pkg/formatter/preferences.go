@@ -0,0 +1,139 @@
+package formatter
+
+import (
+	"math"
+	"strings"
+
+	"github.com/andrewneudegg/calc/pkg/evaluator"
+	"github.com/andrewneudegg/calc/pkg/units"
+)
+
+// imperialUnits and metricUnits list the units :set prefer converts away from.
+// Units that belong to neither system, such as kelvin or knots, are left alone.
+var imperialUnits = toSet(
+	"ft", "foot", "feet", "in", "inch", "inches", "yd", "yard", "yards", "mi", "mile", "miles",
+	"lb", "lbs", "pound", "pounds", "oz", "ounce", "ounces", "stone", "stones", "st", "ton", "tons",
+	"f", "fahrenheit", "r", "rankine", "°r",
+	"ft3", "ft³", "in3", "in³", "usgal", "usgallon", "usgallons", "gal", "gallon", "gallons",
+	"usquart", "usquarts", "quart", "quarts", "qt", "uspint", "uspints", "pint", "pints", "pt",
+	"cup", "cups", "floz", "fluidounce", "fluidounces", "tbsp", "tablespoon", "tablespoons",
+	"tsp", "teaspoon", "teaspoons", "ukgal", "ukgallon", "ukgallons", "impgal", "imperialgallon",
+	"ukquart", "ukquarts", "ukpint", "ukpints", "imppint", "imperialpint",
+	"mph", "fps",
+	"sqft", "ft2", "ft²", "sqin", "in2", "in²", "sqyd", "yd2", "yd²", "sqmi", "mi2", "mi²",
+	"squarefoot", "squarefeet", "squareinch", "squareinches", "squareyard", "squareyards",
+	"squaremile", "squaremiles", "acre", "acres",
+)
+
+var metricUnits = toSet(
+	"m", "metre", "metres", "meter", "meters", "cm", "mm", "km",
+	"kg", "kilogram", "kilograms", "g", "gram", "grams", "mg", "milligram", "milligrams",
+	"µg", "ug", "microgram", "micrograms", "tonne", "tonnes",
+	"c", "celsius",
+	"l", "litre", "litres", "liter", "liters", "ml", "millilitre", "millilitres", "milliliter",
+	"milliliters", "cl", "centilitre", "centilitres", "centiliter", "centiliters", "dl",
+	"decilitre", "decilitres", "deciliter", "deciliters", "m3", "m³", "cm3", "cm³", "cc", "mm3", "mm³",
+	"kph", "kmh", "mps",
+	"sqm", "m2", "m²", "sqmm", "mm2", "mm²", "sqcm", "cm2", "cm²", "sqkm", "km2", "km²",
+	"squaremetre", "squaremetres", "squaremeter", "squaremeters", "squarekilometre",
+	"squarekilometres", "squarekilometer", "squarekilometers", "hectare", "hectares", "ha",
+)
+
+// preferredLadders lists, smallest first, the units a result is shown in for
+// each system. The largest unit that keeps the value at or above one is used.
+var preferredLadders = map[string]map[units.Dimension][]string{
+	"metric": {
+		units.DimensionLength:      {"mm", "cm", "m", "km"},
+		units.DimensionMass:        {"g", "kg", "tonne"},
+		units.DimensionTemperature: {"c"},
+		units.DimensionVolume:      {"ml", "l"},
+		units.DimensionSpeed:       {"kph"},
+		units.DimensionArea:        {"m²", "km²"},
+	},
+	"imperial": {
+		units.DimensionLength:      {"in", "ft", "mi"},
+		units.DimensionMass:        {"oz", "lb"},
+		units.DimensionTemperature: {"f"},
+		units.DimensionVolume:      {"floz", "gal"},
+		units.DimensionSpeed:       {"mph"},
+		units.DimensionArea:        {"ft²", "acres"},
+	},
+}
+
+func toSet(names ...string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, n := range names {
+		set[n] = true
+	}
+	return set
+}
+
+// preferred converts a unit value to the display unit chosen with :set prefer
+// or a per-dimension prefer-<dimension> setting. Values converted with "in",
+// clock times and compound units are returned unchanged, as is anything that
+// cannot be converted. The second result reports whether a conversion happened.
+func (f *Formatter) preferred(val evaluator.Value) (evaluator.Value, bool) {
+	if val.Type != evaluator.ValueUnit || val.Explicit || val.Unit == "" || val.Unit == "time" {
+		return val, false
+	}
+	unit := strings.ToLower(val.Unit)
+	dim, err := f.units.GetDimension(unit)
+	if err != nil {
+		return val, false
+	}
+
+	target := ""
+	if fixed := f.dimensionPreference(dim); fixed != "" && fixed != "off" {
+		target = fixed
+	} else {
+		system := f.settings.Prefer
+		ladder, ok := preferredLadders[system][dim]
+		if !ok || (system == "metric" && !imperialUnits[unit]) || (system == "imperial" && !metricUnits[unit]) {
+			return val, false
+		}
+		target = f.pickFromLadder(val.Number, unit, ladder)
+	}
+	if target == "" || strings.EqualFold(target, unit) {
+		return val, false
+	}
+
+	n, err := f.units.Convert(val.Number, unit, target)
+	if err != nil {
+		return val, false
+	}
+	out := val
+	out.Number = n
+	out.Unit = target
+	return out, true
+}
+
+// dimensionPreference returns the fixed display unit set for a dimension, if any.
+func (f *Formatter) dimensionPreference(dim units.Dimension) string {
+	switch dim {
+	case units.DimensionLength:
+		return f.settings.PreferLength
+	case units.DimensionMass:
+		return f.settings.PreferMass
+	case units.DimensionTemperature:
+		return f.settings.PreferTemperature
+	case units.DimensionVolume:
+		return f.settings.PreferVolume
+	case units.DimensionSpeed:
+		return f.settings.PreferSpeed
+	case units.DimensionArea:
+		return f.settings.PreferArea
+	}
+	return ""
+}
+
+// pickFromLadder chooses the largest unit in ladder that shows n (in unit) as
+// at least one, falling back to the smallest.
+func (f *Formatter) pickFromLadder(n float64, unit string, ladder []string) string {
+	for i := len(ladder) - 1; i > 0; i-- {
+		v, err := f.units.Convert(n, unit, ladder[i])
+		if err == nil && math.Abs(v) >= 1 {
+			return ladder[i]
+		}
+	}
+	return ladder[0]
+}
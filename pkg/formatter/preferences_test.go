@@ -0,0 +1,109 @@
+package formatter
+
+import (
+	"testing"
+
+	"github.com/andrewneudegg/calc/pkg/evaluator"
+	"github.com/andrewneudegg/calc/pkg/settings"
+)
+
+func unitValue(n float64, unit string) evaluator.Value {
+	return evaluator.Value{Type: evaluator.ValueUnit, Number: n, Unit: unit}
+}
+
+func TestPreferMetric(t *testing.T) {
+	s := settings.Default()
+	s.Prefer = "metric"
+	f := New(s)
+
+	tests := []struct {
+		val  evaluator.Value
+		want string
+	}{
+		{unitValue(32, "f"), "0.00 c"},
+		{unitValue(10, "miles"), "16.09 km"},
+		{unitValue(6, "inches"), "15.24 cm"},
+		{unitValue(5, "lb"), "2.27 kg"},
+		{unitValue(1, "gallon"), "3.79 l"},
+		{unitValue(60, "mph"), "96.56 kph"},
+		{unitValue(5, "km"), "5.00 km"},       // already metric
+		{unitValue(300, "k"), "300.00 k"},     // kelvin belongs to neither system
+		{unitValue(1.5, "time"), "01:30"},     // clock times are never converted
+		{unitValue(3, "hours"), "3.00 hours"}, // no preference for time
+	}
+	for _, tt := range tests {
+		if got := f.Format(tt.val); got != tt.want {
+			t.Errorf("Format(%v %s) = %q, want %q", tt.val.Number, tt.val.Unit, got, tt.want)
+		}
+	}
+}
+
+func TestPreferImperial(t *testing.T) {
+	s := settings.Default()
+	s.Prefer = "imperial"
+	f := New(s)
+
+	tests := []struct {
+		val  evaluator.Value
+		want string
+	}{
+		{unitValue(100, "c"), "212.00 f"},
+		{unitValue(5, "km"), "3.11 mi"},
+		{unitValue(30, "cm"), "11.81 in"},
+		{unitValue(1, "kg"), "2.20 lb"},
+		{unitValue(10, "miles"), "10.00 miles"},
+	}
+	for _, tt := range tests {
+		if got := f.Format(tt.val); got != tt.want {
+			t.Errorf("Format(%v %s) = %q, want %q", tt.val.Number, tt.val.Unit, got, tt.want)
+		}
+	}
+}
+
+func TestPreferLeavesExplicitConversions(t *testing.T) {
+	s := settings.Default()
+	s.Prefer = "metric"
+	s.PreferTemperature = "k"
+	f := New(s)
+
+	val := unitValue(32, "f")
+	val.Explicit = true
+	if got := f.Format(val); got != "32.00 f" {
+		t.Errorf("explicit conversion should be kept, got %q", got)
+	}
+}
+
+func TestPreferDimensionOverride(t *testing.T) {
+	s := settings.Default()
+	s.Prefer = "metric"
+	s.PreferLength = "m"
+	f := New(s)
+
+	if got := f.Format(unitValue(3, "km")); got != "3,000.00 m" {
+		t.Errorf("prefer-length should apply to metric units too, got %q", got)
+	}
+	if got := f.Format(unitValue(32, "f")); got != "0.00 c" {
+		t.Errorf("other dimensions should follow prefer, got %q", got)
+	}
+}
+
+func TestPreferOriginal(t *testing.T) {
+	s := settings.Default()
+	s.Prefer = "metric"
+	s.PreferOriginal = true
+	f := New(s)
+
+	if got := f.Format(unitValue(32, "f")); got != "0.00 c (32.00 f)" {
+		t.Errorf("Format = %q, want converted and original values", got)
+	}
+	if got := f.Format(unitValue(5, "km")); got != "5.00 km" {
+		t.Errorf("unconverted values should not repeat themselves, got %q", got)
+	}
+}
+
+func TestPreferOff(t *testing.T) {
+	f := New(settings.Default())
+	if got := f.Format(unitValue(32, "f")); got != "32.00 f" {
+		t.Errorf("Format = %q, want value unchanged by default", got)
+	}
+}
@@ -0,0 +1,21 @@
+package formatter
+
+import (
+	"strings"
+
+	"github.com/andrewneudegg/calc/pkg/evaluator"
+)
+
+// Steps returns the conversions behind val on one line for :set show-steps
+// on, such as "steps: 5 miles = 8.05 km = 8,046.72 m", or "" when val is not
+// the result of a conversion.
+func (f *Formatter) Steps(val evaluator.Value) string {
+	if val.IsError() || !val.ChainEnds() {
+		return ""
+	}
+	parts := make([]string, len(val.Chain))
+	for i, link := range val.Chain {
+		parts[i] = f.Format(link)
+	}
+	return "steps: " + strings.Join(parts, " = ")
+}
@@ -0,0 +1,35 @@
+package formatter
+
+import (
+	"time"
+
+	"github.com/andrewneudegg/calc/pkg/evaluator"
+)
+
+// RateNotes returns a line such as "rate: 1 GBP = 1.27 USD" for each exchange
+// rate behind val, shown under results at the verbose output level. It gives
+// nil when :set show-rates on already shows the rates after the result.
+func (f *Formatter) RateNotes(val evaluator.Value) []string {
+	if f.settings.ShowRates || val.IsError() {
+		return nil
+	}
+	var notes []string
+	for _, r := range val.Rates {
+		note := "rate: " + f.rateText(r)
+		if f.useASCII() {
+			note = ToASCII(note)
+		}
+		notes = append(notes, note)
+	}
+	return notes
+}
+
+// Elapsed describes how long a line took to evaluate, such as "took 152µs",
+// for the verbose output level.
+func (f *Formatter) Elapsed(d time.Duration) string {
+	out := "took " + max(d.Round(time.Microsecond), time.Microsecond).String()
+	if f.useASCII() {
+		return ToASCII(out)
+	}
+	return out
+}
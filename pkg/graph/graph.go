@@ -2,6 +2,9 @@ package graph
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 )
 
 // Node represents a line in the calculation graph.
@@ -52,16 +55,40 @@ func (g *Graph) GetDependents(id int) []int {
 	return dependents
 }
 
-// TopologicalSort returns nodes in evaluation order.
+// CycleError reports a circular dependency. Path lists the lines around the
+// cycle, starting and ending with the same line.
+type CycleError struct {
+	Path []int
+}
+
+func (e *CycleError) Error() string {
+	parts := make([]string, len(e.Path))
+	for i, id := range e.Path {
+		parts[i] = strconv.Itoa(id)
+	}
+	return fmt.Sprintf("circular dependency detected between lines %s", strings.Join(parts, " -> "))
+}
+
+// TopologicalSort returns nodes in evaluation order. Nodes that do not depend
+// on each other keep ascending ID order, so lines numbered in the order they
+// were written only move when they have to. A cycle is reported as a
+// *CycleError.
 func (g *Graph) TopologicalSort() ([]int, error) {
 	visited := make(map[int]bool)
 	tempMark := make(map[int]bool)
 	var result []int
+	var path []int
 	
 	var visit func(int) error
 	visit = func(id int) error {
 		if tempMark[id] {
-			return fmt.Errorf("circular dependency detected involving line %d", id)
+			// The cycle is the part of the current path from id's first visit
+			for i, p := range path {
+				if p == id {
+					cycle := append(append([]int{}, path[i:]...), id)
+					return &CycleError{Path: cycle}
+				}
+			}
 		}
 		
 		if visited[id] {
@@ -69,16 +96,20 @@ func (g *Graph) TopologicalSort() ([]int, error) {
 		}
 		
 		tempMark[id] = true
+		path = append(path, id)
 		
 		node, ok := g.nodes[id]
 		if ok {
-			for _, dep := range node.Dependencies {
+			deps := append([]int{}, node.Dependencies...)
+			sort.Ints(deps)
+			for _, dep := range deps {
 				if err := visit(dep); err != nil {
 					return err
 				}
 			}
 		}
 		
+		path = path[:len(path)-1]
 		tempMark[id] = false
 		visited[id] = true
 		result = append(result, id)
@@ -86,7 +117,12 @@ func (g *Graph) TopologicalSort() ([]int, error) {
 		return nil
 	}
 	
+	ids := make([]int, 0, len(g.nodes))
 	for id := range g.nodes {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
 		if !visited[id] {
 			if err := visit(id); err != nil {
 				return nil, err
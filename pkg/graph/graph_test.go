@@ -175,3 +175,48 @@ func TestClear(t *testing.T) {
 		t.Error("Node 2 should not exist after clear")
 	}
 }
+
+func TestTopologicalSortKeepsIDOrder(t *testing.T) {
+	g := NewGraph()
+	g.AddNode(1, "total = a + b", []int{3, 2})
+	g.AddNode(2, "a = 10", nil)
+	g.AddNode(3, "b = a * 2", []int{2})
+	g.AddNode(4, "c = 1", nil)
+
+	order, err := g.TopologicalSort()
+	if err != nil {
+		t.Fatalf("TopologicalSort failed: %v", err)
+	}
+	want := []int{2, 3, 1, 4}
+	for i := range want {
+		if i >= len(order) || order[i] != want[i] {
+			t.Fatalf("order = %v, want %v", order, want)
+		}
+	}
+}
+
+func TestTopologicalSortNamesTheCycle(t *testing.T) {
+	g := NewGraph()
+	g.AddNode(1, "x = 1", nil)
+	g.AddNode(2, "a = c", []int{4})
+	g.AddNode(3, "b = a", []int{2})
+	g.AddNode(4, "c = b", []int{3})
+
+	_, err := g.TopologicalSort()
+	cycle, ok := err.(*CycleError)
+	if !ok {
+		t.Fatalf("expected a *CycleError, got %v", err)
+	}
+	want := []int{2, 4, 3, 2}
+	if len(cycle.Path) != len(want) {
+		t.Fatalf("cycle = %v, want %v", cycle.Path, want)
+	}
+	for i := range want {
+		if cycle.Path[i] != want[i] {
+			t.Fatalf("cycle = %v, want %v", cycle.Path, want)
+		}
+	}
+	if got := err.Error(); got != "circular dependency detected between lines 2 -> 4 -> 3 -> 2" {
+		t.Errorf("error = %q", got)
+	}
+}
@@ -60,7 +60,7 @@ doubled = amount * 2
 print("Doubled: {doubled}")`,
 			args: []string{"--arg", "amount=50 usd"},
 			wantOutput: []string{
-				"Doubled: $100.00",
+				"Doubled: USD 100.00",
 			},
 		},
 		{
@@ -202,6 +202,83 @@ y=20
 	}
 }
 
+func TestArgDirectiveValidation(t *testing.T) {
+	calcBin := buildCalcBinary(t)
+	defer os.Remove(calcBin)
+
+	script := `:arg budget "Monthly budget" currency default £500
+:arg count "How many" number min 1 max 100
+:arg start "Start date" date
+:arg length "Length" unit max 2 km
+print("{budget} {count} {start} {length}")`
+
+	base := []string{"--arg", "count=5", "--arg", "start=01/01/2025", "--arg", "length=300 m"}
+	with := func(extra ...string) []string {
+		return append(append([]string{}, base...), extra...)
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		stdin      string
+		wantOutput string
+		wantStderr string
+	}{
+		{name: "default applied when omitted", args: base, wantOutput: "£500.00 5.00 1 Jan 2025 300.00 m"},
+		{name: "provided currency overrides default", args: with("--arg", "budget=$700"), wantOutput: "$700.00"},
+		{name: "number below min", args: with("--arg", "count=0"), wantStderr: "count must be at least 1.00"},
+		{name: "number above max", args: with("--arg", "count=101"), wantStderr: "count must be at most 100.00"},
+		{name: "number given currency", args: with("--arg", "count=£5"), wantStderr: "count must be a number, got £5.00"},
+		{name: "currency given number", args: with("--arg", "budget=100"), wantStderr: "budget must be a currency, got 100.00"},
+		{name: "date given number", args: with("--arg", "start=5"), wantStderr: "start must be a date, got 5.00"},
+		{name: "unit above max after conversion", args: with("--arg", "length=3000 m"), wantStderr: "length must be at most 2.00 km"},
+		{name: "unit given number", args: with("--arg", "length=3"), wantStderr: "length must be a value with a unit"},
+		{name: "unparseable value", args: with("--arg", "count=)"), wantStderr: "invalid value for argument count"},
+		{
+			name:       "prompted value validated",
+			args:       []string{"--arg", "start=01/01/2025", "--arg", "length=1 km"},
+			stdin:      "500\n",
+			wantStderr: "count must be at most 100.00",
+		},
+		{
+			name:       "prompted value accepted",
+			args:       []string{"--arg", "start=01/01/2025", "--arg", "length=1 km"},
+			stdin:      "42\n",
+			wantOutput: "£500.00 42.00 1 Jan 2025 1.00 km",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile := createTempScript(t, script)
+			defer os.Remove(tmpFile)
+
+			cmd := exec.Command(calcBin, append([]string{"-f", tmpFile}, tt.args...)...)
+			cmd.Stdin = strings.NewReader(tt.stdin)
+			var stdout, stderr bytes.Buffer
+			cmd.Stdout = &stdout
+			cmd.Stderr = &stderr
+
+			err := cmd.Run()
+			if tt.wantStderr != "" {
+				if err == nil {
+					t.Fatalf("expected failure, got output: %s", stdout.String())
+				}
+				if !strings.Contains(stderr.String(), tt.wantStderr) {
+					t.Errorf("stderr missing %q\ngot: %s", tt.wantStderr, stderr.String())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v\nstderr: %s", err, stderr.String())
+			}
+			if !strings.Contains(stdout.String(), tt.wantOutput) {
+				t.Errorf("output missing %q\ngot: %s", tt.wantOutput, stdout.String())
+			}
+		})
+	}
+}
+
 // Helper functions
 
 func buildCalcBinary(t *testing.T) string {
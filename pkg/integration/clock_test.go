@@ -0,0 +1,68 @@
+package integration
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestNowFlag(t *testing.T) {
+	calcBin := buildCalcBinary(t)
+
+	tests := []struct {
+		name      string
+		args      []string
+		want      string
+		wantError string
+	}{
+		{
+			name: "expression relative to today",
+			args: []string{"-c", "today + 3 weeks", "--now", "2024-06-01T00:00:00Z"},
+			want: "22 Jun 2024",
+		},
+		{
+			name: "weekday from a bare date",
+			args: []string{"--now", "2024-06-01", "-c", "next friday"},
+			want: "7 Jun 2024",
+		},
+		{
+			name: "elapsed since a clock time",
+			args: []string{"--now", "2024-06-01T10:30:00Z", "-c", "now - 09:00"},
+			want: "1.50 hours",
+		},
+		{
+			name: "script run",
+			args: []string{"--now", "2024-06-01T10:30:00Z", "-f", createTempScript(t, "deadline = tomorrow + 1 week\nprint(\"Due {deadline}\")")},
+			want: "Due 9 Jun 2024",
+		},
+		{
+			name:      "invalid time",
+			args:      []string{"--now", "yesterday", "-c", "1"},
+			wantError: "invalid --now",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command(calcBin, tt.args...)
+			var stdout, stderr bytes.Buffer
+			cmd.Stdout = &stdout
+			cmd.Stderr = &stderr
+			err := cmd.Run()
+
+			if tt.wantError != "" {
+				if err == nil || !strings.Contains(stderr.String(), tt.wantError) {
+					t.Fatalf("expected failure mentioning %q, got err=%v stderr=%q", tt.wantError, err, stderr.String())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("command failed: %v\nstderr: %s", err, stderr.String())
+			}
+			if got := strings.TrimSpace(stdout.String()); !strings.Contains(got, tt.want) {
+				t.Errorf("expected output containing %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package integration
 
 import (
+	"math"
 	"strings"
 	"testing"
 
@@ -376,3 +377,35 @@ func TestIssueExamples(t *testing.T) {
 		})
 	}
 }
+
+// TestSalaryRates works salary figures through "per annum" and "per week"
+// wording, cancelling an hourly rate against hours worked a week
+func TestSalaryRates(t *testing.T) {
+	tests := []struct {
+		input    string
+		want     float64
+		currency string
+	}{
+		{"£45,000 per annum in gbp/month", 3750, "£"},
+		{"£12.50/hr * 37.5 hours per week in gbp/year", 12.5 * 37.5 * 365.25 / 7, "£"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			expr, err := parser.New(lexer.New(tt.input).AllTokens()).Parse()
+			if err != nil {
+				t.Fatalf("Parse error for %q: %v", tt.input, err)
+			}
+			result := evaluator.New(evaluator.NewEnvironment()).Eval(expr)
+			if result.IsError() {
+				t.Fatalf("Evaluation error for %q: %s", tt.input, result.Error)
+			}
+			if result.Type != evaluator.ValueCurrency || result.Currency != tt.currency {
+				t.Errorf("got %+v, want an amount in %s", result, tt.currency)
+			}
+			if math.Abs(result.Number-tt.want) > 1e-6 {
+				t.Errorf("got %v, want %v", result.Number, tt.want)
+			}
+		})
+	}
+}
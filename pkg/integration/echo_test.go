@@ -0,0 +1,71 @@
+package integration
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+func TestEchoFlag(t *testing.T) {
+	calcBin := buildCalcBinary(t)
+
+	script := `# Budget
+rent = £1200
+food = £300
+
+total = rent + food
+total / 0
+10 km in m, cm
+`
+	want := `# Budget
+rent = £1200        = £1,200.00
+food = £300         = £300.00
+
+total = rent + food = £1,500.00
+                      warning: 'total' is also the function total(); total(...) still calls it
+total / 0           = Error: division by zero
+10 km in m, cm      = 10,000.00 m
+                      1.00e+06 cm
+`
+
+	tests := []struct {
+		name       string
+		script     string
+		args       []string
+		want       string
+		wantStderr string
+	}{
+		// Errors and warnings stay inline; only their summaries go to stderr
+		{name: "flag", script: script, args: []string{"--echo"}, want: want, wantStderr: "1 warning:\n  line 5: 'total' is also the function total(); total(...) still calls it\n1 of 5 lines failed: line 6\n"},
+		{name: "set in script", script: ":set echo on\nx = 2\nx * 3\n", want: "x = 2 = 2.00\nx * 3 = 6.00\n"},
+		{name: "off by default", script: "x = 2\nx * 3\n", want: "2.00\n6.00\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := createTempScript(t, tt.script)
+			defer os.Remove(path)
+
+			cmd := exec.Command(calcBin, append([]string{"-f", path}, tt.args...)...)
+			// Keep :set echo on from reaching the user's real settings
+			cmd.Env = append(os.Environ(), "CALC_CONFIG_DIR="+t.TempDir())
+			var stdout, stderr bytes.Buffer
+			cmd.Stdout = &stdout
+			cmd.Stderr = &stderr
+			err := cmd.Run()
+			if tt.wantStderr == "" && err != nil {
+				t.Fatalf("unexpected error: %v\nstderr: %s", err, stderr.String())
+			}
+			if tt.wantStderr != "" && err == nil {
+				t.Fatalf("expected a non-zero exit for a script with a failing line")
+			}
+			if stdout.String() != tt.want {
+				t.Errorf("output mismatch\ngot:\n%s\nwant:\n%s", stdout.String(), tt.want)
+			}
+			if tt.name != "off by default" && stderr.String() != tt.wantStderr {
+				t.Errorf("echo should keep errors inline, stderr: %s", stderr.String())
+			}
+		})
+	}
+}
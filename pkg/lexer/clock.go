@@ -0,0 +1,58 @@
+package lexer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// meridiemLen returns the length of the am or pm at the start of rest,
+// counting any spaces before it, or 0 if there is none. The suffix must end
+// the word, so the units in "9 amps" and "3 pmol" are left alone.
+func meridiemLen(rest string) int {
+	i := 0
+	for i < len(rest) && rest[i] == ' ' {
+		i++
+	}
+	if i+2 > len(rest) {
+		return 0
+	}
+	if suffix := strings.ToLower(rest[i : i+2]); suffix != "am" && suffix != "pm" {
+		return 0
+	}
+	if r, _ := utf8.DecodeRuneInString(rest[i+2:]); unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+		return 0
+	}
+	return i + 2
+}
+
+// isTwelveHour reports whether digits is an hour on the 12-hour clock, so
+// that 9pm is a time but 13pm is not.
+func isTwelveHour(digits string) bool {
+	hour, err := strconv.Atoi(digits)
+	return err == nil && hour >= 1 && hour <= 12
+}
+
+// twelveHourClock converts a 12-hour time such as "9pm" or "9:30 am",
+// lower-cased, to HH:MM on the 24-hour clock. 12am is midnight and 12pm noon.
+func twelveHourClock(s string) (string, bool) {
+	pm := strings.HasSuffix(s, "pm")
+	if !pm && !strings.HasSuffix(s, "am") {
+		return "", false
+	}
+	hourText, minutes, ok := strings.Cut(strings.TrimSpace(s[:len(s)-2]), ":")
+	if !isTwelveHour(hourText) {
+		return "", false
+	}
+	if !ok {
+		minutes = "00"
+	}
+	hour, _ := strconv.Atoi(hourText)
+	hour %= 12
+	if pm {
+		hour += 12
+	}
+	return fmt.Sprintf("%02d:%s", hour, minutes), true
+}
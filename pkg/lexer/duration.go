@@ -0,0 +1,157 @@
+package lexer
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DurationPart is one amount of a duration literal, as in the 30 min of
+// 1h30m.
+type DurationPart struct {
+	Number string
+	Unit   string
+}
+
+// goDurationUnits are the units of a Go duration such as 1h30m45s, longest
+// first so ms is not read as m, with the units they stand for. m is minutes
+// here rather than metres.
+var goDurationUnits = []struct{ suffix, unit string }{
+	{"ns", "ns"}, {"us", "us"}, {"µs", "µs"}, {"ms", "ms"},
+	{"h", "h"}, {"m", "min"}, {"s", "s"},
+}
+
+// isoDateUnits and isoTimeUnits are the designators of an ISO 8601 duration
+// such as P1DT2H30M, before and after its T. M is months before the T and
+// minutes after it.
+var (
+	isoDateUnits = map[byte]string{'Y': "years", 'M': "months", 'W': "weeks", 'D': "days"}
+	isoTimeUnits = map[byte]string{'H': "h", 'M': "min", 'S': "s"}
+)
+
+// DurationParts returns the amounts of a duration literal, largest first as
+// written.
+func DurationParts(literal string) []DurationPart {
+	parts, _ := scanDuration(literal, true)
+	return parts
+}
+
+// hasDuration reports whether a word of input is a duration of more than
+// one part, such as 1h30m. Beside one, a Go duration of one part such as 45m
+// is read as a duration too, so 1h30m + 45m adds 45 minutes, while 10 m
+// written apart is still metres.
+func hasDuration(input string) bool {
+	for i := 0; i < len(input); i++ {
+		if i > 0 && !endsWord(input[i-1:]) {
+			continue
+		}
+		if _, n := scanDuration(input[i:], false); n > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// scanDuration reads a duration at the start of s, either ISO 8601 such as
+// PT2H30M or Go's such as 1h30m, returning its parts and length. It returns
+// 0 when s does not start with one, including a Go duration of one part
+// unless single is set: 90s reads the same without a dedicated scan, and 45m
+// alone is metres. The duration must end the word, so the 1h30m of 1h30min
+// is not taken as one.
+func scanDuration(s string, single bool) ([]DurationPart, int) {
+	var parts []DurationPart
+	var n int
+	if strings.HasPrefix(s, "P") {
+		parts, n = scanISODuration(s)
+	} else {
+		parts, n = scanGoDuration(s)
+		if len(parts) == 0 || len(parts) < 2 && !single {
+			return nil, 0
+		}
+	}
+	if n == 0 || endsWord(s[n:]) {
+		return parts, n
+	}
+	return nil, 0
+}
+
+// scanGoDuration reads the number and unit pairs of a Go duration, stopping
+// at the first number without a unit.
+func scanGoDuration(s string) ([]DurationPart, int) {
+	var parts []DurationPart
+	n := 0
+	for {
+		digits := durationNumberLen(s[n:])
+		if digits == 0 {
+			break
+		}
+		rest := s[n+digits:]
+		matched := false
+		for _, u := range goDurationUnits {
+			if strings.HasPrefix(rest, u.suffix) {
+				parts = append(parts, DurationPart{Number: s[n : n+digits], Unit: u.unit})
+				n += digits + len(u.suffix)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			break
+		}
+	}
+	return parts, n
+}
+
+// scanISODuration reads an ISO 8601 duration: a P, amounts of years,
+// months, weeks and days, then a T and amounts of hours, minutes and
+// seconds. Either half may be left out, but not both, and a T must have
+// amounts after it.
+func scanISODuration(s string) ([]DurationPart, int) {
+	var parts []DurationPart
+	n := 1
+	units, timed := isoDateUnits, false
+	for n < len(s) {
+		if s[n] == 'T' && !timed {
+			units, timed = isoTimeUnits, true
+			n++
+			continue
+		}
+		digits := durationNumberLen(s[n:])
+		if digits == 0 || n+digits >= len(s) {
+			break
+		}
+		unit, ok := units[s[n+digits]]
+		if !ok {
+			break
+		}
+		parts = append(parts, DurationPart{Number: s[n : n+digits], Unit: unit})
+		n += digits + 1
+	}
+	if len(parts) == 0 || s[n-1] == 'T' {
+		return nil, 0
+	}
+	return parts, n
+}
+
+// durationNumberLen returns the length of the number at the start of s:
+// digits with an optional fraction, as in 1.5h.
+func durationNumberLen(s string) int {
+	n := 0
+	for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+		n++
+	}
+	if n > 0 && n+1 < len(s) && s[n] == '.' && s[n+1] >= '0' && s[n+1] <= '9' {
+		n++
+		for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+			n++
+		}
+	}
+	return n
+}
+
+// endsWord reports whether rest starts where a word ends: at the end of
+// the input or anything other than a letter, digit, underscore or point.
+func endsWord(rest string) bool {
+	r, _ := utf8.DecodeRuneInString(rest)
+	return rest == "" || !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '.'
+}
@@ -0,0 +1,33 @@
+package lexer
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// gluedToNumber reports whether the word starting at column col follows a
+// number with nothing between them, as the km of 10km does.
+func (l *Lexer) gluedToNumber(col int) bool {
+	return l.last.Type == TokenNumber && l.last.Line == l.line &&
+		l.last.Column+utf8.RuneCountInString(l.last.Literal) == col
+}
+
+// feetWords are the units whose number after them, written against them as
+// in 6ft2, is inches rather than part of the unit.
+var feetWords = map[string]bool{"ft": true, "foot": true, "feet": true}
+
+// gluedUnitLen returns the length of the longest known unit that word
+// starts with, where a number follows it, so the ft10 of 5ft10 reads as ft
+// and then 10. It returns 0 when there is none, leaving the word whole.
+func (l *Lexer) gluedUnitLen(word string) int {
+	for i := len(word) - 1; i > 0; i-- {
+		prev, _ := utf8.DecodeLastRuneInString(word[:i])
+		if word[i] < '0' || word[i] > '9' || unicode.IsDigit(prev) {
+			continue
+		}
+		if l.isKnownUnit(word[:i]) {
+			return i
+		}
+	}
+	return 0
+}
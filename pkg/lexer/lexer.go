@@ -1,9 +1,13 @@
 package lexer
 
 import (
+	"slices"
 	"strings"
 	"unicode"
 	"unicode/utf8"
+
+	"github.com/andrewneudegg/calc/pkg/currency"
+	"github.com/andrewneudegg/calc/pkg/units"
 )
 
 // Lexer tokenises input text.
@@ -12,70 +16,172 @@ type Lexer struct {
 	pos             int
 	line            int
 	column          int
-	keywords        map[string]TokenType
 	constantChecker func(string) bool // Optional function to check if a string is a constant
+	unitChecker     func(string) bool // Optional function that decides which words are units, replacing defaultUnits
+	last            Token             // Most recently emitted token, used for contextual scanning
+	durations       *bool             // Whether the input holds a duration such as 1h30m; nil until first needed
+}
+
+// clockKeywords are words lexed as TokenTimeValue, with the clock time they stand for.
+var clockKeywords = map[string]string{
+	"noon":     "12:00",
+	"midday":   "12:00",
+	"midnight": "00:00",
+}
+
+// ClockValue returns the HH:MM or HH:MM:SS form of a TokenTimeValue literal,
+// resolving named times such as "noon" and 12-hour times such as "9:30pm".
+// A 12-hour time with an hour outside 1 to 12 is returned unchanged.
+func ClockValue(literal string) string {
+	lower := strings.ToLower(literal)
+	if clock, ok := clockKeywords[lower]; ok {
+		return clock
+	}
+	if clock, ok := twelveHourClock(lower); ok {
+		return clock
+	}
+	return literal
 }
 
 // New creates a new lexer for the given input.
 func New(input string) *Lexer {
-	l := &Lexer{
+	return &Lexer{
 		input:  input,
 		pos:    0,
 		line:   1,
 		column: 1,
-		keywords: map[string]TokenType{
-			"in":        TokenIn,
-			"of":        TokenOf,
-			"per":       TokenPer,
-			"by":        TokenBy,
-			"what":      TokenWhat,
-			"is":        TokenIs,
-			"increase":  TokenIncrease,
-			"decrease":  TokenDecrease,
-			"sum":       TokenSum,
-			"average":   TokenAverage,
-			"mean":      TokenMean,
-			"total":     TokenTotal,
-			"half":      TokenHalf,
-			"double":    TokenDouble,
-			"twice":     TokenTwice,
-			"quarters":  TokenQuarters,
-			"three":     TokenThree,
-			"arg":       TokenArg,
-			"after":     TokenAfter,
-			"before":    TokenBefore,
-			"from":      TokenFrom,
-			"ago":       TokenAgo,
-			"now":       TokenNow,
-			"today":     TokenToday,
-			"tomorrow":  TokenTomorrow,
-			"yesterday": TokenYesterday,
-			"next":      TokenNext,
-			"last":      TokenLast,
-			"prev":      TokenPrev,
-			"time":      TokenTime,
-			"monday":    TokenMonday,
-			"tuesday":   TokenTuesday,
-			"wednesday": TokenWednesday,
-			"thursday":  TokenThursday,
-			"friday":    TokenFriday,
-			"saturday":  TokenSaturday,
-			"sunday":    TokenSunday,
-			"january":   TokenJanuary,
-			"february":  TokenFebruary,
-			"march":     TokenMarch,
-			"april":     TokenApril,
-			"may":       TokenMay,
-			"june":      TokenJune,
-			"july":      TokenJuly,
-			"august":    TokenAugust,
-			"september": TokenSeptember,
-			"october":   TokenOctober,
-			"november":  TokenNovember,
-			"december":  TokenDecember,
-		},
 	}
-	return l
+}
+
+// keywords are the words lexed as keyword tokens rather than identifiers,
+// by lower-cased spelling.
+var keywords = map[string]TokenType{
+	"in":        TokenIn,
+	"to":        TokenTo,
+	"of":        TokenOf,
+	"per":       TokenPer,
+	"by":        TokenBy,
+	"what":      TokenWhat,
+	"is":        TokenIs,
+	"increase":  TokenIncrease,
+	"decrease":  TokenDecrease,
+	"sum":       TokenSum,
+	"average":   TokenAverage,
+	"mean":      TokenMean,
+	"total":     TokenTotal,
+	"half":      TokenHalf,
+	"double":    TokenDouble,
+	"twice":     TokenTwice,
+	"quarters":  TokenQuarters,
+	"three":     TokenThree,
+	"arg":       TokenArg,
+	"after":     TokenAfter,
+	"before":    TokenBefore,
+	"from":      TokenFrom,
+	"ago":       TokenAgo,
+	"now":       TokenNow,
+	"today":     TokenToday,
+	"tomorrow":  TokenTomorrow,
+	"yesterday": TokenYesterday,
+	"next":      TokenNext,
+	"last":      TokenLast,
+	"prev":      TokenPrev,
+	"about":     TokenAbout,
+	"roughly":   TokenAbout,
+	"time":      TokenTime,
+	"monday":    TokenMonday,
+	"tuesday":   TokenTuesday,
+	"wednesday": TokenWednesday,
+	"thursday":  TokenThursday,
+	"friday":    TokenFriday,
+	"saturday":  TokenSaturday,
+	"sunday":    TokenSunday,
+	"january":   TokenJanuary,
+	"february":  TokenFebruary,
+	"march":     TokenMarch,
+	"april":     TokenApril,
+	"may":       TokenMay,
+	"june":      TokenJune,
+	"july":      TokenJuly,
+	"august":    TokenAugust,
+	"september": TokenSeptember,
+	"october":   TokenOctober,
+	"november":  TokenNovember,
+	"december":  TokenDecember,
+}
+
+// IsKeyword reports whether word lexes as a keyword or a named time of day,
+// such as in, sum, today or noon, ignoring case.
+func IsKeyword(word string) bool {
+	word = strings.ToLower(word)
+	_, keyword := keywords[word]
+	_, clock := clockKeywords[word]
+	return keyword || clock
+}
+
+// exponentLen returns the length of the exponent at the start of rest, such as
+// e6, e-6 or E+3, or 0 when rest does not start with one. Digits must follow,
+// so 2em or 5 e still read as a number and a word.
+func exponentLen(rest string) int {
+	if len(rest) < 2 || rest[0] != 'e' && rest[0] != 'E' {
+		return 0
+	}
+	i := 1
+	if rest[i] == '+' || rest[i] == '-' {
+		i++
+	}
+	digits := i
+	for i < len(rest) && rest[i] >= '0' && rest[i] <= '9' {
+		i++
+	}
+	if i == digits {
+		return 0
+	}
+	return i
+}
+
+// ordinalSuffixLen returns the length of the ordinal suffix at the start of
+// rest when it correctly pairs with digits (1st, 2nd, 3rd, 11th, 21st) and is
+// not the start of a longer word, or 0 otherwise. Mismatched suffixes such as
+// 2st are left alone, so they still read as a number and a unit.
+func ordinalSuffixLen(digits, rest string) int {
+	if len(rest) < 2 {
+		return 0
+	}
+	suffix := strings.ToLower(rest[:2])
+	if len(rest) > 2 {
+		if r, _ := utf8.DecodeRuneInString(rest[2:]); unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return 0
+		}
+	}
+	if OrdinalSuffix(digits) != suffix {
+		return 0
+	}
+	return 2
+}
+
+// OrdinalSuffix returns the English ordinal suffix for a run of digits:
+// "st", "nd", "rd" or "th".
+func OrdinalSuffix(digits string) string {
+	if digits == "" {
+		return ""
+	}
+	tens := digits
+	if len(tens) > 2 {
+		tens = tens[len(tens)-2:]
+	}
+	if len(tens) == 2 && tens[0] == '1' {
+		return "th" // 11th, 12th, 13th
+	}
+	switch tens[len(tens)-1] {
+	case '1':
+		return "st"
+	case '2':
+		return "nd"
+	case '3':
+		return "rd"
+	}
+	return "th"
 }
 
 // SetConstantChecker sets a function to check if an identifier is a physical constant.
@@ -83,8 +189,24 @@ func (l *Lexer) SetConstantChecker(checker func(string) bool) {
 	l.constantChecker = checker
 }
 
+// NewWithUnits creates a lexer that asks isUnit which words are units instead
+// of using the built-in table, so that units defined at runtime lex as
+// TokenUnit as soon as they exist.
+func NewWithUnits(input string, isUnit func(string) bool) *Lexer {
+	l := New(input)
+	l.unitChecker = isUnit
+	return l
+}
+
 // NextToken returns the next token from the input.
 func (l *Lexer) NextToken() Token {
+	tok := l.nextToken()
+	tok.End = l.column
+	l.last = tok
+	return tok
+}
+
+func (l *Lexer) nextToken() Token {
 	l.skipIgnored()
 
 	if l.pos >= len(l.input) {
@@ -119,6 +241,10 @@ func (l *Lexer) NextToken() Token {
 		return l.advance(TokenColon)
 	case '$':
 		return l.scanCurrency()
+	case '#':
+		if l.atTag() {
+			return l.scanTag()
+		}
 	}
 
 	// Check for multi-byte UTF-8 currency symbols
@@ -131,24 +257,136 @@ func (l *Lexer) NextToken() Token {
 			}
 		}
 		if ch == 0xE2 && l.pos+2 < len(l.input) {
-			if l.input[l.pos+1] == 0x82 && l.input[l.pos+2] == 0xAC { // €
+			// €, ₹, ₩, ₺ and the other symbols in U+20A0-U+20BF
+			if currency.IsSymbol(l.input[l.pos : l.pos+3]) {
 				return l.scanCurrency()
 			}
 		}
 	}
 
+	// Per mille and per ten thousand signs, as in 3‰, are percentages on
+	// finer scales
+	if r, size := utf8.DecodeRuneInString(l.input[l.pos:]); r == '‰' || r == '‱' {
+		tok := l.makeToken(TokenPercent, l.input[l.pos:l.pos+size])
+		l.pos += size
+		l.column++
+		return tok
+	}
+
+	// Durations such as 1h30m or PT2H30M, which would otherwise read as
+	// several amounts or a name, and 45m glued together beside one
+	if unicode.IsDigit(rune(ch)) || ch == 'P' {
+		if l.durations == nil {
+			found := hasDuration(l.input)
+			l.durations = &found
+		}
+		if _, n := scanDuration(l.input[l.pos:], *l.durations); n > 0 {
+			tok := l.makeToken(TokenDuration, l.input[l.pos:l.pos+n])
+			l.column += utf8.RuneCountInString(tok.Literal)
+			l.pos += n
+			return tok
+		}
+	}
+
 	// Numbers
 	if unicode.IsDigit(rune(ch)) {
 		return l.scanNumber()
 	}
 
 	// Identifiers and keywords
-	if unicode.IsLetter(rune(ch)) || ch == '_' {
+	r, size := utf8.DecodeRuneInString(l.input[l.pos:])
+	if unicode.IsLetter(r) || r == '_' || r == '°' || units.IsSymbolVariant(r) && !isProductDot(r) {
 		return l.scanIdentifier()
 	}
 
-	// Unknown character
-	return l.advance(TokenError)
+	// Unknown character, consumed whole so that multi-byte runes such as ²
+	// cannot stall the lexer part way through
+	tok := l.makeToken(TokenError, l.input[l.pos:l.pos+size])
+	l.pos += size
+	l.column++
+	return tok
+}
+
+// atTag reports whether the '#' at the current position starts a tag: it
+// must follow whitespace and be followed by a letter, so "prev#3" and a
+// lone '#' are left alone.
+func (l *Lexer) atTag() bool {
+	if l.pos == 0 || !unicode.IsSpace(rune(l.input[l.pos-1])) {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(l.input[l.pos+1:])
+	return unicode.IsLetter(r)
+}
+
+// scanTag scans a tag such as #food, made of letters, digits and
+// underscores after the '#'. The literal keeps the '#'.
+func (l *Lexer) scanTag() Token {
+	start := l.pos
+	startCol := l.column
+	l.pos++
+	l.column++
+	for l.pos < len(l.input) {
+		r, size := utf8.DecodeRuneInString(l.input[l.pos:])
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			break
+		}
+		l.pos += size
+		l.column++
+	}
+	return Token{Type: TokenTag, Literal: l.input[start:l.pos], Line: l.line, Column: startCol}
+}
+
+// TagName returns the name a tag token files its line under: the literal
+// without the '#', in lower case, so #Food and #food are the same tag.
+func TagName(literal string) string {
+	return strings.ToLower(strings.TrimPrefix(literal, "#"))
+}
+
+// SplitTags removes the tags a line is filed under, as in "coffee = £3.20
+// #food", returning the rest of the line and the tags' names. A tag read by
+// sum, total, average or mean, as in "sum #food", is part of the
+// expression and stays.
+func SplitTags(tokens []Token) ([]Token, []string) {
+	var rest []Token
+	var tags []string
+	for i, tok := range tokens {
+		if tok.Type != TokenTag || isAggregated(tokens, i) {
+			rest = append(rest, tok)
+			continue
+		}
+		if name := TagName(tok.Literal); !slices.Contains(tags, name) {
+			tags = append(tags, name)
+		}
+	}
+	return rest, tags
+}
+
+// isAggregated reports whether the tag at tokens[i] follows an aggregate
+// word, optionally with "of" between them.
+func isAggregated(tokens []Token, i int) bool {
+	j := i - 1
+	if j >= 0 && tokens[j].Type == TokenOf {
+		j--
+	}
+	if j < 0 {
+		return false
+	}
+	switch tokens[j].Type {
+	case TokenSum, TokenTotal, TokenAverage, TokenMean:
+		return true
+	}
+	return false
+}
+
+// isUnitMark reports whether r can appear in unit names alongside letters,
+// as in m², cm³, ° and ㎞.
+func isUnitMark(r rune) bool {
+	return r == '°' || r == '²' || r == '³' || units.IsSymbolVariant(r) && !isProductDot(r)
+}
+
+// isProductDot reports whether r joins the units of a product, as in N·m.
+func isProductDot(r rune) bool {
+	return r == '·' || r == '⋅' || r == '∙'
 }
 
 // scanString scans a double-quoted string literal, supporting simple escapes (\" and \\ and \n).
@@ -264,12 +502,50 @@ func (l *Lexer) scanNumber() Token {
 	start := l.pos
 	startCol := l.column
 
+	// After the "ratio" keyword, colon-separated numbers are ratio parts (2:3:4 or 1.5:2.5), not times
+	if l.last.Type == TokenIdent && strings.EqualFold(l.last.Literal, "ratio") {
+		if end := l.ratioEnd(); end > l.pos {
+			l.column += end - l.pos
+			l.pos = end
+			return Token{
+				Type:    TokenRatio,
+				Literal: l.input[start:l.pos],
+				Line:    l.line,
+				Column:  startCol,
+			}
+		}
+	}
+
 	// Scan number including any thousand separators (commas/periods) and decimal points
 	for l.pos < len(l.input) && unicode.IsDigit(rune(l.input[l.pos])) {
 		l.pos++
 		l.column++
 	}
 
+	// An hour with am or pm, as in 9pm or 9 pm, is a time of day
+	if n := meridiemLen(l.input[l.pos:]); n > 0 && isTwelveHour(l.input[start:l.pos]) {
+		l.pos += n
+		l.column += n
+		return Token{
+			Type:    TokenTimeValue,
+			Literal: l.input[start:l.pos],
+			Line:    l.line,
+			Column:  startCol,
+		}
+	}
+
+	// An ordinal such as 1st or 21st must not read as a number of stones
+	if n := ordinalSuffixLen(l.input[start:l.pos], l.input[l.pos:]); n > 0 {
+		l.pos += n
+		l.column += n
+		return Token{
+			Type:    TokenOrdinal,
+			Literal: l.input[start:l.pos],
+			Line:    l.line,
+			Column:  startCol,
+		}
+	}
+
 	// Check for date format (DD/MM/YYYY or MM/DD/YYYY)
 	if l.pos < len(l.input) && l.input[l.pos] == '/' {
 		// Look ahead to see if this could be a date
@@ -344,6 +620,10 @@ func (l *Lexer) scanNumber() Token {
 
 			// Make sure we have at least 2 digits for minutes
 			if l.pos-minuteStart >= 2 || l.pos-minuteStart == 1 {
+				// An optional am or pm, as in 9:30pm or 9:30 pm
+				n := meridiemLen(l.input[l.pos:])
+				l.pos += n
+				l.column += n
 				literal := l.input[start:l.pos]
 				return Token{
 					Type:    TokenTimeValue,
@@ -382,6 +662,12 @@ func (l *Lexer) scanNumber() Token {
 		}
 	}
 
+	// Scientific notation, as in 3e-6 or 1.5E+3
+	if n := exponentLen(l.input[l.pos:]); n > 0 {
+		l.pos += n
+		l.column += n
+	}
+
 	literal := l.input[start:l.pos]
 
 	return Token{
@@ -395,6 +681,7 @@ func (l *Lexer) scanNumber() Token {
 func (l *Lexer) scanIdentifier() Token {
 	start := l.pos
 	startCol := l.column
+	dot, dotCol := -1, 0 // Where a middle dot or hyphen joins two unit names, if one does
 
 	for l.pos < len(l.input) {
 		// Decode UTF-8 rune from string
@@ -402,7 +689,15 @@ func (l *Lexer) scanIdentifier() Token {
 		if size == 0 {
 			break
 		}
-		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+		if (isProductDot(r) || r == '-') && dot < 0 && l.pos > start {
+			// Kept only if the whole turns out to be a unit, as N·m and
+			// foot-pound are
+			next, _ := utf8.DecodeRuneInString(l.input[l.pos+size:])
+			if !unicode.IsLetter(next) {
+				break
+			}
+			dot, dotCol = l.pos, l.column
+		} else if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && !isUnitMark(r) {
 			break
 		}
 		l.pos += size    // Advance by byte size
@@ -410,6 +705,28 @@ func (l *Lexer) scanIdentifier() Token {
 	}
 
 	literal := l.input[start:l.pos]
+	if literal == "°" {
+		// A degree sign spaced from its scale, as in "20 ° F"
+		literal = l.degreeScale(start)
+	}
+	if dot >= 0 && !l.isKnownUnit(literal) {
+		l.pos, l.column = dot, dotCol
+		literal = l.input[start:l.pos]
+	}
+	// A unit written against its number, as in 5ft10, ends where the next
+	// number starts. Feet always do, so 6ft2 is a height like 5ft10 rather
+	// than square feet, while 10m2 is still square metres
+	if l.gluedToNumber(startCol) {
+		if n := l.gluedUnitLen(literal); n > 0 && (!l.isKnownUnit(literal) || feetWords[strings.ToLower(literal[:n])]) {
+			l.pos, l.column = start+n, startCol+utf8.RuneCountInString(literal[:n])
+			literal = literal[:n]
+		}
+	}
+	// Units pasted with other characters, such as ㎞, μm or °C, read as the
+	// unit the tables name
+	if normal := units.Normalise(literal); normal != literal && l.isKnownUnit(normal) {
+		return Token{Type: TokenUnit, Literal: normal, Line: l.line, Column: startCol}
+	}
 	lowerLiteral := strings.ToLower(literal)
 
 	// Special handling for 'prev' - check if followed by '~' or '#' and optional number
@@ -434,8 +751,18 @@ func (l *Lexer) scanIdentifier() Token {
 		}
 	}
 
+	// Named times of day take the same path as HH:MM literals
+	if _, ok := clockKeywords[lowerLiteral]; ok {
+		return Token{
+			Type:    TokenTimeValue,
+			Literal: literal,
+			Line:    l.line,
+			Column:  startCol,
+		}
+	}
+
 	// Check if it's a keyword
-	if typ, ok := l.keywords[lowerLiteral]; ok {
+	if typ, ok := keywords[lowerLiteral]; ok {
 		return Token{
 			Type:    typ,
 			Literal: literal,
@@ -444,8 +771,22 @@ func (l *Lexer) scanIdentifier() Token {
 		}
 	}
 
-	// Check if it's a constant (if checker is available)
-	if l.constantChecker != nil && l.constantChecker(literal) {
+	// Basis points and per mille, as in 25 bp or 50 bp in %, are written
+	// like the % sign. bps is left to the parser, as it is also a data rate
+	afterQuantity := l.last.Type == TokenNumber || l.last.Type == TokenIn || l.last.Type == TokenTo
+	if afterQuantity && units.IsPercentWord(literal) && !l.isKnownUnit(literal) {
+		return Token{
+			Type:    TokenPercent,
+			Literal: literal,
+			Line:    l.line,
+			Column:  startCol,
+		}
+	}
+
+	// Check if it's a constant (if checker is available). A word that is also
+	// a unit, such as ly, reads as the unit after a number or "in"/"to", so
+	// "1 ly in mm" and "1e16 m in ly" convert
+	if l.constantChecker != nil && l.constantChecker(literal) && !(afterQuantity && l.isKnownUnit(literal)) {
 		return Token{
 			Type:    TokenConstant,
 			Literal: literal,
@@ -472,6 +813,25 @@ func (l *Lexer) scanIdentifier() Token {
 	}
 }
 
+// degreeScale reads the C, F or K after a lone degree sign and the spaces
+// between them, returning "°C", "°F" or "°K"; otherwise it reads nothing
+// and returns "°".
+func (l *Lexer) degreeScale(start int) string {
+	i := l.pos
+	for i < len(l.input) && l.input[i] == ' ' {
+		i++
+	}
+	if i == l.pos || i >= len(l.input) || !strings.ContainsRune("CFKcfk", rune(l.input[i])) {
+		return "°"
+	}
+	if next, _ := utf8.DecodeRuneInString(l.input[i+1:]); unicode.IsLetter(next) || unicode.IsDigit(next) {
+		return "°"
+	}
+	l.column += i + 1 - l.pos
+	l.pos = i + 1
+	return "°" + l.input[i:i+1]
+}
+
 func (l *Lexer) scanCurrency() Token {
 	start := l.pos
 	startCol := l.column
@@ -483,7 +843,7 @@ func (l *Lexer) scanCurrency() Token {
 	} else {
 		// Handle UTF-8 multi-byte currency symbols
 		// £ = C2 A3 (2 bytes)
-		// € = E2 82 AC (3 bytes)
+		// €, ₹, ₩, ₺ = E2 82 xx (3 bytes)
 		// ¥ = C2 A5 (2 bytes)
 		firstByte := l.input[l.pos]
 		if firstByte == 0xC2 {
@@ -508,159 +868,14 @@ func (l *Lexer) isCurrencySymbol(ch byte) bool {
 	return ch == '$'
 }
 
+// isKnownUnit reports whether s is read as a unit: by the lexer's unit
+// checker when it has one, otherwise by the built-in table or as an SI
+// prefix on a unit that takes one.
 func (l *Lexer) isKnownUnit(s string) bool {
-	knownUnits := map[string]bool{
-		// Length
-		"m": true, "cm": true, "mm": true, "km": true,
-		"ft": true, "in": true, "yd": true, "mi": true,
-		"mile": true, "miles": true, "metre": true, "metres": true,
-		"meter": true, "meters": true, "foot": true, "feet": true,
-		"inch": true, "inches": true, "yard": true, "yards": true,
-
-		// Mass
-		"g": true, "kg": true, "mg": true, "µg": true, "ug": true,
-		"lb": true, "lbs": true, "oz": true,
-		"gram": true, "grams": true, "milligram": true, "milligrams": true,
-		"microgram": true, "micrograms": true,
-		"kilogram": true, "kilograms": true,
-		"pound": true, "pounds": true, "ounce": true, "ounces": true,
-		"stone": true, "stones": true, "st": true,
-		"carat": true, "carats": true, "ct": true,
-		"troyounce": true, "troyounces": true, "troyoz": true, "ozt": true,
-		"tonne": true, "tonnes": true, "ton": true, "tons": true,
-
-		// Time
-		"ns": true, "nanosecond": true, "nanoseconds": true,
-		"µs": true, "us": true, "microsecond": true, "microseconds": true,
-		"ms": true, "millisecond": true, "milliseconds": true,
-		"s": true, "sec": true, "second": true, "seconds": true,
-		"min": true, "minute": true, "minutes": true,
-		"h": true, "hr": true, "hour": true, "hours": true,
-		"day": true, "days": true, "week": true, "weeks": true,
-		"fortnight": true, "fortnights": true,
-		"month": true, "months": true,
-		"quarter": true, "quarters": true,
-		"semester": true, "semesters": true,
-		"year": true, "years": true, "y": true,
-
-		// Volume
-		"l": true, "ml": true, "litre": true, "litres": true, "liter": true, "liters": true,
-		"millilitre": true, "millilitres": true, "milliliter": true, "milliliters": true,
-		"cl": true, "centilitre": true, "centilitres": true, "centiliter": true, "centiliters": true,
-		"dl": true, "decilitre": true, "decilitres": true, "deciliter": true, "deciliters": true,
-		"m3": true, "m³": true, "cm3": true, "cm³": true, "mm3": true, "mm³": true,
-		"ft3": true, "ft³": true, "in3": true, "in³": true, "cc": true,
-		"gal": true, "gallon": true, "gallons": true,
-		"usgal": true, "usgallon": true, "usgallons": true,
-		"ukgal": true, "ukgallon": true, "ukgallons": true,
-		"impgal": true, "imperialgallon": true,
-		"quart": true, "quarts": true, "qt": true,
-		"usquart": true, "usquarts": true, "ukquart": true, "ukquarts": true,
-		"pint": true, "pints": true, "pt": true,
-		"uspint": true, "uspints": true, "ukpint": true, "ukpints": true,
-		"imppint": true, "imperialpint": true,
-		"cup": true, "cups": true,
-		"floz": true, "fluidounce": true, "fluidounces": true,
-		"tbsp": true, "tablespoon": true, "tablespoons": true,
-		"tsp": true, "teaspoon": true, "teaspoons": true,
-
-		// Area
-		"sqm": true, "m2": true, "m²": true,
-		"sqmm": true, "mm2": true, "mm²": true,
-		"sqcm": true, "cm2": true, "cm²": true,
-		"sqkm": true, "km2": true, "km²": true,
-		"sqft": true, "ft2": true, "ft²": true,
-		"sqin": true, "in2": true, "in²": true,
-		"sqyd": true, "yd2": true, "yd²": true,
-		"sqmi": true, "mi2": true, "mi²": true,
-		"squaremetre": true, "squaremetres": true, "squaremeter": true, "squaremeters": true,
-		"squarefoot": true, "squarefeet": true,
-		"squareinch": true, "squareinches": true,
-		"squareyard": true, "squareyards": true,
-		"squaremile": true, "squaremiles": true,
-		"squarekilometre": true, "squarekilometres": true, "squarekilometer": true, "squarekilometers": true,
-		"acre": true, "acres": true,
-		"hectare": true, "hectares": true, "ha": true,
-		"are": true, "ares": true, "decare": true, "decares": true,
-
-		// Temperature
-		"c": true, "f": true, "celsius": true, "fahrenheit": true,
-		"k": true, "kelvin": true,
-		"r": true, "rankine": true, "°r": true,
-
-		// Speed
-		"mps": true, "kph": true, "kmh": true, "mph": true,
-		"fps": true, "knot": true, "knots": true, "kn": true,
-
-		// Pressure
-		"pa": true, "pascal": true, "pascals": true,
-		"kpa": true, "kilopascal": true, "kilopascals": true,
-		"mpa": true, "megapascal": true, "megapascals": true,
-		"bar": true, "bars": true, "mbar": true, "millibar": true, "millibars": true,
-		"atm": true, "atmosphere": true, "atmospheres": true,
-		"psi": true, "torr": true, "mmhg": true, "inhg": true,
-
-		// Force
-		"n": true, "newton": true, "newtons": true,
-		"kilonewton": true, "kilonewtons": true,
-		"mn": true, "meganewton": true, "meganewtons": true,
-		"lbf": true, "poundforce": true, "poundsforce": true,
-		"kgf": true, "kilogramforce": true,
-		"dyne": true, "dynes": true,
-
-		// Angle
-		"deg": true, "degree": true, "degrees": true, "°": true,
-		"rad": true, "radian": true, "radians": true,
-		"grad": true, "gradian": true, "gradians": true, "gon": true,
-		"turn": true, "turns": true, "revolution": true, "revolutions": true,
-
-		// Frequency
-		"hz": true, "hertz": true,
-		"khz": true, "kilohertz": true,
-		"mhz": true, "megahertz": true,
-		"ghz": true, "gigahertz": true,
-		"thz": true, "terahertz": true,
-		"rpm": true,
-
-		// Digital storage (bytes)
-		"b": true, "byte": true, "bytes": true,
-		"kb": true, "kilobyte": true, "kilobytes": true,
-		"mb": true, "megabyte": true, "megabytes": true,
-		"gb": true, "gigabyte": true, "gigabytes": true,
-		"tb": true, "terabyte": true, "terabytes": true,
-		"pb": true, "petabyte": true, "petabytes": true,
-
-		// Digital storage (bits)
-		"bit": true, "bits": true,
-		"kbit": true, "kilobit": true, "kilobits": true,
-		"mbit": true, "megabit": true, "megabits": true,
-		"gbit": true, "gigabit": true, "gigabits": true,
-		"tbit": true, "terabit": true, "terabits": true,
-		"pbit": true, "petabit": true, "petabits": true,
-
-		// Data rate (bytes per second)
-		"bps": true, "kbps": true, "mbps": true, "gbps": true, "tbps": true,
-		"Bps": true, "KBps": true, "MBps": true, "GBps": true, "TBps": true,
-
-		// Data rate (bits per second)
-		"bitps": true, "kbitps": true, "mbitps": true, "gbitps": true, "tbitps": true,
-
-		// Currency codes and names
-		"usd": true, "dollar": true, "dollars": true,
-		"gbp": true, // "pound" and "pounds" already exist as mass units
-		"eur": true, "euro": true, "euros": true,
-		"jpy": true, "yen": true,
-		// Expanded currency codes
-		"aud": true, "cad": true, "nzd": true,
-		"chf": true, "sek": true, "nok": true, "dkk": true,
-		"pln": true, "czk": true, "huf": true, "ron": true,
-		"rub": true, "try": true,
-		"aed": true, "sar": true, "ils": true,
-		"cny": true, "hkd": true, "sgd": true, "inr": true, "krw": true, "twd": true, "thb": true, "myr": true, "idr": true, "php": true,
-		"mxn": true, "brl": true, "zar": true,
-	}
-
-	return knownUnits[strings.ToLower(s)]
+	if l.unitChecker != nil {
+		return l.unitChecker(s)
+	}
+	return defaultUnits[strings.ToLower(s)] || units.HasSIPrefix(s) || units.IsCaseSymbol(s)
 }
 
 // AllTokens returns all tokens from the input as a slice.
@@ -675,3 +890,177 @@ func (l *Lexer) AllTokens() []Token {
 	}
 	return tokens
 }
+
+// defaultUnits are the unit and currency names recognised by a lexer made with
+// New. It keeps the lexer usable on its own; the REPL and file mode use
+// NewWithUnits so that the unit system decides instead.
+var defaultUnits = map[string]bool{
+	// Length
+	"m": true, "cm": true, "mm": true, "km": true,
+	"ft": true, "in": true, "yd": true, "mi": true,
+	"mile": true, "miles": true, "metre": true, "metres": true,
+	"meter": true, "meters": true, "foot": true, "feet": true,
+	"inch": true, "inches": true, "yard": true, "yards": true,
+	"ly": true, "lightyear": true, "lightyears": true,
+
+	// Mass
+	"g": true, "kg": true, "mg": true, "µg": true, "ug": true,
+	"lb": true, "lbs": true, "oz": true,
+	"gram": true, "grams": true, "milligram": true, "milligrams": true,
+	"microgram": true, "micrograms": true,
+	"kilogram": true, "kilograms": true,
+	"pound": true, "pounds": true, "ounce": true, "ounces": true,
+	"stone": true, "stones": true, "st": true,
+	"carat": true, "carats": true, "ct": true,
+	"troyounce": true, "troyounces": true, "troyoz": true, "ozt": true,
+	"tonne": true, "tonnes": true, "ton": true, "tons": true,
+
+	// Time
+	"ns": true, "nanosecond": true, "nanoseconds": true,
+	"µs": true, "us": true, "microsecond": true, "microseconds": true,
+	"ms": true, "millisecond": true, "milliseconds": true,
+	"s": true, "sec": true, "second": true, "seconds": true,
+	"min": true, "minute": true, "minutes": true,
+	"h": true, "hr": true, "hour": true, "hours": true,
+	"day": true, "days": true, "week": true, "weeks": true,
+	"fortnight": true, "fortnights": true,
+	"month": true, "months": true,
+	"quarter": true, "quarters": true,
+	"semester": true, "semesters": true,
+	"year": true, "years": true, "y": true,
+
+	// Volume
+	"l": true, "ml": true, "litre": true, "litres": true, "liter": true, "liters": true,
+	"millilitre": true, "millilitres": true, "milliliter": true, "milliliters": true,
+	"cl": true, "centilitre": true, "centilitres": true, "centiliter": true, "centiliters": true,
+	"dl": true, "decilitre": true, "decilitres": true, "deciliter": true, "deciliters": true,
+	"m3": true, "m³": true, "cm3": true, "cm³": true, "mm3": true, "mm³": true,
+	"ft3": true, "ft³": true, "in3": true, "in³": true, "cc": true,
+	"gal": true, "gallon": true, "gallons": true,
+	"usgal": true, "usgallon": true, "usgallons": true,
+	"ukgal": true, "ukgallon": true, "ukgallons": true,
+	"impgal": true, "imperialgallon": true,
+	"quart": true, "quarts": true, "qt": true,
+	"usquart": true, "usquarts": true, "ukquart": true, "ukquarts": true,
+	"pint": true, "pints": true, "pt": true,
+	"uspint": true, "uspints": true, "ukpint": true, "ukpints": true,
+	"imppint": true, "imperialpint": true,
+	"cup": true, "cups": true,
+	"floz": true, "fluidounce": true, "fluidounces": true,
+	"tbsp": true, "tablespoon": true, "tablespoons": true,
+	"tsp": true, "teaspoon": true, "teaspoons": true,
+	"pinch": true, "pinches": true, "dash": true, "dashes": true, "splash": true, "splashes": true,
+
+	// Area
+	"sqm": true, "m2": true, "m²": true,
+	"sqmm": true, "mm2": true, "mm²": true,
+	"sqcm": true, "cm2": true, "cm²": true,
+	"sqkm": true, "km2": true, "km²": true,
+	"sqft": true, "ft2": true, "ft²": true,
+	"sqin": true, "in2": true, "in²": true,
+	"sqyd": true, "yd2": true, "yd²": true,
+	"sqmi": true, "mi2": true, "mi²": true,
+	"squaremetre": true, "squaremetres": true, "squaremeter": true, "squaremeters": true,
+	"squarefoot": true, "squarefeet": true,
+	"squareinch": true, "squareinches": true,
+	"squareyard": true, "squareyards": true,
+	"squaremile": true, "squaremiles": true,
+	"squarekilometre": true, "squarekilometres": true, "squarekilometer": true, "squarekilometers": true,
+	"acre": true, "acres": true,
+	"hectare": true, "hectares": true, "ha": true,
+	"are": true, "ares": true, "decare": true, "decares": true,
+
+	// Temperature
+	"c": true, "f": true, "celsius": true, "fahrenheit": true,
+	"k": true, "kelvin": true,
+	"r": true, "rankine": true, "°r": true,
+
+	// Speed
+	"mps": true, "kph": true, "kmh": true, "mph": true,
+	"fps": true, "knot": true, "knots": true, "kn": true,
+	"mach": true, "beaufort": true, "bft": true,
+
+	// Pressure
+	"pa": true, "pascal": true, "pascals": true,
+	"kpa": true, "kilopascal": true, "kilopascals": true,
+	"mpa": true, "megapascal": true, "megapascals": true,
+	"bar": true, "bars": true, "mbar": true, "millibar": true, "millibars": true,
+	"atm": true, "atmosphere": true, "atmospheres": true,
+	"psi": true, "torr": true, "mmhg": true, "inhg": true,
+
+	// Force
+	"n": true, "newton": true, "newtons": true,
+	"kilonewton": true, "kilonewtons": true,
+	"mn": true, "meganewton": true, "meganewtons": true,
+	"lbf": true, "poundforce": true, "poundsforce": true,
+	"kgf": true, "kilogramforce": true,
+	"dyne": true, "dynes": true,
+
+	// Angle
+	"deg": true, "degree": true, "degrees": true, "°": true,
+	"rad": true, "radian": true, "radians": true,
+	"grad": true, "gradian": true, "gradians": true, "gon": true,
+	"turn": true, "turns": true, "revolution": true, "revolutions": true,
+
+	// Frequency
+	"hz": true, "hertz": true,
+	"khz": true, "kilohertz": true,
+	"mhz": true, "megahertz": true,
+	"ghz": true, "gigahertz": true,
+	"thz": true, "terahertz": true,
+	"rpm": true,
+
+	// Energy
+	"j": true, "joule": true, "joules": true,
+	"kj": true, "kilojoule": true, "kilojoules": true,
+	"mj": true, "megajoule": true, "megajoules": true,
+	"wh": true, "kwh": true, "kilowatthour": true, "kilowatthours": true,
+
+	// Torque, with Nm told apart from nm by its case in isKnownUnit
+	"newtonmetre": true, "newtonmetres": true, "newtonmeter": true, "newtonmeters": true,
+	"newton-metre": true, "newton-metres": true, "newton-meter": true, "newton-meters": true,
+	"knm": true, "lbft": true, "lbfft": true, "ftlb": true, "ftlbf": true,
+	"lb-ft": true, "ft-lb": true, "lbf-ft": true, "ft-lbf": true,
+	"poundfoot": true, "poundfeet": true, "pound-foot": true, "pound-feet": true,
+	"footpound": true, "footpounds": true, "foot-pound": true, "foot-pounds": true,
+	"lbin": true, "lbfin": true, "inlb": true, "inlbf": true,
+
+	// Power
+	"w": true, "watt": true, "watts": true,
+	"kw": true, "kilowatt": true, "kilowatts": true,
+	"mw": true, "megawatt": true, "megawatts": true,
+
+	// Counts
+	"person": true, "people": true, "item": true, "items": true, "units": true,
+
+	// Digital storage (bytes)
+	"b": true, "byte": true, "bytes": true,
+	"kb": true, "kilobyte": true, "kilobytes": true,
+	"mb": true, "megabyte": true, "megabytes": true,
+	"gb": true, "gigabyte": true, "gigabytes": true,
+	"tb": true, "terabyte": true, "terabytes": true,
+	"pb": true, "petabyte": true, "petabytes": true,
+
+	// Digital storage (bits)
+	"bit": true, "bits": true,
+	"kbit": true, "kilobit": true, "kilobits": true,
+	"mbit": true, "megabit": true, "megabits": true,
+	"gbit": true, "gigabit": true, "gigabits": true,
+	"tbit": true, "terabit": true, "terabits": true,
+	"pbit": true, "petabit": true, "petabits": true,
+
+	// Data rate (bytes per second)
+	"bps": true, "kbps": true, "mbps": true, "gbps": true, "tbps": true,
+	"Bps": true, "KBps": true, "MBps": true, "GBps": true, "TBps": true,
+
+	// Data rate (bits per second)
+	"bitps": true, "kbitps": true, "mbitps": true, "gbitps": true, "tbitps": true,
+
+	// Currency codes and names are added from the currency package in init
+}
+
+func init() {
+	for _, name := range currency.Names() {
+		defaultUnits[name] = true
+	}
+}
@@ -0,0 +1,11 @@
+package lexer
+
+import "testing"
+
+// BenchmarkLexLine tokenises a typical line, which includes making the lexer.
+func BenchmarkLexLine(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		New("total = (rent + £45.50 * 3) in dollars + 20% of 5 km in miles").AllTokens()
+	}
+}
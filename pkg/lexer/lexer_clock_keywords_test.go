@@ -0,0 +1,58 @@
+package lexer
+
+import "testing"
+
+func TestNamedTimesLexAsTimeValues(t *testing.T) {
+	tests := []struct {
+		input string
+		clock string
+	}{
+		{"noon", "12:00"},
+		{"midday", "12:00"},
+		{"Midnight", "00:00"},
+		{"14:30", "14:30"},
+		{"9pm", "21:00"},
+		{"9 pm", "21:00"},
+		{"9:30am", "09:30"},
+		{"9:30 PM", "21:30"},
+		{"12am", "00:00"},
+		{"12:15pm", "12:15"},
+		{"11:59:30 pm", "23:59:30"},
+	}
+
+	for _, tt := range tests {
+		tok := New(tt.input).NextToken()
+		if tok.Type != TokenTimeValue {
+			t.Errorf("%q: expected TIMEVALUE, got %s", tt.input, tok.Type)
+		}
+		if tok.Literal != tt.input {
+			t.Errorf("%q: literal should keep the source text, got %q", tt.input, tok.Literal)
+		}
+		if got := ClockValue(tok.Literal); got != tt.clock {
+			t.Errorf("ClockValue(%q) = %q, want %q", tok.Literal, got, tt.clock)
+		}
+	}
+}
+
+func TestMeridiemNeedsAWholeWord(t *testing.T) {
+	tests := []struct {
+		input string
+		typ   TokenType
+	}{
+		{"9 amps", TokenNumber},
+		{"3 pmol", TokenNumber},
+		{"13pm", TokenNumber},
+		{"0am", TokenNumber},
+		{"9.5pm", TokenNumber},
+		{"13:00pm", TokenTimeValue},
+	}
+	for _, tt := range tests {
+		if tok := New(tt.input).NextToken(); tok.Type != tt.typ {
+			t.Errorf("%q: expected %s, got %s %q", tt.input, tt.typ, tok.Type, tok.Literal)
+		}
+	}
+	// An hour outside 1 to 12 is left for the parser to reject
+	if got := ClockValue("13:00pm"); got != "13:00pm" {
+		t.Errorf("ClockValue(13:00pm) = %q, want it unchanged", got)
+	}
+}
@@ -0,0 +1,68 @@
+package lexer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDurations(t *testing.T) {
+	tests := []struct {
+		input string
+		types []TokenType
+	}{
+		{"1h30m", []TokenType{TokenDuration}},
+		{"1h30m45s", []TokenType{TokenDuration}},
+		{"1.5h30m", []TokenType{TokenDuration}},
+		{"2s500ms", []TokenType{TokenDuration}},
+		{"1h30m + 45m", []TokenType{TokenDuration, TokenPlus, TokenDuration}},
+		{"1h30m + 10 m", []TokenType{TokenDuration, TokenPlus, TokenNumber, TokenUnit}},
+		{"PT2H30M", []TokenType{TokenDuration}},
+		{"P1DT12H", []TokenType{TokenDuration}},
+		{"P1Y2M", []TokenType{TokenDuration}},
+		{"PT1.5H in minutes", []TokenType{TokenDuration, TokenIn, TokenUnit}},
+		// One part alone reads as before, so 45m is still metres
+		{"90s", []TokenType{TokenNumber, TokenUnit}},
+		{"45m", []TokenType{TokenNumber, TokenUnit}},
+		// Not durations
+		{"P", []TokenType{TokenIdent}},
+		{"P1DT", []TokenType{TokenIdent}},
+		{"Price", []TokenType{TokenIdent}},
+		{"pt2h", []TokenType{TokenIdent}},
+	}
+
+	for _, tt := range tests {
+		tokens := New(tt.input).AllTokens()
+		tokens = tokens[:len(tokens)-1] // drop EOF
+		if len(tokens) != len(tt.types) {
+			t.Errorf("%q: got %v, want %v", tt.input, tokens, tt.types)
+			continue
+		}
+		for i, typ := range tt.types {
+			if tokens[i].Type != typ {
+				t.Errorf("%q: token %d is %s %q, want %s", tt.input, i, tokens[i].Type, tokens[i].Literal, typ)
+			}
+		}
+	}
+}
+
+func TestDurationParts(t *testing.T) {
+	tests := []struct {
+		literal string
+		want    []DurationPart
+	}{
+		{"1h30m45s", []DurationPart{{"1", "h"}, {"30", "min"}, {"45", "s"}}},
+		{"1m500ms", []DurationPart{{"1", "min"}, {"500", "ms"}}},
+		{"PT2H30M", []DurationPart{{"2", "h"}, {"30", "min"}}},
+		{"P1Y2M3W4DT5H6M7S", []DurationPart{
+			{"1", "years"}, {"2", "months"}, {"3", "weeks"}, {"4", "days"},
+			{"5", "h"}, {"6", "min"}, {"7", "s"},
+		}},
+		{"P1D", []DurationPart{{"1", "days"}}},
+		{"45m", []DurationPart{{"45", "min"}}},
+	}
+	for _, tt := range tests {
+		if got := DurationParts(tt.literal); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("DurationParts(%q) = %v, want %v", tt.literal, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package lexer
+
+import "testing"
+
+func TestLexerScientificNotation(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []Token
+	}{
+		{"3e-6 m", []Token{{Type: TokenNumber, Literal: "3e-6"}, {Type: TokenUnit, Literal: "m"}}},
+		{"1.5E+3", []Token{{Type: TokenNumber, Literal: "1.5E+3"}}},
+		{"6.022e23", []Token{{Type: TokenNumber, Literal: "6.022e23"}}},
+		{"2e", []Token{{Type: TokenNumber, Literal: "2"}, {Type: TokenIdent, Literal: "e"}}},
+		{"2e-x", []Token{{Type: TokenNumber, Literal: "2"}, {Type: TokenIdent, Literal: "e"}, {Type: TokenMinus, Literal: "-"}, {Type: TokenIdent, Literal: "x"}}},
+	}
+	for _, tt := range tests {
+		tokens := New(tt.input).AllTokens()
+		tokens = tokens[:len(tokens)-1] // drop EOF
+		if len(tokens) != len(tt.want) {
+			t.Errorf("%q: got %v, want %v", tt.input, tokens, tt.want)
+			continue
+		}
+		for i, want := range tt.want {
+			if tokens[i].Type != want.Type || tokens[i].Literal != want.Literal {
+				t.Errorf("%q token %d: got %s %q, want %s %q", tt.input, i, tokens[i].Type, tokens[i].Literal, want.Type, want.Literal)
+			}
+		}
+	}
+}
+
+func TestLexerSIPrefixedUnits(t *testing.T) {
+	for _, input := range []string{"nm", "GPa", "µm", "kJ", "micrograms"} {
+		if tok := New("3 " + input).AllTokens()[1]; tok.Type != TokenUnit {
+			t.Errorf("%q: expected a unit, got %s", input, tok.Type)
+		}
+	}
+	for _, input := range []string{"pm", "fn"} {
+		if tok := New("x " + input).AllTokens()[1]; tok.Type == TokenUnit {
+			t.Errorf("%q: should not read as a unit", input)
+		}
+	}
+}
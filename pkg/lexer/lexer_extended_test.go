@@ -61,6 +61,8 @@ func TestLexerAllKeywords(t *testing.T) {
 		{"yesterday", TokenYesterday},
 		{"next", TokenNext},
 		{"last", TokenLast},
+		{"about", TokenAbout},
+		{"roughly", TokenAbout},
 		{"monday", TokenMonday},
 		{"tuesday", TokenTuesday},
 		{"wednesday", TokenWednesday},
@@ -0,0 +1,47 @@
+package lexer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGluedUnits(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"10km", "NUMBER(10) UNIT(km)"},
+		{"2.5kg in lb", "NUMBER(2.5) UNIT(kg) in(in) UNIT(lb)"},
+		{"30c in f", "NUMBER(30) UNIT(c) in(in) UNIT(f)"},
+		{"3mps", "NUMBER(3) UNIT(mps)"},
+		{"£3.50/hr*40", "CURRENCY(£) NUMBER(3.50) /(/) UNIT(hr) *(*) NUMBER(40)"},
+		// The longest unit wins: km over k, and square metres over metres
+		{"1.5km", "NUMBER(1.5) UNIT(km)"},
+		{"1.5k", "NUMBER(1.5) UNIT(k)"},
+		{"10m2", "NUMBER(10) UNIT(m2)"},
+		// A unit ends where a number starts, and feet always do, so 6ft2 is
+		// a height like 5ft10; square feet are written apart or as ft²
+		{"5ft10", "NUMBER(5) UNIT(ft) NUMBER(10)"},
+		{"6ft2", "NUMBER(6) UNIT(ft) NUMBER(2)"},
+		{"6 ft2", "NUMBER(6) UNIT(ft2)"},
+		{"6ft²", "NUMBER(6) UNIT(ft²)"},
+		{"5ft10in", "NUMBER(5) UNIT(ft) NUMBER(10) in(in)"},
+		{"1ft²3", "NUMBER(1) UNIT(ft²) NUMBER(3)"},
+		// Words that do not start with a unit stay whole
+		{"2x3", "NUMBER(2) IDENT(x3)"},
+		{"2pi", "NUMBER(2) IDENT(pi)"},
+		// Only a word against its number is split
+		{"5 ft10", "NUMBER(5) IDENT(ft10)"},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, tok := range New(tt.input).AllTokens() {
+			if tok.Type != TokenEOF {
+				got = append(got, tok.Type.String()+"("+tok.Literal+")")
+			}
+		}
+		if strings.Join(got, " ") != tt.want {
+			t.Errorf("%q lexed as %s, want %s", tt.input, strings.Join(got, " "), tt.want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package lexer
+
+import "testing"
+
+func TestOrdinals(t *testing.T) {
+	tests := []struct {
+		input string
+		types []TokenType
+	}{
+		{"1st", []TokenType{TokenOrdinal}},
+		{"2nd", []TokenType{TokenOrdinal}},
+		{"3rd", []TokenType{TokenOrdinal}},
+		{"4th", []TokenType{TokenOrdinal}},
+		{"11th", []TokenType{TokenOrdinal}},
+		{"12th", []TokenType{TokenOrdinal}},
+		{"13th", []TokenType{TokenOrdinal}},
+		{"21st", []TokenType{TokenOrdinal}},
+		{"22ND", []TokenType{TokenOrdinal}},
+		{"111th", []TokenType{TokenOrdinal}},
+		{"21st of march 2026", []TokenType{TokenOrdinal, TokenOf, TokenMarch, TokenNumber}},
+		// A space keeps st as stones, and mismatched suffixes are not ordinals
+		{"1 st", []TokenType{TokenNumber, TokenUnit}},
+		{"2st", []TokenType{TokenNumber, TokenUnit}},
+		{"11st", []TokenType{TokenNumber, TokenUnit}},
+		{"1stone", []TokenType{TokenNumber, TokenUnit}},
+	}
+
+	for _, tt := range tests {
+		tokens := New(tt.input).AllTokens()
+		tokens = tokens[:len(tokens)-1] // drop EOF
+		if len(tokens) != len(tt.types) {
+			t.Errorf("%q: got %v, want %v", tt.input, tokens, tt.types)
+			continue
+		}
+		for i, typ := range tt.types {
+			if tokens[i].Type != typ {
+				t.Errorf("%q: token %d is %s %q, want %s", tt.input, i, tokens[i].Type, tokens[i].Literal, typ)
+			}
+		}
+	}
+}
+
+func TestOrdinalSuffix(t *testing.T) {
+	tests := map[string]string{
+		"1": "st", "2": "nd", "3": "rd", "4": "th", "10": "th", "11": "th", "12": "th",
+		"13": "th", "21": "st", "22": "nd", "23": "rd", "101": "st", "111": "th", "112": "th",
+	}
+	for digits, want := range tests {
+		if got := OrdinalSuffix(digits); got != want {
+			t.Errorf("OrdinalSuffix(%q) = %q, want %q", digits, got, want)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package lexer
+
+import "testing"
+
+func TestLexerPercentUnits(t *testing.T) {
+	tests := []struct {
+		input   string
+		types   []TokenType
+		literal string
+	}{
+		{"3‰", []TokenType{TokenNumber, TokenPercent, TokenEOF}, "‰"},
+		{"2 ‱", []TokenType{TokenNumber, TokenPercent, TokenEOF}, "‱"},
+		{"25 bp", []TokenType{TokenNumber, TokenPercent, TokenEOF}, "bp"},
+		{"7 basispoints", []TokenType{TokenNumber, TokenPercent, TokenEOF}, "basispoints"},
+		{"5 permille", []TokenType{TokenNumber, TokenPercent, TokenEOF}, "permille"},
+		{"10% in bp", []TokenType{TokenNumber, TokenPercent, TokenIn, TokenPercent, TokenEOF}, "%"},
+		// bps is also a data rate, which the parser tells apart
+		{"25 bps", []TokenType{TokenNumber, TokenUnit, TokenEOF}, "bps"},
+		// Away from a number these are plain words, free for variables
+		{"bp", []TokenType{TokenIdent, TokenEOF}, ""},
+	}
+	for _, tt := range tests {
+		tokens := New(tt.input).AllTokens()
+		if len(tokens) != len(tt.types) {
+			t.Errorf("%q: got %v, want %d tokens", tt.input, tokens, len(tt.types))
+			continue
+		}
+		for i, typ := range tt.types {
+			if tokens[i].Type != typ {
+				t.Errorf("%q: token %d is %v, want %v", tt.input, i, tokens[i], typ)
+			}
+		}
+		if len(tokens) > 2 && tokens[1].Literal != tt.literal {
+			t.Errorf("%q: literal %q, want %q", tt.input, tokens[1].Literal, tt.literal)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package lexer
+
+import "testing"
+
+func TestLexerRatioAfterRatioKeyword(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []TokenType
+		literal  string
+	}{
+		{"ratio 2:3", []TokenType{TokenIdent, TokenRatio, TokenEOF}, "2:3"},
+		{"ratio 2:3:4", []TokenType{TokenIdent, TokenRatio, TokenEOF}, "2:3:4"},
+		{"RATIO 1:1:1:1", []TokenType{TokenIdent, TokenRatio, TokenEOF}, "1:1:1:1"},
+		{"ratio 1.5:2.5", []TokenType{TokenIdent, TokenRatio, TokenEOF}, "1.5:2.5"},
+		{"ratio 1:2.5", []TokenType{TokenIdent, TokenRatio, TokenEOF}, "1:2.5"},
+	}
+
+	for _, tt := range tests {
+		tokens := New(tt.input).AllTokens()
+		if len(tokens) != len(tt.expected) {
+			t.Fatalf("input %q: expected %d tokens, got %d", tt.input, len(tt.expected), len(tokens))
+		}
+		for i, tok := range tokens {
+			if tok.Type != tt.expected[i] {
+				t.Errorf("input %q: token %d expected %s, got %s", tt.input, i, tt.expected[i], tok.Type)
+			}
+		}
+		if tokens[1].Literal != tt.literal {
+			t.Errorf("input %q: expected literal %q, got %q", tt.input, tt.literal, tokens[1].Literal)
+		}
+	}
+}
+
+func TestLexerTimeUnchangedOutsideRatio(t *testing.T) {
+	for _, input := range []string{"2:30", "10:30:15", "split 2:3"} {
+		tokens := New(input).AllTokens()
+		found := false
+		for _, tok := range tokens {
+			if tok.Type == TokenRatio {
+				t.Errorf("input %q: unexpected RATIO token", input)
+			}
+			if tok.Type == TokenTimeValue {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("input %q: expected a TIMEVALUE token", input)
+		}
+	}
+}
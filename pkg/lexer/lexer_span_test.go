@@ -0,0 +1,33 @@
+package lexer
+
+import "testing"
+
+func TestLexer_TokenEnd(t *testing.T) {
+	tests := []struct {
+		input string
+		want  [][2]int // Column and End of each token
+	}{
+		{"£3.20 + 5 km", [][2]int{{1, 2}, {2, 6}, {7, 8}, {9, 10}, {11, 13}}},
+		{`print("hi")`, [][2]int{{1, 6}, {6, 7}, {7, 11}, {11, 12}}},
+		{"sum #food", [][2]int{{1, 4}, {5, 10}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var got [][2]int
+			l := New(tt.input)
+			for tok := l.NextToken(); tok.Type != TokenEOF; tok = l.NextToken() {
+				got = append(got, [2]int{tok.Column, tok.End})
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("got %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package lexer
+
+import (
+	"testing"
+
+	"github.com/andrewneudegg/calc/pkg/units"
+)
+
+func TestUnitSymbolVariants(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"20°C", []string{"20", "C"}},
+		{"20 °F", []string{"20", "F"}},
+		{"20 ° F", []string{"20", "F"}},
+		{"5 ℃", []string{"5", "C"}},
+		{"5 µm", []string{"5", "µm"}},
+		{"5 μm", []string{"5", "µm"}},
+		{"3 ㎞", []string{"3", "km"}},
+		{"2 ㎏ in g", []string{"2", "kg", "in", "g"}},
+		{"10 N·m", []string{"10", "N·m"}},
+		{"10 N⋅m", []string{"10", "N·m"}},
+		{"90 °", []string{"90", "°"}},
+		{"90 ° from", []string{"90", "°", "from"}},
+		// Not a unit, so the dot is left out as before
+		{"a·b", []string{"a", "·", "b"}},
+	}
+	system := units.NewSystem()
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			toks := NewWithUnits(tt.input, system.IsUnit).AllTokens()
+			var got []string
+			for _, tok := range toks[:len(toks)-1] {
+				got = append(got, tok.Literal)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("lexed as %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("lexed as %q, want %q", got, tt.want)
+				}
+			}
+			if len(toks) > 2 && toks[1].Type != TokenUnit && tt.want[1] != "·" {
+				t.Errorf("%q is %s, want UNIT", toks[1].Literal, toks[1].Type)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package lexer
+
+import "testing"
+
+func TestLexer_Tags(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []TokenType
+	}{
+		{"£3.20 #food", []TokenType{TokenCurrency, TokenNumber, TokenTag}},
+		{"coffee = 3 #food #work", []TokenType{TokenIdent, TokenEquals, TokenNumber, TokenTag, TokenTag}},
+		{"sum #food", []TokenType{TokenSum, TokenTag}},
+		{"3 #food2", []TokenType{TokenNumber, TokenTag}},
+		// Not tags: no space before, no letter after, or part of prev#3
+		{"prev#3", []TokenType{TokenPrev}},
+		{"3#food", []TokenType{TokenNumber, TokenError, TokenIdent}},
+		{"3 #3", []TokenType{TokenNumber, TokenError, TokenNumber}},
+		{"3 #", []TokenType{TokenNumber, TokenError}},
+		{"#food", []TokenType{TokenError, TokenIdent}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			l := New(tt.input)
+			var got []TokenType
+			for tok := l.NextToken(); tok.Type != TokenEOF; tok = l.NextToken() {
+				got = append(got, tok.Type)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("token %d: got %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLexer_TagLiteral(t *testing.T) {
+	l := New("£3.20 #Eating_Out")
+	l.NextToken()
+	l.NextToken()
+	tok := l.NextToken()
+	if tok.Type != TokenTag || tok.Literal != "#Eating_Out" || tok.Column != 7 {
+		t.Fatalf("unexpected token %+v", tok)
+	}
+	if name := TagName(tok.Literal); name != "eating_out" {
+		t.Errorf("TagName = %q, want eating_out", name)
+	}
+}
@@ -66,6 +66,7 @@ func TestLexerKeywords(t *testing.T) {
 		expected TokenType
 	}{
 		{"in", TokenIn},
+		{"to", TokenTo},
 		{"of", TokenOf},
 		{"per", TokenPer},
 		{"today", TokenToday},
@@ -121,6 +122,10 @@ func TestLexerCurrency(t *testing.T) {
 	}{
 		{"$100", true},
 		{"£50", true},
+		{"₹500", true},
+		{"₩5000", true},
+		{"₺20", true},
+		{"₿1", false},
 		{"10 dollars", false},
 	}
 	
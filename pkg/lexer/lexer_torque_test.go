@@ -0,0 +1,28 @@
+package lexer
+
+import "testing"
+
+func TestLexerTorqueUnits(t *testing.T) {
+	tests := []struct {
+		input   string
+		literal string
+	}{
+		{"5 Nm", "Nm"},
+		{"5 nm", "nm"},
+		{"5 foot-pound", "foot-pound"},
+		{"5 newton-metres", "newton-metres"},
+		{"5 ft-lb", "ft-lb"},
+	}
+	for _, tt := range tests {
+		tokens := New(tt.input).AllTokens()
+		if len(tokens) < 2 || tokens[1].Type != TokenUnit || tokens[1].Literal != tt.literal {
+			t.Errorf("%q: got %v, want unit %s", tt.input, tokens, tt.literal)
+		}
+	}
+
+	// A hyphen between words that are not a unit is still a minus
+	tokens := New("a-b").AllTokens()
+	if len(tokens) < 3 || tokens[0].Literal != "a" || tokens[1].Type != TokenMinus {
+		t.Errorf("a-b: got %v, want a minus b", tokens)
+	}
+}
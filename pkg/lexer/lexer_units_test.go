@@ -0,0 +1,93 @@
+package lexer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewWithUnitsReplacesTable(t *testing.T) {
+	isUnit := func(s string) bool { return s == "box" || s == "m" }
+
+	toks := NewWithUnits("10 box + 2 m + 3 kg", isUnit).AllTokens()
+	want := []TokenType{TokenNumber, TokenUnit, TokenPlus, TokenNumber, TokenUnit, TokenPlus, TokenNumber, TokenIdent, TokenEOF}
+	if len(toks) != len(want) {
+		t.Fatalf("got %d tokens, want %d: %v", len(toks), len(want), toks)
+	}
+	for i, tok := range toks {
+		if tok.Type != want[i] {
+			t.Errorf("token %d (%q) = %s, want %s", i, tok.Literal, tok.Type, want[i])
+		}
+	}
+
+	// New keeps the built-in table
+	if tok := New("box").NextToken(); tok.Type != TokenIdent {
+		t.Errorf("box with the default table = %s, want IDENT", tok.Type)
+	}
+	if tok := New("kg").NextToken(); tok.Type != TokenUnit {
+		t.Errorf("kg with the default table = %s, want UNIT", tok.Type)
+	}
+}
+
+func TestUnitMarks(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"10 m²", []string{"10", "m²"}},
+		{"5 cm³", []string{"5", "cm³"}},
+		{"90 °", []string{"90", "°"}},
+		{"3 ¬ 4", []string{"3", "¬", "4"}},
+	}
+	for _, tt := range tests {
+		done := make(chan []Token, 1)
+		go func() { done <- New(tt.input).AllTokens() }()
+		var toks []Token
+		select {
+		case toks = <-done:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("lexing %q did not finish", tt.input)
+		}
+		var got []string
+		for _, tok := range toks[:len(toks)-1] {
+			got = append(got, tok.Literal)
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%q lexed as %q, want %q", tt.input, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%q lexed as %q, want %q", tt.input, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestUnitConstantReadsAsUnitAfterQuantity(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []TokenType
+	}{
+		{"ly", []TokenType{TokenConstant}},
+		{"2 * ly", []TokenType{TokenNumber, TokenMultiply, TokenConstant}},
+		{"1 ly", []TokenType{TokenNumber, TokenUnit}},
+		{"5 km in ly", []TokenType{TokenNumber, TokenUnit, TokenIn, TokenUnit}},
+		{"5 km to ly", []TokenType{TokenNumber, TokenUnit, TokenTo, TokenUnit}},
+	}
+	for _, tt := range tests {
+		l := New(tt.input)
+		l.SetConstantChecker(func(s string) bool { return s == "ly" })
+		toks := l.AllTokens()
+		toks = toks[:len(toks)-1]
+		if len(toks) != len(tt.want) {
+			t.Errorf("%q: got %v, want %v", tt.input, toks, tt.want)
+			continue
+		}
+		for i, tok := range toks {
+			if tok.Type != tt.want[i] {
+				t.Errorf("%q: token %d (%q) = %s, want %s", tt.input, i, tok.Literal, tok.Type, tt.want[i])
+			}
+		}
+	}
+}
@@ -65,6 +65,23 @@ var enNumberWords = map[string]float64{
 	"quarter":    0.25,
 }
 
+// fractionWords are the spoken denominators, as in "three fifths" or "a
+// third", singular and plural.
+var fractionWords = map[string]float64{
+	"half": 2, "halves": 2,
+	"third": 3, "thirds": 3,
+	"quarter": 4, "quarters": 4,
+	"fifth": 5, "fifths": 5,
+	"sixth": 6, "sixths": 6,
+	"seventh": 7, "sevenths": 7,
+	"eighth": 8, "eighths": 8,
+	"ninth": 9, "ninths": 9,
+	"tenth": 10, "tenths": 10,
+}
+
+// pointWord separates the whole number from its digits, as in "two point five".
+const pointWord = "point"
+
 // Connector words that should be ignored
 var connectorWords = map[string]bool{
 	"and": true,
@@ -80,6 +97,17 @@ func ParseNumberWords(words []string, locale string) (float64, bool) {
 	}
 	
 	numberWords := GetNumberWords(locale)
+
+	for i, w := range words {
+		if strings.EqualFold(w, pointWord) {
+			return parsePointWords(words[:i], words[i+1:], locale)
+		}
+	}
+	if len(words) > 1 {
+		if den, ok := fractionWords[strings.ToLower(words[len(words)-1])]; ok {
+			return parseFractionWords(words[:len(words)-1], den, locale)
+		}
+	}
 	
 	// Single word case
 	if len(words) == 1 {
@@ -133,11 +161,83 @@ func ParseNumberWords(words []string, locale string) (float64, bool) {
 	return 0, false
 }
 
+// parsePointWords reads "two point five" as 2.5: the whole number before
+// "point", which may be left out, then one digit word per decimal place.
+func parsePointWords(whole, digits []string, locale string) (float64, bool) {
+	var val float64
+	if len(whole) > 0 {
+		v, ok := ParseNumberWords(whole, locale)
+		if !ok {
+			return 0, false
+		}
+		val = v
+	}
+	if len(digits) == 0 {
+		return 0, false
+	}
+	numberWords := GetNumberWords(locale)
+	scale := 0.1
+	for _, w := range digits {
+		d, ok := numberWords[strings.ToLower(w)]
+		if !ok || d > 9 || d != float64(int(d)) {
+			return 0, false
+		}
+		val += d * scale
+		scale /= 10
+	}
+	return val, true
+}
+
+// parseFractionWords reads a fraction whose denominator den has been taken
+// off the end of words: "three fifths", "a third", and a whole number with
+// "and a half" or "and three quarters" after it.
+func parseFractionWords(words []string, den float64, locale string) (float64, bool) {
+	// The numerator runs back to the last "and"; before it is the whole number
+	split := -1
+	for i := len(words) - 1; i >= 0; i-- {
+		if strings.EqualFold(words[i], "and") {
+			split = i
+			break
+		}
+	}
+	numerator := words[split+1:]
+	var whole float64
+	if split >= 0 {
+		w, ok := ParseNumberWords(words[:split], locale)
+		if !ok {
+			return 0, false
+		}
+		whole = w
+	}
+	num := 1.0
+	switch {
+	case len(numerator) == 1 && (strings.EqualFold(numerator[0], "a") || strings.EqualFold(numerator[0], "an")):
+	case len(numerator) == 0 && split < 0:
+		return 0, false
+	case len(numerator) == 0:
+		// "two and half" is not English, but means the same
+	default:
+		n, ok := ParseNumberWords(numerator, locale)
+		if !ok {
+			return 0, false
+		}
+		num = n
+	}
+	return whole + num/den, true
+}
+
+// IsFractionWord reports whether word is a spoken denominator such as
+// "half", "thirds" or "quarter".
+func IsFractionWord(word string) bool {
+	_, ok := fractionWords[strings.ToLower(word)]
+	return ok
+}
+
 // IsNumberWord checks if a single word is a number word
 func IsNumberWord(word string, locale string) bool {
 	numberWords := GetNumberWords(locale)
 	_, exists := numberWords[strings.ToLower(word)]
-	if exists {
+	if exists || IsFractionWord(word) || strings.EqualFold(word, pointWord) {
 		return true
 	}
 	return connectorWords[strings.ToLower(word)]
@@ -1,6 +1,7 @@
 package lexer
 
 import (
+	"math"
 	"testing"
 )
 
@@ -63,6 +64,46 @@ func TestParseNumberWords(t *testing.T) {
 	}
 }
 
+func TestParseNumberWordsFractionsAndPoint(t *testing.T) {
+	tests := []struct {
+		input    []string
+		expected float64
+	}{
+		{[]string{"two", "point", "five"}, 2.5},
+		{[]string{"one", "point", "two", "five"}, 1.25},
+		{[]string{"point", "five"}, 0.5},
+		{[]string{"twenty", "one", "point", "zero", "five"}, 21.05},
+		{[]string{"a", "half"}, 0.5},
+		{[]string{"a", "third"}, 1.0 / 3},
+		{[]string{"three", "fifths"}, 0.6},
+		{[]string{"seven", "eighths"}, 0.875},
+		{[]string{"three", "quarters"}, 0.75},
+		{[]string{"one", "and", "a", "half"}, 1.5},
+		{[]string{"two", "and", "three", "quarters"}, 2.75},
+		{[]string{"one", "hundred", "and", "a", "half"}, 100.5},
+		// Existing forms are unchanged
+		{[]string{"half"}, 0.5},
+		{[]string{"one", "hundred", "and", "five"}, 105},
+	}
+
+	for _, tt := range tests {
+		result, ok := ParseNumberWords(tt.input, "en_GB")
+		if !ok {
+			t.Errorf("ParseNumberWords(%v) failed to parse", tt.input)
+			continue
+		}
+		if math.Abs(result-tt.expected) > 1e-9 {
+			t.Errorf("ParseNumberWords(%v) = %f, want %f", tt.input, result, tt.expected)
+		}
+	}
+
+	for _, bad := range [][]string{{"two", "point"}, {"two", "point", "twenty"}, {"third"}} {
+		if v, ok := ParseNumberWords(bad, "en_GB"); ok {
+			t.Errorf("ParseNumberWords(%v) = %v, should have failed", bad, v)
+		}
+	}
+}
+
 func TestParseNumberWordsFail(t *testing.T) {
 	tests := []struct {
 		input []string
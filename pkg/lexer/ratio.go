@@ -0,0 +1,35 @@
+package lexer
+
+// ratioEnd returns where the ratio at the lexer's position ends, for parts
+// such as 2:3:4 or 1.5:2.5 that may each have a decimal point. It returns
+// the position itself when there is no second part.
+func (l *Lexer) ratioEnd() int {
+	end, parts := ratioPartEnd(l.input, l.pos), 1
+	for end+1 < len(l.input) && l.input[end] == ':' && isASCIIDigit(l.input[end+1]) {
+		end = ratioPartEnd(l.input, end+1)
+		parts++
+	}
+	if parts < 2 {
+		return l.pos
+	}
+	return end
+}
+
+// ratioPartEnd returns where the number starting at i ends, taking digits
+// and at most one decimal point followed by more digits.
+func ratioPartEnd(input string, i int) int {
+	for i < len(input) && isASCIIDigit(input[i]) {
+		i++
+	}
+	if i+1 < len(input) && input[i] == '.' && isASCIIDigit(input[i+1]) {
+		i++
+		for i < len(input) && isASCIIDigit(input[i]) {
+			i++
+		}
+	}
+	return i
+}
+
+func isASCIIDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
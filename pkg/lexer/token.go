@@ -30,6 +30,7 @@ const (
 
 	// Keywords
 	TokenIn
+	TokenTo // conversion keyword, a synonym of "in"
 	TokenOf
 	TokenPer
 	TokenBy
@@ -58,6 +59,7 @@ const (
 	TokenNext
 	TokenLast
 	TokenPrev
+	TokenAbout // about or roughly: the line's result is shown rounded, as in about 37 * 42
 	TokenMonday
 	TokenTuesday
 	TokenWednesday
@@ -93,6 +95,18 @@ const (
 	TokenDate
 	TokenTime
 	TokenTimeValue // HH:MM or HH:MM:SS format
+
+	// Ratios
+	TokenRatio // A:B or A:B:C following the "ratio" keyword
+
+	// Ordinals
+	TokenOrdinal // 1st, 2nd, 3rd, 21st: a day of the month rather than a quantity
+
+	// Tags
+	TokenTag // #food: a category a line is filed under, for sum #food
+
+	// Durations
+	TokenDuration // 1h30m or PT2H30M: an amount in several time units
 )
 
 // Token represents a single lexical token.
@@ -101,6 +115,7 @@ type Token struct {
 	Literal string
 	Line    int
 	Column  int
+	End     int // Column just past the token's last character
 }
 
 func (t TokenType) String() string {
@@ -139,6 +154,8 @@ func (t TokenType) String() string {
 		return ":"
 	case TokenIn:
 		return "in"
+	case TokenTo:
+		return "to"
 	case TokenOf:
 		return "of"
 	case TokenPer:
@@ -195,6 +212,8 @@ func (t TokenType) String() string {
 		return "last"
 	case TokenPrev:
 		return "prev"
+	case TokenAbout:
+		return "about"
 	case TokenMonday:
 		return "monday"
 	case TokenTuesday:
@@ -245,6 +264,14 @@ func (t TokenType) String() string {
 		return "TIME"
 	case TokenTimeValue:
 		return "TIMEVALUE"
+	case TokenRatio:
+		return "RATIO"
+	case TokenOrdinal:
+		return "ORDINAL"
+	case TokenTag:
+		return "TAG"
+	case TokenDuration:
+		return "DURATION"
 	default:
 		return "UNKNOWN"
 	}
@@ -5,6 +5,30 @@ import "time"
 // Node represents an AST node.
 type Node interface {
 	node()
+	spanRef() *Span
+}
+
+// Position is a place in the source, as a 1-based line and a column
+// counted in characters, like a token's.
+type Position struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Span is the stretch of source a node was parsed from, with End just past
+// its last character. Every node embeds one. It is zero for nodes that were
+// not read from the source, such as the 12 implied by "a dozen", or that
+// were built outside the parser.
+type Span struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+func (s *Span) spanRef() *Span { return s }
+
+// SpanOf returns the span of the source a node was parsed from.
+func SpanOf(n Node) Span {
+	return *n.spanRef()
 }
 
 // Expr represents an expression node.
@@ -15,11 +39,13 @@ type Expr interface {
 
 // NumberExpr represents a numeric literal.
 type NumberExpr struct {
+	Span
 	Value float64
 }
 
 // BinaryExpr represents a binary operation.
 type BinaryExpr struct {
+	Span
 	Left     Expr
 	Operator string
 	Right    Expr
@@ -27,86 +53,182 @@ type BinaryExpr struct {
 
 // UnaryExpr represents a unary operation.
 type UnaryExpr struct {
+	Span
 	Operator string
 	Operand  Expr
 }
 
 // IdentExpr represents a variable reference.
 type IdentExpr struct {
-	Name string
+	Span
+	Name     string
+	Fallback Expr // What the name means when no variable has it, as total alone is total(); nil for none
 }
 
 // AssignExpr represents a variable assignment.
 type AssignExpr struct {
+	Span
 	Name  string
 	Value Expr
 }
 
-// UnitExpr represents a value with a unit.
-type UnitExpr struct {
+// DestructureExpr represents an assignment of each value of a list to a
+// variable of its own, as in "tip, total = 15% tip on £42.50". A name of
+// "_" discards its value.
+type DestructureExpr struct {
+	Span
+	Names []string
 	Value Expr
-	Unit  string
+}
+
+// AssignedNames returns the variables expr assigns, none when it is not an
+// assignment. The "_" that discards a value is left out.
+func AssignedNames(expr Expr) []string {
+	switch n := expr.(type) {
+	case *AssignExpr:
+		return []string{n.Name}
+	case *DestructureExpr:
+		var names []string
+		for _, name := range n.Names {
+			if name != "_" {
+				names = append(names, name)
+			}
+		}
+		return names
+	}
+	return nil
+}
+
+// Inspect walks the tree under n depth first, calling f for n and, unless f
+// returns false, for each node under it, as ast.Inspect does for Go.
+func Inspect(n Node, f func(Node) bool) {
+	if n == nil || !f(n) {
+		return
+	}
+	eachChild(n, func(child Node) { Inspect(child, f) })
+}
+
+// UnitExpr represents a value with a unit. A rate quoted against a quantity,
+// as in "£1.89 per 100g", sets Per to that quantity (100) and Unit to the
+// single-unit rate "£/g".
+type UnitExpr struct {
+	Span
+	Value      Expr
+	Unit       string
+	Per        float64 // 0 unless the denominator was a quantity
+	Ingredient string  // What is measured, as in 1 cup flour; "" for most amounts
+}
+
+// CompositeExpr represents an amount written in several units, largest
+// first, as in 5 ft 10 in or 1 hour 30 minutes. Its value is the sum of its
+// parts in the first part's unit.
+type CompositeExpr struct {
+	Span
+	Parts    []Expr
+	Duration bool // Written as a duration such as 1h30m or PT2H30M
 }
 
 // ConversionExpr represents a unit conversion.
 type ConversionExpr struct {
+	Span
 	Value  Expr
 	ToUnit string
+	On     Expr // Date whose exchange rate a currency conversion uses, as in "on 15/03/2024"; nil for today's
+}
+
+// MultiConversionExpr represents a conversion to several targets at once, e.g. "100 kg in lb, stone".
+type MultiConversionExpr struct {
+	Span
+	Value   Expr
+	ToUnits []string
 }
 
 // CurrencyExpr represents a currency value.
 type CurrencyExpr struct {
+	Span
 	Value    Expr
 	Currency string
 }
 
-// PercentExpr represents a percentage.
+// PercentExpr represents a percentage. Unit is how it was written when not
+// with %, as bps in 25 bps or ‰ in 3‰.
 type PercentExpr struct {
+	Span
 	Value Expr
+	Unit  string
 }
 
 // PercentOfExpr represents "X% of Y".
 type PercentOfExpr struct {
+	Span
 	Percent Expr
 	Of      Expr
 }
 
-// PercentChangeExpr represents "increase/decrease X by Y%".
+// NamedPercentOfExpr represents "vat of £100", where Percent names a
+// variable that must hold a percentage when the line is evaluated.
+type NamedPercentOfExpr struct {
+	Span
+	Percent Expr
+	Of      Expr
+}
+
+// PercentChangeExpr represents "increase/decrease X by Y", where Y is a percentage or an amount.
 type PercentChangeExpr struct {
+	Span
 	Base     Expr
-	Percent  Expr
+	Percent  Expr // the "by" operand: a percentage, a plain number or a typed amount
 	Increase bool // true for increase, false for decrease
 }
 
 // WhatPercentExpr represents "X is what % of Y".
 type WhatPercentExpr struct {
+	Span
 	Part  Expr
 	Whole Expr
 }
 
 // FunctionCallExpr represents a function call like sum(), average(), etc.
 type FunctionCallExpr struct {
+	Span
 	Name string
 	Args []Expr
 }
 
 // StringExpr represents a string literal.
 type StringExpr struct {
+	Span
 	Value string
 }
 
-// DateExpr represents a date value.
+// DateExpr represents a date value. Relative dates such as today and
+// tomorrow are resolved against the evaluator's clock, so Date is unset and
+// DayOffset counts days from the start of today.
 type DateExpr struct {
-	Date time.Time
+	Span
+	Date      time.Time
+	Relative  bool
+	DayOffset int
+}
+
+// DateAtTimeExpr places a clock time on a date, e.g. "tomorrow at noon".
+type DateAtTimeExpr struct {
+	Span
+	Date Expr
+	Time Expr
 }
 
-// TimeExpr represents a time value.
+// TimeExpr represents a time value. "now" sets Now and is resolved against
+// the evaluator's clock.
 type TimeExpr struct {
+	Span
 	Time time.Time
+	Now  bool
 }
 
 // DateArithmeticExpr represents date arithmetic like "today + 3 days".
 type DateArithmeticExpr struct {
+	Span
 	Base     Expr
 	Operator string
 	Offset   Expr
@@ -115,35 +237,63 @@ type DateArithmeticExpr struct {
 
 // FuzzyExpr represents fuzzy phrases like "half of X", "double X".
 type FuzzyExpr struct {
-	Pattern string // "half", "double", "twice", etc.
+	Span
+	Pattern string // "half", "double", "twice", "duration", etc.
 	Value   Expr
 }
 
 // CommandExpr represents a command like ":save file.txt".
 type CommandExpr struct {
+	Span
 	Command string
 	Args    []string
 }
 
 // RateExpr represents a rate like "100 km / 2 hours".
 type RateExpr struct {
+	Span
 	Numerator   Expr
 	Denominator Expr
 }
 
-// WeekdayExpr represents "next monday", "last friday", etc.
+// WeekdayExpr represents "next monday", "last friday", etc. With From set it
+// counts from that date rather than today, so "friday after 25/12/2025" is
+// the next friday from it and "friday before 25/12/2025" the last.
 type WeekdayExpr struct {
+	Span
 	Weekday  time.Weekday
 	Modifier string // "next", "last", or empty for "this week"
+	From     Expr   // date to count from (optional)
+}
+
+// NthWeekdayExpr represents "first monday of march" or "last friday of
+// march 2026".
+type NthWeekdayExpr struct {
+	Span
+	N       int // 1 to 4, or -1 for the last
+	Weekday time.Weekday
+	Month   time.Month
+	Year    int // 0 for the current year
+}
+
+// CalendarDateExpr represents a day and month written out, as in "25th
+// december", "dec 25" or "december 25th 2025".
+type CalendarDateExpr struct {
+	Span
+	Day   int
+	Month time.Month
+	Year  int // 0 for the current year
 }
 
 // TimeInLocationExpr represents "time in Sydney".
 type TimeInLocationExpr struct {
+	Span
 	Location string
 }
 
 // TimeDifferenceExpr represents "time difference between London and Sydney".
 type TimeDifferenceExpr struct {
+	Span
 	From       string
 	To         string
 	TargetUnit string // Optional: "days", "hours", "minutes", etc.
@@ -151,6 +301,7 @@ type TimeDifferenceExpr struct {
 
 // TimeConversionExpr represents "10am London in Sydney time" or "time in Sydney plus 3 hours in London".
 type TimeConversionExpr struct {
+	Span
 	Time     Expr   // time expression or nil for current time
 	From     string // source location
 	To       string // target location
@@ -160,75 +311,162 @@ type TimeConversionExpr struct {
 
 // MonthExpr represents a month name (e.g., "March", "December") for queries like "days in March".
 type MonthExpr struct {
+	Span
 	Month string // month name
 }
 
 // PrevExpr represents a reference to a previous REPL result (e.g., "prev", "prev~1", "prev~5", "prev#15").
 type PrevExpr struct {
+	Span
 	Offset   int  // 0 for "prev", 1 for "prev~" or "prev~1", 5 for "prev~5", etc.
 	Absolute bool // true for "prev#N" (absolute line number), false for "prev~N" (relative offset)
 }
 
-// ArgDirectiveExpr represents an argument directive like ":arg var_name "prompt text"".
+// ArgDirectiveExpr represents an argument directive like ":arg var_name "prompt text"",
+// optionally followed by a type and "default", "min" and "max" clauses:
+// :arg count "How many" number min 1 max 100 default 10
+// The unit type can name the unit or dimension expected, as in
+// :arg distance "Enter distance" unit km
 type ArgDirectiveExpr struct {
-	Name   string // variable name
-	Prompt string // prompt text (optional)
+	Span
+	Name    string // variable name
+	Prompt  string // prompt text (optional)
+	Type    string // "number", "currency", "unit", "date" or "" for any value
+	Unit    string // unit or dimension after the unit type, such as "km" or "length" (optional)
+	Default Expr   // value used when the argument is not supplied (optional)
+	Min     Expr   // inclusive lower bound (optional)
+	Max     Expr   // inclusive upper bound (optional)
+}
+
+// SplitExpr represents "split X in ratio A:B:C" or "split X as 70/30".
+type SplitExpr struct {
+	Span
+	Value Expr
+	Parts []float64 // relative weights of each share
+}
+
+// ShareExpr represents an amount shared equally, as in "£86.40 between 4",
+// "£86.40 / 4 each" or "£86.40 per person for 4 people".
+type ShareExpr struct {
+	Span
+	Value Expr
+	Count Expr // number of shares, optionally in a count unit such as people
+}
+
+// TipExpr represents "15% tip on £42.50" or "tip 15% on £42.50", which
+// gives both the tip and the total with it.
+type TipExpr struct {
+	Span
+	Percent Expr
+	Base    Expr
+}
+
+// CompareExpr represents a question about which of two values is more, as
+// in "is 5 km more than 3 miles" or "which is bigger: 1 gb or 900 mb".
+type CompareExpr struct {
+	Span
+	Left  Expr
+	Right Expr
+	More  bool // Asks about the greater value (more, bigger), not the lesser (less, smaller)
+	Which bool // "which is ...": the answer is the winning operand rather than yes or no
+}
+
+// TagExpr represents an aggregate over every line filed under a tag, as in
+// "sum #food" or "average #travel".
+type TagExpr struct {
+	Span
+	Func string // "sum" or "average"
+	Tag  string // The tag's name in lower case, without the '#'
+}
+
+// ApproxExpr represents a line asking for a ballpark, as in "about 37 * 42"
+// or "roughly 1234 km in miles". Its value is worked out in full and shown
+// rounded to a few significant figures.
+type ApproxExpr struct {
+	Span
+	Value Expr
 }
 
 // Implement node() for all types
-func (*NumberExpr) node()         {}
-func (*BinaryExpr) node()         {}
-func (*UnaryExpr) node()          {}
-func (*IdentExpr) node()          {}
-func (*AssignExpr) node()         {}
-func (*UnitExpr) node()           {}
-func (*ConversionExpr) node()     {}
-func (*CurrencyExpr) node()       {}
-func (*PercentExpr) node()        {}
-func (*PercentOfExpr) node()      {}
-func (*PercentChangeExpr) node()  {}
-func (*WhatPercentExpr) node()    {}
-func (*FunctionCallExpr) node()   {}
-func (*StringExpr) node()         {}
-func (*DateExpr) node()           {}
-func (*TimeExpr) node()           {}
-func (*DateArithmeticExpr) node() {}
-func (*FuzzyExpr) node()          {}
-func (*CommandExpr) node()        {}
-func (*RateExpr) node()           {}
-func (*WeekdayExpr) node()        {}
-func (*TimeInLocationExpr) node() {}
-func (*TimeDifferenceExpr) node() {}
-func (*TimeConversionExpr) node() {}
-func (*MonthExpr) node()          {}
-func (*PrevExpr) node()           {}
-func (*ArgDirectiveExpr) node()   {}
+func (*NumberExpr) node()          {}
+func (*BinaryExpr) node()          {}
+func (*UnaryExpr) node()           {}
+func (*IdentExpr) node()           {}
+func (*AssignExpr) node()          {}
+func (*DestructureExpr) node()     {}
+func (*UnitExpr) node()            {}
+func (*CompositeExpr) node()       {}
+func (*ConversionExpr) node()      {}
+func (*CurrencyExpr) node()        {}
+func (*PercentExpr) node()         {}
+func (*PercentOfExpr) node()       {}
+func (*NamedPercentOfExpr) node()  {}
+func (*PercentChangeExpr) node()   {}
+func (*WhatPercentExpr) node()     {}
+func (*FunctionCallExpr) node()    {}
+func (*StringExpr) node()          {}
+func (*DateExpr) node()            {}
+func (*TimeExpr) node()            {}
+func (*DateArithmeticExpr) node()  {}
+func (*FuzzyExpr) node()           {}
+func (*CommandExpr) node()         {}
+func (*RateExpr) node()            {}
+func (*WeekdayExpr) node()         {}
+func (*NthWeekdayExpr) node()      {}
+func (*CalendarDateExpr) node()    {}
+func (*TimeInLocationExpr) node()  {}
+func (*TimeDifferenceExpr) node()  {}
+func (*TimeConversionExpr) node()  {}
+func (*DateAtTimeExpr) node()      {}
+func (*MonthExpr) node()           {}
+func (*PrevExpr) node()            {}
+func (*ArgDirectiveExpr) node()    {}
+func (*SplitExpr) node()           {}
+func (*ShareExpr) node()           {}
+func (*TipExpr) node()             {}
+func (*CompareExpr) node()         {}
+func (*TagExpr) node()             {}
+func (*MultiConversionExpr) node() {}
+func (*ApproxExpr) node()          {}
 
 // Implement expr() for expression types
-func (*NumberExpr) expr()         {}
-func (*BinaryExpr) expr()         {}
-func (*UnaryExpr) expr()          {}
-func (*IdentExpr) expr()          {}
-func (*AssignExpr) expr()         {}
-func (*UnitExpr) expr()           {}
-func (*ConversionExpr) expr()     {}
-func (*CurrencyExpr) expr()       {}
-func (*PercentExpr) expr()        {}
-func (*PercentOfExpr) expr()      {}
-func (*PercentChangeExpr) expr()  {}
-func (*WhatPercentExpr) expr()    {}
-func (*FunctionCallExpr) expr()   {}
-func (*StringExpr) expr()         {}
-func (*DateExpr) expr()           {}
-func (*TimeExpr) expr()           {}
-func (*DateArithmeticExpr) expr() {}
-func (*FuzzyExpr) expr()          {}
-func (*CommandExpr) expr()        {}
-func (*RateExpr) expr()           {}
-func (*MonthExpr) expr()          {}
-func (*WeekdayExpr) expr()        {}
-func (*TimeInLocationExpr) expr() {}
-func (*TimeDifferenceExpr) expr() {}
-func (*TimeConversionExpr) expr() {}
-func (*PrevExpr) expr()           {}
-func (*ArgDirectiveExpr) expr()   {}
+func (*NumberExpr) expr()          {}
+func (*BinaryExpr) expr()          {}
+func (*UnaryExpr) expr()           {}
+func (*IdentExpr) expr()           {}
+func (*AssignExpr) expr()          {}
+func (*DestructureExpr) expr()     {}
+func (*UnitExpr) expr()            {}
+func (*CompositeExpr) expr()       {}
+func (*ConversionExpr) expr()      {}
+func (*CurrencyExpr) expr()        {}
+func (*PercentExpr) expr()         {}
+func (*PercentOfExpr) expr()       {}
+func (*NamedPercentOfExpr) expr()  {}
+func (*PercentChangeExpr) expr()   {}
+func (*WhatPercentExpr) expr()     {}
+func (*FunctionCallExpr) expr()    {}
+func (*StringExpr) expr()          {}
+func (*DateExpr) expr()            {}
+func (*TimeExpr) expr()            {}
+func (*DateArithmeticExpr) expr()  {}
+func (*FuzzyExpr) expr()           {}
+func (*CommandExpr) expr()         {}
+func (*RateExpr) expr()            {}
+func (*MonthExpr) expr()           {}
+func (*WeekdayExpr) expr()         {}
+func (*NthWeekdayExpr) expr()      {}
+func (*CalendarDateExpr) expr()    {}
+func (*TimeInLocationExpr) expr()  {}
+func (*TimeDifferenceExpr) expr()  {}
+func (*TimeConversionExpr) expr()  {}
+func (*DateAtTimeExpr) expr()      {}
+func (*PrevExpr) expr()            {}
+func (*ArgDirectiveExpr) expr()    {}
+func (*SplitExpr) expr()           {}
+func (*ShareExpr) expr()           {}
+func (*TipExpr) expr()             {}
+func (*CompareExpr) expr()         {}
+func (*TagExpr) expr()             {}
+func (*MultiConversionExpr) expr() {}
+func (*ApproxExpr) expr()          {}
@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/andrewneudegg/calc/pkg/lexer"
+)
+
+// tryNumberWordsExpr reads number words as a NumberExpr, and a spoken
+// fraction followed by "of" as that share of what follows, as in "a third
+// of 90" or "three fifths of £200". It reports false, consuming nothing,
+// when no number words are there.
+func (p *Parser) tryNumberWordsExpr() (Expr, bool, error) {
+	val, ok := p.tryParseNumberWords()
+	if !ok {
+		return nil, false, nil
+	}
+	num := &NumberExpr{Value: val}
+	if p.current().Type != lexer.TokenOf || !lexer.IsFractionWord(p.tokens[p.pos-1].Literal) {
+		return num, true, nil
+	}
+	p.advance()
+	of, err := p.parseAdditive()
+	if err != nil {
+		return nil, true, err
+	}
+	return &BinaryExpr{Left: num, Operator: "*", Right: of}, true, nil
+}
+
+// tryParseAndFraction reads "and a half" or "and three quarters" after a
+// whole number written in digits, as in "2 and a half hours", returning the
+// whole with the fraction added. It reports false, consuming nothing, when
+// no fraction follows.
+func (p *Parser) tryParseAndFraction(whole float64) (float64, bool) {
+	if !p.atWord("and") {
+		return 0, false
+	}
+	start := p.pos
+	p.advance()
+	if frac, ok := p.tryParseNumberWords(); ok && frac < 1 && lexer.IsFractionWord(p.tokens[p.pos-1].Literal) {
+		return whole + frac, true
+	}
+	p.pos = start
+	return 0, false
+}
+
+// fractionUnitAt reports whether the unit "quarter" at the current token is a
+// fraction after the number words collected so far: "a quarter of 80" or
+// "two and a quarter hours". Otherwise it is three months, as in "a quarter"
+// or "1 quarter in months".
+func (p *Parser) fractionUnitAt(words []string) bool {
+	tok := p.current()
+	if tok.Type != lexer.TokenUnit || !lexer.IsFractionWord(tok.Literal) || len(words) == 0 {
+		return false
+	}
+	if p.peek(1).Type == lexer.TokenOf {
+		return true
+	}
+	n := len(words)
+	return n >= 2 && strings.EqualFold(words[n-2], "and") && (strings.EqualFold(words[n-1], "a") || strings.EqualFold(words[n-1], "an"))
+}
@@ -0,0 +1,98 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/andrewneudegg/calc/pkg/lexer"
+)
+
+// DefaultFew is how many "a few" means unless SetFew says otherwise.
+const DefaultFew = 3
+
+// SetInformal turns the informal quantity words on or off: a dozen, a
+// couple of, a few, 2 dozen, and "a" or "an" before a unit, as in a pinch
+// of salt. They are on unless turned off.
+func (p *Parser) SetInformal(on bool) {
+	p.noInformal = !on
+}
+
+// SetFew sets how many "a few" means.
+func (p *Parser) SetFew(n float64) {
+	p.few = n
+}
+
+// tryQuantityWords reads an informal quantity at the current token: "a
+// dozen" as 12, "a couple" as 2 and "a few" as 3, each optionally followed
+// by "of", and "a" or "an" before a unit as 1. It consumes nothing when
+// none of them is there, or "a" is a defined variable.
+func (p *Parser) tryQuantityWords() (Expr, bool) {
+	if p.noInformal || !(p.atWord("a") || p.atWord("an")) {
+		return nil, false
+	}
+	if p.isVariable != nil && p.isVariable(p.current().Literal) {
+		return nil, false
+	}
+	next := p.peek(1)
+	// "a quarter of 80" is a fraction, read as number words
+	fraction := lexer.IsFractionWord(next.Literal) && p.peek(2).Type == lexer.TokenOf
+	if next.Type == lexer.TokenUnit && !p.isCurrencyCode(next.Literal) && !fraction {
+		p.advance()
+		return &NumberExpr{Value: 1}, true
+	}
+	if next.Type != lexer.TokenIdent {
+		return nil, false
+	}
+	var n float64
+	switch strings.ToLower(next.Literal) {
+	case "dozen":
+		n = 12
+	case "couple":
+		n = 2
+	case "few":
+		n = p.few
+		if n == 0 {
+			n = DefaultFew
+		}
+	default:
+		return nil, false
+	}
+	p.advance()
+	p.advance()
+	if p.current().Type == lexer.TokenOf {
+		p.advance()
+	}
+	return &NumberExpr{Value: n}, true
+}
+
+// parseDozens reads "dozen" after a number, as in 2 dozen, as twelve of it.
+func (p *Parser) parseDozens(expr Expr) Expr {
+	num, ok := expr.(*NumberExpr)
+	if !ok || p.noInformal || !p.atWord("dozen") {
+		return expr
+	}
+	p.advance()
+	return &NumberExpr{Value: num.Value * 12}
+}
+
+// parsePriceEachTail recognises a price for each of count things, as in
+// "12 eggs at £0.25 each", and multiplies them. The word naming the things
+// is optional. It reports false, consuming nothing, when the phrase does not
+// follow.
+func (p *Parser) parsePriceEachTail(count Expr) (Expr, bool) {
+	start := p.pos
+	if p.current().Type == lexer.TokenIdent && !p.atWord("at") && (p.isVariable == nil || !p.isVariable(p.current().Literal)) {
+		p.advance()
+	}
+	if !p.atWord("at") || p.atClock() {
+		p.pos = start
+		return nil, false
+	}
+	p.advance()
+	price, err := p.parseMultiplicative()
+	if err != nil || !p.atWord("each") {
+		p.pos = start
+		return nil, false
+	}
+	p.advance()
+	return &BinaryExpr{Left: count, Operator: "*", Right: price}, true
+}
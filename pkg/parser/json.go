@@ -0,0 +1,160 @@
+package parser
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/andrewneudegg/calc/pkg/lexer"
+)
+
+// Tree is a parse laid out for tools such as editors, which marshals to
+// JSON as calc --parse-only prints it, here indented:
+//
+//	{
+//	  "tokens": [{"type": "NUMBER", "literal": "5", "span": ...}, ...],
+//	  "ast": {"type": "unit", "span": ..., "literals": {"unit": "km", ...},
+//	          "children": {"value": {"type": "number", ...}}}
+//	}
+//
+// When the input does not parse, ast is left out and error says why.
+type Tree struct {
+	Tokens []TreeToken `json:"tokens"`
+	AST    *TreeNode   `json:"ast,omitempty"`
+	Error  *TreeError  `json:"error,omitempty"`
+}
+
+// TreeToken is a token of the input and where it was read.
+type TreeToken struct {
+	Type    string `json:"type"`
+	Literal string `json:"literal"`
+	Span    Span   `json:"span"`
+}
+
+// TreeNode is a node of the AST. Type is the node's name without Expr and
+// starting in lower case, such as "binary" or "percentOf". Literals holds
+// its other fields, such as a binary's operator, and Children the nodes
+// under it, each a *TreeNode or, for a list such as a call's arguments, a
+// []*TreeNode. Keys are the field names, starting in lower case.
+type TreeNode struct {
+	Type     string         `json:"type"`
+	Span     Span           `json:"span"`
+	Literals map[string]any `json:"literals,omitempty"`
+	Children map[string]any `json:"children,omitempty"`
+}
+
+// TreeError is why the input did not parse, and the position of the token
+// the parser stopped at.
+type TreeError struct {
+	Message  string   `json:"message"`
+	Position Position `json:"position"`
+}
+
+// ParseTree parses the tokens as Parse does and returns the tokens and the
+// AST as a Tree, or the error. It only reads the tokens, so it is cheap
+// enough to run as someone types.
+func (p *Parser) ParseTree() *Tree {
+	tree := &Tree{Tokens: []TreeToken{}}
+	for _, tok := range p.tokens {
+		if tok.Type == lexer.TokenEOF {
+			continue
+		}
+		tree.Tokens = append(tree.Tokens, TreeToken{
+			Type:    tok.Type.String(),
+			Literal: tok.Literal,
+			Span:    tokenSpan(tok),
+		})
+	}
+
+	expr, err := p.Parse()
+	if err != nil {
+		tree.Error = &TreeError{Message: err.Error(), Position: p.StopPosition()}
+		return tree
+	}
+	tree.AST = NewTreeNode(expr)
+	return tree
+}
+
+// StopPosition returns where the parser stopped: the current token, or just
+// past the last one at the end of the input. After Parse fails, it is where
+// the error was found.
+func (p *Parser) StopPosition() Position {
+	if tok := p.current(); tok.Type != lexer.TokenEOF {
+		return Position{Line: tok.Line, Column: tok.Column}
+	}
+	for i := len(p.tokens) - 1; i >= 0; i-- {
+		if tok := p.tokens[i]; tok.Type != lexer.TokenEOF {
+			return tokenSpan(tok).End
+		}
+	}
+	return Position{Line: 1, Column: 1}
+}
+
+// NewTreeNode lays out expr and the nodes under it as a TreeNode.
+func NewTreeNode(expr Expr) *TreeNode {
+	v := reflect.ValueOf(expr)
+	if expr == nil || v.Kind() != reflect.Pointer || v.IsNil() {
+		return nil
+	}
+	v = v.Elem()
+	node := &TreeNode{Type: lowerFirst(strings.TrimSuffix(v.Type().Name(), "Expr")), Span: SpanOf(expr)}
+	for i := 0; i < v.NumField(); i++ {
+		field, value := v.Type().Field(i), v.Field(i)
+		name := lowerFirst(field.Name)
+		switch {
+		case field.Anonymous:
+			// The span
+		case field.Type == exprType:
+			if !value.IsNil() {
+				node.setChild(name, NewTreeNode(value.Interface().(Expr)))
+			}
+		case field.Type.Kind() == reflect.Slice && field.Type.Elem() == exprType:
+			list := make([]*TreeNode, 0, value.Len())
+			for j := 0; j < value.Len(); j++ {
+				if !value.Index(j).IsNil() {
+					list = append(list, NewTreeNode(value.Index(j).Interface().(Expr)))
+				}
+			}
+			node.setChild(name, list)
+		default:
+			if node.Literals == nil {
+				node.Literals = make(map[string]any)
+			}
+			node.Literals[name] = literal(value.Interface())
+		}
+	}
+	return node
+}
+
+func (n *TreeNode) setChild(name string, child any) {
+	if n.Children == nil {
+		n.Children = make(map[string]any)
+	}
+	n.Children[name] = child
+}
+
+// literal returns a field's value as it is written in JSON: a time as RFC
+// 3339, a month or weekday by name, and a number JSON cannot hold, such as
+// an infinity, as text.
+func literal(v any) any {
+	switch v := v.(type) {
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case fmt.Stringer:
+		return v.String()
+	case float64:
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return fmt.Sprint(v)
+		}
+	}
+	return v
+}
+
+func lowerFirst(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[size:]
+}
@@ -6,37 +6,94 @@ import (
 	"strings"
 	"time"
 
+	"github.com/andrewneudegg/calc/pkg/currency"
 	"github.com/andrewneudegg/calc/pkg/lexer"
+	"github.com/andrewneudegg/calc/pkg/timezone"
 )
 
 // Parser parses tokens into an AST.
 type Parser struct {
-	tokens []lexer.Token
-	pos    int
-	locale string // Locale for number parsing (e.g., "en_GB", "en_US")
+	tokens       []lexer.Token
+	pos          int
+	locale       string            // Locale for number parsing (e.g., "en_GB", "en_US")
+	argDepth     int               // Nesting depth of function-call argument lists
+	implicitMul  bool              // Read adjacent terms such as 2(3+4) as a product
+	isVariable   func(string) bool // Optional check for defined variables, used by implicit multiplication
+	isIngredient func(string) bool // Optional check for ingredients, as in 1 cup flour
+	isLocation   func(string) bool // Optional check for places of several words, as in time in Sao Paulo
+	depth        int               // Current nesting of operands, such as brackets and signs
+	maxDepth     int               // Deepest nesting accepted before giving up
+	noInformal   bool              // Informal quantity words such as a dozen are off
+	few          float64           // How many "a few" means, 0 for DefaultFew
 }
 
+// DefaultMaxDepth is how deeply operands may nest, as in ((((1)))) or
+// ----1, unless SetMaxDepth says otherwise. It keeps hostile input from
+// exhausting the stack.
+const DefaultMaxDepth = 200
+
 // New creates a new parser from tokens with default UK locale.
 func New(tokens []lexer.Token) *Parser {
 	return &Parser{
-		tokens: tokens,
-		pos:    0,
-		locale: "en_GB", // Default to UK format
+		tokens:   tokens,
+		pos:      0,
+		locale:   "en_GB", // Default to UK format
+		maxDepth: DefaultMaxDepth,
 	}
 }
 
 // NewWithLocale creates a new parser from tokens with a specific locale.
 func NewWithLocale(tokens []lexer.Token, locale string) *Parser {
 	return &Parser{
-		tokens: tokens,
-		pos:    0,
-		locale: locale,
+		tokens:   tokens,
+		pos:      0,
+		locale:   locale,
+		maxDepth: DefaultMaxDepth,
 	}
 }
 
+// SetMaxDepth sets how deeply operands may nest before Parse fails with
+// "expression too deeply nested".
+func (p *Parser) SetMaxDepth(n int) {
+	p.maxDepth = n
+}
+
+// SetImplicitMultiplication turns on reading adjacent terms as a product:
+// 2(3+4), (2+3)(4+5), (1+2)3, and a number followed by a constant or a
+// defined variable, e.g. 2x. A known unit after a number still wins, so 2m
+// stays two metres.
+func (p *Parser) SetImplicitMultiplication(on bool) {
+	p.implicitMul = on
+}
+
+// SetVariableChecker sets the function used to recognise defined variables
+// for implicit multiplication. Without one, only constants qualify.
+func (p *Parser) SetVariableChecker(checker func(string) bool) {
+	p.isVariable = checker
+}
+
+// SetIngredientChecker sets the function used to recognise ingredients
+// written after an amount, as in 1 cup flour. Without one, a word after an
+// amount is only read as an ingredient when a conversion follows it.
+func (p *Parser) SetIngredientChecker(checker func(string) bool) {
+	p.isIngredient = checker
+}
+
+// SetLocationChecker sets the function used to recognise places whose names
+// are several words, as in time in Rio de Janeiro, including those added
+// with :tz add. Without one, only the built-in places are known.
+func (p *Parser) SetLocationChecker(checker func(string) bool) {
+	p.isLocation = checker
+}
+
 // Parse parses the tokens and returns an expression.
 func (p *Parser) Parse() (Expr, error) {
-	return p.parseExpression()
+	expr, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	fillSpans(expr)
+	return expr, nil
 }
 
 func (p *Parser) current() lexer.Token {
@@ -71,24 +128,7 @@ func (p *Parser) expect(typ lexer.TokenType) (lexer.Token, error) {
 
 // isCurrencyCode checks if a unit string is a currency code or name
 func (p *Parser) isCurrencyCode(unit string) bool {
-	lower := strings.ToLower(unit)
-	switch lower {
-	case "usd", "dollar", "dollars",
-		"gbp",
-		"eur", "euro", "euros",
-		"jpy", "yen",
-		// Expanded 3-letter codes
-		"aud", "cad", "nzd",
-		"chf", "sek", "nok", "dkk",
-		"pln", "czk", "huf", "ron",
-		"rub", "try",
-		"aed", "sar", "ils",
-		"cny", "hkd", "sgd", "inr", "krw", "twd", "thb", "myr", "idr", "php",
-		"mxn", "brl", "zar":
-		return true
-	default:
-		return false
-	}
+	return currency.IsName(unit)
 }
 
 // normalizeNumber converts a number string with thousand separators to a valid float string.
@@ -165,19 +205,27 @@ func (p *Parser) isEuropeanLocale() bool {
 	}
 }
 
-func (p *Parser) parseExpression() (Expr, error) {
+func (p *Parser) parseExpression() (node Expr, _ error) {
+	defer p.markSpan(p.pos, &node)
 	// Check for command
 	if p.current().Type == lexer.TokenColon {
 		return p.parseCommand()
 	}
 
 	// Check for assignment (allow keywords and units as variable names)
-	if (p.current().Type == lexer.TokenIdent ||
-		p.isKeywordToken(p.current().Type) ||
-		p.current().Type == lexer.TokenUnit) &&
-		p.peek(1).Type == lexer.TokenEquals {
+	if p.isAssignTarget(p.current().Type) && p.peek(1).Type == lexer.TokenEquals {
+		return p.parseAssignment()
+	}
+	// A label, as in "coffee: £3.20", names the line's value as = would
+	if p.current().Type == lexer.TokenIdent && p.peek(1).Type == lexer.TokenColon && p.peek(2).Type != lexer.TokenEOF {
 		return p.parseAssignment()
 	}
+	if p.atDestructure() {
+		return p.parseDestructure()
+	}
+	if p.atApprox() {
+		return p.parseApprox()
+	}
 
 	// Try parsing timezone queries
 	if expr, ok := p.tryParseTimezoneQuery(); ok {
@@ -185,18 +233,22 @@ func (p *Parser) parseExpression() (Expr, error) {
 	}
 
 	// Try parsing fuzzy phrases first
-	if expr, ok := p.tryParseFuzzyPhrase(); ok {
-		return expr, nil
+	if expr, ok, err := p.tryParseFuzzyPhrase(); ok {
+		return expr, err
 	}
 
 	// Parse standard expression
 	return p.parseConversion()
 }
 
-func (p *Parser) parseCommand() (Expr, error) {
+func (p *Parser) parseCommand() (node Expr, _ error) {
+	defer p.markSpan(p.pos, &node)
 	p.advance() // skip ':'
 
-	if p.current().Type != lexer.TokenIdent && p.current().Type != lexer.TokenArg {
+	// Command names are words, including ones that read as units, such as :units
+	switch p.current().Type {
+	case lexer.TokenIdent, lexer.TokenArg, lexer.TokenUnit:
+	default:
 		return nil, fmt.Errorf("expected command name")
 	}
 
@@ -208,44 +260,50 @@ func (p *Parser) parseCommand() (Expr, error) {
 		return p.parseArgDirective()
 	}
 
-	// Reconstruct the remainder of the line into a raw tail string while
-	// preserving filename/path punctuation like '.', '/', and '-' by gluing
-	// those to adjacent tokens without spaces. Then split on spaces to get args.
-	var tailBuilder strings.Builder
-	glue := map[string]bool{".": true, "/": true, "-": true}
-	wrote := false
+	var tail []lexer.Token
 	for p.current().Type != lexer.TokenEOF {
-		lit := p.current().Literal
-		if wrote {
-			// Add a space before this token unless either side is a glue token
-			if !glue[lit] {
-				// Also peek previous written rune to handle trailing glue
-				// We can't easily get previous token here, so approximate by
-				// checking last rune written.
-				if tailBuilder.Len() > 0 {
-					last, _ := utf8DecLastRune(&tailBuilder)
-					if last != '.' && last != '/' && last != '-' {
-						tailBuilder.WriteByte(' ')
-					}
-				}
-			}
-		}
-		tailBuilder.WriteString(lit)
-		wrote = true
+		tail = append(tail, p.current())
 		p.advance()
 	}
-	tail := strings.TrimSpace(tailBuilder.String())
-	var args []string
-	if tail != "" {
-		args = strings.Fields(tail)
-	}
 
 	return &CommandExpr{
 		Command: command,
-		Args:    args,
+		Args:    commandArgs(tail),
 	}, nil
 }
 
+// pathPunctuation are the characters commandArgs glues to the tokens they
+// touch, so that paths survive lexing: '.', '/' and '-' in notes/a-b.calc,
+// and '\\' and ':' in C:\\notes\\work.calc.
+const pathPunctuation = "./-\\:"
+
+// commandArgs reconstructs the arguments of a command from the tokens after
+// its name. Tokens that touch in the source are joined when either side is
+// path punctuation, and the result is split on spaces. Glue only applies when
+// the tokens touch, so "notes/a-b.calc" stays whole while
+// ":set precision -1" keeps "-1" as its own argument.
+func commandArgs(tokens []lexer.Token) []string {
+	var tailBuilder strings.Builder
+	prevEnd := 0
+	for i, tok := range tokens {
+		lit := tok.Literal
+		if i > 0 {
+			last, _ := utf8DecLastRune(&tailBuilder)
+			touching := tok.Column == prevEnd
+			glued := (len(lit) == 1 && strings.Contains(pathPunctuation, lit)) || strings.ContainsRune(pathPunctuation, last)
+			if !touching || !glued {
+				tailBuilder.WriteByte(' ')
+			}
+		}
+		tailBuilder.WriteString(lit)
+		prevEnd = tok.Column + len([]rune(lit))
+	}
+	if tailBuilder.Len() == 0 {
+		return nil
+	}
+	return strings.Fields(tailBuilder.String())
+}
+
 // parseArgDirective parses ":arg var_name "prompt text"" directives.
 func (p *Parser) parseArgDirective() (Expr, error) {
 	// Expect variable name (can be an identifier, a keyword, or a unit token used as variable name)
@@ -265,24 +323,75 @@ func (p *Parser) parseArgDirective() (Expr, error) {
 		p.advance()
 	}
 
-	return &ArgDirectiveExpr{
+	arg := &ArgDirectiveExpr{
 		Name:   varName,
 		Prompt: prompt,
-	}, nil
+	}
+
+	// Optional type
+	if argTypes[strings.ToLower(p.current().Literal)] && p.current().Type != lexer.TokenEOF {
+		arg.Type = strings.ToLower(p.current().Literal)
+		p.advance()
+		// The unit type may name the unit or dimension it expects
+		if arg.Type == "unit" && p.current().Type != lexer.TokenEOF && !argClauses[strings.ToLower(p.current().Literal)] {
+			arg.Unit = p.current().Literal
+			p.advance()
+		}
+	}
+
+	// Optional "default", "min" and "max" clauses. Each value runs until the next
+	// clause keyword, so "min 1 max 100" is not read as "1 max".
+	for p.current().Type != lexer.TokenEOF {
+		clause := strings.ToLower(p.current().Literal)
+		if !argClauses[clause] {
+			return nil, fmt.Errorf("unexpected %q in :arg %s, expected a type or default, min or max", p.current().Literal, varName)
+		}
+		p.advance()
+
+		var tokens []lexer.Token
+		for p.current().Type != lexer.TokenEOF && !argClauses[strings.ToLower(p.current().Literal)] {
+			tokens = append(tokens, p.current())
+			p.advance()
+		}
+		if len(tokens) == 0 {
+			return nil, fmt.Errorf("missing value after %s in :arg %s", clause, varName)
+		}
+		value, err := NewWithLocale(tokens, p.locale).parseConversion()
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s in :arg %s: %v", clause, varName, err)
+		}
+
+		switch clause {
+		case "default":
+			arg.Default = value
+		case "min":
+			arg.Min = value
+		case "max":
+			arg.Max = value
+		}
+	}
+
+	return arg, nil
 }
 
+// argTypes are the value types an :arg directive can require.
+var argTypes = map[string]bool{"number": true, "currency": true, "unit": true, "date": true}
+
+// argClauses are the keywords that introduce optional :arg clauses.
+var argClauses = map[string]bool{"default": true, "min": true, "max": true}
+
 // isKeywordToken checks if a token type is a keyword that can be used as a variable name or identifier.
 // Not all keywords are included—only those allowed in this context.
 func (p *Parser) isKeywordToken(t lexer.TokenType) bool {
 	switch t {
-	case lexer.TokenIn, lexer.TokenOf, lexer.TokenPer, lexer.TokenBy,
+	case lexer.TokenIn, lexer.TokenTo, lexer.TokenOf, lexer.TokenPer, lexer.TokenBy,
 		lexer.TokenWhat, lexer.TokenIs, lexer.TokenIncrease, lexer.TokenDecrease,
 		lexer.TokenSum, lexer.TokenAverage, lexer.TokenMean, lexer.TokenTotal,
 		lexer.TokenHalf, lexer.TokenDouble, lexer.TokenTwice, lexer.TokenQuarters,
 		lexer.TokenThree, lexer.TokenArg, lexer.TokenAfter, lexer.TokenBefore,
 		lexer.TokenFrom, lexer.TokenAgo, lexer.TokenNow, lexer.TokenToday,
 		lexer.TokenTomorrow, lexer.TokenYesterday, lexer.TokenNext, lexer.TokenLast,
-		lexer.TokenPrev, lexer.TokenTime, lexer.TokenMonday, lexer.TokenTuesday,
+		lexer.TokenPrev, lexer.TokenAbout, lexer.TokenTime, lexer.TokenMonday, lexer.TokenTuesday,
 		lexer.TokenWednesday, lexer.TokenThursday, lexer.TokenFriday, lexer.TokenSaturday,
 		lexer.TokenSunday, lexer.TokenJanuary, lexer.TokenFebruary, lexer.TokenMarch,
 		lexer.TokenApril, lexer.TokenMay, lexer.TokenJune, lexer.TokenJuly,
@@ -306,128 +415,277 @@ func utf8DecLastRune(b *strings.Builder) (rune, bool) {
 	return rune(s[len(s)-1]), true
 }
 
-func (p *Parser) parseAssignment() (Expr, error) {
+func (p *Parser) parseAssignment() (node Expr, _ error) {
+	defer p.markSpan(p.pos, &node)
 	name := p.current().Literal
 	p.advance() // skip identifier
-	p.advance() // skip '='
+	p.advance() // skip '=' or a label's ':'
+
+	value, err := p.parseAssignedValue()
+	if err != nil {
+		return nil, err
+	}
+
+	return &AssignExpr{
+		Name:  name,
+		Value: value,
+	}, nil
+}
+
+// parseAssignedValue parses the right-hand side of an assignment.
+func (p *Parser) parseAssignedValue() (Expr, error) {
+	if p.atApprox() {
+		return p.parseApprox()
+	}
 
 	// Try parsing fuzzy phrases first in assignments
-	if expr, ok := p.tryParseFuzzyPhrase(); ok {
-		return &AssignExpr{
-			Name:  name,
-			Value: expr,
-		}, nil
+	if expr, ok, err := p.tryParseFuzzyPhrase(); ok {
+		return expr, err
 	}
 
 	// Allow timezone queries on the right-hand side of assignments
 	if expr, ok := p.tryParseTimezoneQuery(); ok {
-		return &AssignExpr{
-			Name:  name,
-			Value: expr,
-		}, nil
+		return expr, nil
 	}
 
-	value, err := p.parseConversion()
+	return p.parseConversion()
+}
+
+// atApprox reports whether the line goes on from "about" or "roughly" to
+// the expression it wants a ballpark of. Followed by nothing or by an
+// operator, as in about * 2, the word is a variable instead.
+func (p *Parser) atApprox() bool {
+	if p.current().Type != lexer.TokenAbout {
+		return false
+	}
+	switch p.peek(1).Type {
+	case lexer.TokenEOF, lexer.TokenPlus, lexer.TokenMultiply, lexer.TokenDivide, lexer.TokenPercent,
+		lexer.TokenEquals, lexer.TokenRParen, lexer.TokenComma, lexer.TokenIn, lexer.TokenTo:
+		return false
+	}
+	return true
+}
+
+// parseApprox parses "about 37 * 42" or "roughly 1234 km in miles": the
+// rest of the line, as the right-hand side of an assignment reads.
+func (p *Parser) parseApprox() (node Expr, _ error) {
+	defer p.markSpan(p.pos, &node)
+	p.advance() // skip about or roughly
+	value, err := p.parseAssignedValue()
 	if err != nil {
 		return nil, err
 	}
+	return &ApproxExpr{Value: value}, nil
+}
 
-	return &AssignExpr{
-		Name:  name,
-		Value: value,
-	}, nil
+// isAssignTarget reports whether a token of type t can name a variable being
+// assigned: keywords and units can, as in total = 5 or m = 3.
+func (p *Parser) isAssignTarget(t lexer.TokenType) bool {
+	return t == lexer.TokenIdent || p.isKeywordToken(t) || t == lexer.TokenUnit || t == lexer.TokenConstant
+}
+
+// ReadsBack reports whether name, written alone, reads as the variable of
+// that name. Most keywords do where a value is expected, such as in or per
+// and total, but some keep their own meaning wherever they are: today
+// reads the date and half 0.5.
+func ReadsBack(name string) bool {
+	expr, err := New(lexer.New(name).AllTokens()).Parse()
+	ident, ok := expr.(*IdentExpr)
+	return err == nil && ok && strings.EqualFold(ident.Name, name)
+}
+
+// atDestructure reports whether the line starts with two or more names
+// separated by commas and followed by '=', as in "tip, total = ...".
+func (p *Parser) atDestructure() bool {
+	i := 0
+	for p.isAssignTarget(p.peek(i).Type) && p.peek(i+1).Type == lexer.TokenComma {
+		i += 2
+	}
+	return i > 0 && p.isAssignTarget(p.peek(i).Type) && p.peek(i+1).Type == lexer.TokenEquals
+}
+
+// parseDestructure parses "tip, total = 15% tip on £42.50", which assigns
+// each value of the result to its own name. "_" may be repeated; other
+// names may not.
+func (p *Parser) parseDestructure() (node Expr, _ error) {
+	defer p.markSpan(p.pos, &node)
+	var names []string
+	seen := make(map[string]bool)
+	for {
+		name := p.current().Literal
+		if name != "_" && seen[strings.ToLower(name)] {
+			return nil, fmt.Errorf("%s is assigned twice", name)
+		}
+		seen[strings.ToLower(name)] = true
+		names = append(names, name)
+		p.advance() // skip the name
+		if p.current().Type != lexer.TokenComma {
+			break
+		}
+		p.advance() // skip ','
+	}
+	if len(seen) == 1 && seen["_"] {
+		return nil, fmt.Errorf("nothing to assign: every name is _")
+	}
+	p.advance() // skip '='
+
+	value, err := p.parseAssignedValue()
+	if err != nil {
+		return nil, err
+	}
+	return &DestructureExpr{Names: names, Value: value}, nil
 }
 
-func (p *Parser) tryParseFuzzyPhrase() (Expr, bool) {
+// tryParseFuzzyPhrase parses phrases such as "half of X", "increase X by
+// Y%" and "X is what % of Y". It reports false, with the position restored,
+// when the phrase does not match, so the words can be read as numbers or
+// variables instead. Once the words of a phrase have all matched, as with
+// "increase X by", nothing else could read the line, so an error in the
+// operand after them is returned with ok true rather than left to surface
+// somewhere less helpful.
+func (p *Parser) tryParseFuzzyPhrase() (Expr, bool, error) {
 	tok := p.current()
 
+	// "3 weeks from today", "2 days ago", "a week before next friday", "in 3 weeks"
+	if expr, ok := p.tryParseRelativeDate(); ok {
+		return expr, true, nil
+	}
+
+	// "is 5 km more than 3 miles", "which is bigger: 1 gb or 900 mb"
+	if tok.Type == lexer.TokenIs || (tok.Type == lexer.TokenIdent && strings.EqualFold(tok.Literal, "which")) {
+		if expr, ok := p.tryParseComparison(); ok {
+			return expr, true, nil
+		}
+	}
+
+	// "split X in ratio A:B:C" or "split X as 70/30"
+	if tok.Type == lexer.TokenIdent && strings.EqualFold(tok.Literal, "split") {
+		if expr, ok := p.tryParseSplit(); ok {
+			return expr, true, nil
+		}
+	}
+
+	// "tip 15% on £42.50"
+	if tok.Type == lexer.TokenIdent && strings.EqualFold(tok.Literal, "tip") {
+		rollback := p.checkpoint()
+		p.advance()
+		percent, err := p.parsePostfix()
+		if _, ok := percent.(*PercentExpr); ok && err == nil && p.atWord("on") {
+			p.advance()
+			base, err := p.parseAdditive()
+			if err != nil {
+				return nil, true, fuzzyOperandError("tip ... on", err)
+			}
+			return &TipExpr{Percent: percent, Base: base}, true, nil
+		}
+		rollback()
+	}
+
+	// "duration of 14:00" reads a clock time as the time since midnight
+	if tok.Type == lexer.TokenIdent && strings.EqualFold(tok.Literal, "duration") && p.peek(1).Type == lexer.TokenOf {
+		p.advance() // skip 'duration'
+		p.advance() // skip 'of'
+		value, err := p.parseAdditive()
+		if err != nil {
+			return nil, true, fuzzyOperandError("duration of", err)
+		}
+		expr := &FuzzyExpr{Pattern: "duration", Value: value}
+		if wrapped, ok := p.tryWrapWithConversion(expr); ok {
+			return wrapped, true, nil
+		}
+		return expr, true, nil
+	}
+
 	// "half of X"
 	if tok.Type == lexer.TokenHalf {
+		rollback := p.checkpoint()
 		p.advance()
-		if p.current().Type == lexer.TokenOf {
+		of := p.current().Type == lexer.TokenOf
+		if of {
 			p.advance()
 		}
 		value, err := p.parseConversion()
 		if err != nil {
-			return nil, false
+			if of {
+				return nil, true, fuzzyOperandError("half of", err)
+			}
+			// "half" on its own is a number
+			rollback()
+			return nil, false, nil
 		}
-		return &FuzzyExpr{Pattern: "half", Value: value}, true
+		return &FuzzyExpr{Pattern: "half", Value: value}, true, nil
 	}
 
 	// "double X" or "twice X"
 	if tok.Type == lexer.TokenDouble || tok.Type == lexer.TokenTwice {
+		rollback := p.checkpoint()
 		pattern := tok.Literal
 		p.advance()
+		operand := p.current().Type == lexer.TokenNumber || p.current().Type == lexer.TokenLParen
 		value, err := p.parseConversion()
 		if err != nil {
-			return nil, false
+			if operand {
+				return nil, true, fuzzyOperandError(strings.ToLower(pattern), err)
+			}
+			// "double" may be a variable
+			rollback()
+			return nil, false, nil
 		}
-		return &FuzzyExpr{Pattern: pattern, Value: value}, true
+		return &FuzzyExpr{Pattern: pattern, Value: value}, true, nil
 	}
 
 	// "three quarters of X"
 	if tok.Type == lexer.TokenThree && p.peek(1).Type == lexer.TokenQuarters {
+		rollback := p.checkpoint()
 		p.advance() // skip 'three'
 		p.advance() // skip 'quarters'
-		if p.current().Type == lexer.TokenOf {
+		of := p.current().Type == lexer.TokenOf
+		if of {
 			p.advance()
 		}
 		value, err := p.parseConversion()
 		if err != nil {
-			return nil, false
-		}
-		return &FuzzyExpr{Pattern: "three quarters", Value: value}, true
-	}
-
-	// "increase X by Y%"
-	if tok.Type == lexer.TokenIncrease {
-		p.advance()
-		base, err := p.parseAdditive()
-		if err != nil {
-			return nil, false
-		}
-		if p.current().Type == lexer.TokenBy {
-			p.advance()
-			percent, err := p.parseAdditive()
-			if err != nil {
-				return nil, false
-			}
-			expr := &PercentChangeExpr{Base: base, Percent: percent, Increase: true}
-			// Optional trailing conversion: "in <unit>"
-			if wrapped, ok := p.tryWrapWithConversion(expr); ok {
-				return wrapped, true
+			if of {
+				return nil, true, fuzzyOperandError("three quarters of", err)
 			}
-			return expr, true
+			// "three quarters" on its own is a number
+			rollback()
+			return nil, false, nil
 		}
+		return &FuzzyExpr{Pattern: "three quarters", Value: value}, true, nil
 	}
 
-	// "decrease X by Y%"
-	if tok.Type == lexer.TokenDecrease {
+	// "increase X by Y%" and "decrease X by Y%"
+	if tok.Type == lexer.TokenIncrease || tok.Type == lexer.TokenDecrease {
+		rollback := p.checkpoint()
+		increase := tok.Type == lexer.TokenIncrease
 		p.advance()
 		base, err := p.parseAdditive()
-		if err != nil {
-			return nil, false
-		}
-		if p.current().Type == lexer.TokenBy {
+		if err == nil && p.current().Type == lexer.TokenBy {
 			p.advance()
 			percent, err := p.parseAdditive()
 			if err != nil {
-				return nil, false
+				return nil, true, fuzzyOperandError(strings.ToLower(tok.Literal)+" ... by", err)
 			}
-			expr := &PercentChangeExpr{Base: base, Percent: percent, Increase: false}
+			expr := &PercentChangeExpr{Base: base, Percent: percent, Increase: increase}
+			// Optional trailing conversion: "in <unit>"
 			if wrapped, ok := p.tryWrapWithConversion(expr); ok {
-				return wrapped, true
+				return wrapped, true, nil
 			}
-			return expr, true
+			return expr, true, nil
 		}
+		// "increase" may be a variable
+		rollback()
 	}
 
 	// "X is what % of Y"
 	if p.pos+3 < len(p.tokens) {
 		if p.peek(1).Type == lexer.TokenIs && p.peek(2).Type == lexer.TokenWhat && p.peek(3).Type == lexer.TokenPercent {
+			rollback := p.checkpoint()
 			part, err := p.parseAdditive()
-			if err != nil {
-				return nil, false
+			if err != nil || p.current().Type != lexer.TokenIs {
+				rollback()
+				return nil, false, nil
 			}
 			p.advance() // 'is'
 			p.advance() // 'what'
@@ -437,45 +695,403 @@ func (p *Parser) tryParseFuzzyPhrase() (Expr, bool) {
 			}
 			whole, err := p.parseAdditive()
 			if err != nil {
-				return nil, false
+				return nil, true, fuzzyOperandError("is what % of", err)
 			}
 			expr := &WhatPercentExpr{Part: part, Whole: whole}
 			if wrapped, ok := p.tryWrapWithConversion(expr); ok {
-				return wrapped, true
+				return wrapped, true, nil
 			}
-			return expr, true
+			return expr, true, nil
+		}
+	}
+
+	return nil, false, nil
+}
+
+// checkpoint returns a function that moves the parser back to where it is
+// now, for a phrase that turns out not to match after reading ahead.
+func (p *Parser) checkpoint() (rollback func()) {
+	pos := p.pos
+	return func() { p.pos = pos }
+}
+
+// fuzzyOperandError reports a malformed operand after the words of a fuzzy
+// phrase, naming the phrase so the error points at what follows it.
+func fuzzyOperandError(phrase string, err error) error {
+	return fmt.Errorf("after %q: %w", phrase, err)
+}
+
+// tryParseSplit parses "split X in ratio A:B:C", "split X as 70/30" and
+// "split X between N".
+// The parser position is restored if the phrase does not match.
+func (p *Parser) tryParseSplit() (Expr, bool) {
+	startPos := p.pos
+	p.advance() // skip 'split'
+
+	value, err := p.parseAdditive()
+	if err != nil {
+		p.pos = startPos
+		return nil, false
+	}
+
+	// "split £86.40 between 4" shares the value equally
+	if p.atWord("between") {
+		if share, ok := p.parseShareTail(value); ok {
+			if p.atConversion() {
+				p.advance()
+				share, _ = p.parseConversionTargets(share)
+			}
+			return share, true
+		}
+		p.pos = startPos
+		return nil, false
+	}
+
+	var literals []string
+	switch {
+	case p.current().Type == lexer.TokenIn &&
+		p.peek(1).Type == lexer.TokenIdent && strings.EqualFold(p.peek(1).Literal, "ratio") &&
+		p.peek(2).Type == lexer.TokenRatio:
+		// "in ratio 2:3:4"
+		literals = strings.Split(p.peek(2).Literal, ":")
+		p.advance() // 'in'
+		p.advance() // 'ratio'
+		p.advance() // ratio literal
+	case p.current().Type == lexer.TokenIdent && strings.EqualFold(p.current().Literal, "as"):
+		// "as 70/30" (two parts lex as number / number, three as a date-shaped literal)
+		p.advance()
+		if p.current().Type == lexer.TokenDate {
+			literals = strings.Split(p.current().Literal, "/")
+			p.advance()
+			break
+		}
+		for p.current().Type == lexer.TokenNumber {
+			literals = append(literals, p.current().Literal)
+			p.advance()
+			if p.current().Type != lexer.TokenDivide || p.peek(1).Type != lexer.TokenNumber {
+				break
+			}
+			p.advance() // '/'
+		}
+	}
+
+	if len(literals) < 2 {
+		p.pos = startPos
+		return nil, false
+	}
+
+	parts := make([]float64, 0, len(literals))
+	for _, lit := range literals {
+		val, err := strconv.ParseFloat(p.normalizeNumber(lit), 64)
+		if err != nil {
+			p.pos = startPos
+			return nil, false
+		}
+		parts = append(parts, val)
+	}
+
+	return &SplitExpr{Value: value, Parts: parts}, true
+}
+
+// comparisonWords are the words that ask which of two values is more, as in
+// "is 5 km more than 3 miles", mapped to whether they ask about the greater.
+// They are read only in that position, so they remain usable as variables.
+var comparisonWords = map[string]bool{
+	"more": true, "bigger": true, "larger": true, "greater": true, "longer": true, "heavier": true,
+	"less": false, "smaller": false, "shorter": false, "lighter": false,
+}
+
+// tryParseComparison parses "is X more than Y" and "which is bigger: X or
+// Y", with any of the comparisonWords and an optional question mark. The
+// winner of "which" may be followed by a conversion.
+// The parser position is restored if the phrase does not match.
+func (p *Parser) tryParseComparison() (Expr, bool) {
+	startPos := p.pos
+	expr := &CompareExpr{Which: p.atWord("which")}
+	if expr.Which {
+		p.advance()
+	}
+	if p.current().Type != lexer.TokenIs {
+		p.pos = startPos
+		return nil, false
+	}
+	p.advance()
+
+	// "which is bigger: X or Y" names the comparison first
+	if expr.Which {
+		more, ok := comparisonWords[strings.ToLower(p.current().Literal)]
+		if p.current().Type != lexer.TokenIdent || !ok {
+			p.pos = startPos
+			return nil, false
+		}
+		expr.More = more
+		p.advance()
+		if p.current().Type == lexer.TokenColon || p.current().Type == lexer.TokenComma {
+			p.advance()
+		}
+	}
+
+	left, err := p.parseAdditive()
+	if err != nil {
+		p.pos = startPos
+		return nil, false
+	}
+	expr.Left = left
+
+	if expr.Which {
+		if !p.atWord("or") {
+			p.pos = startPos
+			return nil, false
+		}
+	} else {
+		more, ok := comparisonWords[strings.ToLower(p.current().Literal)]
+		if p.current().Type != lexer.TokenIdent || !ok || p.peek(1).Type != lexer.TokenIdent || !strings.EqualFold(p.peek(1).Literal, "than") {
+			p.pos = startPos
+			return nil, false
+		}
+		expr.More = more
+		p.advance()
+	}
+	p.advance() // skip 'or' or 'than'
+
+	right, err := p.parseAdditive()
+	if err != nil {
+		p.pos = startPos
+		return nil, false
+	}
+	expr.Right = right
+
+	// The winner of "which" can be converted, as in "... or 900 mb in gb"
+	var result Expr = expr
+	if expr.Which {
+		if wrapped, ok := p.tryWrapWithConversion(expr); ok {
+			result = wrapped
 		}
 	}
+	if p.current().Type == lexer.TokenError && p.current().Literal == "?" {
+		p.advance()
+	}
+	if p.current().Type != lexer.TokenEOF {
+		p.pos = startPos
+		return nil, false
+	}
+	return result, true
+}
 
+// countUnits are the count pseudo-units that name who or what an amount is
+// shared between, as in "£86.40 per person for 4 people".
+var countUnits = map[string]bool{
+	"person": true, "people": true, "item": true, "items": true, "units": true,
+}
+
+// atWord reports whether the current token is the identifier word.
+func (p *Parser) atWord(word string) bool {
+	return p.current().Type == lexer.TokenIdent && strings.EqualFold(p.current().Literal, word)
+}
+
+// parseShareTail recognises the phrases that share value equally and wraps
+// it in a ShareExpr:
+//
+//	£86.40 between 4
+//	£86.40 / 4 each
+//	£86.40 per person for 4 people
+//
+// It reports false, consuming nothing, when none of them follows.
+func (p *Parser) parseShareTail(value Expr) (Expr, bool) {
+	switch {
+	case p.atWord("between"):
+		start := p.pos
+		p.advance()
+		count, err := p.parseMultiplicative()
+		if err != nil {
+			p.pos = start
+			return nil, false
+		}
+		return &ShareExpr{Value: value, Count: count}, true
+
+	case p.atWord("each"):
+		div, ok := value.(*BinaryExpr)
+		if !ok || div.Operator != "/" {
+			return nil, false
+		}
+		p.advance()
+		return &ShareExpr{Value: div.Left, Count: div.Right}, true
+
+	case p.atWord("for"):
+		rate, ok := value.(*UnitExpr)
+		if !ok || rate.Per != 0 {
+			return nil, false
+		}
+		slash := strings.LastIndex(rate.Unit, "/")
+		if slash < 0 || !countUnits[strings.ToLower(rate.Unit[slash+1:])] {
+			return nil, false
+		}
+		start := p.pos
+		p.advance()
+		count, err := p.parseMultiplicative()
+		if err != nil {
+			p.pos = start
+			return nil, false
+		}
+		return &ShareExpr{Value: rate.Value, Count: count}, true
+	}
 	return nil, false
 }
 
+// parseDiscountTail recognises "after 20% discount" following value, as in
+// "£80 after 20% discount", and takes the discount off it. It reports false,
+// consuming nothing, when the phrase does not follow.
+func (p *Parser) parseDiscountTail(value Expr) (Expr, bool) {
+	if p.current().Type != lexer.TokenAfter {
+		return nil, false
+	}
+	start := p.pos
+	p.advance()
+	percent, err := p.parsePostfix()
+	if _, ok := percent.(*PercentExpr); !ok || err != nil || !p.atWord("discount") {
+		p.pos = start
+		return nil, false
+	}
+	p.advance()
+	return &PercentChangeExpr{Base: value, Percent: percent, Increase: false}, true
+}
+
+// atConversion reports whether the current token is a conversion keyword,
+// "in" or its synonym "to".
+func (p *Parser) atConversion() bool {
+	return p.current().Type == lexer.TokenIn || p.current().Type == lexer.TokenTo
+}
+
+// atAsTarget reports whether the parser is at "as" followed by a target, as
+// in "today as iso", which converts like "in".
+func (p *Parser) atAsTarget() bool {
+	return p.atWord("as") && isConversionTargetToken(p.peek(1).Type)
+}
+
+// atInches reports whether the parser is at an "in" that means inches: one
+// straight after a number that does not go on to a conversion target. So
+// 5 ft 6 in, x = 3 in and the first "in" of 12 in in cm are inches, while
+// 12 in cm converts 12, as 10 m in cm converts 10 m.
+func (p *Parser) atInches(expr Expr) bool {
+	if _, ok := expr.(*NumberExpr); !ok || p.current().Type != lexer.TokenIn {
+		return false
+	}
+	return !isConversionTargetToken(p.peek(1).Type)
+}
+
+// atAdjacentAmount reports whether the parser is at a number with a unit of
+// its own, such as the 6 in of 5 ft 6 in, straight after an amount.
+func (p *Parser) atAdjacentAmount() bool {
+	if p.current().Type != lexer.TokenNumber {
+		return false
+	}
+	switch p.peek(1).Type {
+	case lexer.TokenUnit:
+		return true
+	case lexer.TokenIn:
+		return !isConversionTargetToken(p.peek(2).Type)
+	}
+	return false
+}
+
+// feetUnits are the units a bare number after is read as inches.
+var feetUnits = map[string]bool{"ft": true, "foot": true, "feet": true}
+
+// atBareInches reports whether the parser is at a plain number straight
+// after an amount in feet, as the 10 of 5ft10 or 5 ft 10 in cm is, which
+// reads as inches the way a height is written.
+func (p *Parser) atBareInches(unit string) bool {
+	if !feetUnits[strings.ToLower(unit)] || p.current().Type != lexer.TokenNumber {
+		return false
+	}
+	switch p.peek(1).Type {
+	case lexer.TokenPercent, lexer.TokenCurrency, lexer.TokenLParen:
+		return false
+	}
+	return true
+}
+
 // tryWrapWithConversion checks for a trailing "in ..." conversion and wraps the given expr
 func (p *Parser) tryWrapWithConversion(expr Expr) (Expr, bool) {
-	if p.current().Type != lexer.TokenIn {
+	if !p.atConversion() {
 		return nil, false
 	}
 	// Parse one or more chained conversions
-	for p.current().Type == lexer.TokenIn {
+	for p.atConversion() {
 		p.advance()
-		toUnit := p.current().Literal
+		var multi bool
+		expr, multi = p.parseConversionTargets(expr)
+		if multi {
+			break
+		}
+	}
+	return expr, true
+}
+
+// parseConversionTarget reads a single conversion target after "in", including
+// compound forms like "m/s" or "km per hour".
+func (p *Parser) parseConversionTarget() string {
+	toUnit := p.current().Literal
+	p.advance()
+
+	// Check if this is a compound unit (e.g., "m/s" or "km per hour")
+	if p.current().Type == lexer.TokenPer {
 		p.advance()
-		if p.current().Type == lexer.TokenPer {
+		if unit, ok := p.rateUnit(); ok {
+			toUnit = toUnit + "/" + unit
+			p.advance()
+		}
+	} else if p.current().Type == lexer.TokenDivide {
+		// Look ahead to see if next token is a unit
+		if p.peek(1).Type == lexer.TokenUnit {
+			p.advance() // consume /
+			toUnit = toUnit + "/" + p.current().Literal
 			p.advance()
-			if p.current().Type == lexer.TokenUnit {
-				toUnit = toUnit + "/" + p.current().Literal
-				p.advance()
-			}
-		} else if p.current().Type == lexer.TokenDivide {
-			if p.peek(1).Type == lexer.TokenUnit {
-				p.advance()
-				toUnit = toUnit + "/" + p.current().Literal
-				p.advance()
-			}
 		}
-		expr = &ConversionExpr{Value: expr, ToUnit: toUnit}
 	}
-	return expr, true
+	return toUnit
+}
+
+// parseConversionTargets wraps expr in a conversion to the target(s) following "in".
+// A comma-separated list ("in lb, stone") yields a MultiConversionExpr and reports true.
+// Lists are not recognised inside function arguments, where commas separate arguments.
+func (p *Parser) parseConversionTargets(expr Expr) (Expr, bool) {
+	toUnit := p.parseConversionTarget()
+	if p.argDepth > 0 || p.current().Type != lexer.TokenComma || !isConversionTargetToken(p.peek(1).Type) {
+		return &ConversionExpr{Value: expr, ToUnit: toUnit}, false
+	}
+
+	targets := []string{toUnit}
+	for p.current().Type == lexer.TokenComma && isConversionTargetToken(p.peek(1).Type) {
+		p.advance() // skip ','
+		targets = append(targets, p.parseConversionTarget())
+	}
+	return &MultiConversionExpr{Value: expr, ToUnits: targets}, true
+}
+
+// parseRateDate reads the "on <date>" after a currency conversion, as in
+// "£100 in USD on 15/03/2024", which converts at that day's rate. Other
+// conversions have no use for a date, so are an error.
+func (p *Parser) parseRateDate(expr Expr) (Expr, error) {
+	conv, ok := expr.(*ConversionExpr)
+	if !ok {
+		return nil, fmt.Errorf("on <date> needs a single currency to convert to, as in £100 in USD on 15/03/2024")
+	}
+	if !currency.IsName(conv.ToUnit) && !currency.IsSymbol(conv.ToUnit) {
+		return nil, fmt.Errorf("on <date> only applies to currency conversions, not to %s", conv.ToUnit)
+	}
+	p.advance() // skip 'on'
+	date, err := p.parseAdditive()
+	if err != nil {
+		return nil, fmt.Errorf("on <date>: %w", err)
+	}
+	conv.On = date
+	return conv, nil
+}
+
+// isConversionTargetToken reports whether a token can name a conversion target in a list.
+func isConversionTargetToken(t lexer.TokenType) bool {
+	return t == lexer.TokenUnit || t == lexer.TokenIdent || t == lexer.TokenCurrency || t == lexer.TokenPercent
 }
 
 // tryParseTimezoneQuery attempts to parse timezone-related queries
@@ -506,7 +1122,7 @@ func (p *Parser) tryParseTimezoneQuery() (Expr, bool) {
 			}
 
 			// Check for "in <target_location>"
-			if p.current().Type == lexer.TokenIn {
+			if p.atConversion() {
 				p.advance()
 				targetLocation := p.parseLocationName()
 				return &TimeConversionExpr{
@@ -519,9 +1135,52 @@ func (p *Parser) tryParseTimezoneQuery() (Expr, bool) {
 			}
 		}
 
+		// "time in Tokyo at noon London time": a clock time read in another location
+		if p.atClock() {
+			p.advance() // skip 'at'
+			clock, err := p.parsePrimary()
+			if err != nil {
+				return nil, false
+			}
+			from := p.parseLocationName()
+			if from == "" {
+				from = location
+			}
+			if p.current().Type == lexer.TokenTime {
+				p.advance()
+			}
+			return &TimeConversionExpr{Time: clock, From: from, To: location}, true
+		}
+
+		// "time in Sydney to London": the current time there, read in another location
+		if p.atConversion() {
+			start := p.pos
+			p.advance()
+			if target := p.parseLocationName(); target != "" {
+				return &TimeConversionExpr{From: location, To: target}, true
+			}
+			p.pos = start
+		}
+
 		return &TimeInLocationExpr{Location: location}, true
 	}
 
+	// "14:30 London to Tokyo" or "noon London in Tokyo": a clock time read in another location
+	if tok.Type == lexer.TokenTimeValue && p.peek(1).Type == lexer.TokenIdent {
+		start := p.pos
+		clock, err := p.parsePrimary()
+		if err == nil {
+			from := p.parseLocationName()
+			if from != "" && p.atConversion() {
+				p.advance()
+				if to := p.parseLocationName(); to != "" && p.current().Type == lexer.TokenEOF {
+					return &TimeConversionExpr{Time: clock, From: from, To: to}, true
+				}
+			}
+		}
+		p.pos = start
+	}
+
 	// "time difference between <loc1> and <loc2>"
 	// or "time difference <loc1> <loc2>"
 	if tok.Type == lexer.TokenTime && p.peek(1).Type == lexer.TokenIdent && p.peek(1).Literal == "difference" {
@@ -558,33 +1217,36 @@ func (p *Parser) tryParseTimezoneQuery() (Expr, bool) {
 	return nil, false
 }
 
-// parseLocationName parses a location name (can be multi-word like "New York")
-func (p *Parser) parseLocationName() string {
-	var parts []string
-
-	// Known multi-word cities
-	multiWordCities := []string{"New York", "Los Angeles", "Hong Kong"}
+// maxLocationWords is the most words a place name is looked for in, as in
+// Rio de Janeiro.
+const maxLocationWords = 5
 
-	// Try to match multi-word cities first
-	for _, city := range multiWordCities {
-		cityParts := strings.Split(city, " ")
-		matches := true
+// builtinLocation recognises the built-in places for parsers without a
+// location checker.
+var builtinLocation = timezone.NewSystem().IsLocation
 
-		for i, part := range cityParts {
-			if p.peek(i).Type != lexer.TokenIdent ||
-				!strings.EqualFold(p.peek(i).Literal, part) {
-				matches = false
-				break
-			}
+// parseLocationName parses a location name, which may be several words such
+// as New York or São Paulo. The longest run of words naming a known place is
+// taken; otherwise a single word is, to be looked up when evaluated.
+func (p *Parser) parseLocationName() string {
+	isLocation := p.isLocation
+	if isLocation == nil {
+		isLocation = builtinLocation
+	}
+	words := 0
+	for words < maxLocationWords && p.isLocationWord(p.peek(words).Type) {
+		words++
+	}
+	for n := words; n > 1; n-- {
+		parts := make([]string, n)
+		for i := range parts {
+			parts[i] = p.peek(i).Literal
 		}
-
-		if matches {
-			// Consume all parts of the city name
-			for range cityParts {
-				parts = append(parts, p.current().Literal)
+		if name := strings.Join(parts, " "); isLocation(name) {
+			for range n {
 				p.advance()
 			}
-			return strings.Join(parts, " ")
+			return name
 		}
 	}
 
@@ -598,70 +1260,63 @@ func (p *Parser) parseLocationName() string {
 	return ""
 }
 
-func (p *Parser) parseConversion() (Expr, error) {
+// isLocationWord reports whether a token of type t can be a word of a place
+// name, as the of of Port of Spain is.
+func (p *Parser) isLocationWord(t lexer.TokenType) bool {
+	return t == lexer.TokenIdent || t == lexer.TokenUnit || p.isKeywordToken(t)
+}
+
+func (p *Parser) parseConversion() (node Expr, _ error) {
+	defer p.markSpan(p.pos, &node)
+	from := p.pos
 	// Parse the left-hand side expression first
 	expr, err := p.parseAdditive()
 	if err != nil {
 		return nil, err
 	}
-
-	// Handle one or more postfix "in ..." conversions that apply to the current expr
-	for p.current().Type == lexer.TokenIn {
-		p.advance()
-		toUnit := p.current().Literal
-		p.advance()
-
-		// Check if this is a compound unit (e.g., "m/s" or "km per hour")
-		if p.current().Type == lexer.TokenPer {
-			p.advance()
-			if p.current().Type == lexer.TokenUnit {
-				toUnit = toUnit + "/" + p.current().Literal
-				p.advance()
-			}
-		} else if p.current().Type == lexer.TokenDivide {
-			// Look ahead to see if next token is a unit
-			if p.peek(1).Type == lexer.TokenUnit {
-				p.advance() // consume /
-				toUnit = toUnit + "/" + p.current().Literal
-				p.advance()
-			}
-		}
-
-		expr = &ConversionExpr{Value: expr, ToUnit: toUnit}
+	if share, ok := p.parseShareTail(expr); ok {
+		expr = share
+	}
+	if total, ok := p.parsePriceEachTail(expr); ok {
+		expr = total
+	}
+	if discounted, ok := p.parseDiscountTail(expr); ok {
+		expr = discounted
 	}
 
-	// After applying any conversions, allow additive tail (e.g., "(a in x) + b")
 	for {
-		tok := p.current()
-		var op string
-
-		if tok.Type == lexer.TokenPlus {
-			op = "+"
-		} else if tok.Type == lexer.TokenMinus {
-			op = "-"
-		} else if tok.Type == lexer.TokenIdent {
-			if tok.Literal == "plus" {
-				op = "+"
-			} else if tok.Literal == "minus" || tok.Literal == "and" {
-				op = map[bool]string{true: "+", false: "-"}[tok.Literal == "plus" || tok.Literal == "and"]
-			} else {
+		// Handle one or more postfix "in ..." conversions that apply to the current expr
+		for p.atConversion() || p.atAsTarget() {
+			p.advance()
+			var multi bool
+			expr, multi = p.parseConversionTargets(expr)
+			p.markSpan(from, &expr)
+			if p.atWord("on") {
+				if expr, err = p.parseRateDate(expr); err != nil {
+					return nil, err
+				}
+			}
+			if multi {
+				// A list of targets ends the conversion chain
 				break
 			}
-		} else {
-			break
 		}
 
-		p.advance()
-
-		right, err := p.parseMultiplicative()
+		// A converted value is an ordinary operand, so "a in x * b + c" continues
+		// at every precedence level exactly as "(a in x) * b + c" would
+		start := p.pos
+		expr, err = p.parseMultiplicativeTail(expr)
 		if err != nil {
 			return nil, err
 		}
-
-		expr = &BinaryExpr{Left: expr, Operator: op, Right: right}
+		expr, err = p.parseAdditiveTail(expr)
+		if err != nil {
+			return nil, err
+		}
+		if p.pos == start || !(p.atConversion() || p.atAsTarget()) {
+			return expr, nil
+		}
 	}
-
-	return expr, nil
 }
 
 func (p *Parser) parseAdditive() (Expr, error) {
@@ -669,10 +1324,15 @@ func (p *Parser) parseAdditive() (Expr, error) {
 	if err != nil {
 		return nil, err
 	}
+	return p.parseAdditiveTail(left)
+}
 
+// parseAdditiveTail applies any following + and - operations to left.
+func (p *Parser) parseAdditiveTail(left Expr) (Expr, error) {
 	for {
 		tok := p.current()
 		var op string
+		joinedByAnd := false
 
 		// Check for symbolic operators
 		if tok.Type == lexer.TokenPlus {
@@ -686,6 +1346,10 @@ func (p *Parser) parseAdditive() (Expr, error) {
 			} else if tok.Literal == "minus" {
 				op = "-"
 			} else if tok.Literal == "and" {
+				// Inside an argument list "and" separates arguments, as in "sum of 1, 2 and 3"
+				if p.argDepth > 0 {
+					break
+				}
 				// Validate: reject mixing numeric literals with number words via "and"
 				// Check if left is a simple number literal and right would be a number word
 				if _, ok := left.(*NumberExpr); ok {
@@ -707,6 +1371,7 @@ func (p *Parser) parseAdditive() (Expr, error) {
 				}
 				// "and" acts as addition when connecting units or numbers
 				op = "+"
+				joinedByAnd = true
 			} else {
 				break
 			}
@@ -720,6 +1385,11 @@ func (p *Parser) parseAdditive() (Expr, error) {
 		if err != nil {
 			return nil, err
 		}
+		if joinedByAnd {
+			if err := p.checkAndOperands(left, right); err != nil {
+				return nil, err
+			}
+		}
 
 		left = &BinaryExpr{
 			Left:     left,
@@ -731,13 +1401,85 @@ func (p *Parser) parseAdditive() (Expr, error) {
 	return left, nil
 }
 
+// checkAndOperands reports an error when "and" joins something other than
+// two values of the same kind, so that "rent and bills" with neither defined,
+// or "£5 and 3 kg", suggest '+' rather than failing later or adding silently.
+func (p *Parser) checkAndOperands(left, right Expr) error {
+	lk, rk := p.andOperandKind(left), p.andOperandKind(right)
+	for _, side := range []struct {
+		expr Expr
+		kind string
+	}{{left, lk}, {right, rk}} {
+		if side.kind != "" {
+			continue
+		}
+		if ident, ok := side.expr.(*IdentExpr); ok {
+			return fmt.Errorf("cannot use 'and' with undefined variable %s; did you mean '+'?", ident.Name)
+		}
+		return fmt.Errorf("cannot use 'and' with %s; did you mean '+'?", SExpr(side.expr))
+	}
+	if lk != rk && lk != "value" && rk != "value" {
+		return fmt.Errorf("cannot use 'and' between a %s and a %s; did you mean '+'?", lk, rk)
+	}
+	return nil
+}
+
+// andOperandKind names the kind of value expr stands for as an operand of
+// "and": "number", "unit", "currency" or "percentage" for literals, "value"
+// when only evaluation can tell, and "" for things that are not values.
+func (p *Parser) andOperandKind(expr Expr) string {
+	switch n := expr.(type) {
+	case *NumberExpr:
+		return "number"
+	case *UnitExpr, *CompositeExpr:
+		return "unit"
+	case *CurrencyExpr:
+		return "currency"
+	case *PercentExpr:
+		return "percentage"
+	case *IdentExpr:
+		if p.isVariable == nil || p.isVariable(n.Name) || p.isConstantName(n.Name) {
+			return "value"
+		}
+		return ""
+	case *BinaryExpr, *UnaryExpr, *FunctionCallExpr, *ConversionExpr, *FuzzyExpr,
+		*PercentOfExpr, *NamedPercentOfExpr, *PercentChangeExpr, *WhatPercentExpr, *TipExpr, *RateExpr, *PrevExpr:
+		return "value"
+	default:
+		return ""
+	}
+}
+
+// isConstantName reports whether name was read as a physical constant.
+func (p *Parser) isConstantName(name string) bool {
+	for _, tok := range p.tokens {
+		if tok.Type == lexer.TokenConstant && tok.Literal == name {
+			return true
+		}
+	}
+	return false
+}
+
 func (p *Parser) parseMultiplicative() (Expr, error) {
 	left, err := p.parseUnary()
 	if err != nil {
 		return nil, err
 	}
+	return p.parseMultiplicativeTail(left)
+}
 
+// parseMultiplicativeTail applies any following * and / operations to left.
+func (p *Parser) parseMultiplicativeTail(left Expr) (Expr, error) {
 	for {
+		if p.impliesMultiplication() {
+			right, err := p.parseUnary()
+			if err != nil {
+				return nil, err
+			}
+			left = &BinaryExpr{Left: left, Operator: "*", Right: right}
+			continue
+		}
+
 		tok := p.current()
 		var op string
 
@@ -783,17 +1525,62 @@ func (p *Parser) parseMultiplicative() (Expr, error) {
 			return nil, err
 		}
 
-		left = &BinaryExpr{
-			Left:     left,
-			Operator: op,
-			Right:    right,
+		left = &BinaryExpr{
+			Left:     left,
+			Operator: op,
+			Right:    right,
+		}
+	}
+
+	return left, nil
+}
+
+// ingredientAt reports whether the word offset tokens on from an amount such
+// as 1 cup names what is measured: a known ingredient, or any other word that
+// a conversion follows, so 1 cup apples in g can report that apples is not
+// known. Words the grammar uses, such as "and" in 1 m and 3 cm, are left alone.
+func (p *Parser) ingredientAt(offset int) bool {
+	tok := p.peek(offset)
+	if tok.Type != lexer.TokenIdent || p.isVariable != nil && p.isVariable(tok.Literal) {
+		return false
+	}
+	if p.isIngredient != nil && p.isIngredient(tok.Literal) {
+		return true
+	}
+	next := p.peek(offset + 1).Type
+	return next == lexer.TokenIn || next == lexer.TokenTo
+}
+
+// impliesMultiplication reports whether the next token starts a term that is
+// multiplied by the one just parsed, when implicit multiplication is on.
+func (p *Parser) impliesMultiplication() bool {
+	if !p.implicitMul || p.pos == 0 {
+		return false
+	}
+	prev, cur := p.tokens[p.pos-1], p.current()
+	switch prev.Type {
+	case lexer.TokenNumber:
+		switch cur.Type {
+		case lexer.TokenLParen, lexer.TokenConstant:
+			return true
+		case lexer.TokenIdent:
+			return p.isVariable != nil && p.isVariable(cur.Literal)
 		}
+	case lexer.TokenRParen:
+		return cur.Type == lexer.TokenLParen || cur.Type == lexer.TokenNumber
 	}
-
-	return left, nil
+	return false
 }
 
-func (p *Parser) parseUnary() (Expr, error) {
+func (p *Parser) parseUnary() (node Expr, _ error) {
+	defer p.markSpan(p.pos, &node)
+	// Every bracket and sign passes through here, so this bounds the recursion
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > p.maxDepth {
+		return nil, fmt.Errorf("expression too deeply nested (more than %d levels)", p.maxDepth)
+	}
+
 	tok := p.current()
 
 	if tok.Type == lexer.TokenMinus {
@@ -811,14 +1598,25 @@ func (p *Parser) parseUnary() (Expr, error) {
 	return p.parsePostfix()
 }
 
-func (p *Parser) parsePostfix() (Expr, error) {
+func (p *Parser) parsePostfix() (node Expr, _ error) {
+	start := p.pos
+	defer p.markSpan(start, &node)
 	expr, err := p.parsePrimary()
 	if err != nil {
 		return nil, err
 	}
+	expr = p.parseDozens(expr)
+
+	// A currency symbol written after the amount, as in 100€ or 45,50 €. A symbol
+	// followed by a number starts the next amount instead.
+	if num, ok := expr.(*NumberExpr); ok && p.current().Type == lexer.TokenCurrency && p.peek(1).Type != lexer.TokenNumber {
+		expr = &CurrencyExpr{Value: num, Currency: p.current().Literal}
+		p.advance()
+		p.markSpan(start, &expr)
+	}
 
 	// Check for unit
-	if p.current().Type == lexer.TokenUnit {
+	if p.current().Type == lexer.TokenUnit && !p.atPercent() && !p.atPerAnnum(expr) || p.atInches(expr) {
 		unit := p.current().Literal
 		p.advance()
 
@@ -829,17 +1627,21 @@ func (p *Parser) parsePostfix() (Expr, error) {
 				Value:    expr,
 				Currency: unit,
 			}
+			p.markSpan(start, &expr)
 
 			// Check for "per" (rate) after currency - e.g., "32 dollars per day"
 			if p.current().Type == lexer.TokenPer {
 				p.advance()
-				if p.current().Type == lexer.TokenUnit {
-					unit2 := p.current().Literal
-					p.advance()
+				unit2, per, err := p.parseRateDenominator()
+				if err != nil {
+					return nil, err
+				}
+				if unit2 != "" {
 					// Convert to a unit expression with currency/time rate
 					// Store as a UnitExpr with compound unit like "$/day"
 					currencySymbol := p.getCurrencySymbol(unit)
-					expr = &UnitExpr{Value: expr, Unit: currencySymbol + "/" + unit2}
+					expr = &UnitExpr{Value: expr, Unit: currencySymbol + "/" + unit2, Per: per}
+					p.markSpan(start, &expr)
 				}
 			} else if p.current().Type == lexer.TokenDivide {
 				// Look ahead to see if this is a rate (/ followed by unit)
@@ -849,20 +1651,25 @@ func (p *Parser) parsePostfix() (Expr, error) {
 					p.advance()
 					currencySymbol := p.getCurrencySymbol(unit)
 					expr = &UnitExpr{Value: expr, Unit: currencySymbol + "/" + unit2}
+					p.markSpan(start, &expr)
 				}
 			}
 		} else {
 			// Regular unit
 			expr = &UnitExpr{Value: expr, Unit: unit}
+			p.markSpan(start, &expr)
 
 			// Check for "per" (rate) - only consume / if immediately followed by a unit
 			// If followed by a number, leave the / for the binary operator parser
 			if p.current().Type == lexer.TokenPer {
 				p.advance()
-				if p.current().Type == lexer.TokenUnit {
-					unit2 := p.current().Literal
-					p.advance()
-					expr = &UnitExpr{Value: expr, Unit: unit + "/" + unit2}
+				unit2, per, err := p.parseRateDenominator()
+				if err != nil {
+					return nil, err
+				}
+				if unit2 != "" {
+					expr = &UnitExpr{Value: expr, Unit: unit + "/" + unit2, Per: per}
+					p.markSpan(start, &expr)
 				}
 			} else if p.current().Type == lexer.TokenDivide {
 				// Look ahead to see if this is a rate (/ followed by unit) or division (/ followed by number)
@@ -871,8 +1678,51 @@ func (p *Parser) parsePostfix() (Expr, error) {
 					unit2 := p.current().Literal
 					p.advance()
 					expr = &UnitExpr{Value: expr, Unit: unit + "/" + unit2}
+					p.markSpan(start, &expr)
 				}
 				// Otherwise, leave the / for the binary operator parser to handle
+			} else if p.ingredientAt(0) {
+				// A word naming what is measured, as in 1 cup flour, which lets
+				// the amount convert between volume and mass
+				expr.(*UnitExpr).Ingredient = strings.ToLower(p.current().Literal)
+				p.advance()
+			} else if p.current().Type == lexer.TokenOf && p.ingredientAt(1) {
+				// 3 pinches of salt
+				p.advance()
+				expr.(*UnitExpr).Ingredient = strings.ToLower(p.current().Literal)
+				p.advance()
+			}
+
+			// An amount written in several units, as in 5 ft 6 in or
+			// 1 h 30 min, is one quantity
+			if p.atAdjacentAmount() {
+				rest, err := p.parsePostfix()
+				if err != nil {
+					return nil, err
+				}
+				parts := []Expr{expr}
+				if more, ok := rest.(*CompositeExpr); ok {
+					parts = append(parts, more.Parts...)
+				} else {
+					parts = append(parts, rest)
+				}
+				expr = &CompositeExpr{Parts: parts}
+				p.markSpan(start, &expr)
+			} else if p.atBareInches(unit) {
+				// Feet then a bare number, as in 5ft10, are feet and inches
+				from := p.pos
+				num, err := p.parsePrimary()
+				if err != nil {
+					return nil, err
+				}
+				var inches Expr = &UnitExpr{Value: num, Unit: "in"}
+				p.markSpan(from, &inches)
+				expr = &CompositeExpr{Parts: []Expr{expr, inches}}
+				p.markSpan(start, &expr)
+			} else if p.current().Type == lexer.TokenNumber {
+				// Only feet take a bare number after them, so the 10 of
+				// 5 km 10 is a mistake rather than something to drop
+				return nil, fmt.Errorf("unexpected number %s after %s; give it a unit or an operator", p.current().Literal, unit)
 			}
 		}
 	}
@@ -882,10 +1732,13 @@ func (p *Parser) parsePostfix() (Expr, error) {
 	if currExpr, ok := expr.(*CurrencyExpr); ok {
 		if p.current().Type == lexer.TokenPer {
 			p.advance()
-			if p.current().Type == lexer.TokenUnit {
-				unit := p.current().Literal
-				p.advance()
-				expr = &UnitExpr{Value: currExpr, Unit: currExpr.Currency + "/" + unit}
+			unit, per, err := p.parseRateDenominator()
+			if err != nil {
+				return nil, err
+			}
+			if unit != "" {
+				expr = &UnitExpr{Value: currExpr, Unit: currExpr.Currency + "/" + unit, Per: per}
+				p.markSpan(start, &expr)
 			}
 		} else if p.current().Type == lexer.TokenDivide {
 			// Look ahead to see if this is a rate (/ followed by unit)
@@ -894,13 +1747,24 @@ func (p *Parser) parsePostfix() (Expr, error) {
 				unit := p.current().Literal
 				p.advance()
 				expr = &UnitExpr{Value: currExpr, Unit: currExpr.Currency + "/" + unit}
+				p.markSpan(start, &expr)
 			}
+		} else if p.atPerAnnum(expr) {
+			// £45,000 pa
+			p.advance()
+			expr = &UnitExpr{Value: currExpr, Unit: p.getCurrencySymbol(currExpr.Currency) + "/year"}
+			p.markSpan(start, &expr)
 		}
 	}
 
 	// Check for percentage
-	if p.current().Type == lexer.TokenPercent {
+	if p.atPercent() {
+		unit := percentUnit(p.current().Literal)
 		p.advance()
+		if unit != "" {
+			expr = &PercentExpr{Value: expr, Unit: unit}
+			p.markSpan(start, &expr)
+		}
 
 		// Check for "of"
 		if p.current().Type == lexer.TokenOf {
@@ -912,16 +1776,159 @@ func (p *Parser) parsePostfix() (Expr, error) {
 			return &PercentOfExpr{Percent: expr, Of: of}, nil
 		}
 
-		expr = &PercentExpr{Value: expr}
+		if unit == "" {
+			expr = &PercentExpr{Value: expr}
+			p.markSpan(start, &expr)
+		}
+
+		// "20% off £80" and "15% tip on £42.50"
+		switch {
+		case p.atWord("off"):
+			p.advance()
+			base, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			return &PercentChangeExpr{Base: base, Percent: expr, Increase: false}, nil
+		case p.atWord("tip") && p.peek(1).Type == lexer.TokenIdent && strings.EqualFold(p.peek(1).Literal, "on"):
+			p.advance() // 'tip'
+			p.advance() // 'on'
+			base, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			return &TipExpr{Percent: expr, Base: base}, nil
+		}
+	}
+
+	// "vat of £100", where vat = 20%; whether vat holds a percentage is only
+	// known when the line is evaluated
+	if ident, ok := expr.(*IdentExpr); ok && p.current().Type == lexer.TokenOf {
+		p.advance()
+		of, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		return &NamedPercentOfExpr{Percent: ident, Of: of}, nil
 	}
 
 	return expr, nil
 }
 
-func (p *Parser) parsePrimary() (Expr, error) {
+// atPercent reports whether the parser is at a percent sign or a word
+// written like one, such as bp or ‰. bps is a data rate unless "of"
+// follows, as in 50 bps of £2m, or money or a percentage is added to it,
+// which only evaluation can tell.
+func (p *Parser) atPercent() bool {
+	tok := p.current()
+	return tok.Type == lexer.TokenPercent ||
+		tok.Type == lexer.TokenUnit && tok.Literal == "bps" && p.peek(1).Type == lexer.TokenOf
+}
+
+// percentUnit returns how a percentage written with literal is shown: ""
+// for %, otherwise the word or sign in lower case.
+func percentUnit(literal string) string {
+	if literal == "%" {
+		return ""
+	}
+	return strings.ToLower(literal)
+}
+
+// parseRateDenominator reads what follows "per" in a rate: a unit, as in
+// "£5 per day", or a quantity of one, as in "£1.89 per 100g". It returns the
+// unit and, for a quantity, how many of it the rate was quoted against (0 for
+// a bare unit). An empty unit means neither follows and nothing is consumed.
+func (p *Parser) parseRateDenominator() (string, float64, error) {
+	if unit, ok := p.rateUnit(); ok {
+		p.advance()
+		return unit, 0, nil
+	}
+	switch {
+	case p.current().Type == lexer.TokenNumber && p.peek(1).Type == lexer.TokenUnit && !p.isCurrencyCode(p.peek(1).Literal):
+		num := p.current()
+		per, err := strconv.ParseFloat(p.normalizeNumber(num.Literal), 64)
+		if err != nil {
+			return "", 0, fmt.Errorf("invalid number: %s", num.Literal)
+		}
+		if per <= 0 {
+			return "", 0, fmt.Errorf("a rate must be per a positive quantity, got per %s %s", num.Literal, p.peek(1).Literal)
+		}
+		p.advance()
+		unit := p.current().Literal
+		p.advance()
+		return unit, per, nil
+	}
+	return "", 0, nil
+}
+
+// rateUnit returns the unit at the current token when it can follow "per":
+// any unit, or annum, which is a year.
+func (p *Parser) rateUnit() (string, bool) {
+	tok := p.current()
+	switch {
+	case tok.Type == lexer.TokenUnit:
+		return tok.Literal, true
+	case tok.Type == lexer.TokenIdent && strings.EqualFold(tok.Literal, "annum"):
+		return "year", true
+	}
+	return "", false
+}
+
+// atPerAnnum reports whether the parser is at "pa" after an amount of money,
+// as in £45,000 pa, where it means per annum rather than pascals.
+func (p *Parser) atPerAnnum(expr Expr) bool {
+	_, money := expr.(*CurrencyExpr)
+	tok := p.current()
+	return money && tok.Type == lexer.TokenUnit && strings.EqualFold(tok.Literal, "pa")
+}
+
+// currencyMultipliers are the shorthand suffixes accepted straight after a
+// currency amount, as in €1.2k or £3m.
+var currencyMultipliers = map[string]float64{"k": 1e3, "m": 1e6, "bn": 1e9}
+
+// currencyMultiplier consumes a k, m or bn suffix written directly against the
+// number token num and returns the factor it stands for, or 1 if there is none.
+// With a space between them, £3 m stays three pounds and a metre unit.
+func (p *Parser) currencyMultiplier(num lexer.Token) float64 {
+	tok := p.current()
+	if tok.Type != lexer.TokenUnit && tok.Type != lexer.TokenIdent {
+		return 1
+	}
+	if tok.Line != num.Line || tok.Column != num.Column+len(num.Literal) {
+		return 1
+	}
+	factor, ok := currencyMultipliers[strings.ToLower(tok.Literal)]
+	if !ok {
+		return 1
+	}
+	p.advance()
+	return factor
+}
+
+// ordinalError explains that an ordinal such as 21st is not a quantity. 1st
+// gets a hint because it used to be read as one stone.
+func ordinalError(literal string) error {
+	digits := literal[:len(literal)-2]
+	if strings.EqualFold(literal[len(digits):], "st") {
+		return fmt.Errorf("%s is an ordinal, not a quantity; did you mean %s stone? write '%s st'", literal, digits, digits)
+	}
+	return fmt.Errorf("%s is an ordinal, not a quantity; write %s for the number", literal, digits)
+}
+
+func (p *Parser) parsePrimary() (node Expr, _ error) {
+	defer p.markSpan(p.pos, &node)
+	if expr, ok, err := p.tryParseNthWeekday(); ok {
+		return expr, err
+	}
+	if expr, ok, err := p.tryParseCalendarDate(); ok {
+		return expr, err
+	}
 	tok := p.current()
 
 	switch tok.Type {
+	case lexer.TokenOrdinal:
+		return nil, ordinalError(tok.Literal)
+
 	case lexer.TokenNumber:
 		normalized := p.normalizeNumber(tok.Literal)
 		val, err := strconv.ParseFloat(normalized, 64)
@@ -930,6 +1937,11 @@ func (p *Parser) parsePrimary() (Expr, error) {
 		}
 		p.advance()
 		
+		// "2 and a half"
+		if withFraction, ok := p.tryParseAndFraction(val); ok {
+			return &NumberExpr{Value: withFraction}, nil
+		}
+
 		// Check if this number is followed by scale words (e.g., "5 million")
 		if scaledVal, ok := p.tryParseNumericWithScale(val); ok {
 			return &NumberExpr{Value: scaledVal}, nil
@@ -937,6 +1949,24 @@ func (p *Parser) parsePrimary() (Expr, error) {
 		
 		return &NumberExpr{Value: val}, nil
 
+	case lexer.TokenDuration:
+		// A duration such as 1h30m or PT2H30M is an amount in several units
+		p.advance()
+		parts := lexer.DurationParts(tok.Literal)
+		exprs := make([]Expr, len(parts))
+		for i, part := range parts {
+			n, err := strconv.ParseFloat(part.Number, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid duration: %s", tok.Literal)
+			}
+			exprs[i] = &UnitExpr{Span: tokenSpan(tok), Value: &NumberExpr{Span: tokenSpan(tok), Value: n}, Unit: part.Unit}
+		}
+		// 45m beside 1h30m is an amount in minutes like any other
+		if len(exprs) == 1 && !strings.HasPrefix(tok.Literal, "P") {
+			return exprs[0], nil
+		}
+		return &CompositeExpr{Parts: exprs, Duration: true}, nil
+
 	case lexer.TokenString:
 		// String literal
 		val := tok.Literal
@@ -951,22 +1981,29 @@ func (p *Parser) parsePrimary() (Expr, error) {
 			return nil, fmt.Errorf("expected number after currency symbol")
 		}
 
-		normalized := p.normalizeNumber(p.current().Literal)
+		num := p.current()
+		normalized := p.normalizeNumber(num.Literal)
 		val, err := strconv.ParseFloat(normalized, 64)
 		if err != nil {
-			return nil, fmt.Errorf("invalid number: %s", p.current().Literal)
+			return nil, fmt.Errorf("invalid number: %s", num.Literal)
 		}
 		p.advance()
 
+		amount := &NumberExpr{Value: val * p.currencyMultiplier(num)}
+		amount.Span = tokenSpan(num)
 		return &CurrencyExpr{
-			Value:    &NumberExpr{Value: val},
+			Value:    amount,
 			Currency: currency,
 		}, nil
 
 	case lexer.TokenIdent:
+		// "a dozen" before number words, which read it as 12 dozen ones
+		if expr, ok := p.tryQuantityWords(); ok {
+			return expr, nil
+		}
 		// Try to parse as number words first
-		if val, ok := p.tryParseNumberWords(); ok {
-			return &NumberExpr{Value: val}, nil
+		if expr, ok, err := p.tryNumberWordsExpr(); ok {
+			return expr, err
 		}
 		name := tok.Literal
 		p.advance()
@@ -995,20 +2032,20 @@ func (p *Parser) parsePrimary() (Expr, error) {
 		p.advance()
 		return &IdentExpr{Name: name}, nil
 
-	case lexer.TokenThree:
-		// Could be "three quarters" or just "three" as a number
-		if p.peek(1).Type == lexer.TokenQuarters {
-			// It's "three quarters" - handle in tryParseFuzzyPhrase
-			return nil, fmt.Errorf("unexpected token: %s", tok.Type)
-		}
-		// It's just "three" as a number word
-		if val, ok := p.tryParseNumberWords(); ok {
-			return &NumberExpr{Value: val}, nil
+	case lexer.TokenThree, lexer.TokenHalf:
+		// "three", "three quarters" or "half" as a number; "half of X" and
+		// "three quarters of X" that start a line are fuzzy phrases
+		if expr, ok, err := p.tryNumberWordsExpr(); ok {
+			return expr, err
 		}
 		return nil, fmt.Errorf("unexpected token: %s", tok.Type)
 
 	case lexer.TokenLParen:
 		p.advance()
+		// Parentheses start a fresh expression, even inside an argument list
+		argDepth := p.argDepth
+		p.argDepth = 0
+		defer func() { p.argDepth = argDepth }()
 		// Allow conversions inside parentheses
 		expr, err := p.parseConversion()
 		if err != nil {
@@ -1020,6 +2057,15 @@ func (p *Parser) parsePrimary() (Expr, error) {
 		return expr, nil
 
 	case lexer.TokenSum, lexer.TokenAverage, lexer.TokenMean, lexer.TokenTotal:
+		if tag := p.peek(1); tag.Type == lexer.TokenTag || (tag.Type == lexer.TokenOf && p.peek(2).Type == lexer.TokenTag) {
+			return p.parseTagAggregate(tok)
+		}
+		// Alone, as in total * 2, the word reads a variable of its name
+		// when one is defined, like left and remaining
+		if endsOperand(p.peek(1).Type) {
+			p.advance()
+			return &IdentExpr{Name: tok.Literal, Fallback: &FunctionCallExpr{Name: tok.Literal}}, nil
+		}
 		return p.parseFunctionCall(tok.Literal)
 
 	case lexer.TokenToday, lexer.TokenTomorrow, lexer.TokenYesterday:
@@ -1039,11 +2085,14 @@ func (p *Parser) parsePrimary() (Expr, error) {
 
 	case lexer.TokenNow:
 		p.advance()
-		return &TimeExpr{Time: time.Now()}, nil
+		return &TimeExpr{Now: true}, nil
 
 	case lexer.TokenTimeValue:
 		// Parse time in HH:MM or HH:MM:SS format
-		timeStr := tok.Literal
+		timeStr := lexer.ClockValue(tok.Literal)
+		if strings.ContainsAny(timeStr, "apmAPM") {
+			return nil, fmt.Errorf("invalid time %s: hours before am or pm run from 1 to 12", tok.Literal)
+		}
 		parts := strings.Split(timeStr, ":")
 
 		if len(parts) < 2 || len(parts) > 3 {
@@ -1123,7 +2172,7 @@ func (p *Parser) parsePrimary() (Expr, error) {
 			return nil, fmt.Errorf("invalid date: %s (day/month/year out of range)", dateStr)
 		}
 
-		return &DateExpr{Date: parsedDate}, nil
+		return p.parseAtClock(&DateExpr{Date: parsedDate})
 
 	case lexer.TokenPrev:
 		// Parse prev, prev~, prev~1, prev~5, prev#15, etc.
@@ -1193,6 +2242,33 @@ func (p *Parser) parsePrimary() (Expr, error) {
 	}
 }
 
+// endsOperand reports whether a token of type t can follow a complete
+// operand, so that a word before it has no arguments.
+func endsOperand(t lexer.TokenType) bool {
+	switch t {
+	case lexer.TokenEOF, lexer.TokenRParen, lexer.TokenComma, lexer.TokenPlus, lexer.TokenMinus,
+		lexer.TokenMultiply, lexer.TokenDivide, lexer.TokenIn, lexer.TokenTo:
+		return true
+	}
+	return false
+}
+
+// parseTagAggregate parses "sum #food", "total of #food" or "average #food",
+// which aggregate every line tagged #food. mean is average and total is sum.
+func (p *Parser) parseTagAggregate(fn lexer.Token) (Expr, error) {
+	p.advance() // skip sum, total, average or mean
+	if p.current().Type == lexer.TokenOf {
+		p.advance()
+	}
+	tag := p.current()
+	p.advance()
+	name := "sum"
+	if fn.Type == lexer.TokenAverage || fn.Type == lexer.TokenMean {
+		name = "average"
+	}
+	return &TagExpr{Func: name, Tag: lexer.TagName(tag.Literal)}, nil
+}
+
 func (p *Parser) parseFunctionCall(name string) (Expr, error) {
 	p.advance() // skip function name if not already done
 
@@ -1204,6 +2280,9 @@ func (p *Parser) parseFunctionCall(name string) (Expr, error) {
 
 	var args []Expr
 
+	p.argDepth++
+	defer func() { p.argDepth-- }()
+
 	for p.current().Type != lexer.TokenRParen && p.current().Type != lexer.TokenEOF {
 		// Allow conversions within function arguments
 		arg, err := p.parseConversion()
@@ -1212,7 +2291,9 @@ func (p *Parser) parseFunctionCall(name string) (Expr, error) {
 		}
 		args = append(args, arg)
 
-		if p.current().Type == lexer.TokenComma {
+		// "and" separates arguments like a comma: "sum of 1, 2 and 3"
+		if p.current().Type == lexer.TokenComma ||
+			(p.current().Type == lexer.TokenIdent && p.current().Literal == "and") {
 			p.advance()
 		} else {
 			break
@@ -1255,20 +2336,19 @@ func (p *Parser) parseDateKeyword() (Expr, error) {
 	tok := p.current()
 	p.advance()
 
-	var base time.Time
+	// Resolved against the clock at evaluation time
+	expr := &DateExpr{Relative: true}
 	switch tok.Type {
-	case lexer.TokenToday:
-		base = time.Now()
 	case lexer.TokenTomorrow:
-		base = time.Now().AddDate(0, 0, 1)
+		expr.DayOffset = 1
 	case lexer.TokenYesterday:
-		base = time.Now().AddDate(0, 0, -1)
+		expr.DayOffset = -1
 	}
 
-	// Normalise to start of day
-	base = time.Date(base.Year(), base.Month(), base.Day(), 0, 0, 0, 0, base.Location())
-
-	expr := &DateExpr{Date: base}
+	base, err := p.parseAtClock(expr)
+	if err != nil {
+		return nil, err
+	}
 
 	// Check for date arithmetic
 	if p.current().Type == lexer.TokenPlus || p.current().Type == lexer.TokenMinus {
@@ -1279,6 +2359,10 @@ func (p *Parser) parseDateKeyword() (Expr, error) {
 		if err != nil {
 			return nil, err
 		}
+		// A duration such as P1D or 1h30m carries its own units
+		if c, ok := offset.(*CompositeExpr); ok && c.Duration {
+			return &BinaryExpr{Left: base, Operator: op, Right: offset}, nil
+		}
 
 		unit := ""
 		if p.current().Type == lexer.TokenUnit || p.current().Type == lexer.TokenIdent {
@@ -1287,14 +2371,146 @@ func (p *Parser) parseDateKeyword() (Expr, error) {
 		}
 
 		return &DateArithmeticExpr{
-			Base:     expr,
+			Base:     base,
 			Operator: op,
 			Offset:   offset,
 			Unit:     unit,
 		}, nil
 	}
 
-	return expr, nil
+	return base, nil
+}
+
+// atClock reports whether the next tokens are "at" followed by a clock time.
+func (p *Parser) atClock() bool {
+	return p.current().Type == lexer.TokenIdent && strings.EqualFold(p.current().Literal, "at") &&
+		p.peek(1).Type == lexer.TokenTimeValue
+}
+
+// parseAtClock handles an optional "at <clock>" after a date, as in
+// "tomorrow at noon" or "25/12/2025 at 18:30".
+func (p *Parser) parseAtClock(date Expr) (Expr, error) {
+	if !p.atClock() {
+		return date, nil
+	}
+	p.advance() // skip 'at'
+	clock, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	return &DateAtTimeExpr{Date: date, Time: clock}, nil
+}
+
+// relativeDateUnits are the units accepted in relative date phrases; they match
+// the units DateArithmeticExpr knows how to apply.
+var relativeDateUnits = map[string]bool{
+	"day": true, "days": true, "week": true, "weeks": true,
+	"month": true, "months": true, "year": true, "years": true,
+	"hour": true, "hours": true, "h": true, "hr": true, "hrs": true,
+	"minute": true, "minutes": true, "min": true, "mins": true,
+	"second": true, "seconds": true, "s": true, "sec": true, "secs": true,
+}
+
+// tryParseRelativeDate parses durations anchored at a date: "3 weeks from today",
+// "2 days ago", "4 days after 25/12/2025", "a week before next friday" and
+// "in 3 weeks". "in" only starts a date phrase when a bare duration fills the
+// rest of the line, so it keeps its meaning as the conversion keyword elsewhere.
+func (p *Parser) tryParseRelativeDate() (Expr, bool) {
+	start := p.pos
+	leadingIn := p.current().Type == lexer.TokenIn
+	if leadingIn {
+		p.advance()
+	}
+
+	amount, unit, ok := p.parseRelativeDuration()
+	if !ok {
+		p.pos = start
+		return nil, false
+	}
+
+	if leadingIn {
+		if p.current().Type != lexer.TokenEOF {
+			p.pos = start
+			return nil, false
+		}
+		return &DateArithmeticExpr{Base: relativeDateAnchor(unit), Operator: "+", Offset: amount, Unit: unit}, true
+	}
+
+	switch p.current().Type {
+	case lexer.TokenAgo:
+		p.advance()
+		return &DateArithmeticExpr{Base: relativeDateAnchor(unit), Operator: "-", Offset: amount, Unit: unit}, true
+
+	case lexer.TokenFrom, lexer.TokenAfter, lexer.TokenBefore:
+		op := "+"
+		if p.current().Type == lexer.TokenBefore {
+			op = "-"
+		}
+		p.advance()
+
+		var base Expr
+		if p.current().Type == lexer.TokenNow {
+			// "from now" counts from today, or from this moment for sub-day units
+			p.advance()
+			base = relativeDateAnchor(unit)
+		} else {
+			var err error
+			if base, err = p.parseAdditive(); err != nil {
+				p.pos = start
+				return nil, false
+			}
+		}
+		return &DateArithmeticExpr{Base: base, Operator: op, Offset: amount, Unit: unit}, true
+	}
+
+	p.pos = start
+	return nil, false
+}
+
+// parseRelativeDuration reads the "3 weeks" part of a relative date phrase.
+// The amount may be a number, number words, or "a"/"an" meaning one.
+func (p *Parser) parseRelativeDuration() (Expr, string, bool) {
+	var amount float64
+	tok := p.current()
+	switch {
+	case tok.Type == lexer.TokenNumber:
+		val, err := strconv.ParseFloat(p.normalizeNumber(tok.Literal), 64)
+		if err != nil {
+			return nil, "", false
+		}
+		amount = val
+		p.advance()
+	case tok.Type == lexer.TokenIdent && (strings.EqualFold(tok.Literal, "a") || strings.EqualFold(tok.Literal, "an")):
+		amount = 1
+		p.advance()
+	default:
+		val, ok := p.tryParseNumberWords()
+		if !ok {
+			return nil, "", false
+		}
+		amount = val
+	}
+
+	unitTok := p.current()
+	if unitTok.Type != lexer.TokenUnit && unitTok.Type != lexer.TokenIdent {
+		return nil, "", false
+	}
+	unit := strings.ToLower(unitTok.Literal)
+	if !relativeDateUnits[unit] {
+		return nil, "", false
+	}
+	p.advance()
+	return &NumberExpr{Value: amount}, unit, true
+}
+
+// relativeDateAnchor is the default starting point for "ago", "in" and "from now":
+// the current moment for hours, minutes and seconds, otherwise the start of today.
+func relativeDateAnchor(unit string) Expr {
+	switch unit {
+	case "hour", "hours", "h", "hr", "hrs", "minute", "minutes", "min", "mins", "second", "seconds", "s", "sec", "secs":
+		return &TimeExpr{Now: true}
+	}
+	return &DateExpr{Relative: true}
 }
 
 func (p *Parser) parseWeekday() (Expr, error) {
@@ -1308,35 +2524,192 @@ func (p *Parser) parseWeekday() (Expr, error) {
 
 	// Get the weekday
 	tok := p.current()
-	var weekday time.Weekday
-
-	switch tok.Type {
-	case lexer.TokenMonday:
-		weekday = time.Monday
-	case lexer.TokenTuesday:
-		weekday = time.Tuesday
-	case lexer.TokenWednesday:
-		weekday = time.Wednesday
-	case lexer.TokenThursday:
-		weekday = time.Thursday
-	case lexer.TokenFriday:
-		weekday = time.Friday
-	case lexer.TokenSaturday:
-		weekday = time.Saturday
-	case lexer.TokenSunday:
-		weekday = time.Sunday
-	default:
+	weekday, ok := weekdayTokens[tok.Type]
+	if !ok {
 		return nil, fmt.Errorf("expected weekday, got %s", tok.Type)
 	}
-
 	p.advance()
 
+	// "last friday of march"
+	if modifier == "last" && p.current().Type == lexer.TokenOf {
+		return p.parseWeekdayOfMonth(-1, weekday)
+	}
+
+	// "friday after 25/12/2025" is the next friday from that date, and
+	// "friday before 25/12/2025" the last
+	if modifier == "" && (p.current().Type == lexer.TokenAfter || p.current().Type == lexer.TokenBefore) {
+		modifier = "next"
+		if p.current().Type == lexer.TokenBefore {
+			modifier = "last"
+		}
+		p.advance()
+		from, err := p.parsePostfix()
+		if err != nil {
+			return nil, err
+		}
+		return &WeekdayExpr{Weekday: weekday, Modifier: modifier, From: from}, nil
+	}
+
 	return &WeekdayExpr{
 		Weekday:  weekday,
 		Modifier: modifier,
 	}, nil
 }
 
+// weekdayTokens maps the weekday keywords to their days.
+var weekdayTokens = map[lexer.TokenType]time.Weekday{
+	lexer.TokenMonday:    time.Monday,
+	lexer.TokenTuesday:   time.Tuesday,
+	lexer.TokenWednesday: time.Wednesday,
+	lexer.TokenThursday:  time.Thursday,
+	lexer.TokenFriday:    time.Friday,
+	lexer.TokenSaturday:  time.Saturday,
+	lexer.TokenSunday:    time.Sunday,
+}
+
+// monthTokens maps the month keywords to their months.
+var monthTokens = map[lexer.TokenType]time.Month{
+	lexer.TokenJanuary:   time.January,
+	lexer.TokenFebruary:  time.February,
+	lexer.TokenMarch:     time.March,
+	lexer.TokenApril:     time.April,
+	lexer.TokenMay:       time.May,
+	lexer.TokenJune:      time.June,
+	lexer.TokenJuly:      time.July,
+	lexer.TokenAugust:    time.August,
+	lexer.TokenSeptember: time.September,
+	lexer.TokenOctober:   time.October,
+	lexer.TokenNovember:  time.November,
+	lexer.TokenDecember:  time.December,
+}
+
+// weekdayOrdinals are the words that pick a weekday of a month, as in
+// "first monday of march". "last" is a keyword, handled by parseWeekday.
+var weekdayOrdinals = map[string]int{"first": 1, "second": 2, "third": 3, "fourth": 4}
+
+// tryParseNthWeekday parses "first monday of march" and the like, reporting
+// false, consuming nothing, when the line does not start with an ordinal
+// word and a weekday.
+func (p *Parser) tryParseNthWeekday() (Expr, bool, error) {
+	n, ok := weekdayOrdinals[strings.ToLower(p.current().Literal)]
+	if !ok {
+		return nil, false, nil
+	}
+	weekday, ok := weekdayTokens[p.peek(1).Type]
+	if !ok {
+		return nil, false, nil
+	}
+	p.advance()
+	p.advance()
+	expr, err := p.parseWeekdayOfMonth(n, weekday)
+	return expr, true, err
+}
+
+// monthAbbreviations are the short month names read in dates such as
+// "25 dec". Unlike the full names they are not keywords, so they remain
+// usable as variable names, and a variable of the same name wins.
+var monthAbbreviations = map[string]time.Month{
+	"jan": time.January, "feb": time.February, "mar": time.March, "apr": time.April,
+	"jun": time.June, "jul": time.July, "aug": time.August, "sep": time.September,
+	"sept": time.September, "oct": time.October, "nov": time.November, "dec": time.December,
+}
+
+// monthAt reports the month named by the token offset places ahead, either a
+// month keyword or an abbreviation such as dec.
+func (p *Parser) monthAt(offset int) (time.Month, bool) {
+	tok := p.peek(offset)
+	if month, ok := monthTokens[tok.Type]; ok {
+		return month, true
+	}
+	if tok.Type != lexer.TokenIdent || p.isVariable != nil && p.isVariable(tok.Literal) {
+		return 0, false
+	}
+	month, ok := monthAbbreviations[strings.ToLower(tok.Literal)]
+	return month, ok
+}
+
+// dayAt reports the day of the month written by the token offset places
+// ahead: a whole number or an ordinal such as 25th, from 1 to 31.
+func (p *Parser) dayAt(offset int) (int, bool) {
+	tok := p.peek(offset)
+	digits := tok.Literal
+	switch tok.Type {
+	case lexer.TokenOrdinal:
+		digits = digits[:len(digits)-2]
+	case lexer.TokenNumber:
+	default:
+		return 0, false
+	}
+	if len(digits) > 2 || strings.Trim(digits, "0123456789") != "" {
+		return 0, false
+	}
+	day, err := strconv.Atoi(digits)
+	if err != nil || day < 1 || day > 31 {
+		return 0, false
+	}
+	return day, true
+}
+
+// tryParseCalendarDate parses a day and month written out, with the day
+// first as in "25th december", "25 dec" and "3rd of june", or the month first
+// as in "december 25th" and "dec 25", then an optional year. It reports
+// false, consuming nothing, when the line does not start with one, so a
+// month on its own keeps meaning its number of days.
+func (p *Parser) tryParseCalendarDate() (Expr, bool, error) {
+	expr := &CalendarDateExpr{}
+	if day, ok := p.dayAt(0); ok {
+		of := 0
+		if p.current().Type == lexer.TokenOrdinal && p.peek(1).Type == lexer.TokenOf {
+			of = 1
+		}
+		month, ok := p.monthAt(1 + of)
+		if !ok {
+			return nil, false, nil
+		}
+		expr.Day, expr.Month = day, month
+		p.pos += 2 + of
+	} else if month, ok := p.monthAt(0); ok {
+		day, ok := p.dayAt(1)
+		if !ok {
+			return nil, false, nil
+		}
+		expr.Day, expr.Month = day, month
+		p.pos += 2
+	} else {
+		return nil, false, nil
+	}
+
+	if tok := p.current(); tok.Type == lexer.TokenNumber && len(tok.Literal) == 4 {
+		if year, err := strconv.Atoi(tok.Literal); err == nil {
+			expr.Year = year
+			p.advance()
+		}
+	}
+	result, err := p.parseAtClock(expr)
+	return result, true, err
+}
+
+// parseWeekdayOfMonth reads the "of march" or "of march 2026" that follows
+// "first monday" or "last friday".
+func (p *Parser) parseWeekdayOfMonth(n int, weekday time.Weekday) (Expr, error) {
+	if _, err := p.expect(lexer.TokenOf); err != nil {
+		return nil, fmt.Errorf("expected \"of\" and a month after %s", strings.ToLower(weekday.String()))
+	}
+	month, ok := monthTokens[p.current().Type]
+	if !ok {
+		return nil, fmt.Errorf("expected a month after \"%s of\", got %q", strings.ToLower(weekday.String()), p.current().Literal)
+	}
+	p.advance()
+	expr := &NthWeekdayExpr{N: n, Weekday: weekday, Month: month}
+	if tok := p.current(); tok.Type == lexer.TokenNumber {
+		if year, err := strconv.Atoi(tok.Literal); err == nil && len(tok.Literal) == 4 {
+			expr.Year = year
+			p.advance()
+		}
+	}
+	return expr, nil
+}
+
 func (p *Parser) parseMonth() (Expr, error) {
 	tok := p.current()
 	var monthName string
@@ -1392,10 +2765,12 @@ func (p *Parser) tryParseNumberWords() (float64, bool) {
 		switch tok.Type {
 		case lexer.TokenIdent:
 			word = tok.Literal
-		case lexer.TokenThree:
-			// Special case: "three" is a keyword but also a number word
-			// Check if next token is "quarters" - if so, don't treat as number
-			if p.peek(1).Type == lexer.TokenQuarters {
+		case lexer.TokenThree, lexer.TokenHalf, lexer.TokenQuarters:
+			// Keywords that are also number words, as in "three quarters"
+			// and "one and a half"
+			word = tok.Literal
+		case lexer.TokenUnit:
+			if !p.fractionUnitAt(words) {
 				goto done
 			}
 			word = tok.Literal
@@ -1418,15 +2793,17 @@ done:
 		return 0, false
 	}
 
-	// Try to parse the collected words as a number
-	val, ok := lexer.ParseNumberWords(words, "en_GB")
-	if !ok {
-		// Restore position if parsing failed
-		p.pos = startPos
-		return 0, false
+	// Take the longest run of the collected words that reads as a number, so
+	// "two point" is still two
+	for n := len(words); n > 0; n-- {
+		if val, ok := lexer.ParseNumberWords(words[:n], "en_GB"); ok {
+			p.pos = startPos + n
+			return val, true
+		}
 	}
-
-	return val, true
+	// Restore position if parsing failed
+	p.pos = startPos
+	return 0, false
 }
 
 // tryParseNumericWithScale attempts to parse a numeric literal followed by scale words
@@ -0,0 +1,124 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrewneudegg/calc/pkg/lexer"
+)
+
+// parseWithVariables parses input knowing which variables are defined, as
+// the REPL does.
+func parseWithVariables(input string, vars ...string) (Expr, error) {
+	p := New(lexer.New(input).AllTokens())
+	p.SetVariableChecker(func(name string) bool {
+		for _, v := range vars {
+			if strings.EqualFold(v, name) {
+				return true
+			}
+		}
+		return false
+	})
+	return p.Parse()
+}
+
+func TestAndInNumberWords(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"one hundred and five", "105"},
+		{"two thousand and twenty", "2020"},
+		{"one hundred and five + 1", "(+ 105 1)"},
+		{"sum(one hundred and five, 2)", "(sum 105 2)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			expr, err := parseWithVariables(tt.input)
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+			if got := SExpr(expr); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAndAddsValuesOfTheSameKind(t *testing.T) {
+	tests := []struct {
+		input string
+		vars  []string
+		want  string
+	}{
+		{"2 and 3", nil, "(+ 2 3)"},
+		{"5 m and 3 cm", nil, "(+ (unit 5 m) (unit 3 cm))"},
+		{"1 hour and 30 minutes and 10 seconds", nil, "(+ (+ (unit 1 hour) (unit 30 minutes)) (unit 10 seconds))"},
+		{"£5 and £3", nil, "(+ (currency 5 £) (currency 3 £))"},
+		{"rent and bills", []string{"rent", "bills"}, "(+ rent bills)"},
+		{"rent and 50", []string{"rent"}, "(+ rent 50)"},
+		{"(1 and 2) * 3", nil, "(* (+ 1 2) 3)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			expr, err := parseWithVariables(tt.input, tt.vars...)
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+			if got := SExpr(expr); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAndSuggestsPlusOtherwise(t *testing.T) {
+	tests := []struct {
+		input string
+		vars  []string
+		want  string
+	}{
+		{"rent and bills", nil, "undefined variable rent; did you mean '+'?"},
+		{"rent and bills", []string{"rent"}, "undefined variable bills; did you mean '+'?"},
+		{"£5 and 3 kg", nil, "between a currency and a unit; did you mean '+'?"},
+		{"10% and 3 m", nil, "between a percentage and a unit; did you mean '+'?"},
+		{`"a" and 2`, nil, "did you mean '+'?"},
+		{"100 and three", nil, "cannot mix numeric literals with number words"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := parseWithVariables(tt.input, tt.vars...)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("got error %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAndSeparatesFunctionArguments(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"sum of 1, 2 and 3", "(sum 1 2 3)"},
+		{"average of 1, 2 and 3", "(average 1 2 3)"},
+		{"sum(1 and 2, 3)", "(sum 1 2 3)"},
+		{"sum(1 m and 2 m)", "(sum (unit 1 m) (unit 2 m))"},
+		{"sum((1 and 2), 3)", "(sum (+ 1 2) 3)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			expr, err := parseWithVariables(tt.input)
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+			if got := SExpr(expr); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package parser
+
+import "testing"
+
+func TestParseApprox(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"about 37 * 42", "(about (* 37 42))"},
+		{"roughly 1234 km in miles", "(about (in (unit 1234 km) miles))"},
+		{"About £1274.50", "(about (currency 1274.5 £))"},
+		{"x = about 37 * 42", "(= x (about (* 37 42)))"},
+		// Alone or before an operator, the word is a variable
+		{"about", "about"},
+		{"about * 2", "(* about 2)"},
+		{"about = 5", "(= about 5)"},
+	}
+	for _, tt := range tests {
+		expr, err := parseInput(tt.input)
+		if err != nil {
+			t.Errorf("%q: %v", tt.input, err)
+			continue
+		}
+		if got := SExpr(expr); got != tt.want {
+			t.Errorf("%q: got %s, want %s", tt.input, got, tt.want)
+		}
+	}
+}
@@ -81,3 +81,53 @@ func TestParseArgDirective(t *testing.T) {
 		})
 	}
 }
+
+func TestParseArgDirectiveClauses(t *testing.T) {
+	tests := []struct {
+		input      string
+		wantType   string
+		wantUnit   string
+		hasDefault bool
+		hasMin     bool
+		hasMax     bool
+		wantErr    bool
+	}{
+		{input: `:arg budget "Monthly budget" currency default £500`, wantType: "currency", hasDefault: true},
+		{input: `:arg count "How many" number min 1 max 100`, wantType: "number", hasMin: true, hasMax: true},
+		{input: `:arg start "Start date" date`, wantType: "date"},
+		{input: `:arg length unit max 2 km default 1 km`, wantType: "unit", hasDefault: true, hasMax: true},
+		{input: `:arg distance "Enter distance" unit km`, wantType: "unit", wantUnit: "km"},
+		{input: `:arg distance "Enter distance" unit length default 5 km`, wantType: "unit", wantUnit: "length", hasDefault: true},
+		{input: `:arg count "How many" min 1`, hasMin: true},
+		{input: `:arg count "How many" number min`, wantErr: true},
+		{input: `:arg count "How many" number between 1`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			expr, err := parseInput(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %#v", expr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			arg, ok := expr.(*ArgDirectiveExpr)
+			if !ok {
+				t.Fatalf("expected *ArgDirectiveExpr, got %T", expr)
+			}
+			if arg.Type != tt.wantType {
+				t.Errorf("Type = %q, want %q", arg.Type, tt.wantType)
+			}
+			if arg.Unit != tt.wantUnit {
+				t.Errorf("Unit = %q, want %q", arg.Unit, tt.wantUnit)
+			}
+			if (arg.Default != nil) != tt.hasDefault || (arg.Min != nil) != tt.hasMin || (arg.Max != nil) != tt.hasMax {
+				t.Errorf("clauses = default:%v min:%v max:%v", arg.Default != nil, arg.Min != nil, arg.Max != nil)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/andrewneudegg/calc/pkg/lexer"
+)
+
+func TestParseCalendarDates(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"25th december", "(date 25 December)"},
+		{"25 december 2025", "(date 25 December 2025)"},
+		{"december 25th 2025", "(date 25 December 2025)"},
+		{"25 dec", "(date 25 December)"},
+		{"Dec 25", "(date 25 December)"},
+		{"sept 1", "(date 1 September)"},
+		{"3rd of june", "(date 3 June)"},
+		{"1 may", "(date 1 May)"},
+		{"25th december - today", "(- (date 25 December) (date today+0))"},
+		{"dec 25 at 10:00", "(at (date 25 December) (unit 10 time))"},
+		// A month alone, or with a year, is still its number of days
+		{"december", "(month December)"},
+		{"december 2025", "(month December)"},
+		{"2 * december", "(* 2 (month December))"},
+	}
+	for _, tt := range tests {
+		expr, err := parseInput(tt.input)
+		if err != nil {
+			t.Fatalf("parse %q: %v", tt.input, err)
+		}
+		if got := SExpr(expr); got != tt.want {
+			t.Errorf("SExpr(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+
+	if _, err := parseInput("3rd quarter"); err == nil {
+		t.Error("an ordinal without a month should still be an error")
+	}
+}
+
+func TestMonthAbbreviationYieldsToVariable(t *testing.T) {
+	p := New(lexer.New("dec 25").AllTokens())
+	p.SetVariableChecker(func(name string) bool { return name == "dec" })
+	expr, err := p.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := SExpr(expr); got != "dec" {
+		t.Errorf("dec 25 with a variable dec = %s, want the variable", got)
+	}
+}
@@ -0,0 +1,57 @@
+package parser
+
+import "testing"
+
+func TestComparisonQuestions(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"is 5 km more than 3 miles", "(is-more (unit 5 km) (unit 3 miles))"},
+		{"is £20 less than $30", "(is-less (currency 20 £) (currency 30 $))"},
+		{"is 5 km more than 3 miles?", "(is-more (unit 5 km) (unit 3 miles))"},
+		{"Is 2 + 3 bigger than 4", "(is-more (+ 2 3) 4)"},
+		{"is 1 kg lighter than 3 lb", "(is-less (unit 1 kg) (unit 3 lb))"},
+		{"which is bigger: 1 gb or 900 mb", "(which-more (unit 1 gb) (unit 900 mb))"},
+		{"which is smaller, 1 gb or 900 mb", "(which-less (unit 1 gb) (unit 900 mb))"},
+		{"which is more £5 or $6", "(which-more (currency 5 £) (currency 6 $))"},
+		{"which is bigger: 1 gb or 900 mb in mb", "(in (which-more (unit 1 gb) (unit 900 mb)) mb)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			expr, err := parseInput(tt.input)
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+			if got := SExpr(expr); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComparisonWordsStayVariables(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"which = 3", "(= which 3)"},
+		{"more = 2", "(= more 2)"},
+		{"which * more", "(* which more)"},
+		{"is - less", "(- is less)"},
+		{"than + bigger", "(+ than bigger)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			expr, err := parseInput(tt.input)
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+			if got := SExpr(expr); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
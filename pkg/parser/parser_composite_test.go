@@ -0,0 +1,44 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/andrewneudegg/calc/pkg/lexer"
+)
+
+func TestParseComposite(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"5 ft 10 in in cm", "(in (composite (unit 5 ft) (unit 10 in)) cm)"},
+		{"6 pounds 4 ounces in grams", "(in (composite (unit 6 pounds) (unit 4 ounces)) grams)"},
+		{"1 hour 30 minutes in seconds", "(in (composite (unit 1 hour) (unit 30 minutes)) seconds)"},
+		{"2 minutes 30 seconds", "(composite (unit 2 minutes) (unit 30 seconds))"},
+		{"1 h 30 min 15 s", "(composite (unit 1 h) (unit 30 min) (unit 15 s))"},
+		{"5 ft 10 in + 2 in", "(+ (composite (unit 5 ft) (unit 10 in)) (unit 2 in))"},
+		// Checked when evaluated, so the error can name the units
+		{"5 kg 3 m", "(composite (unit 5 kg) (unit 3 m))"},
+	}
+	for _, tt := range tests {
+		expr, err := parseInput(tt.input)
+		if err != nil {
+			t.Errorf("%q: %v", tt.input, err)
+			continue
+		}
+		if got := SExpr(expr); got != tt.want {
+			t.Errorf("%q: got %s, want %s", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseTreeComposite(t *testing.T) {
+	tree := New(lexer.New("5 ft 10 in").AllTokens()).ParseTree()
+	parts, ok := tree.AST.Children["parts"].([]*TreeNode)
+	if tree.AST.Type != "composite" || !ok || len(parts) != 2 || parts[1].Literals["unit"] != "in" {
+		t.Errorf("got %s with %#v", tree.AST.Type, tree.AST.Children)
+	}
+	if end := tree.AST.Span.End.Column; end != 11 {
+		t.Errorf("span ends at column %d, want 11", end)
+	}
+}
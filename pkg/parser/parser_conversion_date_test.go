@@ -0,0 +1,53 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConversionOnDate(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"£100 in usd on 15/03/2024", "(in (currency 100 £) usd (on (date 2024-03-15)))"},
+		{"£100 in $ on d", "(in (currency 100 £) $ (on d))"},
+		{"x to eur on today", "(in x eur (on (date today+0)))"},
+		{"(£100 in usd on d) * 2", "(* (in (currency 100 £) usd (on d)) 2)"},
+		{"on = 3", "(= on 3)"},
+		{"on * 2", "(* on 2)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			expr, err := parseInput(tt.input)
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+			if got := SExpr(expr); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConversionOnDateErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"5 km in miles on 15/03/2024", "on <date> only applies to currency conversions, not to miles"},
+		{"x in hours on d", "on <date> only applies to currency conversions"},
+		{"£100 in usd, eur on d", "needs a single currency"},
+		{"£100 in usd on", "on <date>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := parseInput(tt.input)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package parser
+
+import "testing"
+
+func TestConversionAsOperand(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"(3 kg in lb) * 5", "((3 kg in lb) * 5)"},
+		{"5 * (3 kg in lb)", "(5 * (3 kg in lb))"},
+		{"(3 kg in lb) / 2", "((3 kg in lb) / 2)"},
+		{"(3 kg in lb) + 1 lb", "((3 kg in lb) + 1 lb)"},
+		{"(3 kg in lb) - 1 lb", "((3 kg in lb) - 1 lb)"},
+		{"-(1 km in m)", "-(1 km in m)"},
+		{"10% of (1 km in m)", "(10 of (1 km in m))"},
+		{"(1 km in m) * 2 + 1", "(((1 km in m) * 2) + 1)"},
+		{"1 + (1 km in m) * 2", "(1 + ((1 km in m) * 2))"},
+		{"((1 km in m) * 2) + 1", "(((1 km in m) * 2) + 1)"},
+		{"(3 kg in lb) * 5 in kg", "(((3 kg in lb) * 5) in kg)"},
+		// Without parentheses the conversion still binds loosest, then the line continues
+		{"3 kg in lb * 2", "((3 kg in lb) * 2)"},
+		{"3 kg in lb / 2 + 1 lb", "(((3 kg in lb) / 2) + 1 lb)"},
+		{"3 kg in lb * 2 in kg", "(((3 kg in lb) * 2) in kg)"},
+		{"3 kg in lb minus 1 lb", "((3 kg in lb) - 1 lb)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			expr, err := parseInput(tt.input)
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+			if got := render(expr); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/andrewneudegg/calc/pkg/lexer"
+)
+
+func TestCurrencyPlacement(t *testing.T) {
+	tests := []struct {
+		locale   string
+		input    string
+		currency string
+		value    float64
+	}{
+		{"en_GB", "£12", "£", 12},
+		{"en_GB", "€45.50", "€", 45.50},
+		{"en_GB", "100€", "€", 100},
+		{"en_GB", "45.50 €", "€", 45.50},
+		{"en_GB", "1,250 £", "£", 1250},
+		{"en_GB", "20$", "$", 20},
+		{"en_GB", "€1.2k", "€", 1200},
+		{"en_GB", "£3m", "£", 3e6},
+		{"en_GB", "$2bn", "$", 2e9},
+		{"en_GB", "$5M", "$", 5e6},
+		{"de_DE", "€12", "€", 12},
+		{"de_DE", "100€", "€", 100},
+		{"de_DE", "45,50 €", "€", 45.50},
+		{"de_DE", "1.250,75€", "€", 1250.75},
+		{"de_DE", "€1,2k", "€", 1200},
+		{"de_DE", "£3m", "£", 3e6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.locale+" "+tt.input, func(t *testing.T) {
+			expr, err := NewWithLocale(lexer.New(tt.input).AllTokens(), tt.locale).Parse()
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			cur, ok := expr.(*CurrencyExpr)
+			if !ok {
+				t.Fatalf("Expected *CurrencyExpr, got %T", expr)
+			}
+			num, ok := cur.Value.(*NumberExpr)
+			if !ok {
+				t.Fatalf("Expected *NumberExpr amount, got %T", cur.Value)
+			}
+			if cur.Currency != tt.currency || num.Value != tt.value {
+				t.Errorf("got %s%v, want %s%v", cur.Currency, num.Value, tt.currency, tt.value)
+			}
+		})
+	}
+}
+
+func TestCurrencySuffixNeedsNoSpace(t *testing.T) {
+	// With a space, m is the metre unit rather than a million
+	expr, err := New(lexer.New("£3 m").AllTokens()).Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if _, ok := expr.(*CurrencyExpr); ok {
+		t.Errorf("£3 m should not read as three million pounds")
+	}
+}
+
+func TestPostfixCurrencyInExpression(t *testing.T) {
+	expr, err := New(lexer.New("100€ + €5").AllTokens()).Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	bin, ok := expr.(*BinaryExpr)
+	if !ok {
+		t.Fatalf("Expected *BinaryExpr, got %T", expr)
+	}
+	if _, ok := bin.Left.(*CurrencyExpr); !ok {
+		t.Errorf("left operand: expected *CurrencyExpr, got %T", bin.Left)
+	}
+	if _, ok := bin.Right.(*CurrencyExpr); !ok {
+		t.Errorf("right operand: expected *CurrencyExpr, got %T", bin.Right)
+	}
+}
@@ -0,0 +1,69 @@
+package parser
+
+import "testing"
+
+func TestParseDestructure(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"tip, total = 15% tip on 40", "(= (tip total) (tip (% 15) 40))"},
+		{"usd, eur = 100 in usd, eur", "(= (usd eur) (in 100 usd eur))"},
+		{"_, b, _ = x", "(= (_ b _) x)"},
+		{"a, b = half of 10", "(= (a b) (half 10))"},
+		// A single name is a plain assignment, which keeps a list whole
+		{"a = 100 in usd, eur", "(= a (in 100 usd eur))"},
+		// Commas elsewhere are left alone
+		{"sum(a, b)", "(sum a b)"},
+	}
+	for _, tt := range tests {
+		expr, err := parseInput(tt.input)
+		if err != nil {
+			t.Errorf("%q: %v", tt.input, err)
+			continue
+		}
+		if got := SExpr(expr); got != tt.want {
+			t.Errorf("%q: got %s, want %s", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseDestructureErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"a, A = x", "A is assigned twice"},
+		{"_, _ = x", "nothing to assign: every name is _"},
+	}
+	for _, tt := range tests {
+		_, err := parseInput(tt.input)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%q: error = %v, want %q", tt.input, err, tt.want)
+		}
+	}
+}
+
+func TestAssignedNames(t *testing.T) {
+	for input, want := range map[string]string{
+		"a = 1":       "a",
+		"_, b, c = x": "b c",
+		"a + 1":       "",
+		"x, _ = y":    "x",
+	} {
+		expr, err := parseInput(input)
+		if err != nil {
+			t.Fatalf("%q: %v", input, err)
+		}
+		got := ""
+		for i, name := range AssignedNames(expr) {
+			if i > 0 {
+				got += " "
+			}
+			got += name
+		}
+		if got != want {
+			t.Errorf("AssignedNames(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package parser
+
+import "testing"
+
+func TestDiscountAndTipPhrases(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"20% off £80", "(decrease (currency 80 £) (% 20))"},
+		{"£80 after 20% discount", "(decrease (currency 80 £) (% 20))"},
+		{"10% off (20% off £100)", "(decrease (decrease (currency 100 £) (% 20)) (% 10))"},
+		{"price = 20% off £80", "(= price (decrease (currency 80 £) (% 20)))"},
+		{"£80 after 20% discount in USD", "(in (decrease (currency 80 £) (% 20)) USD)"},
+		{"15% tip on £42.50", "(tip (% 15) (currency 42.5 £))"},
+		{"tip 15% on £42.50", "(tip (% 15) (currency 42.5 £))"},
+		{"tip 15% on bill", "(tip (% 15) bill)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			expr, err := parseInput(tt.input)
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+			if got := SExpr(expr); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiscountWordsStayVariables(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"tip = 15%", "(= tip (% 15))"},
+		{"tip * £42.50", "(* tip (currency 42.5 £))"},
+		{"discount = 20%", "(= discount (% 20))"},
+		{"off = 2", "(= off 2)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			expr, err := parseInput(tt.input)
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+			if got := SExpr(expr); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
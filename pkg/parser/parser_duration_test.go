@@ -0,0 +1,29 @@
+package parser
+
+import "testing"
+
+func TestParseDurations(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"1h30m", "(composite (unit 1 h) (unit 30 min))"},
+		{"PT2H30M in minutes", "(in (composite (unit 2 h) (unit 30 min)) minutes)"},
+		{"1h30m + 45m", "(+ (composite (unit 1 h) (unit 30 min)) (unit 45 min))"},
+		{"45m + 1h30m - 5m", "(- (+ (unit 45 min) (composite (unit 1 h) (unit 30 min))) (unit 5 min))"},
+		{"today + P1D", "(+ (date today+0) (composite (unit 1 days)))"},
+		// m is metres away from a duration, or written apart from its number
+		{"45m + 5m", "(+ (unit 45 m) (unit 5 m))"},
+		{"1h30m + 10 m", "(+ (composite (unit 1 h) (unit 30 min)) (unit 10 m))"},
+	}
+	for _, tt := range tests {
+		expr, err := parseInput(tt.input)
+		if err != nil {
+			t.Errorf("%q: %v", tt.input, err)
+			continue
+		}
+		if got := SExpr(expr); got != tt.want {
+			t.Errorf("%q: got %s, want %s", tt.input, got, tt.want)
+		}
+	}
+}
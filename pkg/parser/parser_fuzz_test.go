@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrewneudegg/calc/pkg/lexer"
+)
+
+// FuzzParse checks that no input makes the lexer or parser panic. Run it with
+// go test -fuzz=FuzzParse ./pkg/parser; inputs it has found are kept in
+// testdata/fuzz/FuzzParse and replayed by plain go test.
+func FuzzParse(f *testing.F) {
+	seeds := []string{
+		"1 + 2 * 3",
+		"x = 5 m in ft",
+		"(((1)))",
+		"20% off £80",
+		"£80 after 20% discount",
+		"tip 15% on $42",
+		"3pm in Tokyo",
+		"today + 3 weeks",
+		"1,234.56 usd in gbp",
+		"sqrt(16) + sum(1, 2, 3)",
+		":set precision 4",
+		":arg price = 10",
+		"prev + prev~2",
+		"5 m/s in mph",
+		"two hundred and fifty",
+		"1e308 * 10",
+		"\"quoted",
+		"((",
+		"))",
+		"",
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, input string) {
+		tokens := lexer.New(input).AllTokens()
+		if len(tokens) > 0 && tokens[len(tokens)-1].Type == lexer.TokenEOF {
+			tokens = tokens[:len(tokens)-1]
+		}
+		_, _ = New(tokens).Parse()
+		_, _ = NewWithLocale(tokens, "de_DE").Parse()
+	})
+}
+
+func TestParseRejectsDeepNesting(t *testing.T) {
+	deep := strings.Repeat("(", 1000) + "1" + strings.Repeat(")", 1000)
+	if _, err := parseInput(deep); err == nil || !strings.Contains(err.Error(), "too deeply nested") {
+		t.Fatalf("expected nesting error, got %v", err)
+	}
+	if _, err := parseInput(strings.Repeat("-", 1000) + "1"); err == nil {
+		t.Fatal("expected nesting error for repeated unary minus")
+	}
+
+	p := New(lexer.New(strings.Repeat("(", 50) + "1" + strings.Repeat(")", 50)).AllTokens())
+	p.SetMaxDepth(10)
+	if _, err := p.Parse(); err == nil {
+		t.Fatal("expected SetMaxDepth to lower the limit")
+	}
+
+	shallow := strings.Repeat("(", 100) + "1" + strings.Repeat(")", 100)
+	if _, err := parseInput(shallow); err != nil {
+		t.Fatalf("nesting within the limit should parse: %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+package parser
+
+import "testing"
+
+func TestFuzzyPhraseOperandErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"half of (3 +", `after "half of": unexpected token: EOF`},
+		{"three quarters of 80 *", `after "three quarters of": unexpected token: EOF`},
+		{"double (3 +", `after "double": unexpected token: EOF`},
+		{"twice 5 *", `after "twice": unexpected token: EOF`},
+		{"increase 100 by (5 +", `after "increase ... by": unexpected token: EOF`},
+		{"increase 100 by", `after "increase ... by": unexpected token: EOF`},
+		{"decrease 100 by 5 *", `after "decrease ... by": unexpected token: EOF`},
+		{"duration of (3 +", `after "duration of": unexpected token: EOF`},
+		{"tip 15% on (3 +", `after "tip ... on": unexpected token: EOF`},
+		{"10 is what % of )", `after "is what % of": unexpected token: )`},
+		{"x = half of (3 +", `after "half of": unexpected token: EOF`},
+	}
+
+	for _, tt := range tests {
+		_, err := parseInput(tt.input)
+		if err == nil {
+			t.Errorf("%q: expected an error", tt.input)
+			continue
+		}
+		if err.Error() != tt.want {
+			t.Errorf("%q: error = %q, want %q", tt.input, err, tt.want)
+		}
+	}
+}
+
+func TestFuzzyPhraseMismatchRestoresPosition(t *testing.T) {
+	// A failed increase used to leave the parser after its base, so the line
+	// was read from "by" onwards as just "5%".
+	expr, err := parseInput("increase (3 + by 5%")
+	if _, ok := expr.(*PercentExpr); ok && err == nil {
+		t.Errorf("increase (3 + by 5%%: parsed as %s", expr)
+	}
+
+	for _, input := range []string{"half", "half + 1", "three quarters", "double * 2", "increase + 1", "double = 3"} {
+		if _, err := parseInput(input); err != nil {
+			t.Errorf("%q: %v", input, err)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package parser
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/andrewneudegg/calc/pkg/lexer"
+)
+
+// render prints the shape of an expression so tests can compare groupings.
+func render(expr Expr) string {
+	switch e := expr.(type) {
+	case *NumberExpr:
+		return fmt.Sprintf("%g", e.Value)
+	case *IdentExpr:
+		return e.Name
+	case *UnitExpr:
+		return render(e.Value) + " " + e.Unit
+	case *BinaryExpr:
+		return "(" + render(e.Left) + " " + e.Operator + " " + render(e.Right) + ")"
+	case *UnaryExpr:
+		return e.Operator + render(e.Operand)
+	case *ConversionExpr:
+		return "(" + render(e.Value) + " in " + e.ToUnit + ")"
+	case *PercentExpr:
+		return render(e.Value) + "%"
+	case *PercentOfExpr:
+		return "(" + render(e.Percent) + " of " + render(e.Of) + ")"
+	}
+	return fmt.Sprintf("%T", expr)
+}
+
+func parseImplicit(input string, on bool, variables ...string) (Expr, error) {
+	l := lexer.New(input)
+	l.SetConstantChecker(func(s string) bool { return s == "c" || s == "au" || s == "pi" })
+	p := New(l.AllTokens())
+	p.SetImplicitMultiplication(on)
+	p.SetVariableChecker(func(name string) bool {
+		for _, v := range variables {
+			if v == name {
+				return true
+			}
+		}
+		return false
+	})
+	return p.Parse()
+}
+
+func TestImplicitMultiplication(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"2(3+4)", "(2 * (3 + 4))"},
+		{"(2+3)(4+5)", "((2 + 3) * (4 + 5))"},
+		{"(1+2)3", "((1 + 2) * 3)"},
+		{"2x", "(2 * x)"},
+		{"2 x + 1", "((2 * x) + 1)"},
+		{"1 + 2(3)", "(1 + (2 * 3))"},
+		{"2(3)(4)", "((2 * 3) * 4)"},
+		{"2au", "(2 * au)"},
+		{"2pi", "(2 * pi)"},
+		{"-2(3)", "(-2 * 3)"},
+		// Units after a number keep their meaning, even when a variable or constant shares the name
+		{"2m", "2 m"},
+		{"2c", "2 c"},
+		{"2 kg", "2 kg"},
+		// Undefined names and function calls are left alone
+		{"2 + y", "(2 + y)"},
+		{"sum(1)(2)", "(*parser.FunctionCallExpr * 2)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			expr, err := parseImplicit(tt.input, true, "x", "m")
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+			if got := render(expr); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImplicitMultiplicationOffByDefault(t *testing.T) {
+	for _, input := range []string{"2(3+4)", "(2+3)(4+5)", "2x", "2au", "2pi"} {
+		t.Run(input, func(t *testing.T) {
+			on, err := parseImplicit(input, true, "x")
+			if err != nil {
+				t.Fatalf("parse error with flag on: %v", err)
+			}
+			off, err := parseImplicit(input, false, "x")
+			if err == nil && render(off) == render(on) {
+				t.Errorf("expected %q to parse differently with the flag off, got %s", input, render(off))
+			}
+
+			l := lexer.New(input)
+			l.SetConstantChecker(func(s string) bool { return s == "c" || s == "au" || s == "pi" })
+			plain, plainErr := New(l.AllTokens()).Parse()
+			if (plainErr == nil) != (err == nil) || (err == nil && render(plain) != render(off)) {
+				t.Errorf("flag off should match the default parser: got %v/%v, want %v/%v", off, err, plain, plainErr)
+			}
+		})
+	}
+}
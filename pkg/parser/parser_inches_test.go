@@ -0,0 +1,48 @@
+package parser
+
+import "testing"
+
+func TestParseInchesOrConversion(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"5 ft 6 in", "(composite (unit 5 ft) (unit 6 in))"},
+		{"12 in in cm", "(in (unit 12 in) cm)"},
+		{"12 in cm", "(in 12 cm)"},
+		{"x = 3 in", "(= x (unit 3 in))"},
+		{"12 in", "(unit 12 in)"},
+		{"5 ft + 12 in", "(+ (unit 5 ft) (unit 12 in))"},
+		{"(12 in) * 2", "(* (unit 12 in) 2)"},
+		{"12 in to cm", "(in (unit 12 in) cm)"},
+		{"5 ft 6 in in cm", "(in (composite (unit 5 ft) (unit 6 in)) cm)"},
+		{"5 ft 6 in * 2", "(* (composite (unit 5 ft) (unit 6 in)) 2)"},
+		{"1 h 30 min", "(composite (unit 1 h) (unit 30 min))"},
+		{"10 m in cm", "(in (unit 10 m) cm)"},
+		{"split 900 in ratio 2:3", "(split 900 2:3)"},
+		// A bare number after feet is inches, as a height is written
+		{"5ft10", "(composite (unit 5 ft) (unit 10 in))"},
+		{"5 ft 10", "(composite (unit 5 ft) (unit 10 in))"},
+		{"5ft10 in cm", "(in (composite (unit 5 ft) (unit 10 in)) cm)"},
+		{"5ft10in in cm", "(in (composite (unit 5 ft) (unit 10 in)) cm)"},
+		{"5ft10 + 2 in", "(+ (composite (unit 5 ft) (unit 10 in)) (unit 2 in))"},
+		{"6ft2", "(composite (unit 6 ft) (unit 2 in))"},
+	}
+	for _, tt := range tests {
+		expr, err := parseInput(tt.input)
+		if err != nil {
+			t.Errorf("%q: %v", tt.input, err)
+			continue
+		}
+		if got := SExpr(expr); got != tt.want {
+			t.Errorf("%q: got %s, want %s", tt.input, got, tt.want)
+		}
+	}
+
+	// Only feet take a bare number, so one after other units is a mistake
+	for _, input := range []string{"5 km 10", "2 kg 3 + 1"} {
+		if _, err := parseInput(input); err == nil {
+			t.Errorf("%q: want an error for the stray number", input)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/andrewneudegg/calc/pkg/lexer"
+)
+
+func TestInformalQuantities(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"a dozen", "12"},
+		{"half a dozen", "(half 12)"},
+		{"2 dozen + 6", "(+ 24 6)"},
+		{"two dozen", "24"},
+		{"a couple of eggs at £0.25 each", "(* 2 (currency 0.25 £))"},
+		{"a few", "3"},
+		{"a dozen eggs at £0.25 each", "(* 12 (currency 0.25 £))"},
+		{"12 at £2 each", "(* 12 (currency 2 £))"},
+		{"a pinch", "(unit 1 pinch)"},
+		{"3 pinches of salt in grams", "(in (unit 3 pinches (of salt)) grams)"},
+		{"a hundred", "100"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			expr, err := parseInput(tt.input)
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+			if got := SExpr(expr); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInformalQuantitiesOff(t *testing.T) {
+	p := New(lexer.New("2 dozen").AllTokens())
+	p.SetInformal(false)
+	expr, err := p.Parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if got := SExpr(expr); got != "2" {
+		t.Errorf("got %s, want 2 with informal quantities off", got)
+	}
+
+	p = New(lexer.New("a few").AllTokens())
+	p.SetFew(5)
+	if expr, err = p.Parse(); err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if got := SExpr(expr); got != "5" {
+		t.Errorf("got %s, want 5 with few set to 5", got)
+	}
+}
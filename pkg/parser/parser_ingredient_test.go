@@ -0,0 +1,49 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/andrewneudegg/calc/pkg/lexer"
+)
+
+func TestIngredientAfterAmount(t *testing.T) {
+	known := map[string]bool{"flour": true, "butter": true}
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"1 cup flour", "(unit 1 cup (of flour))"},
+		{"1 cup Flour in g", "(in (unit 1 cup (of flour)) g)"},
+		{"250 g butter in cups", "(in (unit 250 g (of butter)) cups)"},
+		{"1 cup apples in g", "(in (unit 1 cup (of apples)) g)"},
+		{"1 cup apples", "(unit 1 cup)"},
+		{"5 m and 3 cm", "(+ (unit 5 m) (unit 3 cm))"},
+		{"1 cup flour + 1 cup", "(+ (unit 1 cup (of flour)) (unit 1 cup))"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			p := New(lexer.New(tt.input).AllTokens())
+			p.SetIngredientChecker(func(s string) bool { return known[s] })
+			expr, err := p.Parse()
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+			if got := SExpr(expr); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIngredientYieldsToVariable(t *testing.T) {
+	p := New(lexer.New("2 kg flour").AllTokens())
+	p.SetIngredientChecker(func(string) bool { return true })
+	p.SetVariableChecker(func(s string) bool { return s == "flour" })
+	expr, err := p.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := SExpr(expr); got != "(unit 2 kg)" {
+		t.Errorf("a variable should not be read as an ingredient, got %s", got)
+	}
+}
@@ -0,0 +1,46 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrewneudegg/calc/pkg/lexer"
+)
+
+func TestParseLocationNames(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"time in Sao Paulo", "(time-in Sao Paulo)"},
+		{"time in São Paulo", "(time-in São Paulo)"},
+		{"time in Rio de Janeiro", "(time-in Rio de Janeiro)"},
+		{"time in Port of Spain", "(time-in Port of Spain)"},
+		{"time in sao paulo to New York", "(time-convert () sao paulo New York)"},
+		{"time difference between Belo Horizonte and Hong Kong", "(time-difference Belo Horizonte Hong Kong)"},
+		// An unknown place is one word, for the evaluator to report
+		{"time in Springfield", "(time-in Springfield)"},
+	}
+	for _, tt := range tests {
+		expr, err := parseInput(tt.input)
+		if err != nil {
+			t.Errorf("%q: %v", tt.input, err)
+			continue
+		}
+		if got := SExpr(expr); got != tt.want {
+			t.Errorf("%q: got %s, want %s", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseAddedLocationNames(t *testing.T) {
+	p := New(lexer.New("time in Rio Office").AllTokens())
+	p.SetLocationChecker(func(name string) bool { return strings.EqualFold(name, "rio office") })
+	expr, err := p.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := SExpr(expr); got != "(time-in Rio Office)" {
+		t.Errorf("got %s, want (time-in Rio Office)", got)
+	}
+}
@@ -0,0 +1,50 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParser_MultiConversion(t *testing.T) {
+	tests := []struct {
+		input   string
+		targets []string
+	}{
+		{"100 kg in lb, stone", []string{"lb", "stone"}},
+		{"£100 in usd, eur, jpy", []string{"usd", "eur", "jpy"}},
+		{"60 mph in km/h, m/s", []string{"km/h", "m/s"}},
+	}
+
+	for _, tt := range tests {
+		expr, err := parseInput(tt.input)
+		if err != nil {
+			t.Errorf("input %q: unexpected error: %v", tt.input, err)
+			continue
+		}
+		multi, ok := expr.(*MultiConversionExpr)
+		if !ok {
+			t.Errorf("input %q: expected *MultiConversionExpr, got %T", tt.input, expr)
+			continue
+		}
+		if !reflect.DeepEqual(multi.ToUnits, tt.targets) {
+			t.Errorf("input %q: expected targets %v, got %v", tt.input, tt.targets, multi.ToUnits)
+		}
+	}
+}
+
+func TestParser_CommaInFunctionArgsIsNotConversionList(t *testing.T) {
+	expr, err := parseInput("sum(1 m in cm, x)")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	call, ok := expr.(*FunctionCallExpr)
+	if !ok {
+		t.Fatalf("expected *FunctionCallExpr, got %T", expr)
+	}
+	if len(call.Args) != 2 {
+		t.Fatalf("expected 2 arguments, got %d", len(call.Args))
+	}
+	if _, ok := call.Args[0].(*ConversionExpr); !ok {
+		t.Errorf("expected first argument to be *ConversionExpr, got %T", call.Args[0])
+	}
+}
@@ -0,0 +1,35 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOrdinalsAreNotQuantities(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"1st", "did you mean 1 stone? write '1 st'"},
+		{"21st + 1", "did you mean 21 stone?"},
+		{"3rd quarter", "3rd is an ordinal, not a quantity; write 3 for the number"},
+		{"x = 22nd", "22nd is an ordinal"},
+	}
+	for _, tt := range tests {
+		_, err := parseInput(tt.input)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q: error = %v, want %q", tt.input, err, tt.want)
+		}
+	}
+}
+
+func TestStonesStillParse(t *testing.T) {
+	expr, err := parseInput("1 st")
+	if err != nil {
+		t.Fatalf("1 st: %v", err)
+	}
+	unit, ok := expr.(*UnitExpr)
+	if !ok || unit.Unit != "st" {
+		t.Errorf("1 st = %#v, want a stone value", expr)
+	}
+}
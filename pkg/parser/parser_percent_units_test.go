@@ -0,0 +1,29 @@
+package parser
+
+import "testing"
+
+func TestParsePercentUnits(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"25 bp", "(bp 25)"},
+		{"3‰", "(‰ 3)"},
+		{"50 bps of 200", "(of (bps 50) 200)"},
+		{"3‰ of 1000", "(of (‰ 3) 1000)"},
+		{"20% of 50", "(of 20 50)"},
+		{"25 bps in %", "(in (unit 25 bps) %)"},
+		{"10% in bp", "(in (% 10) bp)"},
+		{"25 bps", "(unit 25 bps)"},
+	}
+	for _, tt := range tests {
+		expr, err := parseInput(tt.input)
+		if err != nil {
+			t.Errorf("%q: %v", tt.input, err)
+			continue
+		}
+		if got := SExpr(expr); got != tt.want {
+			t.Errorf("%q: got %s, want %s", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package parser
+
+import "testing"
+
+func TestRatePerQuantity(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"£1.89 per 100g", "(unit (currency 1.89 £) £/g (per 100))"},
+		{"£1.89 per 100 g", "(unit (currency 1.89 £) £/g (per 100))"},
+		{"2 dollars per 500 ml", "(unit (currency 2 dollars) $/ml (per 500))"},
+		{"6 l per 100 km", "(unit (unit 6 l) l/km (per 100))"},
+		{"£1.89 per 100g in gbp/kg", "(in (unit (currency 1.89 £) £/g (per 100)) gbp/kg)"},
+		{"£15 per kg", "(unit (currency 15 £) £/kg)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			expr, err := parseInput(tt.input)
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+			if got := SExpr(expr); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRatePerZeroQuantity(t *testing.T) {
+	for _, input := range []string{"£5 per 0 g", "3 m per 0 s"} {
+		if _, err := parseInput(input); err == nil {
+			t.Errorf("%s: expected an error", input)
+		}
+	}
+}
+
+func TestRateTails(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"£45,000 per annum", "(unit (currency 45000 £) £/year)"},
+		{"£45,000 pa", "(unit (currency 45000 £) £/year)"},
+		{"£45k PA", "(unit (currency 45000 £) £/year)"},
+		{"45000 gbp pa", "(unit (currency 45000 gbp) £/year)"},
+		{"45000 gbp per annum", "(unit (currency 45000 gbp) £/year)"},
+		{"£900 per week", "(unit (currency 900 £) £/week)"},
+		{"37.5 hours per week", "(unit (unit 37.5 hours) hours/week)"},
+		{"200 hours per annum", "(unit (unit 200 hours) hours/year)"},
+		{"£3750/month in gbp per annum", "(in (unit (currency 3750 £) £/month) gbp/year)"},
+		// pa is pascals after anything but money
+		{"5 pa", "(unit 5 pa)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			expr, err := parseInput(tt.input)
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+			if got := SExpr(expr); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
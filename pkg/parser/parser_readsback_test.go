@@ -0,0 +1,24 @@
+package parser
+
+import "testing"
+
+func TestReadsBack(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"rent", true},
+		{"in", true},
+		{"per", true},
+		{"total", true},
+		{"sum", true},
+		{"average", true},
+		{"today", false},
+		{"half", false},
+	}
+	for _, tt := range tests {
+		if got := ReadsBack(tt.name); got != tt.want {
+			t.Errorf("ReadsBack(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
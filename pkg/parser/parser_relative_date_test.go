@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseRelativeDatePhrases(t *testing.T) {
+	tests := []struct {
+		input    string
+		operator string
+		amount   float64
+		unit     string
+		anchor   string // type of the base expression
+	}{
+		{"3 weeks from today", "+", 3, "weeks", "*parser.DateExpr"},
+		{"2 days ago", "-", 2, "days", "*parser.DateExpr"},
+		{"4 days after 25/12/2025", "+", 4, "days", "*parser.DateExpr"},
+		{"a week before next friday", "-", 1, "week", "*parser.WeekdayExpr"},
+		{"an hour ago", "-", 1, "hour", "*parser.TimeExpr"},
+		{"three days ago", "-", 3, "days", "*parser.DateExpr"},
+		{"10 years from now", "+", 10, "years", "*parser.DateExpr"},
+		{"in 3 weeks", "+", 3, "weeks", "*parser.DateExpr"},
+		{"in 2 hours", "+", 2, "hours", "*parser.TimeExpr"},
+	}
+
+	for _, tt := range tests {
+		expr, err := parseInput(tt.input)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.input, err)
+			continue
+		}
+		date, ok := expr.(*DateArithmeticExpr)
+		if !ok {
+			t.Errorf("%q: expected DateArithmeticExpr, got %T", tt.input, expr)
+			continue
+		}
+		if date.Operator != tt.operator || date.Unit != tt.unit {
+			t.Errorf("%q: got operator %q unit %q, want %q %q", tt.input, date.Operator, date.Unit, tt.operator, tt.unit)
+		}
+		if n, ok := date.Offset.(*NumberExpr); !ok || n.Value != tt.amount {
+			t.Errorf("%q: expected offset %v, got %#v", tt.input, tt.amount, date.Offset)
+		}
+		if got := fmt.Sprintf("%T", date.Base); got != tt.anchor {
+			t.Errorf("%q: expected anchor %s, got %s", tt.input, tt.anchor, got)
+		}
+	}
+}
+
+func TestParseInStillMeansConversion(t *testing.T) {
+	for _, input := range []string{"3 weeks in days", "100 cm in m"} {
+		expr, err := parseInput(input)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", input, err)
+			continue
+		}
+		if _, ok := expr.(*ConversionExpr); !ok {
+			t.Errorf("%q: expected ConversionExpr, got %T", input, expr)
+		}
+	}
+
+	// A leading "in" is only a date phrase when a bare duration follows
+	if expr, err := parseInput("in 3 weeks + 2"); err == nil {
+		if _, ok := expr.(*DateArithmeticExpr); ok {
+			t.Errorf("\"in 3 weeks + 2\" should not parse as a date phrase")
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package parser
+
+import "testing"
+
+func TestSExpr(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"2 + 3 * 4", "(+ 2 (* 3 4))"},
+		{"50 cm in m", "(in (unit 50 cm) m)"},
+		{"10% of £200", "(of 10 (currency 200 £))"},
+		{"vat of £200 + 5", "(of vat (+ (currency 200 £) 5))"},
+		{"x = -5", "(= x (- 5))"},
+		{"sum(1, 2)", "(sum 1 2)"},
+		{"increase 100 by 5%", "(increase 100 (% 5))"},
+	}
+	for _, tt := range tests {
+		expr, err := parseInput(tt.input)
+		if err != nil {
+			t.Fatalf("parse %q: %v", tt.input, err)
+		}
+		if got := SExpr(expr); got != tt.want {
+			t.Errorf("SExpr(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package parser
+
+import "testing"
+
+func TestSharePhrases(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"£86.40 between 4", "(each (currency 86.4 £) 4)"},
+		{"£86.40 between 4 people", "(each (currency 86.4 £) (unit 4 people))"},
+		{"split £86.40 between 4", "(each (currency 86.4 £) 4)"},
+		{"split £86.40 between 4 in USD", "(in (each (currency 86.4 £) 4) USD)"},
+		{"£86.40 / 4 each", "(each (currency 86.4 £) 4)"},
+		{"£86.40 / 4 people each", "(each (currency 86.4 £) (unit 4 people))"},
+		{"£86.40 per person for 4 people", "(each (currency 86.4 £) (unit 4 people))"},
+		{"£86.40 between 4 in USD", "(in (each (currency 86.4 £) 4) USD)"},
+		{"(£80 + £6.40) between 2 * 2", "(each (+ (currency 80 £) (currency 6.4 £)) (* 2 2))"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			expr, err := parseInput(tt.input)
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+			if got := SExpr(expr); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountUnitsAreOrdinaryUnits(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"4 people", "(unit 4 people)"},
+		{"£86.40 / 4 people", "(/ (currency 86.4 £) (unit 4 people))"},
+		{"£21.60/person", "(unit (currency 21.6 £) £/person)"},
+		{"£5 per kg for 4 kg", "(unit (currency 5 £) £/kg)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			expr, err := parseInput(tt.input)
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+			if got := SExpr(expr); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package parser
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/andrewneudegg/calc/pkg/lexer"
+)
+
+// spanText returns the text of input a span covers.
+func spanText(input string, s Span) string {
+	runes := []rune(input)
+	if s.Start.Column < 1 || s.End.Column-1 > len(runes) || s.End.Column < s.Start.Column {
+		return "<bad span>"
+	}
+	return string(runes[s.Start.Column-1 : s.End.Column-1])
+}
+
+func TestSpans(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string // Each node, outermost first, as "sexpr: source"
+	}{
+		{"1 + 2 * 3", []string{
+			"(+ 1 (* 2 3)): 1 + 2 * 3",
+			"1: 1",
+			"(* 2 3): 2 * 3",
+			"2: 2",
+			"3: 3",
+		}},
+		{"x = £3.20 * 2", []string{
+			"(= x (* (currency 3.2 £) 2)): x = £3.20 * 2",
+			"(* (currency 3.2 £) 2): £3.20 * 2",
+			"(currency 3.2 £): £3.20",
+			"3.2: 3.20",
+			"2: 2",
+		}},
+		{"(5 km in m) * 2", []string{
+			"(* (in (unit 5 km) m) 2): (5 km in m) * 2",
+			"(in (unit 5 km) m): 5 km in m",
+			"(unit 5 km): 5 km",
+			"5: 5",
+			"2: 2",
+		}},
+		{"-sqrt(16)", []string{
+			"(- (sqrt 16)): -sqrt(16)",
+			"(sqrt 16): sqrt(16)",
+			"16: 16",
+		}},
+		{"20% of £50", []string{
+			"(of 20 (currency 50 £)): 20% of £50",
+			"20: 20",
+			"(currency 50 £): £50",
+			"50: 50",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			expr, err := parseInput(tt.input)
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+			var got []string
+			var walk func(n Node)
+			walk = func(n Node) {
+				got = append(got, SExpr(n.(Expr))+": "+spanText(tt.input, SpanOf(n)))
+				eachChild(n, walk)
+			}
+			walk(expr)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("node %d: got %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseTree(t *testing.T) {
+	t.Run("expression", func(t *testing.T) {
+		tree := New(lexer.New("5 km").AllTokens()).ParseTree()
+		data, err := json.Marshal(tree)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := `{"tokens":[` +
+			`{"type":"NUMBER","literal":"5","span":{"start":{"line":1,"column":1},"end":{"line":1,"column":2}}},` +
+			`{"type":"UNIT","literal":"km","span":{"start":{"line":1,"column":3},"end":{"line":1,"column":5}}}],` +
+			`"ast":{"type":"unit","span":{"start":{"line":1,"column":1},"end":{"line":1,"column":5}},` +
+			`"literals":{"ingredient":"","per":0,"unit":"km"},` +
+			`"children":{"value":{"type":"number","span":{"start":{"line":1,"column":1},"end":{"line":1,"column":2}},"literals":{"value":5}}}}}`
+		if string(data) != want {
+			t.Errorf("got  %s\nwant %s", data, want)
+		}
+	})
+
+	t.Run("list of children", func(t *testing.T) {
+		tree := New(lexer.New("max(1, 2)").AllTokens()).ParseTree()
+		args, ok := tree.AST.Children["args"].([]*TreeNode)
+		if !ok || len(args) != 2 || args[1].Literals["value"] != 2.0 {
+			t.Errorf("expected two args, got %#v", tree.AST.Children)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		tree := New(lexer.New("1 + * 2").AllTokens()).ParseTree()
+		if tree.AST != nil || tree.Error == nil {
+			t.Fatalf("expected an error, got %+v", tree)
+		}
+		if tree.Error.Position != (Position{Line: 1, Column: 5}) || tree.Error.Message == "" {
+			t.Errorf("got %+v", tree.Error)
+		}
+		if len(tree.Tokens) != 4 {
+			t.Errorf("expected all 4 tokens, got %d", len(tree.Tokens))
+		}
+	})
+
+	t.Run("error at the end", func(t *testing.T) {
+		tree := New(lexer.New("1 +").AllTokens()).ParseTree()
+		if tree.Error == nil || tree.Error.Position != (Position{Line: 1, Column: 4}) {
+			t.Errorf("got %+v", tree.Error)
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package parser
+
+import "testing"
+
+func TestTagAggregates(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"sum #food", "(sum #food)"},
+		{"total #Food", "(sum #food)"},
+		{"total of #food", "(sum #food)"},
+		{"average #travel", "(average #travel)"},
+		{"mean #travel", "(average #travel)"},
+		{"sum #food in usd", "(in (sum #food) usd)"},
+		{"sum #food / 4", "(/ (sum #food) 4)"},
+		{"budget - sum #food", "(- budget (sum #food))"},
+		{"sum(1, 2)", "(sum 1 2)"},
+		{"sum = 3", "(= sum 3)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			expr, err := parseInput(tt.input)
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+			if got := SExpr(expr); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLabels(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"coffee: £3.20", "(= coffee (currency 3.2 £))"},
+		{"rent: 900 + 50", "(= rent (+ 900 50))"},
+		{"which is bigger: 5 km or 3 miles", "(which-more (unit 5 km) (unit 3 miles))"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			expr, err := parseInput(tt.input)
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+			if got := SExpr(expr); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -317,6 +318,58 @@ func TestParseCommands(t *testing.T) {
 	}
 }
 
+func TestParseCommandArgs(t *testing.T) {
+	tests := []struct {
+		input string
+		args  []string
+	}{
+		{":save notes/a-b.calc", []string{"notes/a-b.calc"}},
+		{":set precision -1", []string{"precision", "-1"}},
+		{":set precision 3", []string{"precision", "3"}},
+		{`:save C:\notes\work.calc`, []string{`C:\notes\work.calc`}},
+		{`:open D:\2024\q1-budget.calc`, []string{`D:\2024\q1-budget.calc`}},
+		{`:open ..\notes\trip.calc`, []string{`..\notes\trip.calc`}},
+		{`:save C:\my notes\work.calc`, []string{`C:\my`, `notes\work.calc`}},
+	}
+
+	for _, tt := range tests {
+		expr, err := parseInput(tt.input)
+		if err != nil {
+			t.Errorf("Parser errors for %q: %v", tt.input, err)
+			continue
+		}
+		cmdExpr, ok := expr.(*CommandExpr)
+		if !ok {
+			t.Errorf("Expected CommandExpr for %q, got %T", tt.input, expr)
+			continue
+		}
+		if strings.Join(cmdExpr.Args, "|") != strings.Join(tt.args, "|") {
+			t.Errorf("%q: expected args %q, got %q", tt.input, tt.args, cmdExpr.Args)
+		}
+	}
+}
+
+// TestCommandArgsGluesOnlyTouchingTokens checks that path punctuation is
+// joined to its neighbours only where nothing separated them.
+func TestCommandArgsGluesOnlyTouchingTokens(t *testing.T) {
+	tok := func(lit string, col int) lexer.Token { return lexer.Token{Literal: lit, Column: col} }
+	tests := []struct {
+		name   string
+		tokens []lexer.Token
+		want   []string
+	}{
+		{"touching", []lexer.Token{tok("C", 1), tok(":", 2), tok("\\", 3), tok("x", 4)}, []string{`C:\x`}},
+		{"spaced colon", []lexer.Token{tok("a", 1), tok(":", 3), tok("b", 5)}, []string{"a", ":", "b"}},
+		{"words", []lexer.Token{tok("precision", 1), tok("3", 11)}, []string{"precision", "3"}},
+		{"none", nil, nil},
+	}
+	for _, tt := range tests {
+		if got := commandArgs(tt.tokens); strings.Join(got, "|") != strings.Join(tt.want, "|") || (got == nil) != (tt.want == nil) {
+			t.Errorf("%s: commandArgs = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
 func TestParseComplexExpressions(t *testing.T) {
 	tests := []struct {
 		input string
@@ -0,0 +1,91 @@
+package parser
+
+import "testing"
+
+func TestToParsesLikeIn(t *testing.T) {
+	tests := []struct {
+		to string
+		in string
+	}{
+		{"10 m to cm", "10 m in cm"},
+		{"£100 to usd", "£100 in usd"},
+		{"32 f to c", "32 f in c"},
+		{"60 mph to km/h", "60 mph in km/h"},
+		{"100 km per hour to m per s", "100 km per hour in m per s"},
+		{"1 km to m to cm", "1 km in m in cm"},
+		{"100 kg to lb, stone", "100 kg in lb, stone"},
+		{"3 kg to lb * 2", "3 kg in lb * 2"},
+		{"(3 kg to lb) * 5 to kg", "(3 kg in lb) * 5 in kg"},
+		{"increase 10 m by 10% to cm", "increase 10 m by 10% in cm"},
+		{"x = 5 km to m", "x = 5 km in m"},
+		{"time in Sydney plus 3 hours to London", "time in Sydney plus 3 hours in London"},
+		{"today + 90 days as iso", "today + 90 days in iso"},
+		{"25/12/2025 as weekday", "25/12/2025 in weekday"},
+		{"5 m as ft", "5 m in ft"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.to, func(t *testing.T) {
+			got, err := parseInput(tt.to)
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+			want, err := parseInput(tt.in)
+			if err != nil {
+				t.Fatalf("parse error for %q: %v", tt.in, err)
+			}
+			if SExpr(got) != SExpr(want) {
+				t.Errorf("got %s, want %s", SExpr(got), SExpr(want))
+			}
+		})
+	}
+}
+
+func TestToConvertsBetweenTimezones(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"time in Sydney to London", "(time-convert () Sydney London)"},
+		{"time in Sydney in London", "(time-convert () Sydney London)"},
+		{"14:30 London to Tokyo", "(time-convert (unit 14.5 time) London Tokyo)"},
+		{"14:30 London in New York", "(time-convert (unit 14.5 time) London New York)"},
+		{"time in Sydney", "(time-in Sydney)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			expr, err := parseInput(tt.input)
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+			if got := SExpr(expr); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToIsAnIdentifierOutsideConversions(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"to = 5", "(= to 5)"},
+		{"to * 2", "(* to 2)"},
+		{"to(3)", "(to 3)"},
+		{"10 m to to", "(in (unit 10 m) to)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			expr, err := parseInput(tt.input)
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+			if got := SExpr(expr); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
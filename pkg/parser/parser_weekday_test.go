@@ -0,0 +1,35 @@
+package parser
+
+import "testing"
+
+func TestParseWeekdayPhrases(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"next monday + 2 weeks", "(+ (weekday next Monday) (unit 2 weeks))"},
+		{"friday after 25/12/2025", "(weekday next Friday (date 2025-12-25))"},
+		{"friday before today", "(weekday last Friday (date today+0))"},
+		{"friday after 25/12/2025 + 1 week", "(+ (weekday next Friday (date 2025-12-25)) (unit 1 week))"},
+		{"first monday of march", "(weekday 1 Monday March)"},
+		{"second tuesday of june 2026", "(weekday 2 Tuesday June 2026)"},
+		{"Fourth Thursday of November", "(weekday 4 Thursday November)"},
+		{"last friday of march", "(weekday -1 Friday March)"},
+		{"last friday", "(weekday last Friday)"},
+	}
+	for _, tt := range tests {
+		expr, err := parseInput(tt.input)
+		if err != nil {
+			t.Fatalf("parse %q: %v", tt.input, err)
+		}
+		if got := SExpr(expr); got != tt.want {
+			t.Errorf("SExpr(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+
+	for _, input := range []string{"first monday", "first monday of 5", "last friday of"} {
+		if expr, err := parseInput(input); err == nil {
+			t.Errorf("parse %q = %s, want an error", input, SExpr(expr))
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SExpr writes expr as a compact s-expression, e.g. "(in (+ 1 (unit 50 cm)) m)",
+// for showing how a line was parsed.
+func SExpr(expr Expr) string {
+	switch n := expr.(type) {
+	case nil:
+		return "()"
+	case *NumberExpr:
+		return strconv.FormatFloat(n.Value, 'g', -1, 64)
+	case *StringExpr:
+		return strconv.Quote(n.Value)
+	case *IdentExpr:
+		return n.Name
+	case *BinaryExpr:
+		return sexprList(n.Operator, SExpr(n.Left), SExpr(n.Right))
+	case *UnaryExpr:
+		return sexprList(n.Operator, SExpr(n.Operand))
+	case *AssignExpr:
+		return sexprList("=", n.Name, SExpr(n.Value))
+	case *DestructureExpr:
+		return sexprList("=", sexprList(n.Names[0], n.Names[1:]...), SExpr(n.Value))
+	case *UnitExpr:
+		if n.Per != 0 {
+			return sexprList("unit", SExpr(n.Value), n.Unit, sexprList("per", strconv.FormatFloat(n.Per, 'g', -1, 64)))
+		}
+		if n.Ingredient != "" {
+			return sexprList("unit", SExpr(n.Value), n.Unit, sexprList("of", n.Ingredient))
+		}
+		return sexprList("unit", SExpr(n.Value), n.Unit)
+	case *CompositeExpr:
+		parts := make([]string, len(n.Parts))
+		for i, part := range n.Parts {
+			parts[i] = SExpr(part)
+		}
+		return sexprList("composite", parts...)
+	case *ConversionExpr:
+		if n.On != nil {
+			return sexprList("in", SExpr(n.Value), n.ToUnit, sexprList("on", SExpr(n.On)))
+		}
+		return sexprList("in", SExpr(n.Value), n.ToUnit)
+	case *MultiConversionExpr:
+		return sexprList("in", append([]string{SExpr(n.Value)}, n.ToUnits...)...)
+	case *CurrencyExpr:
+		return sexprList("currency", SExpr(n.Value), n.Currency)
+	case *PercentExpr:
+		if n.Unit != "" {
+			return sexprList(n.Unit, SExpr(n.Value))
+		}
+		return sexprList("%", SExpr(n.Value))
+	case *PercentOfExpr:
+		return sexprList("of", SExpr(n.Percent), SExpr(n.Of))
+	case *NamedPercentOfExpr:
+		return sexprList("of", SExpr(n.Percent), SExpr(n.Of))
+	case *PercentChangeExpr:
+		op := "increase"
+		if !n.Increase {
+			op = "decrease"
+		}
+		return sexprList(op, SExpr(n.Base), SExpr(n.Percent))
+	case *WhatPercentExpr:
+		return sexprList("what-percent", SExpr(n.Part), SExpr(n.Whole))
+	case *FunctionCallExpr:
+		args := make([]string, len(n.Args))
+		for i, arg := range n.Args {
+			args[i] = SExpr(arg)
+		}
+		return sexprList(n.Name, args...)
+	case *DateExpr:
+		if n.Relative {
+			return sexprList("date", fmt.Sprintf("today%+d", n.DayOffset))
+		}
+		return sexprList("date", n.Date.Format("2006-01-02"))
+	case *TimeExpr:
+		if n.Now {
+			return sexprList("time", "now")
+		}
+		return sexprList("time", n.Time.Format("15:04"))
+	case *DateAtTimeExpr:
+		return sexprList("at", SExpr(n.Date), SExpr(n.Time))
+	case *DateArithmeticExpr:
+		return sexprList(n.Operator, SExpr(n.Base), sexprList("unit", SExpr(n.Offset), n.Unit))
+	case *FuzzyExpr:
+		return sexprList(n.Pattern, SExpr(n.Value))
+	case *CommandExpr:
+		return sexprList(":"+n.Command, n.Args...)
+	case *RateExpr:
+		return sexprList("per", SExpr(n.Numerator), SExpr(n.Denominator))
+	case *WeekdayExpr:
+		if n.From != nil {
+			return sexprList("weekday", n.Modifier, n.Weekday.String(), SExpr(n.From))
+		}
+		if n.Modifier == "" {
+			return sexprList("weekday", n.Weekday.String())
+		}
+		return sexprList("weekday", n.Modifier, n.Weekday.String())
+	case *NthWeekdayExpr:
+		month := n.Month.String()
+		if n.Year != 0 {
+			month += " " + strconv.Itoa(n.Year)
+		}
+		return sexprList("weekday", strconv.Itoa(n.N), n.Weekday.String(), month)
+	case *CalendarDateExpr:
+		date := fmt.Sprintf("%d %s", n.Day, n.Month)
+		if n.Year != 0 {
+			date += " " + strconv.Itoa(n.Year)
+		}
+		return sexprList("date", date)
+	case *MonthExpr:
+		return sexprList("month", n.Month)
+	case *TimeInLocationExpr:
+		return sexprList("time-in", n.Location)
+	case *TimeDifferenceExpr:
+		if n.TargetUnit == "" {
+			return sexprList("time-difference", n.From, n.To)
+		}
+		return sexprList("time-difference", n.From, n.To, n.TargetUnit)
+	case *TimeConversionExpr:
+		parts := []string{SExpr(n.Time), n.From, n.To}
+		if n.Offset != nil {
+			parts = append(parts, sexprList(n.Operator, SExpr(n.Offset)))
+		}
+		return sexprList("time-convert", parts...)
+	case *PrevExpr:
+		if n.Absolute {
+			return fmt.Sprintf("prev#%d", n.Offset)
+		}
+		if n.Offset == 0 {
+			return "prev"
+		}
+		return fmt.Sprintf("prev~%d", n.Offset)
+	case *SplitExpr:
+		parts := make([]string, len(n.Parts))
+		for i, p := range n.Parts {
+			parts[i] = strconv.FormatFloat(p, 'g', -1, 64)
+		}
+		return sexprList("split", SExpr(n.Value), strings.Join(parts, ":"))
+	case *ShareExpr:
+		return sexprList("each", SExpr(n.Value), SExpr(n.Count))
+	case *TipExpr:
+		return sexprList("tip", SExpr(n.Percent), SExpr(n.Base))
+	case *CompareExpr:
+		head, side := "is-", "less"
+		if n.Which {
+			head = "which-"
+		}
+		if n.More {
+			side = "more"
+		}
+		return sexprList(head+side, SExpr(n.Left), SExpr(n.Right))
+	case *TagExpr:
+		return sexprList(n.Func, "#"+n.Tag)
+	case *ApproxExpr:
+		return sexprList("about", SExpr(n.Value))
+	case *ArgDirectiveExpr:
+		return sexprList(":arg", n.Name)
+	default:
+		return fmt.Sprintf("(%T)", expr)
+	}
+}
+
+// sexprList writes a head and its operands as "(head a b)".
+func sexprList(head string, operands ...string) string {
+	return "(" + strings.Join(append([]string{head}, operands...), " ") + ")"
+}
@@ -0,0 +1,102 @@
+package parser
+
+import (
+	"reflect"
+
+	"github.com/andrewneudegg/calc/pkg/lexer"
+)
+
+var exprType = reflect.TypeFor[Expr]()
+
+// markSpan gives *node, unless it already has one, the span of the tokens
+// read since start. Parsing functions defer it, so a node built from several
+// tokens, such as 5 km, covers them all.
+func (p *Parser) markSpan(start int, node *Expr) {
+	if *node == nil || start < 0 {
+		return
+	}
+	end := min(p.pos, len(p.tokens))
+	for end > start && p.tokens[end-1].Type == lexer.TokenEOF {
+		end--
+	}
+	if end <= start {
+		return
+	}
+	span := (*node).spanRef()
+	if *span != (Span{}) {
+		return
+	}
+	span.Start = tokenSpan(p.tokens[start]).Start
+	span.End = tokenSpan(p.tokens[end-1]).End
+}
+
+// tokenSpan returns the span of a single token.
+func tokenSpan(tok lexer.Token) Span {
+	return Span{
+		Start: Position{Line: tok.Line, Column: tok.Column},
+		// Tokens made up by the REPL, such as the _ before "* 2", have no end
+		End: Position{Line: tok.Line, Column: max(tok.End, tok.Column)},
+	}
+}
+
+// fillSpans gives every node under n that has no span the smallest one
+// covering its children's, as for the operators built while reading a
+// sum, whose operands were already marked.
+func fillSpans(n Node) {
+	var covered Span
+	eachChild(n, func(child Node) {
+		fillSpans(child)
+		covered = covered.union(SpanOf(child))
+	})
+	if span := n.spanRef(); *span == (Span{}) {
+		*span = covered
+	}
+}
+
+// union returns the smallest span covering s and t, ignoring either if it
+// is zero.
+func (s Span) union(t Span) Span {
+	switch {
+	case s == Span{}:
+		return t
+	case t == Span{}:
+		return s
+	}
+	if t.Start.before(s.Start) {
+		s.Start = t.Start
+	}
+	if s.End.before(t.End) {
+		s.End = t.End
+	}
+	return s
+}
+
+func (p Position) before(q Position) bool {
+	return p.Line < q.Line || p.Line == q.Line && p.Column < q.Column
+}
+
+// eachChild calls f with each node held in a field of n, including those
+// in a list such as a call's arguments, in the order the fields are
+// declared.
+func eachChild(n Node, f func(child Node)) {
+	v := reflect.ValueOf(n)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return
+	}
+	v = v.Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field, value := v.Type().Field(i), v.Field(i)
+		switch {
+		case field.Type == exprType:
+			if !value.IsNil() {
+				f(value.Interface().(Node))
+			}
+		case field.Type.Kind() == reflect.Slice && field.Type.Elem() == exprType:
+			for j := 0; j < value.Len(); j++ {
+				if !value.Index(j).IsNil() {
+					f(value.Index(j).Interface().(Node))
+				}
+			}
+		}
+	}
+}
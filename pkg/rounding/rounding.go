@@ -0,0 +1,100 @@
+// Package rounding rounds numbers for display and for the round functions,
+// following the rule chosen with ":set rounding". Stored values are never
+// rounded; only the digits shown or returned by a rounding function are.
+package rounding
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Mode is a rounding rule.
+type Mode string
+
+const (
+	// HalfUp rounds ties away from zero, so 2.5 gives 3 and -2.5 gives -3.
+	HalfUp Mode = "half-up"
+	// HalfEven rounds ties to the even neighbour, so 2.5 gives 2 and 3.5 gives 4.
+	HalfEven Mode = "half-even"
+	// Floor rounds towards negative infinity.
+	Floor Mode = "floor"
+	// Ceil rounds towards positive infinity.
+	Ceil Mode = "ceil"
+	// Truncate rounds towards zero, dropping the extra digits.
+	Truncate Mode = "truncate"
+)
+
+// Default is the mode used until another is chosen.
+const Default = HalfUp
+
+// Modes lists the rounding modes in the order they are documented.
+var Modes = []Mode{HalfUp, HalfEven, Floor, Ceil, Truncate}
+
+// Parse reads a mode name, ignoring case and accepting "_" for "-".
+func Parse(s string) (Mode, error) {
+	name := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(s)), "_", "-")
+	for _, m := range Modes {
+		if string(m) == name {
+			return m, nil
+		}
+	}
+	names := make([]string, len(Modes))
+	for i, m := range Modes {
+		names[i] = string(m)
+	}
+	return "", fmt.Errorf("rounding must be one of %s, got %q", strings.Join(names, ", "), s)
+}
+
+// epsilon is how close, relative to its size, a scaled value must be to a
+// whole number or a tie to count as one. It absorbs binary representation
+// error so that 0.29 floors to 0.29 rather than 0.28, and 2.675 is a tie.
+const epsilon = 1e-9
+
+// Round rounds x to a whole number under m. An empty or unknown mode rounds
+// as Default.
+func (m Mode) Round(x float64) float64 {
+	if math.IsInf(x, 0) || math.IsNaN(x) {
+		return x
+	}
+	tolerance := epsilon * math.Max(1, math.Abs(x))
+	if n := math.Round(x); math.Abs(x-n) < tolerance {
+		return n
+	}
+	switch m {
+	case Floor:
+		return math.Floor(x)
+	case Ceil:
+		return math.Ceil(x)
+	case Truncate:
+		return math.Trunc(x)
+	}
+	whole := math.Trunc(x)
+	if math.Abs(math.Abs(x-whole)-0.5) < tolerance {
+		x = whole + math.Copysign(0.5, x)
+	}
+	if m == HalfEven {
+		return math.RoundToEven(x)
+	}
+	return math.Round(x)
+}
+
+// Places rounds x to the given number of decimal places under m.
+func (m Mode) Places(x float64, decimals int) float64 {
+	pow := math.Pow(10, float64(decimals))
+	return m.Round(x*pow) / pow
+}
+
+// Step rounds x to a multiple of step under m, as in rounding a price to
+// the nearest 0.05. A step that is not positive leaves x unchanged.
+func (m Mode) Step(x, step float64) float64 {
+	if step <= 0 || math.IsInf(step, 0) || math.IsNaN(step) {
+		return x
+	}
+	n := m.Round(x / step)
+	// Dividing by a whole reciprocal avoids results like 12.350000000000001
+	if inv := 1 / step; inv == math.Trunc(inv) {
+		return n / inv
+	}
+	return n * step
+}
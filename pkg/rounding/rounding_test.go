@@ -0,0 +1,136 @@
+package rounding
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRoundTies(t *testing.T) {
+	tests := []struct {
+		mode Mode
+		in   float64
+		want float64
+	}{
+		{HalfUp, 2.5, 3},
+		{HalfUp, -2.5, -3},
+		{HalfUp, 3.5, 4},
+		{HalfUp, 2.4, 2},
+		{HalfEven, 2.5, 2},
+		{HalfEven, -2.5, -2},
+		{HalfEven, 3.5, 4},
+		{HalfEven, -3.5, -4},
+		{HalfEven, 2.6, 3},
+		{Floor, 2.5, 2},
+		{Floor, -2.5, -3},
+		{Ceil, 2.5, 3},
+		{Ceil, -2.5, -2},
+		{Truncate, 2.5, 2},
+		{Truncate, -2.5, -2},
+		{Truncate, 2.9, 2},
+		{"", 2.5, 3},
+	}
+
+	for _, tt := range tests {
+		if got := tt.mode.Round(tt.in); got != tt.want {
+			t.Errorf("%s.Round(%v) = %v, want %v", tt.mode, tt.in, got, tt.want)
+		}
+	}
+}
+
+// Every mode lands within one unit of its input, on a whole number, and
+// agrees with the others on whole numbers.
+func TestRoundProperties(t *testing.T) {
+	for _, m := range Modes {
+		for i := -400; i <= 400; i++ {
+			x := float64(i) / 8
+			got := m.Round(x)
+			if got != math.Trunc(got) || math.Abs(got-x) >= 1 {
+				t.Fatalf("%s.Round(%v) = %v", m, x, got)
+			}
+			if x == math.Trunc(x) && got != x {
+				t.Fatalf("%s.Round(%v) = %v, want it unchanged", m, x, got)
+			}
+			switch m {
+			case Floor:
+				if got > x {
+					t.Fatalf("floor rounded %v up to %v", x, got)
+				}
+			case Ceil:
+				if got < x {
+					t.Fatalf("ceil rounded %v down to %v", x, got)
+				}
+			case Truncate:
+				if math.Abs(got) > math.Abs(x) {
+					t.Fatalf("truncate rounded %v away from zero to %v", x, got)
+				}
+			case HalfUp, HalfEven:
+				if math.Abs(got-x) > 0.5 {
+					t.Fatalf("%s rounded %v to %v, more than half a unit", m, x, got)
+				}
+			}
+		}
+	}
+}
+
+func TestPlacesIgnoresRepresentationError(t *testing.T) {
+	tests := []struct {
+		mode     Mode
+		in       float64
+		decimals int
+		want     float64
+	}{
+		{Floor, 0.29, 2, 0.29},
+		{Truncate, 0.29, 2, 0.29},
+		{Ceil, 0.57, 2, 0.57},
+		{HalfUp, 2.675, 2, 2.68},
+		{HalfEven, 2.675, 2, 2.68},
+		{HalfEven, 2.665, 2, 2.66},
+		{Floor, 12.379, 2, 12.37},
+		{Ceil, -12.379, 2, -12.37},
+	}
+
+	for _, tt := range tests {
+		if got := tt.mode.Places(tt.in, tt.decimals); got != tt.want {
+			t.Errorf("%s.Places(%v, %d) = %v, want %v", tt.mode, tt.in, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+func TestStep(t *testing.T) {
+	tests := []struct {
+		mode Mode
+		in   float64
+		step float64
+		want float64
+	}{
+		{HalfUp, 17, 5, 15},
+		{HalfUp, 17.5, 5, 20},
+		{HalfEven, 17.5, 5, 20},
+		{HalfEven, 12.5, 5, 10},
+		{HalfUp, 12.37, 0.05, 12.35},
+		{HalfUp, 12.38, 0.05, 12.4},
+		{Ceil, 12.31, 0.05, 12.35},
+		{Floor, 12.34, 0.05, 12.3},
+		{HalfUp, 17, 0, 17},
+	}
+
+	for _, tt := range tests {
+		if got := tt.mode.Step(tt.in, tt.step); got != tt.want {
+			t.Errorf("%s.Step(%v, %v) = %v, want %v", tt.mode, tt.in, tt.step, got, tt.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	for _, m := range Modes {
+		if got, err := Parse(string(m)); err != nil || got != m {
+			t.Errorf("Parse(%q) = %q, %v", m, got, err)
+		}
+	}
+	if got, err := Parse("Half_Even"); err != nil || got != HalfEven {
+		t.Errorf("Parse(Half_Even) = %q, %v", got, err)
+	}
+	if _, err := Parse("bankers"); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+}
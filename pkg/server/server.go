@@ -0,0 +1,322 @@
+// Package server serves calc over HTTP as a local evaluation service for
+// editor plugins and browser extensions. POST /eval evaluates one line,
+// optionally in a named session whose variables persist between calls until
+// it has been idle for too long.
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andrewneudegg/calc/pkg/evaluator"
+	"github.com/andrewneudegg/calc/pkg/formatter"
+	"github.com/andrewneudegg/calc/pkg/lexer"
+	"github.com/andrewneudegg/calc/pkg/parser"
+	"github.com/andrewneudegg/calc/pkg/settings"
+)
+
+// TokenHeader is the request header that must carry the shared secret when
+// the server has a token.
+const TokenHeader = "X-Calc-Token"
+
+// maxBodyBytes caps the size of a /eval request body.
+const maxBodyBytes = 64 << 10
+
+// Options configure a Server. The zero value serves without a token, expires
+// sessions after DefaultIdleTimeout, evaluates with DefaultEvalTimeout and
+// keeps at most DefaultMaxSessions sessions.
+type Options struct {
+	Token       string          // Shared secret required in TokenHeader; "" for none
+	IdleTimeout time.Duration   // How long a session lives without requests
+	EvalTimeout time.Duration   // How long one evaluation may run
+	MaxSessions int             // How many named sessions may be kept at once
+	Clock       evaluator.Clock // Current time for sessions and dates; nil for the system time
+}
+
+const (
+	// DefaultIdleTimeout is how long an unused session is kept.
+	DefaultIdleTimeout = 30 * time.Minute
+	// DefaultEvalTimeout is how long one evaluation may run.
+	DefaultEvalTimeout = 5 * time.Second
+	// DefaultMaxSessions is how many named sessions are kept at once.
+	DefaultMaxSessions = 100
+)
+
+// errTooManySessions reports a new session asked for when the server already
+// keeps as many as it may.
+var errTooManySessions = errors.New("too many sessions; reuse one or wait for an idle one to expire")
+
+// Server evaluates calc input for HTTP clients. It is safe for concurrent
+// use: requests to different sessions run in parallel, and requests to the
+// same session take turns, since an Environment is not safe to share.
+type Server struct {
+	opts     Options
+	mu       sync.Mutex // Guards sessions
+	sessions map[string]*session
+}
+
+// session is a named environment that keeps its variables between requests.
+type session struct {
+	lastUsed time.Time // Guarded by the server's mu
+
+	mu          sync.Mutex // Held while evaluating, serialising use of env
+	env         *evaluator.Environment
+	eval        *evaluator.Evaluator
+	format      *formatter.Formatter
+	evaluations int
+}
+
+// New returns a Server with the given options.
+func New(opts Options) *Server {
+	if opts.IdleTimeout <= 0 {
+		opts.IdleTimeout = DefaultIdleTimeout
+	}
+	if opts.EvalTimeout <= 0 {
+		opts.EvalTimeout = DefaultEvalTimeout
+	}
+	if opts.MaxSessions <= 0 {
+		opts.MaxSessions = DefaultMaxSessions
+	}
+	return &Server{opts: opts, sessions: make(map[string]*session)}
+}
+
+// Handler returns the HTTP handler serving /eval, /sessions and /healthz.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /eval", s.handleEval)
+	mux.HandleFunc("GET /sessions", s.handleSessions)
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	return s.authorise(mux)
+}
+
+// authorise rejects requests without the shared secret when there is one.
+// Without a secret it instead rejects requests whose Host header is not
+// loopback, so a web page cannot reach the server by pointing a name it
+// controls at 127.0.0.1.
+func (s *Server) authorise(next http.Handler) http.Handler {
+	if s.opts.Token == "" {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !isLoopbackHost(r.Host) {
+				writeError(w, http.StatusForbidden, fmt.Sprintf("host %q is not loopback", r.Host))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := r.Header.Get(TokenHeader)
+		if subtle.ConstantTimeCompare([]byte(got), []byte(s.opts.Token)) != 1 {
+			writeError(w, http.StatusUnauthorized, "missing or wrong "+TokenHeader+" header")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isLoopbackHost reports whether a Host header, with or without a port, names
+// this machine: localhost or a loopback address.
+func isLoopbackHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// evalRequest is the body of POST /eval.
+type evalRequest struct {
+	Input   string `json:"input"`
+	Session string `json:"session"` // Optional; variables persist within a named session
+}
+
+func (s *Server) handleEval(w http.ResponseWriter, r *http.Request) {
+	// A browser sends a cross-site form post without asking first, but not JSON
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		writeError(w, http.StatusUnsupportedMediaType, "Content-Type must be application/json")
+		return
+	}
+	var req evalRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %s", err))
+		return
+	}
+	input := strings.TrimSpace(req.Input)
+	switch {
+	case input == "":
+		writeError(w, http.StatusBadRequest, "input is required")
+		return
+	case strings.ContainsAny(input, "\r\n"):
+		writeError(w, http.StatusBadRequest, "input must be a single line")
+		return
+	}
+
+	sess, err := s.session(req.Session)
+	if err != nil {
+		writeError(w, http.StatusTooManyRequests, err.Error())
+		return
+	}
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	ctx, cancel := context.WithTimeout(r.Context(), s.opts.EvalTimeout)
+	defer cancel()
+	result := sess.evaluate(ctx, input)
+	writeJSON(w, http.StatusOK, encodeValue(result, sess.format))
+}
+
+// sessionInfo describes a session for GET /sessions.
+type sessionInfo struct {
+	Name        string    `json:"name"`
+	Variables   int       `json:"variables"`
+	Evaluations int       `json:"evaluations"`
+	LastUsed    time.Time `json:"last_used"`
+	Expires     time.Time `json:"expires"`
+}
+
+func (s *Server) handleSessions(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.expire()
+	sessions := make(map[string]*session, len(s.sessions))
+	infos := make([]sessionInfo, 0, len(s.sessions))
+	for name, sess := range s.sessions {
+		sessions[name] = sess
+		infos = append(infos, sessionInfo{
+			Name:     name,
+			LastUsed: sess.lastUsed,
+			Expires:  sess.lastUsed.Add(s.opts.IdleTimeout),
+		})
+	}
+	s.mu.Unlock()
+
+	// Each session is locked in turn, so a long evaluation holds up only its own entry
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	for i := range infos {
+		sess := sessions[infos[i].Name]
+		sess.mu.Lock()
+		infos[i].Variables = len(sess.env.GetVariableNames())
+		infos[i].Evaluations = sess.evaluations
+		sess.mu.Unlock()
+	}
+	writeJSON(w, http.StatusOK, infos)
+}
+
+// session returns the named session, creating it if needed, and marks it
+// used. An unnamed request gets a session of its own that is not kept. A new
+// name is refused once the server keeps MaxSessions sessions.
+func (s *Server) session(name string) (*session, error) {
+	now := s.now()
+	if name == "" {
+		return s.newSession(now), nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expire()
+	sess, ok := s.sessions[name]
+	if !ok {
+		if len(s.sessions) >= s.opts.MaxSessions {
+			return nil, errTooManySessions
+		}
+		sess = s.newSession(now)
+		s.sessions[name] = sess
+	}
+	sess.lastUsed = now
+	return sess, nil
+}
+
+func (s *Server) newSession(now time.Time) *session {
+	env := evaluator.NewEnvironment()
+	env.SetClock(s.opts.Clock)
+	format := formatter.New(settings.Default())
+	env.SetFormatFunc(format.FormatPlaces)
+	return &session{
+		env:      env,
+		eval:     evaluator.New(env),
+		format:   format,
+		lastUsed: now,
+	}
+}
+
+// expire drops the sessions idle for longer than the idle timeout. The
+// caller must hold s.mu.
+func (s *Server) expire() {
+	cutoff := s.now().Add(-s.opts.IdleTimeout)
+	for name, sess := range s.sessions {
+		if sess.lastUsed.Before(cutoff) {
+			delete(s.sessions, name)
+		}
+	}
+}
+
+func (s *Server) now() time.Time {
+	if s.opts.Clock != nil {
+		return s.opts.Clock.Now()
+	}
+	return time.Now()
+}
+
+// evaluate lexes, parses and evaluates one line in the session. The caller
+// must hold sess.mu.
+func (sess *session) evaluate(ctx context.Context, input string) evaluator.Value {
+	sess.evaluations++
+	lex := lexer.NewWithUnits(input, sess.env.IsUnit)
+	lex.SetConstantChecker(sess.env.Constants().IsConstant)
+	p := parser.New(lex.AllTokens())
+	p.SetVariableChecker(sess.env.HasVariable)
+	p.SetIngredientChecker(sess.env.Units().IsIngredient)
+	p.SetLocationChecker(sess.env.Timezones().IsLocation)
+	expr, err := p.Parse()
+	if err != nil {
+		return evaluator.NewError(err.Error())
+	}
+	if _, ok := expr.(*parser.CommandExpr); ok {
+		return evaluator.NewError("commands are not supported by the server")
+	}
+	return sess.eval.EvalContext(ctx, expr)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
+// Serve serves s on ln until ctx is done, then shuts down, letting
+// requests in progress finish.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	srv := &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	done := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), s.opts.EvalTimeout)
+		defer cancel()
+		done <- srv.Shutdown(shutdown)
+	}()
+	if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return <-done
+}
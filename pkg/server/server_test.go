@@ -0,0 +1,271 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testClock is a Clock the test moves by hand.
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// post sends an /eval request and decodes the response.
+func post(t *testing.T, srv *httptest.Server, input, session string) value {
+	t.Helper()
+	body, _ := json.Marshal(evalRequest{Input: input, Session: session})
+	resp, err := http.Post(srv.URL+"/eval", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("POST /eval %q: status %d", input, resp.StatusCode)
+	}
+	var v value
+	if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestEvalReturnsValue(t *testing.T) {
+	srv := httptest.NewServer(New(Options{}).Handler())
+	defer srv.Close()
+
+	got := post(t, srv, "10 m in cm", "")
+	if got.Type != "unit" || got.Number == nil || *got.Number != 1000 || got.Unit != "cm" || got.Display != "1,000.00 cm" {
+		t.Errorf("10 m in cm = %+v", got)
+	}
+	got = post(t, srv, "1 / 0", "")
+	if got.Type != "error" || got.Error != "division by zero" {
+		t.Errorf("1 / 0 = %+v", got)
+	}
+	got = post(t, srv, "£5 + £3", "")
+	if got.Type != "currency" || got.Currency != "£" || *got.Number != 8 {
+		t.Errorf("£5 + £3 = %+v", got)
+	}
+}
+
+func TestEvalRejectsBadRequests(t *testing.T) {
+	srv := httptest.NewServer(New(Options{}).Handler())
+	defer srv.Close()
+
+	for _, body := range []string{`{"input": ""}`, `not json`, `{"input": "1", "extra": true}`, `{"input": "1\n2"}`} {
+		resp, err := http.Post(srv.URL+"/eval", "application/json", bytes.NewBufferString(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", body, resp.StatusCode)
+		}
+	}
+}
+
+func TestSessionsAreIsolated(t *testing.T) {
+	srv := httptest.NewServer(New(Options{}).Handler())
+	defer srv.Close()
+
+	post(t, srv, "x = 10", "a")
+	post(t, srv, "x = 20", "b")
+	if got := post(t, srv, "x * 2", "a"); *got.Number != 20 {
+		t.Errorf("session a: x * 2 = %+v, want 20", got)
+	}
+	if got := post(t, srv, "x * 2", "b"); *got.Number != 40 {
+		t.Errorf("session b: x * 2 = %+v, want 40", got)
+	}
+	if got := post(t, srv, "x", ""); got.Type != "error" {
+		t.Errorf("a request without a session should not see session variables, got %+v", got)
+	}
+}
+
+func TestSessionsExpireWhenIdle(t *testing.T) {
+	clock := &testClock{now: time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)}
+	srv := httptest.NewServer(New(Options{IdleTimeout: time.Minute, Clock: clock}).Handler())
+	defer srv.Close()
+
+	post(t, srv, "x = 10", "a")
+	post(t, srv, "y = 1", "b")
+	clock.advance(45 * time.Second)
+	post(t, srv, "x", "a") // Keeps a alive
+	clock.advance(45 * time.Second)
+
+	resp, err := http.Get(srv.URL + "/sessions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var sessions []sessionInfo
+	if err := json.NewDecoder(resp.Body).Decode(&sessions); err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || sessions[0].Name != "a" || sessions[0].Variables != 1 || sessions[0].Evaluations != 2 {
+		t.Fatalf("sessions = %+v, want only a", sessions)
+	}
+	if got := post(t, srv, "y", "b"); got.Type != "error" {
+		t.Errorf("an expired session should start afresh, got %+v", got)
+	}
+}
+
+func TestConcurrentRequests(t *testing.T) {
+	srv := httptest.NewServer(New(Options{}).Handler())
+	defer srv.Close()
+
+	post(t, srv, "n = 0", "shared")
+	const workers = 20
+	var wg sync.WaitGroup
+	for i := range workers {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			post(t, srv, "n = n + 1", "shared")
+		}()
+		go func() {
+			defer wg.Done()
+			session := fmt.Sprintf("own%d", i)
+			post(t, srv, fmt.Sprintf("x = %d", i), session)
+			if got := post(t, srv, "x", session); *got.Number != float64(i) {
+				t.Errorf("%s: x = %+v, want %d", session, got, i)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := post(t, srv, "n", "shared"); *got.Number != workers {
+		t.Errorf("n = %+v after %d increments", got, workers)
+	}
+}
+
+func TestTokenIsRequired(t *testing.T) {
+	srv := httptest.NewServer(New(Options{Token: "s3cret"}).Handler())
+	defer srv.Close()
+
+	for token, want := range map[string]int{"": http.StatusUnauthorized, "wrong": http.StatusUnauthorized, "s3cret": http.StatusOK} {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+"/healthz", nil)
+		if token != "" {
+			req.Header.Set(TokenHeader, token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("token %q: status %d, want %d", token, resp.StatusCode, want)
+		}
+	}
+}
+
+func TestEvalRequiresJSON(t *testing.T) {
+	srv := httptest.NewServer(New(Options{}).Handler())
+	defer srv.Close()
+
+	for contentType, want := range map[string]int{
+		"":                                  http.StatusUnsupportedMediaType,
+		"text/plain":                        http.StatusUnsupportedMediaType,
+		"application/x-www-form-urlencoded": http.StatusUnsupportedMediaType,
+		"application/json":                  http.StatusOK,
+		"application/json; charset=utf-8":   http.StatusOK,
+	} {
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/eval", bytes.NewBufferString(`{"input": "1 + 1"}`))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("Content-Type %q: status %d, want %d", contentType, resp.StatusCode, want)
+		}
+	}
+}
+
+func TestHostMustBeLoopback(t *testing.T) {
+	srv := httptest.NewServer(New(Options{}).Handler())
+	defer srv.Close()
+
+	for host, want := range map[string]int{
+		"localhost:7436":    http.StatusOK,
+		"LOCALHOST":         http.StatusOK,
+		"127.0.0.1:7436":    http.StatusOK,
+		"[::1]:7436":        http.StatusOK,
+		"evil.example:7436": http.StatusForbidden,
+		"192.168.1.5":       http.StatusForbidden,
+	} {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+"/healthz", nil)
+		req.Host = host
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("Host %q: status %d, want %d", host, resp.StatusCode, want)
+		}
+	}
+
+	// A token guards a server on another address, which clients reach by its own name
+	srv = httptest.NewServer(New(Options{Token: "s3cret"}).Handler())
+	defer srv.Close()
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/healthz", nil)
+	req.Host = "calc.lan:7436"
+	req.Header.Set(TokenHeader, "s3cret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Host %q with a token: status %d, want 200", req.Host, resp.StatusCode)
+	}
+}
+
+func TestSessionsAreCapped(t *testing.T) {
+	clock := &testClock{now: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+	srv := httptest.NewServer(New(Options{MaxSessions: 2, IdleTimeout: time.Minute, Clock: clock}).Handler())
+	defer srv.Close()
+
+	post(t, srv, "x = 1", "a")
+	post(t, srv, "x = 2", "b")
+	body, _ := json.Marshal(evalRequest{Input: "x = 3", Session: "c"})
+	resp, err := http.Post(srv.URL+"/eval", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("third session: status %d, want 429", resp.StatusCode)
+	}
+
+	// Existing sessions and unnamed requests still work, and an expired session frees its place
+	if got := post(t, srv, "x", "a"); *got.Number != 1 {
+		t.Errorf("session a: x = %+v, want 1", got)
+	}
+	post(t, srv, "1 + 1", "")
+	clock.advance(2 * time.Minute)
+	if got := post(t, srv, "x = 3", "c"); *got.Number != 3 {
+		t.Errorf("session c after expiry: x = %+v, want 3", got)
+	}
+}
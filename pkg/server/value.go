@@ -0,0 +1,65 @@
+package server
+
+import (
+	"math"
+	"time"
+
+	"github.com/andrewneudegg/calc/pkg/evaluator"
+	"github.com/andrewneudegg/calc/pkg/formatter"
+)
+
+// value is the JSON form of an evaluated value. Display is the result as
+// calc would print it; the other fields carry its parts for programs.
+type value struct {
+	Type     string   `json:"type"`
+	Display  string   `json:"display"`
+	Number   *float64 `json:"number,omitempty"`
+	Unit     string   `json:"unit,omitempty"`
+	Currency string   `json:"currency,omitempty"`
+	Date     string   `json:"date,omitempty"`
+	Text     string   `json:"text,omitempty"`
+	Label    string   `json:"label,omitempty"`
+	Error    string   `json:"error,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+	Items    []value  `json:"items,omitempty"`
+}
+
+// typeNames name each value type in the JSON form.
+var typeNames = map[evaluator.ValueType]string{
+	evaluator.ValueNumber:   "number",
+	evaluator.ValueUnit:     "unit",
+	evaluator.ValueCurrency: "currency",
+	evaluator.ValuePercent:  "percent",
+	evaluator.ValueDate:     "date",
+	evaluator.ValueString:   "string",
+	evaluator.ValueError:    "error",
+	evaluator.ValueList:     "list",
+}
+
+// encodeValue converts v to its JSON form, formatting Display with f.
+func encodeValue(v evaluator.Value, f *formatter.Formatter) value {
+	out := value{Type: typeNames[v.Type], Display: f.Format(v), Label: v.Label}
+	switch v.Type {
+	case evaluator.ValueError:
+		out.Error = v.Error
+		return out
+	case evaluator.ValueNumber, evaluator.ValuePercent, evaluator.ValueUnit, evaluator.ValueCurrency:
+		// JSON has no infinities or NaN, so those are left to Display
+		if !math.IsInf(v.Number, 0) && !math.IsNaN(v.Number) {
+			n := v.Number
+			out.Number = &n
+		}
+		out.Unit = v.Unit
+		out.Currency = v.Currency
+	case evaluator.ValueDate:
+		out.Date = v.Date.Format(time.RFC3339)
+	case evaluator.ValueString:
+		out.Text = v.Text
+	case evaluator.ValueList:
+		for _, item := range v.Items {
+			out.Items = append(out.Items, encodeValue(item, f))
+		}
+	}
+	out.Warnings = v.Warnings()
+	return out
+}
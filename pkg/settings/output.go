@@ -0,0 +1,60 @@
+package settings
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OutputLevel is how much calc prints besides results. The REPL, calc -c and
+// calc -f all follow it:
+//
+//	          results  assignments  commands  warnings  tips  rates, also, timing
+//	silent    yes      no           no        no        no    no
+//	quiet     yes      no           yes       yes       no    no
+//	normal    yes      yes          yes       yes       yes   no
+//	verbose   yes      yes          yes       yes       yes   yes
+//
+// Errors are printed at every level. Commands only print in the REPL; in a
+// file they configure the run.
+type OutputLevel int
+
+// The zero OutputLevel is unset, meaning the output setting applies.
+const (
+	OutputSilent OutputLevel = iota + 1
+	OutputQuiet
+	OutputNormal
+	OutputVerbose
+)
+
+var outputLevelNames = map[OutputLevel]string{
+	OutputSilent:  "silent",
+	OutputQuiet:   "quiet",
+	OutputNormal:  "normal",
+	OutputVerbose: "verbose",
+}
+
+// ParseOutputLevel reads an output level by name, ignoring case.
+func ParseOutputLevel(s string) (OutputLevel, error) {
+	for l, name := range outputLevelNames {
+		if strings.EqualFold(s, name) {
+			return l, nil
+		}
+	}
+	return 0, fmt.Errorf("output must be silent, quiet, normal or verbose, got %q", s)
+}
+
+// String returns the level's name, such as "quiet".
+func (l OutputLevel) String() string {
+	if name, ok := outputLevelNames[l]; ok {
+		return name
+	}
+	return "unset"
+}
+
+// OutputLevel returns the output setting as a level, normal if it is invalid.
+func (s *Settings) OutputLevel() OutputLevel {
+	if l, err := ParseOutputLevel(s.Output); err == nil {
+		return l
+	}
+	return OutputNormal
+}
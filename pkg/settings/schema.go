@@ -0,0 +1,583 @@
+package settings
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/andrewneudegg/calc/pkg/currency"
+	"github.com/andrewneudegg/calc/pkg/rounding"
+	"github.com/andrewneudegg/calc/pkg/units"
+)
+
+// MaxPrecision is the largest number of decimal places a float64 can meaningfully show.
+const MaxPrecision = 15
+
+// MaxSciAbove is the largest power of ten a float64 can reach.
+const MaxSciAbove = 308
+
+// Setting describes a single user preference. Help text, :set validation and
+// autocomplete are all generated from Schema so they cannot drift apart.
+type Setting struct {
+	Key         string   // Name used with :set
+	Aliases     []string // Alternative names accepted by :set
+	JSON        string   // Key in the persisted settings file
+	Type        string   // "int", "string" or "bool"
+	Arg         string   // Placeholder shown in help, e.g. "<n>"
+	Description string
+
+	get func(*Settings) string
+	set func(*Settings, string) error
+}
+
+var localePattern = regexp.MustCompile(`^[a-z]{2}_[A-Z]{2}$`)
+
+var schema = []Setting{
+	{
+		Key: "precision", JSON: "precision", Type: "int", Arg: "<n>",
+		Description: "Number of decimal places",
+		get:         func(s *Settings) string { return strconv.Itoa(s.Precision) },
+		set: func(s *Settings, v string) error {
+			p, err := strconv.Atoi(v)
+			if err != nil || p < 0 || p > MaxPrecision {
+				return fmt.Errorf("precision must be a whole number from 0 to %d, got %q", MaxPrecision, v)
+			}
+			s.Precision = p
+			return nil
+		},
+	},
+	{
+		Key: "dateformat", Aliases: []string{"date_format"}, JSON: "date_format", Type: "string", Arg: "<fmt>",
+		Description: "Date format string",
+		get:         func(s *Settings) string { return fmt.Sprintf("%q", s.DateFormat) },
+		set: func(s *Settings, v string) error {
+			if strings.TrimSpace(v) == "" {
+				return fmt.Errorf("dateformat must not be empty, e.g. \"2 Jan 2006\"")
+			}
+			s.DateFormat = v
+			return nil
+		},
+	},
+	{
+		Key: "currency", JSON: "currency", Type: "string", Arg: "<code>",
+		Description: "Default currency code",
+		get:         func(s *Settings) string { return s.Currency },
+		set: func(s *Settings, v string) error {
+			if !currency.NewSystem().IsCurrency(v) {
+				return fmt.Errorf("unknown currency code %q, expected a code such as GBP, USD, EUR or JPY", v)
+			}
+			s.Currency = strings.ToUpper(v)
+			return nil
+		},
+	},
+	{
+		Key: "currency-precision", Aliases: []string{"currency_precision"}, JSON: "currency_precision", Type: "string", Arg: "<n|auto>",
+		Description: "Decimal places of money; auto uses the currency's own, as 0 for yen",
+		get:         func(s *Settings) string { return s.CurrencyPrecision },
+		set: func(s *Settings, v string) error {
+			if v = strings.ToLower(v); v == "auto" {
+				s.CurrencyPrecision = v
+				return nil
+			}
+			p, err := strconv.Atoi(v)
+			if err != nil || p < 0 || p > MaxPrecision {
+				return fmt.Errorf("currency-precision must be auto or a whole number from 0 to %d, got %q", MaxPrecision, v)
+			}
+			s.CurrencyPrecision = strconv.Itoa(p)
+			return nil
+		},
+	},
+	{
+		Key: "percent-precision", Aliases: []string{"percent_precision"}, JSON: "percent_precision", Type: "int", Arg: "<n>",
+		Description: "Decimal places of percentages",
+		get:         func(s *Settings) string { return strconv.Itoa(s.PercentPrecision) },
+		set: func(s *Settings, v string) error {
+			p, err := strconv.Atoi(v)
+			if err != nil || p < 0 || p > MaxPrecision {
+				return fmt.Errorf("percent-precision must be a whole number from 0 to %d, got %q", MaxPrecision, v)
+			}
+			s.PercentPrecision = p
+			return nil
+		},
+	},
+	{
+		Key: "trim-zeros", Aliases: []string{"trim_zeros"}, JSON: "trim_zeros", Type: "bool", Arg: "<on|off>",
+		Description: "Drop trailing zeros, as in 2.5 for 2.5000; money keeps its currency-precision places",
+		get:         func(s *Settings) string { return onOff(s.TrimZeros) },
+		set: func(s *Settings, v string) error {
+			b, err := parseBool("trim-zeros", v)
+			if err != nil {
+				return err
+			}
+			s.TrimZeros = b
+			return nil
+		},
+	},
+	{
+		Key: "approx-sigfigs", Aliases: []string{"approx_sigfigs"}, JSON: "approx_sigfigs", Type: "int", Arg: "<n>",
+		Description: "Significant figures of a line starting with about or roughly",
+		get:         func(s *Settings) string { return strconv.Itoa(s.ApproxSigFigs) },
+		set: func(s *Settings, v string) error {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 || n > MaxPrecision {
+				return fmt.Errorf("approx-sigfigs must be a whole number from 1 to %d, got %q", MaxPrecision, v)
+			}
+			s.ApproxSigFigs = n
+			return nil
+		},
+	},
+	{
+		Key: "locale", JSON: "locale", Type: "string", Arg: "<locale>",
+		Description: "Locale for formatting",
+		get:         func(s *Settings) string { return s.Locale },
+		set: func(s *Settings, v string) error {
+			if !localePattern.MatchString(v) {
+				return fmt.Errorf("locale must look like en_GB or de_DE, got %q", v)
+			}
+			s.Locale = v
+			return nil
+		},
+	},
+	{
+		Key: "fuzzy", Aliases: []string{"fuzzy_mode"}, JSON: "fuzzy_mode", Type: "bool", Arg: "<on|off>",
+		Description: "Enable fuzzy phrase parsing",
+		get:         func(s *Settings) string { return onOff(s.FuzzyMode) },
+		set: func(s *Settings, v string) error {
+			b, err := parseBool("fuzzy", v)
+			if err != nil {
+				return err
+			}
+			s.FuzzyMode = b
+			return nil
+		},
+	},
+	{
+		Key: "implicitmul", Aliases: []string{"implicit_mul"}, JSON: "implicit_mul", Type: "bool", Arg: "<on|off>",
+		Description: "Read 2(3+4), (a)(b) and 2x as multiplication",
+		get:         func(s *Settings) string { return onOff(s.ImplicitMul) },
+		set: func(s *Settings, v string) error {
+			b, err := parseBool("implicitmul", v)
+			if err != nil {
+				return err
+			}
+			s.ImplicitMul = b
+			return nil
+		},
+	},
+	{
+		Key: "strict", JSON: "strict", Type: "bool", Arg: "<on|off>",
+		Description: "Treat undefined variables as errors; off reads them as 0 with a warning",
+		get:         func(s *Settings) string { return onOff(s.Strict) },
+		set: func(s *Settings, v string) error {
+			b, err := parseBool("strict", v)
+			if err != nil {
+				return err
+			}
+			s.Strict = b
+			return nil
+		},
+	},
+	{
+		Key: "autocomplete", JSON: "autocomplete", Type: "bool", Arg: "<on|off>",
+		Description: "Enable autocomplete suggestions",
+		get:         func(s *Settings) string { return onOff(s.Autocomplete) },
+		set: func(s *Settings, v string) error {
+			b, err := parseBool("autocomplete", v)
+			if err != nil {
+				return err
+			}
+			s.Autocomplete = b
+			return nil
+		},
+	},
+	{
+		Key: "echo", JSON: "echo", Type: "bool", Arg: "<on|off>",
+		Description: "Print each input line beside its result when running a file",
+		get:         func(s *Settings) string { return onOff(s.Echo) },
+		set: func(s *Settings, v string) error {
+			b, err := parseBool("echo", v)
+			if err != nil {
+				return err
+			}
+			s.Echo = b
+			return nil
+		},
+	},
+	{
+		Key: "output", JSON: "output", Type: "string", Arg: "<level>",
+		Description: "How much to print besides results: silent, quiet, normal or verbose",
+		get:         func(s *Settings) string { return s.Output },
+		set: func(s *Settings, v string) error {
+			l, err := ParseOutputLevel(v)
+			if err != nil {
+				return err
+			}
+			s.Output = l.String()
+			return nil
+		},
+	},
+	{
+		Key: "ascii", JSON: "ascii", Type: "string", Arg: "<auto|on|off>",
+		Description: "Restrict results to ASCII symbols",
+		get:         func(s *Settings) string { return s.ASCII },
+		set: func(s *Settings, v string) error {
+			if strings.EqualFold(v, "auto") {
+				s.ASCII = "auto"
+				return nil
+			}
+			b, err := parseBool("ascii", v)
+			if err != nil {
+				return fmt.Errorf("ascii must be auto, on or off, got %q", v)
+			}
+			s.ASCII = onOff(b)
+			return nil
+		},
+	},
+	{
+		Key: "rounding", JSON: "rounding", Type: "string", Arg: "<mode>",
+		Description: "Rounding for display and round(): half-up, half-even, floor, ceil or truncate",
+		get:         func(s *Settings) string { return s.Rounding },
+		set: func(s *Settings, v string) error {
+			m, err := rounding.Parse(v)
+			if err != nil {
+				return err
+			}
+			s.Rounding = string(m)
+			return nil
+		},
+	},
+	{
+		Key: "decimal", JSON: "decimal", Type: "bool", Arg: "<on|off>",
+		Description: "Do arithmetic on numbers and money in exact decimal",
+		get:         func(s *Settings) string { return onOff(s.Decimal) },
+		set: func(s *Settings, v string) error {
+			b, err := parseBool("decimal", v)
+			if err != nil {
+				return err
+			}
+			s.Decimal = b
+			return nil
+		},
+	},
+	{
+		Key: "month-arithmetic", Aliases: []string{"month_arithmetic"}, JSON: "month_arithmetic", Type: "string", Arg: "<mode>",
+		Description: "Adding months to 31 Jan: clamp to the month's last day or overflow into the next",
+		get:         func(s *Settings) string { return s.MonthArithmetic },
+		set: func(s *Settings, v string) error {
+			switch v = strings.ToLower(v); v {
+			case "clamp", "overflow":
+				s.MonthArithmetic = v
+				return nil
+			}
+			return fmt.Errorf("month-arithmetic must be clamp or overflow, got %q", v)
+		},
+	},
+	{
+		Key: "show-rates", Aliases: []string{"show_rates"}, JSON: "show_rates", Type: "bool", Arg: "<on|off>",
+		Description: "Show the exchange rate behind converted currency results",
+		get:         func(s *Settings) string { return onOff(s.ShowRates) },
+		set: func(s *Settings, v string) error {
+			b, err := parseBool("show-rates", v)
+			if err != nil {
+				return err
+			}
+			s.ShowRates = b
+			return nil
+		},
+	},
+	{
+		Key: "currency-display", Aliases: []string{"currency_display"}, JSON: "currency_display", Type: "string", Arg: "<style>",
+		Description: "Show money worked out from both symbols and codes, as in £12 + 3 gbp, as symbol or code",
+		get:         func(s *Settings) string { return s.CurrencyDisplay },
+		set: func(s *Settings, v string) error {
+			switch v = strings.ToLower(v); v {
+			case "symbol", "code":
+				s.CurrencyDisplay = v
+				return nil
+			}
+			return fmt.Errorf("currency-display must be symbol or code, got %q", v)
+		},
+	},
+	{
+		Key: "also", JSON: "also", Type: "bool", Arg: "<on|off>",
+		Description: "Show a unit result in one or two companion units on a line below",
+		get:         func(s *Settings) string { return onOff(s.Also) },
+		set: func(s *Settings, v string) error {
+			b, err := parseBool("also", v)
+			if err != nil {
+				return err
+			}
+			s.Also = b
+			return nil
+		},
+	},
+	{
+		Key: "show-steps", Aliases: []string{"show_steps"}, JSON: "show_steps", Type: "bool", Arg: "<on|off>",
+		Description: "Show the steps of a conversion chain, as in 5 miles = 8.05 km = 8,046.72 m",
+		get:         func(s *Settings) string { return onOff(s.ShowSteps) },
+		set: func(s *Settings, v string) error {
+			b, err := parseBool("show-steps", v)
+			if err != nil {
+				return err
+			}
+			s.ShowSteps = b
+			return nil
+		},
+	},
+	{
+		Key: "timing", JSON: "timing", Type: "bool", Arg: "<on|off>",
+		Description: "Show how long each line took to lex, parse and evaluate",
+		get:         func(s *Settings) string { return onOff(s.Timing) },
+		set: func(s *Settings, v string) error {
+			b, err := parseBool("timing", v)
+			if err != nil {
+				return err
+			}
+			s.Timing = b
+			return nil
+		},
+	},
+	{
+		Key: "tips", JSON: "tips", Type: "bool", Arg: "<on|off>",
+		Description: "Show hints in the REPL, such as what Ctrl-C does, each once a session",
+		get:         func(s *Settings) string { return onOff(s.Tips) },
+		set: func(s *Settings, v string) error {
+			b, err := parseBool("tips", v)
+			if err != nil {
+				return err
+			}
+			s.Tips = b
+			return nil
+		},
+	},
+	{
+		Key: "shadow-warnings", Aliases: []string{"shadow_warnings"}, JSON: "shadow_warnings", Type: "bool", Arg: "<on|off>",
+		Description: "Warn when a variable takes the name of a unit, currency, function or keyword",
+		get:         func(s *Settings) string { return onOff(s.ShadowWarnings) },
+		set: func(s *Settings, v string) error {
+			b, err := parseBool("shadow-warnings", v)
+			if err != nil {
+				return err
+			}
+			s.ShadowWarnings = b
+			return nil
+		},
+	},
+	{
+		Key: "sci-above", Aliases: []string{"sci_above"}, JSON: "sci_above", Type: "int", Arg: "<n>",
+		Description: "Show results of 10^n and beyond in scientific notation",
+		get:         func(s *Settings) string { return strconv.Itoa(s.SciAbove) },
+		set: func(s *Settings, v string) error {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 || n > MaxSciAbove {
+				return fmt.Errorf("sci-above must be a whole number from 1 to %d, got %q", MaxSciAbove, v)
+			}
+			s.SciAbove = n
+			return nil
+		},
+	},
+	{
+		Key: "prefer", JSON: "prefer", Type: "string", Arg: "<metric|imperial|off>",
+		Description: "Convert displayed results to metric or imperial units",
+		get:         func(s *Settings) string { return s.Prefer },
+		set: func(s *Settings, v string) error {
+			switch v = strings.ToLower(v); v {
+			case "metric", "imperial", "off":
+				s.Prefer = v
+				return nil
+			}
+			return fmt.Errorf("prefer must be metric, imperial or off, got %q", v)
+		},
+	},
+	preferSetting("length", units.DimensionLength, "km", func(s *Settings) *string { return &s.PreferLength }),
+	preferSetting("mass", units.DimensionMass, "kg", func(s *Settings) *string { return &s.PreferMass }),
+	preferSetting("temperature", units.DimensionTemperature, "c", func(s *Settings) *string { return &s.PreferTemperature }),
+	preferSetting("volume", units.DimensionVolume, "l", func(s *Settings) *string { return &s.PreferVolume }),
+	preferSetting("speed", units.DimensionSpeed, "kph", func(s *Settings) *string { return &s.PreferSpeed }),
+	preferSetting("area", units.DimensionArea, "m²", func(s *Settings) *string { return &s.PreferArea }),
+	{
+		Key: "prefer-original", Aliases: []string{"prefer_original"}, JSON: "prefer_original", Type: "bool", Arg: "<on|off>",
+		Description: "Show the original value next to a preferred-unit result",
+		get:         func(s *Settings) string { return onOff(s.PreferOriginal) },
+		set: func(s *Settings, v string) error {
+			b, err := parseBool("prefer-original", v)
+			if err != nil {
+				return err
+			}
+			s.PreferOriginal = b
+			return nil
+		},
+	},
+	{
+		Key: "rate-form", Aliases: []string{"rate_form"}, JSON: "rate_form", Type: "string", Arg: "<form>",
+		Description: "Show rates such as £1.89 per 100g as quoted (original) or per single unit (normalised)",
+		get:         func(s *Settings) string { return s.RateForm },
+		set: func(s *Settings, v string) error {
+			switch v = strings.ToLower(v); v {
+			case "original", "normalised":
+				s.RateForm = v
+				return nil
+			case "normalized":
+				s.RateForm = "normalised"
+				return nil
+			}
+			return fmt.Errorf("rate-form must be original or normalised, got %q", v)
+		},
+	},
+	{
+		Key: "composite", JSON: "composite", Type: "bool", Arg: "<on|off>",
+		Description: "Show feet, pounds and hours with the remainder in the next unit down, as in 5 ft 10.08 in",
+		get:         func(s *Settings) string { return onOff(s.Composite) },
+		set: func(s *Settings, v string) error {
+			b, err := parseBool("composite", v)
+			if err != nil {
+				return err
+			}
+			s.Composite = b
+			return nil
+		},
+	},
+	{
+		Key: "durations", JSON: "durations", Type: "string", Arg: "<units|compact>",
+		Description: "Show amounts of time in their units, as 2.25 h, or compact, as 2h15m",
+		get:         func(s *Settings) string { return s.Durations },
+		set: func(s *Settings, v string) error {
+			switch v = strings.ToLower(v); v {
+			case "units", "compact":
+				s.Durations = v
+				return nil
+			}
+			return fmt.Errorf("durations must be units or compact, got %q", v)
+		},
+	},
+	{
+		Key: "informal-units", Aliases: []string{"informal_units"}, JSON: "informal_units", Type: "bool", Arg: "<on|off>",
+		Description: "Read a dozen, a couple of, a few, and kitchen measures such as a pinch, dash or splash",
+		get:         func(s *Settings) string { return onOff(s.InformalUnits) },
+		set: func(s *Settings, v string) error {
+			b, err := parseBool("informal-units", v)
+			if err != nil {
+				return err
+			}
+			s.InformalUnits = b
+			return nil
+		},
+	},
+	{
+		Key: "few", JSON: "few", Type: "int", Arg: "<n>",
+		Description: "How many \"a few\" means",
+		get:         func(s *Settings) string { return strconv.Itoa(s.Few) },
+		set: func(s *Settings, v string) error {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 2 || n > 12 {
+				return fmt.Errorf("few must be a whole number from 2 to 12, got %q", v)
+			}
+			s.Few = n
+			return nil
+		},
+	},
+	limitSetting("max-line-length", "Longest input line, in characters", func(s *Settings) *int { return &s.MaxLineLength }),
+	limitSetting("max-tokens", "Most tokens a single line may contain", func(s *Settings) *int { return &s.MaxTokens }),
+	limitSetting("max-depth", "Deepest nesting of brackets and operators allowed", func(s *Settings) *int { return &s.MaxDepth }),
+}
+
+// limitSetting describes a guard against pathological input, e.g.
+// "max-tokens 10000". Values are parsed as floats first because numbers read
+// back from the settings file arrive as "1e+06".
+func limitSetting(key, description string, field func(*Settings) *int) Setting {
+	json := strings.ReplaceAll(key, "-", "_")
+	return Setting{
+		Key: key, Aliases: []string{json}, JSON: json, Type: "int", Arg: "<n>",
+		Description: description,
+		get:         func(s *Settings) string { return strconv.Itoa(*field(s)) },
+		set: func(s *Settings, v string) error {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil || f < 1 || f != float64(int(f)) || f > 1e9 {
+				return fmt.Errorf("%s must be a whole number from 1 to 1000000000, got %q", key, v)
+			}
+			*field(s) = int(f)
+			return nil
+		},
+	}
+}
+
+// preferSetting describes a per-dimension display unit, e.g. "prefer-length km".
+func preferSetting(name string, dim units.Dimension, example string, field func(*Settings) *string) Setting {
+	key := "prefer-" + name
+	return Setting{
+		Key: key, Aliases: []string{"prefer_" + name}, JSON: "prefer_" + name, Type: "string", Arg: "<unit|off>",
+		Description: "Display " + name + " results in this unit",
+		get:         func(s *Settings) string { return *field(s) },
+		set: func(s *Settings, v string) error {
+			if strings.EqualFold(v, "off") {
+				*field(s) = "off"
+				return nil
+			}
+			if d, err := units.NewSystem().GetDimension(v); err != nil || d != dim {
+				return fmt.Errorf("%s must be a %s unit such as %s, or off, got %q", key, name, example, v)
+			}
+			*field(s) = v
+			return nil
+		},
+	}
+}
+
+// Schema returns the description of every setting, in display order.
+func Schema() []Setting {
+	out := make([]Setting, len(schema))
+	copy(out, schema)
+	return out
+}
+
+// Lookup finds a setting by its key or one of its aliases.
+func Lookup(name string) (Setting, bool) {
+	name = strings.ToLower(name)
+	for _, st := range schema {
+		if st.Key == name {
+			return st, true
+		}
+		for _, alias := range st.Aliases {
+			if alias == name {
+				return st, true
+			}
+		}
+	}
+	return Setting{}, false
+}
+
+// Keys returns the :set name of every setting.
+func Keys() []string {
+	keys := make([]string, len(schema))
+	for i, st := range schema {
+		keys[i] = st.Key
+	}
+	return keys
+}
+
+// Value returns the current value of the setting in s, formatted for display.
+func (st Setting) Value(s *Settings) string {
+	return st.get(s)
+}
+
+// Default returns the default value of the setting, formatted for display.
+func (st Setting) Default() string {
+	return st.get(Default())
+}
+
+func parseBool(key, v string) (bool, error) {
+	switch strings.ToLower(v) {
+	case "on", "true", "1", "yes":
+		return true, nil
+	case "off", "false", "0", "no":
+		return false, nil
+	}
+	return false, fmt.Errorf("%s must be on or off, got %q", key, v)
+}
+
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
@@ -0,0 +1,144 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSchemaCoversEverySetting(t *testing.T) {
+	s := Default()
+	for _, st := range Schema() {
+		if st.Description == "" || st.Arg == "" || st.JSON == "" {
+			t.Errorf("setting %q is missing schema details: %+v", st.Key, st)
+		}
+		if got := st.Value(s); got != st.Default() {
+			t.Errorf("setting %q: value %q differs from default %q on default settings", st.Key, got, st.Default())
+		}
+		if _, ok := Lookup(st.Key); !ok {
+			t.Errorf("Lookup(%q) failed", st.Key)
+		}
+	}
+}
+
+func TestSetValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr string
+	}{
+		{"precision", "-1", "from 0 to 15"},
+		{"precision", "16", "from 0 to 15"},
+		{"precision", "two", "whole number"},
+		{"currency", "XYZ", "unknown currency code"},
+		{"locale", "english", "like en_GB"},
+		{"fuzzy", "maybe", "on or off"},
+		{"autocomplete", "sometimes", "on or off"},
+		{"dateformat", " ", "must not be empty"},
+		{"prefer", "nautical", "metric, imperial or off"},
+		{"rounding", "bankers", "half-up, half-even, floor, ceil, truncate"},
+		{"sci-above", "0", "from 1 to 308"},
+		{"sci-above", "1e6", "whole number"},
+		{"prefer-length", "kg", "length unit such as km"},
+		{"prefer-temperature", "parsecs", "temperature unit"},
+		{"max-tokens", "0", "whole number from 1"},
+		{"max-depth", "2.5", "whole number from 1"},
+		{"max-line-length", "lots", "whole number from 1"},
+		{"output", "loud", "silent, quiet, normal or verbose"},
+		{"currency-display", "name", "symbol or code"},
+		{"month-arithmetic", "end", "clamp or overflow"},
+		{"currency-precision", "-1", "auto or a whole number from 0 to 15"},
+		{"currency-precision", "two", "auto or a whole number from 0 to 15"},
+		{"percent-precision", "16", "whole number from 0 to 15"},
+		{"trim-zeros", "maybe", "on or off"},
+		{"tips", "sometimes", "on or off"},
+		{"approx-sigfigs", "0", "whole number from 1 to 15"},
+		{"durations", "long", "units or compact"},
+		{"precission", "3", "valid settings: precision, dateformat"},
+		{"precission", "3", "did you mean precision?"},
+		{"curency", "USD", "did you mean currency?"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+"="+tt.value, func(t *testing.T) {
+			s := Default()
+			err := s.Set(tt.name, tt.value)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Set(%q, %q) error = %v, want %q", tt.name, tt.value, err, tt.wantErr)
+			}
+			if s.Precision != 2 || s.Currency != "GBP" || !s.FuzzyMode {
+				t.Errorf("invalid value changed settings: %+v", s)
+			}
+		})
+	}
+}
+
+func TestUnknownSettingSuggestsOnlyNearKeys(t *testing.T) {
+	if err := UnknownSetting("echoo"); !strings.Contains(err.Error(), "did you mean echo?") {
+		t.Errorf("echoo: %v", err)
+	}
+	for _, name := range []string{"colour", "xy", "verbosity"} {
+		if err := UnknownSetting(name); strings.Contains(err.Error(), "did you mean") {
+			t.Errorf("%s: unexpected suggestion in %v", name, err)
+		}
+	}
+}
+
+func TestSetAliasesAndNormalisation(t *testing.T) {
+	s := Default()
+	if err := s.Set("fuzzy_mode", "off"); err != nil || s.FuzzyMode {
+		t.Errorf("fuzzy_mode alias: err=%v fuzzy=%v", err, s.FuzzyMode)
+	}
+	if err := s.Set("currency", "usd"); err != nil || s.Currency != "USD" {
+		t.Errorf("currency: err=%v currency=%q", err, s.Currency)
+	}
+	if err := s.Set("precision", "0"); err != nil || s.Precision != 0 {
+		t.Errorf("precision 0: err=%v precision=%d", err, s.Precision)
+	}
+	if err := s.Set("prefer_length", "m"); err != nil || s.PreferLength != "m" {
+		t.Errorf("prefer_length alias: err=%v prefer-length=%q", err, s.PreferLength)
+	}
+	if err := s.Set("prefer", "Imperial"); err != nil || s.Prefer != "imperial" {
+		t.Errorf("prefer: err=%v prefer=%q", err, s.Prefer)
+	}
+	if err := s.Set("rounding", "Half_Even"); err != nil || s.Rounding != "half-even" {
+		t.Errorf("rounding: err=%v rounding=%q", err, s.Rounding)
+	}
+	if err := s.Set("output", "Verbose"); err != nil || s.Output != "verbose" || s.OutputLevel() != OutputVerbose {
+		t.Errorf("output: err=%v output=%q", err, s.Output)
+	}
+	if err := s.Set("sci_above", "6"); err != nil || s.SciAbove != 6 {
+		t.Errorf("sci_above alias: err=%v sci-above=%d", err, s.SciAbove)
+	}
+	// Large limits come back from the settings file in exponent form
+	if err := s.Set("max_line_length", "2e+06"); err != nil || s.MaxLineLength != 2000000 {
+		t.Errorf("max_line_length alias: err=%v max-line-length=%d", err, s.MaxLineLength)
+	}
+}
+
+func TestLoadWarnsOnUnknownAndInvalidKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	data := `{"precision": 4, "precission": 3, "currency": "XYZ", "locale": "en_US"}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load should not fail on unknown keys: %v", err)
+	}
+	if s.Precision != 4 || s.Locale != "en_US" {
+		t.Errorf("valid keys not applied: %+v", s)
+	}
+	if s.Currency != "GBP" {
+		t.Errorf("invalid currency should keep default, got %q", s.Currency)
+	}
+	if len(s.LoadWarnings) != 2 {
+		t.Fatalf("expected 2 warnings, got %q", s.LoadWarnings)
+	}
+	joined := strings.Join(s.LoadWarnings, "\n")
+	if !strings.Contains(joined, `unknown setting "precission"`) || !strings.Contains(joined, "currency") {
+		t.Errorf("unexpected warnings: %q", s.LoadWarnings)
+	}
+}
@@ -4,7 +4,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"path/filepath"
+	"sort"
+
+	"github.com/andrewneudegg/calc/pkg/alias"
+	"github.com/andrewneudegg/calc/pkg/config"
+	"github.com/andrewneudegg/calc/pkg/parser"
+	"github.com/andrewneudegg/calc/pkg/rounding"
 )
 
 // Settings holds user preferences.
@@ -14,8 +19,79 @@ type Settings struct {
 	Currency     string `json:"currency"`
 	Locale       string `json:"locale"`
 	FuzzyMode    bool   `json:"fuzzy_mode"`
+	ImplicitMul  bool   `json:"implicit_mul"`
+	Strict       bool   `json:"strict"` // Undefined variables are errors; off reads them as 0 with a warning
 	Autocomplete bool   `json:"autocomplete"`
-	ConfigPath   string `json:"-"`
+	Echo         bool   `json:"echo"`   // Print each input line beside its result in file mode
+	Output       string `json:"output"` // Output level: "silent", "quiet", "normal" or "verbose"
+	ASCII        string `json:"ascii"`  // "auto", "on" or "off"
+	// Rounding is how results are rounded for display and by round functions.
+	Rounding string `json:"rounding"`
+	// CurrencyPrecision is the decimal places of amounts of money: a number,
+	// or "auto" for the currency's own, as 2 for pounds and 0 for yen.
+	CurrencyPrecision string `json:"currency_precision"`
+	PercentPrecision  int    `json:"percent_precision"` // Decimal places of percentages
+	// TrimZeros drops the zeros at the end of numbers and percentages, so
+	// 2.5000 shows as 2.5. Money keeps its currency-precision places.
+	TrimZeros bool `json:"trim_zeros"`
+	// ApproxSigFigs is the significant figures a line starting with "about"
+	// or "roughly" is rounded to, as in ≈ 1,600 for about 37 * 42.
+	ApproxSigFigs int `json:"approx_sigfigs"`
+	// Decimal does number and currency arithmetic in decimal rather than binary
+	// floating point, so £0.10 * 3 - £0.30 is exactly zero.
+	Decimal   bool `json:"decimal"`
+	ShowRates bool `json:"show_rates"` // Append the exchange rate to converted currency results
+	Also      bool `json:"also"`       // Show companion conversions under unit results in the REPL
+	ShowSteps bool `json:"show_steps"` // Show the values a chain of conversions passed through
+	Timing    bool `json:"timing"`     // Show how long each line took to evaluate in the REPL
+	// ShadowWarnings warns when a variable takes the name of a unit,
+	// currency, function or keyword.
+	ShadowWarnings bool `json:"shadow_warnings"`
+	// Tips shows hints in the REPL, such as what Ctrl-C does, each once a session.
+	Tips bool `json:"tips"`
+	// SciAbove is the power of ten from which results use scientific notation.
+	SciAbove int `json:"sci_above"`
+	// Prefer converts displayed results to "metric" or "imperial" units, or "off".
+	// The per-dimension fields name a fixed unit instead and win over Prefer.
+	Prefer            string `json:"prefer"`
+	PreferLength      string `json:"prefer_length"`
+	PreferMass        string `json:"prefer_mass"`
+	PreferTemperature string `json:"prefer_temperature"`
+	PreferVolume      string `json:"prefer_volume"`
+	PreferSpeed       string `json:"prefer_speed"`
+	PreferArea        string `json:"prefer_area"`
+	PreferOriginal    bool   `json:"prefer_original"` // Also show the unconverted value
+	// RateForm shows rates quoted per a quantity "original", as 1.89 £/100 g,
+	// or "normalised" to a single unit, as 0.0189 £/g.
+	RateForm string `json:"rate_form"`
+	// Composite shows feet, pounds, hours and the like with the remainder in
+	// the next unit down, as in 5 ft 10.08 in.
+	Composite bool `json:"composite"`
+	// Durations shows amounts of time in their "units", as 2.25 h, or
+	// "compact" as a Go duration, as 2h15m.
+	Durations string `json:"durations"`
+	// CurrencyDisplay shows money worked out from amounts written both with
+	// symbols and codes as "symbol" or "code"; the rest follows how it was written.
+	CurrencyDisplay string `json:"currency_display"`
+	// MonthArithmetic is how adding months to a date that falls past the end
+	// of the target month behaves: "clamp" to its last day, or "overflow".
+	MonthArithmetic string `json:"month_arithmetic"`
+	// InformalUnits reads a dozen, a few and kitchen measures such as a pinch.
+	InformalUnits bool `json:"informal_units"`
+	Few           int  `json:"few"` // How many "a few" means
+	// MaxLineLength, MaxTokens and MaxDepth bound how much work one line can
+	// ask for, so that pathological input gives an error instead of a crash.
+	MaxLineLength int `json:"max_line_length"`
+	MaxTokens     int `json:"max_tokens"`
+	MaxDepth      int `json:"max_depth"`
+	// Aliases are text shorthands managed with :alias, keyed by lower-cased name.
+	Aliases map[string]alias.Alias `json:"aliases,omitempty"`
+	// Tutorial lists the :tutorial lessons completed, by topic.
+	Tutorial   []string `json:"tutorial,omitempty"`
+	ConfigPath string   `json:"-"`
+	// LoadWarnings lists problems found in the settings file that were skipped
+	// rather than treated as fatal, such as unknown keys or invalid values.
+	LoadWarnings []string `json:"-"`
 }
 
 // Default returns default settings.
@@ -26,11 +102,44 @@ func Default() *Settings {
 		Currency:     "GBP",
 		Locale:       "en_GB", // Default to UK format (period=decimal, comma=thousands)
 		FuzzyMode:    true,
+		Strict:       true,
 		Autocomplete: true,
+		Output:       "normal",
+		ASCII:        "auto",
+		Rounding:     string(rounding.Default),
+		SciAbove:     15,
+
+		CurrencyPrecision: "auto",
+		PercentPrecision:  2,
+		ApproxSigFigs:     2,
+
+		Prefer:            "off",
+		PreferLength:      "off",
+		PreferMass:        "off",
+		PreferTemperature: "off",
+		PreferVolume:      "off",
+		PreferSpeed:       "off",
+		PreferArea:        "off",
+
+		RateForm:        "original",
+		CurrencyDisplay: "symbol",
+		MonthArithmetic: "clamp",
+		Durations:       "units",
+
+		InformalUnits:  true,
+		ShadowWarnings: true,
+		Tips:           true,
+		Few:            parser.DefaultFew,
+
+		MaxLineLength: 100000,
+		MaxTokens:     10000,
+		MaxDepth:      parser.DefaultMaxDepth,
 	}
 }
 
-// Load loads settings from a file.
+// Load loads settings from a file. A file that is not valid JSON, such as
+// one left empty by a crash, is set aside with a warning and the defaults
+// are used, so a damaged file never stops calc starting.
 func Load(path string) (*Settings, error) {
 	s := Default()
 	s.ConfigPath = path
@@ -45,51 +154,70 @@ func Load(path string) (*Settings, error) {
 		return nil, err
 	}
 
-	if err := json.Unmarshal(data, s); err != nil {
-		return nil, err
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		s.LoadWarnings = append(s.LoadWarnings, config.SetAsideWarning("settings", path, err))
+		return s, nil
+	}
+	for key, msg := range raw {
+		// Aliases are a map rather than a single :set value
+		if key == "aliases" {
+			if err := json.Unmarshal(msg, &s.Aliases); err != nil {
+				s.LoadWarnings = append(s.LoadWarnings, fmt.Sprintf("ignoring aliases in %s: %s", path, err))
+			}
+			continue
+		}
+		if key == "tutorial" {
+			if err := json.Unmarshal(msg, &s.Tutorial); err != nil {
+				s.LoadWarnings = append(s.LoadWarnings, fmt.Sprintf("ignoring tutorial progress in %s: %s", path, err))
+			}
+			continue
+		}
+		st, ok := lookupJSON(key)
+		if !ok {
+			s.LoadWarnings = append(s.LoadWarnings, fmt.Sprintf("ignoring unknown setting %q in %s", key, path))
+			continue
+		}
+		var v any
+		if err := json.Unmarshal(msg, &v); err != nil {
+			return nil, err
+		}
+		if err := st.set(s, fmt.Sprint(v)); err != nil {
+			s.LoadWarnings = append(s.LoadWarnings, fmt.Sprintf("ignoring %s in %s: %s", key, path, err))
+		}
 	}
+	sort.Strings(s.LoadWarnings)
 
 	s.ConfigPath = path
 	return s, nil
 }
 
-// Save saves settings to a file.
-func (s *Settings) Save() error {
-	// Create directory if it doesn't exist
-	dir := filepath.Dir(s.ConfigPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return err
+// lookupJSON finds a setting by its key in the persisted settings file.
+func lookupJSON(key string) (Setting, bool) {
+	for _, st := range schema {
+		if st.JSON == key {
+			return st, true
+		}
 	}
+	return Setting{}, false
+}
 
+// Save saves settings to a file, creating its directory. The file is
+// replaced whole, so another calc reading it never sees half of it.
+func (s *Settings) Save() error {
 	data, err := json.MarshalIndent(s, "", "  ")
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(s.ConfigPath, data, 0644)
+	return config.WriteFile(s.ConfigPath, data)
 }
 
-// Set updates a setting by name.
+// Set updates a setting by name, rejecting unknown names and invalid values.
 func (s *Settings) Set(name, value string) error {
-	switch name {
-	case "precision":
-		var p int
-		if _, err := fmt.Sscanf(value, "%d", &p); err != nil {
-			return err
-		}
-		s.Precision = p
-	case "dateformat", "date_format":
-		s.DateFormat = value
-	case "currency":
-		s.Currency = value
-	case "locale":
-		s.Locale = value
-	case "fuzzy", "fuzzy_mode":
-		s.FuzzyMode = value == "on" || value == "true" || value == "1"
-	case "autocomplete":
-		s.Autocomplete = value == "on" || value == "true" || value == "1"
-	default:
-		return fmt.Errorf("unknown setting: %s", name)
+	st, ok := Lookup(name)
+	if !ok {
+		return UnknownSetting(name)
 	}
-	return nil
+	return st.set(s, value)
 }
@@ -1,7 +1,9 @@
 package settings
 
 import (
+	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 )
 
@@ -71,6 +73,55 @@ func TestLoadNonExistent(t *testing.T) {
 	}
 }
 
+func TestLoadDamagedFile(t *testing.T) {
+	// A crash mid-write once left settings.json empty
+	path := filepath.Join(t.TempDir(), "settings.json")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Load(path)
+	if err != nil {
+		t.Fatalf("a damaged file should not stop settings loading: %v", err)
+	}
+	if s.Precision != Default().Precision || len(s.LoadWarnings) != 1 {
+		t.Fatalf("expected the defaults and one warning, got precision %d and %v", s.Precision, s.LoadWarnings)
+	}
+	if matches, _ := filepath.Glob(path + ".bad-*"); len(matches) != 1 {
+		t.Errorf("the damaged file should be backed up, got %v", matches)
+	}
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := Load(path); len(s.LoadWarnings) != 0 {
+		t.Errorf("saving should replace the damaged file, got %v", s.LoadWarnings)
+	}
+}
+
+func TestConcurrentSaves(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s := Default()
+			s.ConfigPath = path
+			s.Precision = i
+			if err := s.Save(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	s, err := Load(path)
+	if err != nil || len(s.LoadWarnings) != 0 {
+		t.Fatalf("concurrent saves left a damaged file: %v %v", err, s.LoadWarnings)
+	}
+	if s.Precision < 0 || s.Precision > 9 {
+		t.Errorf("precision %d was not written by any saver", s.Precision)
+	}
+}
+
 func TestSet(t *testing.T) {
 	s := Default()
 
@@ -126,3 +177,21 @@ func TestSaveInvalidPath(t *testing.T) {
 		t.Error("Expected error saving to invalid path")
 	}
 }
+
+func TestLoadAliases(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	data := `{"precision": 3, "aliases": {"vat": {"body": "20%"}, "net": {"params": ["x"], "body": "decrease x by vat"}}}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.LoadWarnings) != 0 {
+		t.Fatalf("unexpected warnings: %v", s.LoadWarnings)
+	}
+	if s.Aliases["vat"].Body != "20%" || len(s.Aliases["net"].Params) != 1 {
+		t.Fatalf("aliases not loaded: %+v", s.Aliases)
+	}
+}
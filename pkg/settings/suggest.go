@@ -0,0 +1,53 @@
+package settings
+
+import (
+	"fmt"
+	"strings"
+)
+
+// UnknownSetting returns the error for a name that is no setting. When a key
+// is close enough to be what was meant, as precision is to precission, the
+// error suggests it before listing the valid keys.
+func UnknownSetting(name string) error {
+	if key, ok := closestKey(name); ok {
+		return fmt.Errorf("unknown setting: %s; did you mean %s? (valid settings: %s)", name, key, strings.Join(Keys(), ", "))
+	}
+	return fmt.Errorf("unknown setting: %s (valid settings: %s)", name, strings.Join(Keys(), ", "))
+}
+
+// closestKey finds the setting key or alias nearest to name, reporting false
+// when none is within two edits or the name is too short to judge.
+func closestKey(name string) (string, bool) {
+	name = strings.ToLower(name)
+	best, bestDist := "", 3
+	for _, st := range schema {
+		for _, candidate := range append([]string{st.Key}, st.Aliases...) {
+			if d := editDistance(name, candidate); d < bestDist && d*2 < len(name) {
+				best, bestDist = st.Key, d
+			}
+		}
+	}
+	return best, best != ""
+}
+
+// editDistance is the number of single-character insertions, deletions and
+// substitutions that turn a into b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
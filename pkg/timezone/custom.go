@@ -0,0 +1,164 @@
+package timezone
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andrewneudegg/calc/pkg/config"
+)
+
+// AddLocation adds a location for the IANA zone iana, replacing any location
+// of the same name, built in or not. The offset is taken from a known
+// location in the same zone, or else from the system's zone database.
+func (s *System) AddLocation(name, iana string) (*Location, error) {
+	name = strings.TrimSpace(name)
+	if locationKey(name) == "" {
+		return nil, fmt.Errorf("a location needs a name")
+	}
+	offset, ok := s.zoneOffset(iana)
+	if !ok {
+		zone, err := time.LoadLocation(iana)
+		if err != nil || iana == "" || strings.EqualFold(iana, "local") {
+			return nil, fmt.Errorf("unknown IANA time zone: %s", iana)
+		}
+		offset = standardOffset(zone)
+	}
+	loc := &Location{Name: name, IanaName: iana, Offset: offset, Custom: true}
+	s.own()
+	s.locations[locationKey(name)] = loc
+	s.custom[locationKey(name)] = loc
+	return loc, nil
+}
+
+// zoneOffset finds the offset of a built-in location in the zone iana.
+func (s *System) zoneOffset(iana string) (int, bool) {
+	for _, loc := range s.locations {
+		if !loc.Custom && strings.EqualFold(loc.IanaName, iana) {
+			return loc.Offset, true
+		}
+	}
+	return 0, false
+}
+
+// standardOffset returns a zone's offset outside daylight saving time, in
+// whole hours, matching the built-in list.
+func standardOffset(zone *time.Location) int {
+	year := time.Now().Year()
+	_, jan := time.Date(year, time.January, 1, 0, 0, 0, 0, zone).Zone()
+	_, jul := time.Date(year, time.July, 1, 0, 0, 0, 0, zone).Zone()
+	return min(jan, jul) / 3600
+}
+
+// Search returns the locations whose name contains query, ignoring case,
+// accents and punctuation, sorted by name.
+func (s *System) Search(query string) []*Location {
+	key := locationKey(query)
+	if key == "" {
+		return nil
+	}
+	var found []*Location
+	for k, loc := range s.locations {
+		if strings.Contains(k, key) {
+			found = append(found, loc)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].Name < found[j].Name })
+	return found
+}
+
+// SaveCustomLocations writes the locations added with AddLocation to path as
+// a JSON object of names to IANA zones, creating its directory if needed.
+// Only the locations added or changed since they were last loaded or saved
+// are written over what the file holds now, so locations another calc has
+// saved there meanwhile are kept.
+func (s *System) SaveCustomLocations(path string) error {
+	ours := s.customZones()
+	err := config.UpdateJSON(path, func(theirs map[string]string) map[string]string {
+		return config.MergeChanges(s.saved, ours, theirs)
+	})
+	if err == nil {
+		s.saved = ours
+	}
+	return err
+}
+
+// customZones returns the locations added with AddLocation, names to IANA zones.
+func (s *System) customZones() map[string]string {
+	zones := make(map[string]string, len(s.custom))
+	for _, loc := range s.custom {
+		zones[loc.Name] = loc.IanaName
+	}
+	return zones
+}
+
+// LoadCustomLocations adds the locations saved at path by
+// SaveCustomLocations, overriding built-in locations of the same name. A
+// missing file is not an error. A file that cannot be read, or entries naming
+// an unknown zone, are skipped and described in the returned warnings so that
+// a damaged file never stops calc starting; a file that is not JSON is set
+// aside.
+func (s *System) LoadCustomLocations(path string) []string {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return []string{fmt.Sprintf("ignoring custom locations: %s", err)}
+	}
+	var zones map[string]string
+	if err := json.Unmarshal(data, &zones); err != nil {
+		return []string{config.SetAsideWarning("custom locations", path, err)}
+	}
+	names := make([]string, 0, len(zones))
+	for name := range zones {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var warnings []string
+	for _, name := range names {
+		if _, err := s.AddLocation(name, zones[name]); err != nil {
+			warnings = append(warnings, fmt.Sprintf("ignoring custom location %s in %s: %s", name, path, err))
+		}
+	}
+	s.saved = s.customZones()
+	return warnings
+}
+
+// locationKey reduces a location name to the form used for lookups, so that
+// "São Paulo", "sao paulo" and "Sao-Paulo" are the same place and "St. John's"
+// matches "st johns".
+func locationKey(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r == '.' || r == '\'' || r == '’':
+			continue
+		case r == '-' || r == '_' || r == ',':
+			b.WriteByte(' ')
+		default:
+			if plain, ok := foldedLetters[r]; ok {
+				b.WriteString(plain)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// foldedLetters spells accented Latin letters without their accents.
+var foldedLetters = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ą': "a",
+	'æ': "ae", 'ç': "c", 'ć': "c", 'č': "c", 'đ': "d", 'ď': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ę': "e", 'ě': "e", 'ğ': "g",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ī': "i", 'ı': "i", 'ł': "l",
+	'ñ': "n", 'ń': "n", 'ň': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'œ': "oe",
+	'ř': "r", 'ś': "s", 'š': "s", 'ş': "s", 'ß': "ss", 'ť': "t", 'ţ': "t",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ū': "u", 'ů': "u",
+	'ý': "y", 'ÿ': "y", 'ź': "z", 'ż': "z", 'ž': "z",
+}
@@ -0,0 +1,137 @@
+package timezone
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLookupIgnoresAccentsAndPunctuation(t *testing.T) {
+	s := NewSystem()
+	for _, name := range []string{"São Paulo", "sao paulo", "Sao-Paulo", "SAINT JOHNS", "saint john's"} {
+		if _, err := s.GetLocation(name); err != nil {
+			t.Errorf("GetLocation(%q): %v", name, err)
+		}
+	}
+}
+
+func TestAddLocation(t *testing.T) {
+	s := NewSystem()
+	loc, err := s.AddLocation("Springfield", "America/Chicago")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loc.Offset != -6 || !loc.Custom {
+		t.Errorf("got %+v, want a custom location at UTC-6", loc)
+	}
+	if got, err := s.GetLocation("springfield"); err != nil || got != loc {
+		t.Errorf("GetLocation(springfield) = %v, %v", got, err)
+	}
+
+	if _, err := s.AddLocation("Atlantis", "Atlantic/Atlantis"); err == nil {
+		t.Error("adding an unknown zone should fail")
+	}
+	if _, err := s.AddLocation(" ", "Europe/London"); err == nil {
+		t.Error("adding a location without a name should fail")
+	}
+}
+
+func TestCustomLocationsOverrideBuiltins(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calc", "locations.json")
+	s := NewSystem()
+	if _, err := s.AddLocation("Perth", "Europe/London"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.AddLocation("Springfield", "America/Chicago"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveCustomLocations(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// A new session picks the user's entries over the built-in ones
+	s = NewSystem()
+	if warnings := s.LoadCustomLocations(path); len(warnings) > 0 {
+		t.Fatalf("unexpected warnings: %v", warnings)
+	}
+	perth, err := s.GetLocation("perth")
+	if err != nil || perth.IanaName != "Europe/London" || perth.Offset != 0 {
+		t.Errorf("Perth = %+v, %v; want the user's Europe/London", perth, err)
+	}
+
+	var names []string
+	for _, loc := range s.Search("spring") {
+		names = append(names, loc.Name)
+	}
+	if len(names) != 1 || names[0] != "Springfield" {
+		t.Errorf("Search(spring) = %v", names)
+	}
+	if found := s.Search("tokyo"); len(found) != 1 || found[0].Custom {
+		t.Errorf("Search(tokyo) = %v, want the built-in location", found)
+	}
+}
+
+func TestLoadCustomLocationsSkipsBadEntries(t *testing.T) {
+	s := NewSystem()
+	if warnings := s.LoadCustomLocations(filepath.Join(t.TempDir(), "missing.json")); warnings != nil {
+		t.Errorf("missing file gave warnings %v", warnings)
+	}
+
+	path := filepath.Join(t.TempDir(), "locations.json")
+	os.WriteFile(path, []byte(`{"Springfield": "America/Chicago", "Atlantis": "Atlantic/Atlantis"}`), 0644)
+	warnings := s.LoadCustomLocations(path)
+	if len(warnings) != 1 {
+		t.Errorf("warnings = %v, want one for Atlantis", warnings)
+	}
+	if _, err := s.GetLocation("Springfield"); err != nil {
+		t.Errorf("good entry was not loaded: %v", err)
+	}
+
+	os.WriteFile(path, []byte(`not json`), 0644)
+	if warnings := s.LoadCustomLocations(path); len(warnings) != 1 {
+		t.Errorf("warnings = %v, want one for the damaged file", warnings)
+	}
+}
+
+func TestAddedLocationsStayInTheirSystem(t *testing.T) {
+	a, b := NewSystem(), NewSystem()
+	if _, err := a.AddLocation("Springfield", "America/Chicago"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.GetLocation("Springfield"); err == nil {
+		t.Error("Springfield leaked into another system")
+	}
+	if _, err := NewSystem().GetLocation("Springfield"); err == nil {
+		t.Error("Springfield leaked into a new system")
+	}
+}
+
+// TestSaveCustomLocationsKeepsOtherWriters has two calcs started on the same
+// file each add a location and save, checking that neither loses the other's.
+func TestSaveCustomLocationsKeepsOtherWriters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "locations.json")
+	first, second := NewSystem(), NewSystem()
+	first.LoadCustomLocations(path)
+	second.LoadCustomLocations(path)
+
+	if _, err := first.AddLocation("Perth", "Europe/London"); err != nil {
+		t.Fatal(err)
+	}
+	if err := first.SaveCustomLocations(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := second.AddLocation("Springfield", "America/Chicago"); err != nil {
+		t.Fatal(err)
+	}
+	if err := second.SaveCustomLocations(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := NewSystem()
+	loaded.LoadCustomLocations(path)
+	for _, name := range []string{"perth", "springfield"} {
+		if loc, err := loaded.GetLocation(name); err != nil || !loc.Custom {
+			t.Errorf("%s = %+v, %v; want the saved location", name, loc, err)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+// Package tutorial runs the guided lessons of the REPL's :tutorial command.
+// Lessons are data, in lessons.json: each step asks for a calculation and
+// checks the answer by comparing its value with that of an expected
+// expression, so any way of writing the right answer passes.
+package tutorial
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/andrewneudegg/calc/pkg/evaluator"
+	"github.com/andrewneudegg/calc/pkg/lexer"
+)
+
+//go:embed lessons.json
+var lessonsJSON []byte
+
+// Lesson is a short sequence of steps on one topic.
+type Lesson struct {
+	Topic string `json:"topic"` // Name used with :tutorial <topic>
+	Title string `json:"title"`
+	Steps []Step `json:"steps"`
+}
+
+// Step asks for one calculation.
+type Step struct {
+	Say    string `json:"say"`    // What to try
+	Expect string `json:"expect"` // An expression whose value the answer must have
+	// Uses are words the answer must contain, so that 1000 cm does not pass
+	// for a conversion of 10 m. Alternatives are separated by |, as in "in|to".
+	Uses  []string `json:"uses,omitempty"`
+	Hints []string `json:"hints,omitempty"` // Shown in turn after wrong answers
+	Note  string   `json:"note,omitempty"`  // Shown once the step is passed
+}
+
+// Lessons returns the built-in lessons, in the order they are taught.
+func Lessons() ([]Lesson, error) {
+	var lessons []Lesson
+	if err := json.Unmarshal(lessonsJSON, &lessons); err != nil {
+		return nil, fmt.Errorf("reading lessons: %w", err)
+	}
+	for _, l := range lessons {
+		if l.Topic == "" || len(l.Steps) == 0 {
+			return nil, fmt.Errorf("reading lessons: lesson %q needs a topic and at least one step", l.Title)
+		}
+	}
+	return lessons, nil
+}
+
+// Runner takes someone through the lessons, one step at a time.
+type Runner struct {
+	lessons []Lesson
+	eval    func(input string) evaluator.Value // Evaluates expected answers
+	done    map[string]bool                    // Topics completed, in this or earlier sessions
+	lesson  int                                // Index of the running lesson, or -1
+	step    int
+	misses  int // Wrong answers to the current step
+}
+
+// NewRunner returns a runner for lessons that works out expected answers
+// with eval. completed lists the topics finished in earlier sessions.
+func NewRunner(lessons []Lesson, eval func(input string) evaluator.Value, completed []string) *Runner {
+	r := &Runner{lessons: lessons, eval: eval, done: make(map[string]bool), lesson: -1}
+	for _, topic := range completed {
+		r.done[strings.ToLower(topic)] = true
+	}
+	return r
+}
+
+// Active reports whether a lesson is running, so answers should be checked.
+func (r *Runner) Active() bool {
+	return r.lesson >= 0
+}
+
+// Completed returns the topics completed, in lesson order.
+func (r *Runner) Completed() []string {
+	var topics []string
+	for _, l := range r.lessons {
+		if r.done[l.Topic] {
+			topics = append(topics, l.Topic)
+		}
+	}
+	return topics
+}
+
+// Command runs :tutorial with its arguments: nothing to start the next
+// lesson not yet completed, or repeat the current step; list, stop or skip;
+// or a topic to start that lesson.
+func (r *Runner) Command(args string) string {
+	switch arg := strings.ToLower(strings.TrimSpace(args)); arg {
+	case "":
+		if r.Active() {
+			return r.prompt()
+		}
+		for i, l := range r.lessons {
+			if !r.done[l.Topic] {
+				return r.start(i)
+			}
+		}
+		return "every lesson is complete; :tutorial <topic> repeats one\n" + r.List()
+	case "list":
+		return r.List()
+	case "stop":
+		if !r.Active() {
+			return "no lesson is running"
+		}
+		r.lesson = -1
+		return "tutorial stopped; :tutorial picks up with the next lesson"
+	case "skip":
+		if !r.Active() {
+			return "no lesson is running"
+		}
+		answer := r.current().Expect
+		return fmt.Sprintf("skipped (one answer is %s)\n", answer) + r.advance()
+	default:
+		for i, l := range r.lessons {
+			if l.Topic == arg {
+				return r.start(i)
+			}
+		}
+		return fmt.Sprintf("unknown lesson %q; :tutorial list shows them", arg)
+	}
+}
+
+// List describes each lesson and whether it is complete.
+func (r *Runner) List() string {
+	var b strings.Builder
+	b.WriteString("Lessons:")
+	for i, l := range r.lessons {
+		status := ""
+		switch {
+		case i == r.lesson:
+			status = fmt.Sprintf("  (step %d of %d)", r.step+1, len(l.Steps))
+		case r.done[l.Topic]:
+			status = "  done"
+		}
+		fmt.Fprintf(&b, "\n  %-12s %s, %d steps%s", l.Topic, l.Title, len(l.Steps), status)
+	}
+	return b.String()
+}
+
+// Answer checks the value a line gave against the current step and says
+// how it went, moving to the next step when it is right. finished reports
+// that the answer completed a lesson, whose topic is now in Completed.
+func (r *Runner) Answer(input string, got evaluator.Value) (reply string, finished bool) {
+	if !r.Active() {
+		return "", false
+	}
+	step := r.current()
+	want := r.eval(step.Expect)
+	if want.IsError() {
+		return fmt.Sprintf("this step's answer could not be worked out (%s); :tutorial skip moves on", want.Error), false
+	}
+	if got.IsError() || !Same(got, want) || !uses(input, step.Uses) {
+		return r.miss(got), false
+	}
+
+	reply = "Correct!"
+	if step.Note != "" {
+		reply += " " + step.Note
+	}
+	next := r.advance()
+	return reply + "\n" + next, !r.Active()
+}
+
+// miss counts a wrong answer and gives the next hint, or the answer once
+// the hints run out.
+func (r *Runner) miss(got evaluator.Value) string {
+	step := r.current()
+	r.misses++
+	reply := "Not quite."
+	if got.IsError() {
+		reply = "That gave an error."
+	}
+	if r.misses <= len(step.Hints) {
+		return reply + " Hint: " + step.Hints[r.misses-1]
+	}
+	return fmt.Sprintf("%s One answer is %s (or :tutorial skip to move on).", reply, step.Expect)
+}
+
+// start begins lesson i at its first step.
+func (r *Runner) start(i int) string {
+	r.lesson, r.step, r.misses = i, 0, 0
+	l := r.lessons[i]
+	return fmt.Sprintf("Lesson: %s (%d steps; :tutorial stop to leave)\n", l.Title, len(l.Steps)) + r.prompt()
+}
+
+// advance moves to the next step, completing the lesson after its last one.
+func (r *Runner) advance() string {
+	r.step++
+	r.misses = 0
+	l := r.lessons[r.lesson]
+	if r.step < len(l.Steps) {
+		return r.prompt()
+	}
+	r.done[l.Topic] = true
+	r.lesson = -1
+	msg := fmt.Sprintf("Lesson complete: %s.", l.Title)
+	for _, next := range r.lessons {
+		if !r.done[next.Topic] {
+			return msg + fmt.Sprintf(" Next: :tutorial %s", next.Topic)
+		}
+	}
+	return msg + " That was the last lesson."
+}
+
+func (r *Runner) current() Step {
+	return r.lessons[r.lesson].Steps[r.step]
+}
+
+// prompt shows the current step.
+func (r *Runner) prompt() string {
+	l := r.lessons[r.lesson]
+	return fmt.Sprintf("[%s %d/%d] %s", l.Topic, r.step+1, len(l.Steps), r.current().Say)
+}
+
+// uses reports whether input contains each of the words, as whole tokens,
+// ignoring case. A word may list alternatives separated by |.
+func uses(input string, words []string) bool {
+	seen := make(map[string]bool)
+	for _, tok := range lexer.New(input).AllTokens() {
+		seen[strings.ToLower(tok.Literal)] = true
+	}
+	for _, word := range words {
+		found := false
+		for _, alt := range strings.Split(word, "|") {
+			found = found || seen[strings.ToLower(alt)]
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Same reports whether two values are the same answer: the same kind of
+// value, in the same unit or currency, and equal but for float error.
+func Same(a, b evaluator.Value) bool {
+	if a.Type != b.Type || !strings.EqualFold(a.Unit, b.Unit) || !strings.EqualFold(a.Currency, b.Currency) {
+		return false
+	}
+	switch a.Type {
+	case evaluator.ValueDate:
+		return a.Date.Equal(b.Date)
+	case evaluator.ValueString:
+		return a.Text == b.Text
+	case evaluator.ValueList:
+		if len(a.Items) != len(b.Items) {
+			return false
+		}
+		for i := range a.Items {
+			if !Same(a.Items[i], b.Items[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return math.Abs(a.Number-b.Number) <= 1e-9*math.Max(1, math.Abs(b.Number))
+}
@@ -0,0 +1,137 @@
+package tutorial
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrewneudegg/calc/pkg/evaluator"
+	"github.com/andrewneudegg/calc/pkg/lexer"
+	"github.com/andrewneudegg/calc/pkg/parser"
+)
+
+// session returns a function that evaluates lines in one environment, as
+// the REPL does, so names set on one line can be used on the next.
+func session() func(string) evaluator.Value {
+	env := evaluator.NewEnvironment()
+	ev := evaluator.New(env)
+	return func(input string) evaluator.Value {
+		expr, err := parser.New(lexer.NewWithUnits(input, env.IsUnit).AllTokens()).Parse()
+		if err != nil {
+			return evaluator.NewError(err.Error())
+		}
+		return ev.Eval(expr)
+	}
+}
+
+func loadLessons(t *testing.T) []Lesson {
+	t.Helper()
+	lessons, err := Lessons()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return lessons
+}
+
+// Every lesson can be completed by typing what its last hint suggests,
+// which checks the lesson data as well as the runner.
+func TestEveryLessonCanBeCompleted(t *testing.T) {
+	lessons := loadLessons(t)
+	topics := map[string]bool{}
+	for _, l := range lessons {
+		topics[l.Topic] = true
+	}
+	for _, want := range []string{"arithmetic", "units", "currency", "dates", "percentages", "scripting"} {
+		if !topics[want] {
+			t.Errorf("no %s lesson", want)
+		}
+	}
+
+	for _, l := range lessons {
+		t.Run(l.Topic, func(t *testing.T) {
+			eval := session()
+			r := NewRunner(lessons, eval, nil)
+			r.Command(l.Topic)
+			for i, step := range l.Steps {
+				hint := step.Hints[len(step.Hints)-1]
+				input, ok := strings.CutPrefix(hint, "Type ")
+				if !ok {
+					t.Fatalf("step %d: last hint %q does not give an answer", i+1, hint)
+				}
+				reply, finished := r.Answer(input, eval(input))
+				if !strings.HasPrefix(reply, "Correct!") {
+					t.Fatalf("step %d: %s: %s", i+1, input, reply)
+				}
+				if finished != (i == len(l.Steps)-1) {
+					t.Errorf("step %d: finished = %v", i+1, finished)
+				}
+			}
+			if done := r.Completed(); len(done) != 1 || done[0] != l.Topic {
+				t.Errorf("completed %v, want [%s]", done, l.Topic)
+			}
+		})
+	}
+}
+
+func TestRunnerHintsAndAnswers(t *testing.T) {
+	eval := session()
+	r := NewRunner(loadLessons(t), eval, []string{"arithmetic"})
+
+	// The first lesson not completed starts
+	if got := r.Command(""); !strings.Contains(got, "Lesson: Units") || !strings.Contains(got, "[units 1/4] Write a unit after a number") {
+		t.Fatalf("start: %q", got)
+	}
+
+	steps := []struct {
+		input string
+		want  string
+	}{
+		{"10 m in mm", "Not quite. Hint: Use in (or to) followed by the unit you want."},
+		// The right value without a conversion does not pass
+		{"1000 cm", "Not quite. Hint: Type 10 m in cm"},
+		{"10 m in", "That gave an error. One answer is 10 m in cm (or :tutorial skip to move on)."},
+		// Any way of writing the answer does
+		{"1 dam to cm", "Correct!\n[units 2/4] Units of the same kind add up. Add 1 km and 250 m."},
+	}
+	for _, s := range steps {
+		if got, _ := r.Answer(s.input, eval(s.input)); got != s.want {
+			t.Errorf("%s: got %q, want %q", s.input, got, s.want)
+		}
+	}
+
+	if got := r.Command(""); got != "[units 2/4] Units of the same kind add up. Add 1 km and 250 m." {
+		t.Errorf("repeat: %q", got)
+	}
+	if got := r.Command("skip"); !strings.HasPrefix(got, "skipped (one answer is 1.25 km)\n[units 3/4]") {
+		t.Errorf("skip: %q", got)
+	}
+	if got := r.List(); !strings.Contains(got, "arithmetic   Arithmetic, 4 steps  done") || !strings.Contains(got, "units        Units, 4 steps  (step 3 of 4)") {
+		t.Errorf("list:\n%s", got)
+	}
+	if got := r.Command("stop"); !strings.Contains(got, "stopped") || r.Active() {
+		t.Errorf("stop: %q", got)
+	}
+	if reply, _ := r.Answer("70 kg in lb", eval("70 kg in lb")); reply != "" {
+		t.Errorf("answer with no lesson running: %q", reply)
+	}
+	if got := r.Command("geometry"); got != `unknown lesson "geometry"; :tutorial list shows them` {
+		t.Errorf("unknown: %q", got)
+	}
+}
+
+func TestSame(t *testing.T) {
+	tests := []struct {
+		a, b evaluator.Value
+		want bool
+	}{
+		{evaluator.NewNumber(0.1 + 0.2), evaluator.NewNumber(0.3), true},
+		{evaluator.NewUnit(5, "KM"), evaluator.NewUnit(5, "km"), true},
+		{evaluator.NewUnit(5, "km"), evaluator.NewUnit(5000, "m"), false},
+		{evaluator.NewCurrency(10, "GBP"), evaluator.NewNumber(10), false},
+		{evaluator.NewCurrency(10, "GBP"), evaluator.NewCurrency(10, "USD"), false},
+	}
+	for _, tt := range tests {
+		if got := Same(tt.a, tt.b); got != tt.want {
+			t.Errorf("Same(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package units
+
+import (
+	"sort"
+	"strings"
+)
+
+// Dimensions returns every dimension that has at least one unit, including
+// custom units, in declaration order.
+func (s *System) Dimensions() []Dimension {
+	seen := map[Dimension]bool{}
+	for _, u := range s.units {
+		seen[u.Dimension] = true
+	}
+	dims := make([]Dimension, 0, len(seen))
+	for d := range seen {
+		dims = append(dims, d)
+	}
+	sort.Slice(dims, func(i, j int) bool { return dims[i] < dims[j] })
+	return dims
+}
+
+// ParseDimension finds a dimension by the name String gives it, ignoring case.
+func ParseDimension(name string) (Dimension, bool) {
+	for d, n := range dimensionNames {
+		if strings.EqualFold(n, name) && d != DimensionNone {
+			return d, true
+		}
+	}
+	return DimensionNone, false
+}
+
+// UnitsIn returns one unit for each distinct unit measuring dim, smallest
+// first. Names for the same unit, such as m, metre and meters, appear once,
+// under the name registered first; Aliases lists the others.
+func (s *System) UnitsIn(dim Dimension) []*Unit {
+	var out []*Unit
+	for _, g := range s.groups() {
+		if g[0].Dimension == dim {
+			out = append(out, g[0])
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].ToBase < out[j].ToBase })
+	return out
+}
+
+// Aliases returns every name for the same unit as name, starting with the
+// one UnitsIn lists, in registration order. It returns nil for an unknown unit.
+func (s *System) Aliases(name string) []string {
+	u, ok := s.units[strings.ToLower(name)]
+	if !ok {
+		return nil
+	}
+	for _, g := range s.groups() {
+		if keyOf(g[0]) == keyOf(u) {
+			return groupNames(g)
+		}
+	}
+	return nil
+}
+
+// Search finds the units with a name or alias matching query, ignoring case,
+// returning each under the name UnitsIn uses. Names containing query come
+// first, then names holding its letters in order, so "gall" finds gallon and
+// "mtr" finds metre.
+func (s *System) Search(query string) []*Unit {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return nil
+	}
+	var contains, fuzzy []*Unit
+	for _, g := range s.groups() {
+		var hit, near bool
+		for _, name := range groupNames(g) {
+			name = strings.ToLower(name)
+			hit = hit || strings.Contains(name, query)
+			near = near || isSubsequence(query, name)
+		}
+		switch {
+		case hit:
+			contains = append(contains, g[0])
+		case near:
+			fuzzy = append(fuzzy, g[0])
+		}
+	}
+	return append(contains, fuzzy...)
+}
+
+// aliasKey identifies the unit behind a name: names with the same dimension,
+// base unit and factor are spellings of one unit.
+type aliasKey struct {
+	dim    Dimension
+	base   string
+	toBase float64
+}
+
+func keyOf(u *Unit) aliasKey {
+	return aliasKey{u.Dimension, u.BaseUnit, u.ToBase}
+}
+
+// groups gathers the registered names into one group per underlying unit,
+// each in registration order and the groups ordered by their first name.
+func (s *System) groups() [][]*Unit {
+	if s.grouped != nil {
+		return s.grouped
+	}
+	all := make([]*Unit, 0, len(s.units))
+	for _, u := range s.units {
+		all = append(all, u)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].seq < all[j].seq })
+
+	var groups [][]*Unit
+	index := map[aliasKey]int{}
+	for _, u := range all {
+		i, ok := index[keyOf(u)]
+		if !ok {
+			i = len(groups)
+			index[keyOf(u)] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], u)
+	}
+	s.grouped = groups
+	return groups
+}
+
+// groupNames returns the names of a group's units.
+func groupNames(g []*Unit) []string {
+	names := make([]string, len(g))
+	for i, u := range g {
+		names[i] = u.Name
+	}
+	return names
+}
+
+// isSubsequence reports whether the letters of sub appear in s in order.
+func isSubsequence(sub, s string) bool {
+	rs := []rune(s)
+	i := 0
+	for _, r := range sub {
+		for i < len(rs) && rs[i] != r {
+			i++
+		}
+		if i == len(rs) {
+			return false
+		}
+		i++
+	}
+	return true
+}
@@ -0,0 +1,103 @@
+package units
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAliasesGroupSpellingsOfOneUnit(t *testing.T) {
+	s := NewSystem()
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"metres", []string{"m", "metre", "metres", "meter", "meters"}},
+		{"c", []string{"c", "celsius"}},
+		{"f", []string{"f", "fahrenheit"}},
+	}
+	for _, tt := range tests {
+		if got := s.Aliases(tt.name); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Aliases(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if got := s.Aliases("furlongs"); got != nil {
+		t.Errorf("Aliases of an unknown unit = %v, want nil", got)
+	}
+}
+
+func TestUnitsInListsEachUnitOnceSmallestFirst(t *testing.T) {
+	s := NewSystem()
+	got := s.UnitsIn(DimensionLength)
+	var names []string
+	for _, u := range got {
+		if u.Dimension != DimensionLength {
+			t.Errorf("%s is not a length unit", u.Name)
+		}
+		names = append(names, u.Name)
+	}
+	if names[0] != "mm" || names[1] != "cm" {
+		t.Errorf("expected mm then cm first, got %v", names)
+	}
+	for i := 1; i < len(got); i++ {
+		if got[i].ToBase < got[i-1].ToBase {
+			t.Errorf("%s listed after the larger %s", got[i].Name, got[i-1].Name)
+		}
+	}
+	for _, n := range names {
+		if n == "metre" || n == "meters" {
+			t.Errorf("alias %s listed as a separate unit", n)
+		}
+	}
+}
+
+func TestCatalogIncludesCustomUnits(t *testing.T) {
+	s := NewSystem()
+	if err := s.AddCustomUnit("rackunit", 44.45, "mm"); err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, u := range s.UnitsIn(DimensionLength) {
+		found = found || u.Name == "rackunit"
+	}
+	if !found {
+		t.Error("UnitsIn(length) does not include the custom unit")
+	}
+	if got := s.Search("rack"); len(got) != 1 || got[0].Name != "rackunit" {
+		t.Errorf("Search(rack) = %v, want rackunit", got)
+	}
+
+	s.RemoveCustomUnit("rackunit")
+	if got := s.Search("rack"); len(got) != 0 {
+		t.Errorf("removed unit still found: %v", got)
+	}
+}
+
+func TestSearchRanksSubstringsBeforeFuzzyMatches(t *testing.T) {
+	s := NewSystem()
+	got := s.Search("gall")
+	if len(got) < 2 || got[0].Name != "usgal" || got[1].Name != "ukgal" {
+		t.Fatalf("Search(gall) = %v, want the US then UK gallon", got)
+	}
+
+	got = s.Search("mtr")
+	if len(got) == 0 || got[0].Name != "m" {
+		t.Errorf("Search(mtr) should find metre first, got %v", got)
+	}
+}
+
+func TestDimensionsAndParseDimension(t *testing.T) {
+	s := NewSystem()
+	dims := s.Dimensions()
+	if len(dims) == 0 || dims[0] != DimensionLength {
+		t.Fatalf("Dimensions() = %v, want length first", dims)
+	}
+	for _, d := range dims {
+		got, ok := ParseDimension(d.String())
+		if !ok || got != d {
+			t.Errorf("ParseDimension(%q) = %v, %v", d.String(), got, ok)
+		}
+	}
+	if _, ok := ParseDimension("none"); ok {
+		t.Error("ParseDimension should not accept none")
+	}
+}
@@ -0,0 +1,117 @@
+package units
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/andrewneudegg/calc/pkg/config"
+)
+
+// RemoveCustomUnit deletes a custom unit so that its name is no longer
+// recognised. It reports false when name is not a custom unit.
+func (s *System) RemoveCustomUnit(name string) bool {
+	name = strings.ToLower(name)
+	u, ok := s.custom[name]
+	if !ok {
+		return false
+	}
+	delete(s.custom, name)
+	s.grouped = nil
+	if s.units[name] == u {
+		s.own()
+		delete(s.units, name)
+		// A redefined informal unit goes back to the built-in measure
+		if std, ok := standardSystem().units[name]; ok {
+			s.units[name] = std
+		}
+	}
+	return true
+}
+
+// SaveCustomUnits saves the custom units to path as a units pack, creating
+// its directory if needed. Only the units and ingredients defined, changed
+// or deleted since they were last loaded or saved are written over what the
+// file holds now, so units another calc has saved there meanwhile are kept.
+func (s *System) SaveCustomUnits(path string) error {
+	ours := s.ExportPack()
+	err := config.UpdateJSON(path, func(theirs Pack) Pack {
+		return mergePacks(s.saved, ours, theirs)
+	})
+	if err == nil {
+		s.saved = ours
+	}
+	return err
+}
+
+// mergePacks applies the changes from base to ours to theirs, a pack another
+// calc may have saved since.
+func mergePacks(base, ours, theirs Pack) Pack {
+	units := config.MergeChanges(packUnits(base), packUnits(ours), packUnits(theirs))
+	ingredients := config.MergeChanges(packIngredients(base), packIngredients(ours), packIngredients(theirs))
+	p := Pack{Version: PackVersion, Units: []PackUnit{}}
+	for _, name := range slices.Sorted(maps.Keys(units)) {
+		p.Units = append(p.Units, units[name])
+	}
+	for _, name := range slices.Sorted(maps.Keys(ingredients)) {
+		p.Ingredients = append(p.Ingredients, PackIngredient{Name: name, Density: ingredients[name]})
+	}
+	return p
+}
+
+// packUnits returns the units of p by lower-case name.
+func packUnits(p Pack) map[string]PackUnit {
+	m := make(map[string]PackUnit, len(p.Units))
+	for _, pu := range p.Units {
+		m[strings.ToLower(pu.Name)] = pu
+	}
+	return m
+}
+
+// packIngredients returns the densities of the ingredients of p by lower-case name.
+func packIngredients(p Pack) map[string]float64 {
+	m := make(map[string]float64, len(p.Ingredients))
+	for _, pi := range p.Ingredients {
+		m[strings.ToLower(pi.Name)] = pi.Density
+	}
+	return m
+}
+
+// StartFrom adds the custom units and ingredients of p, exported from a
+// system that loaded or saved them, as if this system had loaded them
+// itself, so that saving writes only what changes here.
+func (s *System) StartFrom(p Pack) {
+	s.ImportPack(p, true)
+	s.saved = s.ExportPack()
+}
+
+// LoadCustomUnits adds the custom units saved at path by SaveCustomUnits,
+// replacing any of the same name. A missing file is not an error. A file that
+// cannot be read, or entries that no longer resolve, are skipped and described
+// in the returned warnings so that a damaged file never stops calc starting.
+// A file that is not a units pack at all is set aside.
+func (s *System) LoadCustomUnits(path string) []string {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return []string{fmt.Sprintf("ignoring custom units: %s", err)}
+	}
+	pack, err := decodePack(path, data)
+	if err != nil {
+		return []string{config.SetAsideWarning("custom units", path, err)}
+	}
+	report, err := s.ImportPack(pack, true)
+	if err != nil {
+		return []string{fmt.Sprintf("ignoring custom units in %s: %s", path, err)}
+	}
+	s.saved = s.ExportPack()
+	var warnings []string
+	for _, f := range report.Failed {
+		warnings = append(warnings, fmt.Sprintf("ignoring custom unit %s in %s", f, path))
+	}
+	return warnings
+}
@@ -0,0 +1,149 @@
+package units
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCustomUnitsSaveLoadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config", "units.json")
+	src := NewSystem()
+	if err := src.AddCustomUnit("rackunit", 44.45, "mm"); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.AddCustomUnit("pallet", 500, "kg"); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.SaveCustomUnits(path); err != nil {
+		t.Fatalf("SaveCustomUnits: %v", err)
+	}
+
+	dst := NewSystem()
+	if w := dst.LoadCustomUnits(path); len(w) != 0 {
+		t.Fatalf("unexpected warnings: %v", w)
+	}
+	if !dst.IsCustomUnit("rackunit") || !dst.IsCustomUnit("pallet") {
+		t.Fatalf("custom units not loaded: %v", dst.CustomUnits())
+	}
+	got, err := dst.Convert(2, "pallet", "kg")
+	if err != nil || got != 1000 {
+		t.Errorf("2 pallet in kg = %v, %v; want 1000", got, err)
+	}
+}
+
+// TestSaveCustomUnitsKeepsOtherWriters has two calcs started on the same
+// file each define a unit and save, checking that neither loses the other's.
+func TestSaveCustomUnitsKeepsOtherWriters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "units.json")
+	first, second := NewSystem(), NewSystem()
+	first.LoadCustomUnits(path)
+	second.LoadCustomUnits(path)
+
+	if err := first.AddCustomUnit("rackunit", 44.45, "mm"); err != nil {
+		t.Fatal(err)
+	}
+	if err := first.SaveCustomUnits(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := second.AddCustomUnit("pallet", 500, "kg"); err != nil {
+		t.Fatal(err)
+	}
+	if err := second.SaveCustomUnits(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := NewSystem()
+	loaded.LoadCustomUnits(path)
+	if !loaded.IsCustomUnit("rackunit") || !loaded.IsCustomUnit("pallet") {
+		t.Fatalf("expected both units saved, got %v", loaded.CustomUnits())
+	}
+
+	// A deletion is saved too, without taking the other calc's unit with it
+	first.RemoveCustomUnit("rackunit")
+	if err := first.SaveCustomUnits(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded = NewSystem()
+	loaded.LoadCustomUnits(path)
+	if loaded.IsCustomUnit("rackunit") || !loaded.IsCustomUnit("pallet") {
+		t.Errorf("expected only pallet after deleting rackunit, got %v", loaded.CustomUnits())
+	}
+}
+
+func TestLoadCustomUnitsMissingFile(t *testing.T) {
+	s := NewSystem()
+	if w := s.LoadCustomUnits(filepath.Join(t.TempDir(), "units.json")); w != nil {
+		t.Fatalf("a missing file should load quietly, got %v", w)
+	}
+}
+
+func TestLoadCustomUnitsCorruptFile(t *testing.T) {
+	dir := t.TempDir()
+	bad := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(bad, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := NewSystem()
+	w := s.LoadCustomUnits(bad)
+	if len(w) != 1 || !strings.Contains(w[0], "not a units pack") {
+		t.Fatalf("expected a warning for bad JSON, got %v", w)
+	}
+	if _, err := os.Stat(bad); !os.IsNotExist(err) {
+		t.Error("a file that is not a units pack should be set aside")
+	}
+
+	// A bad entry is skipped without losing the rest
+	mixed := filepath.Join(dir, "mixed.json")
+	data := `{"version": 1, "units": [{"name": "rackunit", "value": 44.45, "base": "mm"}, {"name": "widget", "value": 1, "base": "nosuchunit"}]}`
+	if err := os.WriteFile(mixed, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	w = s.LoadCustomUnits(mixed)
+	if len(w) != 1 || !strings.Contains(w[0], "widget") {
+		t.Fatalf("expected a warning for the bad entry, got %v", w)
+	}
+	if !s.IsCustomUnit("rackunit") || s.IsCustomUnit("widget") {
+		t.Fatalf("only the valid entry should load: %v", s.CustomUnits())
+	}
+}
+
+func TestRemoveCustomUnit(t *testing.T) {
+	s := NewSystem()
+	if err := s.AddCustomUnit("rackunit", 44.45, "mm"); err != nil {
+		t.Fatal(err)
+	}
+	if !s.RemoveCustomUnit("RackUnit") {
+		t.Fatal("RemoveCustomUnit should report the unit was removed")
+	}
+	if s.IsCustomUnit("rackunit") {
+		t.Fatal("rackunit is still a custom unit")
+	}
+	if _, err := s.Convert(1, "rackunit", "mm"); err == nil {
+		t.Fatal("rackunit should no longer convert")
+	}
+	if s.RemoveCustomUnit("m") {
+		t.Fatal("built-in units cannot be removed")
+	}
+	if _, err := s.Convert(1, "m", "cm"); err != nil {
+		t.Fatalf("built-in unit lost: %v", err)
+	}
+}
+
+func TestCustomUnitsStayInTheirSystem(t *testing.T) {
+	a, b := NewSystem(), NewSystem()
+	if err := a.AddCustomUnit("smoot", 1.7018, "m"); err != nil {
+		t.Fatal(err)
+	}
+	if !a.IsUnit("smoot") {
+		t.Error("smoot missing from the system it was added to")
+	}
+	if b.IsUnit("smoot") || NewSystem().IsUnit("smoot") {
+		t.Error("smoot leaked into another system")
+	}
+	a.RemoveCustomUnit("smoot")
+	if !a.IsUnit("km") || !b.IsUnit("km") {
+		t.Error("standard units lost after removing a custom unit")
+	}
+}
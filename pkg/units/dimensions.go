@@ -0,0 +1,213 @@
+package units
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Positions of the SI base dimensions in a Vector.
+const (
+	BaseMass        = iota // M
+	BaseLength             // L
+	BaseTime               // T
+	BaseCurrent            // I
+	BaseTemperature        // Θ
+	BaseAmount             // N
+	BaseLuminosity         // J
+	baseCount
+)
+
+var baseSymbols = [baseCount]string{"M", "L", "T", "I", "Θ", "N", "J"}
+
+// Vector is a dimension written as exponents of the seven SI base dimensions,
+// so force is M·L·T⁻². Unlike Dimension it can describe the product or
+// quotient of two units, such as N·m or J/s.
+type Vector [baseCount]int
+
+// Named vectors for the dimensions that have an SI meaning.
+var (
+	VectorNone        = Vector{}
+	VectorLength      = Vector{BaseLength: 1}
+	VectorMass        = Vector{BaseMass: 1}
+	VectorTime        = Vector{BaseTime: 1}
+	VectorTemperature = Vector{BaseTemperature: 1}
+	VectorArea        = Vector{BaseLength: 2}
+	VectorVolume      = Vector{BaseLength: 3}
+	VectorSpeed       = Vector{BaseLength: 1, BaseTime: -1}
+	VectorForce       = Vector{BaseMass: 1, BaseLength: 1, BaseTime: -2}
+	VectorPressure    = Vector{BaseMass: 1, BaseLength: -1, BaseTime: -2}
+	VectorEnergy      = Vector{BaseMass: 1, BaseLength: 2, BaseTime: -2}
+	VectorPower       = Vector{BaseMass: 1, BaseLength: 2, BaseTime: -3}
+	VectorFrequency   = Vector{BaseTime: -1}
+)
+
+// siDimensions gives the vector of a Dimension and the factor that takes its
+// base unit to the coherent SI unit; litres, for example, are 0.001 m³. Data,
+// data rates and angles have no place in the SI base and are left out.
+var siDimensions = map[Dimension]struct {
+	vec   Vector
+	scale float64
+}{
+	DimensionNone:        {VectorNone, 1},
+	DimensionLength:      {VectorLength, 1},
+	DimensionMass:        {VectorMass, 1},
+	DimensionTime:        {VectorTime, 1},
+	DimensionTemperature: {VectorTemperature, 1},
+	DimensionArea:        {VectorArea, 1},
+	DimensionVolume:      {VectorVolume, 0.001},
+	DimensionSpeed:       {VectorSpeed, 1},
+	DimensionForce:       {VectorForce, 1},
+	DimensionPressure:    {VectorPressure, 1},
+	DimensionEnergy:      {VectorEnergy, 1},
+	DimensionPower:       {VectorPower, 1},
+	DimensionFrequency:   {VectorFrequency, 1},
+	DimensionCount:       {VectorNone, 1},
+	DimensionTorque:      {VectorEnergy, 1},
+}
+
+// derivedUnits are the named SI units that a product or quotient of units
+// simplifies to.
+var derivedUnits = []struct {
+	name string
+	vec  Vector
+}{
+	{"N", VectorForce},
+	{"J", VectorEnergy},
+	{"W", VectorPower},
+	{"Pa", VectorPressure},
+	{"Hz", VectorFrequency},
+}
+
+// Vector returns the SI exponent vector of d. It reports false for dimensions
+// outside the SI base, such as data.
+func (d Dimension) Vector() (Vector, bool) {
+	si, ok := siDimensions[d]
+	return si.vec, ok
+}
+
+// Vector returns the SI exponent vector of the unit. It reports false for
+// units whose dimension has none.
+func (u *Unit) Vector() (Vector, bool) {
+	return u.Dimension.Vector()
+}
+
+// Mul returns the dimension of a product of quantities with dimensions v and o.
+func (v Vector) Mul(o Vector) Vector {
+	for i := range v {
+		v[i] += o[i]
+	}
+	return v
+}
+
+// Div returns the dimension of a quotient of quantities with dimensions v and o.
+func (v Vector) Div(o Vector) Vector {
+	for i := range v {
+		v[i] -= o[i]
+	}
+	return v
+}
+
+// Pow returns the dimension of a quantity with dimension v raised to n.
+func (v Vector) Pow(n int) Vector {
+	for i := range v {
+		v[i] *= n
+	}
+	return v
+}
+
+// IsZero reports whether v is dimensionless.
+func (v Vector) IsZero() bool {
+	return v == VectorNone
+}
+
+// String writes v in base symbols, e.g. "M·L·T⁻²", or "1" when dimensionless.
+func (v Vector) String() string {
+	var parts []string
+	for i, exp := range v {
+		switch exp {
+		case 0:
+		case 1:
+			parts = append(parts, baseSymbols[i])
+		default:
+			parts = append(parts, baseSymbols[i]+superscript(exp))
+		}
+	}
+	if len(parts) == 0 {
+		return "1"
+	}
+	return strings.Join(parts, "·")
+}
+
+func superscript(n int) string {
+	digits := []rune("⁰¹²³⁴⁵⁶⁷⁸⁹")
+	var b strings.Builder
+	for _, r := range fmt.Sprint(n) {
+		if r == '-' {
+			b.WriteRune('⁻')
+		} else {
+			b.WriteRune(digits[r-'0'])
+		}
+	}
+	return b.String()
+}
+
+// DerivedUnit returns the name of the SI derived unit with dimension v, such
+// as "J" for M·L²·T⁻².
+func DerivedUnit(v Vector) (string, bool) {
+	for _, d := range derivedUnits {
+		if d.vec == v {
+			return d.name, true
+		}
+	}
+	return "", false
+}
+
+// UnitVector resolves a unit, or a compound built from units with · and / as
+// in "n·m" or "kg·m/s/s", to its dimension vector and the factor that converts
+// one of it to coherent SI units. Temperatures are refused because their
+// scales have offsets, as are units outside the SI base.
+func (s *System) UnitVector(unit string) (Vector, float64, error) {
+	var vec Vector
+	factor := 1.0
+	for i, part := range strings.Split(unit, "/") {
+		for _, name := range strings.Split(part, "·") {
+			v, f, err := s.factorVector(name)
+			if err != nil {
+				return VectorNone, 0, err
+			}
+			if i == 0 {
+				vec, factor = vec.Mul(v), factor*f
+			} else {
+				vec, factor = vec.Div(v), factor/f
+			}
+		}
+	}
+	return vec, factor, nil
+}
+
+// factorVector resolves a single unit of a compound, allowing a trailing ² or ³
+// on units that do not already include one, such as s².
+func (s *System) factorVector(name string) (Vector, float64, error) {
+	name = strings.TrimSpace(name)
+	power := 1
+	u, ok := s.lookup(name)
+	if !ok {
+		if base, found := strings.CutSuffix(name, "²"); found {
+			u, ok = s.lookup(base)
+			power = 2
+		} else if base, found := strings.CutSuffix(name, "³"); found {
+			u, ok = s.lookup(base)
+			power = 3
+		}
+	}
+	name = strings.ToLower(name)
+	if !ok {
+		return VectorNone, 0, &ErrUnknownUnit{Name: name}
+	}
+	si, ok := siDimensions[u.Dimension]
+	if !ok || u.Dimension == DimensionTemperature {
+		return VectorNone, 0, fmt.Errorf("%s has no SI dimension", name)
+	}
+	return si.vec.Pow(power), math.Pow(u.ToBase*si.scale, float64(power)), nil
+}
@@ -0,0 +1,175 @@
+package units
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDimensionVectors(t *testing.T) {
+	tests := []struct {
+		dim  Dimension
+		want Vector
+	}{
+		{DimensionLength, VectorLength},
+		{DimensionMass, VectorMass},
+		{DimensionTime, VectorTime},
+		{DimensionArea, Vector{BaseLength: 2}},
+		{DimensionVolume, Vector{BaseLength: 3}},
+		{DimensionSpeed, Vector{BaseLength: 1, BaseTime: -1}},
+		{DimensionForce, Vector{BaseMass: 1, BaseLength: 1, BaseTime: -2}},
+		{DimensionPressure, VectorForce.Div(VectorArea)},
+		{DimensionEnergy, VectorForce.Mul(VectorLength)},
+		{DimensionPower, VectorEnergy.Div(VectorTime)},
+		{DimensionFrequency, VectorNone.Div(VectorTime)},
+	}
+	for _, tt := range tests {
+		got, ok := tt.dim.Vector()
+		if !ok || got != tt.want {
+			t.Errorf("%s: got %s (ok=%v), want %s", tt.dim, got, ok, tt.want)
+		}
+	}
+
+	for _, dim := range []Dimension{DimensionData, DimensionDataRate, DimensionAngle} {
+		if _, ok := dim.Vector(); ok {
+			t.Errorf("%s should have no SI vector", dim)
+		}
+	}
+}
+
+func TestVectorString(t *testing.T) {
+	tests := []struct {
+		vec  Vector
+		want string
+	}{
+		{VectorNone, "1"},
+		{VectorLength, "L"},
+		{VectorForce, "M·L·T⁻²"},
+		{VectorPower, "M·L²·T⁻³"},
+		{VectorFrequency, "T⁻¹"},
+	}
+	for _, tt := range tests {
+		if got := tt.vec.String(); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestUnitVector(t *testing.T) {
+	s := NewSystem()
+
+	tests := []struct {
+		unit   string
+		vec    Vector
+		factor float64
+	}{
+		{"m", VectorLength, 1},
+		{"km", VectorLength, 1000},
+		{"l", VectorVolume, 0.001},
+		{"n·m", VectorEnergy, 1},
+		{"kw·hours", VectorEnergy, 3.6e6},
+		{"kg·m/s/s", VectorForce, 1},
+		{"kg·m/s²", VectorForce, 1},
+		{"n/m²", VectorPressure, 1},
+		{"j/min", VectorPower, 1.0 / 60},
+		{"km/h", VectorSpeed, 1000.0 / 3600},
+	}
+	for _, tt := range tests {
+		vec, factor, err := s.UnitVector(tt.unit)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tt.unit, err)
+			continue
+		}
+		if vec != tt.vec || math.Abs(factor-tt.factor) > 1e-9*tt.factor {
+			t.Errorf("%s: got %s × %g, want %s × %g", tt.unit, vec, factor, tt.vec, tt.factor)
+		}
+	}
+
+	for _, unit := range []string{"mb/s", "c·m", "deg·m", "furlong"} {
+		if _, _, err := s.UnitVector(unit); err == nil {
+			t.Errorf("%s: expected an error", unit)
+		}
+	}
+}
+
+func TestDerivedUnit(t *testing.T) {
+	tests := []struct {
+		vec  Vector
+		want string
+	}{
+		{VectorLength.Mul(VectorForce), "J"},
+		{VectorEnergy.Div(VectorTime), "W"},
+		{VectorForce.Div(VectorArea), "Pa"},
+		{VectorMass.Mul(VectorLength).Div(VectorTime.Pow(2)), "N"},
+		{VectorNone.Div(VectorTime), "Hz"},
+	}
+	for _, tt := range tests {
+		if got, ok := DerivedUnit(tt.vec); !ok || got != tt.want {
+			t.Errorf("%s: got %q (ok=%v), want %q", tt.vec, got, ok, tt.want)
+		}
+	}
+	if name, ok := DerivedUnit(VectorArea); ok {
+		t.Errorf("area should have no derived unit, got %q", name)
+	}
+}
+
+// TestBuiltinUnitsKeepConversions checks that every built-in unit still
+// converts to its base unit by its factor, and that units sharing a Dimension
+// share a vector.
+func TestBuiltinUnitsKeepConversions(t *testing.T) {
+	s := NewSystem()
+	for key, u := range s.units {
+		if u.Dimension == DimensionTemperature {
+			continue
+		}
+		got, err := s.Convert(1, key, u.BaseUnit)
+		if err != nil {
+			t.Errorf("%s: cannot convert to base %s: %s", key, u.BaseUnit, err)
+			continue
+		}
+		if math.Abs(got-u.ToBase) > 1e-12*u.ToBase {
+			t.Errorf("1 %s = %g %s, want %g", key, got, u.BaseUnit, u.ToBase)
+		}
+		base := s.units[u.BaseUnit]
+		if base == nil || base.Dimension != u.Dimension {
+			t.Errorf("%s: base unit %s is missing or measures something else", key, u.BaseUnit)
+			continue
+		}
+		uv, uok := u.Vector()
+		bv, bok := base.Vector()
+		if uv != bv || uok != bok {
+			t.Errorf("%s: vector %s differs from its base %s", key, uv, bv)
+		}
+	}
+}
+
+func TestEnergyAndPowerConversions(t *testing.T) {
+	s := NewSystem()
+
+	tests := []struct {
+		value    float64
+		from     string
+		to       string
+		expected float64
+	}{
+		{1, "kwh", "j", 3.6e6},
+		{1, "kwh", "kj", 3600},
+		{2500, "j", "kj", 2.5},
+		{1, "megajoule", "wh", 277.778},
+		{1, "mw", "kw", 1000},
+		{1500, "watts", "kilowatt", 1.5},
+	}
+	for _, tt := range tests {
+		result, err := s.Convert(tt.value, tt.from, tt.to)
+		if err != nil {
+			t.Errorf("conversion %g %s to %s failed: %s", tt.value, tt.from, tt.to, err)
+			continue
+		}
+		if math.Abs(result-tt.expected) > 0.001 {
+			t.Errorf("%g %s in %s: expected %g, got %g", tt.value, tt.from, tt.to, tt.expected, result)
+		}
+	}
+
+	if _, err := s.Convert(1, "kw", "kj"); err == nil {
+		t.Error("power should not convert to energy")
+	}
+}
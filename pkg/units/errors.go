@@ -0,0 +1,65 @@
+package units
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The errors below are returned by System's methods, possibly wrapped, so
+// callers can tell them apart with errors.As:
+//
+//	var unknown *units.ErrUnknownUnit
+//	if errors.As(err, &unknown) {
+//		// suggest a unit close to unknown.Name
+//	}
+
+// ErrUnknownUnit reports a unit name that is not registered and is not an
+// SI prefix on a unit that takes one.
+type ErrUnknownUnit struct {
+	Name string
+	// Role is what the unit was wanted for: "base" for the unit a custom
+	// unit is defined in, "numerator" or "denominator" for part of a
+	// compound unit, "temperature" for a temperature scale, or "" for a
+	// unit being converted.
+	Role string
+}
+
+func (e *ErrUnknownUnit) Error() string {
+	if e.Role != "" {
+		return fmt.Sprintf("unknown %s unit: %s", e.Role, e.Name)
+	}
+	return fmt.Sprintf("unknown unit '%s'", e.Name)
+}
+
+// ErrIncompatibleDimensions reports a conversion between units that measure
+// different things, such as kg to m.
+type ErrIncompatibleDimensions struct {
+	From, To string
+	// Part is "numerator" or "denominator" when the mismatch is in one part
+	// of two compound units, such as kg/h and m/s; "" otherwise.
+	Part string
+	// Alike is set when the units share their SI units but measure
+	// different things, as torque and energy do, so that only a conversion
+	// asked for by name goes between them.
+	Alike bool
+}
+
+func (e *ErrIncompatibleDimensions) Error() string {
+	if e.Part != "" {
+		return fmt.Sprintf("incompatible %s dimensions: %s vs %s", e.Part, e.From, e.To)
+	}
+	if e.Alike {
+		return fmt.Sprintf("cannot convert %s to %s: torque and energy are different quantities; convert with 'in %s' to treat one as the other", e.From, e.To, e.To)
+	}
+	return fmt.Sprintf("cannot convert %s to %s", e.From, e.To)
+}
+
+// ErrBadCompound reports a compound unit that is not written as one unit
+// over another, such as "km/h/s".
+type ErrBadCompound struct {
+	Input string
+}
+
+func (e *ErrBadCompound) Error() string {
+	return fmt.Sprintf("invalid compound unit format: %s", strings.ToLower(e.Input))
+}
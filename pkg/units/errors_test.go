@@ -0,0 +1,61 @@
+package units
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUnknownUnitError(t *testing.T) {
+	s := NewSystem()
+	tests := []struct {
+		name string
+		err  func() error
+		want ErrUnknownUnit
+		msg  string
+	}{
+		{"convert from", func() error { _, err := s.Convert(1, "parsecs", "m"); return err },
+			ErrUnknownUnit{Name: "parsecs"}, "unknown unit 'parsecs'"},
+		{"dimension", func() error { _, err := s.GetDimension("florps"); return err },
+			ErrUnknownUnit{Name: "florps"}, "unknown unit 'florps'"},
+		{"custom unit base", func() error { return s.AddCustomUnit("smoot", 1.7, "florps") },
+			ErrUnknownUnit{Name: "florps", Role: "base"}, "unknown base unit: florps"},
+		{"compound numerator", func() error { _, err := s.ParseCompoundUnit("xyz/h"); return err },
+			ErrUnknownUnit{Name: "xyz", Role: "numerator"}, "unknown numerator unit: xyz"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.err()
+			var unknown *ErrUnknownUnit
+			if !errors.As(err, &unknown) {
+				t.Fatalf("expected ErrUnknownUnit, got %v", err)
+			}
+			if *unknown != tt.want {
+				t.Errorf("got %+v, want %+v", *unknown, tt.want)
+			}
+			if err.Error() != tt.msg {
+				t.Errorf("Error() = %q, want %q", err.Error(), tt.msg)
+			}
+		})
+	}
+}
+
+func TestIncompatibleDimensionsError(t *testing.T) {
+	s := NewSystem()
+	_, err := s.ConvertCompoundUnit(1, "kg/h", "m/s")
+	var incompatible *ErrIncompatibleDimensions
+	if !errors.As(err, &incompatible) || incompatible.Part != "numerator" {
+		t.Fatalf("expected a numerator ErrIncompatibleDimensions, got %v", err)
+	}
+	if got := err.Error(); got != "incompatible numerator dimensions: kg/h vs m/s" {
+		t.Errorf("Error() = %q", got)
+	}
+}
+
+func TestBadCompoundError(t *testing.T) {
+	s := NewSystem()
+	_, err := s.ParseCompoundUnit("km/h/s")
+	var bad *ErrBadCompound
+	if !errors.As(err, &bad) || bad.Input != "km/h/s" {
+		t.Fatalf("expected ErrBadCompound, got %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+package units
+
+import "strings"
+
+// Informal kitchen measures, in litres. A pinch is a sixteenth of a
+// teaspoon, about 0.36 g of salt, a dash an eighth, and a splash 5 ml.
+const (
+	pinchLitres  = 0.00492892 / 16
+	dashLitres   = 0.00492892 / 8
+	splashLitres = 0.005
+)
+
+// initInformalUnits registers the kitchen measures, which a custom unit of
+// the same name may redefine and SetInformal can hide.
+func (s *System) initInformalUnits() {
+	for _, u := range []struct {
+		names  []string
+		litres float64
+	}{
+		{[]string{"pinch", "pinches"}, pinchLitres},
+		{[]string{"dash", "dashes"}, dashLitres},
+		{[]string{"splash", "splashes"}, splashLitres},
+	} {
+		for _, name := range u.names {
+			s.addUnit(name, DimensionVolume, u.litres, "l")
+			s.units[name].Informal = true
+		}
+	}
+}
+
+// SetInformal shows or hides the informal units such as pinch and splash,
+// for people who would rather those words were not units. They are shown
+// unless hidden.
+func (s *System) SetInformal(on bool) {
+	s.informalOff = !on
+}
+
+// hidden reports whether u is an informal unit while they are hidden.
+func (s *System) hidden(u *Unit) bool {
+	return s.informalOff && u.Informal
+}
+
+// IsInformalUnit reports whether name is one of the built-in informal units,
+// which a custom unit may redefine.
+func IsInformalUnit(name string) bool {
+	u, ok := standardSystem().units[strings.ToLower(name)]
+	return ok && u.Informal
+}
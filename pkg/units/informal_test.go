@@ -0,0 +1,55 @@
+package units
+
+import (
+	"math"
+	"testing"
+)
+
+func TestInformalUnits(t *testing.T) {
+	s := NewSystem()
+	tests := []struct {
+		from string
+		want float64 // in ml
+	}{
+		{"pinch", 0.308},
+		{"pinches", 0.308},
+		{"dash", 0.616},
+		{"splash", 5},
+	}
+	for _, tt := range tests {
+		got, err := s.Convert(1, tt.from, "ml")
+		if err != nil || math.Abs(got-tt.want) > 0.001 {
+			t.Errorf("1 %s in ml = %v, %v; want %v", tt.from, got, err, tt.want)
+		}
+	}
+
+	s.SetInformal(false)
+	if s.IsUnit("pinch") {
+		t.Error("pinch should not be a unit with informal units off")
+	}
+	if !s.IsUnit("tsp") {
+		t.Error("tsp should stay a unit with informal units off")
+	}
+}
+
+func TestCustomUnitOverridesInformalUnit(t *testing.T) {
+	s := NewSystem()
+	if err := s.AddCustomUnit("pinch", 0.5, "ml"); err != nil {
+		t.Fatalf("redefining pinch: %v", err)
+	}
+	if got, _ := s.Convert(2, "pinch", "ml"); math.Abs(got-1) > 1e-9 {
+		t.Errorf("2 pinch in ml = %v, want 1", got)
+	}
+	if !s.RemoveCustomUnit("pinch") {
+		t.Fatal("custom pinch not removed")
+	}
+	if got, _ := s.Convert(16, "pinch", "tsp"); math.Abs(got-1) > 1e-9 {
+		t.Errorf("16 pinch in tsp = %v, want the built-in 1", got)
+	}
+	if NewSystem().IsCustomUnit("pinch") {
+		t.Error("redefining pinch leaked into a new system")
+	}
+	if !IsInformalUnit("Pinch") || IsInformalUnit("tsp") {
+		t.Error("only the kitchen measures are informal units")
+	}
+}
@@ -0,0 +1,121 @@
+package units
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// builtinIngredients are the densities, in g/ml, of common kitchen
+// ingredients as they are measured into a cup: spooned flour rather than
+// packed, uncooked rice and oats. They are rules of thumb; a recipe's own
+// weights win where it gives them.
+var builtinIngredients = map[string]float64{
+	"flour":  0.507, // 120 g a cup
+	"sugar":  0.845, // 200 g a cup
+	"butter": 0.959, // 227 g a cup
+	"rice":   0.782, // 185 g a cup
+	"oats":   0.380, // 90 g a cup
+	"water":  1.0,
+	"milk":   1.03,
+	"cream":  1.01,
+	"oil":    0.92,
+	"honey":  1.42,
+	"salt":   1.22,
+	"yogurt": 1.03,
+}
+
+// Ingredient returns the density of a named ingredient in g/ml. Ingredients
+// defined with DefineIngredient take precedence over the built-in ones.
+func (s *System) Ingredient(name string) (float64, bool) {
+	name = strings.ToLower(name)
+	if d, ok := s.ingredients[name]; ok {
+		return d, true
+	}
+	d, ok := builtinIngredients[name]
+	return d, ok
+}
+
+// IsIngredient reports whether name is a known ingredient.
+func (s *System) IsIngredient(name string) bool {
+	_, ok := s.Ingredient(name)
+	return ok
+}
+
+// Ingredients returns the names of every known ingredient, sorted.
+func (s *System) Ingredients() []string {
+	names := make([]string, 0, len(builtinIngredients)+len(s.ingredients))
+	for name := range builtinIngredients {
+		names = append(names, name)
+	}
+	for name := range s.ingredients {
+		if _, ok := builtinIngredients[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CustomIngredients returns the ingredients defined with DefineIngredient
+// and their densities in g/ml.
+func (s *System) CustomIngredients() map[string]float64 {
+	out := make(map[string]float64, len(s.ingredients))
+	for name, d := range s.ingredients {
+		out[name] = d
+	}
+	return out
+}
+
+// DefineIngredient adds an ingredient with a density in g/ml, replacing any
+// of the same name, including a built-in one.
+func (s *System) DefineIngredient(name string, density float64) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return fmt.Errorf("missing ingredient name")
+	}
+	if density <= 0 {
+		return fmt.Errorf("density must be positive, got %g", density)
+	}
+	if s.ingredients == nil {
+		s.ingredients = make(map[string]float64)
+	}
+	s.ingredients[name] = density
+	return nil
+}
+
+// UnknownIngredientError describes an ingredient without a density,
+// listing the ones that have one.
+func (s *System) UnknownIngredientError(name string) error {
+	return fmt.Errorf("unknown ingredient %q (known: %s)", name, strings.Join(s.Ingredients(), ", "))
+}
+
+// ConvertIngredient converts an amount of an ingredient between units,
+// using its density to go between mass and volume, so 1 cup of flour is
+// about 120 g. Units of the same dimension convert as Convert does.
+func (s *System) ConvertIngredient(value float64, fromUnit, toUnit, ingredient string) (float64, error) {
+	from, ok := s.lookup(fromUnit)
+	if !ok {
+		return 0, &ErrUnknownUnit{Name: fromUnit}
+	}
+	to, ok := s.lookup(toUnit)
+	if !ok {
+		return 0, &ErrUnknownUnit{Name: toUnit}
+	}
+	if from.Dimension == to.Dimension {
+		return s.Convert(value, fromUnit, toUnit)
+	}
+	density, ok := s.Ingredient(ingredient)
+	if !ok {
+		return 0, s.UnknownIngredientError(ingredient)
+	}
+	// Volumes are based on litres and masses on kilograms, so a density in
+	// g/ml is also one in kg/l
+	switch {
+	case from.Dimension == DimensionVolume && to.Dimension == DimensionMass:
+		return value * from.ToBase * density / to.ToBase, nil
+	case from.Dimension == DimensionMass && to.Dimension == DimensionVolume:
+		return value * from.ToBase / density / to.ToBase, nil
+	}
+	return 0, &ErrIncompatibleDimensions{From: fromUnit, To: toUnit}
+}
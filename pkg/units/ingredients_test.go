@@ -0,0 +1,111 @@
+package units
+
+import (
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConvertIngredient(t *testing.T) {
+	s := NewSystem()
+	tests := []struct {
+		value      float64
+		from, to   string
+		ingredient string
+		want       float64
+	}{
+		{1, "cup", "g", "flour", 119.95},
+		{250, "g", "cups", "butter", 1.10},
+		{100, "ml", "g", "water", 100},
+		{1, "kg", "l", "water", 1},
+		{2, "tbsp", "g", "honey", 42.0},
+		{1, "cup", "ml", "flour", 236.59}, // Same dimension, density unused
+	}
+	for _, tt := range tests {
+		got, err := s.ConvertIngredient(tt.value, tt.from, tt.to, tt.ingredient)
+		if err != nil {
+			t.Errorf("%g %s %s in %s: %v", tt.value, tt.from, tt.ingredient, tt.to, err)
+			continue
+		}
+		if math.Abs(got-tt.want) > 0.01 {
+			t.Errorf("%g %s %s in %s = %.4f, want %.2f", tt.value, tt.from, tt.ingredient, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestConvertIngredientErrors(t *testing.T) {
+	s := NewSystem()
+	if _, err := s.ConvertIngredient(1, "cup", "g", "apples"); err == nil || !strings.Contains(err.Error(), "known: butter,") {
+		t.Errorf("unknown ingredient should list the known ones, got %v", err)
+	}
+	if _, err := s.ConvertIngredient(1, "cup", "m", "flour"); err == nil {
+		t.Error("volume to length should not convert")
+	}
+}
+
+func TestDefineIngredient(t *testing.T) {
+	s := NewSystem()
+	if s.IsIngredient("cocoa") {
+		t.Fatal("cocoa should not be built in")
+	}
+	if err := s.DefineIngredient("Cocoa", 0.52); err != nil {
+		t.Fatal(err)
+	}
+	if d, ok := s.Ingredient("cocoa"); !ok || d != 0.52 {
+		t.Errorf("cocoa = %v, %v; want 0.52", d, ok)
+	}
+	if err := s.DefineIngredient("flour", 0.6); err != nil {
+		t.Fatal(err)
+	}
+	if d, _ := s.Ingredient("flour"); d != 0.6 {
+		t.Errorf("a defined ingredient should replace the built-in one, got %v", d)
+	}
+	if err := s.DefineIngredient("grit", 0); err == nil {
+		t.Error("a zero density should be rejected")
+	}
+	if NewSystem().IsIngredient("cocoa") {
+		t.Error("ingredients should not leak between systems")
+	}
+}
+
+func TestIngredientsSaveLoadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "units.json")
+	src := NewSystem()
+	if err := src.DefineIngredient("cocoa", 0.52); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.SaveCustomUnits(path); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := NewSystem()
+	if w := dst.LoadCustomUnits(path); len(w) != 0 {
+		t.Fatalf("unexpected warnings: %v", w)
+	}
+	if d, ok := dst.Ingredient("cocoa"); !ok || d != 0.52 {
+		t.Errorf("cocoa = %v, %v after loading; want 0.52", d, ok)
+	}
+}
+
+func TestImportPackIngredientConflicts(t *testing.T) {
+	s := NewSystem()
+	if err := s.DefineIngredient("cocoa", 0.52); err != nil {
+		t.Fatal(err)
+	}
+	pack := Pack{Version: PackVersion, Ingredients: []PackIngredient{
+		{Name: "cocoa", Density: 0.6},
+		{Name: "cornflour", Density: 0.54},
+		{Name: "grit", Density: -1},
+	}}
+	r, err := s.ImportPack(pack, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Added) != 1 || len(r.Conflicts) != 1 || len(r.Failed) != 1 {
+		t.Errorf("report = %+v", r)
+	}
+	if d, _ := s.Ingredient("cocoa"); d != 0.52 {
+		t.Errorf("a merge should keep cocoa, got %v", d)
+	}
+}
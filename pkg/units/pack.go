@@ -0,0 +1,239 @@
+package units
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/andrewneudegg/calc/pkg/config"
+)
+
+// PackVersion is the units pack schema version written by ExportPack. Packs
+// from a newer version are rejected rather than half understood.
+const PackVersion = 1
+
+// Pack is a shareable set of custom unit definitions, with any ingredients
+// defined for converting between mass and volume.
+type Pack struct {
+	Version     int              `json:"version"`
+	Units       []PackUnit       `json:"units"`
+	Ingredients []PackIngredient `json:"ingredients,omitempty"`
+}
+
+// PackUnit defines one custom unit as a multiple of a base unit, e.g. a rack
+// unit is 44.45 mm. Dimension records what the unit measures so an import can
+// check that the base still means the same thing.
+type PackUnit struct {
+	Name      string  `json:"name"`
+	Value     float64 `json:"value"`
+	Base      string  `json:"base"`
+	Dimension string  `json:"dimension,omitempty"`
+}
+
+// PackIngredient defines an ingredient by its density in g/ml.
+type PackIngredient struct {
+	Name    string  `json:"name"`
+	Density float64 `json:"density"`
+}
+
+// ImportReport describes the outcome of ImportPack for each unit in a pack.
+type ImportReport struct {
+	Added     []string
+	Replaced  []string
+	Unchanged []string
+	Conflicts []string // Existing custom units with a different definition, kept as they were
+	Failed    []string // Entries that could not be resolved, with the reason
+}
+
+var dimensionNames = map[Dimension]string{
+	DimensionNone:        "none",
+	DimensionLength:      "length",
+	DimensionMass:        "mass",
+	DimensionTime:        "time",
+	DimensionTemperature: "temperature",
+	DimensionVolume:      "volume",
+	DimensionArea:        "area",
+	DimensionData:        "data",
+	DimensionDataRate:    "datarate",
+	DimensionSpeed:       "speed",
+	DimensionPressure:    "pressure",
+	DimensionForce:       "force",
+	DimensionAngle:       "angle",
+	DimensionFrequency:   "frequency",
+	DimensionEnergy:      "energy",
+	DimensionPower:       "power",
+	DimensionCount:       "count",
+	DimensionTorque:      "torque",
+}
+
+// String returns the lower-case name of a dimension, e.g. "length".
+func (d Dimension) String() string {
+	if name, ok := dimensionNames[d]; ok {
+		return name
+	}
+	return fmt.Sprintf("dimension(%d)", int(d))
+}
+
+// IsCustomUnit reports whether name is a custom unit.
+func (s *System) IsCustomUnit(name string) bool {
+	_, ok := s.custom[strings.ToLower(name)]
+	return ok
+}
+
+// CustomUnits returns the custom units, sorted by name.
+func (s *System) CustomUnits() []*Unit {
+	out := make([]*Unit, 0, len(s.custom))
+	for _, u := range s.custom {
+		out = append(out, u)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// ExportPack describes every custom unit relative to its dimension's base unit.
+func (s *System) ExportPack() Pack {
+	p := Pack{Version: PackVersion, Units: []PackUnit{}}
+	for _, u := range s.CustomUnits() {
+		p.Units = append(p.Units, PackUnit{
+			Name:      u.Name,
+			Value:     u.ToBase,
+			Base:      u.BaseUnit,
+			Dimension: u.Dimension.String(),
+		})
+	}
+	custom := s.CustomIngredients()
+	names := make([]string, 0, len(custom))
+	for name := range custom {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p.Ingredients = append(p.Ingredients, PackIngredient{Name: name, Density: custom[name]})
+	}
+	return p
+}
+
+// ImportPack adds the units and ingredients in p. A custom unit or ingredient
+// that already exists with a different definition is only overwritten when
+// replace is set; otherwise it is reported as a conflict. Entries that cannot be resolved are reported and
+// skipped without affecting the rest of the pack.
+func (s *System) ImportPack(p Pack, replace bool) (ImportReport, error) {
+	var r ImportReport
+	if p.Version > PackVersion {
+		return r, fmt.Errorf("units pack version %d is newer than supported version %d", p.Version, PackVersion)
+	}
+	if p.Version < 1 {
+		return r, fmt.Errorf("units pack has no valid version")
+	}
+
+	for _, pu := range p.Units {
+		name := strings.ToLower(strings.TrimSpace(pu.Name))
+		base, err := s.resolvePackUnit(name, pu)
+		if err != nil {
+			r.Failed = append(r.Failed, fmt.Sprintf("%s: %s", pu.Name, err))
+			continue
+		}
+
+		existing, isCustom := s.custom[name]
+		switch {
+		case !isCustom:
+			r.Added = append(r.Added, name)
+		case existing.Dimension == base.Dimension && existing.ToBase == pu.Value*base.ToBase:
+			r.Unchanged = append(r.Unchanged, name)
+			continue
+		case !replace:
+			r.Conflicts = append(r.Conflicts, fmt.Sprintf("%s: defined as %g %s, pack has %g %s",
+				name, existing.ToBase, existing.BaseUnit, pu.Value*base.ToBase, base.BaseUnit))
+			continue
+		default:
+			r.Replaced = append(r.Replaced, name)
+		}
+		if err := s.AddCustomUnit(name, pu.Value, pu.Base); err != nil {
+			r.Failed = append(r.Failed, fmt.Sprintf("%s: %s", pu.Name, err))
+		}
+	}
+
+	for _, pi := range p.Ingredients {
+		name := strings.ToLower(strings.TrimSpace(pi.Name))
+		if name == "" {
+			r.Failed = append(r.Failed, fmt.Sprintf("%s: missing ingredient name", pi.Name))
+			continue
+		}
+		if pi.Density <= 0 {
+			r.Failed = append(r.Failed, fmt.Sprintf("%s: density must be positive, got %g", pi.Name, pi.Density))
+			continue
+		}
+		existing, isCustom := s.ingredients[name]
+		switch {
+		case !isCustom:
+			r.Added = append(r.Added, name)
+		case existing == pi.Density:
+			r.Unchanged = append(r.Unchanged, name)
+			continue
+		case !replace:
+			r.Conflicts = append(r.Conflicts, fmt.Sprintf("%s: defined as %g g/ml, pack has %g g/ml", name, existing, pi.Density))
+			continue
+		default:
+			r.Replaced = append(r.Replaced, name)
+		}
+		if err := s.DefineIngredient(name, pi.Density); err != nil {
+			r.Failed = append(r.Failed, fmt.Sprintf("%s: %s", pi.Name, err))
+		}
+	}
+	return r, nil
+}
+
+// resolvePackUnit checks a pack entry against the built-in units and returns
+// the unit it is defined in terms of.
+func (s *System) resolvePackUnit(name string, pu PackUnit) (*Unit, error) {
+	if name == "" {
+		return nil, fmt.Errorf("missing unit name")
+	}
+	if u, ok := s.units[name]; ok && !u.IsCustom && !u.Informal {
+		return nil, fmt.Errorf("would replace the built-in unit %s", name)
+	}
+	if pu.Value <= 0 {
+		return nil, fmt.Errorf("value must be positive, got %g", pu.Value)
+	}
+	base, ok := s.units[strings.ToLower(pu.Base)]
+	if !ok || base.IsCustom {
+		return nil, &ErrUnknownUnit{Name: pu.Base, Role: "base"}
+	}
+	if base.Dimension == DimensionTemperature {
+		return nil, fmt.Errorf("temperature units cannot be defined as multiples")
+	}
+	if pu.Dimension != "" && !strings.EqualFold(pu.Dimension, base.Dimension.String()) {
+		return nil, fmt.Errorf("base unit %s measures %s, not %s", pu.Base, base.Dimension, pu.Dimension)
+	}
+	return base, nil
+}
+
+// WritePack writes the custom units of s to path as JSON.
+func (s *System) WritePack(path string) (int, error) {
+	p := s.ExportPack()
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	return len(p.Units), config.WriteFile(path, append(data, '\n'))
+}
+
+// ReadPack reads a units pack written by WritePack.
+func ReadPack(path string) (Pack, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Pack{}, err
+	}
+	return decodePack(path, data)
+}
+
+// decodePack reads the units pack in data, read from path.
+func decodePack(path string, data []byte) (Pack, error) {
+	var p Pack
+	if err := json.Unmarshal(data, &p); err != nil {
+		return p, fmt.Errorf("%s is not a units pack: %w", path, err)
+	}
+	return p, nil
+}
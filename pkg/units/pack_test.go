@@ -0,0 +1,122 @@
+package units
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPackRoundTrip(t *testing.T) {
+	src := NewSystem()
+	if err := src.AddCustomUnit("rackunit", 44.45, "mm"); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.AddCustomUnit("pallet", 500, "kg"); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "pack.json")
+	n, err := src.WritePack(path)
+	if err != nil || n != 2 {
+		t.Fatalf("WritePack = %d, %v; want 2 units", n, err)
+	}
+
+	pack, err := ReadPack(path)
+	if err != nil {
+		t.Fatalf("ReadPack: %v", err)
+	}
+	if pack.Version != PackVersion || pack.Units[0].Name != "pallet" || pack.Units[0].Dimension != "mass" {
+		t.Errorf("unexpected pack: %+v", pack)
+	}
+
+	dst := NewSystem()
+	report, err := dst.ImportPack(pack, false)
+	if err != nil {
+		t.Fatalf("ImportPack: %v", err)
+	}
+	if len(report.Added) != 2 || len(report.Failed) != 0 {
+		t.Errorf("report = %+v, want 2 added", report)
+	}
+	got, err := dst.Convert(3, "rackunit", "cm")
+	if err != nil || got < 13.33 || got > 13.34 {
+		t.Errorf("3 rackunit in cm = %v, %v; want 13.335", got, err)
+	}
+
+	// Importing the same pack again changes nothing
+	report, _ = dst.ImportPack(pack, false)
+	if len(report.Unchanged) != 2 || len(report.Added) != 0 || len(report.Conflicts) != 0 {
+		t.Errorf("re-import report = %+v, want 2 unchanged", report)
+	}
+}
+
+func TestPackConflicts(t *testing.T) {
+	s := NewSystem()
+	if err := s.AddCustomUnit("pallet", 500, "kg"); err != nil {
+		t.Fatal(err)
+	}
+	pack := Pack{Version: PackVersion, Units: []PackUnit{{Name: "pallet", Value: 750, Base: "kg"}}}
+
+	report, err := s.ImportPack(pack, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Conflicts) != 1 || !strings.Contains(report.Conflicts[0], "pallet") {
+		t.Errorf("merge report = %+v, want a pallet conflict", report)
+	}
+	if got, _ := s.Convert(1, "pallet", "kg"); got != 500 {
+		t.Errorf("merge overwrote the existing unit: 1 pallet = %v kg", got)
+	}
+
+	report, err = s.ImportPack(pack, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Replaced) != 1 {
+		t.Errorf("replace report = %+v, want pallet replaced", report)
+	}
+	if got, _ := s.Convert(1, "pallet", "kg"); got != 750 {
+		t.Errorf("replace kept the old unit: 1 pallet = %v kg", got)
+	}
+}
+
+func TestPackPartialFailure(t *testing.T) {
+	s := NewSystem()
+	pack := Pack{Version: PackVersion, Units: []PackUnit{
+		{Name: "rackunit", Value: 44.45, Base: "mm", Dimension: "length"},
+		{Name: "bogus", Value: 3, Base: "furlongz"},
+		{Name: "km", Value: 3, Base: "m"},
+		{Name: "heavy", Value: 2, Base: "kg", Dimension: "length"},
+		{Name: "zero", Value: 0, Base: "kg"},
+	}}
+
+	report, err := s.ImportPack(pack, false)
+	if err != nil {
+		t.Fatalf("ImportPack: %v", err)
+	}
+	if len(report.Added) != 1 || report.Added[0] != "rackunit" {
+		t.Errorf("added = %v, want only rackunit", report.Added)
+	}
+	want := []string{"unknown base unit", "built-in unit km", "measures mass, not length", "must be positive"}
+	if len(report.Failed) != len(want) {
+		t.Fatalf("failed = %v, want %d entries", report.Failed, len(want))
+	}
+	for i, w := range want {
+		if !strings.Contains(report.Failed[i], w) {
+			t.Errorf("failed[%d] = %q, want it to mention %q", i, report.Failed[i], w)
+		}
+	}
+	if got, _ := s.Convert(1, "km", "m"); got != 1000 {
+		t.Errorf("built-in km was changed: 1 km = %v m", got)
+	}
+}
+
+func TestPackRejectsFutureVersion(t *testing.T) {
+	s := NewSystem()
+	pack := Pack{Version: PackVersion + 1, Units: []PackUnit{{Name: "pallet", Value: 500, Base: "kg"}}}
+	if _, err := s.ImportPack(pack, false); err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Errorf("ImportPack error = %v, want a version error", err)
+	}
+	if s.IsCustomUnit("pallet") {
+		t.Error("a rejected pack should not add any units")
+	}
+}
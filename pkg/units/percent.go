@@ -0,0 +1,35 @@
+package units
+
+import "strings"
+
+// Percentages are also written on finer scales: per mille, tenths of a per
+// cent, and basis points, hundredths of one. These are not units of a
+// dimension but ways of writing a percentage, so they live apart from the
+// unit table. "bps" is also a data rate; which one a line means is up to
+// the parser and evaluator.
+
+// percentScales gives how many of each percent-like unit make one per cent.
+var percentScales = map[string]float64{
+	"%":           1,
+	"‰":           10,
+	"permille":    10,
+	"‱":           100,
+	"bp":          100,
+	"bps":         100,
+	"basispoint":  100,
+	"basispoints": 100,
+}
+
+// PercentScale returns how many of the percent-like unit name make one per
+// cent, as 100 for bps, and false when name is not one.
+func PercentScale(name string) (float64, bool) {
+	scale, ok := percentScales[strings.ToLower(name)]
+	return scale, ok
+}
+
+// IsPercentWord reports whether name is a percent-like unit written as a
+// word, such as bp or permille, rather than a sign such as ‰.
+func IsPercentWord(name string) bool {
+	_, ok := PercentScale(name)
+	return ok && strings.IndexFunc(name, func(r rune) bool { return r < 'A' || r > 'z' }) < 0
+}
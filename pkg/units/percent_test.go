@@ -0,0 +1,32 @@
+package units
+
+import "testing"
+
+func TestPercentScale(t *testing.T) {
+	tests := []struct {
+		name  string
+		scale float64
+		ok    bool
+	}{
+		{"%", 1, true},
+		{"‰", 10, true},
+		{"permille", 10, true},
+		{"bps", 100, true},
+		{"BP", 100, true},
+		{"basispoints", 100, true},
+		{"‱", 100, true},
+		{"kbps", 0, false},
+	}
+	for _, tt := range tests {
+		scale, ok := PercentScale(tt.name)
+		if scale != tt.scale || ok != tt.ok {
+			t.Errorf("PercentScale(%q) = %v, %v, want %v, %v", tt.name, scale, ok, tt.scale, tt.ok)
+		}
+	}
+
+	for name, want := range map[string]bool{"bp": true, "permille": true, "‰": false, "%": false, "m": false} {
+		if got := IsPercentWord(name); got != want {
+			t.Errorf("IsPercentWord(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package units
+
+import (
+	"math"
+	"strings"
+)
+
+// siPrefix is an SI prefix, written as a symbol before a unit symbol (km) or
+// as a word before a unit name (kilometre).
+type siPrefix struct {
+	symbol string
+	word   string
+	factor float64
+}
+
+// siPrefixes are the prefixes from yocto to yotta. Symbols match with their
+// case, since m is milli and M mega; words match ignoring case.
+var siPrefixes = []siPrefix{
+	{"y", "yocto", 1e-24},
+	{"z", "zepto", 1e-21},
+	{"a", "atto", 1e-18},
+	{"f", "femto", 1e-15},
+	{"p", "pico", 1e-12},
+	{"n", "nano", 1e-9},
+	{"µ", "micro", 1e-6},
+	{"μ", "micro", 1e-6}, // Greek mu, which some keyboards give for µ
+	{"u", "micro", 1e-6},
+	{"m", "milli", 1e-3},
+	{"c", "centi", 1e-2},
+	{"d", "deci", 1e-1},
+	{"da", "deca", 1e1},
+	{"da", "deka", 1e1},
+	{"h", "hecto", 1e2},
+	{"k", "kilo", 1e3},
+	{"M", "mega", 1e6},
+	{"G", "giga", 1e9},
+	{"T", "tera", 1e12},
+	{"P", "peta", 1e15},
+	{"E", "exa", 1e18},
+	{"Z", "zetta", 1e21},
+	{"Y", "yotta", 1e24},
+}
+
+// prefixSymbolBases are the unit symbols that take a prefix symbol, and
+// prefixWordBases the unit names that take a prefix word. Single-letter
+// symbols match with their case, so kN and MJ are units while fn and dj,
+// more likely names of variables, are not; longer symbols ignore case.
+var (
+	prefixSymbolBases = map[string]bool{
+		"m": true, "g": true, "s": true, "l": true, "L": true,
+		"N": true, "J": true, "W": true, "pa": true, "hz": true, "wh": true,
+	}
+	prefixWordBases = map[string]bool{
+		"metre": true, "metres": true, "meter": true, "meters": true,
+		"gram": true, "grams": true, "second": true, "seconds": true,
+		"litre": true, "litres": true, "liter": true, "liters": true,
+		"pascal": true, "pascals": true, "newton": true, "newtons": true,
+		"hertz": true, "joule": true, "joules": true, "watt": true, "watts": true,
+	}
+)
+
+// isPrefixSymbolBase reports whether base is a unit symbol that takes a
+// prefix symbol.
+func isPrefixSymbolBase(base string) bool {
+	if len(base) == 1 {
+		return prefixSymbolBases[base]
+	}
+	return prefixSymbolBases[strings.ToLower(base)]
+}
+
+// prefixCollisions are prefixed forms left alone because they mean something
+// else: am and pm are times of day, as is a keyword and al is a name.
+var prefixCollisions = map[string]bool{
+	"am": true, "pm": true, "as": true, "al": true,
+}
+
+// splitPrefix reads name as an SI prefix on a unit that takes one, returning
+// the prefix's factor and the unit's name, so "GPa" gives 1e9 and "Pa".
+func splitPrefix(name string) (float64, string, bool) {
+	if prefixCollisions[strings.ToLower(name)] {
+		return 0, "", false
+	}
+	for _, p := range siPrefixes {
+		if base, ok := strings.CutPrefix(name, p.symbol); ok && isPrefixSymbolBase(base) {
+			return p.factor, base, true
+		}
+		if len(name) > len(p.word) && strings.EqualFold(name[:len(p.word)], p.word) && prefixWordBases[strings.ToLower(name[len(p.word):])] {
+			return p.factor, name[len(p.word):], true
+		}
+	}
+	return 0, "", false
+}
+
+// HasSIPrefix reports whether name is an SI prefix on a unit that takes one,
+// such as nm, GPa or microgram. Names that are units in their own right,
+// like min or ct, should be looked up before asking.
+func HasSIPrefix(name string) bool {
+	_, _, ok := splitPrefix(name)
+	return ok
+}
+
+// lookup finds a unit by name, ignoring case except for the symbols in
+// caseSymbols, such as Nm, and for prefix symbols. A name that is not
+// registered but is an SI prefix on a unit that takes one, such as nm or
+// GPa, gives a unit made for it; a registered name wins, so min stays
+// minutes, unless the case of its prefix says otherwise: mm is registered,
+// but Mm is a megametre.
+// Names written with other characters, such as ㎞ or °C, and products such
+// as N·m are found too.
+func (s *System) lookup(name string) (*Unit, bool) {
+	if caseSymbols[name] {
+		return s.units[name], true
+	}
+	if u, ok := s.units[strings.ToLower(name)]; ok {
+		if p, ok := s.prefixedUnit(name); ok && name != strings.ToLower(name) && !sameSize(p, u) {
+			return p, true
+		}
+		return u, !s.hidden(u)
+	}
+	if u, ok := s.prefixedUnit(name); ok {
+		return u, true
+	}
+	return s.lookupVariant(name)
+}
+
+// prefixedUnit reads name as an SI prefix on a registered unit, making the
+// unit on first use.
+func (s *System) prefixedUnit(name string) (*Unit, bool) {
+	if u, ok := s.prefixed[name]; ok {
+		return u, true
+	}
+	factor, baseName, ok := splitPrefix(name)
+	if !ok {
+		return nil, false
+	}
+	base, ok := s.units[strings.ToLower(baseName)]
+	if !ok {
+		return nil, false
+	}
+	// Prefixed units are cached by their exact name, as Ym and ym differ,
+	// and kept out of s.units so listings show only the registered names
+	u := &Unit{
+		Name:      name,
+		Dimension: base.Dimension,
+		ToBase:    factor * base.ToBase,
+		BaseUnit:  base.BaseUnit,
+	}
+	s.prefixed[name] = u
+	return u, true
+}
+
+// sameSize reports whether a and b are the same amount of the same
+// dimension, as kW read by its prefix and the registered kw are.
+func sameSize(a, b *Unit) bool {
+	return a.Dimension == b.Dimension && math.Abs(a.ToBase-b.ToBase) <= 1e-9*math.Abs(b.ToBase)
+}
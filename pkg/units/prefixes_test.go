@@ -0,0 +1,119 @@
+package units
+
+import (
+	"math"
+	"testing"
+)
+
+func TestConvertSIPrefixedUnits(t *testing.T) {
+	s := NewSystem()
+	tests := []struct {
+		value    float64
+		from, to string
+		want     float64
+	}{
+		{3, "nm", "m", 3e-9},
+		{2, "GPa", "psi", 2e9 / 6894.76},
+		{2, "Gpa", "kpa", 2e6},
+		{5, "µm", "mm", 0.005},
+		{5, "um", "nm", 5000},
+		{2, "micrometres", "nm", 2000},
+		{7, "kilonewtons", "N", 7000},
+		{3, "MJ", "kJ", 3000},
+		{1, "mJ", "J", 0.001},
+		{1, "MW", "W", 1e6},
+		{1, "mW", "W", 0.001},
+		{250, "mW", "MW", 2.5e-7},
+		// All lower case, mw and mj are mega like mwh
+		{1, "mw", "MW", 1},
+		{1, "mj", "MJ", 1},
+		{1, "TWh", "GWh", 1000},
+		{3, "Ym", "m", 3e24},
+		{3, "ym", "m", 3e-24},
+		{4, "ng", "mg", 4e-6},
+		{250, "µL", "ml", 0.25},
+		{1, "dam", "m", 10},
+		{1, "hPa", "mbar", 1},
+		{5, "Mm", "km", 5000},
+		{1, "Ms", "ms", 1e9},
+	}
+	for _, tt := range tests {
+		got, err := s.Convert(tt.value, tt.from, tt.to)
+		if err != nil {
+			t.Errorf("Convert(%g %s to %s): %v", tt.value, tt.from, tt.to, err)
+			continue
+		}
+		if math.Abs(got-tt.want) > math.Abs(tt.want)*1e-9 {
+			t.Errorf("Convert(%g %s to %s) = %g, want %g", tt.value, tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestSIPrefixCollisionsKeepTheirMeaning(t *testing.T) {
+	s := NewSystem()
+	// Registered units win over a prefix reading
+	for name, want := range map[string]float64{"min": 60, "ct": 0.0002, "mm": 0.001, "kn": 0.514444} {
+		u, ok := s.lookup(name)
+		if !ok || u.ToBase != want {
+			t.Errorf("%s: expected the registered unit with factor %g, got %+v", name, want, u)
+		}
+	}
+	// Times of day, words and likely variable names are not prefixed units,
+	// and prefix symbols keep their case, so gpa is not giga-pascals
+	for _, name := range []string{"am", "pm", "as", "al", "fn", "dj", "gpa"} {
+		if s.IsUnit(name) {
+			t.Errorf("%s should not be read as a prefixed unit", name)
+		}
+	}
+}
+
+func TestSIPrefixCaseTellsMilliFromMega(t *testing.T) {
+	s := NewSystem()
+	tests := []struct {
+		milli, mega string
+		base        float64 // Size of the unit both are prefixes of, in its base
+	}{
+		{"mm", "Mm", 1},
+		{"mg", "Mg", 0.001},
+		{"ms", "Ms", 1},
+		{"mL", "ML", 1},
+		{"ml", "Ml", 1},
+		{"mPa", "MPa", 1},
+		{"mN", "MN", 1},
+		{"mHz", "MHz", 1},
+		{"mJ", "MJ", 1},
+		{"mW", "MW", 1},
+	}
+	for _, tt := range tests {
+		milli, ok := s.lookup(tt.milli)
+		if !ok || math.Abs(milli.ToBase-tt.base*1e-3) > tt.base*1e-12 {
+			t.Errorf("%s: expected milli (%g), got %+v", tt.milli, tt.base*1e-3, milli)
+		}
+		mega, ok := s.lookup(tt.mega)
+		if !ok || math.Abs(mega.ToBase-tt.base*1e6) > tt.base*1e-3 {
+			t.Errorf("%s: expected mega (%g), got %+v", tt.mega, tt.base*1e6, mega)
+		}
+	}
+
+	// Where case makes no difference to the size, the registered unit stays
+	if u, _ := s.lookup("kW"); u.Name != "kw" {
+		t.Errorf("kW: expected the registered kw, got %+v", u)
+	}
+}
+
+func TestSIPrefixedUnitsAreCachedAndUnlisted(t *testing.T) {
+	s := NewSystem()
+	a, _ := s.lookup("nm")
+	b, _ := s.lookup("nm")
+	if a == nil || a != b {
+		t.Fatal("expected the prefixed unit to be made once and cached")
+	}
+	for _, u := range s.UnitsIn(DimensionLength) {
+		if u.Name == "nm" {
+			t.Error("prefixed units should not appear in unit listings")
+		}
+	}
+	if !HasSIPrefix("GPa") || HasSIPrefix("pm") || HasSIPrefix("metre") {
+		t.Error("HasSIPrefix disagrees with lookup")
+	}
+}
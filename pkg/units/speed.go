@@ -0,0 +1,95 @@
+package units
+
+import (
+	"fmt"
+	"math"
+)
+
+// beaufortBase is the base unit of the Beaufort scale, which has no fixed
+// factor to metres per second.
+const beaufortBase = "bft"
+
+// beaufortFactor relates Beaufort force B to wind speed, v = 0.836 B^1.5 m/s,
+// as the World Meteorological Organization defines the scale.
+const beaufortFactor = 0.836
+
+// IsScale reports whether the unit is a point on a scale, such as a
+// temperature or a Beaufort force, rather than a multiple of its base unit.
+func (u *Unit) IsScale() bool {
+	return u.Dimension == DimensionTemperature || u.BaseUnit == beaufortBase
+}
+
+// convertBeaufort converts to or from Beaufort force. Forces are whole steps,
+// so a speed converts to the force whose band contains it, capped at
+// hurricane force 12.
+func convertBeaufort(value float64, from, to *Unit) (float64, error) {
+	if from.BaseUnit == to.BaseUnit {
+		return value, nil
+	}
+	if value < 0 {
+		return 0, fmt.Errorf("cannot convert a negative speed to or from the Beaufort scale")
+	}
+	mps := value * from.ToBase
+	if from.BaseUnit == beaufortBase {
+		mps = beaufortFactor * math.Pow(value, 1.5)
+	}
+	if to.BaseUnit != beaufortBase {
+		return mps / to.ToBase, nil
+	}
+	return math.Min(math.Round(math.Pow(mps/beaufortFactor, 2.0/3)), 12), nil
+}
+
+// IsPace reports whether unit is a time per distance, such as min/km.
+func (s *System) IsPace(unit string) bool {
+	if !IsCompoundUnit(unit) {
+		return false
+	}
+	c, err := s.ParseCompoundUnit(unit)
+	return err == nil && c.Numerator.Dimension == DimensionTime && c.Denominator.Dimension == DimensionLength
+}
+
+// convertPace converts between a pace and a speed, either a speed unit such
+// as mph or a distance per time such as km/h, through seconds per metre.
+func (s *System) convertPace(value float64, fromUnit, toUnit string) (float64, error) {
+	if value == 0 {
+		return 0, fmt.Errorf("cannot convert a pace or speed of zero between %s and %s", fromUnit, toUnit)
+	}
+	if s.IsPace(fromUnit) {
+		pace, _ := s.ParseCompoundUnit(fromUnit)
+		mps := pace.ToBaseDen / (value * pace.ToBaseNum)
+		return s.fromMetresPerSecond(mps, toUnit)
+	}
+	mps, err := s.toMetresPerSecond(value, fromUnit)
+	if err != nil {
+		return 0, err
+	}
+	pace, _ := s.ParseCompoundUnit(toUnit)
+	return pace.ToBaseDen / (mps * pace.ToBaseNum), nil
+}
+
+// toMetresPerSecond converts a speed, in a speed unit or as distance per time, to m/s.
+func (s *System) toMetresPerSecond(value float64, unit string) (float64, error) {
+	if !IsCompoundUnit(unit) {
+		if dim, err := s.GetDimension(unit); err != nil || dim != DimensionSpeed {
+			return 0, fmt.Errorf("cannot convert %s to or from a pace", unit)
+		}
+		return s.Convert(value, unit, "mps")
+	}
+	c, err := s.ParseCompoundUnit(unit)
+	if err != nil {
+		return 0, err
+	}
+	if c.Numerator.Dimension != DimensionLength || c.Denominator.Dimension != DimensionTime {
+		return 0, fmt.Errorf("cannot convert %s to or from a pace", unit)
+	}
+	return value * c.ToBaseNum / c.ToBaseDen, nil
+}
+
+// fromMetresPerSecond converts a speed in m/s to unit, the reverse of toMetresPerSecond.
+func (s *System) fromMetresPerSecond(mps float64, unit string) (float64, error) {
+	one, err := s.toMetresPerSecond(1, unit)
+	if err != nil {
+		return 0, err
+	}
+	return mps / one, nil
+}
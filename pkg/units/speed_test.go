@@ -0,0 +1,76 @@
+package units
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPaceConversions(t *testing.T) {
+	s := NewSystem()
+	tests := []struct {
+		value    float64
+		from, to string
+		want     float64
+	}{
+		{5.5, "min/km", "mph", 6.7786},
+		{6, "mph", "min/mile", 10},
+		{10, "kph", "min/km", 6},
+		{12, "km/hr", "min/km", 5},
+		{5, "min/km", "km/hr", 12},
+		{4, "min/km", "m/s", 4.1667},
+		{5, "min/km", "min/mile", 8.0467},
+		{8.0467, "min/mile", "min/km", 5},
+	}
+	for _, tt := range tests {
+		got, err := s.ConvertCompoundUnit(tt.value, tt.from, tt.to)
+		if err != nil {
+			t.Errorf("%v %s in %s: %v", tt.value, tt.from, tt.to, err)
+			continue
+		}
+		if math.Abs(got-tt.want) > 1e-4 {
+			t.Errorf("%v %s in %s = %v, want %v", tt.value, tt.from, tt.to, got, tt.want)
+		}
+	}
+
+	for _, bad := range [][2]string{{"min/km", "kg"}, {"min/km", "kg/s"}} {
+		if _, err := s.ConvertCompoundUnit(5, bad[0], bad[1]); err == nil {
+			t.Errorf("%s in %s should fail", bad[0], bad[1])
+		}
+	}
+	if _, err := s.ConvertCompoundUnit(0, "mph", "min/km"); err == nil {
+		t.Error("a speed of zero has no pace")
+	}
+}
+
+func TestMachAndBeaufort(t *testing.T) {
+	s := NewSystem()
+	tests := []struct {
+		value    float64
+		from, to string
+		want     float64
+	}{
+		{1, "mach", "mps", 340.29},
+		{680.58, "mps", "mach", 2},
+		{0, "beaufort", "mps", 0},
+		{4, "bft", "mps", 6.6880},
+		{12, "beaufort", "mps", 34.7519},
+		{0.2, "mps", "beaufort", 0},
+		{20, "knots", "beaufort", 5},
+		{30, "mph", "bft", 6},
+		{200, "mph", "beaufort", 12},
+		{7, "beaufort", "bft", 7},
+	}
+	for _, tt := range tests {
+		got, err := s.Convert(tt.value, tt.from, tt.to)
+		if err != nil {
+			t.Errorf("%v %s in %s: %v", tt.value, tt.from, tt.to, err)
+			continue
+		}
+		if math.Abs(got-tt.want) > 1e-4 {
+			t.Errorf("%v %s in %s = %v, want %v", tt.value, tt.from, tt.to, got, tt.want)
+		}
+	}
+	if _, err := s.Convert(-1, "beaufort", "mps"); err == nil {
+		t.Error("negative Beaufort force should fail")
+	}
+}
@@ -0,0 +1,152 @@
+package units
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// compatSymbols are the characters that Unicode's compatibility
+// normalisation (NFKC) spells out as unit names, as text copied from web
+// pages often has them: ㎞ is km and ℃ is °C. ² and ³ are left as they are,
+// since the unit tables spell m² and cm³ with them.
+var compatSymbols = map[rune]string{
+	'\u03bc': "µ", // Greek mu, for the micro sign the tables use
+	'ℓ':      "l",
+	'℃':      "°C",
+	'℉':      "°F",
+	'\u212a': "K", // Kelvin sign
+	'\u212b': "Å", // Angstrom sign
+	'⋅':      "·", // Dot operator, for the middle dot
+	'∙':      "·", // Bullet operator
+	'㎅':      "KB",
+	'㎆':      "MB",
+	'㎇':      "GB",
+	'㎈':      "cal",
+	'㎉':      "kcal",
+	'㎍':      "µg",
+	'㎎':      "mg",
+	'㎏':      "kg",
+	'㎐':      "Hz",
+	'㎑':      "kHz",
+	'㎒':      "MHz",
+	'㎓':      "GHz",
+	'㎖':      "ml",
+	'㎗':      "dl",
+	'㎘':      "kl",
+	'㎛':      "µm",
+	'㎜':      "mm",
+	'㎝':      "cm",
+	'㎞':      "km",
+	'㎟':      "mm²",
+	'㎠':      "cm²",
+	'㎡':      "m²",
+	'㎢':      "km²",
+	'㎣':      "mm³",
+	'㎤':      "cm³",
+	'㎥':      "m³",
+	'㎦':      "km³",
+	'㎧':      "m/s",
+	'㎩':      "Pa",
+	'㎪':      "kPa",
+	'㎫':      "MPa",
+	'㎬':      "GPa",
+	'㎰':      "ps",
+	'㎱':      "ns",
+	'㎲':      "µs",
+	'㎳':      "ms",
+	'㎽':      "mW",
+	'㎾':      "kW",
+	'㎿':      "MW",
+	'㏈':      "dB",
+	'㏄':      "cc",
+}
+
+// IsSymbolVariant reports whether r is a character Normalise rewrites, such
+// as ㎞, ℃ or a fullwidth letter.
+func IsSymbolVariant(r rune) bool {
+	_, ok := compatSymbols[r]
+	return ok || isFullwidth(r)
+}
+
+// isFullwidth reports whether r is a fullwidth letter or digit, as in ｋｍ.
+func isFullwidth(r rune) bool {
+	return r >= '０' && r <= '９' || r >= 'Ａ' && r <= 'Ｚ' || r >= 'ａ' && r <= 'ｚ'
+}
+
+// Normalise rewrites a unit name typed or pasted with other characters to
+// the one the unit tables use: ㎞ and ｋｍ are km, μm (with a Greek mu) is
+// µm, and ℃ is C. A degree sign before C, F or K is dropped, so °C is C,
+// while ° alone stays the angle and °R the Rankine scale.
+func Normalise(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case isFullwidth(r):
+			b.WriteRune(r - 0xFEE0)
+		case compatSymbols[r] != "":
+			b.WriteString(compatSymbols[r])
+		default:
+			b.WriteRune(r)
+		}
+	}
+	name = b.String()
+	if scale, ok := strings.CutPrefix(name, "°"); ok && utf8.RuneCountInString(scale) == 1 && strings.ContainsAny(scale, "CFKcfk") {
+		return scale
+	}
+	return name
+}
+
+// lookupVariant finds a unit written in a way lookup does not know, with
+// the characters Normalise rewrites or as a product such as N·m.
+func (s *System) lookupVariant(name string) (*Unit, bool) {
+	if normal := Normalise(name); normal != name {
+		return s.lookup(normal)
+	}
+	return s.lookupProduct(name)
+}
+
+// productDimension is what a product of two units measures, such as a
+// newton metre, and how many of the dimension's base unit one of the
+// product's base units is.
+type productDimension struct {
+	a, b      Dimension
+	result    Dimension
+	base      string
+	baseRatio float64
+}
+
+var productDimensions = []productDimension{
+	{DimensionForce, DimensionLength, DimensionEnergy, "j", 1},
+	{DimensionPower, DimensionTime, DimensionEnergy, "j", 1},
+	{DimensionLength, DimensionLength, DimensionArea, "sqm", 1},
+	{DimensionArea, DimensionLength, DimensionVolume, "l", 1000}, // 1 m³ is 1000 litres
+}
+
+// lookupProduct finds a product of two units written with a middle dot or
+// an asterisk, such as N·m or kW*h, when what it measures is a dimension
+// of its own: a newton metre is a joule.
+func (s *System) lookupProduct(name string) (*Unit, bool) {
+	parts := strings.FieldsFunc(name, func(r rune) bool { return r == '·' || r == '*' })
+	if len(parts) != 2 {
+		return nil, false
+	}
+	a, ok := s.lookup(strings.TrimSpace(parts[0]))
+	if !ok {
+		return nil, false
+	}
+	b, ok := s.lookup(strings.TrimSpace(parts[1]))
+	if !ok {
+		return nil, false
+	}
+	for _, p := range productDimensions {
+		if a.Dimension == p.a && b.Dimension == p.b || a.Dimension == p.b && b.Dimension == p.a {
+			return &Unit{
+				Name:      name,
+				Dimension: p.result,
+				ToBase:    a.ToBase * b.ToBase * p.baseRatio,
+				BaseUnit:  p.base,
+			}, true
+		}
+	}
+	return nil, false
+}
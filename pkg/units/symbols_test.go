@@ -0,0 +1,77 @@
+package units
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNormalise(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"°C", "C"},
+		{"°f", "f"},
+		{"℃", "C"},
+		{"℉", "F"},
+		{"μm", "µm"}, // Greek mu to micro sign
+		{"µm", "µm"},
+		{"㎞", "km"},
+		{"㎏", "kg"},
+		{"㎡", "m²"},
+		{"ｋｍ", "km"},
+		{"N⋅m", "N·m"},
+		{"°", "°"},
+		{"°R", "°R"},
+		{"km", "km"},
+	}
+	for _, tt := range tests {
+		if got := Normalise(tt.in); got != tt.want {
+			t.Errorf("Normalise(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestConvertUnitSymbolVariants(t *testing.T) {
+	s := NewSystem()
+	tests := []struct {
+		value    float64
+		from, to string
+		want     float64
+	}{
+		{20, "°C", "°F", 68},
+		{5, "℃", "F", 41},
+		{5, "μm", "nm", 5000},
+		{3, "㎞", "m", 3000},
+		{2, "㎏", "g", 2000},
+		{1, "㎡", "cm²", 10000},
+		{10, "N·m", "J", 10},
+		{10, "N*m", "kJ", 0.01},
+		{1, "kW·h", "kJ", 3600},
+		{2, "m·m", "sqm", 2},
+	}
+	for _, tt := range tests {
+		got, err := s.Convert(tt.value, tt.from, tt.to)
+		if err != nil {
+			t.Errorf("%g %s in %s: %v", tt.value, tt.from, tt.to, err)
+			continue
+		}
+		if math.Abs(got-tt.want) > math.Abs(tt.want)*1e-9 {
+			t.Errorf("%g %s in %s = %g, want %g", tt.value, tt.from, tt.to, got, tt.want)
+		}
+	}
+
+	if s.IsUnit("kg·m") {
+		t.Error("kg·m measures nothing calc knows, so should not be a unit")
+	}
+}
+
+func TestParseCompoundUnitProducts(t *testing.T) {
+	s := NewSystem()
+	for _, name := range []string{"N·m/s", "N*m/s", "kW·h/day", "㎞/h"} {
+		if _, err := s.ParseCompoundUnit(name); err != nil {
+			t.Errorf("ParseCompoundUnit(%q): %v", name, err)
+		}
+	}
+	got, err := s.ConvertCompoundUnit(1, "kW·h/day", "J/s")
+	if err != nil || math.Abs(got-3600000.0/86400) > 1e-9 {
+		t.Errorf("1 kW·h/day in J/s = %g, %v", got, err)
+	}
+}
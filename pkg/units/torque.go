@@ -0,0 +1,95 @@
+package units
+
+import "strings"
+
+// Torque is measured in newton metres, which have the same SI dimension as
+// joules, M·L²·T⁻², but a turning force is not an amount of energy. Torque
+// has a Dimension of its own so that arithmetic never turns one into the
+// other: 5 Nm + 3 J is an error, while 50 Nm in J, which asks for it by name,
+// converts.
+
+// alikeDimensions pairs dimensions that share their SI units but measure
+// different things, which only an explicit conversion may cross between.
+var alikeDimensions = map[Dimension]Dimension{
+	DimensionTorque: DimensionEnergy,
+	DimensionEnergy: DimensionTorque,
+}
+
+// caseSymbols are unit symbols matched with their case because another unit
+// has the same letters: Nm is a newton metre, nm a nanometre.
+var caseSymbols = map[string]bool{"Nm": true}
+
+// IsCaseSymbol reports whether name is a unit symbol that is only a unit
+// written with exactly this case, such as Nm.
+func IsCaseSymbol(name string) bool {
+	return caseSymbols[name]
+}
+
+// displayName is how errors show a unit name: in lower case, unless case
+// tells it apart from another unit.
+func displayName(name string) string {
+	if caseSymbols[name] {
+		return name
+	}
+	return strings.ToLower(name)
+}
+
+// initTorqueUnits registers the units of torque, based on the newton metre.
+func (s *System) initTorqueUnits() {
+	s.addCaseSymbol("Nm", DimensionTorque, 1.0, "Nm")
+	for _, name := range []string{"newtonmetre", "newtonmetres", "newtonmeter", "newtonmeters", "newton-metre", "newton-metres", "newton-meter", "newton-meters"} {
+		s.addUnit(name, DimensionTorque, 1.0, "Nm")
+	}
+	s.addUnit("knm", DimensionTorque, 1000.0, "Nm")
+
+	// A pound-foot is one pound-force at one foot, 4.4482216152605 N × 0.3048 m
+	const lbft = 1.3558179483314004
+	for _, name := range []string{"lbft", "lbfft", "ftlb", "ftlbf", "lb-ft", "ft-lb", "lbf-ft", "ft-lbf"} {
+		s.addUnit(name, DimensionTorque, lbft, "Nm")
+	}
+	for _, name := range []string{"poundfoot", "poundfeet", "pound-foot", "pound-feet", "footpound", "footpounds", "foot-pound", "foot-pounds"} {
+		s.addUnit(name, DimensionTorque, lbft, "Nm")
+	}
+	for _, name := range []string{"lbin", "lbfin", "inlb", "inlbf"} {
+		s.addUnit(name, DimensionTorque, lbft/12, "Nm")
+	}
+}
+
+// addCaseSymbol registers a unit symbol that lookup matches with its case.
+func (s *System) addCaseSymbol(name string, dim Dimension, toBase float64, baseUnit string) {
+	s.units[name] = &Unit{
+		Name:      name,
+		Dimension: dim,
+		ToBase:    toBase,
+		BaseUnit:  baseUnit,
+		seq:       s.nextSeq(),
+	}
+}
+
+// ConvertAcross converts a value for a conversion asked for by name, as in
+// "50 lbft in nm", where Convert would refuse. It crosses between alike
+// dimensions such as torque and energy, and reads a target written in the
+// wrong case as the symbol that fits, so nm after a torque is Nm. It returns
+// the unit converted to, and false when Convert should be used instead.
+func (s *System) ConvertAcross(value float64, fromUnit, toUnit string) (float64, string, bool) {
+	from, ok := s.lookup(fromUnit)
+	if !ok || from.IsScale() {
+		return 0, "", false
+	}
+	if to, ok := s.lookup(toUnit); ok && to.Dimension == from.Dimension {
+		return 0, "", false
+	}
+	targets := []string{toUnit}
+	for symbol := range caseSymbols {
+		if symbol != toUnit && strings.EqualFold(symbol, toUnit) {
+			targets = append(targets, symbol)
+		}
+	}
+	for _, target := range targets {
+		to, ok := s.lookup(target)
+		if ok && (to.Dimension == from.Dimension || alikeDimensions[from.Dimension] == to.Dimension) {
+			return scale(value, from.ToBase, to.ToBase), target, true
+		}
+	}
+	return 0, "", false
+}
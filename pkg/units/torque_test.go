@@ -0,0 +1,85 @@
+package units
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestNmIsTorqueAndNmLowerIsLength(t *testing.T) {
+	s := NewSystem()
+	for name, want := range map[string]Dimension{
+		"Nm":           DimensionTorque,
+		"nm":           DimensionLength,
+		"newton-metre": DimensionTorque,
+		"lbft":         DimensionTorque,
+		"foot-pound":   DimensionTorque,
+		"kNm":          DimensionTorque,
+	} {
+		got, err := s.GetDimension(name)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("%s is %s, want %s", name, got, want)
+		}
+	}
+	if s.IsUnit("NM") {
+		t.Error("NM should not be a unit")
+	}
+}
+
+func TestTorqueConversions(t *testing.T) {
+	s := NewSystem()
+	got, err := s.Convert(50, "lbft", "Nm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(got-67.7909) > 1e-4 {
+		t.Errorf("50 lbft in Nm = %v, want 67.7909", got)
+	}
+	if got, err := s.Convert(5, "km", "nm"); err != nil || got != 5e12 {
+		t.Errorf("5 km in nm = %v, %v; want 5e12 nanometres", got, err)
+	}
+}
+
+func TestConvertRefusesTorqueToEnergy(t *testing.T) {
+	s := NewSystem()
+	_, err := s.Convert(5, "Nm", "j")
+	var incompatible *ErrIncompatibleDimensions
+	if !errors.As(err, &incompatible) || !incompatible.Alike {
+		t.Fatalf("Nm to j: got %v, want an alike dimensions error", err)
+	}
+	if incompatible.From != "Nm" {
+		t.Errorf("error names %q, want Nm in its own case", incompatible.From)
+	}
+}
+
+func TestConvertAcross(t *testing.T) {
+	s := NewSystem()
+	tests := []struct {
+		value    float64
+		from, to string
+		want     float64
+		unit     string
+	}{
+		{50, "Nm", "j", 50, "j"},
+		{3, "kj", "Nm", 3000, "Nm"},
+		{50, "lbft", "nm", 67.7909, "Nm"}, // nm cannot take a torque
+		{50, "J", "nm", 50, "Nm"},
+	}
+	for _, tt := range tests {
+		got, unit, ok := s.ConvertAcross(tt.value, tt.from, tt.to)
+		if !ok || unit != tt.unit || math.Abs(got-tt.want) > 1e-4 {
+			t.Errorf("ConvertAcross(%v, %s, %s) = %v, %s, %v; want %v %s", tt.value, tt.from, tt.to, got, unit, ok, tt.want, tt.unit)
+		}
+	}
+
+	// Conversions Convert can do, or that no reading makes sense of, are left to it
+	for _, pair := range [][2]string{{"km", "nm"}, {"Nm", "lbft"}, {"Nm", "m"}, {"kg", "j"}} {
+		if _, _, ok := s.ConvertAcross(1, pair[0], pair[1]); ok {
+			t.Errorf("ConvertAcross(%s, %s) should report false", pair[0], pair[1])
+		}
+	}
+}
@@ -2,7 +2,9 @@ package units
 
 import (
 	"fmt"
+	"math"
 	"strings"
+	"sync"
 )
 
 // Dimension represents a physical dimension.
@@ -23,6 +25,10 @@ const (
 	DimensionForce     // Force (N, lbf)
 	DimensionAngle     // Angle (degrees, radians, gradians)
 	DimensionFrequency // Frequency (Hz, kHz, MHz, GHz)
+	DimensionEnergy    // Energy (J, kJ, kWh)
+	DimensionPower     // Power (W, kW, MW)
+	DimensionCount     // Counts of things (people, items)
+	DimensionTorque    // Torque (N·m, lbf·ft), kept apart from energy
 )
 
 // Unit represents a unit of measurement.
@@ -32,6 +38,8 @@ type Unit struct {
 	ToBase    float64 // conversion factor to base unit
 	BaseUnit  string
 	IsCustom  bool
+	Informal  bool // A rough measure such as a pinch, hidden by SetInformal(false)
+	seq       int  // registration order, for listing names as they were added
 }
 
 // CompoundUnit represents a compound unit like km/h or m/s.
@@ -44,18 +52,55 @@ type CompoundUnit struct {
 
 // System manages all units and conversions.
 type System struct {
+	// units starts as the standard units' shared map, copied by own before
+	// the first change so that systems never see each other's custom units
 	units  map[string]*Unit
+	owned  bool // units is this system's own copy
 	custom map[string]*Unit
+	// prefixed caches units made for SI-prefixed names, keyed by exact name
+	prefixed map[string]*Unit
+	added    int // units registered so far, numbering each Unit's seq
+	// grouped caches groups(); changing the registered units clears it
+	grouped [][]*Unit
+	// ingredients are the densities in g/ml added with DefineIngredient
+	ingredients map[string]float64
+	informalOff bool // Informal units are hidden
+	// saved is the custom units as last loaded or saved, against which
+	// SaveCustomUnits finds the changes to merge into the file
+	saved Pack
 }
 
 // NewSystem creates a new unit system.
 func NewSystem() *System {
-	s := &System{
-		units:  make(map[string]*Unit),
-		custom: make(map[string]*Unit),
+	std := standardSystem()
+	return &System{
+		units:    std.units,
+		custom:   make(map[string]*Unit),
+		prefixed: make(map[string]*Unit),
+		added:    std.added,
 	}
+}
+
+// standardSystem holds only the standard units, registered on first use.
+// Every System shares its units map until it changes its own; it must not
+// be changed.
+var standardSystem = sync.OnceValue(func() *System {
+	s := &System{units: make(map[string]*Unit), owned: true}
 	s.initStandardUnits()
 	return s
+})
+
+// own gives the system its own copy of the units map before it is changed.
+func (s *System) own() {
+	if s.owned {
+		return
+	}
+	units := make(map[string]*Unit, len(s.units)+1)
+	for name, u := range s.units {
+		units[name] = u
+	}
+	s.units = units
+	s.owned = true
 }
 
 func (s *System) initStandardUnits() {
@@ -80,6 +125,9 @@ func (s *System) initStandardUnits() {
 	s.addUnit("mi", DimensionLength, 1609.344, "m")
 	s.addUnit("mile", DimensionLength, 1609.344, "m")
 	s.addUnit("miles", DimensionLength, 1609.344, "m")
+	s.addUnit("ly", DimensionLength, 9460730472580800, "m") // light-year, by definition
+	s.addUnit("lightyear", DimensionLength, 9460730472580800, "m")
+	s.addUnit("lightyears", DimensionLength, 9460730472580800, "m")
 
 	// Mass units (base: kilogram)
 	s.addUnit("kg", DimensionMass, 1.0, "kg")
@@ -371,6 +419,10 @@ func (s *System) initStandardUnits() {
 	s.addUnit("knot", DimensionSpeed, 0.514444, "mps") // nautical miles per hour
 	s.addUnit("knots", DimensionSpeed, 0.514444, "mps")
 	s.addUnit("kn", DimensionSpeed, 0.514444, "mps")
+	s.addUnit("mach", DimensionSpeed, 340.29, "mps") // speed of sound in the standard atmosphere at sea level
+	// Beaufort force is a scale rather than a multiple of mps (see convertBeaufort)
+	s.addUnit("beaufort", DimensionSpeed, 1.0, beaufortBase)
+	s.addUnit("bft", DimensionSpeed, 1.0, beaufortBase)
 
 	// Pressure units (base: Pascal)
 	s.addUnit("pa", DimensionPressure, 1.0, "pa")
@@ -442,6 +494,46 @@ func (s *System) initStandardUnits() {
 	s.addUnit("thz", DimensionFrequency, 1000000000000.0, "hz")
 	s.addUnit("terahertz", DimensionFrequency, 1000000000000.0, "hz")
 	s.addUnit("rpm", DimensionFrequency, 0.0166667, "hz") // revolutions per minute
+
+	// Energy units (base: Joule)
+	s.addUnit("j", DimensionEnergy, 1.0, "j")
+	s.addUnit("joule", DimensionEnergy, 1.0, "j")
+	s.addUnit("joules", DimensionEnergy, 1.0, "j")
+	s.addUnit("kj", DimensionEnergy, 1000.0, "j")
+	s.addUnit("kilojoule", DimensionEnergy, 1000.0, "j")
+	s.addUnit("kilojoules", DimensionEnergy, 1000.0, "j")
+	// Lower-case mj and mw are mega, as mwh is; written with a capital,
+	// mJ and mW are milli by their SI prefix
+	s.addUnit("mj", DimensionEnergy, 1000000.0, "j")
+	s.addUnit("megajoule", DimensionEnergy, 1000000.0, "j")
+	s.addUnit("megajoules", DimensionEnergy, 1000000.0, "j")
+	s.addUnit("wh", DimensionEnergy, 3600.0, "j") // watt-hour
+	s.addUnit("kwh", DimensionEnergy, 3600000.0, "j")
+	s.addUnit("kilowatthour", DimensionEnergy, 3600000.0, "j")
+	s.addUnit("kilowatthours", DimensionEnergy, 3600000.0, "j")
+
+	// Power units (base: Watt)
+	s.addUnit("w", DimensionPower, 1.0, "w")
+	s.addUnit("watt", DimensionPower, 1.0, "w")
+	s.addUnit("watts", DimensionPower, 1.0, "w")
+	s.addUnit("kw", DimensionPower, 1000.0, "w")
+	s.addUnit("kilowatt", DimensionPower, 1000.0, "w")
+	s.addUnit("kilowatts", DimensionPower, 1000.0, "w")
+	s.addUnit("mw", DimensionPower, 1000000.0, "w")
+	s.addUnit("megawatt", DimensionPower, 1000000.0, "w")
+	s.addUnit("megawatts", DimensionPower, 1000000.0, "w")
+
+	// Count units (base: item). These are dimensionless divisors for
+	// per-person and per-item amounts such as £86.40 / 4 people. The singular
+	// "unit" is left out so that it still reads as the :unit command.
+	s.addUnit("person", DimensionCount, 1.0, "item")
+	s.addUnit("people", DimensionCount, 1.0, "item")
+	s.addUnit("item", DimensionCount, 1.0, "item")
+	s.addUnit("items", DimensionCount, 1.0, "item")
+	s.addUnit("units", DimensionCount, 1.0, "item")
+
+	s.initTorqueUnits()
+	s.initInformalUnits()
 }
 
 func (s *System) addUnit(name string, dim Dimension, toBase float64, baseUnit string) {
@@ -451,17 +543,25 @@ func (s *System) addUnit(name string, dim Dimension, toBase float64, baseUnit st
 		ToBase:    toBase,
 		BaseUnit:  baseUnit,
 		IsCustom:  false,
+		seq:       s.nextSeq(),
 	}
 }
 
+// nextSeq numbers the next unit registered.
+func (s *System) nextSeq() int {
+	s.grouped = nil
+	s.added++
+	return s.added
+}
+
 // AddCustomUnit adds a custom unit definition.
 func (s *System) AddCustomUnit(name string, value float64, baseUnit string) error {
 	name = strings.ToLower(name)
 
 	// Check if base unit exists
-	base, exists := s.units[strings.ToLower(baseUnit)]
+	base, exists := s.lookup(baseUnit)
 	if !exists {
-		return fmt.Errorf("unknown base unit: %s", baseUnit)
+		return &ErrUnknownUnit{Name: baseUnit, Role: "base"}
 	}
 
 	// Check for circular definition
@@ -475,8 +575,10 @@ func (s *System) AddCustomUnit(name string, value float64, baseUnit string) erro
 		ToBase:    value * base.ToBase,
 		BaseUnit:  base.BaseUnit,
 		IsCustom:  true,
+		seq:       s.nextSeq(),
 	}
 
+	s.own()
 	s.units[name] = s.custom[name]
 
 	return nil
@@ -484,34 +586,58 @@ func (s *System) AddCustomUnit(name string, value float64, baseUnit string) erro
 
 // Convert converts a value from one unit to another.
 func (s *System) Convert(value float64, fromUnit, toUnit string) (float64, error) {
-	fromUnit = strings.ToLower(fromUnit)
-	toUnit = strings.ToLower(toUnit)
-
-	from, ok := s.units[fromUnit]
+	from, ok := s.lookup(fromUnit)
+	fromUnit = displayName(fromUnit)
 	if !ok {
-		return 0, fmt.Errorf("unknown unit '%s'", fromUnit)
+		return 0, &ErrUnknownUnit{Name: fromUnit}
 	}
 
-	to, ok := s.units[toUnit]
+	to, ok := s.lookup(toUnit)
+	toUnit = displayName(toUnit)
 	if !ok {
-		return 0, fmt.Errorf("unknown unit '%s'", toUnit)
+		return 0, &ErrUnknownUnit{Name: toUnit}
 	}
 
 	// Check dimension compatibility
 	if from.Dimension != to.Dimension {
-		return 0, fmt.Errorf("cannot convert %s to %s", fromUnit, toUnit)
+		if alikeDimensions[from.Dimension] == to.Dimension {
+			return 0, &ErrIncompatibleDimensions{From: fromUnit, To: toUnit, Alike: true}
+		}
+		return 0, &ErrIncompatibleDimensions{From: fromUnit, To: toUnit}
 	}
 
-	// Handle temperature specially
+	// Handle temperature and the Beaufort scale specially
 	if from.Dimension == DimensionTemperature {
-		return s.convertTemperature(value, fromUnit, toUnit)
+		return s.convertTemperature(value, Normalise(fromUnit), Normalise(toUnit))
+	}
+	if from.IsScale() || to.IsScale() {
+		return convertBeaufort(value, from, to)
 	}
 
-	// Convert to base unit, then to target unit
-	baseValue := value * from.ToBase
-	result := baseValue / to.ToBase
+	return scale(value, from.ToBase, to.ToBase), nil
+}
 
-	return result, nil
+// scale converts value between units whose base factors are fromBase and
+// toBase. It applies their ratio in one step, taken so that the ratio is at
+// least 1 and snapped to a whole number when it is one but for float error:
+// mm to km is then exactly 1e6 and µs to ms exactly 1000, so conversions
+// between decimal multiples round-trip cleanly.
+func scale(value, fromBase, toBase float64) float64 {
+	if fromBase == toBase {
+		return value
+	}
+	if fromBase > toBase {
+		return value * wholeRatio(fromBase/toBase)
+	}
+	return value / wholeRatio(toBase/fromBase)
+}
+
+// wholeRatio rounds r to the nearest whole number if it is within float error of one.
+func wholeRatio(r float64) float64 {
+	if whole := math.Round(r); math.Abs(r-whole) <= r*1e-12 {
+		return whole
+	}
+	return r
 }
 
 func (s *System) convertTemperature(value float64, from, to string) (float64, error) {
@@ -530,7 +656,7 @@ func (s *System) convertTemperature(value float64, from, to string) (float64, er
 	case "r", "rankine", "°r":
 		celsius = (value - 491.67) * 5 / 9 // R to C: (R - 491.67) × 5/9
 	default:
-		return 0, fmt.Errorf("unknown temperature unit: %s", from)
+		return 0, &ErrUnknownUnit{Name: from, Role: "temperature"}
 	}
 
 	// Convert from celsius to target
@@ -544,45 +670,44 @@ func (s *System) convertTemperature(value float64, from, to string) (float64, er
 	case "r", "rankine", "°r":
 		return (celsius + 273.15) * 9 / 5, nil // C to R: (C + 273.15) × 9/5
 	default:
-		return 0, fmt.Errorf("unknown temperature unit: %s", to)
+		return 0, &ErrUnknownUnit{Name: to, Role: "temperature"}
 	}
 }
 
 // IsUnit checks if a string is a known unit.
 func (s *System) IsUnit(name string) bool {
-	_, ok := s.units[strings.ToLower(name)]
+	_, ok := s.lookup(name)
 	return ok
 }
 
 // GetDimension returns the dimension of a unit.
 func (s *System) GetDimension(name string) (Dimension, error) {
-	unit, ok := s.units[strings.ToLower(name)]
+	unit, ok := s.lookup(name)
 	if !ok {
-		return DimensionNone, fmt.Errorf("unknown unit: %s", name)
+		return DimensionNone, &ErrUnknownUnit{Name: name}
 	}
 	return unit.Dimension, nil
 }
 
 // ParseCompoundUnit parses a compound unit string like "km/h" or "m/s".
 func (s *System) ParseCompoundUnit(unitStr string) (*CompoundUnit, error) {
-	unitStr = strings.ToLower(unitStr)
 	parts := strings.Split(unitStr, "/")
 
 	if len(parts) != 2 {
-		return nil, fmt.Errorf("invalid compound unit format: %s", unitStr)
+		return nil, &ErrBadCompound{Input: unitStr}
 	}
 
 	numStr := strings.TrimSpace(parts[0])
 	denStr := strings.TrimSpace(parts[1])
 
-	num, ok := s.units[numStr]
+	num, ok := s.lookup(numStr)
 	if !ok {
-		return nil, fmt.Errorf("unknown numerator unit: %s", numStr)
+		return nil, &ErrUnknownUnit{Name: strings.ToLower(numStr), Role: "numerator"}
 	}
 
-	den, ok := s.units[denStr]
+	den, ok := s.lookup(denStr)
 	if !ok {
-		return nil, fmt.Errorf("unknown denominator unit: %s", denStr)
+		return nil, &ErrUnknownUnit{Name: strings.ToLower(denStr), Role: "denominator"}
 	}
 
 	return &CompoundUnit{
@@ -597,6 +722,11 @@ func (s *System) ParseCompoundUnit(unitStr string) (*CompoundUnit, error) {
 // Example: Convert 50 km/h to m/s
 // This also handles conversions between speed abbreviations (kph, mph) and compound units (km/h, mi/h).
 func (s *System) ConvertCompoundUnit(value float64, fromUnit, toUnit string) (float64, error) {
+	// A pace is time per distance, the reciprocal of a speed
+	if s.IsPace(fromUnit) != s.IsPace(toUnit) {
+		return s.convertPace(value, fromUnit, toUnit)
+	}
+
 	// If one or both units are speed abbreviations, convert through the base unit (mps)
 	fromIsSimple := !IsCompoundUnit(fromUnit)
 	toIsSimple := !IsCompoundUnit(toUnit)
@@ -615,13 +745,11 @@ func (s *System) ConvertCompoundUnit(value float64, fromUnit, toUnit string) (fl
 
 		// Check dimension compatibility
 		if from.Numerator.Dimension != to.Numerator.Dimension {
-			return 0, fmt.Errorf("incompatible numerator dimensions: %s vs %s",
-				fromUnit, toUnit)
+			return 0, &ErrIncompatibleDimensions{From: fromUnit, To: toUnit, Part: "numerator"}
 		}
 
 		if from.Denominator.Dimension != to.Denominator.Dimension {
-			return 0, fmt.Errorf("incompatible denominator dimensions: %s vs %s",
-				fromUnit, toUnit)
+			return 0, &ErrIncompatibleDimensions{From: fromUnit, To: toUnit, Part: "denominator"}
 		}
 
 		// Convert: value * (fromNum/fromDen) * (toDen/toNum)
@@ -1,7 +1,9 @@
 package units
 
 import (
+	"errors"
 	"math"
+	"sort"
 	"testing"
 )
 
@@ -188,8 +190,9 @@ func TestIncompatibleUnits(t *testing.T) {
 	s := NewSystem()
 
 	_, err := s.Convert(10, "kg", "metres")
-	if err == nil {
-		t.Error("expected error when converting incompatible units, got nil")
+	var incompatible *ErrIncompatibleDimensions
+	if !errors.As(err, &incompatible) || incompatible.From != "kg" || incompatible.To != "metres" {
+		t.Errorf("expected ErrIncompatibleDimensions from kg to metres, got %v", err)
 	}
 }
 
@@ -599,26 +602,31 @@ func TestConvertCompoundUnit(t *testing.T) {
 func TestConvertCompoundUnitErrors(t *testing.T) {
 	s := NewSystem()
 
+	var (
+		unknown      *ErrUnknownUnit
+		incompatible *ErrIncompatibleDimensions
+	)
 	tests := []struct {
-		name  string
-		value float64
-		from  string
-		to    string
+		name   string
+		value  float64
+		from   string
+		to     string
+		target any // Pointer to the error type expected
 	}{
-		{"invalid from unit", 50, "xyz/h", "m/s"},
-		{"invalid to unit", 50, "km/h", "xyz/s"},
-		{"incompatible dimensions numerator", 50, "kg/h", "m/s"},
-		{"incompatible dimensions denominator", 50, "km/kg", "m/s"},
-		{"empty from", 50, "", "m/s"},
-		{"empty to", 50, "km/h", ""},
+		{"invalid from unit", 50, "xyz/h", "m/s", &unknown},
+		{"invalid to unit", 50, "km/h", "xyz/s", &unknown},
+		{"incompatible dimensions numerator", 50, "kg/h", "m/s", &incompatible},
+		{"incompatible dimensions denominator", 50, "km/kg", "m/s", &incompatible},
+		{"empty from", 50, "", "m/s", &unknown},
+		{"empty to", 50, "km/h", "", &unknown},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			_, err := s.ConvertCompoundUnit(tt.value, tt.from, tt.to)
-			if err == nil {
-				t.Errorf("ConvertCompoundUnit(%f %s to %s) expected error, got nil",
-					tt.value, tt.from, tt.to)
+			if !errors.As(err, tt.target) {
+				t.Errorf("ConvertCompoundUnit(%f %s to %s) = %v, want a %T",
+					tt.value, tt.from, tt.to, err, tt.target)
 			}
 		})
 	}
@@ -1355,3 +1363,96 @@ func TestAllNewUnitsRecognition(t *testing.T) {
 		}
 	}
 }
+
+func TestCountUnits(t *testing.T) {
+	s := NewSystem()
+
+	for _, name := range []string{"person", "people", "item", "items", "units"} {
+		dim, err := s.GetDimension(name)
+		if err != nil || dim != DimensionCount {
+			t.Errorf("%s: got dimension %v, %v; want count", name, dim, err)
+		}
+	}
+	if got, err := s.Convert(4, "people", "person"); err != nil || got != 4 {
+		t.Errorf("4 people in person = %v, %v", got, err)
+	}
+	if _, err := s.Convert(4, "people", "kg"); err == nil {
+		t.Error("expected people and kg to be incompatible")
+	}
+	if s.IsUnit("unit") {
+		t.Error("the singular \"unit\" should not be a unit; it names the :unit command")
+	}
+}
+
+// Every pair of units in a dimension converts there and back again without
+// visible drift, whatever the size of the factors involved.
+func TestConversionRoundTrips(t *testing.T) {
+	s := NewSystem()
+
+	byDimension := map[Dimension][]string{}
+	for name, u := range s.units {
+		byDimension[u.Dimension] = append(byDimension[u.Dimension], name)
+	}
+
+	for dim, names := range byDimension {
+		sort.Strings(names)
+		for _, from := range names {
+			for _, to := range names {
+				// Beaufort forces are whole steps, so a speed only comes
+				// back from one to within its band
+				if dim == DimensionSpeed && s.units[from].IsScale() != s.units[to].IsScale() {
+					continue
+				}
+				for _, x := range []float64{1, 1234.5678, 1e-9, 1e15} {
+					y, err := s.Convert(x, from, to)
+					if err != nil {
+						t.Fatalf("%v %s in %s: %v", x, from, to, err)
+					}
+					back, err := s.Convert(y, to, from)
+					if err != nil {
+						t.Fatalf("%v %s in %s: %v", y, to, from, err)
+					}
+					// Temperatures shift by an offset, so tiny values are
+					// measured against a degree rather than themselves
+					scale := math.Abs(x)
+					if dim == DimensionTemperature {
+						scale = math.Max(scale, 1)
+					}
+					if relErr := math.Abs(back-x) / scale; relErr > 1e-12 {
+						t.Errorf("%v %s -> %s -> %s gave %v (relative error %.3g)", x, from, to, from, back, relErr)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestConversionOfExtremeFactors(t *testing.T) {
+	s := NewSystem()
+
+	tests := []struct {
+		value    float64
+		from     string
+		to       string
+		expected float64
+	}{
+		{1000, "pb", "bits", 9.007199254740992e18},
+		{1, "ly", "mm", 9.4607304725808e18},
+		{1, "mm", "km", 1e-6},
+		{1, "km", "mm", 1e6},
+		{3, "mg", "kg", 3e-6},
+		{1000, "µs", "ms", 1},
+		{12, "in", "ft", 1},
+	}
+
+	for _, tt := range tests {
+		got, err := s.Convert(tt.value, tt.from, tt.to)
+		if err != nil {
+			t.Errorf("%v %s in %s: %v", tt.value, tt.from, tt.to, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("%v %s in %s = %v, want %v", tt.value, tt.from, tt.to, got, tt.expected)
+		}
+	}
+}